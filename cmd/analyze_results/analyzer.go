@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"hash/fnv"
 	"io"
+	"math"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"reactsql/internal/adapter"
 )
 
 // Color constants
@@ -356,35 +361,12 @@ func hashRow(row []string) (uint64, string) {
 	return h.Sum64(), key
 }
 
-// hashRowNormalized computes hash on normalized values
-func hashRowNormalized(row []string) (uint64, string) {
-	h := fnv.New64a()
-	var sb strings.Builder
-	sb.Grow(len(row) * 16)
-	for i, v := range row {
-		nv := normalizeValue(v)
-		if i > 0 {
-			h.Write([]byte{'|'})
-			sb.WriteByte('|')
-		}
-		io.WriteString(h, nv)
-		sb.WriteString(nv)
-	}
-	return h.Sum64(), sb.String()
-}
-
 // buildRowCounts builds a hash→rowEntry multiset map from rows.
 // Uses hash bucketing; on collision, falls back to string key comparison.
-func buildRowCounts(rows [][]string, normalize bool) map[uint64][]rowEntry {
+func buildRowCounts(rows [][]string) map[uint64][]rowEntry {
 	m := make(map[uint64][]rowEntry, len(rows))
 	for _, row := range rows {
-		var h uint64
-		var key string
-		if normalize {
-			h, key = hashRowNormalized(row)
-		} else {
-			h, key = hashRow(row)
-		}
+		h, key := hashRow(row)
 		bucket := m[h]
 		found := false
 		for i := range bucket {
@@ -440,18 +422,19 @@ func matchMaps(m1, m2 map[uint64][]rowEntry) bool {
 }
 
 // compareRowSets compares two row sets using hash-first exact match, then normalized match.
-func compareRowSets(rows1, rows2 [][]string, matchingStrategy string) (bool, string) {
+// loose rows are the same rows with every cell normalized for the second pass.
+func compareRowSets(rows1, rows2, loose1, loose2 [][]string, matchingStrategy string) (bool, string) {
 	// Fast path: hash-based exact match
-	m1 := buildRowCounts(rows1, false)
-	m2 := buildRowCounts(rows2, false)
+	m1 := buildRowCounts(rows1)
+	m2 := buildRowCounts(rows2)
 
 	if matchMaps(m1, m2) {
 		return true, ""
 	}
 
-	// Slow path: normalize and re-hash
-	nm1 := buildRowCounts(rows1, true)
-	nm2 := buildRowCounts(rows2, true)
+	// Slow path: compare normalized rows
+	nm1 := buildRowCounts(loose1)
+	nm2 := buildRowCounts(loose2)
 
 	if matchMaps(nm1, nm2) {
 		return true, ""
@@ -523,7 +506,8 @@ func (a *SQLAnalyzer) areResultsEquivalent(result1, result2 *ExecResult) (bool,
 	}
 
 	// Step 4: Try multiple matching strategies
-	var convertedRows1, convertedRows2 [][]string
+	// Each strategy yields, per output position, the source column index in gold/pred rows (-1 = missing)
+	var cols1, cols2 []int
 	var matchingStrategy string
 
 	// Strategy 1: Exact column name match (ignoring order)
@@ -544,54 +528,18 @@ func (a *SQLAnalyzer) areResultsEquivalent(result1, result2 *ExecResult) (bool,
 		}
 		sort.Strings(sortedColumns)
 
-		// Convert result sets to comparable format
-		convertedRows1 = make([][]string, dataRows1)
-		convertedRows2 = make([][]string, dataRows2)
-
-		// Convert gold SQL results to unified column order
-		for i := 1; i <= dataRows1; i++ {
-			row := make([]string, len(sortedColumns))
-			for j, colName := range sortedColumns {
-				colIndex := headerToIndex1[colName]
-				if colIndex < len(result1.Rows[i]) { // prevent index out of bounds
-					row[j] = result1.Rows[i][colIndex]
-				} else {
-					row[j] = ""
-				}
-			}
-			convertedRows1[i-1] = row
-		}
-
-		// Convert predicted SQL results to unified column order
-		for i := 1; i <= dataRows2; i++ {
-			row := make([]string, len(sortedColumns))
-			for j, colName := range sortedColumns {
-				colIndex := headerToIndex2[colName]
-				if colIndex < len(result2.Rows[i]) { // prevent index out of bounds
-					row[j] = result2.Rows[i][colIndex]
-				} else {
-					row[j] = ""
-				}
-			}
-			convertedRows2[i-1] = row
+		cols1 = make([]int, len(sortedColumns))
+		cols2 = make([]int, len(sortedColumns))
+		for j, colName := range sortedColumns {
+			cols1[j] = headerToIndex1[colName]
+			cols2[j] = headerToIndex2[colName]
 		}
 	} else {
 		// Strategy 2: Smart column reordering based on content feature matching
 		matchingStrategy = "content_based_mapping"
-		convertedRows1 = make([][]string, dataRows1)
-		convertedRows2 = make([][]string, dataRows2)
-
-		// Extract gold SQL data rows
-		for i := 1; i <= dataRows1; i++ {
-			row := make([]string, len(headers1))
-			for j := 0; j < len(headers1); j++ {
-				if j < len(result1.Rows[i]) {
-					row[j] = result1.Rows[i][j]
-				} else {
-					row[j] = ""
-				}
-			}
-			convertedRows1[i-1] = row
+		cols1 = make([]int, len(headers1))
+		for j := range cols1 {
+			cols1[j] = j
 		}
 
 		// Smart column reordering: based on column feature values
@@ -599,31 +547,17 @@ func (a *SQLAnalyzer) areResultsEquivalent(result1, result2 *ExecResult) (bool,
 
 		if mapping != nil {
 			// Found valid column mapping, reorder predicted results
-			for i := 1; i <= dataRows2; i++ {
-				row := make([]string, len(headers1))
-				for j := 0; j < len(headers1); j++ {
-					srcCol := mapping[j]
-					if srcCol < len(result2.Rows[i]) {
-						row[j] = result2.Rows[i][srcCol]
-					} else {
-						row[j] = ""
-					}
-				}
-				convertedRows2[i-1] = row
-			}
+			cols2 = mapping
 		} else {
 			// Strategy 3: Positional comparison (ignore column names)
 			matchingStrategy = "positional_comparison"
-			for i := 1; i <= dataRows2; i++ {
-				row := make([]string, len(headers2))
-				for j := 0; j < len(headers2) && j < len(headers1); j++ {
-					if j < len(result2.Rows[i]) {
-						row[j] = result2.Rows[i][j]
-					} else {
-						row[j] = ""
-					}
+			cols2 = make([]int, len(headers2))
+			for j := range cols2 {
+				if j < len(headers1) {
+					cols2[j] = j
+				} else {
+					cols2[j] = -1
 				}
-				convertedRows2[i-1] = row
 			}
 		}
 	}
@@ -631,8 +565,22 @@ func (a *SQLAnalyzer) areResultsEquivalent(result1, result2 *ExecResult) (bool,
 	// Step 5: Compare data content (order-independent, preserving duplicates)
 	// Uses hash-first comparison: compute FNV hash per row for O(1) bucket lookup,
 	// only fall back to string comparison on hash collisions.
+	// When both sides carry typed values, rows are keyed by real types (NULL != "", 3 == 3.0)
+	// instead of their string rendering.
+	var exact1, exact2, loose1, loose2 [][]string
+	if len(result1.Typed) == dataRows1 && len(result2.Typed) == dataRows2 {
+		exact1 = projectTypedRows(result1.Typed, cols1, adapter.Value.Key)
+		exact2 = projectTypedRows(result2.Typed, cols2, adapter.Value.Key)
+		loose1 = projectTypedRows(result1.Typed, cols1, looseValueKey)
+		loose2 = projectTypedRows(result2.Typed, cols2, looseValueKey)
+	} else {
+		exact1 = projectRows(result1.Rows[1:], cols1, nil)
+		exact2 = projectRows(result2.Rows[1:], cols2, nil)
+		loose1 = projectRows(result1.Rows[1:], cols1, normalizeValue)
+		loose2 = projectRows(result2.Rows[1:], cols2, normalizeValue)
+	}
 
-	matched, reason := compareRowSets(convertedRows1, convertedRows2, matchingStrategy)
+	matched, reason := compareRowSets(exact1, exact2, loose1, loose2, matchingStrategy)
 	if !matched {
 		return false, reason
 	}
@@ -641,6 +589,95 @@ func (a *SQLAnalyzer) areResultsEquivalent(result1, result2 *ExecResult) (bool,
 	return true, ""
 }
 
+// projectRows reorders string rows by column indices, optionally normalizing each cell
+func projectRows(rows [][]string, cols []int, normalize func(string) string) [][]string {
+	out := make([][]string, len(rows))
+	for i, src := range rows {
+		row := make([]string, len(cols))
+		for j, c := range cols {
+			if c >= 0 && c < len(src) { // prevent index out of bounds
+				row[j] = src[c]
+			}
+			if normalize != nil {
+				row[j] = normalize(row[j])
+			}
+		}
+		out[i] = row
+	}
+	return out
+}
+
+// projectTypedRows reorders typed rows by column indices and renders each cell with key
+func projectTypedRows(rows [][]adapter.Value, cols []int, key func(adapter.Value) string) [][]string {
+	out := make([][]string, len(rows))
+	for i, src := range rows {
+		row := make([]string, len(cols))
+		for j, c := range cols {
+			if c >= 0 && c < len(src) {
+				row[j] = key(src[c])
+			} else {
+				row[j] = "\x00missing"
+			}
+		}
+		out[i] = row
+	}
+	return out
+}
+
+// looseValueKey typed counterpart of normalizeValue: trims text, drops trailing %,
+// truncates times to seconds and rounds floats. Text that reads as a number or a
+// timestamp gets the number/time key, so "3" matches 3 and text dates match DATETIME values.
+func looseValueKey(v adapter.Value) string {
+	switch v.Kind {
+	case adapter.KindText:
+		s := strings.TrimSpace(v.Text)
+		if isTimeValue(s) {
+			s = normalizeTimeValue(s)
+			if t, ok := parseLooseTime(s); ok {
+				return looseTimeKey(t)
+			}
+			return "s:" + s
+		}
+		s = strings.TrimSuffix(s, "%")
+		if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return looseNumberKey(f)
+		}
+		return "s:" + s
+	case adapter.KindTime:
+		return looseTimeKey(v.Time.UTC())
+	case adapter.KindFloat:
+		return looseNumberKey(v.Float)
+	default:
+		return v.Key()
+	}
+}
+
+// looseNumberKey integral values share the integer key, others are rounded to 9 digits
+func looseNumberKey(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return "n:" + strconv.FormatInt(int64(f), 10)
+	}
+	return "n:" + strconv.FormatFloat(f, 'g', 9, 64)
+}
+
+// looseTimeKey time truncated to seconds, without zone
+func looseTimeKey(t time.Time) string {
+	return "t:" + t.Truncate(time.Second).Format("2006-01-02 15:04:05")
+}
+
+// parseLooseTime parses a timestamp already stripped by normalizeTimeValue
+func parseLooseTime(s string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), true
+	}
+	return time.Time{}, false
+}
+
 // findColumnMapping finds column mapping based on column features
 // Returns mapping array: mapping[i] = j means gold column i maps to pred column j
 func findColumnMapping(result1, result2 *ExecResult) []int {
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"reactsql/internal/adapter"
+)
+
+func TestLooseValueKey(t *testing.T) {
+	ts := time.Date(2021, 3, 4, 5, 6, 7, 890000000, time.UTC)
+	equal := []struct {
+		name string
+		a, b adapter.Value
+	}{
+		{"text timestamp vs time", adapter.NewValue("2021-03-04 05:06:07"), adapter.NewValue(ts)},
+		{"text timestamp with zone vs time", adapter.NewValue("2021-03-04 05:06:07.89 +0000 UTC"), adapter.NewValue(ts)},
+		{"ISO text vs time", adapter.NewValue("2021-03-04T05:06:07Z"), adapter.NewValue(ts)},
+		{"text timestamps differing in fraction", adapter.NewValue("2021-03-04 05:06:07.1"), adapter.NewValue("2021-03-04 05:06:07.9")},
+		{"numeric text vs int", adapter.NewValue("3"), adapter.NewValue(int64(3))},
+		{"numeric text vs float", adapter.NewValue(" 2.50 "), adapter.NewValue(2.5)},
+		{"percent text vs float", adapter.NewValue("12.5%"), adapter.NewValue(12.5)},
+		{"large int vs integral float", adapter.NewValue(int64(1234567890)), adapter.NewValue(1234567890.0)},
+	}
+	for _, tc := range equal {
+		if ka, kb := looseValueKey(tc.a), looseValueKey(tc.b); ka != kb {
+			t.Errorf("%s: %q != %q", tc.name, ka, kb)
+		}
+	}
+
+	different := []struct {
+		name string
+		a, b adapter.Value
+	}{
+		{"different seconds", adapter.NewValue("2021-03-04 05:06:08"), adapter.NewValue(ts)},
+		{"text vs number", adapter.NewValue("three"), adapter.NewValue(int64(3))},
+		{"null vs empty text", adapter.NewValue(nil), adapter.NewValue("")},
+		{"NaN text stays text", adapter.NewValue("NaN"), adapter.NewValue("nan")},
+	}
+	for _, tc := range different {
+		if ka, kb := looseValueKey(tc.a), looseValueKey(tc.b); ka == kb {
+			t.Errorf("%s: both %q", tc.name, ka)
+		}
+	}
+}
+
+func TestAreResultsEquivalentTypedLooseMatch(t *testing.T) {
+	gold := &ExecResult{
+		Success: true,
+		Rows:    [][]string{{"n", "at"}, {"3", "2021-03-04 05:06:07"}},
+		Typed:   [][]adapter.Value{{adapter.NewValue("3"), adapter.NewValue("2021-03-04 05:06:07")}},
+	}
+	pred := &ExecResult{
+		Success: true,
+		Rows:    [][]string{{"n", "at"}, {"3", "2021-03-04 05:06:07.5 +0000 UTC"}},
+		Typed: [][]adapter.Value{{
+			adapter.NewValue(int64(3)),
+			adapter.NewValue(time.Date(2021, 3, 4, 5, 6, 7, 500000000, time.UTC)),
+		}},
+	}
+	if ok, reason := NewSQLAnalyzer().areResultsEquivalent(gold, pred); !ok {
+		t.Fatalf("expected loose match, got %q", reason)
+	}
+}
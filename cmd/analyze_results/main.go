@@ -42,13 +42,35 @@ type ResultDirInfo struct {
 }
 
 func main() {
+	// Sandbox worker mode (re-executed by --sandbox), returns immediately otherwise
+	adapter.SandboxMain()
+
 	// Command line flags (for non-interactive usage)
 	inputPath := flag.String("input", "", "Input file or directory path (if empty, will auto-discover)")
 	outputDir := flag.String("output", "", "Output directory (default: same as input)")
 	dbDir := flag.String("db-dir", "", "Database directory (auto-detected if not set)")
 	dbType := flag.String("db-type", "", "Database type: sqlite | postgresql (auto-detected if not set)")
+	inMemory := flag.Bool("in-memory", false, "Copy each SQLite database into memory before re-executing queries")
+	sandbox := flag.Bool("sandbox", false, "Run SQL queries in a long-lived child process per database with memory/CPU rlimits, killed and restarted on timeout")
+	sandboxMem := flag.Int("sandbox-mem", 2048, "Sandbox memory limit per query (MB)")
+	noCase := flag.Bool("nocase", false, "Compare and sort text case-insensitively (COLLATE NOCASE on text columns of an in-memory copy; the file is never modified)")
+	caseSensitiveLike := flag.Bool("case-sensitive-like", false, "Make LIKE case-sensitive (SQLite ignores ASCII case by default)")
+	encoding := flag.String("encoding", "", "Decode non-UTF8 result text from this charset: latin1 | windows-1252 (empty = leave as is)")
 	flag.Parse()
 
+	var sandboxConfig *adapter.SandboxConfig
+	if *sandbox {
+		sandboxConfig = &adapter.SandboxConfig{MaxMemoryMB: *sandboxMem}
+	}
+	var textOptions *adapter.TextOptions
+	if *noCase || *caseSensitiveLike || *encoding != "" {
+		textOptions = &adapter.TextOptions{NoCase: *noCase, CaseSensitiveLike: *caseSensitiveLike, Encoding: *encoding}
+		if err := textOptions.Validate(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 
 	// ── Step 1: Discover or use provided input ──
@@ -184,7 +206,7 @@ func main() {
 		classifiedOutputDir = filepath.Dir(selectedInput)
 	}
 
-	classifier := NewResultClassifier(classifiedOutputDir)
+	classifier := NewResultClassifier(classifiedOutputDir, detectedDBType)
 
 	// Load results
 	var inputResults []InputResult
@@ -280,6 +302,9 @@ func main() {
 			dbAdapter, err := adapter.NewAdapter(&adapter.DBConfig{
 				Type:     "sqlite",
 				FilePath: g.dbPath,
+				InMemory: *inMemory,
+				Sandbox:  sandboxConfig,
+				Text:     textOptions,
 			})
 
 			var connected bool
@@ -310,6 +335,13 @@ func main() {
 					input.PredSQL == "AMBIGUOUS_QUERY" ||
 					NormalizeSQL(input.PredSQL) == NormalizeSQL(input.GTSQL)
 
+				// Recover a stale connection instead of reporting it as a query error
+				if connected && !skipExec && !dbAdapter.Healthy(ctx) {
+					connected = false
+					skipExec = true
+					err = fmt.Errorf("database unreachable after reconnect")
+				}
+
 				if !connected {
 					errMsg := fmt.Sprintf("DB connection error: %v", err)
 					gtResult.Error = errMsg
@@ -321,8 +353,7 @@ func main() {
 					gtData, ge := dbAdapter.ExecuteQuery(execCtx, input.GTSQL)
 					gtErr = ge
 					if ge == nil {
-						gtResult.Success = true
-						gtResult.Rows = ConvertQueryResultFormat(gtData)
+						SetQueryResult(gtResult, gtData)
 					} else {
 						gtResult.Error = ge.Error()
 						if execCtx.Err() != nil {
@@ -334,8 +365,7 @@ func main() {
 						predData, pe := dbAdapter.ExecuteQuery(execCtx, input.PredSQL)
 						predErr = pe
 						if pe == nil {
-							predResult.Success = true
-							predResult.Rows = ConvertQueryResultFormat(predData)
+							SetQueryResult(predResult, predData)
 						} else {
 							predResult.Error = pe.Error()
 							if execCtx.Err() != nil {
@@ -348,8 +378,7 @@ func main() {
 						predData, pe := dbAdapter.ExecuteQuery(predCtx, input.PredSQL)
 						predErr = pe
 						if pe == nil {
-							predResult.Success = true
-							predResult.Rows = ConvertQueryResultFormat(predData)
+							SetQueryResult(predResult, predData)
 						} else {
 							predResult.Error = pe.Error()
 						}
@@ -422,6 +451,7 @@ func main() {
 	// ── Step 10: Print summary ──
 	reporter.PrintSummary(stats, len(inputResults))
 	reporter.PrintDifficultyBreakdown(analysisResults)
+	reporter.PrintPhaseBreakdown(inputResults)
 
 	// Save summary report
 	if err := reporter.GenerateSummaryReport(stats, len(inputResults)); err != nil {
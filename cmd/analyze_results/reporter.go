@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"reactsql/internal/inference"
 )
 
 // Reporter handles report generation and statistics
@@ -313,15 +315,92 @@ func (r *Reporter) PrintDifficultyBreakdown(results []*AnalysisResult) {
 	fmt.Printf("%s────────────────────────────────────────────────────────────%s\n", Bold, ColorReset)
 }
 
+// phaseOrder display order of the inference phases
+var phaseOrder = []string{"follow_up", "schema_linking", "decomposition", "generation", "clarify", "proofread", "syntax_repair", "exec_repair", "answer"}
+
+// PrintPhaseBreakdown prints average time, LLM calls and tokens per inference phase
+func (r *Reporter) PrintPhaseBreakdown(inputs []InputResult) {
+	totals := make(map[string]*PhaseCost)
+	withPhases := 0
+	totalSeconds := 0.0
+	for _, in := range inputs {
+		if len(in.Phases) == 0 {
+			continue
+		}
+		withPhases++
+		for name, pc := range in.Phases {
+			t, ok := totals[name]
+			if !ok {
+				t = &PhaseCost{}
+				totals[name] = t
+			}
+			t.Seconds += pc.Seconds
+			t.Calls += pc.Calls
+			t.LLMCalls += pc.LLMCalls
+			t.Tokens += pc.Tokens
+		}
+	}
+	if withPhases == 0 {
+		// Results without phase data (older eval runs or JSONL input)
+		return
+	}
+
+	// Unknown phases after the known ones
+	order := append([]string{}, phaseOrder...)
+	var extra []string
+	for name := range totals {
+		known := false
+		for _, p := range phaseOrder {
+			if p == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	order = append(order, extra...)
+
+	// Clarify/proofread run inside generation, leave them out of the total
+	for name, t := range totals {
+		if name != "clarify" && name != "proofread" {
+			totalSeconds += t.Seconds
+		}
+	}
+
+	n := float64(withPhases)
+	fmt.Printf("\n%s%sCost by Phase%s (avg per query, %d queries)\n", Bold, ColorPurple, ColorReset, withPhases)
+	fmt.Printf("%s────────────────────────────────────────────────────────────%s\n", Bold, ColorReset)
+	fmt.Printf("%s%-16s %10s %10s %10s %8s%s\n", Bold, "Phase", "Time", "LLM Calls", "Tokens", "Time %", ColorReset)
+	fmt.Printf("%s────────────────────────────────────────────────────────────%s\n", Bold, ColorReset)
+	for _, name := range order {
+		t, ok := totals[name]
+		if !ok {
+			continue
+		}
+		share := 0.0
+		if totalSeconds > 0 {
+			share = t.Seconds / totalSeconds * 100
+		}
+		fmt.Printf("%-16s %9.2fs %10.1f %10.0f %7.1f%%\n",
+			name, t.Seconds/n, float64(t.LLMCalls)/n, float64(t.Tokens)/n, share)
+	}
+	fmt.Printf("%s────────────────────────────────────────────────────────────%s\n", Bold, ColorReset)
+}
+
 // ResultClassifier classifies results by type and outputs to directories
 type ResultClassifier struct {
 	baseDir string
+	dialect string // SQL dialect of the results, for the formatted review files
 }
 
 // NewResultClassifier creates a new result classifier
-func NewResultClassifier(baseDir string) *ResultClassifier {
+func NewResultClassifier(baseDir, dialect string) *ResultClassifier {
 	return &ResultClassifier{
 		baseDir: baseDir,
+		dialect: dialect,
 	}
 }
 
@@ -392,6 +471,12 @@ func (rc *ResultClassifier) ClassifyAndSaveResults(results []*AnalysisResult) er
 			fmt.Printf("failed to save result %d to %s: %v\n", result.ID, filePath, err)
 			continue
 		}
+
+		// Formatted gold / predicted SQL with a line diff, for side-by-side review
+		reviewPath := strings.TrimSuffix(filePath, ".json") + ".sql"
+		if err := os.WriteFile(reviewPath, []byte(rc.formatReview(result, category)), 0644); err != nil {
+			fmt.Printf("failed to save review %d to %s: %v\n", result.ID, reviewPath, err)
+		}
 	}
 
 	return nil
@@ -435,3 +520,26 @@ func (rc *ResultClassifier) saveResultToFile(result *AnalysisResult, filePath st
 	// Write file
 	return os.WriteFile(filePath, jsonData, 0644)
 }
+
+// formatReview renders a result as pretty-printed gold and predicted SQL plus their line diff
+func (rc *ResultClassifier) formatReview(result *AnalysisResult, category string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("-- [%s] #%d (db: %s)\n", category, result.ID, result.DBName))
+	sb.WriteString(fmt.Sprintf("-- Question: %s\n", result.Question))
+	if result.ErrorReason != "" {
+		sb.WriteString(fmt.Sprintf("-- Reason: %s\n", strings.ReplaceAll(result.ErrorReason, "\n", " ")))
+	}
+
+	sb.WriteString("\n-- Gold\n")
+	sb.WriteString(inference.FormatSQL(result.GTSQL, rc.dialect))
+	sb.WriteString("\n\n-- Predicted\n")
+	sb.WriteString(inference.FormatSQL(result.PredSQL, rc.dialect))
+	sb.WriteString("\n")
+
+	if !result.IsCorrect && result.PredSQL != "" {
+		sb.WriteString("\n-- Diff (- gold, + predicted)\n")
+		sb.WriteString(inference.DiffSQL(result.GTSQL, result.PredSQL, rc.dialect))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -18,6 +18,9 @@ type SpiderResult struct {
 	LLMCalls       int      `json:"llm_calls"`
 	SelectedTables []string `json:"selected_tables"`
 	Difficulty     string   `json:"difficulty,omitempty"`
+	Thinking       string   `json:"thinking,omitempty"`
+
+	Phases map[string]PhaseCost `json:"phases,omitempty"`
 }
 
 // LoadSpiderResultFile loads Spider evaluation result file
@@ -42,6 +45,8 @@ func LoadSpiderResultFile(filePath string) ([]InputResult, error) {
 			GTSQL:      sr.GoldSQL,
 			PredSQL:    sr.GeneratedSQL,
 			Difficulty: sr.Difficulty,
+			Thinking:   sr.Thinking,
+			Phases:     sr.Phases,
 		})
 	}
 
@@ -2,6 +2,8 @@ package main
 
 import (
 	"strings"
+
+	"reactsql/internal/adapter"
 )
 
 // DBType represents supported database type
@@ -54,6 +56,16 @@ type InputResult struct {
 	Ambiguous  string `json:"ambiguous,omitempty"`
 	SPJType    string `json:"spj_type,omitempty"`    // SPJ type tag
 	Difficulty string `json:"difficulty,omitempty"` // simple/moderate/challenging
+
+	Phases map[string]PhaseCost `json:"phases,omitempty"` // Per-phase cost (eval results.json)
+}
+
+// PhaseCost time and cost of one inference phase
+type PhaseCost struct {
+	Seconds  float64 `json:"seconds"`
+	Calls    int     `json:"calls,omitempty"`
+	LLMCalls int     `json:"llm_calls"`
+	Tokens   int     `json:"tokens"`
 }
 
 // AnalysisResult represents analyzed SQL result structure
@@ -83,6 +95,9 @@ type ExecResult struct {
 	Success bool       `json:"Success"`
 	Error   string     `json:"Error"`
 	Rows    [][]string `json:"Rows"`
+
+	// Typed data rows (no header), used for comparison; not serialized
+	Typed [][]adapter.Value `json:"-"`
 }
 
 // ErrorCount for error statistics sorting
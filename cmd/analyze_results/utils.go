@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"reactsql/internal/adapter"
@@ -196,39 +195,23 @@ func ConvertResultFormat(data []map[string]interface{}) [][]string {
 }
 
 // ConvertQueryResultFormat converts adapter.QueryResult to ExecResult format
+// Reads the positional typed rows so duplicate column names keep their own values.
 func ConvertQueryResultFormat(result *adapter.QueryResult) [][]string {
-	if result == nil || len(result.Rows) == 0 {
+	if result == nil || len(result.Values) == 0 {
 		return [][]string{}
 	}
 
 	colCount := len(result.Columns)
-	rows := make([][]string, 0, len(result.Rows)+1)
+	rows := make([][]string, 0, len(result.Values)+1)
 	rows = append(rows, result.Columns) // Add header row
 
-	// Add data rows — avoid fmt.Sprintf for common types
-	for _, row := range result.Rows {
+	for _, row := range result.Values {
 		dataRow := make([]string, colCount)
-		for j, col := range result.Columns {
-			val := row[col]
-			switch v := val.(type) {
-			case string:
-				dataRow[j] = v
-			case []byte:
-				dataRow[j] = string(v)
-			case int64:
-				dataRow[j] = strconv.FormatInt(v, 10)
-			case float64:
-				dataRow[j] = strconv.FormatFloat(v, 'f', -1, 64)
-			case bool:
-				if v {
-					dataRow[j] = "true"
-				} else {
-					dataRow[j] = "false"
-				}
-			case nil:
+		for j := 0; j < colCount && j < len(row); j++ {
+			if row[j].IsNull() {
 				dataRow[j] = "<nil>"
-			default:
-				dataRow[j] = fmt.Sprintf("%v", v)
+			} else {
+				dataRow[j] = row[j].String()
 			}
 		}
 		rows = append(rows, dataRow)
@@ -236,3 +219,12 @@ func ConvertQueryResultFormat(result *adapter.QueryResult) [][]string {
 
 	return rows
 }
+
+// SetQueryResult fills a successful ExecResult from an adapter result (display rows + typed rows)
+func SetQueryResult(res *ExecResult, result *adapter.QueryResult) {
+	res.Success = true
+	res.Rows = ConvertQueryResultFormat(result)
+	if result != nil {
+		res.Typed = result.Values
+	}
+}
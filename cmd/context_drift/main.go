@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"reactsql/internal/adapter"
+	contextpkg "reactsql/internal/context"
+)
+
+// Exit codes for automation
+const (
+	exitClean = 0 // Every context matches its database
+	exitDrift = 1 // At least one context drifted
+	exitError = 2 // A context or database could not be checked (or bad usage)
+)
+
+// driftLabels structural drift kinds and how they read in the console report
+// (Validate kinds outside this list, e.g. stale Rich Context keys, are not structural)
+var driftLabels = map[string]string{
+	contextpkg.IssueMissingTable:    "dropped table",
+	contextpkg.IssueUntrackedTable:  "new table",
+	contextpkg.IssueMissingColumn:   "dropped column",
+	contextpkg.IssueUntrackedColumn: "new column",
+	contextpkg.IssueChangedType:     "changed type",
+	contextpkg.IssueStaleRowCount:   "row count changed",
+	contextpkg.IssueDanglingFK:      "dangling foreign key",
+}
+
+// databaseDrift drift of one context file
+type databaseDrift struct {
+	Context  string                       `json:"context"`
+	Database string                       `json:"database"`
+	Drift    []contextpkg.ValidationIssue `json:"drift"`
+	Error    string                       `json:"error,omitempty"`
+}
+
+// driftReport the -json output
+type driftReport struct {
+	CheckedAt    string          `json:"checked_at"`
+	RowTolerance float64         `json:"row_tolerance"`
+	Databases    []databaseDrift `json:"databases"`
+	Drifted      int             `json:"drifted"` // Contexts with drift
+	Failed       int             `json:"failed"`  // Contexts that could not be checked
+	ByKind       map[string]int  `json:"by_kind"` // Drift items per kind
+	ExitCode     int             `json:"exit_code"`
+}
+
+func main() {
+	contextPath := flag.String("context", "", "Context JSON file, or a directory of them (required)")
+	dbPath := flag.String("db", "", "SQLite database file (single context file only)")
+	dbDir := flag.String("db-dir", "", "Database directory in Spider/BIRD layout: <db-dir>/<database_name>/<database_name>.sqlite")
+	dbType := flag.String("db-type", "sqlite", "Database type: sqlite | mysql | postgresql")
+	host := flag.String("host", "localhost", "Database host (mysql/postgresql)")
+	port := flag.Int("port", 0, "Database port (mysql/postgresql; 0 = default)")
+	user := flag.String("user", "", "Database user (mysql/postgresql)")
+	password := flag.String("password", "", "Database password (mysql/postgresql)")
+	rowTolerance := flag.Float64("row-tolerance", 0.5, "Row counts differing by more than this fraction count as drift")
+	jsonOut := flag.String("json", "", "Write the report as JSON to this file (\"-\" = stdout only, no console report)")
+	flag.Parse()
+
+	if *contextPath == "" {
+		flag.Usage()
+		os.Exit(exitError)
+	}
+	files, err := contextpkg.ContextFiles(*contextPath)
+	if err != nil {
+		log.Printf("Failed to list context files: %v", err)
+		os.Exit(exitError)
+	}
+	if *dbPath != "" && len(files) > 1 {
+		log.Printf("-db names one database but %s holds %d context files; use -db-dir", *contextPath, len(files))
+		os.Exit(exitError)
+	}
+
+	// With -json -, stdout carries the JSON only
+	var console io.Writer = os.Stdout
+	if *jsonOut == "-" {
+		console = io.Discard
+	}
+	fmt.Fprintln(console, "🧭 Context Drift")
+	fmt.Fprintf(console, "📁 Context: %s (%d files)\n\n", *contextPath, len(files))
+
+	ctx := context.Background()
+	report := &driftReport{
+		CheckedAt:    time.Now().Format(time.RFC3339),
+		RowTolerance: *rowTolerance,
+		ByKind:       make(map[string]int),
+	}
+	for _, file := range files {
+		entry := checkFile(ctx, file, func(dbName string) *adapter.DBConfig {
+			config := &adapter.DBConfig{Type: *dbType, Host: *host, Port: *port, Database: dbName, User: *user, Password: *password}
+			if *dbType == "sqlite" {
+				config.FilePath = contextpkg.SQLitePath(*dbPath, *dbDir, dbName)
+			}
+			return config
+		}, *rowTolerance)
+
+		switch {
+		case entry.Error != "":
+			report.Failed++
+		case len(entry.Drift) > 0:
+			report.Drifted++
+		}
+		for _, item := range entry.Drift {
+			report.ByKind[item.Kind]++
+		}
+		printDrift(console, entry)
+		report.Databases = append(report.Databases, entry)
+	}
+
+	report.ExitCode = exitClean
+	if report.Drifted > 0 {
+		report.ExitCode = exitDrift
+	}
+	if report.Failed > 0 {
+		report.ExitCode = exitError
+	}
+
+	fmt.Fprintln(console, "═══════════════════════════════════════")
+	fmt.Fprintf(console, "Contexts: %d, drifted: %d, failed: %d\n", len(files), report.Drifted, report.Failed)
+	kinds := make([]string, 0, len(report.ByKind))
+	for kind := range report.ByKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(console, "  %-22s %d\n", driftLabels[kind], report.ByKind[kind])
+	}
+
+	if *jsonOut != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Printf("Failed to encode report: %v", err)
+			os.Exit(exitError)
+		}
+		if *jsonOut == "-" {
+			fmt.Println(string(data))
+		} else if err := os.WriteFile(*jsonOut, data, 0644); err != nil {
+			log.Printf("Failed to write %s: %v", *jsonOut, err)
+			os.Exit(exitError)
+		} else {
+			fmt.Fprintf(console, "\n💾 Report saved to %s\n", *jsonOut)
+		}
+	}
+	os.Exit(report.ExitCode)
+}
+
+// checkFile compares one context file with its live database (structural drift only)
+func checkFile(ctx context.Context, file string, configFor func(dbName string) *adapter.DBConfig, rowTolerance float64) databaseDrift {
+	entry := databaseDrift{Context: file, Drift: []contextpkg.ValidationIssue{}}
+
+	sharedCtx, err := contextpkg.LoadContextFromFile(file)
+	if err != nil {
+		entry.Error = fmt.Sprintf("failed to load: %v", err)
+		return entry
+	}
+	sharedCtx.Quiet = true
+	entry.Database = sharedCtx.DatabaseName
+
+	config := configFor(sharedCtx.DatabaseName)
+	if config.Type == "sqlite" {
+		if config.FilePath == "" {
+			entry.Error = "SQLite needs -db or -db-dir"
+			return entry
+		}
+		if _, err := os.Stat(config.FilePath); err != nil {
+			entry.Error = fmt.Sprintf("database file not found: %s", config.FilePath)
+			return entry
+		}
+	}
+	dbAdapter, err := adapter.NewAdapter(config)
+	if err != nil {
+		entry.Error = fmt.Sprintf("failed to create adapter: %v", err)
+		return entry
+	}
+	if err := dbAdapter.Connect(ctx); err != nil {
+		entry.Error = fmt.Sprintf("failed to connect: %v", err)
+		return entry
+	}
+	defer dbAdapter.Close()
+
+	issues, err := sharedCtx.Validate(ctx, dbAdapter, rowTolerance, false)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	for _, issue := range issues {
+		if _, structural := driftLabels[issue.Kind]; structural {
+			entry.Drift = append(entry.Drift, issue)
+		}
+	}
+	return entry
+}
+
+// printDrift prints one context's drift grouped by table
+func printDrift(w io.Writer, entry databaseDrift) {
+	switch {
+	case entry.Error != "":
+		fmt.Fprintf(w, "❌ %s (%s): %s\n\n", entry.Context, entry.Database, entry.Error)
+		return
+	case len(entry.Drift) == 0:
+		fmt.Fprintf(w, "✅ %s (%s): no drift\n\n", entry.Context, entry.Database)
+		return
+	}
+
+	fmt.Fprintf(w, "📄 %s (%s): %d changes\n", entry.Context, entry.Database, len(entry.Drift))
+	lastTable := ""
+	for _, item := range entry.Drift {
+		if item.Table != lastTable {
+			fmt.Fprintf(w, "  %s\n", item.Table)
+			lastTable = item.Table
+		}
+		subject := ""
+		if item.Column != "" {
+			subject = item.Column + ": "
+		}
+		fmt.Fprintf(w, "    %-22s %s%s\n", driftLabels[item.Kind], subject, item.Detail)
+	}
+	fmt.Fprintln(w)
+}
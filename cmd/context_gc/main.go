@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	contextpkg "reactsql/internal/context"
+)
+
+func main() {
+	contextPath := flag.String("context", "", "Context JSON file, or a directory of them (required)")
+	grace := flag.Duration("grace", 0, "Keep notes that expired less than this long ago (e.g. 72h)")
+	dryRun := flag.Bool("dry-run", false, "List expired notes without rewriting any file")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: context_gc -context <file.json|dir> [-grace 72h] [-dry-run]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *contextPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files, err := contextpkg.ContextFiles(*contextPath)
+	if err != nil {
+		log.Fatalf("Failed to list context files: %v", err)
+	}
+
+	cutoff := time.Now().Add(-*grace)
+	total := 0
+	for _, path := range files {
+		ctx, err := contextpkg.LoadContextFromFile(path)
+		if err != nil {
+			fmt.Printf("⚠️  Skipping %s: %v\n", path, err)
+			continue
+		}
+
+		expired := ctx.ExpiredNotes(cutoff)
+		if len(expired) == 0 {
+			continue
+		}
+		total += len(expired)
+
+		fmt.Printf("🗑️  %s: %d expired notes\n", path, len(expired))
+		for _, name := range expired {
+			fmt.Printf("    - %s\n", name)
+		}
+		if *dryRun {
+			continue
+		}
+
+		ctx.PruneExpiredNotes(cutoff)
+		if err := ctx.SaveToFile(path); err != nil {
+			log.Fatalf("Failed to save %s: %v", path, err)
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("\n🔍 Dry run: %d expired notes in %d context files (nothing removed)\n", total, len(files))
+		return
+	}
+	fmt.Printf("\n✅ Removed %d expired notes from %d context files\n", total, len(files))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	contextpkg "reactsql/internal/context"
+)
+
+func main() {
+	basePath := flag.String("context", "", "Context JSON file of the main database (required)")
+	outputPath := flag.String("output", "", "Merged context JSON file (required)")
+	prefixBase := flag.String("prefix", "", "Table prefix for the main database (default: none, as for the main SQLite schema)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: context_merge -context <main.json> -output <merged.json> alias=<other.json> ...")
+		fmt.Fprintln(os.Stderr, "\nEach alias should match the SQLite ATTACH alias (DBConfig.Attach) the database is queried under.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *basePath == "" || *outputPath == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	base, err := contextpkg.LoadContextFromFile(*basePath)
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", *basePath, err)
+	}
+
+	merged := base
+	if *prefixBase != "" {
+		merged = contextpkg.NewSharedContext(base.DatabaseName, base.DatabaseType)
+		if err := merged.Merge(base, *prefixBase); err != nil {
+			log.Fatalf("Failed to merge %s: %v", *basePath, err)
+		}
+	}
+
+	for _, arg := range flag.Args() {
+		alias, path, ok := strings.Cut(arg, "=")
+		if !ok || alias == "" || path == "" {
+			log.Fatalf("Expected alias=<context.json>, got %q", arg)
+		}
+		other, err := contextpkg.LoadContextFromFile(path)
+		if err != nil {
+			log.Fatalf("Failed to load %s: %v", path, err)
+		}
+		if err := merged.Merge(other, alias); err != nil {
+			log.Fatalf("Failed to merge %s: %v", path, err)
+		}
+	}
+
+	if err := merged.SaveToFile(*outputPath); err != nil {
+		log.Fatalf("Failed to save %s: %v", *outputPath, err)
+	}
+	fmt.Printf("🔗 Merged %d databases into %s (%d tables)\n", flag.NArg()+1, *outputPath, len(merged.Tables))
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"reactsql/internal/adapter"
+	contextpkg "reactsql/internal/context"
+	"reactsql/internal/inference"
+)
+
+// devEntry one dev-set question (Spider "query", BIRD "SQL")
+type devEntry struct {
+	DBID  string `json:"db_id"`
+	Query string `json:"query"`
+	SQL   string `json:"SQL"`
+}
+
+// coverage hits out of the schema elements the gold SQL needs (one count per question)
+type coverage struct {
+	Covered int     `json:"covered"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+func (c *coverage) add(hit bool) {
+	c.Total++
+	if hit {
+		c.Covered++
+	}
+}
+
+func (c *coverage) finish() {
+	c.Percent = 100
+	if c.Total > 0 {
+		c.Percent = float64(c.Covered) * 100 / float64(c.Total)
+	}
+}
+
+// dbScore coverage of one database's context
+type dbScore struct {
+	Database  string   `json:"database"`
+	Questions int      `json:"questions"`
+	Skipped   int      `json:"skipped,omitempty"` // Gold SQL that failed to resolve
+	Columns   coverage `json:"columns"`           // Referenced columns that are described
+	Values    coverage `json:"values"`            // Compared literals that are enumerated
+	Joins     coverage `json:"joins"`             // Join conditions captured as FK or join path
+	Overall   coverage `json:"overall"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// gap schema element the context misses, with the number of questions needing it
+type gap struct {
+	Kind      string `json:"kind"` // "column" | "value" | "join"
+	Database  string `json:"database"`
+	Item      string `json:"item"`
+	Questions int    `json:"questions"`
+}
+
+// scoreReport the -json output
+type scoreReport struct {
+	Databases []*dbScore `json:"databases"`
+	Total     *dbScore   `json:"total"`
+	Gaps      []gap      `json:"gaps"`
+}
+
+func main() {
+	devFile := flag.String("dev", "", "Dev set JSON with db_id and gold SQL (\"query\" or \"SQL\") (required)")
+	contextDir := flag.String("context-dir", "", "Directory of <db_id>.json context files (required)")
+	dbDir := flag.String("db-dir", "", "Database directory in Spider/BIRD layout: <db-dir>/<database_name>/<database_name>.sqlite (required)")
+	jsonOut := flag.String("json", "", "Also write the full report as JSON to this file")
+	showGaps := flag.Int("gaps", 20, "Most needed missing columns / values / joins to list")
+	flag.Parse()
+
+	if *devFile == "" || *contextDir == "" || *dbDir == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*devFile)
+	if err != nil {
+		log.Fatalf("Failed to read dev file: %v", err)
+	}
+	var entries []devEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.Fatalf("Failed to parse dev file: %v", err)
+	}
+	byDB := make(map[string][]string)
+	for _, entry := range entries {
+		sql := entry.Query
+		if sql == "" {
+			sql = entry.SQL
+		}
+		byDB[entry.DBID] = append(byDB[entry.DBID], sql)
+	}
+	databases := make([]string, 0, len(byDB))
+	for db := range byDB {
+		databases = append(databases, db)
+	}
+	sort.Strings(databases)
+
+	fmt.Println("📏 Context Coverage")
+	fmt.Printf("📁 Dev: %s (%d questions, %d databases)\n", *devFile, len(entries), len(databases))
+	fmt.Printf("📁 Context: %s\n\n", *contextDir)
+
+	ctx := context.Background()
+	report := &scoreReport{Total: &dbScore{Database: "TOTAL"}}
+	needed := make(map[gap]int)
+	for _, db := range databases {
+		score := scoreDatabase(ctx, db, byDB[db], *contextDir, *dbDir, needed)
+		report.Databases = append(report.Databases, score)
+		if score.Error != "" {
+			continue
+		}
+		for _, pair := range [][2]*coverage{
+			{&report.Total.Columns, &score.Columns},
+			{&report.Total.Values, &score.Values},
+			{&report.Total.Joins, &score.Joins},
+			{&report.Total.Overall, &score.Overall},
+		} {
+			pair[0].Covered += pair[1].Covered
+			pair[0].Total += pair[1].Total
+		}
+		report.Total.Questions += score.Questions
+		report.Total.Skipped += score.Skipped
+	}
+	for _, c := range []*coverage{&report.Total.Columns, &report.Total.Values, &report.Total.Joins, &report.Total.Overall} {
+		c.finish()
+	}
+
+	for g, count := range needed {
+		g.Questions = count
+		report.Gaps = append(report.Gaps, g)
+	}
+	sort.Slice(report.Gaps, func(i, j int) bool {
+		a, b := report.Gaps[i], report.Gaps[j]
+		if a.Questions != b.Questions {
+			return a.Questions > b.Questions
+		}
+		if a.Database != b.Database {
+			return a.Database < b.Database
+		}
+		return a.Item < b.Item
+	})
+
+	printReport(report, *showGaps)
+
+	if *jsonOut != "" {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode report: %v", err)
+		}
+		if err := os.WriteFile(*jsonOut, out, 0644); err != nil {
+			log.Fatalf("Failed to write %s: %v", *jsonOut, err)
+		}
+		fmt.Printf("\n💾 Report saved to %s\n", *jsonOut)
+	}
+}
+
+// scoreDatabase scores one context against the gold SQL of its questions
+// Every element the context misses is counted into needed.
+func scoreDatabase(ctx context.Context, db string, queries []string, contextDir, dbDir string, needed map[gap]int) *dbScore {
+	score := &dbScore{Database: db, Questions: len(queries)}
+
+	sharedCtx, err := contextpkg.LoadContextWithOverrides(filepath.Join(contextDir, db+".json"))
+	if err != nil {
+		score.Error = fmt.Sprintf("failed to load context: %v", err)
+		return score
+	}
+	sharedCtx.Quiet = true
+
+	dbAdapter, err := adapter.NewAdapter(&adapter.DBConfig{
+		Type:     "sqlite",
+		FilePath: filepath.Join(dbDir, db, db+".sqlite"),
+	})
+	if err != nil {
+		score.Error = fmt.Sprintf("failed to create adapter: %v", err)
+		return score
+	}
+	if err := dbAdapter.Connect(ctx); err != nil {
+		score.Error = fmt.Sprintf("failed to connect: %v", err)
+		return score
+	}
+	defer dbAdapter.Close()
+
+	for _, sql := range queries {
+		usage, err := inference.AnalyzeGoldSQL(ctx, dbAdapter, sql)
+		if err != nil {
+			score.Skipped++
+			continue
+		}
+
+		for table, columns := range usage.Columns {
+			for _, column := range columns {
+				hit := sharedCtx.DescribesColumn(table, column)
+				score.Columns.add(hit)
+				if !hit {
+					needed[gap{Kind: "column", Database: db, Item: table + "." + column}]++
+				}
+			}
+		}
+		for _, value := range usage.Values {
+			hit := sharedCtx.EnumeratesValue(value.Table, value.Column, value.Value)
+			score.Values.add(hit)
+			if !hit {
+				needed[gap{Kind: "value", Database: db, Item: fmt.Sprintf("%s.%s = '%s'", value.Table, value.Column, value.Value)}]++
+			}
+		}
+		for _, join := range usage.Joins {
+			hit := sharedCtx.CapturesJoin(join.Table, join.Column, join.RefTable, join.RefColumn)
+			score.Joins.add(hit)
+			if !hit {
+				needed[gap{Kind: "join", Database: db, Item: fmt.Sprintf("%s.%s = %s.%s", join.Table, join.Column, join.RefTable, join.RefColumn)}]++
+			}
+		}
+	}
+
+	score.Overall = coverage{
+		Covered: score.Columns.Covered + score.Values.Covered + score.Joins.Covered,
+		Total:   score.Columns.Total + score.Values.Total + score.Joins.Total,
+	}
+	for _, c := range []*coverage{&score.Columns, &score.Values, &score.Joins, &score.Overall} {
+		c.finish()
+	}
+	return score
+}
+
+// printReport prints the per-database table, the totals and the most needed gaps
+func printReport(report *scoreReport, showGaps int) {
+	fmt.Printf("%-32s %6s %14s %14s %14s %9s\n", "Database", "Qs", "Columns", "Values", "Joins", "Overall")
+	row := func(s *dbScore) {
+		cell := func(c coverage) string {
+			if c.Total == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%d/%d %3.0f%%", c.Covered, c.Total, c.Percent)
+		}
+		fmt.Printf("%-32s %6d %14s %14s %14s %8.1f%%\n", s.Database, s.Questions,
+			cell(s.Columns), cell(s.Values), cell(s.Joins), s.Overall.Percent)
+	}
+	for _, s := range report.Databases {
+		if s.Error != "" {
+			fmt.Printf("%-32s ❌ %s\n", s.Database, s.Error)
+			continue
+		}
+		row(s)
+	}
+	fmt.Println("═══════════════════════════════════════")
+	row(report.Total)
+	if report.Total.Skipped > 0 {
+		fmt.Printf("⚠️  %d gold queries could not be resolved and were skipped\n", report.Total.Skipped)
+	}
+
+	if showGaps > 0 && len(report.Gaps) > 0 {
+		fmt.Printf("\nMost needed gaps (questions affected):\n")
+		for i, g := range report.Gaps {
+			if i == showGaps {
+				fmt.Printf("  ... and %d more\n", len(report.Gaps)-showGaps)
+				break
+			}
+			fmt.Printf("  %4d  %-6s %s: %s\n", g.Questions, g.Kind, g.Database, g.Item)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	contextpkg "reactsql/internal/context"
+)
+
+func main() {
+	storePath := flag.String("store", "", "Context store file (SQLite; created if missing) (required)")
+	importPath := flag.String("import", "", "JSON context file to copy into the store")
+	exportPath := flag.String("export", "", "JSON context file to write from the store")
+	list := flag.Bool("list", false, "List the stored tables")
+	flag.Parse()
+
+	if *storePath == "" || (*importPath == "" && *exportPath == "" && !*list) {
+		fmt.Fprintln(os.Stderr, "Need -store and one of -import, -export or -list")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	store, err := contextpkg.OpenContextStore(*storePath)
+	if err != nil {
+		log.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	if *importPath != "" {
+		c, err := store.ImportFile(*importPath)
+		if err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+		fmt.Printf("📥 Imported %s into %s (%d tables)\n", *importPath, *storePath, len(c.Tables))
+	}
+
+	if *exportPath != "" {
+		c, err := store.ExportFile(*exportPath)
+		if err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		fmt.Printf("📤 Exported %s to %s (%d tables)\n", *storePath, *exportPath, len(c.Tables))
+	}
+
+	if *list {
+		c, err := store.Load()
+		if err != nil {
+			log.Fatalf("Failed to load store: %v", err)
+		}
+		names, err := store.TableNames()
+		if err != nil {
+			log.Fatalf("Failed to list tables: %v", err)
+		}
+		fmt.Printf("📦 %s: database %s, %d tables, %d rows\n", *storePath, c.DatabaseName, len(names), c.TotalRows)
+		for _, name := range names {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"reactsql/internal/adapter"
+	contextpkg "reactsql/internal/context"
+)
+
+// issueIcons report marker per issue kind (removals need attention, additions are informational)
+var issueIcons = map[string]string{
+	contextpkg.IssueMissingTable:     "❌",
+	contextpkg.IssueMissingColumn:    "❌",
+	contextpkg.IssueDanglingFK:       "❌",
+	contextpkg.IssueStaleRichContext: "⚠️ ",
+	contextpkg.IssueStaleRowCount:    "⚠️ ",
+	contextpkg.IssueChangedType:      "⚠️ ",
+	contextpkg.IssueUntrackedTable:   "➕",
+	contextpkg.IssueUntrackedColumn:  "➕",
+}
+
+func main() {
+	contextPath := flag.String("context", "", "Context JSON file, or a directory of them (required)")
+	dbPath := flag.String("db", "", "SQLite database file (single context file only)")
+	dbDir := flag.String("db-dir", "", "Database directory in Spider/BIRD layout: <db-dir>/<database_name>/<database_name>.sqlite")
+	dbType := flag.String("db-type", "sqlite", "Database type: sqlite | mysql | postgresql")
+	host := flag.String("host", "localhost", "Database host (mysql/postgresql)")
+	port := flag.Int("port", 0, "Database port (mysql/postgresql; 0 = default)")
+	user := flag.String("user", "", "Database user (mysql/postgresql)")
+	password := flag.String("password", "", "Database password (mysql/postgresql)")
+	rowTolerance := flag.Float64("row-tolerance", 0.1, "Row counts differing by more than this fraction are stale")
+	fix := flag.Bool("fix", false, "Prune vanished tables/columns/keys, add new ones and refresh column types and row counts, then save the context file")
+	flag.Parse()
+
+	if *contextPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files, err := contextpkg.ContextFiles(*contextPath)
+	if err != nil {
+		log.Fatalf("Failed to list context files: %v", err)
+	}
+	if *dbPath != "" && len(files) > 1 {
+		log.Fatalf("-db names one database but %s holds %d context files; use -db-dir", *contextPath, len(files))
+	}
+
+	fmt.Println("🔍 Context Validation")
+	fmt.Printf("📁 Context: %s (%d files)\n", *contextPath, len(files))
+	if *fix {
+		fmt.Println("🔧 Fix mode: context files will be rewritten")
+	}
+	fmt.Println()
+
+	ctx := context.Background()
+	totals := make(map[string]int)
+	failed, dirty := 0, 0
+	for _, file := range files {
+		sharedCtx, err := contextpkg.LoadContextFromFile(file)
+		if err != nil {
+			fmt.Printf("❌ %s: failed to load: %v\n\n", file, err)
+			failed++
+			continue
+		}
+		sharedCtx.Quiet = true
+
+		config := &adapter.DBConfig{
+			Type:     *dbType,
+			Host:     *host,
+			Port:     *port,
+			Database: sharedCtx.DatabaseName,
+			User:     *user,
+			Password: *password,
+		}
+		if *dbType == "sqlite" {
+			config.FilePath = contextpkg.SQLitePath(*dbPath, *dbDir, sharedCtx.DatabaseName)
+			if config.FilePath == "" {
+				log.Fatalf("SQLite needs -db or -db-dir")
+			}
+		}
+
+		issues, err := validateFile(ctx, sharedCtx, config, *rowTolerance, *fix)
+		if err != nil {
+			fmt.Printf("❌ %s (%s): %v\n\n", file, sharedCtx.DatabaseName, err)
+			failed++
+			continue
+		}
+
+		printReport(file, sharedCtx.DatabaseName, issues)
+		for _, issue := range issues {
+			totals[issue.Kind]++
+		}
+		if len(issues) == 0 {
+			continue
+		}
+		dirty++
+
+		if *fix {
+			if err := sharedCtx.SaveToFile(file); err != nil {
+				fmt.Printf("❌ Failed to save %s: %v\n\n", file, err)
+				failed++
+				continue
+			}
+			fmt.Printf("💾 Fixed and saved %s\n\n", file)
+		}
+	}
+
+	// Summary
+	fmt.Println("═══════════════════════════════════════")
+	fmt.Printf("Files: %d, with issues: %d, failed: %d\n", len(files), dirty, failed)
+	kinds := make([]string, 0, len(totals))
+	for kind := range totals {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Printf("  %s %-22s %d\n", issueIcons[kind], kind, totals[kind])
+	}
+	if dirty > 0 && !*fix {
+		fmt.Println("\nRun with -fix to repair the context files in place.")
+	}
+
+	if failed > 0 || (dirty > 0 && !*fix) {
+		os.Exit(1)
+	}
+}
+
+// validateFile connects to the context's database and validates the context against it
+func validateFile(ctx context.Context, sharedCtx *contextpkg.SharedContext, config *adapter.DBConfig, rowTolerance float64, fix bool) ([]contextpkg.ValidationIssue, error) {
+	if config.FilePath != "" {
+		if _, err := os.Stat(config.FilePath); err != nil {
+			return nil, fmt.Errorf("database file not found: %s", config.FilePath)
+		}
+	}
+	dbAdapter, err := adapter.NewAdapter(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+	if err := dbAdapter.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer dbAdapter.Close()
+
+	return sharedCtx.Validate(ctx, dbAdapter, rowTolerance, fix)
+}
+
+// printReport prints one file's issues grouped by table
+func printReport(file, dbName string, issues []contextpkg.ValidationIssue) {
+	if len(issues) == 0 {
+		fmt.Printf("✅ %s (%s): up to date\n\n", file, dbName)
+		return
+	}
+
+	fmt.Printf("📄 %s (%s): %d issues\n", file, dbName, len(issues))
+	lastTable := ""
+	for _, issue := range issues {
+		if issue.Table != lastTable {
+			fmt.Printf("  %s\n", issue.Table)
+			lastTable = issue.Table
+		}
+		subject := issue.Column
+		if issue.Key != "" {
+			subject = issue.Key
+		}
+		if subject != "" {
+			subject += ": "
+		}
+		fmt.Printf("    %s %-22s %s%s\n", issueIcons[issue.Kind], issue.Kind, subject, strings.TrimSpace(issue.Detail))
+	}
+	fmt.Println()
+}
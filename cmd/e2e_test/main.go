@@ -8,7 +8,9 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"reactsql/internal/adapter"
 	"reactsql/internal/agent"
@@ -98,6 +100,11 @@ func main() {
 	modelType := flag.String("model", "deepseek-v3", "Model type (only used with --with-llm)")
 	regenRC := flag.Bool("regen-rc", false, "Regenerate Rich Context from scratch (requires LLM)")
 	showPrompt := flag.Bool("show-prompt", true, "Show the full SQL generation prompt")
+	sampleRows := flag.Int("sample-rows", 3, "Example rows to show and store per table (PII-masked, 0 = skip)")
+	interactive := flag.Bool("interactive", false, "Let the agent ask clarification questions on stdin (with --with-llm)")
+	qcWorkers := flag.Int("qc-workers", 4, "Tables quality-checked at once")
+	thresholdsFile := flag.String("qc-thresholds", "", "JSON file with quality check thresholds (empty = defaults)")
+	qcTimeout := flag.Duration("qc-timeout", 10*time.Second, "Limit per quality check query (0 = none)")
 	flag.Parse()
 
 	header("End-to-End Pipeline Visualization")
@@ -107,6 +114,16 @@ func main() {
 	info("With LLM:", fmt.Sprintf("%v", *withLLM))
 	info("Regen RC:", fmt.Sprintf("%v", *regenRC))
 
+	var thresholds *contextpkg.QualityThresholds
+	if *thresholdsFile != "" {
+		loaded, err := contextpkg.LoadQualityThresholds(*thresholdsFile)
+		if err != nil {
+			log.Fatalf("Failed to load quality thresholds: %v", err)
+		}
+		thresholds = loaded
+		info("QC thresholds:", *thresholdsFile)
+	}
+
 	// ── Resolve paths ──
 	var devFile, dbDir, contextDir string
 	switch *benchmark {
@@ -154,7 +171,7 @@ func main() {
 
 	if *regenRC {
 		header("Phase: Rich Context Generation (fresh)")
-		sharedCtx, err = regenerateRC(ctx, *dbName, dbDir, *modelType, dbAdapter)
+		sharedCtx, err = regenerateRC(ctx, *dbName, dbDir, *modelType, *sampleRows, thresholds, dbAdapter)
 		if err != nil {
 			log.Fatalf("RC generation failed: %v", err)
 		}
@@ -184,13 +201,20 @@ func main() {
 
 	// ── Step 2.5: Run QualityChecker (deterministic, always fresh) ──
 	if sharedCtx != nil {
+		sharedCtx.QualityThresholds = thresholds
 		header("Phase: Deterministic Quality Check (Phase 1.5)")
-		for tableName := range sharedCtx.Tables {
-			qc := contextpkg.NewQualityChecker(dbAdapter, sharedCtx, tableName)
-			if err := qc.RunAll(ctx); err != nil {
-				warn(fmt.Sprintf("QualityChecker failed for %s: %v", tableName, err))
-			}
-		}
+		report := contextpkg.RunQualityChecks(ctx, dbAdapter, sharedCtx, contextpkg.QualityBatchOptions{
+			Concurrency:  *qcWorkers,
+			CheckTimeout: *qcTimeout,
+			Progress: func(p contextpkg.QualityProgress) {
+				fmt.Printf("  %s[%d/%d] %s: %d issues (%s)%s\n", dim, p.Done, p.Total, p.Result.Table,
+					p.Result.Issues, p.Result.Duration.Round(time.Millisecond), reset)
+				if p.Result.Error != "" {
+					warn(fmt.Sprintf("QualityChecker failed for %s: %s", p.Result.Table, p.Result.Error))
+				}
+			},
+		})
+		info("Quality check:", report.Headline())
 		// Show quality issues
 		totalIssues := 0
 		for tableName, table := range sharedCtx.Tables {
@@ -228,6 +252,33 @@ func main() {
 		}
 	}
 
+	// ── Step 2.6: Show masked sample rows ──
+	if sharedCtx != nil && *sampleRows > 0 {
+		header("Phase: Sample Rows (PII-masked)")
+		tableNames := make([]string, 0, len(sharedCtx.Tables))
+		for name := range sharedCtx.Tables {
+			tableNames = append(tableNames, name)
+		}
+		sort.Strings(tableNames)
+		for _, tableName := range tableNames {
+			result, err := dbAdapter.SampleRows(ctx, tableName, *sampleRows, adapter.DefaultMaskingRules())
+			if err != nil {
+				warn(fmt.Sprintf("SampleRows failed for %s: %v", tableName, err))
+				continue
+			}
+			var sb strings.Builder
+			sb.WriteString(strings.Join(result.Columns, " | ") + "\n")
+			for _, row := range result.Rows {
+				vals := make([]string, len(result.Columns))
+				for i, col := range result.Columns {
+					vals[i] = fmt.Sprintf("%v", row[col])
+				}
+				sb.WriteString(strings.Join(vals, " | ") + "\n")
+			}
+			codeBlock(tableName, sb.String())
+		}
+	}
+
 	// ── Step 3: Show Rich Context (Compact Prompt) ──
 	if sharedCtx != nil {
 		header("Phase: Exported Rich Context (Compact Prompt)")
@@ -309,7 +360,7 @@ func main() {
 
 		if *withLLM {
 			// Actually run the full pipeline
-			runWithLLM(ctx, question, sharedCtx, dbAdapter, *benchmark, *dbName, *modelType, goldSQLs[i])
+			runWithLLM(ctx, question, sharedCtx, dbAdapter, *benchmark, *dbName, *modelType, goldSQLs[i], *interactive)
 		} else {
 			// Show the prompt that would be sent to the LLM (all tables selected)
 			showMockPrompt(question, sharedCtx, *benchmark, crossTableSummary)
@@ -327,8 +378,10 @@ func main() {
 // RC regeneration
 // ─────────────────────────────────────────────────────
 
-func regenerateRC(ctx context.Context, dbName, dbDir, modelType string, dbAdapter adapter.DBAdapter) (*contextpkg.SharedContext, error) {
+func regenerateRC(ctx context.Context, dbName, dbDir, modelType string, sampleRows int, thresholds *contextpkg.QualityThresholds, dbAdapter adapter.DBAdapter) (*contextpkg.SharedContext, error) {
 	sharedCtx := contextpkg.NewSharedContext(dbName, "sqlite")
+	sharedCtx.SampleRowCount = sampleRows
+	sharedCtx.QualityThresholds = thresholds
 
 	// Load schema.sql if available
 	schemaPath := filepath.Join(dbDir, dbName, "schema.sql")
@@ -391,7 +444,7 @@ func regenerateRC(ctx context.Context, dbName, dbDir, modelType string, dbAdapte
 // Run with real LLM
 // ─────────────────────────────────────────────────────
 
-func runWithLLM(ctx context.Context, question string, sharedCtx *contextpkg.SharedContext, dbAdapter adapter.DBAdapter, benchmark, dbName, modelType, goldSQL string) {
+func runWithLLM(ctx context.Context, question string, sharedCtx *contextpkg.SharedContext, dbAdapter adapter.DBAdapter, benchmark, dbName, modelType, goldSQL string, interactive bool) {
 	model := parseModelType(modelType)
 	llmInstance, err := llm.CreateLLMByType(model)
 	if err != nil {
@@ -420,9 +473,16 @@ func runWithLLM(ctx context.Context, question string, sharedCtx *contextpkg.Shar
 		Benchmark:      benchmark,
 	}
 
+	if interactive {
+		config.ClarifyMode = "on"
+	}
+
 	pipeline := inference.NewPipeline(llmInstance, dbAdapter, config)
 	logger := inference.NewInferenceLogger()
 	pipeline.SetLogger(logger)
+	if interactive {
+		pipeline.SetClarifyBackend(inference.NewStdinClarifyBackend(nil, nil))
+	}
 
 	result, err := pipeline.Execute(ctx, question)
 	if err != nil {
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"reactsql/internal/adapter"
+	"reactsql/internal/inference"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// evalCache adapter and pipeline of the current database, reused by consecutive examples
+// Building a pipeline loads the context, indexes and ReAct executor; benchmark examples
+// are mostly grouped by database, so most examples reuse the previous one's.
+type evalCache struct {
+	dbTemplate *adapter.DBConfig
+	logger     *inference.InferenceLogger
+
+	dbPath   string
+	adapter  adapter.DBAdapter
+	config   *inference.Config
+	pipeline *inference.Pipeline
+}
+
+// newEvalCache creates an empty cache
+func newEvalCache(dbTemplate *adapter.DBConfig, logger *inference.InferenceLogger) *evalCache {
+	return &evalCache{dbTemplate: dbTemplate, logger: logger}
+}
+
+// open returns a connected adapter for dbPath, reusing the open one for the same file
+func (c *evalCache) open(ctx context.Context, dbPath string) (adapter.DBAdapter, error) {
+	if c.adapter != nil && c.dbPath == dbPath {
+		if c.adapter.Healthy(ctx) {
+			return c.adapter, nil
+		}
+	}
+	c.close()
+
+	dbConfig := *c.dbTemplate
+	dbConfig.FilePath = dbPath
+	dbAdapter, err := adapter.NewAdapter(&dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create adapter: %w", err)
+	}
+	// A fresh Connect already pinged; Healthy is only worth its round-trip before reuse
+	if err := dbAdapter.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("connect db: %w", err)
+	}
+	c.dbPath = dbPath
+	c.adapter = dbAdapter
+	return dbAdapter, nil
+}
+
+// pipelineFor returns the pipeline for config on the open adapter
+// The cached pipeline is reused when only per-example fields differ; those are copied
+// into its config. Non-persisted proofread corrections live in the pipeline's context,
+// so that mode gets a fresh pipeline per example to keep examples independent.
+func (c *evalCache) pipelineFor(llm llms.Model, config *inference.Config) *inference.Pipeline {
+	if c.pipeline != nil && sameEvalDB(c.config, config) && !(config.EnableProofread && !config.PersistProofread) {
+		c.config.ResultFields = config.ResultFields
+		c.config.ResultFieldsDescription = config.ResultFieldsDescription
+		c.config.Evidence = config.Evidence
+		return c.pipeline
+	}
+
+	c.config = config
+	c.pipeline = inference.NewPipeline(llm, c.adapter, config)
+	if c.logger != nil {
+		c.pipeline.SetLogger(c.logger)
+	}
+	return c.pipeline
+}
+
+// sameEvalDB reports whether a and b only differ in per-example fields
+// Every field the pipeline is built from is compared explicitly; a Config field missing
+// here must be added when cmd/eval starts setting it per example or per run.
+func sameEvalDB(a, b *inference.Config) bool {
+	return a.UseRichContext == b.UseRichContext &&
+		a.UseReact == b.UseReact &&
+		a.ReactLinking == b.ReactLinking &&
+		a.ColumnLinking == b.ColumnLinking &&
+		a.UseDryRun == b.UseDryRun &&
+		a.MaxIterations == b.MaxIterations &&
+		a.ContextFile == b.ContextFile &&
+		a.LazyContext == b.LazyContext &&
+		a.SkipExpiredNotes == b.SkipExpiredNotes &&
+		a.RefreshQuality == b.RefreshQuality &&
+		a.ClarifyMode == b.ClarifyMode &&
+		a.LogMode == b.LogMode &&
+		a.EnableProofread == b.EnableProofread &&
+		a.PersistProofread == b.PersistProofread &&
+		a.DBName == b.DBName &&
+		a.DBType == b.DBType &&
+		a.GenerationDialect == b.GenerationDialect &&
+		slices.Equal(a.EmitDialects, b.EmitDialects) &&
+		a.Style == b.Style &&
+		a.FewShot == b.FewShot &&
+		a.FewShotK == b.FewShotK &&
+		a.LinkCache == b.LinkCache &&
+		a.ValueHints == b.ValueHints &&
+		a.CanonicalValues == b.CanonicalValues &&
+		a.Decompose == b.Decompose &&
+		a.Candidates == b.Candidates &&
+		a.MaxPromptTokens == b.MaxPromptTokens &&
+		a.MaxTableTokens == b.MaxTableTokens &&
+		a.MaxEnumValues == b.MaxEnumValues &&
+		a.PruneSchemaTokens == b.PruneSchemaTokens &&
+		a.ColumnEmbedder == b.ColumnEmbedder &&
+		a.ExecRepairAttempts == b.ExecRepairAttempts &&
+		a.JSONAnswer == b.JSONAnswer &&
+		a.NLAnswer == b.NLAnswer &&
+		a.MaxDuration == b.MaxDuration &&
+		slices.Equal(a.RetryTemperatures, b.RetryTemperatures) &&
+		a.WriteMode == b.WriteMode &&
+		a.CommitWrites == b.CommitWrites &&
+		slices.Equal(a.DisabledTools, b.DisabledTools) &&
+		a.MaxLoopInterventions == b.MaxLoopInterventions &&
+		a.Benchmark == b.Benchmark
+}
+
+// close closes the open adapter and drops the pipeline built on it
+func (c *evalCache) close() {
+	if c.adapter != nil {
+		c.adapter.Close()
+	}
+	c.dbPath = ""
+	c.adapter = nil
+	c.config = nil
+	c.pipeline = nil
+}
@@ -11,14 +11,17 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"reactsql/internal/adapter"
+	contextpkg "reactsql/internal/context"
 	"reactsql/internal/inference"
 	"reactsql/internal/llm"
 
+	"github.com/tmc/langchaingo/embeddings"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -49,32 +52,93 @@ type BirdExample struct {
 
 // EvalResult unified evaluation result
 type EvalResult struct {
-	QuestionID     int                   `json:"question_id,omitempty"`
-	DbID           string                `json:"db_id"`
-	Question       string                `json:"question"`
-	Evidence       string                `json:"evidence,omitempty"`
-	GoldSQL        string                `json:"gold_sql"`
-	GeneratedSQL   string                `json:"generated_sql"`
-	Status         string                `json:"status"` // success, error, timeout
-	Error          string                `json:"error,omitempty"`
-	TimeSeconds    float64               `json:"time_seconds"`
-	LLMCalls       int                   `json:"llm_calls"`
-	TotalTokens    int                   `json:"total_tokens"`
-	ClarifyCount   int                   `json:"clarify_count"`
-	SelectedTables []string              `json:"selected_tables"`
-	Difficulty     string                `json:"difficulty,omitempty"`
-	ReActSteps     []inference.ReActStep `json:"react_steps,omitempty"`
+	QuestionID      int                      `json:"question_id,omitempty"`
+	DbID            string                   `json:"db_id"`
+	Question        string                   `json:"question"`
+	Evidence        string                   `json:"evidence,omitempty"`
+	GoldSQL         string                   `json:"gold_sql"`
+	GeneratedSQL    string                   `json:"generated_sql"`
+	Status          string                   `json:"status"` // success, error, timeout
+	Error           string                   `json:"error,omitempty"`
+	TimeSeconds     float64                  `json:"time_seconds"`
+	LLMCalls        int                      `json:"llm_calls"`
+	TotalTokens     int                      `json:"total_tokens"`
+	ClarifyCount    int                      `json:"clarify_count"`
+	SelectedTables  []string                 `json:"selected_tables"`
+	SelectedColumns map[string][]string      `json:"selected_columns,omitempty"`
+	Difficulty      string                   `json:"difficulty,omitempty"`
+	ReActSteps      []inference.ReActStep    `json:"react_steps,omitempty"` // Results written before trajectories (read by replay)
+	Trajectory      *inference.Trajectory    `json:"trajectory,omitempty"`
+	Candidates      []inference.Candidate    `json:"candidates,omitempty"`
+	Agreement       *inference.Agreement     `json:"agreement,omitempty"` // Candidate result-set agreement (-candidates)
+	FinalAnswer     *inference.FinalAnswer   `json:"final_answer,omitempty"`
+	Answer          string                   `json:"answer,omitempty"`              // Natural-language answer (-nl-answer)
+	Thinking        string                   `json:"thinking,omitempty"`            // Model reasoning behind the final SQL
+	ForcedFinal     bool                     `json:"forced_final_answer,omitempty"` // Iteration limit hit, SQL from the forced final answer
+	GenerationPath  string                   `json:"generation_path,omitempty"`     // react | one_shot | one_shot_fallback | decomposition
+	LinkingCached   bool                     `json:"linking_cached,omitempty"`      // Schema linking reused from an earlier example
+	BudgetExhausted bool                     `json:"budget_exhausted,omitempty"`    // -max-duration ran out, SQL is the best so far
+	StyleRewrites   []string                 `json:"style_rewrites,omitempty"`      // -sql-style rewrites applied to the final SQL
+	ValueRewrites   []string                 `json:"value_rewrites,omitempty"`      // -canonical-values literal rewrites
+	Interventions   int                      `json:"loop_interventions,omitempty"`  // Repeated ReAct tool calls answered with an intervention
+	Phases          map[string]EvalPhase     `json:"phases,omitempty"`
+	Linking         *EvalLinking             `json:"linking,omitempty"`
+	EvidenceHints   []inference.EvidenceHint `json:"evidence_hints,omitempty"` // Parsed BIRD evidence
+}
+
+// EvalLinking schema linking precision / recall against the tables and columns of the gold SQL
+type EvalLinking struct {
+	GoldTables      []string            `json:"gold_tables"`
+	GoldColumns     map[string][]string `json:"gold_columns,omitempty"`
+	TablePrecision  float64             `json:"table_precision"`
+	TableRecall     float64             `json:"table_recall"`
+	ColumnPrecision *float64            `json:"column_precision,omitempty"` // Column linking only
+	ColumnRecall    *float64            `json:"column_recall,omitempty"`
+}
+
+// EvalPhase time and cost of one pipeline phase (inference.Phase* names)
+type EvalPhase struct {
+	Seconds  float64 `json:"seconds"`
+	Calls    int     `json:"calls,omitempty"` // Tool calls (clarify / proofread)
+	LLMCalls int     `json:"llm_calls"`
+	Tokens   int     `json:"tokens"`
 }
 
 // EvalMode predefined evaluation mode
 type EvalMode struct {
-	Name            string
-	Description     string
-	UseReact        bool
-	UseRichContext  bool
-	ReactLinking    bool
-	EnableClarify   string
-	EnableProofread bool
+	Name             string
+	Description      string
+	UseReact         bool
+	UseRichContext   bool
+	ReactLinking     bool
+	EnableClarify    string
+	EnableProofread  bool
+	PersistProofread bool               // Write proofread corrections back to the context files
+	Candidates       int                // Multi-candidate generation with execution reranking (<=1 = off)
+	ValueHints       bool               // Inject value index matches for question phrases
+	CanonicalValues  bool               // Rewrite compared literals into the exact stored values
+	SkipExpiredNotes bool               // Leave expired Rich Context notes out of prompts
+	RefreshQuality   bool               // Re-run quality checks on the live database per question
+	LazyContext      bool               // Decode context tables only once schema linking selects them
+	ColumnLinking    bool               // Schema Linking also selects columns
+	Decompose        bool               // Decompose multi-hop questions into sub-questions composed with CTEs
+	JSONAnswer       bool               // Structured JSON final answer
+	NLAnswer         bool               // Natural-language answer from the result rows
+	MaxPromptTokens  int                // Generation prompt budget (0 = unlimited)
+	MaxTableTokens   int                // Per-table schema prompt budget (0 = unlimited)
+	MaxEnumValues    int                // Enumerated values shown per column (0 = default 8)
+	PruneSchema      int                // Prune low-relevance columns from schema prompts over this many tokens (0 = off)
+	ExecRepair       int                // Repair attempts when the final SQL errors or returns no rows (0 = off)
+	MaxDuration      time.Duration      // Wall-clock budget per question (0 = unlimited)
+	Style            inference.SQLStyle // Style guide preferences for the generated SQL
+	RetryTemps       []float64          // Temperature ladder for retries after repeated identical failures
+	DisabledTools    []string           // ReAct tools left out of the loop
+	RawEvidence      bool               // Append BIRD evidence to the question as text instead of structured hints
+
+	FewShot  *inference.FewShotRetriever // Few-shot example retriever (nil = off)
+	FewShotK int                         // Few-shot examples per question
+
+	LinkCache *inference.LinkCache // Schema-linking results shared by the run's examples (nil = off)
 }
 
 // ─────────────────────────────────────────────────────
@@ -138,6 +202,15 @@ var evalModes = []EvalMode{
 }
 
 func main() {
+	// Sandbox worker mode (re-executed by --sandbox), returns immediately otherwise
+	adapter.SandboxMain()
+
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		runReplay(os.Args[2:])
+		return
+	}
+
 	// Command line flags
 	benchmark := flag.String("benchmark", "", "Benchmark: spider | bird (if empty, will ask interactively)")
 	modelType := flag.String("model", "deepseek-v3", "Model: deepseek-v3 | deepseek-v3.2 | qwen-max | qwen3-max | qwen3.5 | doubao-seed2-pro | qwen3-coder-plus | ali-deepseek-v3.2")
@@ -148,9 +221,55 @@ func main() {
 	outputDir := flag.String("output-dir", "", "Output directory (auto-generated if empty)")
 	logMode := flag.String("log-mode", "simple", "Log mode: simple | full")
 	difficulty := flag.String("difficulty", "", "BIRD only: filter by difficulty (simple/moderate/challenging)")
+	inMemory := flag.Bool("in-memory", false, "Copy each SQLite database into memory before running (faster validation queries on small DBs)")
+	sandbox := flag.Bool("sandbox", false, "Run SQL queries in a long-lived child process per database with memory/CPU rlimits, killed and restarted on timeout")
+	sandboxMem := flag.Int("sandbox-mem", 2048, "Sandbox memory limit per query (MB)")
+	noCase := flag.Bool("nocase", false, "Compare and sort text case-insensitively (COLLATE NOCASE on text columns of an in-memory copy; the file is never modified)")
+	caseSensitiveLike := flag.Bool("case-sensitive-like", false, "Make LIKE case-sensitive (SQLite ignores ASCII case by default)")
+	encoding := flag.String("encoding", "", "Decode non-UTF8 result text from this charset: latin1 | windows-1252 (empty = leave as is)")
+	candidates := flag.Int("candidates", 1, "Generate N candidate SQLs per question and pick by execution consensus (1 = off)")
+	fewShotPath := flag.String("fewshot", "", "Training set for few-shot retrieval (Spider train_spider.json or BIRD train.json; empty = off)")
+	fewShotK := flag.Int("fewshot-k", 3, "Few-shot examples injected per question")
+	disableTools := flag.String("disable-tools", "", "Comma-separated ReAct tools to leave out (e.g. verify_result_fields,update_rich_context)")
+	maxPromptTokens := flag.Int("max-prompt-tokens", 0, "Generation prompt budget; low-priority sections are dropped to fit (0 = unlimited)")
+	maxTableTokens := flag.Int("max-table-tokens", 0, "Per-table schema prompt budget; notes, minor issues, value stats, comments, then non-key columns are trimmed to fit (0 = unlimited)")
+	maxEnumValues := flag.Int("max-enum-values", 0, "Enumerated values shown per column in schema prompts (0 = default 8)")
+	jsonAnswer := flag.Bool("json-answer", false, "Ask for the final answer as a JSON object (sql, tables, confidence) instead of raw SQL")
+	persistProofread := flag.Bool("persist-proofread", false, "Write update_rich_context corrections back to the context file (with provenance; a <db>.ctxdb store rewrites only the table's row) so later examples and runs use them")
+	retryTemps := flag.String("retry-temps", "", "Comma-separated temperature ladder for regenerating after repeated identical failures (e.g. 0.4,0.7,1.0; empty = off)")
+	nlAnswer := flag.Bool("nl-answer", false, "After execution, answer each question in prose from the result rows (results.json \"answer\")")
+	decompose := flag.Bool("decompose", false, "Break multi-hop questions into sub-questions, solve them, and compose the final SQL with CTEs")
+	columnLinking := flag.Bool("column-linking", false, "Schema Linking also selects columns; the schema prompt keeps only those plus keys")
+	valueHints := flag.Bool("value-hints", false, "Match question phrases against the context's cell-value index and inject column hints")
+	canonicalValues := flag.Bool("canonical-values", false, "Rewrite literals compared with enumerated TEXT columns into the exact stored value from the context (\"usa\" → \" USA\")")
+	pruneSchema := flag.Int("prune-schema-tokens", 0, "Drop the columns least relevant to the question (keys kept) from schema prompts over this many tokens (0 = off)")
+	execRepair := flag.Int("exec-repair", 0, "Execute the final SQL and let the LLM repair it up to N times when it errors or returns no rows for a question implying an answer (0 = off)")
+	maxDuration := flag.Duration("max-duration", 0, "Wall-clock budget per question; when spent, the best SQL so far is scored (0 = unlimited)")
+	sqlStyle := flag.String("sql-style", "", "Comma-separated SQL style preferences, stated in the prompt and enforced on the final SQL: cte|nested, explicit, as|short")
+	noLinkCache := flag.Bool("no-link-cache", false, "Run schema linking for every example, even a repeated (db, question)")
+	skipExpiredNotes := flag.Bool("skip-expired-notes", false, "Leave expired Rich Context notes out of schema prompts instead of tagging them [EXPIRED] (prune them with context_gc)")
+	lazyContext := flag.Bool("lazy-context", false, "Decode only the context's table index up front and each table once schema linking selects it (large contexts; linking sees names, columns, keys and descriptions instead of the full Rich Context)")
+	refreshQuality := flag.Bool("refresh-quality", false, "Re-run the deterministic quality checks (tables in parallel, per-check timeout) on each database instead of trusting the stored context")
+	rawEvidence := flag.Bool("raw-evidence", false, "BIRD only: append evidence to the question as raw text instead of parsed hints under the relevant tables")
 
 	flag.Parse()
 
+	// Per-example adapter template (FilePath is filled per example)
+	dbTemplate := &adapter.DBConfig{
+		Type:     "sqlite",
+		InMemory: *inMemory,
+	}
+	if *sandbox {
+		dbTemplate.Sandbox = &adapter.SandboxConfig{MaxMemoryMB: *sandboxMem}
+	}
+	if *noCase || *caseSensitiveLike || *encoding != "" {
+		dbTemplate.Text = &adapter.TextOptions{NoCase: *noCase, CaseSensitiveLike: *caseSensitiveLike, Encoding: *encoding}
+		if err := dbTemplate.Text.Validate(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 
 	// ── Step 1: Select benchmark ──
@@ -298,6 +417,52 @@ func main() {
 		}
 	}
 
+	selectedMode.Candidates = *candidates
+	selectedMode.ValueHints = *valueHints
+	selectedMode.CanonicalValues = *canonicalValues
+	selectedMode.SkipExpiredNotes = *skipExpiredNotes
+	selectedMode.RefreshQuality = *refreshQuality
+	selectedMode.LazyContext = *lazyContext
+	selectedMode.ColumnLinking = *columnLinking
+	selectedMode.Decompose = *decompose
+	selectedMode.JSONAnswer = *jsonAnswer
+	selectedMode.NLAnswer = *nlAnswer
+	selectedMode.RawEvidence = *rawEvidence
+	selectedMode.PersistProofread = *persistProofread
+	if selectedMode.PersistProofread && !selectedMode.EnableProofread {
+		fmt.Println("⚠️  -persist-proofread has no effect: the selected mode does not enable proofread")
+	}
+	selectedMode.MaxPromptTokens = *maxPromptTokens
+	selectedMode.MaxTableTokens = *maxTableTokens
+	selectedMode.MaxEnumValues = *maxEnumValues
+	selectedMode.PruneSchema = *pruneSchema
+	selectedMode.ExecRepair = *execRepair
+	selectedMode.MaxDuration = *maxDuration
+	style, err := inference.ParseSQLStyle(*sqlStyle)
+	if err != nil {
+		log.Fatalf("Invalid -sql-style: %v", err)
+	}
+	selectedMode.Style = style
+	if !*noLinkCache {
+		selectedMode.LinkCache = inference.NewLinkCache()
+	}
+	if *retryTemps != "" {
+		for _, field := range strings.Split(*retryTemps, ",") {
+			t, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil || t < 0 || t > 2 {
+				log.Fatalf("Invalid -retry-temps value: %q (want numbers between 0 and 2)", field)
+			}
+			selectedMode.RetryTemps = append(selectedMode.RetryTemps, t)
+		}
+	}
+	if *disableTools != "" {
+		for _, name := range strings.Split(*disableTools, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				selectedMode.DisabledTools = append(selectedMode.DisabledTools, name)
+			}
+		}
+	}
+
 	// Validate Rich Context availability
 	if selectedMode.UseRichContext && !contextAvailable {
 		log.Fatalf("❌ Rich Context directory not found: %s\n   This mode requires Rich Context. Generate it first:\n   go run ./cmd/gen_all_dev --benchmark %s", contextDir, *benchmark)
@@ -427,7 +592,41 @@ func main() {
 	// ── Step 6: Create output directory ──
 	if *outputDir == "" {
 		timestamp := time.Now().Format("20060102_150405")
-		*outputDir = filepath.Join("results", *benchmark, fmt.Sprintf("%s_%s", timestamp, selectedMode.Name))
+		modeName := selectedMode.Name
+		if selectedMode.Candidates > 1 {
+			modeName = fmt.Sprintf("%s+cand%d", modeName, selectedMode.Candidates)
+		}
+		if *fewShotPath != "" {
+			modeName += "+fewshot"
+		}
+		if *valueHints {
+			modeName += "+vh"
+		}
+		if *canonicalValues {
+			modeName += "+canon"
+		}
+		if *columnLinking {
+			modeName += "+cols"
+		}
+		if *decompose {
+			modeName += "+decomp"
+		}
+		if selectedMode.ExecRepair > 0 {
+			modeName += "+repair"
+		}
+		if *jsonAnswer {
+			modeName += "+json"
+		}
+		if *nlAnswer {
+			modeName += "+nla"
+		}
+		if len(selectedMode.RetryTemps) > 0 {
+			modeName += "+ladder"
+		}
+		if *rawEvidence && *benchmark == "bird" {
+			modeName += "+rawev"
+		}
+		*outputDir = filepath.Join("results", *benchmark, fmt.Sprintf("%s_%s", timestamp, modeName))
 	}
 
 	// ── Step 7: Print config summary ──
@@ -449,8 +648,62 @@ func main() {
 	fmt.Printf("  Use ReAct:      %v\n", selectedMode.UseReact)
 	fmt.Printf("  Rich Context:   %v\n", selectedMode.UseRichContext)
 	fmt.Printf("  React Linking:  %v\n", selectedMode.ReactLinking)
+	if selectedMode.ColumnLinking {
+		fmt.Printf("  Column Linking: %v\n", selectedMode.ColumnLinking)
+	}
+	if selectedMode.Decompose {
+		fmt.Printf("  Decompose:      %v\n", selectedMode.Decompose)
+	}
+	if selectedMode.JSONAnswer {
+		fmt.Printf("  JSON Answer:    %v\n", selectedMode.JSONAnswer)
+	}
+	if selectedMode.NLAnswer {
+		fmt.Printf("  NL Answer:      %v\n", selectedMode.NLAnswer)
+	}
+	if len(selectedMode.RetryTemps) > 0 {
+		fmt.Printf("  Retry Ladder:   %v\n", selectedMode.RetryTemps)
+	}
+	if selectedMode.RawEvidence && *benchmark == "bird" {
+		fmt.Printf("  Raw Evidence:   %v\n", selectedMode.RawEvidence)
+	}
+	if selectedMode.MaxPromptTokens > 0 {
+		fmt.Printf("  Prompt Budget:  %d tokens\n", selectedMode.MaxPromptTokens)
+	}
+	if selectedMode.MaxTableTokens > 0 {
+		fmt.Printf("  Table Budget:   %d tokens\n", selectedMode.MaxTableTokens)
+	}
+	if selectedMode.PruneSchema > 0 {
+		fmt.Printf("  Column Pruning: schemas over %d tokens\n", selectedMode.PruneSchema)
+	}
+	if selectedMode.ExecRepair > 0 {
+		fmt.Printf("  Exec Repair:    %d attempts\n", selectedMode.ExecRepair)
+	}
+	if selectedMode.MaxDuration > 0 {
+		fmt.Printf("  Time Budget:    %s per question\n", selectedMode.MaxDuration)
+	}
+	if *sqlStyle != "" {
+		fmt.Printf("  SQL Style:      %s\n", *sqlStyle)
+	}
+	if len(selectedMode.DisabledTools) > 0 {
+		fmt.Printf("  Disabled Tools: %s\n", strings.Join(selectedMode.DisabledTools, ", "))
+	}
 	fmt.Printf("  Clarify Mode:   %s\n", selectedMode.EnableClarify)
 	fmt.Printf("  Proofread:      %v\n", selectedMode.EnableProofread)
+	if selectedMode.EnableProofread && selectedMode.PersistProofread {
+		fmt.Printf("  Persist Fixes:  %s\n", contextDir)
+	}
+	if selectedMode.Candidates > 1 {
+		fmt.Printf("  Candidates:     %d\n", selectedMode.Candidates)
+	}
+	if *fewShotPath != "" {
+		fmt.Printf("  Few-shot:       %s (k=%d)\n", *fewShotPath, *fewShotK)
+	}
+	if *valueHints {
+		fmt.Printf("  Value Hints:    %v\n", *valueHints)
+	}
+	if *canonicalValues {
+		fmt.Printf("  Canon. Values:  %v\n", *canonicalValues)
+	}
 	if *difficulty != "" {
 		fmt.Printf("  Difficulty:     %s\n", *difficulty)
 	}
@@ -474,6 +727,29 @@ func main() {
 		fmt.Printf("🤖 %s\n\n", strings.TrimSpace(identityResponse))
 	}
 
+	// Build few-shot index once (shared by all examples)
+	if *fewShotPath != "" {
+		examples, err := inference.LoadFewShotExamples(*fewShotPath)
+		if err != nil {
+			log.Fatalf("Failed to load few-shot examples: %v", err)
+		}
+		var embedder embeddings.Embedder
+		if embCfg := llm.GetConfig().Embedding; embCfg.ModelName != "" {
+			if embedder, err = llm.CreateEmbedder(embCfg); err != nil {
+				log.Fatalf("Failed to create embedder: %v", err)
+			}
+			fmt.Printf("📎 Embedding %d few-shot examples with %s...\n", len(examples), embCfg.ModelName)
+		} else {
+			fmt.Printf("📎 Indexing %d few-shot examples (local hashing embedder)...\n", len(examples))
+		}
+		retriever, err := inference.NewFewShotRetriever(context.Background(), examples, embedder)
+		if err != nil {
+			log.Fatalf("Failed to build few-shot index: %v", err)
+		}
+		selectedMode.FewShot = retriever
+		selectedMode.FewShotK = *fewShotK
+	}
+
 	// ── Step 9: Create output files ──
 	if err := os.MkdirAll(*outputDir, 0755); err != nil {
 		log.Fatalf("Failed to create output dir: %v", err)
@@ -571,9 +847,20 @@ func main() {
 		totalLLMCalls int
 		totalTokens   int
 		totalClarify  int
+		totalForced   int
+		totalFallback int
+		totalBudget   int
+		totalLooping  int
+		linkTotals    linkingTotals
+		agreeTotals   agreementTotals
+		phaseTotals   = make(map[string]*EvalPhase)
 	)
 	ctx := context.Background()
 
+	// Adapter and pipeline reused while consecutive examples share a database
+	cache := newEvalCache(dbTemplate, evalLogger)
+	defer cache.close()
+
 	// Memory tracking
 	logMemory("Initial", 0)
 
@@ -609,7 +896,7 @@ func main() {
 			evalLogger.FileOnly("Example: %04d\n", i+1)
 			evalLogger.FileOnly("DB: %s\n", exampleDbID)
 			evalLogger.FileOnly("Question: %s\n", exampleQuestion)
-			evalLogger.FileOnly("Gold SQL: %s\n", exampleGoldSQL)
+			evalLogger.FileOnly("Gold SQL:\n%s\n", indentSQL(exampleGoldSQL, dbTemplate.Type))
 			if exampleEvidence != "" {
 				evalLogger.FileOnly("Evidence: %s\n", exampleEvidence)
 			}
@@ -622,7 +909,7 @@ func main() {
 			fmt.Printf("[%d/%d] DB: %s\n", i+1, totalCount, e.DbID)
 			fmt.Printf("Question: %s\n", e.Question)
 			fmt.Printf("Gold SQL: %s\n", e.Query)
-			result = evaluateSpider(ctx, llmModel, e, dbDir, contextDir, selectedMode, *logMode, evalLogger)
+			result = evaluateSpider(ctx, llmModel, e, dbDir, contextDir, selectedMode, *logMode, cache)
 
 		case BirdExample:
 			fmt.Printf("[%d/%d] DB: %s (difficulty: %s)\n", i+1, totalCount, e.DbID, e.Difficulty)
@@ -631,7 +918,7 @@ func main() {
 				fmt.Printf("Evidence: %s\n", e.Evidence)
 			}
 			fmt.Printf("Gold SQL: %s\n", e.SQL)
-			result = evaluateBird(ctx, llmModel, e, dbDir, contextDir, selectedMode, *logMode, evalLogger)
+			result = evaluateBird(ctx, llmModel, e, dbDir, contextDir, selectedMode, *logMode, cache)
 		}
 
 		// Update stats
@@ -642,6 +929,31 @@ func main() {
 		totalLLMCalls += result.LLMCalls
 		totalTokens += result.TotalTokens
 		totalClarify += result.ClarifyCount
+		if result.ForcedFinal {
+			totalForced++
+		}
+		if result.GenerationPath == inference.PathFallback {
+			totalFallback++
+		}
+		if result.BudgetExhausted {
+			totalBudget++
+		}
+		if result.Interventions > 0 {
+			totalLooping++
+		}
+		linkTotals.add(result.Linking)
+		agreeTotals.add(result.Agreement)
+		for name, phase := range result.Phases {
+			total, ok := phaseTotals[name]
+			if !ok {
+				total = &EvalPhase{}
+				phaseTotals[name] = total
+			}
+			total.Seconds += phase.Seconds
+			total.Calls += phase.Calls
+			total.LLMCalls += phase.LLMCalls
+			total.Tokens += phase.Tokens
+		}
 
 		// Incremental JSON write (always keep file as valid JSON)
 		if i > 0 {
@@ -660,15 +972,11 @@ func main() {
 		}
 
 		// Incremental SQL write (with Sync for crash safety)
-		sql := result.GeneratedSQL
+		// One statement per line: newlines can only remain inside literals
+		sql := strings.NewReplacer("\n", " ", "\r", " ").Replace(inference.CleanSQL(result.GeneratedSQL, ""))
 		if sql == "" {
 			sql = "SELECT 1"
 		}
-		sql = strings.TrimSpace(sql)
-		sql = strings.TrimSuffix(sql, ";")
-		sql = strings.ReplaceAll(sql, "\n", " ")
-		sql = strings.ReplaceAll(sql, "\r", " ")
-		sql = strings.Join(strings.Fields(sql), " ")
 		fmt.Fprintf(sqlFile, "%s\t%s\n", sql, result.DbID)
 		sqlFile.Sync()
 
@@ -683,12 +991,28 @@ func main() {
 		if result.ClarifyCount > 0 {
 			fmt.Printf("Clarify Count: %d\n", result.ClarifyCount)
 		}
+		if result.ForcedFinal {
+			fmt.Printf("⏱️  Iteration limit reached, SQL from forced final answer\n")
+		}
+		if result.BudgetExhausted {
+			fmt.Printf("⏱️  Time budget exhausted, SQL is the best so far\n")
+		}
+		if result.Interventions > 0 {
+			fmt.Printf("🔁 Repeated tool calls intercepted: %d\n", result.Interventions)
+		}
 
 		// ── Write per-example log footer & close ──
 		if logFile != nil {
 			evalLogger.FileOnly("\n[Result]\n")
-			evalLogger.FileOnly("  Generated SQL: %s\n", result.GeneratedSQL)
+			evalLogger.FileOnly("  Generated SQL:\n%s\n", indentSQL(result.GeneratedSQL, dbTemplate.Type))
 			evalLogger.FileOnly("  Status: %s\n", result.Status)
+			if result.Status != "success" && result.GeneratedSQL != "" {
+				// Line diff against the gold query (- gold, + generated)
+				evalLogger.FileOnly("  Diff vs gold:\n")
+				for _, line := range strings.Split(inference.DiffSQL(exampleGoldSQL, result.GeneratedSQL, dbTemplate.Type), "\n") {
+					evalLogger.FileOnly("    %s\n", line)
+				}
+			}
 			if result.Error != "" {
 				evalLogger.FileOnly("  Error: %s\n", result.Error)
 			}
@@ -745,12 +1069,63 @@ func main() {
 	if totalClarify > 0 {
 		both("Total Clarifications: %d (%.1f%%)\n", totalClarify, float64(totalClarify)/float64(totalCount)*100)
 	}
+	if totalForced > 0 {
+		both("Forced Final Answers: %d (%.1f%%)\n", totalForced, float64(totalForced)/float64(totalCount)*100)
+	}
+	if totalFallback > 0 {
+		both("One-shot Fallbacks: %d (%.1f%%)\n", totalFallback, float64(totalFallback)/float64(totalCount)*100)
+	}
+	if totalBudget > 0 {
+		both("Time Budget Exhausted: %d (%.1f%%)\n", totalBudget, float64(totalBudget)/float64(totalCount)*100)
+	}
+	if totalLooping > 0 {
+		both("Loop Interventions: %d (%.1f%%)\n", totalLooping, float64(totalLooping)/float64(totalCount)*100)
+	}
+	if selectedMode.LinkCache != nil {
+		if hits, misses := selectedMode.LinkCache.Stats(); hits > 0 {
+			both("Schema Link Cache: %d of %d linkings reused\n", hits, hits+misses)
+		}
+	}
+	if linkTotals.scored > 0 {
+		n := float64(linkTotals.scored)
+		both("Schema Linking: table P %.1f%% / R %.1f%%, all gold tables linked %d/%d (%.1f%%)\n",
+			linkTotals.tablePrecision/n*100, linkTotals.tableRecall/n*100,
+			linkTotals.fullRecall, linkTotals.scored, float64(linkTotals.fullRecall)/n*100)
+		if linkTotals.columnScored > 0 {
+			c := float64(linkTotals.columnScored)
+			both("Column Linking: P %.1f%% / R %.1f%% (%d examples)\n",
+				linkTotals.columnPrecision/c*100, linkTotals.columnRecall/c*100, linkTotals.columnScored)
+		}
+	}
+	if agreeTotals.scored > 0 {
+		n := float64(agreeTotals.scored)
+		both("Candidate Agreement: avg %.1f%%, unanimous %d/%d (%.1f%%), tied %d, avg %.1f clusters\n",
+			agreeTotals.ratio/n*100, agreeTotals.unanimous, agreeTotals.scored, float64(agreeTotals.unanimous)/n*100,
+			agreeTotals.tied, float64(agreeTotals.clusters)/n)
+	}
+	if len(phaseTotals) > 0 && totalCount > 0 {
+		both("\nPer-phase averages (per query; clarify/proofread time is part of generation):\n")
+		both("  %-16s %9s %9s %10s %7s\n", "Phase", "Time", "LLM Calls", "Tokens", "Time%%")
+		for _, name := range phaseOrder {
+			total, ok := phaseTotals[name]
+			if !ok {
+				continue
+			}
+			share := 0.0
+			if totalTime > 0 {
+				share = total.Seconds / totalTime * 100
+			}
+			both("  %-16s %8.2fs %9.1f %10d %6.1f%%\n", name,
+				total.Seconds/float64(totalCount), float64(total.LLMCalls)/float64(totalCount),
+				total.Tokens/totalCount, share)
+		}
+	}
 
 	// Get absolute path for output dir
 	absOutputDir, _ := filepath.Abs(*outputDir)
 
 	both("\n✅ Results saved to: %s/\n", *outputDir)
-	both("  - results.json     (detailed results with ReAct steps)\n")
+	both("  - results.json     (detailed results with trajectories, view with: eval replay)\n")
 	both("  - predict.sql      (predicted SQL for official evaluation)\n")
 	both("  - inference.log    (compressed summary log)\n")
 	both("  - log.txt          (full inference output)\n")
@@ -776,7 +1151,7 @@ func evaluateSpider(
 	contextDir string,
 	mode EvalMode,
 	logMode string,
-	logger *inference.InferenceLogger,
+	cache *evalCache,
 ) (result EvalResult) {
 	result = EvalResult{
 		DbID:     example.DbID,
@@ -790,26 +1165,18 @@ func evaluateSpider(
 		result.TimeSeconds = time.Since(startTime).Seconds()
 	}()
 
-	// Create adapter
+	// Adapter (kept open for the next example on the same database)
 	dbPath := filepath.Join(dbDir, example.DbID, example.DbID+".sqlite")
-	dbAdapter, err := adapter.NewAdapter(&adapter.DBConfig{
-		Type:     "sqlite",
-		FilePath: dbPath,
-	})
+	dbAdapter, err := cache.open(ctx, dbPath)
 	if err != nil {
-		result.Error = fmt.Sprintf("create adapter: %v", err)
-		return result
-	}
-	if err := dbAdapter.Connect(ctx); err != nil {
-		result.Error = fmt.Sprintf("connect db: %v", err)
+		result.Error = err.Error()
 		return result
 	}
-	defer dbAdapter.Close()
 
 	// Context file
 	var contextFile string
 	if mode.UseRichContext {
-		contextFile = filepath.Join(contextDir, example.DbID+".json")
+		contextFile = contextpkg.ContextPath(contextDir, example.DbID)
 		if _, err := os.Stat(contextFile); os.IsNotExist(err) {
 			result.Error = fmt.Sprintf("context file not found: %s", contextFile)
 			return result
@@ -821,23 +1188,43 @@ func evaluateSpider(
 		UseRichContext:          mode.UseRichContext,
 		UseReact:                mode.UseReact,
 		ReactLinking:            mode.ReactLinking,
+		ColumnLinking:           mode.ColumnLinking,
+		Decompose:               mode.Decompose,
+		JSONAnswer:              mode.JSONAnswer,
+		NLAnswer:                mode.NLAnswer,
+		RetryTemperatures:       mode.RetryTemps,
+		MaxPromptTokens:         mode.MaxPromptTokens,
+		MaxTableTokens:          mode.MaxTableTokens,
+		MaxEnumValues:           mode.MaxEnumValues,
+		PruneSchemaTokens:       mode.PruneSchema,
+		ExecRepairAttempts:      mode.ExecRepair,
+		MaxDuration:             mode.MaxDuration,
+		Style:                   mode.Style,
+		DisabledTools:           mode.DisabledTools,
 		UseDryRun:               false,
 		MaxIterations:           20,
 		ContextFile:             contextFile,
+		SkipExpiredNotes:        mode.SkipExpiredNotes,
+		RefreshQuality:          mode.RefreshQuality,
+		LazyContext:             mode.LazyContext,
 		ClarifyMode:             mode.EnableClarify,
 		LogMode:                 logMode,
 		ResultFields:            example.ResultFields,
 		ResultFieldsDescription: example.ResultFieldsDescription,
 		EnableProofread:         mode.EnableProofread,
+		PersistProofread:        mode.PersistProofread,
+		Candidates:              mode.Candidates,
+		FewShot:                 mode.FewShot,
+		LinkCache:               mode.LinkCache,
+		FewShotK:                mode.FewShotK,
+		ValueHints:              mode.ValueHints,
+		CanonicalValues:         mode.CanonicalValues,
 		DBName:                  example.DbID,
 		DBType:                  "sqlite",
 		Benchmark:               "spider",
 	}
 
-	pipeline := inference.NewPipeline(llm, dbAdapter, pipelineConfig)
-	if logger != nil {
-		pipeline.SetLogger(logger)
-	}
+	pipeline := cache.pipelineFor(llm, pipelineConfig)
 	inferResult, err := pipeline.Execute(ctx, example.Question)
 	if err != nil {
 		result.Error = fmt.Sprintf("inference: %v", err)
@@ -849,7 +1236,22 @@ func evaluateSpider(
 	result.TotalTokens = inferResult.TotalTokens
 	result.ClarifyCount = inferResult.ClarifyCount
 	result.SelectedTables = inferResult.SelectedTables
-	result.ReActSteps = inferResult.ReActSteps
+	result.SelectedColumns = inferResult.SelectedColumns
+	result.Trajectory = inferResult.Trajectory
+	result.Candidates = inferResult.Candidates
+	result.Agreement = inferResult.Agreement
+	result.FinalAnswer = inferResult.FinalAnswer
+	result.Answer = inferResult.Answer
+	result.Thinking = inferResult.Thinking
+	result.ForcedFinal = inferResult.ForcedFinalAnswer
+	result.GenerationPath = inferResult.GenerationPath
+	result.LinkingCached = inferResult.LinkingCached
+	result.BudgetExhausted = inferResult.BudgetExhausted
+	result.StyleRewrites = inferResult.StyleRewrites
+	result.ValueRewrites = inferResult.ValueRewrites
+	result.Interventions = inferResult.LoopInterventions
+	result.Phases = evalPhases(inferResult.Phases)
+	result.Linking = evalLinking(ctx, dbAdapter, result.GoldSQL, inferResult)
 	result.Status = "success"
 	return result
 }
@@ -866,7 +1268,7 @@ func evaluateBird(
 	contextDir string,
 	mode EvalMode,
 	logMode string,
-	logger *inference.InferenceLogger,
+	cache *evalCache,
 ) (result EvalResult) {
 	result = EvalResult{
 		QuestionID: example.QuestionID,
@@ -883,36 +1285,30 @@ func evaluateBird(
 		result.TimeSeconds = time.Since(startTime).Seconds()
 	}()
 
-	// Create adapter
+	// Adapter (kept open for the next example on the same database)
 	dbPath := filepath.Join(dbDir, example.DbID, example.DbID+".sqlite")
-	dbAdapter, err := adapter.NewAdapter(&adapter.DBConfig{
-		Type:     "sqlite",
-		FilePath: dbPath,
-	})
+	dbAdapter, err := cache.open(ctx, dbPath)
 	if err != nil {
-		result.Error = fmt.Sprintf("create adapter: %v", err)
-		return result
-	}
-	if err := dbAdapter.Connect(ctx); err != nil {
-		result.Error = fmt.Sprintf("connect db: %v", err)
+		result.Error = err.Error()
 		return result
 	}
-	defer dbAdapter.Close()
 
 	// Context file
 	var contextFile string
 	if mode.UseRichContext {
-		contextFile = filepath.Join(contextDir, example.DbID+".json")
+		contextFile = contextpkg.ContextPath(contextDir, example.DbID)
 		if _, err := os.Stat(contextFile); os.IsNotExist(err) {
 			// BIRD may not have rich context for all DBs, skip silently
 			contextFile = ""
 		}
 	}
 
-	// Build full question with evidence (structured for BIRD)
+	// Evidence: parsed into hints by the pipeline, or appended to the question as text
 	question := example.Question
-	if example.Evidence != "" {
+	evidence := example.Evidence
+	if mode.RawEvidence && example.Evidence != "" {
 		question = fmt.Sprintf("%s\n\nEvidence (MUST follow these constraints):\n%s", example.Question, example.Evidence)
+		evidence = ""
 	}
 
 	// Pipeline
@@ -920,23 +1316,44 @@ func evaluateBird(
 		UseRichContext:          mode.UseRichContext && contextFile != "",
 		UseReact:                mode.UseReact,
 		ReactLinking:            mode.ReactLinking,
+		ColumnLinking:           mode.ColumnLinking,
+		Decompose:               mode.Decompose,
+		JSONAnswer:              mode.JSONAnswer,
+		NLAnswer:                mode.NLAnswer,
+		RetryTemperatures:       mode.RetryTemps,
+		MaxPromptTokens:         mode.MaxPromptTokens,
+		MaxTableTokens:          mode.MaxTableTokens,
+		MaxEnumValues:           mode.MaxEnumValues,
+		PruneSchemaTokens:       mode.PruneSchema,
+		ExecRepairAttempts:      mode.ExecRepair,
+		MaxDuration:             mode.MaxDuration,
+		Style:                   mode.Style,
+		DisabledTools:           mode.DisabledTools,
 		UseDryRun:               false,
 		MaxIterations:           20,
 		ContextFile:             contextFile,
+		SkipExpiredNotes:        mode.SkipExpiredNotes,
+		RefreshQuality:          mode.RefreshQuality,
+		LazyContext:             mode.LazyContext,
 		ClarifyMode:             mode.EnableClarify,
 		LogMode:                 logMode,
 		ResultFields:            example.ResultFields,
 		ResultFieldsDescription: example.ResultFieldsDescription,
 		EnableProofread:         mode.EnableProofread,
+		PersistProofread:        mode.PersistProofread,
+		Candidates:              mode.Candidates,
+		FewShot:                 mode.FewShot,
+		LinkCache:               mode.LinkCache,
+		FewShotK:                mode.FewShotK,
+		ValueHints:              mode.ValueHints,
+		CanonicalValues:         mode.CanonicalValues,
+		Evidence:                evidence,
 		DBName:                  example.DbID,
 		DBType:                  "sqlite",
 		Benchmark:               "bird",
 	}
 
-	pipeline := inference.NewPipeline(llm, dbAdapter, pipelineConfig)
-	if logger != nil {
-		pipeline.SetLogger(logger)
-	}
+	pipeline := cache.pipelineFor(llm, pipelineConfig)
 	inferResult, err := pipeline.Execute(ctx, question)
 	if err != nil {
 		result.Error = fmt.Sprintf("inference: %v", err)
@@ -948,11 +1365,130 @@ func evaluateBird(
 	result.TotalTokens = inferResult.TotalTokens
 	result.ClarifyCount = inferResult.ClarifyCount
 	result.SelectedTables = inferResult.SelectedTables
-	result.ReActSteps = inferResult.ReActSteps
+	result.SelectedColumns = inferResult.SelectedColumns
+	result.Trajectory = inferResult.Trajectory
+	result.Candidates = inferResult.Candidates
+	result.Agreement = inferResult.Agreement
+	result.FinalAnswer = inferResult.FinalAnswer
+	result.Answer = inferResult.Answer
+	result.Thinking = inferResult.Thinking
+	result.ForcedFinal = inferResult.ForcedFinalAnswer
+	result.GenerationPath = inferResult.GenerationPath
+	result.LinkingCached = inferResult.LinkingCached
+	result.BudgetExhausted = inferResult.BudgetExhausted
+	result.StyleRewrites = inferResult.StyleRewrites
+	result.ValueRewrites = inferResult.ValueRewrites
+	result.Interventions = inferResult.LoopInterventions
+	result.Phases = evalPhases(inferResult.Phases)
+	result.Linking = evalLinking(ctx, dbAdapter, result.GoldSQL, inferResult)
+	result.EvidenceHints = inferResult.EvidenceHints
 	result.Status = "success"
 	return result
 }
 
+// phaseOrder summary order of the pipeline phases
+var phaseOrder = []string{
+	inference.PhaseFollowUp,
+	inference.PhaseSchemaLinking,
+	inference.PhaseDecomposition,
+	inference.PhaseGeneration,
+	inference.PhaseClarify,
+	inference.PhaseProofread,
+	inference.PhaseSyntaxRepair,
+	inference.PhaseExecRepair,
+	inference.PhaseAnswer,
+}
+
+// evalPhases converts the pipeline's phase stats for results.json
+func evalPhases(phases map[string]*inference.PhaseStats) map[string]EvalPhase {
+	if len(phases) == 0 {
+		return nil
+	}
+	out := make(map[string]EvalPhase, len(phases))
+	for name, ps := range phases {
+		out[name] = EvalPhase{
+			Seconds:  ps.Duration.Seconds(),
+			Calls:    ps.Calls,
+			LLMCalls: ps.LLMCalls,
+			Tokens:   ps.Tokens,
+		}
+	}
+	return out
+}
+
+// evalLinking scores the linker's selection against the gold SQL (nil if the gold SQL cannot be resolved)
+func evalLinking(ctx context.Context, db adapter.DBAdapter, goldSQL string, inferResult *inference.Result) *EvalLinking {
+	score, err := inference.ScoreLinking(ctx, db, goldSQL, inferResult.SelectedTables, inferResult.SelectedColumns)
+	if err != nil {
+		return nil
+	}
+	linking := &EvalLinking{
+		GoldTables:     score.GoldTables,
+		GoldColumns:    score.GoldColumns,
+		TablePrecision: score.TablePrecision,
+		TableRecall:    score.TableRecall,
+	}
+	if score.ColumnScored {
+		linking.ColumnPrecision = &score.ColumnPrecision
+		linking.ColumnRecall = &score.ColumnRecall
+	}
+	return linking
+}
+
+// linkingTotals schema linking sums for the summary
+type linkingTotals struct {
+	scored          int
+	fullRecall      int
+	tablePrecision  float64
+	tableRecall     float64
+	columnScored    int
+	columnPrecision float64
+	columnRecall    float64
+}
+
+// add accumulates one example's linking scores (nil = not scored)
+func (t *linkingTotals) add(l *EvalLinking) {
+	if l == nil {
+		return
+	}
+	t.scored++
+	t.tablePrecision += l.TablePrecision
+	t.tableRecall += l.TableRecall
+	if l.TableRecall >= 1 {
+		t.fullRecall++
+	}
+	if l.ColumnPrecision != nil && l.ColumnRecall != nil {
+		t.columnScored++
+		t.columnPrecision += *l.ColumnPrecision
+		t.columnRecall += *l.ColumnRecall
+	}
+}
+
+// agreementTotals candidate agreement sums for the summary
+type agreementTotals struct {
+	scored    int
+	unanimous int // Every executed candidate returned the same result
+	tied      int
+	clusters  int
+	ratio     float64
+}
+
+// add accumulates one example's agreement (nil = single candidate)
+func (t *agreementTotals) add(a *inference.Agreement) {
+	if a == nil {
+		return
+	}
+	t.scored++
+	t.ratio += a.Ratio
+	t.clusters += a.Clusters
+	if a.Executed > 0 && a.Clusters == 1 {
+		t.unanimous++
+	}
+	if a.Tied {
+		t.tied++
+	}
+}
+
 // ─────────────────────────────────────────────────────
 // Loaders
 // ─────────────────────────────────────────────────────
@@ -1011,6 +1547,14 @@ func logMemory(label string, sampleIdx int) {
 		label, sampleIdx, m.Alloc/1024/1024, m.HeapInuse/1024/1024, m.Sys/1024/1024, rssMB, m.NumGC)
 }
 
+// indentSQL pretty-printed SQL indented for the per-example log
+func indentSQL(sql, dialect string) string {
+	if strings.TrimSpace(sql) == "" {
+		return "    (none)"
+	}
+	return "    " + strings.ReplaceAll(inference.FormatSQL(sql, dialect), "\n", "\n    ")
+}
+
 func parseModelType(modelType string) llm.ModelType {
 	switch modelType {
 	case "deepseek-v3":
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"reactsql/internal/inference"
+)
+
+// runReplay eval replay [flags] <results.json>: lists the examples, or renders one example's trajectory
+func runReplay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	index := fs.Int("index", -1, "Example index in results.json (0-based; -1 = list examples)")
+	questionID := fs.Int("question-id", -1, "BIRD question_id to replay (instead of -index)")
+	prompts := fs.Bool("prompts", false, "Include the full LLM prompts and responses")
+	asJSON := fs.Bool("json", false, "Print the trajectory as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: eval replay [flags] <results.json>\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	results, err := loadResults(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	selected := -1
+	switch {
+	case *questionID >= 0:
+		for i, r := range results {
+			if r.QuestionID == *questionID {
+				selected = i
+				break
+			}
+		}
+		if selected < 0 {
+			log.Fatalf("❌ question_id %d not found in %s", *questionID, fs.Arg(0))
+		}
+	case *index >= 0:
+		if *index >= len(results) {
+			log.Fatalf("❌ Index %d out of range (%d examples)", *index, len(results))
+		}
+		selected = *index
+	default:
+		listResults(results)
+		return
+	}
+
+	r := results[selected]
+	t := trajectoryOf(r)
+	if *asJSON {
+		data, err := json.MarshalIndent(t, "", "  ")
+		if err != nil {
+			log.Fatalf("❌ Failed to encode trajectory: %v", err)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("#%d  db=%s  status=%s  time=%.1fs  llm_calls=%d\n", selected, r.DbID, r.Status, r.TimeSeconds, r.LLMCalls)
+	if r.Evidence != "" {
+		fmt.Printf("Evidence: %s\n", r.Evidence)
+	}
+	if r.Error != "" {
+		fmt.Printf("Error: %s\n", r.Error)
+	}
+	fmt.Printf("Gold SQL: %s\n", r.GoldSQL)
+	inference.RenderTrajectory(os.Stdout, t, *prompts)
+}
+
+// loadResults reads an eval results.json
+func loadResults(path string) ([]EvalResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	var results []EvalResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse results: %w", err)
+	}
+	return results, nil
+}
+
+// trajectoryOf returns the recorded trajectory, or rebuilds one from react_steps for older results
+func trajectoryOf(r EvalResult) *inference.Trajectory {
+	if r.Trajectory != nil {
+		if r.Trajectory.Version > inference.TrajectoryVersion {
+			log.Printf("⚠️  Trajectory version %d is newer than this build (%d), rendering may be incomplete", r.Trajectory.Version, inference.TrajectoryVersion)
+		}
+		return r.Trajectory
+	}
+	return &inference.Trajectory{
+		Version:      inference.TrajectoryVersion,
+		Query:        r.Question,
+		GeneratedSQL: r.GeneratedSQL,
+		Steps:        r.ReActSteps,
+	}
+}
+
+// listResults prints one line per example
+func listResults(results []EvalResult) {
+	fmt.Printf("%5s  %-8s %-24s %5s  %s\n", "INDEX", "STATUS", "DB", "STEPS", "QUESTION")
+	for i, r := range results {
+		t := trajectoryOf(r)
+		question := strings.ReplaceAll(r.Question, "\n", " ")
+		if len(question) > 80 {
+			question = question[:80] + "..."
+		}
+		fmt.Printf("%5d  %-8s %-24s %5d  %s\n", i, r.Status, r.DbID, len(t.Steps), question)
+	}
+	fmt.Printf("\n%d examples. Replay one with: eval replay -index N <results.json>\n", len(results))
+}
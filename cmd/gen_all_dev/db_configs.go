@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// dbSource one database of a batch
+type dbSource struct {
+	Name   string            // Context file name (<output-dir>/<name>.json)
+	Config *adapter.DBConfig // Connection
+	Dir    string            // Directory with schema.sql, database_description/ and synonyms.json ("" = none)
+}
+
+// file a metadata file of the database ("" without a metadata directory, which os.Stat rejects)
+func (s dbSource) file(name string) string {
+	if s.Dir == "" {
+		return ""
+	}
+	return filepath.Join(s.Dir, name)
+}
+
+// contextName the database name recorded in the context: the server database of a live
+// database (what context_validate connects to), else the source name
+func (s dbSource) contextName() string {
+	if s.Config.Type != "sqlite" && s.Config.Type != "csv" && s.Config.Database != "" {
+		return s.Config.Database
+	}
+	return s.Name
+}
+
+// benchmarkSources the SQLite databases of a Spider/BIRD directory
+// (<db-dir>/<name>/<name>.sqlite, with the benchmark's metadata next to it)
+func benchmarkSources(dbDir string, names []string) []dbSource {
+	sources := make([]dbSource, 0, len(names))
+	for _, name := range names {
+		sources = append(sources, dbSource{
+			Name:   name,
+			Config: &adapter.DBConfig{Type: "sqlite", FilePath: filepath.Join(dbDir, name, name+".sqlite")},
+			Dir:    filepath.Join(dbDir, name),
+		})
+	}
+	return sources
+}
+
+// dbConfigFile a -db-configs file: one database object, or an array of them
+type dbConfigFile struct {
+	Name        string `json:"name"` // Context file name (default: database, else the file_path base name)
+	Type        string `json:"type"` // sqlite | mysql | postgresql | csv
+	Host        string `json:"host"`
+	Port        int    `json:"port"` // 0 = the server type's default port
+	Database    string `json:"database"`
+	User        string `json:"user"`
+	Password    string `json:"password"`
+	PasswordEnv string `json:"password_env"` // Environment variable holding the password
+	FilePath    string `json:"file_path"`    // sqlite file / csv directory (relative to the config file)
+	MetadataDir string `json:"metadata_dir"` // schema.sql, database_description/, synonyms.json (relative to the config file)
+}
+
+// defaultPorts server port used when a config leaves port out
+var defaultPorts = map[string]int{"mysql": 3306, "postgresql": 5432}
+
+// loadDBConfigs reads the databases of a comma-separated list of config files and
+// directories (every *.json of a directory, sorted)
+func loadDBConfigs(list string) ([]dbSource, error) {
+	var files []string
+	for _, path := range strings.Split(list, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	var sources []dbSource
+	seen := make(map[string]string)
+	for _, file := range files {
+		loaded, err := loadDBConfigFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, source := range loaded {
+			if other, dup := seen[source.Name]; dup {
+				return nil, fmt.Errorf("%s: database name %q is already used by %s (set \"name\")", file, source.Name, other)
+			}
+			seen[source.Name] = file
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no database configs in %s", list)
+	}
+	return sources, nil
+}
+
+// loadDBConfigFile reads and checks the databases of one config file
+func loadDBConfigFile(file string) ([]dbSource, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	var configs []dbConfigFile
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &configs)
+	} else {
+		var config dbConfigFile
+		err = json.Unmarshal(data, &config)
+		configs = []dbConfigFile{config}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	base := filepath.Dir(file)
+	relative := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(base, path)
+	}
+
+	sources := make([]dbSource, 0, len(configs))
+	for i, c := range configs {
+		c.Type = strings.ToLower(strings.TrimSpace(c.Type))
+		switch c.Type {
+		case "sqlite", "csv":
+			if c.FilePath == "" {
+				return nil, fmt.Errorf("%s: database %d (%s) needs file_path", file, i+1, c.Type)
+			}
+		case "mysql", "postgresql":
+			if c.Database == "" {
+				return nil, fmt.Errorf("%s: database %d (%s) needs database", file, i+1, c.Type)
+			}
+		default:
+			return nil, fmt.Errorf("%s: database %d has unsupported type %q (sqlite | mysql | postgresql | csv)", file, i+1, c.Type)
+		}
+
+		password := c.Password
+		if c.PasswordEnv != "" {
+			password = os.Getenv(c.PasswordEnv)
+		}
+		source := dbSource{
+			Name: c.Name,
+			Config: &adapter.DBConfig{
+				Type:     c.Type,
+				Host:     c.Host,
+				Port:     c.Port,
+				Database: c.Database,
+				User:     c.User,
+				Password: password,
+				FilePath: relative(c.FilePath),
+			},
+			Dir: relative(c.MetadataDir),
+		}
+		if source.Name == "" {
+			source.Name = c.Database
+		}
+		if source.Name == "" {
+			source.Name = strings.TrimSuffix(filepath.Base(c.FilePath), filepath.Ext(c.FilePath))
+		}
+		if c.Type == "mysql" || c.Type == "postgresql" {
+			if source.Config.Host == "" {
+				source.Config.Host = "localhost"
+			}
+			if source.Config.Port == 0 {
+				source.Config.Port = defaultPorts[c.Type]
+			}
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
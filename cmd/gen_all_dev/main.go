@@ -12,12 +12,17 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"reactsql/internal/adapter"
 	"reactsql/internal/agent"
 	contextpkg "reactsql/internal/context"
+	"reactsql/internal/inference"
 	"reactsql/internal/llm"
 	"reactsql/internal/logger"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/llms"
 )
 
 // devQueryEntry represents one entry in the Spider dev JSON file
@@ -25,12 +30,55 @@ type devQueryEntry struct {
 	DBID string `json:"db_id"`
 }
 
+// genOptions per-database generation settings shared by every database of a run
+type genOptions struct {
+	sampleRows int
+	thresholds *contextpkg.QualityThresholds
+	loadSchema bool // Load <db>/schema.sql (Spider)
+
+	// Tables that get a worker (include/exclude globs; system and backup_* tables skipped)
+	tableFilter agent.TableFilter
+
+	// Record undeclared foreign keys found by value overlap
+	inferFKs bool
+
+	// Deterministic contexts only: introspection, quality checks and value stats (no LLM calls)
+	noLLM bool
+
+	// Per-table worker LLM budget (0 = unlimited); over it, a table keeps deterministic stats only
+	tableMaxTokens int
+	tableMaxCalls  int
+
+	// Per-agent deadlines (0 = none); a timed-out table keeps its partial metadata
+	coordinatorTimeout time.Duration
+	tableTimeout       time.Duration
+
+	// Second pass over each table's notes by a reviewer agent (one extra LLM call per table)
+	review bool
+
+	// How thoroughly workers analyze each table (check limits, LLM phases)
+	depth agent.AnalysisDepth
+
+	// Business glossary file loaded into every database's context ("" = none)
+	glossary string
+
+	// Also write <db>.ctxdb, updated one table per transaction as workers finish
+	contextStore bool
+
+	// Coordinator and worker prompt templates (built-in profile or a template directory)
+	prompts *agent.PromptProfile
+
+	// Spider tables.json entries by db_id; preferred over schema.sql when present
+	spiderTables map[string]*contextpkg.SpiderDatabase
+}
+
 // Default paths
 var defaultGenPaths = map[string]map[string]string{
 	"spider": {
-		"dev-file":   "benchmarks/spider_corrected/dev_with_fields.json",
-		"db-dir":     "benchmarks/spider/database",
-		"output-dir": "contexts/sqlite/spider",
+		"dev-file":    "benchmarks/spider_corrected/dev_with_fields.json",
+		"tables-file": "benchmarks/spider/tables.json",
+		"db-dir":      "benchmarks/spider/database",
+		"output-dir":  "contexts/sqlite/spider",
 	},
 	"bird": {
 		"db-dir":     "benchmarks/bird/dev/dev_databases",
@@ -40,18 +88,36 @@ var defaultGenPaths = map[string]map[string]string{
 
 func main() {
 	benchmark := flag.String("benchmark", "", "Benchmark: spider | bird (if empty, will ask interactively)")
+	dbConfigs := flag.String("db-configs", "", "Comma-separated database config JSON files or directories of them (sqlite | mysql | postgresql | csv); replaces -benchmark, needs -output-dir")
 	modelType := flag.String("model", "deepseek-v3", "Model: deepseek-v3 | deepseek-v3.2 | qwen-max | qwen3-max | qwen3.5 | doubao-seed2-pro | qwen3-coder-plus | ali-deepseek-v3.2")
 	workers := flag.Int("workers", 2, "Number of concurrent workers")
 	skipExisting := flag.Bool("skip-existing", true, "Skip databases that already have Rich Context")
 	devFile := flag.String("dev-file", "", "Spider dev dataset JSON file path (auto-detected)")
+	tablesFile := flag.String("tables-file", "", "Spider tables.json with table, column and FK metadata (auto-detected; falls back to schema.sql)")
 	dbDir := flag.String("db-dir", "", "Database directory (auto-detected)")
 	outputDir := flag.String("output-dir", "", "Output directory (auto-detected)")
+	sampleRows := flag.Int("sample-rows", 0, "Representative rows stored per table (PII-masked, truncated; 0 = none)")
+	inferFKs := flag.Bool("infer-fks", true, "Infer undeclared foreign keys from id-like column names and value overlap (recorded with a confidence)")
+	review := flag.Bool("review", false, "After each worker, let a reviewer agent check the table's notes against fresh sample rows and delete, rewrite or keep them (one extra LLM call per table)")
+	noLLM := flag.Bool("no-llm", false, "Build contexts from introspection, quality checks and value stats only (no coordinator/worker LLM calls, no summary)")
+	tableMaxTokens := flag.Int("table-max-tokens", 0, "LLM tokens a worker may spend on one table before falling back to deterministic stats only (0 = unlimited)")
+	tableMaxCalls := flag.Int("table-max-calls", 0, "LLM calls a worker may make for one table before falling back to deterministic stats only (0 = unlimited)")
+	tableTimeout := flag.Duration("table-timeout", 20*time.Minute, "Deadline of one table's worker; on expiry the table keeps the metadata collected so far (0 = none)")
+	coordinatorTimeout := flag.Duration("coordinator-timeout", 5*time.Minute, "Deadline of table discovery; on expiry the tables found so far are analyzed (0 = none)")
+	includeTables := flag.String("include-tables", "", "Comma-separated table globs to analyze (e.g. \"order*,customer\"; empty = all)")
+	excludeTables := flag.String("exclude-tables", "", "Comma-separated table globs to skip (sqlite_*, pg_* and backup_* are always skipped unless included)")
+	thresholdsFile := flag.String("qc-thresholds", "", "JSON file with quality check thresholds (null_heavy_percent, enum_max_distinct, orphan_critical_percent, ...; empty = defaults)")
+	glossary := flag.String("glossary", "", "Business glossary JSON (term → definition, or [{term, definition, columns}]) stored in every context and shown to workers and SQL generation; <db>/glossary.json is merged per database")
+	depth := flag.String("depth", string(agent.DepthStandard), "Analysis depth: fast (checks on 10 key/leading columns, no orphan or join quality checks, no column descriptions) | standard | deep (no check sampling, longer Phase 2)")
+	promptProfile := flag.String("prompt-profile", agent.DefaultPromptProfile, "Agent prompts: built-in profile (semantics | quality) or a directory of *.tmpl files overriding them")
+	qcSampleAbove := flag.Int64("qc-sample-above", 0, "Run scan-heavy quality checks on a sample for tables with more rows than this (overrides sample_above_rows; 0 = from -qc-thresholds)")
+	contextStore := flag.Bool("context-store", false, "Also write <db>.ctxdb, a SQLite context store updated one table per transaction as workers finish; eval and the pipeline load it in place of <db>.json (per-table lazy loading)")
 	flag.Parse()
 
 	reader := bufio.NewReader(os.Stdin)
 
-	// ── Step 1: Select benchmark ──
-	if *benchmark == "" {
+	// ── Step 1: Select benchmark (not with -db-configs) ──
+	if *benchmark == "" && *dbConfigs == "" {
 		fmt.Println()
 		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 		fmt.Println("🧠 Rich Context Generator")
@@ -91,7 +157,10 @@ func main() {
 		}
 	}
 
-	if *benchmark != "spider" && *benchmark != "bird" {
+	if *dbConfigs != "" && *outputDir == "" {
+		log.Fatalf("❌ -db-configs needs -output-dir")
+	}
+	if *dbConfigs == "" && *benchmark != "spider" && *benchmark != "bird" {
 		log.Fatalf("Unknown benchmark: %s. Use 'spider' or 'bird'.", *benchmark)
 	}
 
@@ -145,25 +214,89 @@ func main() {
 	if *devFile == "" {
 		*devFile = paths["dev-file"]
 	}
+	if *tablesFile == "" {
+		*tablesFile = paths["tables-file"]
+	}
 	resolvedDBDir := resolveDir(*dbDir, paths["db-dir"])
 	resolvedOutputDir := resolveDir(*outputDir, paths["output-dir"])
 
 	// Validate paths
-	if _, err := os.Stat(resolvedDBDir); os.IsNotExist(err) {
+	if _, err := os.Stat(resolvedDBDir); os.IsNotExist(err) && *dbConfigs == "" {
 		log.Fatalf("❌ Database directory not found: %s\n   Please download the %s benchmark databases first.", resolvedDBDir, *benchmark)
 	}
 
 	model := parseModelType(*modelType)
 
+	opts := genOptions{sampleRows: *sampleRows, noLLM: *noLLM, inferFKs: *inferFKs, tableMaxTokens: *tableMaxTokens, tableMaxCalls: *tableMaxCalls,
+		coordinatorTimeout: *coordinatorTimeout, tableTimeout: *tableTimeout, review: *review, glossary: *glossary, contextStore: *contextStore}
+	opts.tableFilter = agent.TableFilter{
+		Include: agent.ParseTablePatterns(*includeTables),
+		Exclude: agent.ParseTablePatterns(*excludeTables),
+	}
+	if err := opts.tableFilter.Validate(); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if *glossary != "" {
+		if _, err := os.Stat(*glossary); err != nil {
+			log.Fatalf("❌ Glossary file: %v", err)
+		}
+	}
+	prompts, err := agent.LoadPromptProfile(*promptProfile)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	opts.prompts = prompts
+	if *thresholdsFile != "" {
+		loaded, err := contextpkg.LoadQualityThresholds(*thresholdsFile)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		opts.thresholds = loaded
+	}
+	if opts.depth, err = agent.ParseAnalysisDepth(*depth); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	opts.thresholds = opts.depth.QualityThresholds(opts.thresholds)
+	if *qcSampleAbove > 0 {
+		if opts.thresholds == nil {
+			opts.thresholds = &contextpkg.QualityThresholds{}
+		}
+		opts.thresholds.SampleAboveRows = *qcSampleAbove
+	}
+
+	if *dbConfigs != "" {
+		sources, err := loadDBConfigs(*dbConfigs)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		runConfigs(model, *dbConfigs, sources, resolvedOutputDir, *workers, opts, *skipExisting)
+		return
+	}
+
 	switch *benchmark {
 	case "spider":
-		runSpider(model, *devFile, resolvedDBDir, resolvedOutputDir, *workers, *skipExisting)
+		runSpider(model, *devFile, *tablesFile, resolvedDBDir, resolvedOutputDir, *workers, opts, *skipExisting)
 	case "bird":
-		runBird(model, resolvedDBDir, resolvedOutputDir, *workers, *skipExisting)
+		runBird(model, resolvedDBDir, resolvedOutputDir, *workers, opts, *skipExisting)
 	}
 }
 
-// countExistingContexts counts .json files in a directory
+// modelLabel model shown in the run header
+func modelLabel(model llm.ModelType, opts genOptions) string {
+	if opts.noLLM {
+		return "none (-no-llm: deterministic only)"
+	}
+	label := llm.GetModelDisplayName(model)
+	if opts.prompts != nil && opts.prompts.Name != agent.DefaultPromptProfile {
+		label += fmt.Sprintf(" (prompts: %s)", opts.prompts.Name)
+	}
+	if opts.depth != "" && opts.depth != agent.DepthStandard {
+		label += fmt.Sprintf(" (depth: %s)", opts.depth)
+	}
+	return label
+}
+
+// countExistingContexts counts the context files in a directory
 func countExistingContexts(dir string) int {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -171,7 +304,7 @@ func countExistingContexts(dir string) int {
 	}
 	count := 0
 	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+		if !e.IsDir() && contextpkg.IsContextFile(e.Name()) {
 			count++
 		}
 	}
@@ -213,18 +346,19 @@ func parseModelType(modelType string) llm.ModelType {
 
 // ─────────────────────────────────────────────────────
 
-func runSpider(model llm.ModelType, devFile, dbDir, outputDir string, workerCount int, skipExisting bool) {
+func runSpider(model llm.ModelType, devFile, tablesFile, dbDir, outputDir string, workerCount int, opts genOptions, skipExisting bool) {
 	existingCount := countExistingContexts(outputDir)
 	fmt.Println()
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	fmt.Println("🚀 Spider — Rich Context Generator")
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	fmt.Printf("  Dev file:      %s\n", devFile)
+	fmt.Printf("  Tables file:   %s\n", tablesFile)
 	fmt.Printf("  DB dir:        %s\n", dbDir)
 	fmt.Printf("  Output dir:    %s\n", outputDir)
 	fmt.Printf("  Workers:       %d\n", workerCount)
 	fmt.Printf("  Skip existing: %v\n", skipExisting)
-	fmt.Printf("  Model:         %s\n", llm.GetModelDisplayName(model))
+	fmt.Printf("  Model:         %s\n", modelLabel(model, opts))
 	if existingCount > 0 {
 		fmt.Printf("  Existing:      %d contexts already generated\n", existingCount)
 	}
@@ -238,8 +372,15 @@ func runSpider(model llm.ModelType, devFile, dbDir, outputDir string, workerCoun
 	}
 	fmt.Printf("Found %d databases in Spider dev set\n\n", len(databases))
 
-	databases = filterExisting(databases, outputDir, skipExisting)
-	runBatch(model, databases, dbDir, outputDir, workerCount, true)
+	// 2. tables.json carries the annotated FKs; without it each database falls back to schema.sql
+	opts.loadSchema = true
+	if spiderTables, err := contextpkg.LoadSpiderTables(tablesFile); err != nil {
+		fmt.Printf("⚠️  %v (falling back to schema.sql)\n\n", err)
+	} else {
+		opts.spiderTables = spiderTables
+	}
+
+	runBatch(model, filterExisting(benchmarkSources(dbDir, databases), outputDir, skipExisting), outputDir, workerCount, opts)
 }
 
 // extractSpiderDevDBIDs reads the dev JSON file and returns sorted unique db_ids
@@ -271,7 +412,7 @@ func extractSpiderDevDBIDs(devFile string) ([]string, error) {
 // BIRD: scans database directory
 // ─────────────────────────────────────────────────────
 
-func runBird(model llm.ModelType, dbDir, outputDir string, workerCount int, skipExisting bool) {
+func runBird(model llm.ModelType, dbDir, outputDir string, workerCount int, opts genOptions, skipExisting bool) {
 	existingCount := countExistingContexts(outputDir)
 	fmt.Println()
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
@@ -281,7 +422,7 @@ func runBird(model llm.ModelType, dbDir, outputDir string, workerCount int, skip
 	fmt.Printf("  Output dir:    %s\n", outputDir)
 	fmt.Printf("  Workers:       %d\n", workerCount)
 	fmt.Printf("  Skip existing: %v\n", skipExisting)
-	fmt.Printf("  Model:         %s\n", llm.GetModelDisplayName(model))
+	fmt.Printf("  Model:         %s\n", modelLabel(model, opts))
 	if existingCount > 0 {
 		fmt.Printf("  Existing:      %d contexts already generated\n", existingCount)
 	}
@@ -303,32 +444,67 @@ func runBird(model llm.ModelType, dbDir, outputDir string, workerCount int, skip
 	sort.Strings(databases)
 	fmt.Printf("Found %d databases in BIRD dev set\n\n", len(databases))
 
-	databases = filterExisting(databases, outputDir, skipExisting)
-	runBatch(model, databases, dbDir, outputDir, workerCount, false)
+	runBatch(model, filterExisting(benchmarkSources(dbDir, databases), outputDir, skipExisting), outputDir, workerCount, opts)
+}
+
+// ─────────────────────────────────────────────────────
+// DB configs: databases listed in config files (any adapter type)
+// ─────────────────────────────────────────────────────
+
+func runConfigs(model llm.ModelType, configList string, sources []dbSource, outputDir string, workerCount int, opts genOptions, skipExisting bool) {
+	existingCount := countExistingContexts(outputDir)
+	fmt.Println()
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("🚀 DB configs — Rich Context Generator")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Printf("  Configs:       %s\n", configList)
+	fmt.Printf("  Output dir:    %s\n", outputDir)
+	fmt.Printf("  Workers:       %d\n", workerCount)
+	fmt.Printf("  Skip existing: %v\n", skipExisting)
+	fmt.Printf("  Model:         %s\n", modelLabel(model, opts))
+	if existingCount > 0 {
+		fmt.Printf("  Existing:      %d contexts already generated\n", existingCount)
+	}
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println()
+
+	fmt.Printf("Found %d databases in the configs\n", len(sources))
+	for _, src := range sources {
+		location := src.Config.FilePath
+		if location == "" {
+			location = fmt.Sprintf("%s:%d/%s", src.Config.Host, src.Config.Port, src.Config.Database)
+		}
+		fmt.Printf("  %-20s %-10s %s\n", src.Name, src.Config.Type, location)
+	}
+	fmt.Println()
+
+	// schema.sql is read when a config names a metadata_dir holding one
+	opts.loadSchema = true
+	runBatch(model, filterExisting(sources, outputDir, skipExisting), outputDir, workerCount, opts)
 }
 
 // ─────────────────────────────────────────────────────
 // Common batch runner
 // ─────────────────────────────────────────────────────
 
-func filterExisting(databases []string, outputDir string, skipExisting bool) []string {
+func filterExisting(databases []dbSource, outputDir string, skipExisting bool) []dbSource {
 	if !skipExisting {
 		return databases
 	}
-	var toProcess []string
+	var toProcess []dbSource
 	for _, db := range databases {
-		outputFile := filepath.Join(outputDir, db+".json")
+		outputFile := filepath.Join(outputDir, db.Name+".json")
 		if _, err := os.Stat(outputFile); os.IsNotExist(err) {
 			toProcess = append(toProcess, db)
 		} else {
-			fmt.Printf("⏭️  Skip %s (already exists)\n", db)
+			fmt.Printf("⏭️  Skip %s (already exists)\n", db.Name)
 		}
 	}
 	fmt.Printf("\nNeed to process %d databases\n\n", len(toProcess))
 	return toProcess
 }
 
-func runBatch(model llm.ModelType, databases []string, dbDir, outputDir string, workerCount int, loadSchema bool) {
+func runBatch(model llm.ModelType, databases []dbSource, outputDir string, workerCount int, opts genOptions) {
 	if len(databases) == 0 {
 		fmt.Println("All databases already have Rich Context. Nothing to do.")
 		return
@@ -339,31 +515,44 @@ func runBatch(model llm.ModelType, databases []string, dbDir, outputDir string,
 	}
 
 	// Create multi-progress display
+	names := make([]string, len(databases))
+	for i, db := range databases {
+		names[i] = db.Name
+	}
 	mp := logger.NewMultiProgress(
 		fmt.Sprintf("🚀 Processing %d databases (workers: %d)", len(databases), workerCount),
-		databases,
+		names,
 	)
 	mp.Start()
 
+	report := loadGenerationReport(outputDir, model)
+
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, workerCount)
 
-	for _, dbName := range databases {
+	for _, db := range databases {
 		wg.Add(1)
 		semaphore <- struct{}{}
 
-		go func(name string) {
+		go func(src dbSource) {
 			defer wg.Done()
 			defer func() { <-semaphore }()
 
+			name := src.Name
 			mp.StartTask(name)
 
-			if err := processDatabase(model, dbDir, outputDir, name, loadSchema, mp); err != nil {
+			entry := databaseReport{Database: name, Depth: string(opts.depth)}
+			start := time.Now()
+			if err := processDatabase(model, src, outputDir, opts, mp, &entry); err != nil {
+				entry.Error = err.Error()
 				mp.FailTask(name, err)
 			} else {
 				mp.CompleteTask(name)
 			}
-		}(dbName)
+			entry.DurationSec = time.Since(start).Seconds()
+			entry.GeneratedAt = time.Now().Format(time.RFC3339)
+			report.Record(entry)
+		}(db)
 	}
 
 	wg.Wait()
@@ -372,14 +561,21 @@ func runBatch(model llm.ModelType, databases []string, dbDir, outputDir string,
 	// Print summary
 	fmt.Print(mp.Summary())
 	fmt.Printf("✅ Rich Context files saved to: %s\n", outputDir)
+
+	if err := report.Save(outputDir); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	} else {
+		fmt.Printf("💰 Generation cost (%s): %s\n", filepath.Join(outputDir, contextpkg.GenerationReportFile), report.Summary())
+	}
 }
 
 // ─────────────────────────────────────────────────────
 // Single database processing (shared by spider & bird)
 // ─────────────────────────────────────────────────────
 
-func processDatabase(model llm.ModelType, dbDir, outputDir, dbName string, loadSchema bool, mp *logger.MultiProgress) error {
+func processDatabase(model llm.ModelType, src dbSource, outputDir string, opts genOptions, mp *logger.MultiProgress, entry *databaseReport) error {
 	ctx := context.Background()
+	dbName := src.Name
 
 	// Helper to update progress display
 	update := func(phase string, progress int) {
@@ -391,11 +587,7 @@ func processDatabase(model llm.ModelType, dbDir, outputDir, dbName string, loadS
 	update("Connecting...", 0)
 
 	// 1. Create adapter
-	dbPath := filepath.Join(dbDir, dbName, dbName+".sqlite")
-	dbAdapter, err := adapter.NewAdapter(&adapter.DBConfig{
-		Type:     "sqlite",
-		FilePath: dbPath,
-	})
+	dbAdapter, err := adapter.NewAdapter(src.Config)
 	if err != nil {
 		return fmt.Errorf("failed to create adapter: %w", err)
 	}
@@ -406,14 +598,21 @@ func processDatabase(model llm.ModelType, dbDir, outputDir, dbName string, loadS
 	defer dbAdapter.Close()
 
 	// 2. Create SharedContext (quiet mode for multi-progress)
-	sharedCtx := contextpkg.NewSharedContext(dbName, "sqlite")
+	sharedCtx := contextpkg.NewSharedContext(src.contextName(), src.Config.Type)
+	sharedCtx.SampleRowCount = opts.sampleRows
+	sharedCtx.QualityThresholds = opts.thresholds
+	if !opts.noLLM {
+		sharedCtx.ModelName = llm.GetModelByType(model).ModelName
+	}
 	if mp != nil {
 		sharedCtx.Quiet = true
 	}
 
-	// 2.1 Load schema.sql if available
-	if loadSchema {
-		schemaPath := filepath.Join(dbDir, dbName, "schema.sql")
+	// 2.1 Load the tables.json entry, else schema.sql if available
+	if spiderDB, ok := opts.spiderTables[dbName]; ok {
+		sharedCtx.LoadSpiderSchema(spiderDB)
+	} else if opts.loadSchema {
+		schemaPath := src.file("schema.sql")
 		if _, err := os.Stat(schemaPath); err == nil {
 			if err := sharedCtx.LoadSchemaFromFile(schemaPath); err != nil && !sharedCtx.Quiet {
 				fmt.Printf("[%s] ⚠️  Warning: failed to load schema.sql: %v\n", dbName, err)
@@ -421,11 +620,35 @@ func processDatabase(model llm.ModelType, dbDir, outputDir, dbName string, loadS
 		}
 	}
 
-	// 3. Create LLM
-	update("Creating LLM...", 5)
-	llmInstance, err := llm.CreateLLMByType(model)
+	// 2.1b Business glossary: the -glossary file, then the database's own glossary.json
+	for _, path := range []string{opts.glossary, src.file("glossary.json")} {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if _, err := sharedCtx.LoadGlossaryFile(path); err != nil {
+			return err
+		}
+	}
+
+	// 2.2 Tables finished by an interrupted run of this database
+	outputFile := filepath.Join(outputDir, dbName+".json")
+	checkpointPath := contextpkg.CheckpointPath(outputFile)
+	checkpoint, completedTables, err := contextpkg.LoadCheckpoint(checkpointPath)
 	if err != nil {
-		return fmt.Errorf("failed to create LLM: %w", err)
+		fmt.Printf("[%s] ⚠️  Ignoring checkpoint: %v\n", dbName, err)
+		checkpoint, completedTables = nil, nil
+	}
+
+	// 3. Create LLM (none with -no-llm)
+	var llmInstance *llm.UsageModel
+	if !opts.noLLM {
+		update("Creating LLM...", 5)
+		baseLLM, err := llm.CreateLLMByType(model)
+		if err != nil {
+			return fmt.Errorf("failed to create LLM: %w", err)
+		}
+		llmInstance = llm.NewUsageModel(baseLLM)
+		defer func() { entry.LLM = llmInstance.Usage() }()
 	}
 
 	// 4. Phase 1: Coordinator Agent discovers tables
@@ -441,28 +664,76 @@ func processDatabase(model llm.ModelType, dbDir, outputDir, dbName string, loadS
 	if err != nil {
 		return fmt.Errorf("failed to create coordinator: %w", err)
 	}
+	coordinator.SetTableFilter(opts.tableFilter)
+	coordinator.SetTimeout(opts.coordinatorTimeout)
+	coordinator.SetPromptProfile(opts.prompts)
 
-	if err := coordinator.Execute(ctx); err != nil {
+	if opts.noLLM {
+		err = coordinator.ExecuteWithoutLLM(ctx)
+	} else {
+		err = coordinator.Execute(ctx)
+	}
+	if err != nil {
 		return fmt.Errorf("coordinator failed: %w", err)
 	}
+	entry.SkippedTables = coordinator.SkippedTables()
+	if len(entry.SkippedTables) > 0 && !sharedCtx.Quiet {
+		fmt.Printf("[%s] ⏭️  Skipped %d tables: %s\n", dbName, len(entry.SkippedTables), strings.Join(entry.SkippedTables, ", "))
+	}
 
-	// 5. Phase 2: Worker Agents analyze tables in parallel
+	// 5. Phase 2: Worker Agents analyze tables in parallel (checkpointed tables are restored instead)
+	resumed := make(map[string]bool, len(completedTables))
+	if checkpoint != nil {
+		for _, name := range completedTables {
+			resumed[name] = true
+		}
+	}
 	tasks := sharedCtx.GetAllTasks()
 	var workerTasks []*contextpkg.TaskInfo
+	var completed []string // Checkpointed tables, then each table finished in this run
 	for _, task := range tasks {
-		if task.AgentID != "coordinator" {
-			workerTasks = append(workerTasks, task)
+		if task.AgentID == "coordinator" {
+			continue
+		}
+		if tableName := task.ID[8:]; resumed[tableName] {
+			completed = append(completed, tableName)
+			sharedCtx.CompleteTask(task.ID, map[string]interface{}{"table": tableName, "resumed": true})
+			continue
+		}
+		workerTasks = append(workerTasks, task)
+	}
+	if len(completed) > 0 {
+		sharedCtx.RestoreTables(checkpoint, completed)
+		entry.ResumedTables = len(completed)
+		if !sharedCtx.Quiet {
+			fmt.Printf("[%s] ♻️  Resumed %d tables from %s\n", dbName, len(completed), checkpointPath)
 		}
 	}
 
 	totalWorkers := len(workerTasks)
+	entry.Tables = totalWorkers + len(completed)
 	update(fmt.Sprintf("Phase 2: Analyzing %d tables", totalWorkers), 20)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output dir: %w", err)
+	}
 
 	if !sharedCtx.Quiet {
 		progLogger = logger.NewLogger(totalWorkers)
 		progLogger.SetPhase(fmt.Sprintf("[%s] Phase 2: Analyzing %d Tables", dbName, totalWorkers))
 	}
 
+	// 5.0 Context store: reset to the resumed tables, then one row per finished table
+	var store *contextpkg.ContextStore
+	if opts.contextStore {
+		if store, err = contextpkg.OpenContextStore(filepath.Join(outputDir, dbName+contextpkg.ContextStoreExt)); err != nil {
+			return err
+		}
+		defer store.Close()
+		if err := store.Save(sharedCtx); err != nil {
+			return fmt.Errorf("failed to reset context store: %w", err)
+		}
+	}
+
 	var wg sync.WaitGroup
 	var completedWorkers int32 = 0
 	var workerMu sync.Mutex
@@ -478,22 +749,86 @@ func processDatabase(model llm.ModelType, dbDir, outputDir, dbName string, loadS
 				progLogger.StartTask(tblName)
 			}
 
-			worker, err := agent.NewWorkerAgent(agentID, taskID, tblName, llmInstance, dbAdapter, sharedCtx)
+			// A model per table (uncapped without -table-max-*) so the worker's telemetry
+			// counts this table's calls and tokens only
+			var workerLLM llms.Model = llmInstance
+			if !opts.noLLM {
+				workerLLM = llm.NewBudgetModel(llmInstance, opts.tableMaxTokens, opts.tableMaxCalls)
+			}
+			worker, err := agent.NewWorkerAgent(agentID, taskID, tblName, workerLLM, dbAdapter, sharedCtx)
 			if err != nil {
 				if !sharedCtx.Quiet {
 					progLogger.FailTask(tblName, err)
 				}
+				workerMu.Lock()
+				entry.FailedTables = append(entry.FailedTables, tblName)
+				workerMu.Unlock()
 				return
 			}
+			worker.SetTimeout(opts.tableTimeout)
+			worker.SetPromptProfile(opts.prompts)
+			worker.SetDepth(opts.depth)
 
-			if err := worker.Execute(ctx); err != nil {
+			if opts.noLLM {
+				err = worker.ExecuteWithoutLLM(ctx)
+			} else {
+				err = worker.Execute(ctx)
+			}
+			if err == nil && opts.review && !opts.noLLM && !worker.TimedOut() && !worker.BudgetExceeded() {
+				result, reviewErr := reviewTable(ctx, opts, tblName, workerLLM, dbAdapter, sharedCtx)
+				if reviewErr != nil && !sharedCtx.Quiet {
+					fmt.Printf("[%s] ⚠️  Review of %s failed, keeping its notes: %v\n", dbName, tblName, reviewErr)
+				}
+				workerMu.Lock()
+				entry.ReviewEditedNotes += len(result.Edited)
+				for _, note := range result.Deleted {
+					entry.ReviewDeletedNotes = append(entry.ReviewDeletedNotes, fmt.Sprintf("%s.%s: %s", tblName, note.Key, note.Reason))
+				}
+				workerMu.Unlock()
+			}
+			if err != nil {
 				if !sharedCtx.Quiet {
 					progLogger.FailTask(tblName, err)
 				}
+				workerMu.Lock()
+				entry.FailedTables = append(entry.FailedTables, tblName)
+				workerMu.Unlock()
 			} else {
 				if !sharedCtx.Quiet {
 					progLogger.CompleteTask(tblName)
 				}
+				// Checkpoint after every table, so a crash only loses the tables in flight
+				// (timed-out tables are left out so a resumed run analyzes them again)
+				workerMu.Lock()
+				if worker.BudgetExceeded() {
+					entry.BudgetExceededTables = append(entry.BudgetExceededTables, tblName)
+				}
+				if worker.TimedOut() {
+					entry.TimedOutTables = append(entry.TimedOutTables, tblName)
+				} else {
+					completed = append(completed, tblName)
+					if err := sharedCtx.SaveCheckpoint(checkpointPath, completed); err != nil && !sharedCtx.Quiet {
+						fmt.Printf("[%s] ⚠️  Warning: failed to save checkpoint: %v\n", dbName, err)
+					}
+					if store != nil {
+						if err := store.SaveTable(sharedCtx, tblName); err != nil && !sharedCtx.Quiet {
+							fmt.Printf("[%s] ⚠️  Warning: failed to save %s to the context store: %v\n", dbName, tblName, err)
+						}
+					}
+				}
+				workerMu.Unlock()
+			}
+			if len(worker.Retried()) > 0 {
+				workerMu.Lock()
+				entry.RetriedTables = append(entry.RetriedTables, tblName)
+				workerMu.Unlock()
+			}
+			if rejected := worker.RejectedNotes(); len(rejected) > 0 {
+				workerMu.Lock()
+				for _, note := range rejected {
+					entry.RejectedNotes = append(entry.RejectedNotes, fmt.Sprintf("%s.%s: %s", tblName, note.Key, note.Reason))
+				}
+				workerMu.Unlock()
 			}
 
 			// Update multi-progress: map worker completion to 20%..90% range
@@ -507,21 +842,96 @@ func processDatabase(model llm.ModelType, dbDir, outputDir, dbName string, loadS
 
 	wg.Wait()
 
-	// 6. Analyze JOIN paths
+	// 6. Analyze JOIN paths (over declared and inferred foreign keys)
+	if opts.inferFKs {
+		update("Inferring foreign keys", 91)
+		inferred, err := sharedCtx.InferForeignKeys(ctx, dbAdapter)
+		entry.InferredForeignKeys = inferred
+		if err != nil && !sharedCtx.Quiet {
+			fmt.Printf("[%s] ⚠️  Warning: foreign key inference failed: %v\n", dbName, err)
+		}
+	}
 	update("Analyzing JOIN paths", 92)
 	sharedCtx.AnalyzeJoinPaths()
+	if opts.depth != agent.DepthFast {
+		update("Measuring JOIN quality", 92)
+		if _, err := sharedCtx.AnalyzeJoinQuality(ctx, dbAdapter); err != nil && !sharedCtx.Quiet {
+			fmt.Printf("[%s] ⚠️  Warning: join quality analysis failed: %v\n", dbName, err)
+		}
+	}
 	if !sharedCtx.Quiet {
 		progLogger.PrintSummary()
 	}
 
+	// 6.1 Merge official BIRD column descriptions (database_description/*.csv)
+	descDir := src.file("database_description")
+	if _, err := os.Stat(descDir); err == nil {
+		update("Merging column descriptions", 93)
+		if _, err := sharedCtx.LoadBirdDescriptions(descDir); err != nil && !sharedCtx.Quiet {
+			fmt.Printf("[%s] ⚠️  Warning: failed to load column descriptions: %v\n", dbName, err)
+		}
+		// Descriptions often state units ("in USD", "%"); detect again with them
+		sharedCtx.DetectUnits()
+	}
+
+	// 6.1b Merge user-provided column synonyms (<db>/synonyms.json: "table.column" → terms)
+	synonymsPath := src.file("synonyms.json")
+	if _, err := os.Stat(synonymsPath); err == nil {
+		if _, err := sharedCtx.LoadSynonymsFile(synonymsPath); err != nil && !sharedCtx.Quiet {
+			fmt.Printf("[%s] ⚠️  Warning: failed to load synonyms: %v\n", dbName, err)
+		}
+	}
+
+	// 6.1c Map glossary terms to the collected columns
+	sharedCtx.ResolveGlossaryColumns()
+
+	// 6.1d Whole-database overview (top of the compact prompt), with the merged column descriptions
+	if !opts.noLLM {
+		update("Summarizing database", 93)
+		if err := agent.GenerateDatabaseSummary(ctx, llmInstance, sharedCtx); err != nil && !sharedCtx.Quiet {
+			fmt.Printf("[%s] ⚠️  Warning: failed to generate database summary: %v\n", dbName, err)
+		}
+	}
+
+	// 6.2 Embed tables and columns (configured embedding model, else and with -no-llm the local hashing embedder)
+	update("Embedding schema", 94)
+	var embedder embeddings.Embedder = inference.NewHashEmbedder(0)
+	if embCfg := llm.GetConfig().Embedding; embCfg.ModelName != "" && !opts.noLLM {
+		if embedder, err = llm.CreateEmbedder(embCfg); err != nil {
+			return fmt.Errorf("failed to create embedder: %w", err)
+		}
+	}
+	if err := sharedCtx.EmbedSchema(context.Background(), embedder); err != nil && !sharedCtx.Quiet {
+		fmt.Printf("[%s] ⚠️  Warning: failed to embed schema: %v\n", dbName, err)
+	}
+
 	// 7. Save to file
 	update("Saving context file", 95)
-	os.MkdirAll(outputDir, 0755)
-	outputFile := filepath.Join(outputDir, dbName+".json")
 	if err := sharedCtx.SaveToFile(outputFile); err != nil {
 		return fmt.Errorf("failed to save: %w", err)
 	}
+	// Keys, join paths and descriptions added after Phase 2 touch every table
+	if store != nil {
+		if err := store.Save(sharedCtx); err != nil {
+			return fmt.Errorf("failed to save context store: %w", err)
+		}
+	}
+	os.Remove(checkpointPath)
 
 	update("Done", 100)
 	return nil
 }
+
+// reviewTable runs the reviewer agent over one table's notes, within -table-timeout
+func reviewTable(ctx context.Context, opts genOptions, tableName string, model llms.Model, dbAdapter adapter.DBAdapter, sharedCtx *contextpkg.SharedContext) (*agent.ReviewResult, error) {
+	if opts.tableTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.tableTimeout)
+		defer cancel()
+	}
+	reviewer, err := agent.NewReviewerAgent("reviewer_"+tableName, tableName, model, dbAdapter, sharedCtx)
+	if err != nil {
+		return &agent.ReviewResult{}, err
+	}
+	return reviewer.Execute(ctx)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	contextpkg "reactsql/internal/context"
+	"reactsql/internal/llm"
+)
+
+// databaseReport cost and outcome of generating one database's Rich Context
+type databaseReport struct {
+	Database             string    `json:"database"`
+	Tables               int       `json:"tables"`
+	Depth                string    `json:"depth,omitempty"` // -depth the database was generated with
+	FailedTables         []string  `json:"failed_tables,omitempty"`
+	SkippedTables        []string  `json:"skipped_tables,omitempty"`         // System, backup_* or filtered-out tables with no worker
+	ResumedTables        int       `json:"resumed_tables,omitempty"`         // Restored from the checkpoint of an interrupted run
+	BudgetExceededTables []string  `json:"budget_exceeded_tables,omitempty"` // Hit -table-max-tokens / -table-max-calls; deterministic stats only
+	RetriedTables        []string  `json:"retried_tables,omitempty"`         // A ReAct phase was rerun with the strict prompt
+	TimedOutTables       []string  `json:"timed_out_tables,omitempty"`       // Hit -table-timeout; partial metadata only
+	InferredForeignKeys  int       `json:"inferred_foreign_keys,omitempty"`  // Undeclared keys found by value overlap (-infer-fks)
+	RejectedNotes        []string  `json:"rejected_notes,omitempty"`         // Worker notes dropped for unknown columns or broken SQL ("table.key: reason")
+	ReviewEditedNotes    int       `json:"review_edited_notes,omitempty"`    // Notes rewritten by the reviewer (-review)
+	ReviewDeletedNotes   []string  `json:"review_deleted_notes,omitempty"`   // Notes the reviewer deleted ("table.key: reason")
+	LLM                  llm.Usage `json:"llm"`
+	DurationSec          float64   `json:"duration_sec"`
+	Error                string    `json:"error,omitempty"`
+	GeneratedAt          string    `json:"generated_at"`
+}
+
+// generationTotals sums of all databases in the report
+type generationTotals struct {
+	Databases       int       `json:"databases"`
+	FailedDatabases int       `json:"failed_databases"`
+	Tables          int       `json:"tables"`
+	FailedTables    int       `json:"failed_tables"`
+	CappedTables    int       `json:"budget_exceeded_tables,omitempty"`
+	TimedOutTables  int       `json:"timed_out_tables,omitempty"`
+	LLM             llm.Usage `json:"llm"`
+	DurationSec     float64   `json:"duration_sec"`
+}
+
+// generationReport generation_report.json: per-database LLM cost across runs
+// Runs with -skip-existing only regenerate some databases; their entries replace older ones.
+type generationReport struct {
+	Model     string           `json:"model"`
+	UpdatedAt string           `json:"updated_at"`
+	Totals    generationTotals `json:"totals"`
+	Databases []databaseReport `json:"databases"`
+
+	mu sync.Mutex
+}
+
+// loadGenerationReport reads the report in outputDir, or starts an empty one
+func loadGenerationReport(outputDir string, model llm.ModelType) *generationReport {
+	report := &generationReport{}
+	if data, err := os.ReadFile(filepath.Join(outputDir, contextpkg.GenerationReportFile)); err == nil {
+		if err := json.Unmarshal(data, report); err != nil {
+			fmt.Printf("⚠️  Ignoring unreadable %s: %v\n", contextpkg.GenerationReportFile, err)
+			report = &generationReport{}
+		}
+	}
+	report.Model = llm.GetModelDisplayName(model)
+	return report
+}
+
+// Record adds or replaces the entry of a database
+func (r *generationReport) Record(entry databaseReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.Databases {
+		if r.Databases[i].Database == entry.Database {
+			r.Databases[i] = entry
+			return
+		}
+	}
+	r.Databases = append(r.Databases, entry)
+}
+
+// Save recomputes the totals and writes the report to outputDir
+func (r *generationReport) Save(outputDir string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sort.Slice(r.Databases, func(i, j int) bool { return r.Databases[i].Database < r.Databases[j].Database })
+	r.Totals = generationTotals{Databases: len(r.Databases)}
+	for _, db := range r.Databases {
+		if db.Error != "" {
+			r.Totals.FailedDatabases++
+		}
+		r.Totals.Tables += db.Tables
+		r.Totals.FailedTables += len(db.FailedTables)
+		r.Totals.CappedTables += len(db.BudgetExceededTables)
+		r.Totals.TimedOutTables += len(db.TimedOutTables)
+		r.Totals.LLM.Add(db.LLM)
+		r.Totals.DurationSec += db.DurationSec
+	}
+	r.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal generation report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, contextpkg.GenerationReportFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write generation report: %w", err)
+	}
+	return nil
+}
+
+// Summary one-line totals for the console
+func (r *generationReport) Summary() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.Totals
+	capped := ""
+	if t.CappedTables > 0 {
+		capped = fmt.Sprintf(", %d over the per-table LLM budget", t.CappedTables)
+	}
+	if t.TimedOutTables > 0 {
+		capped += fmt.Sprintf(", %d timed out", t.TimedOutTables)
+	}
+	return fmt.Sprintf("%d databases (%d failed), %d tables (%d failed%s), %d LLM calls, %d tokens (%d prompt + %d completion), %.0fs",
+		t.Databases, t.FailedDatabases, t.Tables, t.FailedTables, capped, t.LLM.Calls,
+		t.LLM.TotalTokens, t.LLM.PromptTokens, t.LLM.CompletionTokens, t.DurationSec)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"reactsql/internal/adapter"
+	contextpkg "reactsql/internal/context"
+)
+
+func main() {
+	contextPath := flag.String("context", "", "Context JSON file (tables, keys and value statistics)")
+	dbPath := flag.String("db", "", "SQLite database file")
+	dbType := flag.String("db-type", "sqlite", "Database type: sqlite | mysql | postgresql")
+	host := flag.String("host", "localhost", "Database host (mysql/postgresql)")
+	port := flag.Int("port", 0, "Database port (mysql/postgresql; 0 = default)")
+	database := flag.String("database", "", "Database name (mysql/postgresql; defaults to the context's)")
+	user := flag.String("user", "", "Database user (mysql/postgresql)")
+	password := flag.String("password", "", "Database password (mysql/postgresql)")
+	format := flag.String("format", "mermaid", "Output format: mermaid | dot | svg (svg needs Graphviz)")
+	output := flag.String("output", "", "Output file (default: stdout)")
+	estimate := flag.Bool("estimate", true, "Count cardinalities from the data when a database is given (else from context statistics)")
+	flag.Parse()
+
+	if *format != "mermaid" && *format != "dot" && *format != "svg" {
+		log.Fatalf("Unknown format %q (mermaid | dot | svg)", *format)
+	}
+	live := *dbPath != "" || *dbType != "sqlite"
+	if *contextPath == "" && !live {
+		fmt.Fprintln(os.Stderr, "Need -context, a database, or both")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+
+	// Schema: the context file, else introspected from the database
+	var sharedCtx *contextpkg.SharedContext
+	if *contextPath != "" {
+		loaded, err := contextpkg.LoadContextFromFile(*contextPath)
+		if err != nil {
+			log.Fatalf("Failed to load context: %v", err)
+		}
+		sharedCtx = loaded
+	}
+
+	var dbAdapter adapter.DBAdapter
+	if live {
+		name := *database
+		if name == "" && sharedCtx != nil {
+			name = sharedCtx.DatabaseName
+		}
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(*dbPath), filepath.Ext(*dbPath))
+		}
+
+		var err error
+		dbAdapter, err = adapter.NewAdapter(&adapter.DBConfig{
+			Type:     *dbType,
+			Host:     *host,
+			Port:     *port,
+			Database: name,
+			User:     *user,
+			Password: *password,
+			FilePath: *dbPath,
+		})
+		if err != nil {
+			log.Fatalf("Failed to create adapter: %v", err)
+		}
+		if err := dbAdapter.Connect(ctx); err != nil {
+			log.Fatalf("Failed to connect: %v", err)
+		}
+		defer dbAdapter.Close()
+
+		if sharedCtx == nil {
+			sharedCtx = contextpkg.NewSharedContext(name, *dbType)
+			sharedCtx.Quiet = true
+			if err := sharedCtx.LoadSchemaFromDB(ctx, dbAdapter); err != nil {
+				log.Fatalf("Failed to read schema: %v", err)
+			}
+		}
+	}
+
+	var rels []contextpkg.Relationship
+	if dbAdapter != nil && *estimate {
+		rels = sharedCtx.EstimateRelationships(ctx, dbAdapter)
+	} else {
+		rels = sharedCtx.Relationships()
+	}
+
+	var out []byte
+	switch *format {
+	case "mermaid":
+		out = []byte(sharedCtx.RenderMermaidER(rels))
+	case "dot":
+		out = []byte(sharedCtx.RenderDOT(rels))
+	case "svg":
+		svg, err := renderSVG(ctx, sharedCtx.RenderDOT(rels))
+		if err != nil {
+			log.Fatalf("Failed to render SVG: %v", err)
+		}
+		out = svg
+	}
+
+	if *output == "" {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := os.WriteFile(*output, out, 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *output, err)
+	}
+	fmt.Fprintf(os.Stderr, "✅ %s diagram of %s (%d tables, %d relationships) written to %s\n",
+		*format, sharedCtx.DatabaseName, len(sharedCtx.Tables), len(rels), *output)
+}
+
+// renderSVG lays out a DOT graph with Graphviz
+func renderSVG(ctx context.Context, dot string) ([]byte, error) {
+	if _, err := exec.LookPath("dot"); err != nil {
+		return nil, fmt.Errorf("graphviz 'dot' not found on PATH; install Graphviz or use -format dot")
+	}
+
+	cmd := exec.CommandContext(ctx, "dot", "-Tsvg")
+	cmd.Stdin = strings.NewReader(dot)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	svg, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("dot failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return svg, nil
+}
@@ -11,6 +11,7 @@ const (
 	MySQL      DatabaseType = "mysql"
 	PostgreSQL DatabaseType = "postgresql"
 	SQLite     DatabaseType = "sqlite"
+	CSV        DatabaseType = "csv"
 )
 
 // DBAdapter database adapter interface
@@ -35,20 +36,90 @@ type DBAdapter interface {
 
 	// DryRunSQL validates SQL syntax (dry run)
 	DryRunSQL(ctx context.Context, sql string) error
+
+	// GetTables lists user tables (system tables excluded)
+	GetTables(ctx context.Context) ([]string, error)
+
+	// GetViews lists user views with their definitions (read-only, queried like tables)
+	GetViews(ctx context.Context) ([]ViewInfo, error)
+
+	// GetColumns lists columns of a table or view in definition order
+	GetColumns(ctx context.Context, table string) ([]ColumnInfo, error)
+
+	// GetForeignKeys lists foreign keys declared on a table
+	GetForeignKeys(ctx context.Context, table string) ([]ForeignKeyInfo, error)
+
+	// GetIndexes lists indexes of a table
+	GetIndexes(ctx context.Context, table string) ([]IndexInfo, error)
+
+	// SampleRows returns up to n rows of a table, masked by rules (nil = no masking)
+	SampleRows(ctx context.Context, table string, n int, rules *MaskingRules) (*QueryResult, error)
+
+	// Ping checks the connection with a round-trip query
+	Ping(ctx context.Context) error
+
+	// Healthy pings and reconnects once if the connection is stale
+	// Returns false if the database is still unreachable
+	Healthy(ctx context.Context) bool
 }
 
 // QueryResult query result (unified structure)
 type QueryResult struct {
 	Columns       []string                 // Column name
 	Rows          []map[string]interface{} // Data rows (unified map format)
+	Values        [][]Value                // Data rows (typed, positional; keeps duplicate column names)
 	RowCount      int                      // Row count
 	ExecutionTime int64                    // Execution time (ms)
 	Error         string                   // Error message (if any)
 }
 
+// ColumnInfo column description (unified across dialects)
+type ColumnInfo struct {
+	Name         string
+	Type         string
+	Nullable     bool
+	DefaultValue string // Empty if no default
+	IsPrimaryKey bool
+	Comment      string // MySQL only
+
+	Collation       string // Explicit/effective collation (empty = engine default, binary)
+	CaseInsensitive bool   // '=' ignores case ('usa' matches 'USA'): NOCASE, *_ci, nondeterministic or citext
+
+	Generated  string // Computed column: GeneratedVirtual or GeneratedStored (empty = regular column)
+	Expression string // Generation expression of a computed column (empty if unknown)
+}
+
+// Generated column kinds
+const (
+	GeneratedVirtual = "virtual" // Computed on read
+	GeneratedStored  = "stored"  // Computed on write and stored
+)
+
+// ViewInfo view description
+type ViewInfo struct {
+	Name       string
+	Definition string // SELECT statement the view is defined by (empty if not readable)
+}
+
+// ForeignKeyInfo foreign key column mapping (one entry per column)
+type ForeignKeyInfo struct {
+	Name             string // Constraint name (SQLite: FK id)
+	ColumnName       string
+	ReferencedTable  string
+	ReferencedColumn string
+}
+
+// IndexInfo index description
+type IndexInfo struct {
+	Name      string
+	Columns   []string // In index order
+	IsUnique  bool
+	IsPrimary bool
+}
+
 // DBConfig database connection config (generic)
 type DBConfig struct {
-	Type     string // Database type: "mysql", "postgresql", "sqlite"
+	Type     string // Database type: "mysql", "postgresql", "sqlite", "csv"
 	Host     string // Host address
 	Port     int    // Port
 	Database string // Database name
@@ -56,7 +127,18 @@ type DBConfig struct {
 	Password string // Password
 
 	// SQLite specific
-	FilePath string // SQLite file path
+	FilePath string            // SQLite file path (csv: directory of CSV and Parquet files)
+	InMemory bool              // SQLite: copy file into memory at connect time
+	Attach   map[string]string // SQLite: extra files attached under schema aliases
+
+	// Transient-error retry in ExecuteQuery (optional, nil = DefaultRetryConfig)
+	Retry *RetryConfig
+
+	// Collation / encoding handling (optional)
+	Text *TextOptions
+
+	// Run queries in a child process with rlimits (optional, nil = in-process)
+	Sandbox *SandboxConfig
 
 	// Connection pool config (optional)
 	MaxOpenConns int // Max open connections
@@ -65,6 +147,13 @@ type DBConfig struct {
 
 // NewAdapter factory: creates adapter based on config
 func NewAdapter(config *DBConfig) (DBAdapter, error) {
+	if err := config.Text.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Sandbox != nil {
+		return NewSandboxAdapter(config, config.Sandbox)
+	}
+
 	switch config.Type {
 	case "mysql":
 		return NewMySQLAdapter(&MySQLConfig{
@@ -73,6 +162,8 @@ func NewAdapter(config *DBConfig) (DBAdapter, error) {
 			Database: config.Database,
 			User:     config.User,
 			Password: config.Password,
+			Retry:    config.Retry,
+			Text:     config.Text,
 		}), nil
 	case "postgresql":
 		return NewPostgreSQLAdapter(&PostgreSQLConfig{
@@ -81,10 +172,22 @@ func NewAdapter(config *DBConfig) (DBAdapter, error) {
 			Database: config.Database,
 			User:     config.User,
 			Password: config.Password,
+			Retry:    config.Retry,
+			Text:     config.Text,
 		}), nil
 	case "sqlite":
 		return NewSQLiteAdapter(&SQLiteConfig{
 			FilePath: config.FilePath,
+			InMemory: config.InMemory,
+			Attach:   config.Attach,
+			Retry:    config.Retry,
+			Text:     config.Text,
+		}), nil
+	case "csv":
+		return NewFileAdapter(&FileConfig{
+			Dir:   config.FilePath,
+			Retry: config.Retry,
+			Text:  config.Text,
 		}), nil
 	default:
 		return nil, &UnsupportedDatabaseError{Type: config.Type}
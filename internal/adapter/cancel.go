@@ -0,0 +1,139 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"modernc.org/libc"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// killTimeout bounds the out-of-band KILL / cancel statement
+const killTimeout = 5 * time.Second
+
+// watchCancel calls kill once if ctx is done before stop is called
+// stop waits for a running kill, so a late kill never hits the next query on the same connection.
+func watchCancel(ctx context.Context, kill func()) (stop func()) {
+	if ctx.Done() == nil || kill == nil {
+		return func() {}
+	}
+
+	var mu sync.Mutex
+	finished := false
+	stopCh := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			if !finished {
+				kill()
+			}
+			mu.Unlock()
+		case <-stopCh:
+		}
+	}()
+
+	return func() {
+		mu.Lock()
+		finished = true
+		mu.Unlock()
+		close(stopCh)
+	}
+}
+
+// queryer runs a query on either the pool or a reserved connection
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// cancelableConn picks where to run a query so that a cancelled ctx kills it
+// Drivers only abandon the client side on cancel (MySQL drops the socket, modernc SQLite only
+// interrupts the first step), leaving the server/engine to burn CPU on a timed-out query.
+// A ctx that can never be done runs on the pool directly: no reserved connection, no id round-trip.
+// Caller must call release once the rows are closed.
+func cancelableConn(ctx context.Context, db *sql.DB,
+	killer func(ctx context.Context, conn *sql.Conn) (func(), error)) (q queryer, release func(), err error) {
+	if ctx.Done() == nil {
+		return db, func() {}, nil
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	kill, err := killer(ctx, conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to prepare query cancellation: %w", err)
+	}
+	stop := watchCancel(ctx, kill)
+	return conn, func() {
+		stop()
+		conn.Close()
+	}, nil
+}
+
+// sqliteKiller interrupts the running statement with sqlite3_interrupt
+// modernc does not export its handle, so it is read from the driver connection by reflection;
+// if that ever fails we fall back to the driver's own (first-step only) interrupt.
+func sqliteKiller(ctx context.Context, conn *sql.Conn) (func(), error) {
+	var handle uintptr
+	_ = conn.Raw(func(driverConn interface{}) error {
+		v := reflect.ValueOf(driverConn)
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		if f := v.FieldByName("db"); f.IsValid() && f.Kind() == reflect.Uintptr {
+			handle = uintptr(f.Uint())
+		}
+		return nil
+	})
+	if handle == 0 {
+		return nil, nil
+	}
+
+	return func() {
+		// sqlite3_interrupt only sets a flag on the db, safe from any thread
+		tls := libc.NewTLS()
+		defer tls.Close()
+		sqlite3.Xsqlite3_interrupt(tls, handle)
+	}, nil
+}
+
+// mysqlKiller issues KILL QUERY for the connection's thread from another pool connection
+func mysqlKiller(db *sql.DB) func(ctx context.Context, conn *sql.Conn) (func(), error) {
+	return func(ctx context.Context, conn *sql.Conn) (func(), error) {
+		var id int64
+		if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id); err != nil {
+			return nil, err
+		}
+		return func() {
+			killCtx, cancel := context.WithTimeout(context.Background(), killTimeout)
+			defer cancel()
+			db.ExecContext(killCtx, fmt.Sprintf("KILL QUERY %d", id))
+		}, nil
+	}
+}
+
+// postgresKiller cancels the backend's current query with pg_cancel_backend
+func postgresKiller(db *sql.DB) func(ctx context.Context, conn *sql.Conn) (func(), error) {
+	return func(ctx context.Context, conn *sql.Conn) (func(), error) {
+		var pid int64
+		if err := conn.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+			return nil, err
+		}
+		return func() {
+			killCtx, cancel := context.WithTimeout(context.Background(), killTimeout)
+			defer cancel()
+			db.ExecContext(killCtx, "SELECT pg_cancel_backend($1)", pid)
+		}, nil
+	}
+}
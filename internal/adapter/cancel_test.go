@@ -0,0 +1,68 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestCancelableConnUsesPoolWithoutDeadline(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	killerCalled := false
+	killer := func(ctx context.Context, conn *sql.Conn) (func(), error) {
+		killerCalled = true
+		return func() {}, nil
+	}
+
+	q, release, err := cancelableConn(context.Background(), db, killer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if q != db || killerCalled {
+		t.Fatal("a context without Done must run on the pool without preparing a killer")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q, release, err = cancelableConn(ctx, db, killer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if _, ok := q.(*sql.Conn); !ok || !killerCalled {
+		t.Fatal("a cancellable context must reserve a connection and prepare its killer")
+	}
+}
+
+func TestWatchCancelSkipsNonCancellableContext(t *testing.T) {
+	killed := false
+	stop := watchCancel(context.Background(), func() { killed = true })
+	stop()
+	if killed {
+		t.Fatal("kill must not run for a context without Done")
+	}
+}
+
+func TestWatchCancelKillsOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := make(chan struct{}, 2)
+	stop := watchCancel(ctx, func() { calls <- struct{}{} })
+	cancel()
+
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("kill was not called after cancel")
+	}
+	stop()
+	if len(calls) != 0 {
+		t.Fatal("kill called more than once")
+	}
+}
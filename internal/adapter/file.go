@@ -0,0 +1,270 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileAdapter mounts a directory of CSV and Parquet files as tables
+// Each file is loaded into a shared-cache in-memory SQLite database at connect time,
+// so queries use the SQLite dialect and all SQLiteAdapter metadata methods apply.
+// Other files in the directory are ignored.
+type FileAdapter struct {
+	*SQLiteAdapter
+	config *FileConfig
+}
+
+// FileConfig file directory config
+type FileConfig struct {
+	Dir   string       // Directory containing *.csv and *.parquet files (one table per file)
+	Retry *RetryConfig // Transient-error retry (nil = DefaultRetryConfig)
+	Text  *TextOptions // LIKE case sensitivity and encoding normalization (nil = defaults)
+}
+
+// NewFileAdapter creates CSV/Parquet directory adapter
+func NewFileAdapter(config *FileConfig) *FileAdapter {
+	return &FileAdapter{
+		SQLiteAdapter: NewSQLiteAdapter(&SQLiteConfig{
+			FilePath: ":memory:",
+			Retry:    config.Retry,
+			Text:     config.Text,
+		}),
+		config: config,
+	}
+}
+
+// Connect creates the in-memory database and loads every CSV and Parquet file in Dir
+func (a *FileAdapter) Connect(ctx context.Context) error {
+	entries, err := os.ReadDir(a.config.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read data directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".csv", ".parquet":
+			files = append(files, filepath.Join(a.config.Dir, entry.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no CSV or Parquet files found in %s", a.config.Dir)
+	}
+	sort.Strings(files)
+
+	if _, err := a.SQLiteAdapter.openSharedMemory(nil); err != nil {
+		return err
+	}
+	db := a.SQLiteAdapter.db
+
+	seen := make(map[string]bool)
+	for _, path := range files {
+		table := fileTableName(path)
+		if seen[strings.ToLower(table)] {
+			a.SQLiteAdapter.Close()
+			return fmt.Errorf("duplicate table name %q from %s", table, filepath.Base(path))
+		}
+		seen[strings.ToLower(table)] = true
+
+		load := loadCSV
+		if strings.EqualFold(filepath.Ext(path), ".parquet") {
+			load = loadParquet
+		}
+		if err := load(ctx, db, table, path); err != nil {
+			a.SQLiteAdapter.Close()
+			return fmt.Errorf("failed to load %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	if a.SQLiteAdapter.config.Text.noCase() {
+		if err := applyNoCaseCollation(ctx, db); err != nil {
+			a.SQLiteAdapter.Close()
+			return fmt.Errorf("failed to apply NOCASE collation: %w", err)
+		}
+	}
+	return nil
+}
+
+var identifierCleanup = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// fileTableName derives a table name from the file name ("Sales 2024.csv" -> "Sales_2024")
+func fileTableName(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return sanitizeIdentifier(name, "table")
+}
+
+// sanitizeIdentifier keeps letters, digits and underscores, prefixing names that start with a digit
+func sanitizeIdentifier(name, fallback string) string {
+	name = strings.Trim(identifierCleanup.ReplaceAllString(strings.TrimSpace(name), "_"), "_")
+	if name == "" {
+		return fallback
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// loadCSV creates a table from the CSV header and inserts all records
+// Column types are inferred from the data: INTEGER, REAL or TEXT; empty fields become NULL.
+func loadCSV(ctx context.Context, db *sql.DB, table, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1 // tolerate ragged rows, missing fields become NULL
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("empty file")
+	}
+	if err != nil {
+		return err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // UTF-8 BOM from spreadsheet exports
+	}
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+
+	columns := csvColumnNames(header)
+	types := inferColumnTypes(len(columns), records)
+
+	defs := make([]string, len(columns))
+	for i, col := range columns {
+		defs[i] = fmt.Sprintf(`"%s" %s`, col, types[i])
+	}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE "%s" (%s)`, table, strings.Join(defs, ", "))); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO "%s" VALUES (%s)`, table, placeholders))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	args := make([]interface{}, len(columns))
+	for _, record := range records {
+		for i := range columns {
+			args[i] = csvValue(record, i, types[i])
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// csvColumnNames sanitizes header names and makes them unique
+func csvColumnNames(header []string) []string {
+	columns := make([]string, len(header))
+	used := make(map[string]int)
+	for i, h := range header {
+		name := sanitizeIdentifier(h, fmt.Sprintf("col_%d", i+1))
+		key := strings.ToLower(name)
+		if n := used[key]; n > 0 {
+			name = fmt.Sprintf("%s_%d", name, n+1)
+		}
+		used[key]++
+		columns[i] = name
+	}
+	return columns
+}
+
+// inferColumnTypes picks the narrowest type that fits every non-empty value
+func inferColumnTypes(n int, records [][]string) []string {
+	types := make([]string, n)
+	for i := 0; i < n; i++ {
+		isInt, isReal, hasValue := true, true, false
+		for _, record := range records {
+			if i >= len(record) || strings.TrimSpace(record[i]) == "" {
+				continue
+			}
+			hasValue = true
+			v := strings.TrimSpace(record[i])
+			if isInt {
+				if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+					isInt = false
+				}
+			}
+			if isReal && !isInt {
+				if _, err := strconv.ParseFloat(v, 64); err != nil {
+					isReal = false
+				}
+			}
+			if !isInt && !isReal {
+				break
+			}
+		}
+		switch {
+		case !hasValue:
+			types[i] = "TEXT"
+		case isInt:
+			types[i] = "INTEGER"
+		case isReal:
+			types[i] = "REAL"
+		default:
+			types[i] = "TEXT"
+		}
+	}
+	return types
+}
+
+// csvValue converts a CSV field to the column's inferred type (empty/missing = NULL)
+func csvValue(record []string, i int, colType string) interface{} {
+	if i >= len(record) {
+		return nil
+	}
+	v := record[i]
+	if colType == "TEXT" {
+		if v == "" {
+			return nil
+		}
+		return v
+	}
+
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil
+	}
+	if colType == "INTEGER" {
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	f, _ := strconv.ParseFloat(v, 64)
+	return f
+}
@@ -0,0 +1,70 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetPerson row of the Parquet fixture
+type parquetPerson struct {
+	ID      int64     `parquet:"id"`
+	Name    *string   `parquet:"name,optional"`
+	Active  bool      `parquet:"active"`
+	Score   float64   `parquet:"score"`
+	Balance int64     `parquet:"balance,decimal(2:10)"`
+	Born    int32     `parquet:"born,date"` // Days since 1970-01-01
+	Seen    time.Time `parquet:"seen,timestamp(millisecond)"`
+}
+
+func TestFileAdapterParquet(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "people.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ann := "Ann"
+	writer := parquet.NewGenericWriter[parquetPerson](f)
+	if _, err := writer.Write([]parquetPerson{
+		{ID: 1, Name: &ann, Active: true, Score: 1.5, Balance: -1234,
+			Born: 7441, Seen: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{ID: 2, Score: 2, Balance: 50,
+			Born: 5479, Seen: time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := os.WriteFile(filepath.Join(dir, "city.csv"), []byte("name\nParis\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewFileAdapter(&FileConfig{Dir: dir})
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	result, err := a.ExecuteQuery(context.Background(),
+		`SELECT id, name, active, score, balance, born, seen, typeof(balance) AS balance_type FROM people ORDER BY id`)
+	if err != nil || result.Error != "" {
+		t.Fatalf("query failed: %v %s", err, result.Error)
+	}
+	want := []map[string]interface{}{
+		{"id": int64(1), "name": "Ann", "active": int64(1), "score": 1.5, "balance": -12.34,
+			"born": "1990-05-17", "seen": "2024-01-02 03:04:05", "balance_type": "real"},
+		{"id": int64(2), "name": nil, "active": int64(0), "score": 2.0, "balance": 0.5,
+			"born": "1985-01-01", "seen": "2024-06-30 23:59:59", "balance_type": "real"},
+	}
+	if !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("rows = %v\nwant %v", result.Rows, want)
+	}
+	assertCount(t, a, `SELECT count(*) FROM city`, 1)
+}
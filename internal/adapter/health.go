@@ -0,0 +1,73 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// pingDB checks the pool answers a trivial query (PingContext alone may reuse a dead idle conn)
+func pingDB(ctx context.Context, db *sql.DB) error {
+	if db == nil {
+		return fmt.Errorf("not connected")
+	}
+	var one int
+	if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	return nil
+}
+
+// reconnector is the subset of DBAdapter needed to recover a stale connection
+type reconnector interface {
+	Connect(ctx context.Context) error
+	Close() error
+	Ping(ctx context.Context) error
+}
+
+// healthy pings and, on failure, reconnects once and pings again
+func healthy(ctx context.Context, a reconnector) bool {
+	if a.Ping(ctx) == nil {
+		return true
+	}
+	a.Close()
+	if err := a.Connect(ctx); err != nil {
+		return false
+	}
+	return a.Ping(ctx) == nil
+}
+
+// Ping SQLite: round-trip check
+func (a *SQLiteAdapter) Ping(ctx context.Context) error {
+	return pingDB(ctx, a.db)
+}
+
+// Healthy SQLite: ping, reconnecting once if stale
+func (a *SQLiteAdapter) Healthy(ctx context.Context) bool {
+	return healthy(ctx, a)
+}
+
+// Healthy CSV and Parquet files: reconnecting reloads the files
+func (a *FileAdapter) Healthy(ctx context.Context) bool {
+	return healthy(ctx, a)
+}
+
+// Ping MySQL: round-trip check
+func (a *MySQLAdapter) Ping(ctx context.Context) error {
+	return pingDB(ctx, a.db)
+}
+
+// Healthy MySQL: ping, reconnecting once if stale
+func (a *MySQLAdapter) Healthy(ctx context.Context) bool {
+	return healthy(ctx, a)
+}
+
+// Ping PostgreSQL: round-trip check
+func (a *PostgreSQLAdapter) Ping(ctx context.Context) error {
+	return pingDB(ctx, a.db)
+}
+
+// Healthy PostgreSQL: ping, reconnecting once if stale
+func (a *PostgreSQLAdapter) Healthy(ctx context.Context) bool {
+	return healthy(ctx, a)
+}
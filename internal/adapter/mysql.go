@@ -3,6 +3,7 @@ package adapter
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -22,6 +23,8 @@ type MySQLConfig struct {
 	Database string
 	User     string
 	Password string
+	Retry    *RetryConfig // Transient-error retry (nil = DefaultRetryConfig)
+	Text     *TextOptions // Encoding normalization of results (nil = none)
 }
 
 // NewMySQLAdapter creates MySQL adapter
@@ -63,11 +66,28 @@ func (a *MySQLAdapter) Close() error {
 	return nil
 }
 
-// ExecuteQuery executes query
+// ExecuteQuery executes query, retrying transient errors per config.Retry
 func (a *MySQLAdapter) ExecuteQuery(ctx context.Context, query string) (*QueryResult, error) {
+	result, err := executeWithRetry(ctx, a.config.Retry, query, a.executeQueryOnce)
+	normalizeEncoding(result, a.config.Text)
+	return result, err
+}
+
+// executeQueryOnce executes query (single attempt)
+func (a *MySQLAdapter) executeQueryOnce(ctx context.Context, query string) (*QueryResult, error) {
 	start := time.Now()
 
-	rows, err := a.db.QueryContext(ctx, query)
+	// Dedicated connection so a cancelled ctx kills the query instead of abandoning it
+	q, release, err := cancelableConn(ctx, a.db, mysqlKiller(a.db))
+	if err != nil {
+		return &QueryResult{
+			Error:         err.Error(),
+			ExecutionTime: time.Since(start).Milliseconds(),
+		}, err
+	}
+	defer release()
+
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return &QueryResult{
 			Error:         err.Error(),
@@ -81,9 +101,14 @@ func (a *MySQLAdapter) ExecuteQuery(ctx context.Context, query string) (*QueryRe
 	if err != nil {
 		return nil, err
 	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
 
 	// Read data
 	var result []map[string]interface{}
+	var typed [][]Value
 	for rows.Next() {
 		// Create scan targets
 		values := make([]interface{}, len(columns))
@@ -109,6 +134,12 @@ func (a *MySQLAdapter) ExecuteQuery(ctx context.Context, query string) (*QueryRe
 			}
 		}
 		result = append(result, row)
+
+		typedRow := make([]Value, len(columns))
+		for i, val := range values {
+			typedRow[i] = newColumnValue(val, columnTypes[i].DatabaseTypeName())
+		}
+		typed = append(typed, typedRow)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -118,6 +149,7 @@ func (a *MySQLAdapter) ExecuteQuery(ctx context.Context, query string) (*QueryRe
 	return &QueryResult{
 		Columns:       columns,
 		Rows:          result,
+		Values:        typed,
 		RowCount:      len(result),
 		ExecutionTime: time.Since(start).Milliseconds(),
 	}, nil
@@ -135,7 +167,7 @@ func (a *MySQLAdapter) GetDatabaseVersion(ctx context.Context) (string, error) {
 		return "", err
 	}
 	if result.Error != "" {
-		return "", fmt.Errorf(result.Error)
+		return "", errors.New(result.Error)
 	}
 	if len(result.Rows) > 0 {
 		if version, ok := result.Rows[0]["version"].(string); ok {
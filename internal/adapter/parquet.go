@@ -0,0 +1,211 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// parquetBatchRows rows read from a Parquet file per call
+const parquetBatchRows = 1024
+
+// julianUnixEpoch Julian day of 1970-01-01 (INT96 timestamps count Julian days)
+const julianUnixEpoch = 2440588
+
+// loadParquet creates a table from the Parquet schema and inserts all rows
+// Integers and booleans become INTEGER, floats and decimals REAL, strings, dates and
+// timestamps TEXT (SQLite date functions read "2006-01-02 15:04:05"); nulls stay NULL.
+// Only flat schemas are read: nested groups and repeated columns are rejected.
+func loadParquet(ctx context.Context, db *sql.DB, table, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	file, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	schema := file.Schema()
+	paths := schema.Columns()
+	if len(paths) == 0 {
+		return fmt.Errorf("no columns")
+	}
+	header := make([]string, len(paths))
+	leafTypes := make([]parquet.Type, len(paths))
+	for _, path := range paths {
+		leaf, ok := schema.Lookup(path...)
+		if !ok || len(path) > 1 || leaf.MaxRepetitionLevel > 0 {
+			return fmt.Errorf("column %s: nested and repeated columns are not supported", strings.Join(path, "."))
+		}
+		header[leaf.ColumnIndex] = path[0]
+		leafTypes[leaf.ColumnIndex] = leaf.Node.Type()
+	}
+
+	columns := csvColumnNames(header)
+	types := make([]string, len(columns))
+	defs := make([]string, len(columns))
+	for i, col := range columns {
+		types[i] = parquetColumnType(leafTypes[i])
+		defs[i] = fmt.Sprintf(`"%s" %s`, col, types[i])
+	}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE "%s" (%s)`, table, strings.Join(defs, ", "))); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO "%s" VALUES (%s)`, table, placeholders))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+	rows := make([]parquet.Row, parquetBatchRows)
+	args := make([]interface{}, len(columns))
+	for {
+		n, readErr := reader.ReadRows(rows)
+		for _, row := range rows[:n] {
+			for i := range args {
+				args[i] = nil
+			}
+			for _, v := range row {
+				if col := v.Column(); col >= 0 && col < len(args) {
+					args[col] = parquetValue(v, leafTypes[col])
+				}
+			}
+			if _, err := stmt.ExecContext(ctx, args...); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	return tx.Commit()
+}
+
+// parquetColumnType the SQLite column type of a Parquet leaf
+func parquetColumnType(t parquet.Type) string {
+	if logical := t.LogicalType(); logical != nil {
+		switch logical.Value.(type) {
+		case *format.DateType, *format.TimestampType, *format.TimeType:
+			return "TEXT"
+		case *format.DecimalType:
+			return "REAL"
+		}
+	}
+	switch t.Kind() {
+	case parquet.Boolean, parquet.Int32, parquet.Int64:
+		return "INTEGER"
+	case parquet.Float, parquet.Double:
+		return "REAL"
+	default:
+		return "TEXT" // Byte arrays and legacy INT96 timestamps
+	}
+}
+
+// parquetValue converts a Parquet value to the column's SQLite type (null = NULL)
+func parquetValue(v parquet.Value, t parquet.Type) interface{} {
+	if v.IsNull() {
+		return nil
+	}
+	if logical := t.LogicalType(); logical != nil {
+		switch lt := logical.Value.(type) {
+		case *format.DateType:
+			return time.Unix(int64(v.Int32())*86400, 0).UTC().Format("2006-01-02")
+		case *format.TimestampType:
+			return time.Unix(0, 0).Add(time.Duration(v.Int64()) * lt.Unit.Value.Duration()).UTC().Format("2006-01-02 15:04:05.999999999")
+		case *format.TimeType:
+			var ticks int64
+			if v.Kind() == parquet.Int32 {
+				ticks = int64(v.Int32())
+			} else {
+				ticks = v.Int64()
+			}
+			return time.Time{}.Add(time.Duration(ticks) * lt.Unit.Value.Duration()).Format("15:04:05.999999999")
+		case *format.DecimalType:
+			return parquetDecimal(v, lt.Scale)
+		case *format.IntType:
+			if !lt.IsSigned {
+				if v.Kind() == parquet.Int32 {
+					return int64(v.Uint32())
+				}
+				if u := v.Uint64(); u > math.MaxInt64 {
+					return float64(u)
+				}
+			}
+		}
+	}
+	switch v.Kind() {
+	case parquet.Boolean:
+		if v.Boolean() {
+			return int64(1)
+		}
+		return int64(0)
+	case parquet.Int32:
+		return int64(v.Int32())
+	case parquet.Int64:
+		return v.Int64()
+	case parquet.Float:
+		return float64(v.Float())
+	case parquet.Double:
+		return v.Double()
+	case parquet.Int96:
+		return int96Time(v.Int96()).Format("2006-01-02 15:04:05.999999999")
+	default:
+		return string(v.ByteArray())
+	}
+}
+
+// parquetDecimal a DECIMAL value (INT32, INT64 or big-endian two's complement bytes) as float64
+func parquetDecimal(v parquet.Value, scale int32) float64 {
+	unscaled := new(big.Int)
+	switch v.Kind() {
+	case parquet.Int32:
+		unscaled.SetInt64(int64(v.Int32()))
+	case parquet.Int64:
+		unscaled.SetInt64(v.Int64())
+	default:
+		b := v.ByteArray()
+		unscaled.SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+		}
+	}
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(unscaled), new(big.Float).SetFloat64(math.Pow10(int(scale)))).Float64()
+	return f
+}
+
+// int96Time a legacy INT96 timestamp (nanoseconds of the day, then the Julian day)
+func int96Time(i deprecated.Int96) time.Time {
+	nanos := int64(uint64(i[1])<<32 | uint64(i[0]))
+	days := int64(i[2]) - julianUnixEpoch
+	return time.Unix(days*86400, nanos).UTC()
+}
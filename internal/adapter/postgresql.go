@@ -3,6 +3,7 @@ package adapter
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -22,7 +23,9 @@ type PostgreSQLConfig struct {
 	Database string
 	User     string
 	Password string
-	SSLMode  string // disable, require, verify-ca, verify-full
+	SSLMode  string       // disable, require, verify-ca, verify-full
+	Retry    *RetryConfig // Transient-error retry (nil = DefaultRetryConfig)
+	Text     *TextOptions // Encoding normalization of results (nil = none)
 }
 
 // NewPostgreSQLAdapter creates PostgreSQL adapter
@@ -68,11 +71,28 @@ func (a *PostgreSQLAdapter) Close() error {
 	return nil
 }
 
-// ExecuteQuery executes query
+// ExecuteQuery executes query, retrying transient errors per config.Retry
 func (a *PostgreSQLAdapter) ExecuteQuery(ctx context.Context, query string) (*QueryResult, error) {
+	result, err := executeWithRetry(ctx, a.config.Retry, query, a.executeQueryOnce)
+	normalizeEncoding(result, a.config.Text)
+	return result, err
+}
+
+// executeQueryOnce executes query (single attempt)
+func (a *PostgreSQLAdapter) executeQueryOnce(ctx context.Context, query string) (*QueryResult, error) {
 	start := time.Now()
 
-	rows, err := a.db.QueryContext(ctx, query)
+	// Dedicated connection so a cancelled ctx kills the query instead of abandoning it
+	q, release, err := cancelableConn(ctx, a.db, postgresKiller(a.db))
+	if err != nil {
+		return &QueryResult{
+			Error:         err.Error(),
+			ExecutionTime: time.Since(start).Milliseconds(),
+		}, err
+	}
+	defer release()
+
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return &QueryResult{
 			Error:         err.Error(),
@@ -86,9 +106,14 @@ func (a *PostgreSQLAdapter) ExecuteQuery(ctx context.Context, query string) (*Qu
 	if err != nil {
 		return nil, err
 	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
 
 	// Read data
 	var result []map[string]interface{}
+	var typed [][]Value
 	for rows.Next() {
 		values := make([]interface{}, len(columns))
 		valuePtrs := make([]interface{}, len(columns))
@@ -110,6 +135,12 @@ func (a *PostgreSQLAdapter) ExecuteQuery(ctx context.Context, query string) (*Qu
 			}
 		}
 		result = append(result, row)
+
+		typedRow := make([]Value, len(columns))
+		for i, val := range values {
+			typedRow[i] = newColumnValue(val, columnTypes[i].DatabaseTypeName())
+		}
+		typed = append(typed, typedRow)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -119,6 +150,7 @@ func (a *PostgreSQLAdapter) ExecuteQuery(ctx context.Context, query string) (*Qu
 	return &QueryResult{
 		Columns:       columns,
 		Rows:          result,
+		Values:        typed,
 		RowCount:      len(result),
 		ExecutionTime: time.Since(start).Milliseconds(),
 	}, nil
@@ -136,7 +168,7 @@ func (a *PostgreSQLAdapter) GetDatabaseVersion(ctx context.Context) (string, err
 		return "", err
 	}
 	if result.Error != "" {
-		return "", fmt.Errorf(result.Error)
+		return "", errors.New(result.Error)
 	}
 	if len(result.Rows) > 0 {
 		if version, ok := result.Rows[0]["version"].(string); ok {
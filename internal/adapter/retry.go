@@ -0,0 +1,129 @@
+package adapter
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RetryConfig transient-error retry policy for ExecuteQuery
+type RetryConfig struct {
+	MaxRetries     int           // Retries after the first attempt (0 = disabled)
+	InitialBackoff time.Duration // Wait before first retry, doubled each time
+	MaxBackoff     time.Duration // Backoff cap
+}
+
+// DefaultRetryConfig used when adapter config leaves Retry nil
+func DefaultRetryConfig() *RetryConfig {
+	return &RetryConfig{
+		MaxRetries:     3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+	}
+}
+
+// notExecutedErrorPatterns lock contention and refused connections: the statement did not
+// take effect (it never ran or was rolled back), so it is safe to retry any statement
+var notExecutedErrorPatterns = []string{
+	"database is locked",       // SQLite SQLITE_BUSY
+	"database table is locked", // SQLite SQLITE_LOCKED
+	"deadlock found",           // MySQL 1213
+	"lock wait timeout",        // MySQL 1205
+	"could not serialize",      // PostgreSQL 40001
+	"deadlock detected",        // PostgreSQL 40P01
+	"too many connections",
+}
+
+// droppedConnectionErrorPatterns the connection broke mid-statement: a write may already have
+// been applied, so only read-only statements are retried
+var droppedConnectionErrorPatterns = []string{
+	"connection reset",
+	"broken pipe",
+	"bad connection",
+}
+
+// IsTransientError reports whether err is worth retrying (lock contention, dropped connection)
+func IsTransientError(err error) bool {
+	return isNotExecutedError(err) || isDroppedConnectionError(err)
+}
+
+// isNotExecutedError lock contention or refused connection
+func isNotExecutedError(err error) bool {
+	return err != nil && containsAny(strings.ToLower(err.Error()), notExecutedErrorPatterns)
+}
+
+// isDroppedConnectionError connection lost, possibly after the server ran the statement
+// driver.ErrBadConn is included: drivers also return it after a partial write.
+func isDroppedConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	return containsAny(strings.ToLower(err.Error()), droppedConnectionErrorPatterns)
+}
+
+func containsAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(s, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyLeadPattern statements that start as a query
+var readOnlyLeadPattern = regexp.MustCompile(`(?i)^[\s(]*(SELECT|WITH|VALUES|EXPLAIN|SHOW|DESCRIBE)\b`)
+
+// mutatingWordPattern words that can make a query change data (data-modifying CTE, SELECT INTO, locks)
+var mutatingWordPattern = regexp.MustCompile(`(?i)\b(INSERT|UPDATE|DELETE|MERGE|REPLACE\s+INTO|INTO|CREATE|DROP|ALTER|TRUNCATE)\b`)
+
+// sqlCommentPattern line and block comments
+var sqlCommentPattern = regexp.MustCompile(`(?s)--[^\n]*|/\*.*?\*/`)
+
+// isReadOnlyStatement reports whether query is certainly a read, so running it twice is harmless
+// Conservative: a mutating keyword anywhere, even inside a string literal, counts as a write.
+func isReadOnlyStatement(query string) bool {
+	query = sqlCommentPattern.ReplaceAllString(query, " ")
+	return readOnlyLeadPattern.MatchString(query) && !mutatingWordPattern.MatchString(query)
+}
+
+// retryable reports whether a failed attempt of query may run again
+func retryable(err error, query string) bool {
+	if isNotExecutedError(err) {
+		return true
+	}
+	return isDroppedConnectionError(err) && isReadOnlyStatement(query)
+}
+
+// executeWithRetry runs exec, retrying transient errors with exponential backoff
+// Non-transient errors, dropped connections during a possible write and ctx cancellation return immediately.
+func executeWithRetry(ctx context.Context, cfg *RetryConfig, query string,
+	exec func(ctx context.Context, query string) (*QueryResult, error)) (*QueryResult, error) {
+	if cfg == nil {
+		cfg = DefaultRetryConfig()
+	}
+
+	backoff := cfg.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		result, err := exec(ctx, query)
+		if err == nil || attempt >= cfg.MaxRetries || !retryable(err, query) {
+			return result, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+			backoff = cfg.MaxBackoff
+		}
+	}
+}
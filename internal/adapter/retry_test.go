@@ -0,0 +1,36 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExecuteWithRetryOnlyRepeatsSafeStatements(t *testing.T) {
+	cfg := &RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond}
+	cases := []struct {
+		query    string
+		err      string
+		attempts int
+	}{
+		{"SELECT * FROM t", "driver: bad connection", 3},
+		{"/* note */ WITH x AS (SELECT 1) SELECT * FROM x", "read: connection reset by peer", 3},
+		{"UPDATE t SET x = 1", "driver: bad connection", 1},
+		{"INSERT INTO t VALUES (1)", "write: broken pipe", 1},
+		{"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", "driver: bad connection", 1},
+		{"CREATE TABLE u (x INT)", "driver: bad connection", 1},
+		{"UPDATE t SET x = 1", "database is locked (5) (SQLITE_BUSY)", 3},
+		{"SELECT * FROM t", "no such table: t", 1},
+	}
+	for _, tc := range cases {
+		attempts := 0
+		executeWithRetry(context.Background(), cfg, tc.query, func(ctx context.Context, query string) (*QueryResult, error) {
+			attempts++
+			return nil, errors.New(tc.err)
+		})
+		if attempts != tc.attempts {
+			t.Errorf("%q with %q: %d attempts, want %d", tc.query, tc.err, attempts, tc.attempts)
+		}
+	}
+}
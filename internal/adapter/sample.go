@@ -0,0 +1,114 @@
+package adapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskingRules PII masking applied to sampled rows
+// Lets example data be shown to the LLM when pointing at real customer databases
+type MaskingRules struct {
+	HashEmails    bool     // Replace email addresses with a stable short hash
+	MaxTextLength int      // Truncate strings longer than this (0 = no limit)
+	RedactColumns []string // Columns whose values are replaced entirely (case-insensitive)
+}
+
+// DefaultMaskingRules hashes emails and truncates free text to 64 chars
+func DefaultMaskingRules() *MaskingRules {
+	return &MaskingRules{
+		HashEmails:    true,
+		MaxTextLength: 64,
+	}
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// MaskRows applies masking rules to query result rows in place
+func MaskRows(result *QueryResult, rules *MaskingRules) {
+	if result == nil || rules == nil {
+		return
+	}
+
+	redact := make(map[string]bool, len(rules.RedactColumns))
+	for _, col := range rules.RedactColumns {
+		redact[strings.ToLower(col)] = true
+	}
+
+	for _, row := range result.Rows {
+		for col, val := range row {
+			if val == nil {
+				continue
+			}
+			if redact[strings.ToLower(col)] {
+				row[col] = "<redacted>"
+				continue
+			}
+			if s, ok := val.(string); ok {
+				row[col] = maskString(s, rules)
+			}
+		}
+	}
+
+	for _, row := range result.Values {
+		for i, val := range row {
+			if val.IsNull() {
+				continue
+			}
+			if i < len(result.Columns) && redact[strings.ToLower(result.Columns[i])] {
+				row[i] = Value{Kind: KindText, Text: "<redacted>"}
+				continue
+			}
+			if val.Kind == KindText {
+				row[i].Text = maskString(val.Text, rules)
+			}
+		}
+	}
+}
+
+// maskString hashes emails first so truncation never leaks a partial address
+func maskString(s string, rules *MaskingRules) string {
+	if rules.HashEmails {
+		s = emailPattern.ReplaceAllStringFunc(s, func(email string) string {
+			sum := sha256.Sum256([]byte(strings.ToLower(email)))
+			return "email_" + hex.EncodeToString(sum[:4]) + "@masked"
+		})
+	}
+	if rules.MaxTextLength > 0 && utf8.RuneCountInString(s) > rules.MaxTextLength {
+		runes := []rune(s)
+		s = string(runes[:rules.MaxTextLength]) + "…"
+	}
+	return s
+}
+
+// sampleRows runs the dialect-specific sample query and masks the result
+func sampleRows(ctx context.Context, a DBAdapter, query string, rules *MaskingRules) (*QueryResult, error) {
+	result, err := a.ExecuteQuery(ctx, query)
+	if err != nil {
+		return result, err
+	}
+	MaskRows(result, rules)
+	return result, nil
+}
+
+// SampleRows SQLite: first n rows, masked
+func (a *SQLiteAdapter) SampleRows(ctx context.Context, table string, n int, rules *MaskingRules) (*QueryResult, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s LIMIT %d`, a.quoteTable(table), n)
+	return sampleRows(ctx, a, query, rules)
+}
+
+// SampleRows MySQL: first n rows, masked
+func (a *MySQLAdapter) SampleRows(ctx context.Context, table string, n int, rules *MaskingRules) (*QueryResult, error) {
+	query := fmt.Sprintf("SELECT * FROM `%s` LIMIT %d", strings.ReplaceAll(table, "`", "``"), n)
+	return sampleRows(ctx, a, query, rules)
+}
+
+// SampleRows PostgreSQL: first n rows, masked
+func (a *PostgreSQLAdapter) SampleRows(ctx context.Context, table string, n int, rules *MaskingRules) (*QueryResult, error) {
+	query := fmt.Sprintf(`SELECT * FROM "%s" LIMIT %d`, strings.ReplaceAll(table, `"`, `""`), n)
+	return sampleRows(ctx, a, query, rules)
+}
@@ -0,0 +1,361 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sandboxEnv marks a re-executed binary as a sandbox worker
+const sandboxEnv = "REACTSQL_SANDBOX_WORKER"
+
+// SandboxConfig child-process execution limits
+type SandboxConfig struct {
+	Timeout       time.Duration // Kill child after this if ctx has no earlier deadline (default 120s)
+	MaxMemoryMB   int           // Address-space (virtual) limit of the child, needs ~1GB headroom for the runtime (0 = unlimited)
+	MaxCPUSeconds int           // CPU time limit of the child per query (0 = unlimited)
+}
+
+// SandboxAdapter runs ExecuteQuery in a separate child process
+// The child is the current binary re-executed in worker mode (see SandboxMain). It is started on
+// the first query, connects once and then serves queries over stdin/stdout until Close, so a
+// runaway query can only exhaust the child's memory/CPU, never the parent's. A killed or
+// crashed child is replaced on the next query. Metadata methods run in-process on an ordinary adapter.
+type SandboxAdapter struct {
+	DBAdapter
+	config  *DBConfig
+	sandbox *SandboxConfig
+
+	mu     sync.Mutex // one query at a time per child
+	worker *sandboxWorker
+}
+
+// sandboxInit parent -> child, first message: what to connect to and the limits
+type sandboxInit struct {
+	Config        DBConfig
+	MaxMemoryMB   int
+	MaxCPUSeconds int // per query
+}
+
+// sandboxRequest parent -> child, one per query
+type sandboxRequest struct {
+	Query string
+}
+
+// sandboxResponse child -> parent, one per message (the init answer carries only Error)
+type sandboxResponse struct {
+	Result *QueryResult
+	Error  string
+}
+
+// sandboxWorker a running child process and its pipes
+type sandboxWorker struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	enc    *json.Encoder
+	dec    *json.Decoder
+	stderr *bytes.Buffer
+	done   chan struct{} // closed once the process has exited
+}
+
+// NewSandboxAdapter creates sandboxed adapter (config.Sandbox is ignored for the inner adapter)
+func NewSandboxAdapter(config *DBConfig, sandbox *SandboxConfig) (*SandboxAdapter, error) {
+	if sandbox.Timeout <= 0 {
+		sandbox.Timeout = 120 * time.Second
+	}
+
+	inner := *config
+	inner.Sandbox = nil
+	innerAdapter, err := NewAdapter(&inner)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SandboxAdapter{
+		DBAdapter: innerAdapter,
+		config:    &inner,
+		sandbox:   sandbox,
+	}, nil
+}
+
+// ExecuteQuery executes query in the child process, killing it on timeout or ctx cancel
+func (a *SandboxAdapter) ExecuteQuery(ctx context.Context, query string) (*QueryResult, error) {
+	start := time.Now()
+
+	fail := func(err error) (*QueryResult, error) {
+		return &QueryResult{
+			Error:         err.Error(),
+			ExecutionTime: time.Since(start).Milliseconds(),
+		}, err
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, a.sandbox.Timeout)
+	defer cancel()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.worker == nil {
+		worker, err := a.startWorker(execCtx)
+		if err != nil {
+			return fail(err)
+		}
+		a.worker = worker
+	}
+	worker := a.worker
+
+	type reply struct {
+		resp sandboxResponse
+		err  error
+	}
+	replyCh := make(chan reply, 1)
+	go func() {
+		var r reply
+		if r.err = worker.enc.Encode(sandboxRequest{Query: query}); r.err == nil {
+			r.err = worker.dec.Decode(&r.resp)
+		}
+		replyCh <- r
+	}()
+
+	var r reply
+	select {
+	case r = <-replyCh:
+	case <-execCtx.Done():
+		// The child may be stuck anywhere in the query; only killing it is safe
+		a.stopWorker()
+		return fail(fmt.Errorf("sandbox: query killed: %w", execCtx.Err()))
+	}
+
+	if r.err != nil {
+		// Killed by rlimit (SIGKILL/SIGXCPU) or crashed before answering
+		a.stopWorker()
+		return fail(worker.exitError(r.err))
+	}
+
+	resp := r.resp
+	if resp.Error != "" {
+		if resp.Result == nil {
+			resp.Result = &QueryResult{Error: resp.Error}
+		}
+		resp.Result.ExecutionTime = time.Since(start).Milliseconds()
+		return resp.Result, fmt.Errorf("%s", resp.Error)
+	}
+
+	restoreNumbers(resp.Result)
+	resp.Result.ExecutionTime = time.Since(start).Milliseconds()
+	return resp.Result, nil
+}
+
+// startWorker launches the child and waits until it has connected
+func (a *SandboxAdapter) startWorker(ctx context.Context) (*sandboxWorker, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("sandbox: failed to locate executable: %w", err)
+	}
+
+	cmd := exec.Command(exe)
+	cmd.Env = append(os.Environ(), sandboxEnv+"=1")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("sandbox: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("sandbox: %w", err)
+	}
+	worker := &sandboxWorker{
+		cmd:    cmd,
+		stdin:  stdin,
+		enc:    json.NewEncoder(stdin),
+		dec:    json.NewDecoder(stdout),
+		stderr: &bytes.Buffer{},
+		done:   make(chan struct{}),
+	}
+	worker.dec.UseNumber()
+	cmd.Stderr = worker.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("sandbox: failed to start worker: %w", err)
+	}
+	go func() {
+		cmd.Wait()
+		close(worker.done)
+	}()
+
+	initErr := make(chan error, 1)
+	go func() {
+		err := worker.enc.Encode(sandboxInit{
+			Config:        *a.config,
+			MaxMemoryMB:   a.sandbox.MaxMemoryMB,
+			MaxCPUSeconds: a.sandbox.MaxCPUSeconds,
+		})
+		var resp sandboxResponse
+		if err == nil {
+			err = worker.dec.Decode(&resp)
+		}
+		if err == nil && resp.Error != "" {
+			err = fmt.Errorf("sandbox: %s", resp.Error)
+		} else if err != nil {
+			err = worker.exitError(err)
+		}
+		initErr <- err
+	}()
+
+	select {
+	case err = <-initErr:
+	case <-ctx.Done():
+		err = fmt.Errorf("sandbox: worker start killed: %w", ctx.Err())
+	}
+	if err != nil {
+		worker.kill()
+		return nil, err
+	}
+	return worker, nil
+}
+
+// stopWorker kills the current child; the next query starts a new one (caller holds mu)
+func (a *SandboxAdapter) stopWorker() {
+	if a.worker != nil {
+		a.worker.kill()
+		a.worker = nil
+	}
+}
+
+// kill terminates the child and waits for it to exit
+func (w *sandboxWorker) kill() {
+	w.stdin.Close()
+	w.cmd.Process.Kill()
+	<-w.done
+}
+
+// exitError describes a broken pipe to the child, with its stderr once it has exited
+func (w *sandboxWorker) exitError(err error) error {
+	select {
+	case <-w.done:
+	case <-time.After(time.Second):
+		return fmt.Errorf("sandbox: worker stopped answering: %w", err)
+	}
+	msg := strings.TrimSpace(w.stderr.String())
+	if len(msg) > 200 {
+		msg = msg[:200]
+	}
+	return fmt.Errorf("sandbox: process exited: %v %s", w.cmd.ProcessState, msg)
+}
+
+// Close stops the child (closing stdin ends its loop) and closes the in-process adapter
+func (a *SandboxAdapter) Close() error {
+	a.mu.Lock()
+	if w := a.worker; w != nil {
+		w.stdin.Close()
+		select {
+		case <-w.done:
+		case <-time.After(5 * time.Second):
+			w.kill()
+		}
+		a.worker = nil
+	}
+	a.mu.Unlock()
+	return a.DBAdapter.Close()
+}
+
+// restoreNumbers turns json.Number back into int64/float64 (JSON has no integer type)
+func restoreNumbers(result *QueryResult) {
+	if result == nil {
+		return
+	}
+	for _, row := range result.Rows {
+		for col, val := range row {
+			n, ok := val.(json.Number)
+			if !ok {
+				continue
+			}
+			if i, err := n.Int64(); err == nil {
+				row[col] = i
+			} else if f, err := n.Float64(); err == nil {
+				row[col] = f
+			} else {
+				row[col] = n.String()
+			}
+		}
+	}
+}
+
+// SandboxMain turns the process into a sandbox worker when re-executed by SandboxAdapter
+// Call first thing in main() of every binary that enables sandboxed execution; no-op otherwise.
+func SandboxMain() {
+	if os.Getenv(sandboxEnv) != "1" {
+		return
+	}
+	os.Exit(runSandboxWorker())
+}
+
+// runSandboxWorker connects per the init message, then answers one query per request until stdin closes
+func runSandboxWorker() int {
+	dec := json.NewDecoder(os.Stdin)
+	enc := json.NewEncoder(os.Stdout)
+
+	var init sandboxInit
+	if err := dec.Decode(&init); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: invalid request: %v\n", err)
+		return 2
+	}
+
+	// Soft limit lets the GC work harder before the hard rlimit kills us
+	if init.MaxMemoryMB > 0 {
+		debug.SetMemoryLimit(int64(init.MaxMemoryMB) * 1024 * 1024 * 8 / 10)
+	}
+	if err := applyMemoryRlimit(init.MaxMemoryMB); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: failed to set rlimits: %v\n", err)
+		return 2
+	}
+
+	ctx := context.Background()
+	init.Config.Sandbox = nil
+	dbAdapter, err := NewAdapter(&init.Config)
+	if err == nil {
+		err = dbAdapter.Connect(ctx)
+	}
+	if err != nil {
+		enc.Encode(sandboxResponse{Error: err.Error()})
+		return 1
+	}
+	defer dbAdapter.Close()
+	if err := enc.Encode(sandboxResponse{}); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: failed to write response: %v\n", err)
+		return 2
+	}
+
+	for {
+		var req sandboxRequest
+		if err := dec.Decode(&req); err != nil {
+			if err == io.EOF {
+				return 0
+			}
+			fmt.Fprintf(os.Stderr, "sandbox: invalid request: %v\n", err)
+			return 2
+		}
+
+		// RLIMIT_CPU counts the whole process lifetime, so each query gets its budget on top of what is used
+		if err := limitCPUFromNow(init.MaxCPUSeconds); err != nil {
+			fmt.Fprintf(os.Stderr, "sandbox: failed to set rlimits: %v\n", err)
+			return 2
+		}
+
+		resp := sandboxResponse{}
+		resp.Result, err = dbAdapter.ExecuteQuery(ctx, req.Query)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		if err := enc.Encode(resp); err != nil {
+			fmt.Fprintf(os.Stderr, "sandbox: failed to write response: %v\n", err)
+			return 2
+		}
+	}
+}
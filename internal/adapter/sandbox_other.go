@@ -0,0 +1,13 @@
+//go:build !unix
+
+package adapter
+
+// applyMemoryRlimit rlimits are unavailable, the child is still killed on timeout
+func applyMemoryRlimit(maxMemoryMB int) error {
+	return nil
+}
+
+// limitCPUFromNow rlimits are unavailable, the child is still killed on timeout
+func limitCPUFromNow(maxCPUSeconds int) error {
+	return nil
+}
@@ -0,0 +1,72 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestMain lets the test binary serve as the sandbox worker
+func TestMain(m *testing.M) {
+	SandboxMain()
+	os.Exit(m.Run())
+}
+
+func TestSandboxReusesWorker(t *testing.T) {
+	path := createSQLiteFile(t, t.TempDir(), "main.sqlite", 3)
+	a, err := NewSandboxAdapter(&DBConfig{Type: "sqlite", FilePath: path, InMemory: true}, &SandboxConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	assertCount(t, a, `SELECT count(*) FROM t`, 3)
+	pid := a.worker.cmd.Process.Pid
+	assertCount(t, a, `SELECT count(*) FROM t WHERE id > 1`, 2)
+	if a.worker.cmd.Process.Pid != pid {
+		t.Fatal("second query started a new worker")
+	}
+
+	// A query error leaves the worker usable
+	if _, err := a.ExecuteQuery(context.Background(), `SELECT * FROM missing`); err == nil {
+		t.Fatal("expected an error for a missing table")
+	}
+	assertCount(t, a, `SELECT count(*) FROM t`, 3)
+	if a.worker.cmd.Process.Pid != pid {
+		t.Fatal("query error restarted the worker")
+	}
+}
+
+func TestSandboxKillsTimedOutWorker(t *testing.T) {
+	path := createSQLiteFile(t, t.TempDir(), "main.sqlite", 2)
+	a, err := NewSandboxAdapter(&DBConfig{Type: "sqlite", FilePath: path}, &SandboxConfig{Timeout: 300 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	assertCount(t, a, `SELECT count(*) FROM t`, 2)
+	first := a.worker
+
+	start := time.Now()
+	if _, err := a.ExecuteQuery(context.Background(), endlessQuery); err == nil {
+		t.Fatal("expected the endless query to be killed")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("worker was not killed on timeout: took %v", elapsed)
+	}
+	select {
+	case <-first.done:
+	default:
+		t.Fatal("timed-out worker is still running")
+	}
+
+	assertCount(t, a, `SELECT count(*) FROM t`, 2)
+}
@@ -0,0 +1,35 @@
+//go:build unix
+
+package adapter
+
+import "syscall"
+
+// applyMemoryRlimit sets a hard address-space limit on the current process
+func applyMemoryRlimit(maxMemoryMB int) error {
+	if maxMemoryMB <= 0 {
+		return nil
+	}
+	limit := uint64(maxMemoryMB) * 1024 * 1024
+	return syscall.Setrlimit(syscall.RLIMIT_AS, &syscall.Rlimit{Cur: limit, Max: limit})
+}
+
+// limitCPUFromNow allows maxCPUSeconds more CPU time before SIGXCPU (soft limit, re-armed per query)
+func limitCPUFromNow(maxCPUSeconds int) error {
+	if maxCPUSeconds <= 0 {
+		return nil
+	}
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return err
+	}
+	var current syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_CPU, &current); err != nil {
+		return err
+	}
+	used := uint64(usage.Utime.Sec + usage.Stime.Sec)
+	limit := used + uint64(maxCPUSeconds) + 1 // +1: the seconds above are truncated
+	if limit > current.Max {
+		limit = current.Max
+	}
+	return syscall.Setrlimit(syscall.RLIMIT_CPU, &syscall.Rlimit{Cur: limit, Max: current.Max})
+}
@@ -0,0 +1,507 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// queryStrings runs a parameterized metadata query and returns rows as column -> string
+// Used by schema introspection only, where every value is a name, flag or number
+func queryStrings(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]map[string]sql.NullString, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]sql.NullString
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]sql.NullString, len(columns))
+		for i, col := range columns {
+			row[col] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// isTruthy interprets driver-specific boolean renderings ("1", "true", "t", "YES")
+func isTruthy(s string) bool {
+	switch s {
+	case "1", "true", "t", "TRUE", "YES", "yes":
+		return true
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n != 0
+	}
+	return false
+}
+
+// appendIndexColumn groups per-column index rows into IndexInfo, preserving first-seen order
+func appendIndexColumn(indexes []IndexInfo, pos map[string]int, name, column string, unique, primary bool) []IndexInfo {
+	i, ok := pos[name]
+	if !ok {
+		pos[name] = len(indexes)
+		return append(indexes, IndexInfo{
+			Name:      name,
+			Columns:   []string{column},
+			IsUnique:  unique,
+			IsPrimary: primary,
+		})
+	}
+	indexes[i].Columns = append(indexes[i].Columns, column)
+	return indexes
+}
+
+// ========== SQLite ==========
+
+// GetTables SQLite: sqlite_master, excluding internal sqlite_* tables
+// Tables of attached databases are returned qualified as alias.table
+func (a *SQLiteAdapter) GetTables(ctx context.Context) ([]string, error) {
+	rows, err := queryStrings(ctx, a.db,
+		"SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	tables := make([]string, 0, len(rows))
+	for _, row := range rows {
+		tables = append(tables, row["name"].String)
+	}
+
+	aliases := make([]string, 0, len(a.config.Attach))
+	for alias := range a.config.Attach {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		rows, err := queryStrings(ctx, a.db, fmt.Sprintf(
+			`SELECT name FROM "%s".sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%%' ORDER BY name`, alias))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tables of %s: %w", alias, err)
+		}
+		for _, row := range rows {
+			tables = append(tables, alias+"."+row["name"].String)
+		}
+	}
+	return tables, nil
+}
+
+// GetViews SQLite: sqlite_master views, attached databases' qualified as alias.view
+func (a *SQLiteAdapter) GetViews(ctx context.Context) ([]ViewInfo, error) {
+	schemas := []string{"main"}
+	aliases := make([]string, 0, len(a.config.Attach))
+	for alias := range a.config.Attach {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	schemas = append(schemas, aliases...)
+
+	var views []ViewInfo
+	for _, schema := range schemas {
+		rows, err := queryStrings(ctx, a.db, fmt.Sprintf(
+			`SELECT name, sql FROM "%s".sqlite_master WHERE type='view' ORDER BY name`, schema))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list views: %w", err)
+		}
+		for _, row := range rows {
+			name := row["name"].String
+			if schema != "main" {
+				name = schema + "." + name
+			}
+			views = append(views, ViewInfo{Name: name, Definition: sqliteViewSelect(row["sql"].String)})
+		}
+	}
+	return views, nil
+}
+
+// GetColumns SQLite: pragma_table_xinfo (hidden 2/3 = virtual/stored generated columns)
+func (a *SQLiteAdapter) GetColumns(ctx context.Context, table string) ([]ColumnInfo, error) {
+	schema, name := a.splitSchema(table)
+	rows, err := queryStrings(ctx, a.db,
+		`SELECT name, type, "notnull" AS not_null, dflt_value, pk, hidden FROM pragma_table_xinfo(?, ?) ORDER BY cid`, name, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns of %s: %w", table, err)
+	}
+
+	createSQL := a.tableSQL(ctx, schema, name)
+	collations := sqliteColumnCollations(createSQL)
+	expressions := sqliteGeneratedExpressions(createSQL)
+
+	columns := make([]ColumnInfo, 0, len(rows))
+	for _, row := range rows {
+		collation := collations[strings.ToLower(row["name"].String)]
+		col := ColumnInfo{
+			Name:            row["name"].String,
+			Type:            row["type"].String,
+			Nullable:        !isTruthy(row["not_null"].String), // SQLite: 0=nullable, 1=not null
+			DefaultValue:    row["dflt_value"].String,
+			IsPrimaryKey:    isTruthy(row["pk"].String), // pk is 1-based position in PK, 0 if not
+			Collation:       collation,
+			CaseInsensitive: isCaseInsensitiveCollation(collation),
+		}
+		switch row["hidden"].String {
+		case "1":
+			continue // Hidden column of a virtual table
+		case "2":
+			col.Generated = GeneratedVirtual
+		case "3":
+			col.Generated = GeneratedStored
+		}
+		if col.Generated != "" {
+			col.Expression = expressions[strings.ToLower(col.Name)]
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// tableSQL SQLite: the CREATE TABLE statement of a table ("" for views and unknown tables)
+func (a *SQLiteAdapter) tableSQL(ctx context.Context, schema, name string) string {
+	var createSQL sql.NullString
+	query := fmt.Sprintf(`SELECT sql FROM "%s".sqlite_master WHERE type = 'table' AND name = ?`, schema)
+	if err := a.db.QueryRowContext(ctx, query, name).Scan(&createSQL); err != nil {
+		return ""
+	}
+	return createSQL.String
+}
+
+// GetForeignKeys SQLite: pragma_foreign_key_list
+// A NULL "to" column means the parent's primary key, resolved here so callers always get a column
+func (a *SQLiteAdapter) GetForeignKeys(ctx context.Context, table string) ([]ForeignKeyInfo, error) {
+	schema, name := a.splitSchema(table)
+	rows, err := queryStrings(ctx, a.db,
+		`SELECT id, seq, "table" AS ref_table, "from" AS from_col, "to" AS to_col FROM pragma_foreign_key_list(?, ?) ORDER BY id, seq`, name, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign keys of %s: %w", table, err)
+	}
+
+	fks := make([]ForeignKeyInfo, 0, len(rows))
+	for _, row := range rows {
+		fk := ForeignKeyInfo{
+			Name:             row["id"].String,
+			ColumnName:       row["from_col"].String,
+			ReferencedTable:  row["ref_table"].String,
+			ReferencedColumn: row["to_col"].String,
+		}
+		if fk.ReferencedColumn == "" {
+			seq, _ := strconv.Atoi(row["seq"].String)
+			fk.ReferencedColumn = a.primaryKeyColumn(ctx, schema, fk.ReferencedTable, seq)
+		}
+		// FKs can only reference tables in the same database file
+		if schema != "main" {
+			fk.ReferencedTable = schema + "." + fk.ReferencedTable
+		}
+		fks = append(fks, fk)
+	}
+	return fks, nil
+}
+
+// primaryKeyColumn returns the seq-th (0-based) primary key column of a table, or "" if unknown
+func (a *SQLiteAdapter) primaryKeyColumn(ctx context.Context, schema, table string, seq int) string {
+	rows, err := queryStrings(ctx, a.db,
+		"SELECT name FROM pragma_table_info(?, ?) WHERE pk > 0 ORDER BY pk", table, schema)
+	if err != nil || seq >= len(rows) {
+		return ""
+	}
+	return rows[seq]["name"].String
+}
+
+// GetIndexes SQLite: pragma_index_list + pragma_index_info
+func (a *SQLiteAdapter) GetIndexes(ctx context.Context, table string) ([]IndexInfo, error) {
+	schema, name := a.splitSchema(table)
+	rows, err := queryStrings(ctx, a.db,
+		`SELECT il.name AS index_name, il."unique" AS is_unique, il.origin AS origin, ii.name AS column_name
+FROM pragma_index_list(?1, ?2) AS il, pragma_index_info(il.name, ?2) AS ii
+ORDER BY il.seq, ii.seqno`, name, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes of %s: %w", table, err)
+	}
+
+	var indexes []IndexInfo
+	pos := make(map[string]int)
+	for _, row := range rows {
+		indexes = appendIndexColumn(indexes, pos,
+			row["index_name"].String, row["column_name"].String,
+			isTruthy(row["is_unique"].String), row["origin"].String == "pk")
+	}
+	return indexes, nil
+}
+
+// ========== MySQL ==========
+
+// GetTables MySQL: base tables of the current database
+func (a *MySQLAdapter) GetTables(ctx context.Context) ([]string, error) {
+	rows, err := queryStrings(ctx, a.db,
+		"SELECT table_name AS name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	tables := make([]string, 0, len(rows))
+	for _, row := range rows {
+		tables = append(tables, row["name"].String)
+	}
+	return tables, nil
+}
+
+// GetViews MySQL: information_schema.views of the current database
+func (a *MySQLAdapter) GetViews(ctx context.Context) ([]ViewInfo, error) {
+	rows, err := queryStrings(ctx, a.db,
+		"SELECT table_name AS name, view_definition AS definition FROM information_schema.views WHERE table_schema = DATABASE() ORDER BY table_name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+
+	views := make([]ViewInfo, 0, len(rows))
+	for _, row := range rows {
+		views = append(views, ViewInfo{Name: row["name"].String, Definition: row["definition"].String})
+	}
+	return views, nil
+}
+
+// GetColumns MySQL: information_schema.columns (extra names VIRTUAL / STORED GENERATED columns)
+func (a *MySQLAdapter) GetColumns(ctx context.Context, table string) ([]ColumnInfo, error) {
+	rows, err := queryStrings(ctx, a.db,
+		`SELECT column_name AS name, column_type AS type, is_nullable AS nullable,
+       column_default AS dflt_value, column_key AS col_key, column_comment AS comment,
+       collation_name AS collation, extra, generation_expression AS expression
+FROM information_schema.columns
+WHERE table_schema = DATABASE() AND table_name = ?
+ORDER BY ordinal_position`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns of %s: %w", table, err)
+	}
+
+	columns := make([]ColumnInfo, 0, len(rows))
+	for _, row := range rows {
+		columns = append(columns, ColumnInfo{
+			Name:            row["name"].String,
+			Type:            row["type"].String,
+			Nullable:        row["nullable"].String == "YES",
+			DefaultValue:    row["dflt_value"].String,
+			IsPrimaryKey:    row["col_key"].String == "PRI",
+			Comment:         row["comment"].String,
+			Collation:       row["collation"].String,
+			CaseInsensitive: isCaseInsensitiveCollation(row["collation"].String),
+			Generated:       mysqlGenerated(row["extra"].String),
+			Expression:      row["expression"].String,
+		})
+	}
+	return columns, nil
+}
+
+// mysqlGenerated generated column kind from information_schema.columns.extra
+func mysqlGenerated(extra string) string {
+	extra = strings.ToUpper(extra)
+	switch {
+	case strings.Contains(extra, "VIRTUAL GENERATED"):
+		return GeneratedVirtual
+	case strings.Contains(extra, "STORED GENERATED"):
+		return GeneratedStored
+	}
+	return ""
+}
+
+// GetForeignKeys MySQL: information_schema.key_column_usage
+func (a *MySQLAdapter) GetForeignKeys(ctx context.Context, table string) ([]ForeignKeyInfo, error) {
+	rows, err := queryStrings(ctx, a.db,
+		`SELECT constraint_name AS name, column_name AS from_col,
+       referenced_table_name AS ref_table, referenced_column_name AS to_col
+FROM information_schema.key_column_usage
+WHERE table_schema = DATABASE() AND table_name = ? AND referenced_table_name IS NOT NULL
+ORDER BY constraint_name, ordinal_position`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign keys of %s: %w", table, err)
+	}
+
+	fks := make([]ForeignKeyInfo, 0, len(rows))
+	for _, row := range rows {
+		fks = append(fks, ForeignKeyInfo{
+			Name:             row["name"].String,
+			ColumnName:       row["from_col"].String,
+			ReferencedTable:  row["ref_table"].String,
+			ReferencedColumn: row["to_col"].String,
+		})
+	}
+	return fks, nil
+}
+
+// GetIndexes MySQL: information_schema.statistics
+func (a *MySQLAdapter) GetIndexes(ctx context.Context, table string) ([]IndexInfo, error) {
+	rows, err := queryStrings(ctx, a.db,
+		`SELECT index_name, non_unique, column_name
+FROM information_schema.statistics
+WHERE table_schema = DATABASE() AND table_name = ?
+ORDER BY index_name, seq_in_index`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes of %s: %w", table, err)
+	}
+
+	var indexes []IndexInfo
+	pos := make(map[string]int)
+	for _, row := range rows {
+		name := row["index_name"].String
+		indexes = appendIndexColumn(indexes, pos,
+			name, row["column_name"].String,
+			!isTruthy(row["non_unique"].String), name == "PRIMARY")
+	}
+	return indexes, nil
+}
+
+// ========== PostgreSQL ==========
+
+// GetTables PostgreSQL: pg_tables of the current schema
+func (a *PostgreSQLAdapter) GetTables(ctx context.Context) ([]string, error) {
+	rows, err := queryStrings(ctx, a.db,
+		"SELECT tablename AS name FROM pg_tables WHERE schemaname = current_schema() ORDER BY tablename")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	tables := make([]string, 0, len(rows))
+	for _, row := range rows {
+		tables = append(tables, row["name"].String)
+	}
+	return tables, nil
+}
+
+// GetViews PostgreSQL: pg_views of the current schema
+func (a *PostgreSQLAdapter) GetViews(ctx context.Context) ([]ViewInfo, error) {
+	rows, err := queryStrings(ctx, a.db,
+		"SELECT viewname AS name, definition FROM pg_views WHERE schemaname = current_schema() ORDER BY viewname")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+
+	views := make([]ViewInfo, 0, len(rows))
+	for _, row := range rows {
+		views = append(views, ViewInfo{Name: row["name"].String, Definition: strings.TrimSpace(row["definition"].String)})
+	}
+	return views, nil
+}
+
+// GetColumns PostgreSQL: information_schema.columns + primary key constraint
+// Generated columns (is_generated = ALWAYS) are always stored in PostgreSQL.
+func (a *PostgreSQLAdapter) GetColumns(ctx context.Context, table string) ([]ColumnInfo, error) {
+	rows, err := queryStrings(ctx, a.db,
+		`SELECT c.column_name AS name, c.data_type AS type, c.is_nullable AS nullable,
+       c.column_default AS dflt_value, c.is_generated AS is_generated, c.generation_expression AS expression,
+       EXISTS (
+         SELECT 1 FROM information_schema.table_constraints tc
+         JOIN information_schema.key_column_usage kcu
+           ON kcu.constraint_name = tc.constraint_name AND kcu.table_schema = tc.table_schema
+         WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = c.table_schema
+           AND tc.table_name = c.table_name AND kcu.column_name = c.column_name
+       ) AS is_pk,
+       col_description(format('%I.%I', c.table_schema, c.table_name)::regclass::oid, c.ordinal_position) AS comment,
+       c.collation_name AS collation,
+       c.udt_name = 'citext' OR COALESCE((
+         SELECT NOT co.collisdeterministic FROM pg_collation co WHERE co.collname = c.collation_name LIMIT 1
+       ), false) AS case_insensitive
+FROM information_schema.columns c
+WHERE c.table_schema = current_schema() AND c.table_name = $1
+ORDER BY c.ordinal_position`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns of %s: %w", table, err)
+	}
+
+	columns := make([]ColumnInfo, 0, len(rows))
+	for _, row := range rows {
+		col := ColumnInfo{
+			Name:            row["name"].String,
+			Type:            row["type"].String,
+			Nullable:        row["nullable"].String == "YES",
+			DefaultValue:    row["dflt_value"].String,
+			IsPrimaryKey:    isTruthy(row["is_pk"].String),
+			Comment:         row["comment"].String,
+			Collation:       row["collation"].String,
+			CaseInsensitive: isTruthy(row["case_insensitive"].String),
+		}
+		if row["is_generated"].String == "ALWAYS" {
+			col.Generated = GeneratedStored
+			col.Expression = row["expression"].String
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// GetForeignKeys PostgreSQL: referential_constraints, matched column-by-column
+func (a *PostgreSQLAdapter) GetForeignKeys(ctx context.Context, table string) ([]ForeignKeyInfo, error) {
+	rows, err := queryStrings(ctx, a.db,
+		`SELECT kcu.constraint_name AS name, kcu.column_name AS from_col,
+       ref.table_name AS ref_table, ref.column_name AS to_col
+FROM information_schema.referential_constraints rc
+JOIN information_schema.key_column_usage kcu
+  ON kcu.constraint_name = rc.constraint_name AND kcu.constraint_schema = rc.constraint_schema
+JOIN information_schema.key_column_usage ref
+  ON ref.constraint_name = rc.unique_constraint_name AND ref.constraint_schema = rc.unique_constraint_schema
+ AND ref.ordinal_position = kcu.position_in_unique_constraint
+WHERE kcu.table_schema = current_schema() AND kcu.table_name = $1
+ORDER BY kcu.constraint_name, kcu.ordinal_position`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign keys of %s: %w", table, err)
+	}
+
+	fks := make([]ForeignKeyInfo, 0, len(rows))
+	for _, row := range rows {
+		fks = append(fks, ForeignKeyInfo{
+			Name:             row["name"].String,
+			ColumnName:       row["from_col"].String,
+			ReferencedTable:  row["ref_table"].String,
+			ReferencedColumn: row["to_col"].String,
+		})
+	}
+	return fks, nil
+}
+
+// GetIndexes PostgreSQL: pg_index, expanded per key column
+func (a *PostgreSQLAdapter) GetIndexes(ctx context.Context, table string) ([]IndexInfo, error) {
+	rows, err := queryStrings(ctx, a.db,
+		`SELECT i.relname AS index_name, ix.indisunique AS is_unique, ix.indisprimary AS is_primary,
+       att.attname AS column_name
+FROM pg_class t
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_index ix ON ix.indrelid = t.oid
+JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+JOIN pg_attribute att ON att.attrelid = t.oid AND att.attnum = k.attnum
+WHERE n.nspname = current_schema() AND t.relname = $1
+ORDER BY i.relname, k.ord`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes of %s: %w", table, err)
+	}
+
+	var indexes []IndexInfo
+	pos := make(map[string]int)
+	for _, row := range rows {
+		indexes = appendIndexColumn(indexes, pos,
+			row["index_name"].String, row["column_name"].String,
+			isTruthy(row["is_unique"].String), isTruthy(row["is_primary"].String))
+	}
+	return indexes, nil
+}
@@ -3,21 +3,36 @@ package adapter
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
 )
 
 // SQLiteAdapter SQLite adapter
 type SQLiteAdapter struct {
 	db     *sql.DB
+	keep   driver.Conn // Sentinel holding a shared-cache in-memory DB open (nil for files)
 	config *SQLiteConfig
 }
 
 // SQLiteConfig SQLite connection config
 type SQLiteConfig struct {
-	FilePath string // DB file path, ":memory:" for in-memory
+	FilePath string       // DB file path, ":memory:" for in-memory
+	InMemory bool         // Copy FilePath into an in-memory DB at connect time (read-heavy, small DBs)
+	Retry    *RetryConfig // Transient-error retry, e.g. "database is locked" (nil = DefaultRetryConfig)
+	Text     *TextOptions // LIKE case sensitivity and encoding normalization (nil = defaults)
+
+	// Attach extra DB files under schema aliases (alias -> file path)
+	// Tables are then addressed as alias.table; pins the pool to one connection
+	Attach map[string]string
 }
 
 // NewSQLiteAdapter creates SQLite adapter
@@ -29,33 +44,224 @@ func NewSQLiteAdapter(config *SQLiteConfig) *SQLiteAdapter {
 
 // Connect connects to database
 func (a *SQLiteAdapter) Connect(ctx context.Context) error {
-	db, err := sql.Open("sqlite", a.config.FilePath)
+	aliases, err := a.attachAliases()
 	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+		return err
+	}
+
+	if (a.config.InMemory || a.config.Text.noCase()) && a.config.FilePath != ":memory:" {
+		if err := a.connectInMemory(ctx, aliases); err != nil {
+			return err
+		}
+	} else {
+		db := sql.OpenDB(newSQLiteConnector(sqliteDSN(a.config.FilePath, a.config.Text), a.config.Attach, aliases))
+
+		// Test connection
+		if err := db.PingContext(ctx); err != nil {
+			db.Close()
+			return fmt.Errorf("failed to ping database: %w", err)
+		}
+
+		a.db = db
+	}
+
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	// ATTACH is per-connection; the connector re-attaches whenever the pool opens a new one,
+	// one connection just avoids doing that for every concurrent query
+	a.db.SetMaxOpenConns(1)
+	a.db.SetMaxIdleConns(1)
+	a.db.SetConnMaxLifetime(0)
+	a.db.SetConnMaxIdleTime(0)
+	return nil
+}
+
+var schemaAliasPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// attachAliases validates config.Attach and returns its aliases in attach order
+func (a *SQLiteAdapter) attachAliases() ([]string, error) {
+	aliases := make([]string, 0, len(a.config.Attach))
+	for alias, path := range a.config.Attach {
+		if !schemaAliasPattern.MatchString(alias) || strings.EqualFold(alias, "main") || strings.EqualFold(alias, "temp") {
+			return nil, fmt.Errorf("invalid schema alias: %q", alias)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("failed to attach %s: %w", alias, err)
+		}
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases, nil
+}
+
+// sqliteConnector opens SQLite connections with the configured files attached
+// database/sql discards a connection whose query was interrupted, so per-connection
+// state (ATTACH) must be rebuilt on every connection the pool opens, not once at Connect.
+type sqliteConnector struct {
+	dsn     string
+	attach  map[string]string
+	aliases []string
+	driver  *sqlite.Driver
+}
+
+func newSQLiteConnector(dsn string, attach map[string]string, aliases []string) *sqliteConnector {
+	return &sqliteConnector{dsn: dsn, attach: attach, aliases: aliases, driver: &sqlite.Driver{}}
+}
+
+// Connect opens a connection and attaches every alias
+func (c *sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	for _, alias := range c.aliases {
+		args := []driver.NamedValue{{Ordinal: 1, Value: c.attach[alias]}}
+		if _, err := conn.(driver.ExecerContext).ExecContext(ctx, fmt.Sprintf(`ATTACH DATABASE ? AS "%s"`, alias), args); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to attach %s: %w", alias, err)
+		}
+	}
+	return conn, nil
+}
+
+// Driver returns the underlying modernc driver
+func (c *sqliteConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// splitSchema splits "alias.table" into (alias, table) when alias is attached, else ("main", table)
+func (a *SQLiteAdapter) splitSchema(table string) (string, string) {
+	if i := strings.Index(table, "."); i > 0 {
+		if _, ok := a.config.Attach[table[:i]]; ok {
+			return table[:i], table[i+1:]
+		}
+	}
+	return "main", table
+}
+
+// quoteTable quotes a possibly alias-qualified table name
+func (a *SQLiteAdapter) quoteTable(table string) string {
+	schema, name := a.splitSchema(table)
+	quoted := `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	if schema != "main" {
+		return `"` + schema + `".` + quoted
 	}
+	return quoted
+}
+
+// memoryDBSeq numbers the shared-cache in-memory databases of this process
+var memoryDBSeq atomic.Int64
+
+// openSharedMemory opens a named shared-cache in-memory database
+// A private :memory: database dies with its connection, and database/sql replaces the
+// connection after an interrupted query. Every connection to the same name shares one
+// database, which lives as long as the sentinel connection kept in a.keep.
+func (a *SQLiteAdapter) openSharedMemory(aliases []string) (driver.Conn, error) {
+	dsn := sqliteDSN(fmt.Sprintf("file:reactsql_mem_%d?mode=memory&cache=shared", memoryDBSeq.Add(1)), a.config.Text)
+	connector := newSQLiteConnector(dsn, a.config.Attach, aliases)
 
-	// Test connection
-	if err := db.PingContext(ctx); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+	keep, err := connector.driver.Open(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	db := sql.OpenDB(connector)
+	// One connection: shared-cache writers would otherwise fail with SQLITE_LOCKED
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
 	a.db = db
+	a.keep = keep
+	return keep, nil
+}
+
+// connectInMemory copies the DB file into a shared-cache in-memory database
+func (a *SQLiteAdapter) connectInMemory(ctx context.Context, aliases []string) error {
+	if _, err := os.Stat(a.config.FilePath); err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	keep, err := a.openSharedMemory(aliases)
+	if err != nil {
+		return err
+	}
+
+	// Restore file pages into the in-memory DB via SQLite online backup API
+	err = func() error {
+		r, ok := keep.(sqliteRestorer)
+		if !ok {
+			return fmt.Errorf("sqlite driver does not support restore")
+		}
+		bck, err := r.NewRestore(a.config.FilePath)
+		if err != nil {
+			return err
+		}
+		for more := true; more; {
+			if more, err = bck.Step(-1); err != nil {
+				bck.Finish()
+				return err
+			}
+		}
+		return bck.Finish()
+	}()
+	if err == nil && a.config.Text.noCase() {
+		err = applyNoCaseCollation(ctx, a.db)
+	}
+	if err == nil {
+		err = a.db.PingContext(ctx)
+	}
+	if err != nil {
+		a.Close()
+		return fmt.Errorf("failed to copy database into memory: %w", err)
+	}
 	return nil
 }
 
+// sqliteRestorer is implemented by modernc.org/sqlite driver connections
+type sqliteRestorer interface {
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
 // Close closes connection
 func (a *SQLiteAdapter) Close() error {
+	var err error
 	if a.db != nil {
-		return a.db.Close()
+		err = a.db.Close()
 	}
-	return nil
+	// The in-memory database is freed with its last connection
+	if a.keep != nil {
+		a.keep.Close()
+		a.keep = nil
+	}
+	return err
 }
 
-// ExecuteQuery executes query
+// ExecuteQuery executes query, retrying transient errors per config.Retry
 func (a *SQLiteAdapter) ExecuteQuery(ctx context.Context, query string) (*QueryResult, error) {
+	result, err := executeWithRetry(ctx, a.config.Retry, query, a.executeQueryOnce)
+	normalizeEncoding(result, a.config.Text)
+	return result, err
+}
+
+// executeQueryOnce executes query (single attempt)
+func (a *SQLiteAdapter) executeQueryOnce(ctx context.Context, query string) (*QueryResult, error) {
 	start := time.Now()
 
-	rows, err := a.db.QueryContext(ctx, query)
+	// Dedicated connection so a cancelled ctx kills the query instead of abandoning it
+	q, release, err := cancelableConn(ctx, a.db, sqliteKiller)
+	if err != nil {
+		return &QueryResult{
+			Error:         err.Error(),
+			ExecutionTime: time.Since(start).Milliseconds(),
+		}, err
+	}
+	defer release()
+
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return &QueryResult{
 			Error:         err.Error(),
@@ -69,9 +275,14 @@ func (a *SQLiteAdapter) ExecuteQuery(ctx context.Context, query string) (*QueryR
 	if err != nil {
 		return nil, err
 	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
 
 	// Read data
 	var result []map[string]interface{}
+	var typed [][]Value
 	for rows.Next() {
 		values := make([]interface{}, len(columns))
 		valuePtrs := make([]interface{}, len(columns))
@@ -93,6 +304,12 @@ func (a *SQLiteAdapter) ExecuteQuery(ctx context.Context, query string) (*QueryR
 			}
 		}
 		result = append(result, row)
+
+		typedRow := make([]Value, len(columns))
+		for i, val := range values {
+			typedRow[i] = newColumnValue(val, columnTypes[i].DatabaseTypeName())
+		}
+		typed = append(typed, typedRow)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -102,6 +319,7 @@ func (a *SQLiteAdapter) ExecuteQuery(ctx context.Context, query string) (*QueryR
 	return &QueryResult{
 		Columns:       columns,
 		Rows:          result,
+		Values:        typed,
 		RowCount:      len(result),
 		ExecutionTime: time.Since(start).Milliseconds(),
 	}, nil
@@ -119,7 +337,7 @@ func (a *SQLiteAdapter) GetDatabaseVersion(ctx context.Context) (string, error)
 		return "", err
 	}
 	if result.Error != "" {
-		return "", fmt.Errorf(result.Error)
+		return "", errors.New(result.Error)
 	}
 	if len(result.Rows) > 0 {
 		if version, ok := result.Rows[0]["version"].(string); ok {
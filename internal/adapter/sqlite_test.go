@@ -0,0 +1,130 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// endlessQuery never finishes on its own
+const endlessQuery = `WITH RECURSIVE r(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM r) SELECT count(*) FROM r`
+
+// createSQLiteFile writes a DB file with table t holding rows 1..n
+func createSQLiteFile(t *testing.T, dir, name string, n int) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE t (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= n; i++ {
+		if _, err := db.Exec(`INSERT INTO t VALUES (?)`, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+// assertInterrupted runs endlessQuery under a short timeout and expects it to be killed promptly
+func assertInterrupted(t *testing.T, a DBAdapter) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := a.ExecuteQuery(ctx, endlessQuery); err == nil {
+		t.Fatal("expected the endless query to fail after the timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("query was not interrupted: took %v", elapsed)
+	}
+}
+
+// assertCount expects query to return a single count of want
+func assertCount(t *testing.T, a DBAdapter, query string, want int64) {
+	t.Helper()
+	result, err := a.ExecuteQuery(context.Background(), query)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	if result.RowCount != 1 || len(result.Values) != 1 {
+		t.Fatalf("%s: got %d rows", query, result.RowCount)
+	}
+	if got := result.Values[0][0].Int; got != want {
+		t.Fatalf("%s: got %d, want %d", query, got, want)
+	}
+}
+
+func TestCancelledQueryKeepsFileDatabase(t *testing.T) {
+	path := createSQLiteFile(t, t.TempDir(), "main.sqlite", 3)
+	a := NewSQLiteAdapter(&SQLiteConfig{FilePath: path})
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	assertInterrupted(t, a)
+	assertCount(t, a, `SELECT count(*) FROM t`, 3)
+}
+
+func TestCancelledQueryKeepsInMemoryCopy(t *testing.T) {
+	path := createSQLiteFile(t, t.TempDir(), "main.sqlite", 5)
+	a := NewSQLiteAdapter(&SQLiteConfig{FilePath: path, InMemory: true})
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	for i := 0; i < 2; i++ {
+		assertInterrupted(t, a)
+		assertCount(t, a, `SELECT count(*) FROM t`, 5)
+	}
+	tables, err := a.GetTables(context.Background())
+	if err != nil || len(tables) != 1 {
+		t.Fatalf("GetTables = %v, %v; want [t]", tables, err)
+	}
+}
+
+func TestCancelledQueryKeepsAttachedDatabases(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := createSQLiteFile(t, dir, "main.sqlite", 2)
+	extraPath := createSQLiteFile(t, dir, "extra.sqlite", 4)
+
+	for _, inMemory := range []bool{false, true} {
+		a := NewSQLiteAdapter(&SQLiteConfig{
+			FilePath: mainPath,
+			InMemory: inMemory,
+			Attach:   map[string]string{"extra": extraPath},
+		})
+		if err := a.Connect(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		assertInterrupted(t, a)
+		assertCount(t, a, `SELECT count(*) FROM t`, 2)
+		assertCount(t, a, `SELECT count(*) FROM extra.t`, 4)
+		a.Close()
+	}
+}
+
+func TestCancelledQueryKeepsCSVTables(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "people.csv"), []byte("id,name\n1,Ann\n2,Bob\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := NewFileAdapter(&FileConfig{Dir: dir})
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	assertInterrupted(t, a)
+	assertCount(t, a, `SELECT count(*) FROM people`, 2)
+}
@@ -0,0 +1,346 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// TextOptions collation and encoding handling (optional)
+type TextOptions struct {
+	// SQLite: make LIKE case-sensitive (PRAGMA case_sensitive_like); by default LIKE ignores ASCII case
+	CaseSensitiveLike bool
+
+	// SQLite: compare and sort text case-insensitively ('usa' = 'USA', ORDER BY ignores case)
+	// Text columns without an explicit collation get COLLATE NOCASE. Implies an in-memory copy,
+	// the database file is never modified; CSV sources are already in memory.
+	NoCase bool
+
+	// Charset of legacy non-UTF8 text: "latin1" or "windows-1252" (empty = leave bytes as is)
+	// Result strings that are not valid UTF-8 are decoded from it, so 'São Paulo' compares and prints correctly.
+	Encoding string
+}
+
+// Validate checks the encoding name
+func (o *TextOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	switch strings.ToLower(o.Encoding) {
+	case "", "utf8", "utf-8", "latin1", "iso-8859-1", "windows-1252", "cp1252":
+		return nil
+	default:
+		return fmt.Errorf("unsupported text encoding: %s", o.Encoding)
+	}
+}
+
+// noCase reports whether NoCase is set (nil-safe)
+func (o *TextOptions) noCase() bool {
+	return o != nil && o.NoCase
+}
+
+// sqliteDSN appends per-connection pragmas from text options to a SQLite path
+func sqliteDSN(path string, opts *TextOptions) string {
+	if opts == nil || !opts.CaseSensitiveLike {
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "_pragma=case_sensitive_like(1)"
+}
+
+// cp1252High maps windows-1252 bytes 0x80-0x9F (latin1 has C1 controls there)
+var cp1252High = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// decodeLegacy decodes a single-byte encoded string to UTF-8; valid UTF-8 is returned unchanged
+func decodeLegacy(s, encoding string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	cp1252 := false
+	switch strings.ToLower(encoding) {
+	case "latin1", "iso-8859-1":
+	case "windows-1252", "cp1252":
+		cp1252 = true
+	default:
+		return s
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s) + len(s)/4)
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if cp1252 && b >= 0x80 && b <= 0x9F {
+			sb.WriteRune(cp1252High[b-0x80])
+		} else {
+			sb.WriteRune(rune(b))
+		}
+	}
+	return sb.String()
+}
+
+// normalizeEncoding decodes non-UTF8 text in a result in place
+func normalizeEncoding(result *QueryResult, opts *TextOptions) {
+	if result == nil || opts == nil || opts.Encoding == "" {
+		return
+	}
+	for _, row := range result.Rows {
+		for col, val := range row {
+			if s, ok := val.(string); ok {
+				row[col] = decodeLegacy(s, opts.Encoding)
+			}
+		}
+	}
+	for _, row := range result.Values {
+		for i := range row {
+			if row[i].Kind == KindText {
+				row[i].Text = decodeLegacy(row[i].Text, opts.Encoding)
+			}
+		}
+	}
+}
+
+var collatePattern = regexp.MustCompile("(?i)\\bCOLLATE\\s+[\"'`\\[]?(\\w+)")
+
+// sqliteColumnCollations extracts explicit per-column COLLATE clauses from a CREATE TABLE statement
+// SQLite has no pragma for column collation, so the stored DDL is parsed (lower-cased name -> collation)
+func sqliteColumnCollations(createSQL string) map[string]string {
+	result := make(map[string]string)
+	start := strings.Index(createSQL, "(")
+	end := strings.LastIndex(createSQL, ")")
+	if start < 0 || end <= start {
+		return result
+	}
+
+	for _, def := range splitTopLevel(createSQL[start+1 : end]) {
+		def = strings.TrimSpace(def)
+		if def == "" {
+			continue
+		}
+		first := strings.ToUpper(strings.Fields(def)[0])
+		switch first {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			continue
+		}
+		m := collatePattern.FindStringSubmatch(def)
+		if m == nil {
+			continue
+		}
+		result[strings.ToLower(columnDefName(def))] = strings.ToUpper(m[1])
+	}
+	return result
+}
+
+// splitTopLevel splits a column list on commas outside parentheses and quotes
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	last := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '[':
+			quote = ']'
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[last:i])
+			last = i + 1
+		}
+	}
+	return append(parts, s[last:])
+}
+
+// columnDefName returns the (unquoted) column name at the start of a column definition
+func columnDefName(def string) string {
+	closers := map[byte]byte{'"': '"', '`': '`', '[': ']', '\'': '\''}
+	if closer, ok := closers[def[0]]; ok {
+		if i := strings.IndexByte(def[1:], closer); i >= 0 {
+			return def[1 : i+1]
+		}
+	}
+	if i := strings.IndexAny(def, " \t\r\n"); i > 0 {
+		return def[:i]
+	}
+	return def
+}
+
+// noCaseTypeStop words that end a column's declared type
+var noCaseTypeStop = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "NOT": true, "NULL": true, "UNIQUE": true, "CHECK": true,
+	"DEFAULT": true, "COLLATE": true, "REFERENCES": true, "GENERATED": true, "AS": true,
+}
+
+// noCaseTableSQL adds COLLATE NOCASE to the text columns of a CREATE TABLE statement
+// Text columns are those declared CHAR/CLOB/TEXT or without a type (which may hold text);
+// columns with an explicit COLLATE keep it. Returns createSQL unchanged if nothing applies.
+func noCaseTableSQL(createSQL string) string {
+	start := strings.Index(createSQL, "(")
+	end := strings.LastIndex(createSQL, ")")
+	if start < 0 || end <= start {
+		return createSQL
+	}
+
+	defs := splitTopLevel(createSQL[start+1 : end])
+	changed := false
+	for i, def := range defs {
+		trimmed := strings.TrimSpace(def)
+		if trimmed == "" {
+			continue
+		}
+		switch strings.ToUpper(strings.Fields(trimmed)[0]) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			continue
+		}
+		if collatePattern.MatchString(trimmed) {
+			continue
+		}
+		nameLen := len(columnDefName(trimmed))
+		if strings.ContainsRune("\"`['", rune(trimmed[0])) {
+			nameLen += 2 // quotes
+		}
+		words := strings.Fields(strings.ToUpper(trimmed[min(nameLen, len(trimmed)):]))
+		var declType string
+		for _, w := range words {
+			if noCaseTypeStop[w] {
+				break
+			}
+			declType += w
+		}
+		if declType != "" && !strings.Contains(declType, "CHAR") && !strings.Contains(declType, "CLOB") && !strings.Contains(declType, "TEXT") {
+			continue
+		}
+		defs[i] = strings.TrimRight(def, " \t\r\n") + " COLLATE NOCASE"
+		changed = true
+	}
+	if !changed {
+		return createSQL
+	}
+	return createSQL[:start+1] + strings.Join(defs, ",") + createSQL[end:]
+}
+
+// applyNoCaseCollation gives the text columns of every table COLLATE NOCASE (TextOptions.NoCase)
+// Column collations only exist in the stored DDL, so sqlite_schema is edited in place and
+// indexes are rebuilt for the new ordering. Only ever run on a private in-memory database.
+func applyNoCaseCollation(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, `SELECT name, sql FROM sqlite_schema
+		WHERE type = 'table' AND sql IS NOT NULL AND name NOT LIKE 'sqlite_%' AND sql NOT LIKE 'CREATE VIRTUAL%'`)
+	if err != nil {
+		return err
+	}
+	updates := make(map[string]string)
+	for rows.Next() {
+		var name, createSQL string
+		if err := rows.Scan(&name, &createSQL); err != nil {
+			rows.Close()
+			return err
+		}
+		if updated := noCaseTableSQL(createSQL); updated != createSQL {
+			updates[name] = updated
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	var version int64
+	if err := db.QueryRowContext(ctx, "PRAGMA schema_version").Scan(&version); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, "PRAGMA writable_schema = ON"); err != nil {
+		return err
+	}
+	for name, createSQL := range updates {
+		if _, err := db.ExecContext(ctx, "UPDATE sqlite_schema SET sql = ? WHERE type = 'table' AND name = ?", createSQL, name); err != nil {
+			db.ExecContext(ctx, "PRAGMA writable_schema = OFF")
+			return err
+		}
+	}
+	// A new schema version makes SQLite reparse the edited DDL
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("PRAGMA schema_version = %d", version+1)); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, "PRAGMA writable_schema = OFF"); err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, "REINDEX")
+	return err
+}
+
+// isCaseInsensitiveCollation reports whether '=' ignores case under a collation name
+func isCaseInsensitiveCollation(collation string) bool {
+	c := strings.ToLower(collation)
+	return c == "nocase" || strings.HasSuffix(c, "_ci")
+}
+
+// generatedPattern start of a generated column clause: [GENERATED ALWAYS] AS (
+var generatedPattern = regexp.MustCompile(`(?i)\b(?:GENERATED\s+ALWAYS\s+)?AS\s*\(`)
+
+// sqliteGeneratedExpressions generation expressions of computed columns in a CREATE TABLE
+// statement (lower-cased column name -> expression)
+func sqliteGeneratedExpressions(createSQL string) map[string]string {
+	result := make(map[string]string)
+	start := strings.Index(createSQL, "(")
+	end := strings.LastIndex(createSQL, ")")
+	if start < 0 || end <= start {
+		return result
+	}
+
+	for _, def := range splitTopLevel(createSQL[start+1 : end]) {
+		def = strings.TrimSpace(def)
+		if def == "" {
+			continue
+		}
+		loc := generatedPattern.FindStringIndex(def)
+		if loc == nil {
+			continue
+		}
+		// Expression runs to the matching close paren
+		depth := 1
+		for i := loc[1]; i < len(def); i++ {
+			switch def[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 {
+				result[strings.ToLower(columnDefName(def))] = strings.TrimSpace(def[loc[1]:i])
+				break
+			}
+		}
+	}
+	return result
+}
+
+// viewSelectPattern the SELECT of a CREATE VIEW statement
+var viewSelectPattern = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:TEMP(?:ORARY)?\s+)?VIEW\s+.*?\bAS\s+(.*?)\s*;?\s*$`)
+
+// sqliteViewSelect the defining SELECT of a view's CREATE VIEW statement, whitespace collapsed
+func sqliteViewSelect(createSQL string) string {
+	if m := viewSelectPattern.FindStringSubmatch(createSQL); m != nil {
+		createSQL = m[1]
+	}
+	return strings.Join(strings.Fields(createSQL), " ")
+}
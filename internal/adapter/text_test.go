@@ -0,0 +1,107 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNoCaseTableSQL(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{
+			`CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT NOT NULL, code VARCHAR(3), raw, note TEXT COLLATE BINARY, PRIMARY KEY (id))`,
+			`CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT NOT NULL COLLATE NOCASE, code VARCHAR(3) COLLATE NOCASE, raw COLLATE NOCASE, note TEXT COLLATE BINARY, PRIMARY KEY (id))`,
+		},
+		{
+			"CREATE TABLE \"a b\" (`Country Name` text, [Score] REAL)",
+			"CREATE TABLE \"a b\" (`Country Name` text COLLATE NOCASE, [Score] REAL)",
+		},
+		{
+			`CREATE TABLE n (x INT, y REAL)`,
+			`CREATE TABLE n (x INT, y REAL)`,
+		},
+	}
+	for _, c := range cases {
+		if got := noCaseTableSQL(c.in); got != c.want {
+			t.Errorf("noCaseTableSQL(%q)\n got %q\nwant %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestNoCaseOption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geo.sqlite")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE country (id INTEGER PRIMARY KEY, name TEXT)`,
+		`CREATE INDEX country_name ON country (name)`,
+		`INSERT INTO country (name) VALUES ('USA'), ('canada'), ('Brazil')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewSQLiteAdapter(&SQLiteConfig{FilePath: path, Text: &TextOptions{NoCase: true}})
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	assertCount(t, a, `SELECT count(*) FROM country WHERE name = 'usa'`, 1)
+	assertCount(t, a, `SELECT count(*) FROM country INDEXED BY country_name WHERE name = 'CANADA'`, 1)
+
+	result, err := a.ExecuteQuery(context.Background(), `SELECT name FROM country ORDER BY name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, row := range result.Values {
+		order = append(order, row[0].Text)
+	}
+	if want := []string{"Brazil", "canada", "USA"}; len(order) != 3 || order[0] != want[0] || order[1] != want[1] || order[2] != want[2] {
+		t.Fatalf("ORDER BY = %v, want %v", order, want)
+	}
+
+	columns, err := a.GetColumns(context.Background(), "country")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, col := range columns {
+		if col.Name == "name" && !col.CaseInsensitive {
+			t.Fatal("name column should be reported case-insensitive")
+		}
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Fatal("NoCase must not modify the database file")
+	}
+}
+
+func TestNoCaseOptionCSV(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "city.csv"), []byte("name,pop\nParis,2\nparis,3\nLyon,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := NewFileAdapter(&FileConfig{Dir: dir, Text: &TextOptions{NoCase: true}})
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	assertCount(t, a, `SELECT count(*) FROM city WHERE name = 'PARIS'`, 2)
+	assertCount(t, a, `SELECT count(DISTINCT name) FROM city`, 2)
+}
@@ -0,0 +1,190 @@
+package adapter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueKind type of a result cell
+type ValueKind uint8
+
+const (
+	KindNull  ValueKind = iota // SQL NULL
+	KindInt                    // integer
+	KindFloat                  // real / decimal
+	KindText                   // text / blob
+	KindBool                   // boolean (PostgreSQL)
+	KindTime                   // date / timestamp (driver-parsed)
+)
+
+// Value typed result cell
+// Keeps NULL vs empty string and integer vs float distinctions that string rendering loses
+type Value struct {
+	Kind  ValueKind `json:"k"`
+	Int   int64     `json:"i,omitempty"`
+	Float float64   `json:"f,omitempty"`
+	Text  string    `json:"s,omitempty"`
+	Bool  bool      `json:"b,omitempty"`
+	Time  time.Time `json:"t,omitzero"`
+}
+
+// NewValue converts a driver value to Value
+func NewValue(val interface{}) Value {
+	switch v := val.(type) {
+	case nil:
+		return Value{Kind: KindNull}
+	case int64:
+		return Value{Kind: KindInt, Int: v}
+	case int:
+		return Value{Kind: KindInt, Int: int64(v)}
+	case int32:
+		return Value{Kind: KindInt, Int: int64(v)}
+	case uint64:
+		if v <= math.MaxInt64 {
+			return Value{Kind: KindInt, Int: int64(v)}
+		}
+		return Value{Kind: KindFloat, Float: float64(v)}
+	case float64:
+		return Value{Kind: KindFloat, Float: v}
+	case float32:
+		return Value{Kind: KindFloat, Float: float64(v)}
+	case bool:
+		return Value{Kind: KindBool, Bool: v}
+	case time.Time:
+		return Value{Kind: KindTime, Time: v}
+	case []byte:
+		return Value{Kind: KindText, Text: string(v)}
+	case string:
+		return Value{Kind: KindText, Text: v}
+	default:
+		return Value{Kind: KindText, Text: fmt.Sprintf("%v", v)}
+	}
+}
+
+// newColumnValue converts a driver value using the declared column type
+// MySQL (text protocol) and PostgreSQL NUMERIC return numbers as []byte, parse them back
+func newColumnValue(val interface{}, dbTypeName string) Value {
+	v := NewValue(val)
+	if v.Kind != KindText {
+		return v
+	}
+
+	switch t := strings.ToUpper(dbTypeName); {
+	case strings.Contains(t, "INT"):
+		if i, err := strconv.ParseInt(v.Text, 10, 64); err == nil {
+			return Value{Kind: KindInt, Int: i}
+		}
+	case strings.Contains(t, "DECIMAL"), strings.Contains(t, "NUMERIC"),
+		strings.Contains(t, "FLOAT"), strings.Contains(t, "DOUBLE"), strings.Contains(t, "REAL"):
+		if f, err := strconv.ParseFloat(v.Text, 64); err == nil {
+			return Value{Kind: KindFloat, Float: f}
+		}
+	}
+	return v
+}
+
+// Interface returns the plain Go value (nil, int64, float64, string, bool, time.Time)
+func (v Value) Interface() interface{} {
+	switch v.Kind {
+	case KindInt:
+		return v.Int
+	case KindFloat:
+		return v.Float
+	case KindText:
+		return v.Text
+	case KindBool:
+		return v.Bool
+	case KindTime:
+		return v.Time
+	default:
+		return nil
+	}
+}
+
+// IsNull reports SQL NULL
+func (v Value) IsNull() bool {
+	return v.Kind == KindNull
+}
+
+// String renders the value for display (NULL as "NULL")
+func (v Value) String() string {
+	switch v.Kind {
+	case KindNull:
+		return "NULL"
+	case KindInt:
+		return strconv.FormatInt(v.Int, 10)
+	case KindFloat:
+		return strconv.FormatFloat(v.Float, 'f', -1, 64)
+	case KindBool:
+		return strconv.FormatBool(v.Bool)
+	case KindTime:
+		return v.Time.Format(time.RFC3339Nano)
+	default:
+		return v.Text
+	}
+}
+
+// Literal renders the value SQL-literal style (text quoted), for showing results to the LLM
+func (v Value) Literal() string {
+	if v.Kind == KindText {
+		return "'" + strings.ReplaceAll(v.Text, "'", "''") + "'"
+	}
+	return v.String()
+}
+
+// Key canonical comparison key
+// Integers and integral floats share a key (COUNT(*) = 3 equals SUM(x) = 3.0);
+// NULL, text and numbers never collide.
+func (v Value) Key() string {
+	switch v.Kind {
+	case KindNull:
+		return "\x00null"
+	case KindInt:
+		return "n:" + strconv.FormatInt(v.Int, 10)
+	case KindFloat:
+		if v.Float == math.Trunc(v.Float) && math.Abs(v.Float) < 1<<53 {
+			return "n:" + strconv.FormatInt(int64(v.Float), 10)
+		}
+		return "n:" + strconv.FormatFloat(v.Float, 'g', 12, 64)
+	case KindBool:
+		return "b:" + strconv.FormatBool(v.Bool)
+	case KindTime:
+		return "t:" + v.Time.UTC().Format(time.RFC3339Nano)
+	default:
+		return "s:" + v.Text
+	}
+}
+
+// Equal reports whether two values compare equal under Key semantics
+func (v Value) Equal(other Value) bool {
+	return v.Key() == other.Key()
+}
+
+// FormatRows renders up to maxRows typed rows as "col=value" lines for LLM observations
+func FormatRows(columns []string, values [][]Value, maxRows int) string {
+	var sb strings.Builder
+	for i, row := range values {
+		if maxRows > 0 && i >= maxRows {
+			break
+		}
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("{")
+		for j, val := range row {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			if j < len(columns) {
+				sb.WriteString(columns[j])
+				sb.WriteString(": ")
+			}
+			sb.WriteString(val.Literal())
+		}
+		sb.WriteString("}")
+	}
+	return sb.String()
+}
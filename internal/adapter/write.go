@@ -0,0 +1,85 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// WriteResult outcome of a data-changing statement
+type WriteResult struct {
+	RowsAffected  int64
+	Committed     bool  // false = rolled back (dry run)
+	ExecutionTime int64 // Execution time (ms)
+}
+
+// WriteExecutor runs INSERT / UPDATE / DELETE statements inside a transaction
+// Implemented by the SQL adapters; check with a type assertion.
+type WriteExecutor interface {
+	// ExecuteWrite executes stmt in a transaction and commits only if commit is set
+	ExecuteWrite(ctx context.Context, stmt string, commit bool) (*WriteResult, error)
+}
+
+// executeWriteTx runs stmt in a transaction, then rolls back (dry run) or commits
+// Statements that commit implicitly (MySQL DDL) or tables without transactions (MyISAM)
+// cannot be rolled back; callers only pass DML.
+func executeWriteTx(ctx context.Context, db *sql.DB, stmt string, commit bool) (*WriteResult, error) {
+	if db == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	start := time.Now()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, stmt)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if !commit {
+		if err := tx.Rollback(); err != nil {
+			return nil, fmt.Errorf("failed to roll back: %w", err)
+		}
+	} else if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return &WriteResult{
+		RowsAffected:  affected,
+		Committed:     commit,
+		ExecutionTime: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// ExecuteWrite SQLite (CSV and Parquet files: commits only change the in-memory copy)
+func (a *SQLiteAdapter) ExecuteWrite(ctx context.Context, stmt string, commit bool) (*WriteResult, error) {
+	return executeWriteTx(ctx, a.db, stmt, commit)
+}
+
+// ExecuteWrite MySQL
+func (a *MySQLAdapter) ExecuteWrite(ctx context.Context, stmt string, commit bool) (*WriteResult, error) {
+	return executeWriteTx(ctx, a.db, stmt, commit)
+}
+
+// ExecuteWrite PostgreSQL
+func (a *PostgreSQLAdapter) ExecuteWrite(ctx context.Context, stmt string, commit bool) (*WriteResult, error) {
+	return executeWriteTx(ctx, a.db, stmt, commit)
+}
+
+// ExecuteWrite runs in-process on the inner adapter (a single DML statement needs no sandbox)
+func (a *SandboxAdapter) ExecuteWrite(ctx context.Context, stmt string, commit bool) (*WriteResult, error) {
+	writer, ok := a.DBAdapter.(WriteExecutor)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support write statements", a.DBAdapter.GetDatabaseType())
+	}
+	return writer.ExecuteWrite(ctx, stmt, commit)
+}
@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	contextpkg "reactsql/internal/context"
+)
+
+// maxColumnDescription characters kept of a generated column description
+const maxColumnDescription = 120
+
+// generateColumnDescriptions asks the LLM for a short business description of every column
+// without a comment, one call per table, and stores them as column comments. Comments from
+// the DDL or dataset descriptions are never overwritten.
+func (a *WorkerAgent) generateColumnDescriptions(ctx context.Context) error {
+	table, exists := a.sharedCtx.Tables[a.tableName]
+	if !exists {
+		return nil
+	}
+
+	var columns []string
+	for _, col := range table.Columns {
+		if col.Comment != "" {
+			continue
+		}
+		columns = append(columns, fmt.Sprintf("- %s (%s)%s", col.Name, col.Type, columnValueHint(col)))
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	prompt := fmt.Sprintf(`You are a database expert. Describe the business meaning of each column below in a short phrase (max 15 words): what it holds, its unit or encoding if evident.
+
+Table: %s
+Description: %s
+
+Columns:
+%s
+`, a.tableName, table.Description, strings.Join(columns, "\n"))
+
+	if len(table.RichContext) > 0 {
+		prompt += "\nBusiness Insights:\n"
+		for key, value := range table.RichContext {
+			prompt += fmt.Sprintf("- %s: %s\n", key, value.Content)
+		}
+	}
+
+	prompt += `
+Output format: one line per column, "column_name: description", no extra text.`
+
+	response, err := a.llm.Call(ctx, prompt)
+	if err != nil {
+		return err
+	}
+
+	stored := 0
+	for _, line := range strings.Split(response, "\n") {
+		name, description, ok := strings.Cut(strings.TrimLeft(strings.TrimSpace(line), "-* "), ":")
+		if !ok {
+			continue
+		}
+		name = strings.Trim(strings.TrimSpace(name), "`\"'")
+		description = strings.TrimSpace(description)
+		if description == "" {
+			continue
+		}
+		if runes := []rune(description); len(runes) > maxColumnDescription {
+			description = string(runes[:maxColumnDescription])
+		}
+		if err := a.sharedCtx.SetColumnComment(a.tableName, name, description); err == nil {
+			stored++
+		}
+	}
+
+	if !a.sharedCtx.Quiet {
+		fmt.Printf("[%s] Generated %d/%d column descriptions\n", a.id, stored, len(columns))
+	}
+	return nil
+}
+
+// columnValueHint a few observed values, so the description can name units and codes
+func columnValueHint(col contextpkg.ColumnMetadata) string {
+	vs := col.ValueStats
+	if vs == nil {
+		return ""
+	}
+	switch {
+	case len(vs.TopValues) > 0:
+		var values []string
+		for i, tv := range vs.TopValues {
+			if i == 5 {
+				break
+			}
+			values = append(values, tv.Value)
+		}
+		return fmt.Sprintf(" values: %s", strings.Join(values, ", "))
+	case vs.Range != nil:
+		return fmt.Sprintf(" range: %g..%g", vs.Range.Min, vs.Range.Max)
+	case vs.Dates != nil:
+		return fmt.Sprintf(" dates: %s..%s", vs.Dates.Min, vs.Dates.Max)
+	}
+	return ""
+}
+
+// maxColumnSynonyms synonyms kept per column from the LLM
+const maxColumnSynonyms = 4
+
+// generateColumnSynonyms asks the LLM for the words users say for each column ("revenue"
+// for total_amount) and stores them as column synonyms for schema linking
+func (a *WorkerAgent) generateColumnSynonyms(ctx context.Context) error {
+	table, exists := a.sharedCtx.Tables[a.tableName]
+	if !exists || len(table.Columns) == 0 {
+		return nil
+	}
+
+	var columns []string
+	for _, col := range table.Columns {
+		line := fmt.Sprintf("- %s (%s)", col.Name, col.Type)
+		if col.Comment != "" {
+			line += ": " + col.Comment
+		}
+		columns = append(columns, line)
+	}
+
+	prompt := fmt.Sprintf(`You are a database expert. For each column below, list words or short phrases a user might use in a question to refer to it (e.g. total_amount: revenue, sales, order value). Skip ID/key columns and columns with no natural synonym.
+
+Table: %s
+Description: %s
+
+Columns:
+%s
+
+Output format: one line per column, "column_name: synonym1, synonym2" (at most %d synonyms), no extra text.`,
+		a.tableName, table.Description, strings.Join(columns, "\n"), maxColumnSynonyms)
+
+	response, err := a.llm.Call(ctx, prompt)
+	if err != nil {
+		return err
+	}
+
+	stored := 0
+	for _, line := range strings.Split(response, "\n") {
+		name, list, ok := strings.Cut(strings.TrimLeft(strings.TrimSpace(line), "-* "), ":")
+		if !ok {
+			continue
+		}
+		name = strings.Trim(strings.TrimSpace(name), "`\"'")
+		var synonyms []string
+		for _, s := range strings.Split(list, ",") {
+			if s = strings.Trim(strings.TrimSpace(s), "`\"'."); s != "" && len(synonyms) < maxColumnSynonyms {
+				synonyms = append(synonyms, s)
+			}
+		}
+		if added, err := a.sharedCtx.AddColumnSynonyms(a.tableName, name, synonyms); err == nil {
+			stored += added
+		}
+	}
+
+	if !a.sharedCtx.Quiet {
+		fmt.Printf("[%s] Generated %d column synonyms\n", a.id, stored)
+	}
+	return nil
+}
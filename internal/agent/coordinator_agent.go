@@ -2,23 +2,32 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
-	"github.com/tmc/langchaingo/agents"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
 
 	"reactsql/internal/adapter"
 	contextpkg "reactsql/internal/context"
+	"reactsql/internal/inference"
 )
 
+// coordinatorMaxIterations model calls of the coordinator ReAct loop
+const coordinatorMaxIterations = 15
+
 // CoordinatorAgent coordinator agent
 type CoordinatorAgent struct {
 	id        string
 	llm       llms.Model
 	adapter   adapter.DBAdapter
 	sharedCtx *contextpkg.SharedContext
-	executor  *agents.Executor
+	tools     []tools.Tool
+	list      *ListTablesTool
+	timeout   time.Duration  // Deadline of Execute (0 = none)
+	prompts   *PromptProfile // Prompt templates (nil = built-in default)
 }
 
 // NewCoordinatorAgent creates coordinator agent
@@ -37,67 +46,74 @@ func NewCoordinatorAgent(
 	}
 
 	// Create tools
-	sqlTool := &CoordinatorSQLTool{
+	listTool := &ListTablesTool{
 		adapter:   adapter,
 		sharedCtx: sharedCtx,
 		agentID:   id,
 	}
 
-	// Create LangChain executor
-	executor, err := agents.Initialize(
-		llm,
-		[]tools.Tool{sqlTool},
-		agents.ZeroShotReactDescription,
-		agents.WithMaxIterations(15),
-	)
-	if err != nil {
-		return nil, err
+	sqlTool := &CoordinatorSQLTool{
+		adapter:   adapter,
+		sharedCtx: sharedCtx,
+		agentID:   id,
 	}
 
-	agent.executor = executor
+	agent.list = listTool
+	agent.tools = []tools.Tool{listTool, sqlTool}
 	return agent, nil
 }
 
+// SetTableFilter limits the tables that get a worker task (system tables are always skipped
+// unless included explicitly)
+func (a *CoordinatorAgent) SetTableFilter(filter TableFilter) {
+	a.list.filter = filter
+}
+
+// SetTimeout sets the deadline of Execute (0 = none)
+func (a *CoordinatorAgent) SetTimeout(timeout time.Duration) {
+	a.timeout = timeout
+}
+
+// SetPromptProfile sets the prompt templates (nil = built-in default)
+func (a *CoordinatorAgent) SetPromptProfile(profile *PromptProfile) {
+	a.prompts = profile
+}
+
+// SkippedTables tables discovered but left out by the table filter
+func (a *CoordinatorAgent) SkippedTables() []string {
+	return a.list.skipped
+}
+
 // Execute runs coordination task
+// If the timeout expires after tables were discovered, their registered tasks are kept.
 func (a *CoordinatorAgent) Execute(ctx context.Context) error {
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+
 	if !a.sharedCtx.Quiet {
 		fmt.Printf("\n[%s] Starting coordination...\n", a.id)
 	}
 
-	// Select query based on database type
-	var discoverQuery string
-	switch a.adapter.GetDatabaseType() {
-	case "MySQL":
-		discoverQuery = "SHOW TABLES"
-	case "PostgreSQL":
-		discoverQuery = "SELECT tablename FROM pg_tables WHERE schemaname='public'"
-	case "SQLite":
-		discoverQuery = "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'"
-	default:
-		discoverQuery = "SHOW TABLES"
+	prompt, err := promptsOrDefault(a.prompts).render(PromptCoordinator, coordinatorPromptData{
+		Database: a.sharedCtx.DatabaseName,
+		DBType:   a.adapter.GetDatabaseType(),
+		Summary:  a.sharedCtx.GetSummary(),
+	})
+	if err != nil {
+		return fmt.Errorf("coordinator failed: %w", err)
 	}
 
-	prompt := fmt.Sprintf(`You are a Coordinator Agent for database analysis.
-
-Your mission: Analyze database "%s" (%s) and discover ALL tables, then register tasks for workers.
-
-Your workflow:
-1. Execute: %s - to discover all tables
-2. For EACH table found, register a task in the shared context
-3. Report completion when all tasks are registered
-
-IMPORTANT: 
-- Use execute_sql tool to query the database
-- After discovering tables, your job is DONE
-- Worker agents will handle the detailed analysis
-
-Current context:
-%s
-
-Start by discovering tables.`, a.sharedCtx.DatabaseName, a.adapter.GetDatabaseType(), discoverQuery, a.sharedCtx.GetSummary())
-
-	result, err := a.executor.Call(ctx, map[string]any{"input": prompt})
+	result, err := inference.RunReAct(ctx, a.llm, a.tools, coordinatorMaxIterations, prompt)
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && len(a.sharedCtx.GetAllTasks()) > 0 {
+			if !a.sharedCtx.Quiet {
+				fmt.Printf("\n[%s] ⚠️  Timed out after %v, continuing with the %d registered tasks\n", a.id, a.timeout, len(a.sharedCtx.GetAllTasks()))
+			}
+			return nil
+		}
 		return fmt.Errorf("coordinator failed: %w", err)
 	}
 
@@ -107,6 +123,18 @@ Start by discovering tables.`, a.sharedCtx.DatabaseName, a.adapter.GetDatabaseTy
 	return nil
 }
 
+// ExecuteWithoutLLM discovers the tables and registers their tasks directly (no ReAct loop)
+func (a *CoordinatorAgent) ExecuteWithoutLLM(ctx context.Context) error {
+	output, err := a.list.Call(ctx, "all")
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(output, "List Error:") {
+		return fmt.Errorf("coordinator failed: %s", output)
+	}
+	return nil
+}
+
 // CoordinatorSQLTool SQL tool (for coordinator agent)
 type CoordinatorSQLTool struct {
 	adapter   adapter.DBAdapter
@@ -122,10 +150,9 @@ func (t *CoordinatorSQLTool) Description() string {
 	return `Execute SQL queries to discover database structure.
 
 Use this to:
-- Discover tables: SHOW TABLES
-- Get database info: SELECT VERSION(), SELECT DATABASE()
+- Run extra checks, e.g. SELECT COUNT(*) FROM table_name
 
-After discovering tables, register tasks for each table in the shared context.`
+To discover tables use list_tables instead.`
 }
 
 func (t *CoordinatorSQLTool) Call(ctx context.Context, input string) (string, error) {
@@ -158,46 +185,94 @@ func (t *CoordinatorSQLTool) Call(ctx context.Context, input string) (string, er
 		}
 	}
 
-	// If discover tables query, auto-register tasks
-	isDiscoverQuery := contains(input, "SHOW TABLES") ||
-		contains(input, "sqlite_master") ||
-		contains(input, "pg_tables")
-
-	if isDiscoverQuery && result.RowCount > 0 {
-		output += "\n📋 Auto-registering tasks for discovered tables:\n"
-		for _, row := range result.Rows {
-			for _, val := range row {
-				if tableName, ok := val.(string); ok {
-					taskID := "analyze_" + tableName
-					err := t.sharedCtx.RegisterTask(
-						taskID,
-						"worker_"+tableName,
-						fmt.Sprintf("Analyze table: %s", tableName),
-					)
-					if err == nil {
-						output += fmt.Sprintf("  ✓ Registered: %s\n", taskID)
-					}
-				}
-			}
-		}
-	}
-
 	output += "\n" + t.sharedCtx.GetSummary()
 
 	return output, nil
 }
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) &&
-		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
-			findSubstring(s, substr)))
+// ListTablesTool table discovery tool (dialect-independent, via adapter)
+type ListTablesTool struct {
+	adapter   adapter.DBAdapter
+	sharedCtx *contextpkg.SharedContext
+	agentID   string
+	filter    TableFilter
+	skipped   []string // Left out by filter (last call)
+}
+
+func (t *ListTablesTool) Name() string {
+	return "list_tables"
 }
 
-func findSubstring(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
+func (t *ListTablesTool) Description() string {
+	return `List all user tables and views in the database and auto-register an analysis task for each.
+Views are analyzed like tables but marked read-only. System, backup and filtered-out tables are skipped.
+
+Input: ignored (use "all")`
+}
+
+func (t *ListTablesTool) Call(ctx context.Context, input string) (string, error) {
+	if !t.sharedCtx.Quiet {
+		fmt.Printf("\n[%s] List tables\n", t.agentID)
+	}
+
+	all, err := t.adapter.GetTables(ctx)
+	if err != nil {
+		return fmt.Sprintf("List Error: %v", err), nil
+	}
+	t.skipped = nil
+	var skipNotes []string
+	var tables []string
+	for _, name := range all {
+		if skip, reason := t.filter.Skip(name); skip {
+			t.skipped = append(t.skipped, name)
+			skipNotes = append(skipNotes, fmt.Sprintf("  - %s (%s)\n", name, reason))
+			continue
+		}
+		tables = append(tables, name)
+	}
+
+	// Views are analyzed like tables; the marker reaches BuildTableMetadata via temp data
+	views, err := t.adapter.GetViews(ctx)
+	if err != nil {
+		return fmt.Sprintf("List Error: %v", err), nil
+	}
+	viewCount := 0
+	for _, view := range views {
+		if skip, reason := t.filter.Skip(view.Name); skip {
+			t.skipped = append(t.skipped, view.Name)
+			skipNotes = append(skipNotes, fmt.Sprintf("  - %s (view, %s)\n", view.Name, reason))
+			continue
 		}
+		t.sharedCtx.SetData(view.Name+"_view", view)
+		tables = append(tables, view.Name)
+		viewCount++
 	}
-	return false
+
+	output := fmt.Sprintf("Found %d tables\n", len(tables)-viewCount)
+	if viewCount > 0 {
+		output += fmt.Sprintf("Found %d views (read-only)\n", viewCount)
+	}
+	if len(skipNotes) > 0 {
+		output += fmt.Sprintf("Skipped %d tables (no task needed):\n%s", len(skipNotes), strings.Join(skipNotes, ""))
+	}
+
+	// Auto-register tasks for discovered tables
+	if len(tables) > 0 {
+		output += "\n📋 Auto-registering tasks for discovered tables:\n"
+		for _, tableName := range tables {
+			taskID := "analyze_" + tableName
+			err := t.sharedCtx.RegisterTask(
+				taskID,
+				"worker_"+tableName,
+				fmt.Sprintf("Analyze table: %s", tableName),
+			)
+			if err == nil {
+				output += fmt.Sprintf("  ✓ Registered: %s\n", taskID)
+			}
+		}
+	}
+
+	output += "\n" + t.sharedCtx.GetSummary()
+
+	return output, nil
 }
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+
+	contextpkg "reactsql/internal/context"
+)
+
+const (
+	// maxSummaryTables tables listed in the summary prompt (largest first)
+	maxSummaryTables = 60
+	// maxSummaryColumns described columns listed per table
+	maxSummaryColumns = 8
+	// maxSummaryCommentLen characters kept of a column description
+	maxSummaryCommentLen = 80
+)
+
+// GenerateDatabaseSummary asks the LLM for a 3-5 sentence overview of the whole database
+// (domain, key entities, main relationships) from the finished table descriptions, keys and
+// column descriptions, and stores it as SharedContext.Summary. Run it after the workers,
+// AnalyzeJoinPaths and LoadBirdDescriptions.
+func GenerateDatabaseSummary(ctx context.Context, llm llms.Model, sharedCtx *contextpkg.SharedContext) error {
+	tables := make([]*contextpkg.TableMetadata, 0, len(sharedCtx.Tables))
+	for _, table := range sharedCtx.Tables {
+		tables = append(tables, table)
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].RowCount != tables[j].RowCount {
+			return tables[i].RowCount > tables[j].RowCount
+		}
+		return tables[i].Name < tables[j].Name
+	})
+
+	var listing strings.Builder
+	for i, table := range tables {
+		if i == maxSummaryTables {
+			listing.WriteString(fmt.Sprintf("... and %d more tables\n", len(tables)-maxSummaryTables))
+			break
+		}
+		listing.WriteString(fmt.Sprintf("- %s (%d rows)", table.Name, table.RowCount))
+		if table.Description != "" {
+			listing.WriteString(": " + table.Description)
+		}
+		listing.WriteString("\n")
+		for _, fk := range table.ForeignKeys {
+			listing.WriteString(fmt.Sprintf("    %s → %s.%s\n", fk.ColumnName, fk.ReferencedTable, fk.ReferencedColumn))
+		}
+		var described []string
+		for _, col := range table.Columns {
+			if col.Comment == "" {
+				continue
+			}
+			if len(described) == maxSummaryColumns {
+				described = append(described, "...")
+				break
+			}
+			comment := col.Comment
+			if runes := []rune(comment); len(runes) > maxSummaryCommentLen {
+				comment = string(runes[:maxSummaryCommentLen]) + "..."
+			}
+			described = append(described, fmt.Sprintf("%s (%s)", col.Name, comment))
+		}
+		if len(described) > 0 {
+			listing.WriteString("    columns: " + strings.Join(described, ", ") + "\n")
+		}
+	}
+
+	prompt := fmt.Sprintf(`You are a database expert. Write a short overview of the database below for someone who must answer questions about it without knowing its domain.
+
+Database: %s
+
+Tables (with foreign keys and described columns):
+%s
+Task: In 3 to 5 sentences, state the business domain, the key entities and how the main tables relate to each other.
+Output format: Just the paragraph, no headings or lists.
+
+Overview:`, sharedCtx.DatabaseName, listing.String())
+
+	response, err := llm.Call(ctx, prompt)
+	if err != nil {
+		return err
+	}
+
+	summary := strings.Join(strings.Fields(response), " ")
+	summary = strings.TrimSpace(strings.TrimPrefix(summary, "Overview:"))
+	if summary == "" {
+		return fmt.Errorf("empty database summary")
+	}
+	sharedCtx.SetSummary(summary)
+
+	if !sharedCtx.Quiet {
+		fmt.Printf("[Context] Generated database summary: %s\n", summary)
+	}
+	return nil
+}
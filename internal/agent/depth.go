@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	contextpkg "reactsql/internal/context"
+)
+
+// AnalysisDepth how thoroughly a worker analyzes its table, trading cost for context richness
+type AnalysisDepth string
+
+const (
+	// DepthFast per-column checks on a few key and leading columns, no orphan or join
+	// quality checks and no LLM column descriptions or synonyms
+	DepthFast AnalysisDepth = "fast"
+	// DepthStandard every check and every LLM phase (default)
+	DepthStandard AnalysisDepth = "standard"
+	// DepthDeep standard, with the scan-heavy checks always on the whole table and a longer
+	// Phase 2 exploration
+	DepthDeep AnalysisDepth = "deep"
+)
+
+const (
+	// fastCheckedColumns columns that get the per-column checks at DepthFast
+	fastCheckedColumns = 10
+	// deepMaxIterations model calls per worker ReAct phase at DepthDeep
+	deepMaxIterations = 40
+)
+
+// ParseAnalysisDepth parses fast | standard | deep ("" = standard)
+func ParseAnalysisDepth(s string) (AnalysisDepth, error) {
+	switch depth := AnalysisDepth(strings.ToLower(strings.TrimSpace(s))); depth {
+	case "":
+		return DepthStandard, nil
+	case DepthFast, DepthStandard, DepthDeep:
+		return depth, nil
+	default:
+		return "", fmt.Errorf("invalid depth %q (fast | standard | deep)", s)
+	}
+}
+
+// QualityThresholds the thresholds with the depth's check limits applied (nil = defaults)
+// Fast caps the per-column checks and skips orphan checks; deep turns sampling off.
+func (d AnalysisDepth) QualityThresholds(base *contextpkg.QualityThresholds) *contextpkg.QualityThresholds {
+	if d != DepthFast && d != DepthDeep {
+		return base
+	}
+	thresholds := contextpkg.QualityThresholds{}
+	if base != nil {
+		thresholds = *base
+	}
+	switch d {
+	case DepthFast:
+		if thresholds.MaxCheckedColumns == 0 || thresholds.MaxCheckedColumns > fastCheckedColumns {
+			thresholds.MaxCheckedColumns = fastCheckedColumns
+		}
+		thresholds.SkipOrphanChecks = true
+	case DepthDeep:
+		thresholds.SampleAboveRows = 0
+	}
+	return &thresholds
+}
+
+// describesColumns reports whether the worker generates column descriptions and synonyms
+func (d AnalysisDepth) describesColumns() bool {
+	return d != DepthFast
+}
+
+// maxIterations model calls per worker ReAct phase
+func (d AnalysisDepth) maxIterations() int {
+	if d == DepthDeep {
+		return deepMaxIterations
+	}
+	return workerMaxIterations
+}
@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Prompt template names (<name>.tmpl in a profile)
+const (
+	PromptCoordinator         = "coordinator"           // Data: Database, DBType, Summary
+	PromptWorkerExplore       = "worker_explore"        // Data: Table, DBType, Samples, Glossary
+	PromptWorkerExploreStrict = "worker_explore_strict" // Data: Table, DBType (retry after a failed Phase 2)
+)
+
+// DefaultPromptProfile built-in profile used when none is set
+const DefaultPromptProfile = "semantics"
+
+// promptNames every template a profile provides
+var promptNames = []string{PromptCoordinator, PromptWorkerExplore, PromptWorkerExploreStrict}
+
+//go:embed prompts/*/*.tmpl
+var builtinPrompts embed.FS
+
+// coordinatorPromptData fields of the coordinator template
+type coordinatorPromptData struct {
+	Database string
+	DBType   string
+	Summary  string // Shared context summary
+}
+
+// workerPromptData fields of the worker templates
+type workerPromptData struct {
+	Table    string
+	DBType   string
+	Samples  string // Masked sample rows block ("" when sampling failed)
+	Glossary string // Business glossary block ("" without a glossary)
+}
+
+// PromptProfile agent prompt templates
+type PromptProfile struct {
+	Name      string
+	templates map[string]*template.Template
+}
+
+// BuiltinPromptProfiles names of the profiles shipped with the binary (sorted)
+func BuiltinPromptProfiles() []string {
+	entries, _ := fs.ReadDir(builtinPrompts, "prompts")
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadPromptProfile loads a built-in profile by name, or a directory of <name>.tmpl files
+// Templates a profile does not provide fall back to the default profile, so a directory
+// may override only the worker prompt. Every template is rendered once with empty data,
+// so a misspelled field fails here rather than in the middle of a run.
+func LoadPromptProfile(nameOrDir string) (*PromptProfile, error) {
+	if nameOrDir == "" {
+		nameOrDir = DefaultPromptProfile
+	}
+
+	var source fs.FS
+	name := nameOrDir
+	if _, err := fs.Stat(builtinPrompts, "prompts/"+nameOrDir); err == nil && !strings.ContainsAny(nameOrDir, `/\.`) {
+		source, _ = fs.Sub(builtinPrompts, "prompts/"+nameOrDir)
+	} else if info, err := os.Stat(nameOrDir); err == nil && info.IsDir() {
+		source = os.DirFS(nameOrDir)
+		name = filepath.Base(filepath.Clean(nameOrDir))
+	} else {
+		return nil, fmt.Errorf("unknown prompt profile %q (built-in: %s, or a directory of *.tmpl files)",
+			nameOrDir, strings.Join(BuiltinPromptProfiles(), ", "))
+	}
+
+	profile := &PromptProfile{Name: name, templates: make(map[string]*template.Template)}
+	provided := 0
+	for _, prompt := range promptNames {
+		text, err := fs.ReadFile(source, prompt+".tmpl")
+		if err == nil {
+			provided++
+		} else {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("prompt profile %s: %w", name, err)
+			}
+			if text, err = fs.ReadFile(builtinPrompts, "prompts/"+DefaultPromptProfile+"/"+prompt+".tmpl"); err != nil {
+				return nil, err
+			}
+		}
+		tmpl, err := template.New(prompt).Parse(strings.TrimSuffix(string(text), "\n"))
+		if err != nil {
+			return nil, fmt.Errorf("prompt profile %s: %w", name, err)
+		}
+		profile.templates[prompt] = tmpl
+	}
+	if provided == 0 {
+		return nil, fmt.Errorf("prompt profile %s: no %s.tmpl files", name, strings.Join(promptNames, ".tmpl, "))
+	}
+
+	for prompt, data := range map[string]interface{}{
+		PromptCoordinator:         coordinatorPromptData{},
+		PromptWorkerExplore:       workerPromptData{},
+		PromptWorkerExploreStrict: workerPromptData{},
+	} {
+		if _, err := profile.render(prompt, data); err != nil {
+			return nil, fmt.Errorf("prompt profile %s: %w", name, err)
+		}
+	}
+	return profile, nil
+}
+
+// defaultPrompts the built-in default profile, for agents without a profile set
+var defaultPrompts = func() *PromptProfile {
+	profile, err := LoadPromptProfile(DefaultPromptProfile)
+	if err != nil {
+		panic(err)
+	}
+	return profile
+}()
+
+// render executes one template of the profile
+func (p *PromptProfile) render(prompt string, data interface{}) (string, error) {
+	tmpl, ok := p.templates[prompt]
+	if !ok {
+		return "", fmt.Errorf("prompt profile %s has no %s template", p.Name, prompt)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// promptsOrDefault the profile, or the built-in default when nil
+func promptsOrDefault(p *PromptProfile) *PromptProfile {
+	if p == nil {
+		return defaultPrompts
+	}
+	return p
+}
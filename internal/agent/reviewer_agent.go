@@ -0,0 +1,237 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"reactsql/internal/adapter"
+	contextpkg "reactsql/internal/context"
+	"reactsql/internal/inference"
+)
+
+// reviewSampleRows fresh rows the reviewer checks the notes against
+const reviewSampleRows = 10
+
+// ReviewerAgent second pass over a completed table's notes
+// One LLM call reads the generated notes next to the table's columns, deterministic quality
+// issues and freshly sampled rows, and answers which notes to keep, rewrite or delete:
+// hallucinated issues the data contradicts and duplicates go, vague wording is tightened.
+// Hand-written and proofread notes are never touched.
+type ReviewerAgent struct {
+	id        string
+	tableName string
+	llm       llms.Model
+	adapter   adapter.DBAdapter
+	sharedCtx *contextpkg.SharedContext
+}
+
+// ReviewResult notes the reviewer changed
+type ReviewResult struct {
+	Edited  []string                  // Keys of rewritten notes
+	Deleted []contextpkg.RejectedNote // Deleted notes with the reviewer's reason
+}
+
+// reviewDecision one entry of the reviewer's answer
+type reviewDecision struct {
+	Key     string
+	Action  string // keep | edit | delete
+	Content string // Rewritten note (edit)
+	Reason  string
+}
+
+// NewReviewerAgent creates reviewer agent
+func NewReviewerAgent(
+	id string,
+	tableName string,
+	llm llms.Model,
+	adapter adapter.DBAdapter,
+	sharedCtx *contextpkg.SharedContext,
+) (*ReviewerAgent, error) {
+	return &ReviewerAgent{
+		id:        id,
+		tableName: tableName,
+		llm:       llm,
+		adapter:   adapter,
+		sharedCtx: sharedCtx,
+	}, nil
+}
+
+// Execute reviews the table's generated notes and applies the decisions
+// Tables without generated notes are skipped without calling the model. Rewritten notes
+// go through the same column and SQL checks as worker notes.
+func (a *ReviewerAgent) Execute(ctx context.Context) (*ReviewResult, error) {
+	result := &ReviewResult{}
+	table, exists := a.sharedCtx.TableSnapshot(a.tableName)
+	if !exists {
+		return result, nil
+	}
+	notes := make(map[string]contextpkg.BusinessNote)
+	for key, note := range table.RichContext {
+		if note.Provenance != nil && note.Provenance.Source == "worker" {
+			notes[key] = note.BusinessNote
+		}
+	}
+	if len(notes) == 0 {
+		return result, nil
+	}
+
+	if !a.sharedCtx.Quiet {
+		fmt.Printf("\n[%s] Reviewing %d notes of '%s'...\n", a.id, len(notes), a.tableName)
+	}
+	response, err := a.llm.Call(ctx, a.prompt(ctx, table, notes))
+	if err != nil {
+		return result, err
+	}
+
+	for _, decision := range parseReviewDecisions(response) {
+		note, ok := notes[decision.Key]
+		if !ok {
+			continue // Unknown key, or not a generated note
+		}
+		switch decision.Action {
+		case "delete":
+			if a.sharedCtx.RemoveTableRichContext(a.tableName, decision.Key) {
+				result.Deleted = append(result.Deleted, contextpkg.RejectedNote{Key: decision.Key, Reason: decision.Reason})
+			}
+		case "edit":
+			if decision.Content == "" || decision.Content == note.Content {
+				continue
+			}
+			note.Provenance = &contextpkg.NoteProvenance{
+				Source:    "review",
+				Agent:     a.id,
+				Model:     a.sharedCtx.ModelName,
+				Reason:    decision.Reason,
+				Previous:  note.Content,
+				UpdatedAt: time.Now().Format(time.RFC3339),
+			}
+			note.Content = decision.Content
+			if _, err := a.sharedCtx.CorrectTableRichContext(a.tableName, decision.Key, note); err != nil {
+				continue
+			}
+			result.Edited = append(result.Edited, decision.Key)
+		}
+	}
+
+	// A rewrite may name a column or carry SQL the worker's note did not
+	dialect := a.adapter.GetDatabaseType()
+	dropped := make(map[string]bool)
+	for _, rejected := range a.sharedCtx.CheckWorkerNotes(a.tableName, func(sql string) error {
+		return inference.ParseSQL(sql, dialect)
+	}) {
+		result.Deleted = append(result.Deleted, rejected)
+		dropped[rejected.Key] = true
+	}
+	edited := result.Edited[:0]
+	for _, key := range result.Edited {
+		if !dropped[key] {
+			edited = append(edited, key)
+		}
+	}
+	result.Edited = edited
+
+	sort.Strings(result.Edited)
+	if !a.sharedCtx.Quiet {
+		fmt.Printf("[%s] Review of '%s': %d edited, %d deleted\n", a.id, a.tableName, len(result.Edited), len(result.Deleted))
+	}
+	return result, nil
+}
+
+// prompt the review request: schema, deterministic issues, fresh sample rows and the notes
+func (a *ReviewerAgent) prompt(ctx context.Context, table *contextpkg.TableMetadata, notes map[string]contextpkg.BusinessNote) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`You are reviewing business notes another agent wrote about table "%s" (%s database, %d rows).
+The notes are shown to a text-to-SQL model, so a wrong note causes wrong SQL.
+
+Columns:
+`, a.tableName, a.adapter.GetDatabaseType(), table.RowCount))
+	for _, col := range table.Columns {
+		sb.WriteString(fmt.Sprintf("- %s %s\n", col.Name, col.Type))
+	}
+
+	if len(table.QualityIssues) > 0 {
+		sb.WriteString("\nData quality issues measured over the whole table (ground truth):\n")
+		for _, issue := range table.QualityIssues {
+			sb.WriteString(fmt.Sprintf("- [%s] %s: %s\n", issue.Severity, issue.Column, issue.Description))
+		}
+	}
+
+	if rows, err := a.adapter.SampleRows(ctx, a.tableName, reviewSampleRows, adapter.DefaultMaskingRules()); err == nil && rows.RowCount > 0 {
+		data, _ := json.MarshalIndent(rows.Rows, "", "  ")
+		sb.WriteString(fmt.Sprintf("\nSample rows (masked):\n%s\n", data))
+	}
+
+	keys := make([]string, 0, len(notes))
+	for key := range notes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sb.WriteString("\nNotes:\n")
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", key, notes[key].Content))
+	}
+
+	sb.WriteString(`
+Review every note:
+- delete: a data quality claim the issues or sample rows contradict, a note with no information, or a duplicate of another note (keep the better one)
+- edit: a correct note that is vague, wordy or mixes in reasoning text; rewrite it in one or two sentences, keeping every value, code and percentage, never inventing new ones
+- keep: anything else
+
+Answer with JSON only:
+{"reviews": [{"key": "<note key>", "action": "keep|edit|delete", "content": "<rewritten note, edit only>", "reason": "<short reason>"}]}`)
+	return sb.String()
+}
+
+// parseReviewDecisions the decisions of a reviewer answer (tolerant: prose, code fences,
+// a bare array of decision objects, any case)
+func parseReviewDecisions(response string) []reviewDecision {
+	objects := inference.LooseJSONObjects(response)
+	for _, raw := range objects {
+		items, ok := raw["reviews"].([]interface{})
+		if !ok {
+			continue
+		}
+		var decisions []reviewDecision
+		for _, item := range items {
+			if fields, ok := item.(map[string]interface{}); ok {
+				if decision := decodeReviewDecision(fields); decision.Key != "" {
+					decisions = append(decisions, decision)
+				}
+			}
+		}
+		return decisions
+	}
+
+	var decisions []reviewDecision
+	for _, raw := range objects {
+		if decision := decodeReviewDecision(raw); decision.Key != "" {
+			decisions = append(decisions, decision)
+		}
+	}
+	return decisions
+}
+
+// decodeReviewDecision reads the members by case-insensitive name
+func decodeReviewDecision(fields map[string]interface{}) reviewDecision {
+	decision := reviewDecision{}
+	for name, value := range fields {
+		s, _ := value.(string)
+		switch strings.ToLower(name) {
+		case "key", "note_key":
+			decision.Key = strings.TrimSpace(s)
+		case "action", "decision":
+			decision.Action = strings.ToLower(strings.TrimSpace(s))
+		case "content", "new_content":
+			decision.Content = stripReActText(s)
+		case "reason":
+			decision.Reason = strings.TrimSpace(s)
+		}
+	}
+	return decision
+}
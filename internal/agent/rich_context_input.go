@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	contextpkg "reactsql/internal/context"
+	"reactsql/internal/inference"
+)
+
+// richContextInput arguments of set_rich_context
+type richContextInput struct {
+	Key             string
+	Content         string
+	Severity        string // critical/warning/info ("" = not given or unknown)
+	ExpiresAt       string // As given ("" = default lifetime)
+	AffectedColumns []string
+}
+
+// parseRichContextInput tolerant decoder of set_rich_context input
+// The JSON object may be fenced or surrounded by text, name its members in any case, use
+// aliases (value/note for content, columns for affected_columns) and loose types
+// (affected_columns as "a, b"). Input without a usable object is read as the legacy
+// key|content form. ReAct text the model ran on into (Thought:/Action:) is cut off.
+func parseRichContextInput(input string) (*richContextInput, error) {
+	for _, raw := range inference.LooseJSONObjects(input) {
+		if in := decodeRichContextInput(raw); in.Key != "" && in.Content != "" {
+			return in, nil
+		}
+	}
+
+	parts := strings.SplitN(input, "|", 2)
+	if len(parts) != 2 || strings.Contains(parts[0], "{") {
+		return nil, fmt.Errorf(`invalid input, expected a JSON object: {"key": "...", "content": "..."}`)
+	}
+	in := &richContextInput{
+		Key:     strings.TrimSpace(parts[0]),
+		Content: stripReActText(parts[1]),
+	}
+	if in.Key == "" || in.Content == "" {
+		return nil, fmt.Errorf("key and content cannot be empty")
+	}
+	return in, nil
+}
+
+// decodeRichContextInput reads the members by case-insensitive name, coercing loose types
+func decodeRichContextInput(raw map[string]interface{}) *richContextInput {
+	in := &richContextInput{}
+	for name, value := range raw {
+		s, _ := value.(string)
+		switch strings.ToLower(name) {
+		case "key", "note_key", "name":
+			in.Key = strings.TrimSpace(s)
+		case "content", "value", "note", "insight":
+			in.Content = stripReActText(s)
+		case "severity", "level":
+			in.Severity = noteSeverity(s)
+		case "expires_at", "expires", "expiry":
+			in.ExpiresAt = strings.TrimSpace(s)
+		case "affected_columns", "columns", "column":
+			in.AffectedColumns = stringList(value)
+		}
+	}
+	return in
+}
+
+// stripReActText cuts a Thought:/Action: continuation off a note and trims it
+func stripReActText(content string) string {
+	for _, marker := range []string{"\n\nThought:", "\n\nAction:", "\nThought:", "\nAction:"} {
+		if idx := strings.Index(content, marker); idx > 0 {
+			content = content[:idx]
+		}
+	}
+	return strings.TrimSpace(content)
+}
+
+// noteSeverity maps the model's wording to critical/warning/info ("" when unknown)
+func noteSeverity(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "critical", "high", "error", "severe":
+		return "critical"
+	case "warning", "warn", "medium", "moderate":
+		return "warning"
+	case "info", "low", "note", "informational":
+		return "info"
+	}
+	return ""
+}
+
+// stringList a JSON array of strings or a comma-separated string, trimmed, empty items dropped
+func stringList(value interface{}) []string {
+	var items []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+	case string:
+		items = strings.Split(v, ",")
+	}
+	var list []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// noteExpiry the ExpiresAt of a worker note: the requested time (RFC3339 or YYYY-MM-DD)
+// when it falls within the default lifetime of generated notes, else the default. Workers
+// may expire a volatile insight early but never keep an unverified one longer.
+func noteExpiry(requested string, now time.Time) (string, bool) {
+	limit := now.Add(contextpkg.GeneratedNoteTTL)
+	if requested == "" {
+		return limit.Format(time.RFC3339), true
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, requested); err == nil && t.After(now) && !t.After(limit) {
+			return t.Format(time.RFC3339), true
+		}
+	}
+	return limit.Format(time.RFC3339), false
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SystemTablePatterns tables skipped unless an include pattern names them:
+// SQLite internals (sqlite_sequence, sqlite_stat1), PostgreSQL catalogs and backup copies
+var SystemTablePatterns = []string{"sqlite_*", "pg_*", "backup_*"}
+
+// TableFilter decides which discovered tables get a worker
+// Patterns are shell globs (*, ?, [...]) matched case-insensitively against the table name.
+type TableFilter struct {
+	Include []string // If set, only matching tables are analyzed
+	Exclude []string // Matching tables are skipped
+}
+
+// Validate reports the first malformed pattern
+func (f TableFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+			return fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Skip reports whether a table is left out, with the reason
+// System tables are skipped unless an include pattern names them explicitly.
+func (f TableFilter) Skip(table string) (bool, string) {
+	included := matchTablePattern(f.Include, table)
+	if len(f.Include) > 0 && !included {
+		return true, "not included"
+	}
+	if matchTablePattern(f.Exclude, table) {
+		return true, "excluded"
+	}
+	if !included && matchTablePattern(SystemTablePatterns, table) {
+		return true, "system table"
+	}
+	return false, ""
+}
+
+// matchTablePattern reports whether any pattern matches the table name
+func matchTablePattern(patterns []string, table string) bool {
+	name := strings.ToLower(table)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTablePatterns splits a comma-separated pattern list (e.g. a CLI flag)
+func ParseTablePatterns(list string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(list, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
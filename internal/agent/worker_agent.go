@@ -3,18 +3,23 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
-	"github.com/tmc/langchaingo/agents"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
 
 	"reactsql/internal/adapter"
 	contextpkg "reactsql/internal/context"
+	"reactsql/internal/inference"
+	"reactsql/internal/llm"
 )
 
+// workerMaxIterations model calls per worker ReAct phase (room for complex table analysis)
+const workerMaxIterations = 25
+
 // WorkerAgent worker agent
 type WorkerAgent struct {
 	id        string
@@ -23,7 +28,18 @@ type WorkerAgent struct {
 	llm       llms.Model
 	adapter   adapter.DBAdapter
 	sharedCtx *contextpkg.SharedContext
-	executor  *agents.Executor
+	tools     []tools.Tool
+	queries   *workerQueryLog // Shared by the SQL and note tools
+	steps     int             // Tool calls of the ReAct phases
+
+	budgetExceeded bool                      // An llm.BudgetModel cap was hit; the table keeps deterministic stats only
+	retried        []string                  // Phases rerun with the strict prompt
+	rejected       []contextpkg.RejectedNote // Notes dropped by the post-worker check
+
+	timeout  time.Duration  // Deadline of Execute (0 = none)
+	timedOut bool           // The deadline expired; the table keeps what was collected before it
+	prompts  *PromptProfile // Phase 2 prompt templates (nil = built-in default)
+	depth    AnalysisDepth  // Which LLM phases run and how long Phase 2 explores ("" = standard)
 }
 
 // NewWorkerAgent creates worker agent
@@ -46,36 +62,63 @@ func NewWorkerAgent(
 	}
 
 	// Create tools
+	queries := &workerQueryLog{}
 	sqlTool := &WorkerSQLTool{
 		adapter:   adapter,
 		sharedCtx: sharedCtx,
 		agentID:   id,
 		tableName: tableName,
+		queries:   queries,
 	}
 
-	richContextTool := &SetRichContextTool{
+	describeTool := &DescribeTableTool{
+		adapter:   adapter,
 		sharedCtx: sharedCtx,
 		agentID:   id,
 		tableName: tableName,
 	}
 
-	// Create LangChain executor
-	executor, err := agents.Initialize(
-		llm,
-		[]tools.Tool{sqlTool, richContextTool},
-		agents.ZeroShotReactDescription,
-		agents.WithMaxIterations(25), // Increase iterations for complex table analysis
-	)
-	if err != nil {
-		return nil, err
+	richContextTool := &SetRichContextTool{
+		sharedCtx: sharedCtx,
+		agentID:   id,
+		tableName: tableName,
+		queries:   queries,
 	}
 
-	agent.executor = executor
+	agent.queries = queries
+	agent.tools = countSteps(&agent.steps, sqlTool, describeTool, richContextTool)
 	return agent, nil
 }
 
+// SetTimeout sets the deadline of Execute (0 = none)
+func (a *WorkerAgent) SetTimeout(timeout time.Duration) {
+	a.timeout = timeout
+}
+
+// SetPromptProfile sets the Phase 2 prompt templates (nil = built-in default)
+func (a *WorkerAgent) SetPromptProfile(profile *PromptProfile) {
+	a.prompts = profile
+}
+
+// SetDepth sets the analysis depth of the LLM phases; the quality check limits of a depth
+// come from SharedContext.QualityThresholds (see AnalysisDepth.QualityThresholds)
+func (a *WorkerAgent) SetDepth(depth AnalysisDepth) {
+	a.depth = depth
+}
+
 // Execute runs analysis task (multi-phase)
-func (a *WorkerAgent) Execute(ctx context.Context) error {
+// When the worker's model is an llm.BudgetModel and its cap is hit, the LLM phases stop
+// and the table is completed with its metadata and deterministic stats (BudgetExceeded).
+// When the timeout expires, the table is completed with whatever was collected (TimedOut).
+// Steps, queries, tokens and duration are saved with the table (TableMetadata.Generation).
+func (a *WorkerAgent) Execute(ctx context.Context) (err error) {
+	defer func(start time.Time) { a.recordTelemetry(start, err) }(time.Now())
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+
 	if !a.sharedCtx.Quiet {
 		fmt.Printf("\n[%s] Starting analysis of table '%s'...\n", a.id, a.tableName)
 	}
@@ -85,25 +128,25 @@ func (a *WorkerAgent) Execute(ctx context.Context) error {
 		return err
 	}
 
-	// ========== Phase 1: Collect basic metadata ==========
+	// ========== Phase 1: Collect basic metadata (introspection, no LLM) ==========
 	if !a.sharedCtx.Quiet {
 		fmt.Printf("\n[%s] Phase 1: Collecting basic metadata...\n", a.id)
 	}
 	if err := a.collectBasicMetadata(ctx); err != nil {
+		if a.deadlineHit(ctx) {
+			// Salvage the describe / count results that came back before the deadline
+			a.sharedCtx.BuildTableMetadata(a.tableName)
+			return a.complete()
+		}
 		a.sharedCtx.FailTask(a.taskID, err)
 		return fmt.Errorf("phase 1 failed: %w", err)
 	}
 
 	// ========== Phase 1.5: Deterministic quality checks & value stats ==========
-	if !a.sharedCtx.Quiet {
-		fmt.Printf("\n[%s] Phase 1.5: Running quality checks...\n", a.id)
-	}
-	qc := contextpkg.NewQualityChecker(a.adapter, a.sharedCtx, a.tableName)
-	if err := qc.RunAll(ctx); err != nil {
-		if !a.sharedCtx.Quiet {
-			fmt.Printf("[%s] Warning: quality check failed: %v\n", a.id, err)
-		}
-		// Non-fatal: continue to Phase 2
+	a.collectStats(ctx)
+
+	if a.budgetExceeded || a.deadlineHit(ctx) {
+		return a.complete()
 	}
 
 	// ========== Phase 2: ReAct explore Rich Context ==========
@@ -111,7 +154,12 @@ func (a *WorkerAgent) Execute(ctx context.Context) error {
 		fmt.Printf("\n[%s] Phase 2: Exploring rich context...\n", a.id)
 	}
 	if err := a.exploreRichContext(ctx); err != nil {
-		return err
+		if !a.overBudget(err) && !a.deadlineHit(ctx) {
+			a.sharedCtx.FailTask(a.taskID, err)
+			return err
+		}
+		// Notes saved before the cap / deadline are kept
+		return a.complete()
 	}
 
 	// Phase 3: Generate table description (from collected info)
@@ -119,15 +167,99 @@ func (a *WorkerAgent) Execute(ctx context.Context) error {
 		fmt.Printf("\n[%s] Phase 3: Generating table description...\n", a.id)
 	}
 	if err := a.generateTableDescription(ctx); err != nil {
+		if a.overBudget(err) || a.deadlineHit(ctx) {
+			return a.complete()
+		}
 	if !a.sharedCtx.Quiet {
 		fmt.Printf("[%s] Warning: Failed to generate description: %v\n", a.id, err)
 	}
 		// Do not interrupt flow, description gen failure is non-fatal
 	}
 
-	// completes task
+	// Phase 3.5/3.6 are skipped at DepthFast
+	if !a.depth.describesColumns() {
+		return a.complete()
+	}
+
+	// Phase 3.5: Generate column descriptions (columns without a DDL comment)
+	if !a.sharedCtx.Quiet {
+		fmt.Printf("\n[%s] Phase 3.5: Generating column descriptions...\n", a.id)
+	}
+	if err := a.generateColumnDescriptions(ctx); err != nil {
+		if a.overBudget(err) || a.deadlineHit(ctx) {
+			return a.complete()
+		}
+		if !a.sharedCtx.Quiet {
+			fmt.Printf("[%s] Warning: Failed to generate column descriptions: %v\n", a.id, err)
+		}
+	}
+
+	// Phase 3.6: Generate column synonyms (for schema linking)
+	if !a.sharedCtx.Quiet {
+		fmt.Printf("\n[%s] Phase 3.6: Generating column synonyms...\n", a.id)
+	}
+	if err := a.generateColumnSynonyms(ctx); err != nil {
+		if a.overBudget(err) || a.deadlineHit(ctx) {
+			return a.complete()
+		}
+		if !a.sharedCtx.Quiet {
+			fmt.Printf("[%s] Warning: Failed to generate column synonyms: %v\n", a.id, err)
+		}
+	}
+
+	return a.complete()
+}
+
+// ExecuteWithoutLLM runs only the deterministic phases: introspected metadata, quality
+// checks, value statistics and sample rows (the worker's model is never called, may be nil)
+func (a *WorkerAgent) ExecuteWithoutLLM(ctx context.Context) (err error) {
+	defer func(start time.Time) { a.recordTelemetry(start, err) }(time.Now())
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+
+	if err := a.sharedCtx.StartTask(a.taskID); err != nil {
+		return err
+	}
+	if err := a.collectBasicMetadata(ctx); err != nil {
+		if a.deadlineHit(ctx) {
+			a.sharedCtx.BuildTableMetadata(a.tableName)
+			return a.complete()
+		}
+		a.sharedCtx.FailTask(a.taskID, err)
+		return fmt.Errorf("phase 1 failed: %w", err)
+	}
+	a.collectStats(ctx)
+	return a.complete()
+}
+
+// collectStats Phase 1.5: deterministic quality checks, value stats and sample rows (non-fatal)
+func (a *WorkerAgent) collectStats(ctx context.Context) {
+	if !a.sharedCtx.Quiet {
+		fmt.Printf("\n[%s] Phase 1.5: Running quality checks...\n", a.id)
+	}
+	qc := contextpkg.NewQualityChecker(a.adapter, a.sharedCtx, a.tableName)
+	if err := qc.RunAll(ctx); err != nil && !a.sharedCtx.Quiet {
+		fmt.Printf("[%s] Warning: quality check failed: %v\n", a.id, err)
+	}
+	if a.sharedCtx.SampleRowCount > 0 {
+		if err := a.sharedCtx.CollectSamples(ctx, a.adapter, a.tableName, a.sharedCtx.SampleRowCount); err != nil && !a.sharedCtx.Quiet {
+			fmt.Printf("[%s] Warning: sample rows failed: %v\n", a.id, err)
+		}
+	}
+}
+
+// complete marks the task completed
+func (a *WorkerAgent) complete() error {
+	a.checkNotes()
 	a.sharedCtx.CompleteTask(a.taskID, map[string]interface{}{
-		"table": a.tableName,
+		"table":           a.tableName,
+		"budget_exceeded": a.budgetExceeded,
+		"retried_phases":  a.retried,
+		"timed_out":       a.timedOut,
+		"rejected_notes":  a.rejected,
 	})
 
 	if !a.sharedCtx.Quiet {
@@ -136,104 +268,144 @@ func (a *WorkerAgent) Execute(ctx context.Context) error {
 	return nil
 }
 
-// collectBasicMetadata Phase 1: collect basic metadata (fixed flow)
-func (a *WorkerAgent) collectBasicMetadata(ctx context.Context) error {
-	// Generate queries based on DB type
-	var queries string
-	switch a.adapter.GetDatabaseType() {
-	case "MySQL":
-		queries = fmt.Sprintf(`1. DESCRIBE %s
-2. SHOW INDEX FROM %s
-3. SELECT COUNT(*) FROM %s
-4. SHOW CREATE TABLE %s`, a.tableName, a.tableName, a.tableName, a.tableName)
-	case "PostgreSQL":
-		queries = fmt.Sprintf(`1. SELECT column_name, data_type, is_nullable, column_default FROM information_schema.columns WHERE table_name='%s'
-2. SELECT indexname, indexdef FROM pg_indexes WHERE tablename='%s'
-3. SELECT COUNT(*) FROM %s
-4. SELECT tc.constraint_name, kcu.column_name, ccu.table_name AS foreign_table_name, ccu.column_name AS foreign_column_name FROM information_schema.table_constraints AS tc JOIN information_schema.key_column_usage AS kcu ON tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema JOIN information_schema.constraint_column_usage AS ccu ON ccu.constraint_name = tc.constraint_name AND ccu.table_schema = tc.table_schema WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_name='%s'`, a.tableName, a.tableName, a.tableName, a.tableName)
-	case "SQLite":
-		queries = fmt.Sprintf(`1. PRAGMA table_info(%s)
-2. PRAGMA index_list(%s)
-3. SELECT COUNT(*) FROM %s
-4. PRAGMA foreign_key_list(%s)`, a.tableName, a.tableName, a.tableName, a.tableName)
-	default:
-		queries = fmt.Sprintf(`1. DESCRIBE %s
-2. SHOW INDEX FROM %s
-3. SELECT COUNT(*) FROM %s
-4. SHOW CREATE TABLE %s`, a.tableName, a.tableName, a.tableName, a.tableName)
-	}
-
-	prompt := fmt.Sprintf(`You are analyzing table "%s" in %s database.
-
-Phase 1: Collect basic metadata using these EXACT queries:
-
-%s
-
-Execute these queries ONE BY ONE. After all queries complete, say "Phase 1 complete".`,
-		a.tableName, a.adapter.GetDatabaseType(), queries)
-
-	_, err := a.executor.Call(ctx, map[string]any{"input": prompt})
-	if err != nil {
-		return err
-	}
+// checkNotes drops the notes of this table that name unknown columns or carry SQL the
+// parser rejects, so they never reach SQL generation prompts
+func (a *WorkerAgent) checkNotes() {
+	dialect := a.adapter.GetDatabaseType()
+	a.rejected = a.sharedCtx.CheckWorkerNotes(a.tableName, func(sql string) error {
+		return inference.ParseSQL(sql, dialect)
+	})
+}
 
-	// Build basic metadata after Phase 1 completes
-	a.sharedCtx.BuildTableMetadata(a.tableName)
-	return nil
+// RejectedNotes notes dropped by the post-worker check
+func (a *WorkerAgent) RejectedNotes() []contextpkg.RejectedNote {
+	return a.rejected
 }
 
-// exploreRichContext Phase 2: ReAct loop for business insights
-// Note: data quality checks (whitespace, type mismatch, orphan, NULL stats) are now
-// handled deterministically in Phase 1.5. This phase focuses on BUSINESS SEMANTICS only.
-func (a *WorkerAgent) exploreRichContext(ctx context.Context) error {
-	// Get DB-type specific SQL syntax hints
-	dbType := a.adapter.GetDatabaseType()
-	sqlHint := ""
-	switch dbType {
-	case "SQLite":
-		sqlHint = "Note: This is SQLite. Use PRAGMA table_info(table_name) instead of DESCRIBE."
-	case "MySQL":
-		sqlHint = "Note: This is MySQL. Use DESCRIBE table_name or SHOW COLUMNS FROM table_name."
-	case "PostgreSQL":
-		sqlHint = "Note: This is PostgreSQL. Use \\d table_name or query information_schema.columns."
+// overBudget records whether err is the model's llm.ErrBudgetExceeded
+func (a *WorkerAgent) overBudget(err error) bool {
+	if !errors.Is(err, llm.ErrBudgetExceeded) {
+		return false
 	}
+	if !a.budgetExceeded && !a.sharedCtx.Quiet {
+		fmt.Printf("[%s] ⚠️  LLM budget exceeded for '%s', keeping deterministic stats only\n", a.id, a.tableName)
+	}
+	a.budgetExceeded = true
+	return true
+}
 
-	prompt := fmt.Sprintf(`You are analyzing table "%s" in %s database.
-%s
+// deadlineHit records whether ctx (the Execute deadline) has expired
+func (a *WorkerAgent) deadlineHit(ctx context.Context) bool {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return false
+	}
+	if !a.timedOut && !a.sharedCtx.Quiet {
+		fmt.Printf("[%s] ⚠️  Timed out analyzing '%s' after %v, keeping partial metadata\n", a.id, a.tableName, a.timeout)
+	}
+	a.timedOut = true
+	return true
+}
 
-Phase 2: Discover BUSINESS MEANING and VALUE PATTERNS.
+// TimedOut reports whether the table was completed with partial metadata at the deadline
+func (a *WorkerAgent) TimedOut() bool {
+	return a.timedOut
+}
 
-Data quality issues (whitespace, type mismatch, orphan records, NULL stats) have already been checked automatically. Focus ONLY on business semantics.
+// BudgetExceeded reports whether the LLM phases stopped at the model's budget
+func (a *WorkerAgent) BudgetExceeded() bool {
+	return a.budgetExceeded
+}
+
+// Retried phases rerun with the strict prompt after a failed first attempt
+func (a *WorkerAgent) Retried() []string {
+	return a.retried
+}
 
-WORKFLOW:
+// runPhase runs one ReAct phase. If it fails on malformed output or a provider error, the
+// phase is retried once with strictPrompt and the retry is recorded in the task info.
+// Budget and cancellation errors are returned as is.
+func (a *WorkerAgent) runPhase(ctx context.Context, phase, prompt, strictPrompt string) error {
+	_, err := inference.RunReAct(ctx, a.llm, a.tools, a.depth.maxIterations(), prompt)
+	if err == nil || errors.Is(err, llm.ErrBudgetExceeded) || ctx.Err() != nil {
+		return err
+	}
+	if !a.sharedCtx.Quiet {
+		fmt.Printf("[%s] ⚠️  %s failed (%v), retrying with the strict prompt\n", a.id, phase, err)
+	}
 
-1. For columns with small enumerations (<20 distinct values), explore value distributions:
-   Execute: SELECT [column], COUNT(*) as cnt FROM %s GROUP BY [column] ORDER BY cnt DESC LIMIT 15
-   Save: [column]_values|value1=meaning1(N%%), value2=meaning2(N%%)
+	_, retryErr := inference.RunReAct(ctx, a.llm, a.tools, a.depth.maxIterations(), strictPrompt)
+	a.retried = append(a.retried, phase)
+	a.sharedCtx.RecordTaskRetry(a.taskID, contextpkg.TaskRetry{Phase: phase, Error: err.Error(), Recovered: retryErr == nil})
+	return retryErr
+}
 
-2. For key business columns, record their meaning:
-   - What does this column represent?
-   - Any special encoding (e.g., 0=inactive, 1=active)?
-   Save: [column]_meaning|description of what values mean
+// collectBasicMetadata Phase 1: describes the table and counts its rows through the worker's
+// tools, without the LLM (the steps are fixed, so a ReAct loop only adds cost and failure
+// modes). The results land in the shared context the way Phase 2 tool calls do.
+func (a *WorkerAgent) collectBasicMetadata(ctx context.Context) error {
+	for _, tool := range a.tools {
+		if counted, ok := tool.(countedTool); ok {
+			tool = counted.Tool // Direct calls are not ReAct steps
+		}
+		var output string
+		var err error
+		switch t := tool.(type) {
+		case *DescribeTableTool:
+			output, err = t.Call(ctx, a.tableName)
+		case *WorkerSQLTool:
+			output, err = t.Call(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", a.quotedTable()))
+		}
+		if err != nil {
+			return err
+		}
+		// Tool errors are observations for the model; here they fail the phase
+		if strings.HasPrefix(output, "Describe Error:") || strings.HasPrefix(output, "SQL Error:") {
+			return errors.New(output)
+		}
+	}
+	a.sharedCtx.BuildTableMetadata(a.tableName)
+	return nil
+}
 
-3. Record any cross-table business rules:
-   Save: business_rules|description of business logic
+// quotedTable the table name quoted for the worker's database, so names with spaces or
+// keywords ("order") work in the fixed Phase 1 query
+func (a *WorkerAgent) quotedTable() string {
+	if strings.EqualFold(a.adapter.GetDatabaseType(), "mysql") {
+		return "`" + strings.ReplaceAll(a.tableName, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(a.tableName, `"`, `""`) + `"`
+}
 
-Examples:
-Action: execute_sql
-Action Input: SELECT status, COUNT(*) as cnt FROM orders GROUP BY status
-Observation: active=800, inactive=200
-Action: set_rich_context
-Action Input: status_values|active=800(80%%), inactive=200(20%%)
+// exploreRichContext Phase 2: ReAct loop for business insights
+// Note: data quality checks (whitespace, type mismatch, orphan, NULL stats) are now
+// handled deterministically in Phase 1.5. What this phase looks for is set by the prompt
+// profile (business semantics by default).
+func (a *WorkerAgent) exploreRichContext(ctx context.Context) error {
+	dbType := a.adapter.GetDatabaseType()
 
-Action: set_rich_context
-Action Input: business_rules|dept_id=0 means unassigned department
+	// Show a few masked example rows so the LLM sees real value shapes
+	samples := ""
+	if result, err := a.adapter.SampleRows(ctx, a.tableName, 3, adapter.DefaultMaskingRules()); err == nil && result.RowCount > 0 {
+		jsonBytes, _ := json.MarshalIndent(result.Rows, "", "  ")
+		samples = fmt.Sprintf("\nSample rows (masked):\n%s\n", string(jsonBytes))
+	}
 
-Continue exploring. Say "Phase 2 complete" when done.`,
-		a.tableName, dbType, sqlHint, a.tableName)
+	prompts := promptsOrDefault(a.prompts)
+	prompt, err := prompts.render(PromptWorkerExplore, workerPromptData{
+		Table:    a.tableName,
+		DBType:   dbType,
+		Samples:  samples,
+		Glossary: contextpkg.FormatGlossaryPrompt(a.sharedCtx.GlossaryTerms()),
+	})
+	if err != nil {
+		return err
+	}
+	strictPrompt, err := prompts.render(PromptWorkerExploreStrict, workerPromptData{Table: a.tableName, DBType: dbType})
+	if err != nil {
+		return err
+	}
 
-	_, err := a.executor.Call(ctx, map[string]any{"input": prompt})
-	return err
+	return a.runPhase(ctx, "phase2", prompt, strictPrompt)
 }
 
 // WorkerSQLTool SQL tool (for worker agent)
@@ -242,6 +414,26 @@ type WorkerSQLTool struct {
 	sharedCtx *contextpkg.SharedContext
 	agentID   string
 	tableName string
+	queries   *workerQueryLog
+}
+
+// workerQueryLog SQL a worker ran since it last saved a note
+// The queries become the provenance of the next note (the evidence it was written from).
+type workerQueryLog struct {
+	queries []string
+	ran     int // Queries executed, failed ones included (telemetry)
+}
+
+// add records an executed query
+func (l *workerQueryLog) add(sql string) {
+	l.queries = append(l.queries, sql)
+}
+
+// take returns the recorded queries and starts a new log
+func (l *workerQueryLog) take() []string {
+	queries := l.queries
+	l.queries = nil
+	return queries
 }
 
 func (t *WorkerSQLTool) Name() string {
@@ -252,10 +444,10 @@ func (t *WorkerSQLTool) Description() string {
 	return `Execute SQL queries to analyze the table.
 
 Use this to collect:
-- Column information (schema, types, etc.)
-- Index information: SHOW INDEX FROM table_name
 - Row count: SELECT COUNT(*) FROM table_name
+- Value distributions: SELECT col, COUNT(*) FROM table_name GROUP BY col
 
+For columns, indexes and foreign keys use describe_table instead.
 Execute queries one by one and collect all information.`
 }
 
@@ -263,6 +455,9 @@ func (t *WorkerSQLTool) Call(ctx context.Context, input string) (string, error)
 	if !t.sharedCtx.Quiet {
 		fmt.Printf("\n[%s] SQL: %s\n", t.agentID, input)
 	}
+	if t.queries != nil {
+		t.queries.ran++
+	}
 
 	// Execute SQL
 	result, err := t.adapter.ExecuteQuery(ctx, input)
@@ -273,6 +468,9 @@ func (t *WorkerSQLTool) Call(ctx context.Context, input string) (string, error)
 	if result.Error != "" {
 		return fmt.Sprintf("SQL Error: %s", result.Error), nil
 	}
+	if t.queries != nil {
+		t.queries.add(input)
+	}
 
 	// Format results
 	output := fmt.Sprintf("✓ Query successful! (%d rows, %dms)\n\n", result.RowCount, result.ExecutionTime)
@@ -298,33 +496,113 @@ func (t *WorkerSQLTool) Call(ctx context.Context, input string) (string, error)
 func detectQueryType(sql string) string {
 	sql = strings.ToUpper(sql)
 
-	// Column info query
-	if strings.Contains(sql, "DESCRIBE") ||
-		strings.Contains(sql, "PRAGMA TABLE_INFO") ||
-		strings.Contains(sql, "INFORMATION_SCHEMA.COLUMNS") {
-		return "columns"
+	// Row count query (columns/indexes/foreign keys come from describe_table)
+	if strings.Contains(sql, "COUNT(*)") {
+		return "rowcount"
 	}
 
-	// Index info query
-	if strings.Contains(sql, "SHOW INDEX") ||
-		strings.Contains(sql, "PRAGMA INDEX_LIST") ||
-		strings.Contains(sql, "PG_INDEXES") {
-		return "indexes"
+	return ""
+}
+
+// DescribeTableTool schema introspection tool (dialect-independent, via adapter)
+type DescribeTableTool struct {
+	adapter   adapter.DBAdapter
+	sharedCtx *contextpkg.SharedContext
+	agentID   string
+	tableName string
+}
+
+func (t *DescribeTableTool) Name() string {
+	return "describe_table"
+}
+
+func (t *DescribeTableTool) Description() string {
+	return `Describe a table's structure: columns (name, type, nullable, default, primary key), indexes and foreign keys.
+
+Input: table name (defaults to the table being analyzed)
+
+Works on every database type, no dialect-specific SQL needed.`
+}
+
+func (t *DescribeTableTool) Call(ctx context.Context, input string) (string, error) {
+	tableName := strings.Trim(strings.TrimSpace(input), "\"`'[]")
+	if tableName == "" {
+		tableName = t.tableName
 	}
 
-	// Row count query
-	if strings.Contains(sql, "COUNT(*)") {
-		return "rowcount"
+	if !t.sharedCtx.Quiet {
+		fmt.Printf("\n[%s] Describe: %s\n", t.agentID, tableName)
 	}
 
-	// Foreign key info query
-	if strings.Contains(sql, "FOREIGN_KEY_LIST") ||
-		strings.Contains(sql, "SHOW CREATE TABLE") ||
-		(strings.Contains(sql, "TABLE_CONSTRAINTS") && strings.Contains(sql, "FOREIGN KEY")) {
-		return "foreignkeys"
+	columns, err := t.adapter.GetColumns(ctx, tableName)
+	if err != nil {
+		return fmt.Sprintf("Describe Error: %v", err), nil
+	}
+	if len(columns) == 0 {
+		return fmt.Sprintf("Describe Error: table %s not found", tableName), nil
+	}
+	indexes, err := t.adapter.GetIndexes(ctx, tableName)
+	if err != nil {
+		return fmt.Sprintf("Describe Error: %v", err), nil
+	}
+	foreignKeys, err := t.adapter.GetForeignKeys(ctx, tableName)
+	if err != nil {
+		return fmt.Sprintf("Describe Error: %v", err), nil
 	}
 
-	return ""
+	kind := "Table"
+	if _, isView := t.sharedCtx.GetData(tableName + "_view"); isView {
+		kind = "View (read-only)"
+	}
+	output := fmt.Sprintf("✓ %s %s: %d columns, %d indexes, %d foreign keys\n\nColumns:\n", kind, tableName, len(columns), len(indexes), len(foreignKeys))
+	for _, col := range columns {
+		output += fmt.Sprintf("  - %s %s", col.Name, col.Type)
+		if col.IsPrimaryKey {
+			output += " PK"
+		}
+		if !col.Nullable {
+			output += " NOT NULL"
+		}
+		if col.DefaultValue != "" {
+			output += " DEFAULT " + col.DefaultValue
+		}
+		if col.Collation != "" {
+			output += " COLLATE " + col.Collation
+		}
+		if col.CaseInsensitive {
+			output += " (case-insensitive)"
+		}
+		if col.Generated != "" {
+			output += fmt.Sprintf(" GENERATED %s AS (%s)", strings.ToUpper(col.Generated), col.Expression)
+		}
+		output += "\n"
+	}
+	if len(indexes) > 0 {
+		output += "Indexes:\n"
+		for _, idx := range indexes {
+			output += fmt.Sprintf("  - %s(%s)", idx.Name, strings.Join(idx.Columns, ", "))
+			if idx.IsUnique {
+				output += " UNIQUE"
+			}
+			output += "\n"
+		}
+	}
+	if len(foreignKeys) > 0 {
+		output += "Foreign keys:\n"
+		for _, fk := range foreignKeys {
+			output += fmt.Sprintf("  - %s → %s.%s\n", fk.ColumnName, fk.ReferencedTable, fk.ReferencedColumn)
+		}
+	}
+
+	// Auto-save data to SharedContext (only for the analyzed table)
+	if tableName == t.tableName {
+		t.sharedCtx.SetData(tableName+"_columns", columns)
+		t.sharedCtx.SetData(tableName+"_indexes", indexes)
+		t.sharedCtx.SetData(tableName+"_foreignkeys", foreignKeys)
+		output += fmt.Sprintf("\n💾 Data saved to context: %s_columns, %s_indexes, %s_foreignkeys\n", tableName, tableName, tableName)
+	}
+
+	return output, nil
 }
 
 // generateTableDescription generates table business description
@@ -351,7 +629,7 @@ Business Insights:
 
 	if len(table.RichContext) > 0 {
 		for key, value := range table.RichContext {
-			prompt += fmt.Sprintf("- %s: %s\n", key, value)
+			prompt += fmt.Sprintf("- %s: %s\n", key, value.Content)
 		}
 	} else {
 		prompt += "(No business insights collected yet)\n"
@@ -387,6 +665,7 @@ type SetRichContextTool struct {
 	sharedCtx *contextpkg.SharedContext
 	agentID   string
 	tableName string
+	queries   *workerQueryLog // Queries behind the note (nil = not recorded)
 }
 
 func (t *SetRichContextTool) Name() string {
@@ -396,68 +675,72 @@ func (t *SetRichContextTool) Name() string {
 func (t *SetRichContextTool) Description() string {
 	return `Save business insights and DATA QUALITY ISSUES to rich context. Use this IMMEDIATELY after discovering insights.
 
-Input format: key|value
+Input: a JSON object
+- key (required): note name, see the conventions below
+- content (required): ONLY the insight itself, NO Thought/Action/Observation text
+- severity: "critical" | "warning" | "info" (for quality issues)
+- affected_columns: the columns the note is about
+- expires_at: RFC3339 time or YYYY-MM-DD, only to expire a volatile insight early (default: 7 days)
 
 Key naming conventions:
 - Business insights: {column}_values, {column}_meaning, business_rules
 - Quality issues: {column}_quality_issue (CRITICAL for SQL generation)
 
-Value: ONLY the insight itself, NO Thought/Action/Observation text.
-
 Good examples:
-- status_values|0=disabled(10%), 1=active(90%)
-- business_rules|dept_id=0 means unassigned department
-- payment_methods|1=Alipay(50%), 2=WeChat(30%), 3=Bank(20%)
-- horsepower_quality_issue|⚠️ TEXT field storing numeric values. Requires CAST() for comparisons.
-- airport_code_quality_issue|⚠️ Contains whitespace. Use TRIM() for exact matching.
-- model_list_orphan_issue|⚠️ 1 orphan record (Maker ID not in car_makers).
+- {"key": "status_values", "content": "0=disabled(10%), 1=active(90%)", "affected_columns": ["status"]}
+- {"key": "business_rules", "content": "dept_id=0 means unassigned department", "affected_columns": ["dept_id"]}
+- {"key": "payment_methods", "content": "1=Alipay(50%), 2=WeChat(30%), 3=Bank(20%)", "affected_columns": ["payment_method"]}
+- {"key": "horsepower_quality_issue", "content": "⚠️ TEXT field storing numeric values. Use CAST(horsepower AS REAL) for comparisons.", "severity": "critical", "affected_columns": ["horsepower"]}
+- {"key": "airport_code_quality_issue", "content": "⚠️ Contains whitespace. Use TRIM(airport_code) for exact matching.", "severity": "warning", "affected_columns": ["airport_code"]}
 
 Bad examples (DO NOT include Thought/Action):
-- status_values|0=disabled(10%), 1=active(90%)\n\nThought: Next I will...
+- {"key": "status_values", "content": "0=disabled(10%), 1=active(90%)\n\nThought: Next I will..."}
 
 IMPORTANT: 
 - Save insights IMMEDIATELY after each discovery, not at the end
 - Use ⚠️ prefix for quality issues to highlight them
-- Quality issues are CRITICAL - they directly affect SQL query correctness`
+- Quality issues are CRITICAL - they directly affect SQL query correctness
+- {column} and affected_columns must be existing columns, and SQL in backticks must be valid; other notes are dropped`
 }
 
 func (t *SetRichContextTool) Call(ctx context.Context, input string) (string, error) {
-	// Parse input: key|value
-	parts := strings.SplitN(input, "|", 2)
-	if len(parts) != 2 {
-		return "", fmt.Errorf("invalid format, expected: key|value")
-	}
-
-	key := strings.TrimSpace(parts[0])
-	value := strings.TrimSpace(parts[1])
-
-	// Clean value: remove Thought/Action/Observation text
-	// Find newline followed by Thought: or Action:
-	if idx := strings.Index(value, "\n\nThought:"); idx > 0 {
-		value = value[:idx]
-	}
-	if idx := strings.Index(value, "\n\nAction:"); idx > 0 {
-		value = value[:idx]
-	}
-	if idx := strings.Index(value, "\nThought:"); idx > 0 {
-		value = value[:idx]
+	in, err := parseRichContextInput(input)
+	if err != nil {
+		return "", err
 	}
-	if idx := strings.Index(value, "\nAction:"); idx > 0 {
-		value = value[:idx]
+	for i, col := range in.AffectedColumns {
+		if len(col) > len(t.tableName)+1 && strings.EqualFold(col[:len(t.tableName)+1], t.tableName+".") {
+			in.AffectedColumns[i] = col[len(t.tableName)+1:] // "orders.status" → "status"
+		}
 	}
 
-	value = strings.TrimSpace(value)
+	// Expires unless re-generated or corrected; workers may only shorten the lifetime
+	expiresAt, honored := noteExpiry(in.ExpiresAt, time.Now())
 
-	if key == "" || value == "" {
-		return "", fmt.Errorf("key and value cannot be empty")
+	provenance := &contextpkg.NoteProvenance{
+		Source:    "worker",
+		Agent:     t.agentID,
+		Model:     t.sharedCtx.ModelName,
+		UpdatedAt: time.Now().Format(time.RFC3339),
+	}
+	if t.queries != nil {
+		provenance.Queries = t.queries.take()
 	}
 
-	// Save to SharedContext (7-day expiry)
-	expiresAt := time.Now().Add(7 * 24 * time.Hour).Format(time.RFC3339)
-	err := t.sharedCtx.SetTableRichContext(t.tableName, key, value, expiresAt)
+	err = t.sharedCtx.SetTableNote(t.tableName, in.Key, contextpkg.BusinessNote{
+		Content:         in.Content,
+		ExpiresAt:       expiresAt,
+		Severity:        in.Severity,
+		AffectedColumns: in.AffectedColumns,
+		Provenance:      provenance,
+	})
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("✓ Rich context saved: %s = %s", key, value), nil
+	saved := fmt.Sprintf("✓ Rich context saved: %s = %s", in.Key, in.Content)
+	if !honored {
+		saved += fmt.Sprintf(" (expires_at %q ignored: not a time within 7 days, expires %s)", in.ExpiresAt, expiresAt)
+	}
+	return saved, nil
 }
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/tmc/langchaingo/tools"
+
+	contextpkg "reactsql/internal/context"
+	"reactsql/internal/llm"
+)
+
+// countedTool counts the calls of a worker tool (the worker's ReAct steps)
+type countedTool struct {
+	tools.Tool
+	calls *int
+}
+
+func (t countedTool) Call(ctx context.Context, input string) (string, error) {
+	*t.calls++
+	return t.Tool.Call(ctx, input)
+}
+
+// countSteps wraps tools so each call counts as one ReAct step
+func countSteps(calls *int, toolList ...tools.Tool) []tools.Tool {
+	counted := make([]tools.Tool, len(toolList))
+	for i, tool := range toolList {
+		counted[i] = countedTool{Tool: tool, calls: calls}
+	}
+	return counted
+}
+
+// recordTelemetry saves how the worker ran with its table
+// LLM calls and tokens are only known when the worker has its own llm.BudgetModel (one per
+// table, as gen_all_dev creates them); a model shared by all workers reports nothing.
+func (a *WorkerAgent) recordTelemetry(start time.Time, err error) {
+	telemetry := &contextpkg.WorkerTelemetry{
+		Agent:       a.id,
+		Model:       a.sharedCtx.ModelName,
+		Outcome:     contextpkg.OutcomeCompleted,
+		ReActSteps:  a.steps,
+		SQLQueries:  a.queries.ran,
+		Retries:     len(a.retried),
+		DurationSec: time.Since(start).Seconds(),
+	}
+	switch {
+	case err != nil:
+		telemetry.Outcome = contextpkg.OutcomeFailed
+	case a.timedOut:
+		telemetry.Outcome = contextpkg.OutcomeTimedOut
+	case a.budgetExceeded:
+		telemetry.Outcome = contextpkg.OutcomeBudgetExceeded
+	}
+	if budget, ok := a.llm.(*llm.BudgetModel); ok {
+		usage := budget.Usage()
+		telemetry.LLMCalls = usage.Calls
+		telemetry.Tokens = usage.TotalTokens
+	}
+	a.sharedCtx.SetTableTelemetry(a.tableName, telemetry)
+}
@@ -0,0 +1,184 @@
+package context
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// BirdValueNoteKey Rich Context key of the official value descriptions of a table's columns
+const BirdValueNoteKey = "bird_value_meanings"
+
+// LoadBirdDescriptions merges a BIRD database_description directory into the context
+// Each <table>.csv lists original_column_name, column_name, column_description,
+// data_format and value_description per column. The expanded name and description become
+// the column Comment (appended to a DDL comment); value descriptions (code meanings,
+// units, commonsense evidence) are stored as the table's BirdValueNoteKey note.
+// Files and columns not in the context are skipped. Returns the columns described.
+func (c *SharedContext) LoadBirdDescriptions(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read description directory: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tables := make(map[string]*TableMetadata, len(c.Tables))
+	for _, table := range c.Tables {
+		tables[normalizeTableName(strings.TrimSpace(table.Name))] = table
+	}
+
+	described := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".csv") {
+			continue
+		}
+		tableName := strings.TrimSpace(strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())))
+		table, ok := tables[normalizeTableName(tableName)]
+		if !ok {
+			continue
+		}
+
+		rows, err := readDescriptionCSV(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			if !c.Quiet {
+				fmt.Printf("[Context] ⚠️  Skipping %s: %v\n", entry.Name(), err)
+			}
+			continue
+		}
+		described += mergeBirdDescriptions(table, rows)
+	}
+
+	if !c.Quiet {
+		fmt.Printf("[Context] Loaded BIRD descriptions: %d columns\n", described)
+	}
+	return described, nil
+}
+
+// birdColumnDescription one row of a BIRD description CSV
+type birdColumnDescription struct {
+	column      string // original_column_name
+	name        string // column_name (expanded)
+	description string // column_description
+	values      string // value_description
+}
+
+// readDescriptionCSV parses a description file (UTF-8 with optional BOM, or Latin-1)
+func readDescriptionCSV(path string) ([]birdColumnDescription, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		// Several BIRD files are Windows-1252 / Latin-1
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		data = []byte(string(runes))
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	// Columns by header name; the files share a header but not always its case or spacing
+	index := map[string]int{}
+	for i, header := range records[0] {
+		index[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	field := func(record []string, name string) string {
+		i, ok := index[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.Join(strings.Fields(record[i]), " ")
+	}
+	if _, ok := index["original_column_name"]; !ok {
+		return nil, fmt.Errorf("missing original_column_name header")
+	}
+
+	var rows []birdColumnDescription
+	for _, record := range records[1:] {
+		row := birdColumnDescription{
+			column:      field(record, "original_column_name"),
+			name:        field(record, "column_name"),
+			description: field(record, "column_description"),
+			values:      field(record, "value_description"),
+		}
+		if row.column != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// mergeBirdDescriptions applies the rows to the table's columns, returning the columns described
+func mergeBirdDescriptions(table *TableMetadata, rows []birdColumnDescription) int {
+	columns := make(map[string]*ColumnMetadata, len(table.Columns))
+	for i := range table.Columns {
+		columns[normalizeColumnName(table.Columns[i].Name)] = &table.Columns[i]
+	}
+
+	described := 0
+	var values []string
+	for _, row := range rows {
+		col, ok := columns[normalizeColumnName(row.column)]
+		if !ok {
+			continue
+		}
+
+		if comment := birdComment(row); comment != "" {
+			switch {
+			case col.Comment == "":
+				col.Comment = comment
+			case !strings.Contains(col.Comment, comment):
+				col.Comment += "; " + comment
+			}
+			described++
+		}
+		if row.values != "" && !strings.EqualFold(row.values, row.description) {
+			values = append(values, fmt.Sprintf("%s: %s", col.Name, row.values))
+		}
+	}
+
+	if len(values) > 0 {
+		if table.RichContext == nil {
+			table.RichContext = make(map[string]RichContextValue)
+		}
+		table.RichContext[BirdValueNoteKey] = RichContextValue{
+			BusinessNote: BusinessNote{Content: strings.Join(values, "; ")},
+		}
+	}
+	return described
+}
+
+// birdComment expanded column name and description, e.g. "customer id: the id of the customer"
+func birdComment(row birdColumnDescription) string {
+	name := row.name
+	plain := strings.NewReplacer("_", " ", "-", " ").Replace(row.column)
+	if strings.EqualFold(name, row.column) || strings.EqualFold(name, plain) ||
+		strings.Contains(strings.ToLower(row.description), strings.ToLower(name)) {
+		name = ""
+	}
+	switch {
+	case name != "" && row.description != "":
+		return name + ": " + row.description
+	case name != "":
+		return name
+	}
+	return row.description
+}
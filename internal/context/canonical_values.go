@@ -0,0 +1,66 @@
+package context
+
+import "strings"
+
+// CanonicalKey the form user-provided values are matched in: trimmed, inner whitespace
+// collapsed, case-folded ("  United  States " → "united states")
+func CanonicalKey(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+// buildCanonicalMap maps each value's CanonicalKey to the value
+// Keys shared by several distinct literals ("USA" and "usa") are ambiguous and left out,
+// so a rewrite never picks one of them; empty keys are left out too.
+func buildCanonicalMap(values []string) map[string]string {
+	canonical := make(map[string]string, len(values))
+	ambiguous := make(map[string]bool)
+	for _, value := range values {
+		key := CanonicalKey(value)
+		if key == "" || ambiguous[key] {
+			continue
+		}
+		if existing, ok := canonical[key]; ok && existing != value {
+			delete(canonical, key)
+			ambiguous[key] = true
+			continue
+		}
+		canonical[key] = value
+	}
+	if len(canonical) == 0 {
+		return nil
+	}
+	return canonical
+}
+
+// CanonicalValue the exact stored literal a user-provided value stands for
+// ok is false when the column has no canonicalization map, the value has no unambiguous
+// match, or the value is already the stored literal.
+func (c *SharedContext) CanonicalValue(tableName, column, value string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	table := c.findTable(tableName)
+	if table == nil {
+		return "", false
+	}
+	for _, col := range table.Columns {
+		if !strings.EqualFold(col.Name, column) || col.ValueStats == nil {
+			continue
+		}
+		canonical := col.ValueStats.Canonical
+		if canonical == nil && len(col.ValueStats.TopValues) > 0 && len(col.ValueStats.TopValues) == col.ValueStats.DistinctCount {
+			// Contexts generated before the map: top values listing every value are enough
+			values := make([]string, len(col.ValueStats.TopValues))
+			for i, top := range col.ValueStats.TopValues {
+				values[i] = top.Value
+			}
+			canonical = buildCanonicalMap(values)
+		}
+		literal, ok := canonical[CanonicalKey(value)]
+		if !ok || literal == value {
+			return "", false
+		}
+		return literal, true
+	}
+	return "", false
+}
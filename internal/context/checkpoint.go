@@ -0,0 +1,95 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// checkpointFile tables finished by an interrupted generation run
+type checkpointFile struct {
+	CompletedTables []string       `json:"completed_tables"`
+	Context         *SharedContext `json:"context"` // Completed tables only, with their semantics and indexed values
+}
+
+// CheckpointPath checkpoint of a context file being generated (orders.json → orders.json.partial)
+// The suffix keeps checkpoints out of *.json globs over the context directory.
+func CheckpointPath(contextPath string) string {
+	return contextPath + ".partial"
+}
+
+// SaveCheckpoint writes the completed tables to a checkpoint file
+// Written to a temp file and renamed, so a crash mid-write keeps the previous checkpoint.
+func (c *SharedContext) SaveCheckpoint(path string, completed []string) error {
+	c.mu.RLock()
+	snapshot := NewSharedContext(c.DatabaseName, c.DatabaseType)
+	snapshot.restoreTables(c, completed)
+	data, err := json.MarshalIndent(checkpointFile{CompletedTables: completed, Context: snapshot}, "", "  ")
+	c.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadCheckpoint reads a checkpoint file; a missing file is no checkpoint (nil, nil, nil)
+func LoadCheckpoint(path string) (*SharedContext, []string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	file := checkpointFile{Context: NewSharedContext("", "")}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	return file.Context, file.CompletedTables, nil
+}
+
+// RestoreTables copies tables finished in another context (a checkpoint) into this one:
+// their metadata, field semantics and indexed values. Tables missing from from are skipped.
+func (c *SharedContext) RestoreTables(from *SharedContext, tables []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.restoreTables(from, tables)
+}
+
+// restoreTables RestoreTables without locking c (from is only read)
+func (c *SharedContext) restoreTables(from *SharedContext, tables []string) {
+	for _, name := range tables {
+		table, ok := from.Tables[name]
+		if !ok {
+			continue
+		}
+		c.Tables[name] = table
+
+		for key, fs := range from.FieldSemantics {
+			if strings.EqualFold(fs.TableName, name) {
+				if c.FieldSemantics == nil {
+					c.FieldSemantics = make(map[string]*FieldSemantic)
+				}
+				c.FieldSemantics[key] = fs
+			}
+		}
+
+		if from.ValueIndex == nil {
+			continue
+		}
+		for key, values := range from.ValueIndex.Columns {
+			if strings.HasPrefix(key, name+".") {
+				if c.ValueIndex == nil {
+					c.ValueIndex = NewValueIndex()
+				}
+				c.ValueIndex.Columns[key] = values
+			}
+		}
+	}
+}
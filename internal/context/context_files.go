@@ -0,0 +1,52 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GenerationReportFile per-database cost report gen_all_dev writes next to the contexts
+const GenerationReportFile = "generation_report.json"
+
+// IsContextFile reports whether a file of a context directory holds a context
+// (*.json other than the generation report and <db>.overrides.json files)
+func IsContextFile(name string) bool {
+	return strings.HasSuffix(name, ".json") && name != GenerationReportFile && !strings.HasSuffix(name, overridesSuffix)
+}
+
+// ContextFiles the context file at path, or every context file directly under a directory (sorted)
+func ContextFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range matches {
+		if IsContextFile(filepath.Base(file)) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// SQLitePath the database file of a context: dbPath when set, else the Spider/BIRD layout
+// <dbDir>/<dbName>/<dbName>.sqlite ("" without either)
+func SQLitePath(dbPath, dbDir, dbName string) string {
+	if dbPath != "" {
+		return dbPath
+	}
+	if dbDir == "" {
+		return ""
+	}
+	return filepath.Join(dbDir, dbName, dbName+".sqlite")
+}
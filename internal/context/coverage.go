@@ -0,0 +1,128 @@
+package context
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Coverage checks: what the context tells the model about the schema elements a query needs.
+// Names are matched case-insensitively.
+
+// DescribesColumn reports whether the context explains a column: a column comment, a field
+// semantic note, or a Rich Context note keyed by or mentioning the column
+func (c *SharedContext) DescribesColumn(tableName, column string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	table := c.findTable(tableName)
+	if table == nil {
+		return false
+	}
+	for _, col := range table.Columns {
+		if strings.EqualFold(col.Name, column) && col.Comment != "" {
+			return true
+		}
+	}
+	for _, fs := range c.FieldSemantics {
+		if strings.EqualFold(fs.TableName, tableName) && strings.EqualFold(fs.ColumnName, column) && fs.Note != "" {
+			return true
+		}
+	}
+	return len(columnNotes(table, column)) > 0
+}
+
+// EnumeratesValue reports whether the context lists a column value: among its top values,
+// in the value index, or in a Rich Context note about the column
+func (c *SharedContext) EnumeratesValue(tableName, column, value string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	table := c.findTable(tableName)
+	if table == nil {
+		return false
+	}
+	for _, col := range table.Columns {
+		if !strings.EqualFold(col.Name, column) || col.ValueStats == nil {
+			continue
+		}
+		for _, top := range col.ValueStats.TopValues {
+			if strings.EqualFold(top.Value, value) {
+				return true
+			}
+		}
+	}
+	if c.ValueIndex != nil {
+		for key, values := range c.ValueIndex.Columns {
+			if !strings.EqualFold(key, table.Name+"."+column) {
+				continue
+			}
+			for _, v := range values {
+				if strings.EqualFold(v, value) {
+					return true
+				}
+			}
+		}
+	}
+	lower := strings.ToLower(value)
+	for _, note := range columnNotes(table, column) {
+		if strings.Contains(strings.ToLower(note), lower) {
+			return true
+		}
+	}
+	return false
+}
+
+// CapturesJoin reports whether the context knows the join a.x = b.y: a foreign key between
+// the columns (either direction) or a join path using them
+func (c *SharedContext) CapturesJoin(tableA, columnA, tableB, columnB string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if foreignKeyLinks(c.findTable(tableA), columnA, tableB, columnB) ||
+		foreignKeyLinks(c.findTable(tableB), columnB, tableA, columnA) {
+		return true
+	}
+	left := strings.ToLower(tableA + "." + columnA)
+	right := strings.ToLower(tableB + "." + columnB)
+	for _, path := range c.JoinPaths {
+		clauses := strings.ToLower(strings.Join(path.JoinClauses, " ") + " " + path.JoinSQL)
+		if strings.Contains(clauses, left) && strings.Contains(clauses, right) {
+			return true
+		}
+	}
+	return false
+}
+
+// foreignKeyLinks reports whether a key of table pairs column with refTable.refColumn
+func foreignKeyLinks(table *TableMetadata, column, refTable, refColumn string) bool {
+	if table == nil {
+		return false
+	}
+	for _, fk := range table.ForeignKeys {
+		if !strings.EqualFold(fk.ReferencedTable, refTable) {
+			continue
+		}
+		refs := fk.ReferencedKeyColumns()
+		for i, col := range fk.KeyColumns() {
+			// An empty referenced column means the referenced primary key; accept it
+			if strings.EqualFold(col, column) && (refs[i] == "" || strings.EqualFold(refs[i], refColumn)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// columnNotes contents of the Rich Context notes keyed by the column (<column>_...) or
+// naming it as a whole word
+func columnNotes(table *TableMetadata, column string) []string {
+	mention := regexp.MustCompile(`(?i)(^|[^\w])` + regexp.QuoteMeta(column) + `($|[^\w])`)
+	prefix := normalizeColumnName(column) + "_"
+	var notes []string
+	for key, note := range table.RichContext {
+		if strings.HasPrefix(strings.ToLower(key), prefix) || mention.MatchString(note.Content) {
+			notes = append(notes, note.Content)
+		}
+	}
+	return notes
+}
@@ -0,0 +1,144 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// minDateFormatRatio share of non-empty values a date format must match
+const minDateFormatRatio = 0.9
+
+// textDateFormat a way dates are written into a TEXT column
+type textDateFormat struct {
+	name     string
+	globs    []string // SQLite GLOB patterns of the whole value
+	severity string
+	hint     bool                    // Only with a temporal column name (digit strings are often IDs)
+	recipe   func(col string) string // SQLite expressions that read the format correctly
+}
+
+// textDateFormats formats detected by checkDateFormat, most specific first
+var textDateFormats = []textDateFormat{
+	{
+		name:     "YYYY-MM-DD HH:MM:SS",
+		globs:    []string{"[0-9][0-9][0-9][0-9]-[01][0-9]-[0-3][0-9][ T][0-2][0-9]:[0-5][0-9]*"},
+		severity: "info",
+		recipe: func(col string) string {
+			return fmt.Sprintf("year: strftime('%%Y', %s) = '2019'; day: date(%s); range: %s >= '2019-01-01' AND %s < '2020-01-01' (BETWEEN ... '2019-12-31' misses times on the last day)", col, col, col, col)
+		},
+	},
+	{
+		name:     "YYYY-MM-DD",
+		globs:    []string{"[0-9][0-9][0-9][0-9]-[01][0-9]-[0-3][0-9]"},
+		severity: "info",
+		recipe: func(col string) string {
+			return fmt.Sprintf("year: strftime('%%Y', %s) = '2019' (or substr(%s, 1, 4)); month: strftime('%%m', %s); range: %s BETWEEN '2019-01-01' AND '2019-12-31'", col, col, col, col)
+		},
+	},
+	{
+		name:     "DD/MM/YYYY",
+		globs:    []string{"[0-3][0-9][/.-][01][0-9][/.-][0-9][0-9][0-9][0-9]"},
+		severity: "warning",
+		recipe: func(col string) string {
+			return fmt.Sprintf("not sortable or comparable as text, and strftime()/date() return NULL; year: substr(%s, 7, 4); ISO date: substr(%s, 7, 4) || '-' || substr(%s, 4, 2) || '-' || substr(%s, 1, 2)", col, col, col, col)
+		},
+	},
+	{
+		name:     "MM/DD/YYYY",
+		globs:    []string{"[01][0-9][/.-][0-3][0-9][/.-][0-9][0-9][0-9][0-9]"},
+		severity: "warning",
+		recipe: func(col string) string {
+			return fmt.Sprintf("not sortable or comparable as text, and strftime()/date() return NULL; year: substr(%s, 7, 4); ISO date: substr(%s, 7, 4) || '-' || substr(%s, 1, 2) || '-' || substr(%s, 4, 2)", col, col, col, col)
+		},
+	},
+	{
+		name:     "epoch seconds",
+		globs:    []string{"[1-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9]", "[1-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9]"},
+		severity: "warning",
+		hint:     true,
+		recipe: func(col string) string {
+			return fmt.Sprintf("seconds since 1970; date: datetime(CAST(%s AS INTEGER), 'unixepoch'); year: strftime('%%Y', CAST(%s AS INTEGER), 'unixepoch') = '2019'", col, col)
+		},
+	},
+	{
+		name:     "epoch milliseconds",
+		globs:    []string{"1[0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9]"},
+		severity: "warning",
+		hint:     true,
+		recipe: func(col string) string {
+			return fmt.Sprintf("milliseconds since 1970; date: datetime(CAST(%s AS INTEGER) / 1000, 'unixepoch'); year: strftime('%%Y', CAST(%s AS INTEGER) / 1000, 'unixepoch') = '2019'", col, col)
+		},
+	},
+}
+
+// checkDateFormat classifies a TEXT column holding dates and returns the recipe to query it
+func (qc *QualityChecker) checkDateFormat(ctx context.Context, colName string) *QualityIssue {
+	col := quoteIdent(colName)
+	sums := []string{"COUNT(*) as total"}
+	for i, format := range textDateFormats {
+		matches := make([]string, len(format.globs))
+		for j, glob := range format.globs {
+			matches[j] = fmt.Sprintf("%s GLOB '%s'", col, glob)
+		}
+		sums = append(sums, fmt.Sprintf("SUM(CASE WHEN %s THEN 1 ELSE 0 END) as f%d", strings.Join(matches, " OR "), i))
+	}
+	// Day-first vs month-first: a first or second field above 12 settles it
+	sums = append(sums,
+		fmt.Sprintf("SUM(CASE WHEN CAST(substr(%s, 1, 2) AS INTEGER) > 12 THEN 1 ELSE 0 END) as day_first", col),
+		fmt.Sprintf("SUM(CASE WHEN CAST(substr(%s, 4, 2) AS INTEGER) > 12 THEN 1 ELSE 0 END) as month_first", col),
+	)
+	formatSQL := fmt.Sprintf(`SELECT %s FROM %s WHERE %s IS NOT NULL AND %s != ''`,
+		strings.Join(sums, ", "), qc.from(""), col, col)
+
+	result, err := qc.query(ctx, formatSQL)
+	if err != nil || result.RowCount == 0 {
+		return nil
+	}
+	row := result.Rows[0]
+	total := toInt(row["total"])
+	if total < 5 {
+		return nil // too few values to judge
+	}
+
+	for i, format := range textDateFormats {
+		count := toInt(row[fmt.Sprintf("f%d", i)])
+		if float64(count)/float64(total) < minDateFormatRatio || (format.hint && !isTemporalName(colName)) {
+			continue
+		}
+
+		description := fmt.Sprintf("TEXT dates in %s format (%d/%d non-empty values)", format.name, count, total)
+		if format.name == "DD/MM/YYYY" {
+			// Both slash patterns match "01/02/2019"; a field above 12 decides the order
+			dayFirst, monthFirst := toInt(row["day_first"]), toInt(row["month_first"])
+			if monthFirst > 0 && dayFirst == 0 {
+				format = textDateFormats[i+1]
+				description = fmt.Sprintf("TEXT dates in %s format (%d/%d non-empty values)", format.name, count, total)
+			} else if dayFirst == 0 {
+				description += "; day/month order assumed, no field is above 12"
+			}
+		}
+
+		return &QualityIssue{
+			Table:       qc.tableName,
+			Column:      colName,
+			Type:        "date_format",
+			Severity:    format.severity,
+			Description: description,
+			SQLFix:      format.recipe(col),
+			AffectedOps: []string{"WHERE", "ORDER BY", "GROUP BY"},
+		}
+	}
+	return nil
+}
+
+// isTemporalName reports whether a column name suggests a date or time
+func isTemporalName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, hint := range []string{"date", "time", "epoch", "created", "updated", "modified"} {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return strings.HasSuffix(lower, "_at") || strings.HasSuffix(lower, "_ts") || lower == "ts"
+}
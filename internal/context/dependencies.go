@@ -0,0 +1,114 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxDependencyColumns columns of a table compared pairwise for dependencies
+const maxDependencyColumns = 12
+
+// minDependencyRows rows a table needs before a dependency is trusted (tiny tables are 1:1 by chance)
+const minDependencyRows = 10
+
+// checkDependencies detects column pairs where one determines the other (every A value has
+// a single B value) or both determine each other (1:1), and records them as FieldSemantics
+// so the generator knows it may join or group on either column. Returns the pairs found.
+func (qc *QualityChecker) checkDependencies(ctx context.Context, table *TableMetadata) int {
+	if table.RowCount < minDependencyRows {
+		return 0
+	}
+
+	var candidates []ColumnMetadata
+	for _, col := range table.Columns {
+		colType := strings.ToUpper(col.Type)
+		if col.ValueStats == nil || col.ValueStats.DistinctCount < 2 || col.ValueStats.Dates != nil {
+			continue
+		}
+		if !isTextType(colType) && !strings.Contains(colType, "INT") {
+			continue // Measures and dates determine nothing useful
+		}
+		candidates = append(candidates, col)
+		if len(candidates) == maxDependencyColumns {
+			break
+		}
+	}
+
+	unique := func(col ColumnMetadata) bool {
+		return int64(col.ValueStats.DistinctCount) == table.RowCount-int64(col.ValueStats.NullCount)
+	}
+
+	found := 0
+	for i, a := range candidates {
+		for _, b := range candidates[i+1:] {
+			switch {
+			case unique(a) && unique(b):
+				// Two keys are trivially 1:1; only worth noting next to the primary key
+				if a.IsPrimaryKey || b.IsPrimaryKey {
+					qc.recordDependency(a.Name, b.Name, true)
+					found++
+				}
+			case unique(a) || unique(b):
+				// A key determines every column; not informative
+			default:
+				da, db := a.ValueStats.DistinctCount, b.ValueStats.DistinctCount
+				aToB := db <= da && qc.determines(ctx, a.Name, b.Name)
+				bToA := da <= db && qc.determines(ctx, b.Name, a.Name)
+				switch {
+				case aToB && bToA:
+					qc.recordDependency(a.Name, b.Name, true)
+				case aToB:
+					qc.recordDependency(a.Name, b.Name, false)
+				case bToA:
+					qc.recordDependency(b.Name, a.Name, false)
+				default:
+					continue
+				}
+				found++
+			}
+		}
+	}
+	return found
+}
+
+// determines reports whether every non-NULL value of from has a single value of to
+func (qc *QualityChecker) determines(ctx context.Context, from, to string) bool {
+	fdSQL := fmt.Sprintf(
+		`SELECT COUNT(*) as cnt FROM (SELECT %s FROM %s WHERE %s IS NOT NULL GROUP BY %s HAVING COUNT(DISTINCT %s) > 1) AS v`,
+		quoteIdent(from), quoteIdent(qc.tableName), quoteIdent(from), quoteIdent(from), quoteIdent(to),
+	)
+	result, err := qc.query(ctx, fdSQL)
+	if err != nil {
+		return false
+	}
+	return extractCount(result) == 0
+}
+
+// recordDependency stores "from determines to" (or a 1:1 pair) as a FieldSemantic of from
+func (qc *QualityChecker) recordDependency(from, to string, oneToOne bool) {
+	fs := &FieldSemantic{
+		TableName:  qc.tableName,
+		ColumnName: from,
+		References: qc.tableName + "." + to,
+	}
+	if oneToOne {
+		fs.StorageType = "one_to_one"
+		fs.Note = fmt.Sprintf("1:1 with %s: each %s has exactly one %s and vice versa, so JOIN or GROUP BY on either gives the same result", to, from, to)
+	} else {
+		fs.StorageType = "functional_dependency"
+		fs.Note = fmt.Sprintf("Determines %s: each %s has a single %s, so %s can be selected alongside GROUP BY %s (or grouped on both) without changing the groups", to, from, to, to, from)
+	}
+	qc.sharedCtx.SetFieldSemantic(fmt.Sprintf("%s.%s->%s", qc.tableName, from, to), fs)
+}
+
+// SetFieldSemantic stores a field semantic under key (safe for concurrent quality checkers)
+func (c *SharedContext) SetFieldSemantic(key string, fs *FieldSemantic) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.FieldSemantics == nil {
+		c.FieldSemantics = make(map[string]*FieldSemantic)
+	}
+	c.FieldSemantics[key] = fs
+}
@@ -0,0 +1,187 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// Relationship cardinalities (child rows per referenced parent)
+const (
+	CardinalityOneToOne  = "1:1"
+	CardinalityManyToOne = "N:1"
+	CardinalityUnknown   = "?"
+)
+
+// Relationship one foreign key of the schema diagram, pointing from child to parent
+type Relationship struct {
+	FromTable   string   // Referencing (child) table
+	FromColumn  string   // Foreign key column ("a, b" for composite keys)
+	ToTable     string   // Referenced (parent) table
+	ToColumn    string   // Referenced column ("x, y" for composite keys)
+	KeyColumns  []string // Foreign key columns, in key order
+	Cardinality string   // CardinalityOneToOne / CardinalityManyToOne / CardinalityUnknown
+	Optional    bool     // Some child rows have a NULL key (no parent)
+	AvgChildren float64  // Child rows per referenced value (0 = unknown)
+}
+
+// Label relationship text: key column and cardinality estimate
+func (r Relationship) Label() string {
+	switch r.Cardinality {
+	case CardinalityOneToOne:
+		return fmt.Sprintf("%s (1:1)", r.FromColumn)
+	case CardinalityManyToOne:
+		return fmt.Sprintf("%s (N:1, avg %.1f)", r.FromColumn, r.AvgChildren)
+	default:
+		return r.FromColumn
+	}
+}
+
+// Relationships foreign keys of all tables, with cardinalities estimated from value statistics
+func (c *SharedContext) Relationships() []Relationship {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.relationships()
+}
+
+// relationships foreign keys sorted by child table and column (caller holds the lock)
+func (c *SharedContext) relationships() []Relationship {
+	var rels []Relationship
+	seen := make(map[string]bool)
+	for _, table := range c.sortedTables() {
+		for _, fk := range table.ForeignKeys {
+			keyColumns := fk.KeyColumns()
+			key := strings.ToLower(table.Name + "." + strings.Join(keyColumns, ",") + "->" + fk.ReferencedTable)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			rel := Relationship{
+				FromTable:   table.Name,
+				FromColumn:  strings.Join(keyColumns, ", "),
+				ToTable:     fk.ReferencedTable,
+				ToColumn:    strings.Join(fk.ReferencedKeyColumns(), ", "),
+				KeyColumns:  keyColumns,
+				Cardinality: CardinalityUnknown,
+			}
+			for _, col := range table.Columns {
+				// Single-column stats say nothing about a composite key's distinct values
+				if !fk.IsComposite() && col.Name == fk.ColumnName && col.ValueStats != nil {
+					vs := col.ValueStats
+					rel.estimate(table.RowCount, table.RowCount-int64(vs.NullCount), int64(vs.DistinctCount))
+				}
+			}
+			rels = append(rels, rel)
+		}
+	}
+	sort.SliceStable(rels, func(i, j int) bool {
+		if rels[i].FromTable != rels[j].FromTable {
+			return rels[i].FromTable < rels[j].FromTable
+		}
+		return rels[i].FromColumn < rels[j].FromColumn
+	})
+	return rels
+}
+
+// EstimateRelationships foreign keys with cardinalities counted from the data itself
+// Keys whose count query fails keep the estimate from value statistics.
+func (c *SharedContext) EstimateRelationships(ctx context.Context, db adapter.DBAdapter) []Relationship {
+	rels := c.Relationships()
+	for i := range rels {
+		rel := &rels[i]
+		col := quoteIdent(rel.FromColumn)
+		countSQL := fmt.Sprintf(`SELECT COUNT(*) as total, COUNT(%s) as non_null, COUNT(DISTINCT %s) as distinct_values FROM %s`,
+			col, col, quoteIdent(rel.FromTable))
+		if len(rel.KeyColumns) > 1 {
+			// A composite key is present only when all of its columns are
+			var cols, notNull []string
+			for _, c := range rel.KeyColumns {
+				cols = append(cols, quoteIdent(c))
+				notNull = append(notNull, quoteIdent(c)+" IS NOT NULL")
+			}
+			countSQL = fmt.Sprintf(`SELECT (SELECT COUNT(*) FROM %[1]s) as total, (SELECT COUNT(*) FROM %[1]s WHERE %[3]s) as non_null, (SELECT COUNT(*) FROM (SELECT DISTINCT %[2]s FROM %[1]s WHERE %[3]s) d) as distinct_values`,
+				quoteIdent(rel.FromTable), strings.Join(cols, ", "), strings.Join(notNull, " AND "))
+		}
+		result, err := db.ExecuteQuery(ctx, countSQL)
+		if err != nil || result.RowCount == 0 {
+			continue
+		}
+		row := result.Rows[0]
+		rel.estimate(int64(toInt(row["total"])), int64(toInt(row["non_null"])), int64(toInt(row["distinct_values"])))
+	}
+	return rels
+}
+
+// estimate sets cardinality from the child table's row, non-NULL key and distinct key counts
+func (r *Relationship) estimate(total, nonNull, distinct int64) {
+	if distinct <= 0 {
+		return
+	}
+	r.Optional = nonNull < total
+	r.AvgChildren = float64(nonNull) / float64(distinct)
+	if nonNull == distinct {
+		r.Cardinality = CardinalityOneToOne
+	} else {
+		r.Cardinality = CardinalityManyToOne
+	}
+}
+
+// RenderDOT renders the schema and the given relationships as a Graphviz digraph
+// Tables are record nodes; edges point from the foreign key to the referenced table,
+// dashed when the key is optional, with the cardinality at both ends.
+func (c *SharedContext) RenderDOT(rels []Relationship) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("digraph %s {\n", dotQuote(c.DatabaseName)))
+	sb.WriteString("    rankdir=LR;\n")
+	sb.WriteString("    node [shape=record, fontname=\"Helvetica\", fontsize=10];\n")
+	sb.WriteString("    edge [fontname=\"Helvetica\", fontsize=9];\n\n")
+
+	for _, table := range c.sortedTables() {
+		var fields []string
+		for _, col := range table.Columns {
+			field := fmt.Sprintf("%s : %s", col.Name, simplifyType(col.Type))
+			if tags := columnKeyTags(table, col); len(tags) > 0 {
+				field += " " + strings.Join(tags, ",")
+			}
+			fields = append(fields, dotRecordEscape(field)+"\\l")
+		}
+		label := fmt.Sprintf("{%s (%d rows)|%s}", dotRecordEscape(table.Name), table.RowCount, strings.Join(fields, ""))
+		sb.WriteString(fmt.Sprintf("    %s [label=%s];\n", dotQuote(table.Name), dotQuote(label)))
+	}
+	sb.WriteString("\n")
+
+	for _, rel := range rels {
+		attrs := []string{"label=" + dotQuote(rel.Label())}
+		switch rel.Cardinality {
+		case CardinalityOneToOne:
+			attrs = append(attrs, `taillabel="1"`, `headlabel="1"`)
+		case CardinalityManyToOne:
+			attrs = append(attrs, `taillabel="N"`, `headlabel="1"`)
+		}
+		if rel.Optional {
+			attrs = append(attrs, "style=dashed")
+		}
+		sb.WriteString(fmt.Sprintf("    %s -> %s [%s];\n", dotQuote(rel.FromTable), dotQuote(rel.ToTable), strings.Join(attrs, ", ")))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotQuote DOT string literal
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// dotRecordEscape escapes characters with a meaning inside record labels
+func dotRecordEscape(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "{", `\{`, "}", `\}`, "|", `\|`, "<", `\<`, ">", `\>`)
+	return replacer.Replace(s)
+}
@@ -2,6 +2,7 @@ package context
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -18,6 +19,14 @@ type ExportOptions struct {
 	IncludeRichContext bool
 	// Include statistics
 	IncludeStats bool
+	// Columns to export per table, PK/FK columns always kept (table missing = all columns)
+	Columns map[string][]string
+	// Estimated token limit per table in the compact prompt (0 = unlimited)
+	MaxTableTokens int
+	// Enumerated values shown per column in the compact prompt (0 = default 8)
+	MaxEnumValues int
+	// Leave out expired Business Notes instead of tagging them [EXPIRED]
+	SkipExpiredNotes bool
 }
 
 // DefaultExportOptions default export options
@@ -42,6 +51,9 @@ func (c *SharedContext) ExportToPrompt(opts *ExportOptions) string {
 	// 1. Database overview
 	sb.WriteString("# Database Schema Context\n\n")
 	sb.WriteString(fmt.Sprintf("**Database**: %s (%s)\n", c.DatabaseName, c.DatabaseType))
+	if c.Summary != "" {
+		sb.WriteString(fmt.Sprintf("**Overview**: %s\n", c.Summary))
+	}
 
 	// Filter tables to export
 	tables := c.filterTables(opts.Tables)
@@ -55,7 +67,16 @@ func (c *SharedContext) ExportToPrompt(opts *ExportOptions) string {
 		}
 
 		sb.WriteString("---\n\n")
-		sb.WriteString(fmt.Sprintf("## Table: `%s`\n\n", table.Name))
+		if table.IsView {
+			sb.WriteString(fmt.Sprintf("## View: `%s` (read-only)\n\n", table.Name))
+			if table.ViewDefinition != "" {
+				sb.WriteString(fmt.Sprintf("**Definition**: `%s`\n\n", table.ViewDefinition))
+			}
+		} else {
+			sb.WriteString(fmt.Sprintf("## Table: `%s`\n\n", table.Name))
+		}
+
+		keep := opts.columnFilter(table)
 
 		// Statistics
 		if opts.IncludeStats {
@@ -76,6 +97,9 @@ func (c *SharedContext) ExportToPrompt(opts *ExportOptions) string {
 				sb.WriteString("### ⚠️ Data Quality Issues\n\n")
 				sb.WriteString("> **CRITICAL**: These issues directly affect SQL query correctness.\n\n")
 				for _, issue := range table.QualityIssues {
+					if keep != nil && issue.Column != "" && !keep[strings.ToLower(issue.Column)] {
+						continue
+					}
 					sb.WriteString(fmt.Sprintf("- **[%s] %s**: %s → Fix: `%s`\n",
 						issue.Severity, issue.Column, issue.Description, issue.SQLFix))
 				}
@@ -93,6 +117,12 @@ func (c *SharedContext) ExportToPrompt(opts *ExportOptions) string {
 						strings.HasSuffix(key, "_rowcount") || strings.HasSuffix(key, "_foreignkeys") {
 						continue
 					}
+					if note.Expired(time.Now()) {
+						if opts.SkipExpiredNotes {
+							continue
+						}
+						note.Content += " [EXPIRED]"
+					}
 					businessContext[key] = note
 				}
 
@@ -113,6 +143,9 @@ func (c *SharedContext) ExportToPrompt(opts *ExportOptions) string {
 			sb.WriteString("|--------|------|----------|---------|-----|----------|\n")
 
 			for _, col := range table.Columns {
+				if keep != nil && !keep[strings.ToLower(col.Name)] {
+					continue
+				}
 				nullable := "NO"
 				if col.Nullable {
 					nullable = "YES"
@@ -133,8 +166,19 @@ func (c *SharedContext) ExportToPrompt(opts *ExportOptions) string {
 					comment = "-"
 				}
 
+				colType := col.Type
+				if col.Collation != "" {
+					colType += " COLLATE " + col.Collation
+				}
+				if col.CaseInsensitive {
+					colType += " (case-insensitive)"
+				}
+				if col.Generated != "" {
+					colType += fmt.Sprintf(" GENERATED %s AS (%s)", strings.ToUpper(col.Generated), col.Expression)
+				}
+
 				sb.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s | %s | %s |\n",
-					col.Name, col.Type, nullable, defaultVal, key, comment))
+					col.Name, colType, nullable, defaultVal, key, comment))
 			}
 			sb.WriteString("\n")
 		}
@@ -160,8 +204,7 @@ func (c *SharedContext) ExportToPrompt(opts *ExportOptions) string {
 		if len(table.ForeignKeys) > 0 {
 			sb.WriteString("### Foreign Keys\n\n")
 			for _, fk := range table.ForeignKeys {
-				sb.WriteString(fmt.Sprintf("- `%s` → `%s.%s`\n",
-					fk.ColumnName, fk.ReferencedTable, fk.ReferencedColumn))
+				sb.WriteString(fmt.Sprintf("- `%s` → `%s`%s\n", fk.LocalRef(), fk.TargetRef(), fk.inferredNote()))
 			}
 			sb.WriteString("\n")
 		}
@@ -178,6 +221,7 @@ func (c *SharedContext) ExportTableToPrompt(tableName string) string {
 }
 
 // ExportToCompactPrompt exports as compact Prompt format (for Schema Linking)
+// With MaxTableTokens set, each table block is trimmed to fit (see compactTable).
 func (c *SharedContext) ExportToCompactPrompt(opts *ExportOptions) string {
 	if opts == nil {
 		opts = DefaultExportOptions()
@@ -185,8 +229,12 @@ func (c *SharedContext) ExportToCompactPrompt(opts *ExportOptions) string {
 
 	var sb strings.Builder
 
-	// Database info
-	sb.WriteString(fmt.Sprintf("Database: %s\n\n", c.DatabaseName))
+	// Database info, overview first so unfamiliar domains are read in context
+	sb.WriteString(fmt.Sprintf("Database: %s\n", c.DatabaseName))
+	if c.Summary != "" {
+		sb.WriteString(fmt.Sprintf("Overview: %s\n", c.Summary))
+	}
+	sb.WriteString("\n")
 
 	// Filter tables to export
 	tables := c.filterTables(opts.Tables)
@@ -196,94 +244,290 @@ func (c *SharedContext) ExportToCompactPrompt(opts *ExportOptions) string {
 		if !exists {
 			continue
 		}
+		sb.WriteString(c.compactTable(table, opts))
+		sb.WriteString("\n")
+	}
 
-		// Table name and row count
-		sb.WriteString(fmt.Sprintf("Table %s (%d rows):\n", table.Name, table.RowCount))
+	return sb.String()
+}
 
-		// Column info (compact format with inline value stats)
-		if opts.IncludeColumns {
-			for _, col := range table.Columns {
-				pk := ""
-				if col.IsPrimaryKey {
-					pk = " [PK]"
-				}
-				// Check if foreign key
-				fkInfo := ""
-				for _, fk := range table.ForeignKeys {
-					if fk.ColumnName == col.Name {
-						fkInfo = fmt.Sprintf(" → %s.%s", fk.ReferencedTable, fk.ReferencedColumn)
-						break
-					}
-				}
+// Compact table trim levels, applied in order until the block fits MaxTableTokens
+const (
+	trimNone     = iota
+	trimSamples  // Sample rows dropped
+	trimNotes    // Business notes dropped
+	trimIssues   // Only critical quality issues kept
+	trimStats    // Inline value statistics dropped
+	trimComments // Column comments dropped
+)
 
-				// Inline value stats annotation
-				statsInfo := ""
-				if col.ValueStats != nil {
-					vs := col.ValueStats
-					if vs.DistinctCount > 0 && vs.DistinctCount <= 15 && len(vs.TopValues) > 0 {
-						// Compact enum display
-						vals := make([]string, 0, len(vs.TopValues))
-						for _, tv := range vs.TopValues {
-							if len(vals) >= 8 {
-								vals = append(vals, "...")
-								break
-							}
-							vals = append(vals, fmt.Sprintf("%s(%d)", tv.Value, tv.Count))
-						}
-						statsInfo = fmt.Sprintf(" values=[%s]", strings.Join(vals, ", "))
-					} else if vs.Range != nil {
-						statsInfo = fmt.Sprintf(" range=[%.0f..%.0f]", vs.Range.Min, vs.Range.Max)
-					}
-				}
+// defaultEnumValues enumerated values shown per column when MaxEnumValues is unset
+const defaultEnumValues = 8
+
+// compactTable one table of the compact prompt, trimmed to opts.MaxTableTokens
+// Trimming is deterministic, least useful first: sample rows, business notes, non-critical
+// quality issues, value statistics, column comments, then non-key columns from the end of
+// the table (PK and FK columns are never dropped).
+func (c *SharedContext) compactTable(table *TableMetadata, opts *ExportOptions) string {
+	keep := opts.columnFilter(table)
+	var columns []ColumnMetadata
+	for _, col := range table.Columns {
+		if keep == nil || keep[strings.ToLower(col.Name)] {
+			columns = append(columns, col)
+		}
+	}
+
+	units := c.columnUnits(table.Name)
+	block := renderCompactTable(table, columns, units, 0, opts, trimNone)
+	if opts.MaxTableTokens <= 0 || estimateTokens(block) <= opts.MaxTableTokens {
+		return block
+	}
+	for level := trimSamples; level <= trimComments; level++ {
+		block = renderCompactTable(table, columns, units, 0, opts, level)
+		if estimateTokens(block) <= opts.MaxTableTokens {
+			return block
+		}
+	}
+	if !opts.IncludeColumns {
+		return block
+	}
 
-				sb.WriteString(fmt.Sprintf("  - %s: %s%s%s%s\n", col.Name, col.Type, pk, fkInfo, statsInfo))
+	keys := make(map[string]bool)
+	for _, fk := range table.ForeignKeys {
+		for _, col := range fk.KeyColumns() {
+			keys[strings.ToLower(col)] = true
+		}
+	}
+	for omitted := 0; omitted < len(columns); {
+		// Drop the last non-key column
+		last := -1
+		for i := len(columns) - 1; i >= 0; i-- {
+			if !columns[i].IsPrimaryKey && !keys[strings.ToLower(columns[i].Name)] {
+				last = i
+				break
 			}
 		}
+		if last < 0 {
+			break
+		}
+		columns = append(columns[:last:last], columns[last+1:]...)
+		omitted++
+		block = renderCompactTable(table, columns, units, omitted, opts, trimComments)
+		if estimateTokens(block) <= opts.MaxTableTokens {
+			break
+		}
+	}
+	return block
+}
 
-		// Structured quality issues (from deterministic checker)
-		if opts.IncludeRichContext && len(table.QualityIssues) > 0 {
-			sb.WriteString("  ⚠️ Data Quality Issues:\n")
-			for _, issue := range table.QualityIssues {
-				sb.WriteString(fmt.Sprintf("    * [%s] %s.%s: %s → Fix: %s\n",
-					issue.Severity, issue.Table, issue.Column, issue.Description, issue.SQLFix))
+// renderCompactTable writes a table block: header, column lines, sample rows, quality issues and notes
+func renderCompactTable(table *TableMetadata, columns []ColumnMetadata, units map[string]string, omitted int, opts *ExportOptions, level int) string {
+	var sb strings.Builder
+
+	// Table name and row count; views are flagged so the generator never writes to them
+	if table.IsView {
+		sb.WriteString(fmt.Sprintf("Table %s (%d rows, read-only view):\n", table.Name, table.RowCount))
+		if table.ViewDefinition != "" && level < trimComments {
+			sb.WriteString(fmt.Sprintf("  View of: %s\n", truncateComment(table.ViewDefinition, maxViewDefinition)))
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("Table %s (%d rows):\n", table.Name, table.RowCount))
+	}
+
+	shown := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		shown[strings.ToLower(col.Name)] = true
+	}
+
+	// Column info (compact format with inline value stats)
+	if opts.IncludeColumns {
+		for _, col := range columns {
+			sb.WriteString(compactColumn(table, col, units[strings.ToLower(col.Name)], opts, level))
+		}
+		if omitted > 0 {
+			sb.WriteString(fmt.Sprintf("  ... (%d more columns not shown)\n", omitted))
+		}
+		if level < trimStats {
+			sb.WriteString(indexHint(table))
+		}
+		if table.Samples != nil && level < trimSamples {
+			sb.WriteString(compactSamples(table.Samples, shown))
+		}
+	}
+
+	// Structured quality issues (from deterministic checker)
+	if opts.IncludeRichContext && len(table.QualityIssues) > 0 {
+		var issues []string
+		for _, issue := range table.QualityIssues {
+			// Issues of hidden columns are left out; multi-column ones ("a, b") stay
+			hidden := len(table.Columns) > 0 && !shown[strings.ToLower(issue.Column)] && !strings.Contains(issue.Column, ",")
+			if issue.Column != "" && hidden {
+				continue
+			}
+			if level >= trimIssues && issue.Severity != "critical" {
+				continue
 			}
+			issues = append(issues, fmt.Sprintf("    * [%s] %s.%s: %s → Fix: %s\n",
+				issue.Severity, issue.Table, issue.Column, issue.Description, issue.SQLFix))
 		}
+		if len(issues) > 0 {
+			sb.WriteString("  ⚠️ Data Quality Issues:\n")
+			sb.WriteString(strings.Join(issues, ""))
+		}
+	}
 
-		// Rich Context (LLM-generated business notes only — quality issues already shown above)
-		if opts.IncludeRichContext && len(table.RichContext) > 0 {
-			// Filter: only show business notes, skip old quality_issue keys
-			businessNotes := make(map[string]RichContextValue)
-			for key, note := range table.RichContext {
-				if strings.Contains(key, "quality_issue") || strings.Contains(key, "orphan_issue") {
-					continue // skip — now handled by structured QualityIssues
+	// Rich Context (LLM-generated business notes only — quality issues already shown above)
+	if opts.IncludeRichContext && len(table.RichContext) > 0 && level < trimNotes {
+		// Filter: only show business notes, skip old quality_issue keys
+		var keys []string
+		for key := range table.RichContext {
+			if strings.Contains(key, "quality_issue") || strings.Contains(key, "orphan_issue") {
+				continue // skip — now handled by structured QualityIssues
+			}
+			// Skip metadata keys that duplicate column/index info
+			if strings.HasSuffix(key, "_columns") || strings.HasSuffix(key, "_indexes") ||
+				strings.HasSuffix(key, "_rowcount") || strings.HasSuffix(key, "_foreignkeys") {
+				continue
+			}
+			if opts.SkipExpiredNotes && table.RichContext[key].Expired(time.Now()) {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		if len(keys) > 0 {
+			sb.WriteString("  Business Notes:\n")
+			for _, key := range keys {
+				note := table.RichContext[key]
+				expiredTag := ""
+				if note.Expired(time.Now()) {
+					expiredTag = " [EXPIRED]"
 				}
-				// Skip metadata keys that duplicate column/index info
-				if strings.HasSuffix(key, "_columns") || strings.HasSuffix(key, "_indexes") ||
-					strings.HasSuffix(key, "_rowcount") || strings.HasSuffix(key, "_foreignkeys") {
-					continue
+				sb.WriteString(fmt.Sprintf("    * %s: %s%s\n", formatKey(key), note.Content, expiredTag))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// compactSamples sample rows as a pipe table, restricted to the shown columns
+func compactSamples(samples *TableSamples, shown map[string]bool) string {
+	var indexes []int
+	var header []string
+	for i, col := range samples.Columns {
+		if shown[strings.ToLower(col)] {
+			indexes = append(indexes, i)
+			header = append(header, col)
+		}
+	}
+	if len(indexes) == 0 || len(samples.Rows) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("  Sample rows:\n")
+	sb.WriteString("    " + strings.Join(header, " | ") + "\n")
+	for _, row := range samples.Rows {
+		values := make([]string, 0, len(indexes))
+		for _, i := range indexes {
+			if i < len(row) {
+				values = append(values, row[i])
+			}
+		}
+		sb.WriteString("    " + strings.Join(values, " | ") + "\n")
+	}
+	return sb.String()
+}
+
+// compactColumn one column line: type, keys, collation, unit, comment and value stats
+func compactColumn(table *TableMetadata, col ColumnMetadata, unit string, opts *ExportOptions, level int) string {
+	pk := ""
+	if col.IsPrimaryKey {
+		pk = " [PK]"
+	}
+	// Check if foreign key
+	fkInfo := ""
+	for _, fk := range table.ForeignKeys {
+		refColumns := fk.ReferencedKeyColumns()
+		for i, keyCol := range fk.KeyColumns() {
+			if keyCol == col.Name {
+				fkInfo = fmt.Sprintf(" → %s.%s%s", fk.ReferencedTable, refColumns[i], fk.inferredNote())
+				if fk.IsComposite() {
+					fkInfo += fmt.Sprintf(" (composite key %s, join on all)", fk.LocalRef())
 				}
-				businessNotes[key] = note
 			}
+		}
+		if fkInfo != "" {
+			break
+		}
+	}
 
-			if len(businessNotes) > 0 {
-				sb.WriteString("  Business Notes:\n")
-				for key, note := range businessNotes {
-					expiredTag := ""
-					if note.ExpiresAt != "" {
-						expiresAt, err := time.Parse(time.RFC3339, note.ExpiresAt)
-						if err == nil && time.Now().After(expiresAt) {
-							expiredTag = " [EXPIRED]"
-						}
-					}
-					sb.WriteString(fmt.Sprintf("    * %s: %s%s\n", formatKey(key), note.Content, expiredTag))
+	// Inline value stats annotation
+	statsInfo := ""
+	if col.ValueStats != nil && level < trimStats {
+		vs := col.ValueStats
+		maxValues := opts.MaxEnumValues
+		if maxValues <= 0 {
+			maxValues = defaultEnumValues
+		}
+		if vs.DistinctCount > 0 && vs.DistinctCount <= 15 && len(vs.TopValues) > 0 {
+			// Compact enum display
+			vals := make([]string, 0, len(vs.TopValues))
+			for _, tv := range vs.TopValues {
+				if len(vals) >= maxValues {
+					vals = append(vals, "...")
+					break
+				}
+				value := tv.Value
+				if strings.TrimSpace(value) != value {
+					// Quoted so the surrounding whitespace of the stored literal is visible
+					value = "'" + value + "'"
 				}
+				vals = append(vals, fmt.Sprintf("%s(%d)", value, tv.Count))
 			}
+			statsInfo = fmt.Sprintf(" values=[%s]", strings.Join(vals, ", "))
+		} else if vs.Dates != nil {
+			statsInfo = dateSpan(vs)
+		} else if vs.Range != nil {
+			statsInfo = fmt.Sprintf(" range=[%s..%s]", rangeBound(vs.Range.Min), rangeBound(vs.Range.Max))
 		}
+	}
 
-		sb.WriteString("\n")
+	// Computed column: readable like any other, but derived from the expression
+	genInfo := ""
+	if col.Generated != "" {
+		genInfo = " [computed]"
+		if col.Expression != "" {
+			genInfo = fmt.Sprintf(" [computed: %s]", truncateComment(col.Expression, maxCompactComment))
+		}
 	}
 
-	return sb.String()
+	// '=' semantics on text: tells the LLM whether 'usa' matches 'USA'
+	ciInfo := ""
+	if col.CaseInsensitive {
+		ciInfo = " [case-insensitive]"
+	}
+
+	// Detected unit and scale (kept at every trim level: formulas hinge on it)
+	unitInfo := ""
+	if unit != "" {
+		unitInfo = fmt.Sprintf(" [unit: %s]", unit)
+	}
+
+	// Column comment (DDL, BIRD descriptions); before the stats, which trimming cuts from the end
+	commentInfo := ""
+	if col.Comment != "" && level < trimComments {
+		commentInfo = fmt.Sprintf(" /* %s */", truncateComment(col.Comment, maxCompactComment))
+	}
+
+	return fmt.Sprintf("  - %s: %s%s%s%s%s%s%s%s\n", col.Name, col.Type, pk, fkInfo, genInfo, ciInfo, unitInfo, commentInfo, statsInfo)
+}
+
+// estimateTokens rough token count of prompt text (~4 characters per token)
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
 }
 
 // ExportToSchemaLinking exports as Schema Linking format (most compact)
@@ -326,6 +570,8 @@ func (c *SharedContext) ExportToSchemaLinking(tableNames []string) string {
 // focusing on issues that affect cross-table JOINs and commonly-misused columns.
 // selectedTables controls which tables get per-table detail; issues from other tables
 // that reference a selected table (e.g., orphan FK) are also included.
+// Foreign keys measured by AnalyzeJoinQuality that touch a selected table add quantified
+// join guidance (dropped rows, fan-out, childless rows) in place of their orphan issue.
 func (c *SharedContext) BuildCrossTableQualitySummary(selectedTables []string) string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -335,6 +581,28 @@ func (c *SharedContext) BuildCrossTableQualitySummary(selectedTables []string) s
 		selected[t] = true
 	}
 
+	// Measured joins touching a selected table (all joins without a selection)
+	tableNames := make([]string, 0, len(c.Tables))
+	for name := range c.Tables {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+	var joinLines []string
+	measuredJoins := make(map[string]bool) // "table.column" of keys with a join line
+	for _, tableName := range tableNames {
+		for _, fk := range c.Tables[tableName].ForeignKeys {
+			if len(selected) > 0 && !selected[tableName] && !selected[fk.ReferencedTable] {
+				continue
+			}
+			if line := joinQualityGuidance(tableName, fk); line != "" {
+				joinLines = append(joinLines, line)
+				for _, col := range fk.KeyColumns() {
+					measuredJoins[tableName+"."+col] = true
+				}
+			}
+		}
+	}
+
 	// Collect cross-table-relevant issues from ALL tables
 	type issueEntry struct {
 		table string
@@ -344,8 +612,11 @@ func (c *SharedContext) BuildCrossTableQualitySummary(selectedTables []string) s
 
 	for tableName, table := range c.Tables {
 		for _, qi := range table.QualityIssues {
-			// Always include orphan issues (they affect JOINs)
+			// Always include orphan issues (they affect JOINs), unless a join line covers them
 			if qi.Type == "orphan" {
+				if measuredJoins[tableName+"."+qi.Column] {
+					continue
+				}
 				crossIssues = append(crossIssues, issueEntry{tableName, qi})
 				continue
 			}
@@ -368,7 +639,7 @@ func (c *SharedContext) BuildCrossTableQualitySummary(selectedTables []string) s
 		}
 	}
 
-	if len(crossIssues) == 0 {
+	if len(crossIssues) == 0 && len(joinLines) == 0 {
 		return ""
 	}
 
@@ -378,9 +649,47 @@ func (c *SharedContext) BuildCrossTableQualitySummary(selectedTables []string) s
 		sb.WriteString(fmt.Sprintf("- %s.%s: %s → %s\n",
 			entry.table, entry.issue.Column, entry.issue.Description, entry.issue.SQLFix))
 	}
+	for _, line := range joinLines {
+		sb.WriteString(line)
+	}
 	return sb.String()
 }
 
+// columnFilter returns the lower-cased columns of a table to export (nil = all)
+// PK and FK columns are always kept so JOINs stay writable; a selection naming no existing column is ignored.
+func (opts *ExportOptions) columnFilter(table *TableMetadata) map[string]bool {
+	selected, ok := opts.Columns[table.Name]
+	if !ok || len(selected) == 0 {
+		return nil
+	}
+
+	existing := make(map[string]bool, len(table.Columns))
+	for _, col := range table.Columns {
+		existing[strings.ToLower(col.Name)] = true
+	}
+	keep := make(map[string]bool)
+	for _, name := range selected {
+		if existing[strings.ToLower(name)] {
+			keep[strings.ToLower(name)] = true
+		}
+	}
+	if len(keep) == 0 {
+		return nil
+	}
+
+	for _, col := range table.Columns {
+		if col.IsPrimaryKey {
+			keep[strings.ToLower(col.Name)] = true
+		}
+	}
+	for _, fk := range table.ForeignKeys {
+		for _, col := range fk.KeyColumns() {
+			keep[strings.ToLower(col)] = true
+		}
+	}
+	return keep
+}
+
 // filterTables filters tables to export
 func (c *SharedContext) filterTables(tableNames []string) []string {
 	c.mu.RLock()
@@ -392,6 +701,7 @@ func (c *SharedContext) filterTables(tableNames []string) []string {
 		for name := range c.Tables {
 			result = append(result, name)
 		}
+		sort.Strings(result) // Stable prompts across runs
 		return result
 	}
 
@@ -404,3 +714,46 @@ func (c *SharedContext) filterTables(tableNames []string) []string {
 	}
 	return result
 }
+
+// maxCompactComment column comment length kept in the compact prompt
+const maxCompactComment = 160
+
+// maxViewDefinition view definition length kept in the compact prompt
+const maxViewDefinition = 300
+
+// truncateComment shortens a comment to maxLen characters on a word boundary
+func truncateComment(comment string, maxLen int) string {
+	comment = strings.ReplaceAll(comment, "*/", "* /")
+	runes := []rune(comment)
+	if len(runes) <= maxLen {
+		return comment
+	}
+	cut := string(runes[:maxLen])
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return cut + "..."
+}
+
+// dateSpan compact annotation of a temporal column: " spans 2018-01..2023-12", plus the
+// histogram buckets holding no rows, so the model does not filter on years absent from the data
+func dateSpan(vs *ValueStats) string {
+	month := func(date string) string {
+		if len(date) >= 7 {
+			return date[:7]
+		}
+		return date
+	}
+	span := fmt.Sprintf(" spans %s..%s", month(vs.Dates.Min), month(vs.Dates.Max))
+
+	var empty []string
+	for _, bucket := range vs.Histogram {
+		if bucket.Count == 0 {
+			empty = append(empty, bucket.Label)
+		}
+	}
+	if len(empty) > 0 {
+		span += fmt.Sprintf(" (no rows in %s)", strings.Join(empty, ", "))
+	}
+	return span
+}
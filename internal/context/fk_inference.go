@@ -0,0 +1,278 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+const (
+	// inferredFKMinConfidence share of a column's distinct values that must exist in the
+	// referenced key before the pair is recorded as a foreign key
+	inferredFKMinConfidence = 0.9
+	// inferredFKMinValues distinct values a column needs before its overlap is judged
+	inferredFKMinValues = 2
+	// inferredFKMaxValues distinct values compared per column (keeps huge tables cheap)
+	inferredFKMaxValues = 10000
+)
+
+// fkCandidate a column pair that may be an undeclared foreign key
+type fkCandidate struct {
+	table, column       string
+	refTable, refColumn string
+	exactName           bool // Same column name on both sides
+}
+
+// InferForeignKeys records undeclared foreign keys found by value overlap
+// Candidates pair a column with the single-column primary key of another table when the
+// names link them (singer_id → singer.singer_id, CountryCode → country.code, StudentID →
+// student.id) and the types agree. A pair is kept when at least 90% of the column's distinct
+// values exist in the key; that share is its Confidence. Each column gets at most one
+// inferred key, and columns with a declared key are left alone. Tables sharing a primary
+// key are linked in one direction only (see keepsDirection). Run it before
+// AnalyzeJoinPaths so join paths use the inferred keys. Returns the number recorded.
+func (c *SharedContext) InferForeignKeys(ctx context.Context, dbAdapter adapter.DBAdapter) (int, error) {
+	candidates := c.foreignKeyCandidates()
+
+	type inferred struct {
+		table string
+		fk    ForeignKeyMetadata
+		exact bool
+	}
+	best := make(map[string]inferred) // table.column -> best key
+	for _, cand := range candidates {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		confidence, ok := valueOverlap(ctx, dbAdapter, cand)
+		if !ok || confidence < inferredFKMinConfidence {
+			continue
+		}
+		key := cand.table + "." + cand.column
+		if prev, seen := best[key]; seen && (prev.fk.Confidence > confidence || prev.fk.Confidence == confidence && prev.exact) {
+			continue
+		}
+		fk := newForeignKey([]string{cand.column}, cand.refTable, []string{cand.refColumn})
+		fk.Inferred = true
+		fk.Confidence = confidence
+		best[key] = inferred{table: cand.table, fk: fk, exact: cand.exactName}
+	}
+
+	// Tables sharing a primary key (student.student_id, student_detail.student_id) match in
+	// both directions; keep one so join paths see no cycle
+	for key, entry := range best {
+		reverse, mutual := best[entry.fk.ReferencedTable+"."+entry.fk.ReferencedColumn]
+		if !mutual || reverse.fk.ReferencedTable != entry.table || reverse.fk.ReferencedColumn != entry.fk.ColumnName {
+			continue
+		}
+		if !c.keepsDirection(entry.table, entry.fk.Confidence, reverse.table, reverse.fk.Confidence) {
+			delete(best, key)
+		}
+	}
+
+	keys := make([]string, 0, len(best))
+	for key := range best {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	recorded := 0
+	for _, key := range keys {
+		fk := best[key].fk
+		table := c.Tables[best[key].table]
+		if referencesBack(c.Tables[fk.ReferencedTable], fk.ReferencedColumn, table.Name, fk.ColumnName) {
+			continue // A declared key already links the pair the other way
+		}
+		recorded++
+		table.ForeignKeys = append(table.ForeignKeys, fk)
+		if !c.Quiet {
+			fmt.Printf("[Context] Inferred foreign key %s.%s → %s (%.0f%% of values match)\n",
+				table.Name, fk.ColumnName, fk.TargetRef(), fk.Confidence*100)
+		}
+	}
+	return recorded, nil
+}
+
+// keepsDirection reports whether table → other is kept over the mutual other → table
+// The direction whose values all exist on the other side wins, then the smaller table
+// (the detail table refers to the main one), then the later name (student_detail → student).
+func (c *SharedContext) keepsDirection(table string, confidence float64, other string, otherConfidence float64) bool {
+	if confidence != otherConfidence {
+		return confidence > otherConfidence
+	}
+	c.mu.RLock()
+	rows, otherRows := c.Tables[table].RowCount, c.Tables[other].RowCount
+	c.mu.RUnlock()
+	if rows != otherRows {
+		return rows < otherRows
+	}
+	return table > other
+}
+
+// referencesBack reports whether table has a key from column to refTable.refColumn
+func referencesBack(table *TableMetadata, column, refTable, refColumn string) bool {
+	if table == nil {
+		return false
+	}
+	for _, fk := range table.ForeignKeys {
+		if !fk.IsComposite() && strings.EqualFold(fk.ColumnName, column) &&
+			strings.EqualFold(fk.ReferencedTable, refTable) &&
+			(fk.ReferencedColumn == "" || strings.EqualFold(fk.ReferencedColumn, refColumn)) {
+			return true
+		}
+	}
+	return false
+}
+
+// foreignKeyCandidates column pairs linked by name and type, in table / column order
+func (c *SharedContext) foreignKeyCandidates() []fkCandidate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	type key struct {
+		table  string
+		column ColumnMetadata
+	}
+	var refs []key
+	for name, table := range c.Tables {
+		if pk := primaryKeyNames(table); len(pk) == 1 {
+			for _, col := range table.Columns {
+				if col.Name == pk[0] {
+					refs = append(refs, key{name, col})
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(c.Tables))
+	for name := range c.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var candidates []fkCandidate
+	for _, name := range names {
+		table := c.Tables[name]
+		pk := primaryKeyNames(table)
+		for _, col := range table.Columns {
+			if isForeignKeyColumn(table, col.Name) || len(pk) == 1 && pk[0] == col.Name && isBareID(col.Name) {
+				continue
+			}
+			for _, ref := range refs {
+				if ref.table == name && ref.column.Name == col.Name {
+					continue
+				}
+				if !typesCompatible(col.Type, ref.column.Type) {
+					continue
+				}
+				exact, linked := namesLink(col.Name, ref.table, ref.column.Name)
+				if !linked {
+					continue
+				}
+				candidates = append(candidates, fkCandidate{
+					table: name, column: col.Name,
+					refTable: ref.table, refColumn: ref.column.Name,
+					exactName: exact,
+				})
+			}
+		}
+	}
+	return candidates
+}
+
+// valueOverlap share of the column's distinct values found in the referenced key
+// (false when the query fails or the column has too few values to judge)
+func valueOverlap(ctx context.Context, dbAdapter adapter.DBAdapter, cand fkCandidate) (float64, bool) {
+	col := quoteIdent(cand.column)
+	sql := fmt.Sprintf(
+		`SELECT COUNT(*) as total, COUNT(p.%s) as matched FROM (SELECT DISTINCT %s as v FROM %s WHERE %s IS NOT NULL LIMIT %d) c LEFT JOIN %s p ON c.v = p.%s`,
+		quoteIdent(cand.refColumn), col, quoteIdent(cand.table), col, inferredFKMaxValues,
+		quoteIdent(cand.refTable), quoteIdent(cand.refColumn),
+	)
+	result, err := dbAdapter.ExecuteQuery(ctx, sql)
+	if err != nil || result.Error != "" || result.RowCount == 0 {
+		return 0, false
+	}
+	total, matched := toInt(result.Rows[0]["total"]), toInt(result.Rows[0]["matched"])
+	if total < inferredFKMinValues {
+		return 0, false
+	}
+	return float64(matched) / float64(total), true
+}
+
+// namesLink reports whether a column name points at refTable.refColumn, and whether the
+// id-like names are equal. Case, underscores and spaces are ignored; refTable may be plural.
+func namesLink(column, refTable, refColumn string) (exact, linked bool) {
+	local, ref := squashName(column), squashName(refColumn)
+	table := squashName(refTable)
+	stems := []string{table, strings.TrimSuffix(table, "s")}
+
+	if local == ref && !isBareID(refColumn) && hasKeySuffix(ref) {
+		return true, true
+	}
+	for _, stem := range stems {
+		if stem == "" {
+			continue
+		}
+		// country.code ← country_code; student.id ← student_id
+		if local == stem+ref {
+			return false, true
+		}
+		// student.stuid ← student_id (id-like suffix after the table name)
+		for _, suffix := range keySuffixes {
+			if local == stem+suffix && strings.HasSuffix(ref, suffix) {
+				return false, true
+			}
+		}
+	}
+	return false, false
+}
+
+// keySuffixes endings of id-like column names (squashed)
+var keySuffixes = []string{"id", "code", "key", "no"}
+
+// hasKeySuffix reports whether a squashed column name is id-like
+func hasKeySuffix(name string) bool {
+	for _, suffix := range keySuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// squashName lowercases a name and drops underscores, spaces and dashes
+func squashName(name string) string {
+	return strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// isBareID reports whether a column is just named id
+func isBareID(name string) bool {
+	return squashName(name) == "id"
+}
+
+// typesCompatible reports whether two declared types can hold the same keys
+// Untyped columns (SQLite) match anything.
+func typesCompatible(a, b string) bool {
+	fa, fb := typeFamily(a), typeFamily(b)
+	return fa == "" || fb == "" || fa == fb
+}
+
+// typeFamily "text", "number" or "" (unknown / untyped)
+func typeFamily(colType string) string {
+	upper := strings.ToUpper(colType)
+	if isTextType(upper) {
+		return "text"
+	}
+	for _, numeric := range []string{"INT", "NUM", "DEC", "REAL", "FLOAT", "DOUBLE"} {
+		if strings.Contains(upper, numeric) {
+			return "number"
+		}
+	}
+	return ""
+}
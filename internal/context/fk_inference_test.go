@@ -0,0 +1,78 @@
+package context
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"reactsql/internal/adapter"
+)
+
+// sharedKeyContext student and student_detail, both keyed by student_id, with the given ids
+func sharedKeyContext(t *testing.T, students, details []int) (*SharedContext, adapter.DBAdapter) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "school.sqlite")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	c := NewSharedContext("school", "sqlite")
+	c.Quiet = true
+	for name, ids := range map[string][]int{"student": students, "student_detail": details} {
+		if _, err := db.Exec(`CREATE TABLE ` + name + ` (student_id INTEGER PRIMARY KEY, note TEXT)`); err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range ids {
+			if _, err := db.Exec(`INSERT INTO `+name+` VALUES (?, 'x')`, id); err != nil {
+				t.Fatal(err)
+			}
+		}
+		c.Tables[name] = &TableMetadata{
+			Name:     name,
+			RowCount: int64(len(ids)),
+			Columns: []ColumnMetadata{
+				{Name: "student_id", Type: "INTEGER", IsPrimaryKey: true},
+				{Name: "note", Type: "TEXT", Nullable: true},
+			},
+		}
+	}
+
+	dbAdapter := adapter.NewSQLiteAdapter(&adapter.SQLiteConfig{FilePath: path})
+	if err := dbAdapter.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbAdapter.Close() })
+	return c, dbAdapter
+}
+
+func TestInferForeignKeysSharedPrimaryKey(t *testing.T) {
+	cases := []struct {
+		name              string
+		students, details []int
+	}{
+		{"detail covers 90% of students", []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"same rows", []int{1, 2, 3}, []int{1, 2, 3}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, dbAdapter := sharedKeyContext(t, tc.students, tc.details)
+			inferred, err := c.InferForeignKeys(context.Background(), dbAdapter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if inferred != 1 {
+				t.Fatalf("inferred %d keys, want 1", inferred)
+			}
+			if fks := c.Tables["student"].ForeignKeys; len(fks) != 0 {
+				t.Errorf("student got key %s → %s, want none", fks[0].ColumnName, fks[0].TargetRef())
+			}
+			fks := c.Tables["student_detail"].ForeignKeys
+			if len(fks) != 1 || fks[0].TargetRef() != "student.student_id" {
+				t.Errorf("student_detail keys = %+v, want student_id → student.student_id", fks)
+			}
+		})
+	}
+}
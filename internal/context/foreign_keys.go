@@ -0,0 +1,147 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// newForeignKey a foreign key over the given column pairs; Columns / ReferencedColumns are
+// only filled for composite keys
+func newForeignKey(columns []string, refTable string, refColumns []string) ForeignKeyMetadata {
+	fk := ForeignKeyMetadata{ReferencedTable: refTable}
+	if len(columns) > 0 {
+		fk.ColumnName = columns[0]
+	}
+	if len(refColumns) > 0 {
+		fk.ReferencedColumn = refColumns[0]
+	}
+	if len(columns) > 1 {
+		fk.Columns = columns
+		fk.ReferencedColumns = refColumns
+	}
+	return fk
+}
+
+// KeyColumns local columns of the key in key order (one for single-column keys)
+func (fk ForeignKeyMetadata) KeyColumns() []string {
+	if len(fk.Columns) > 0 {
+		return fk.Columns
+	}
+	return []string{fk.ColumnName}
+}
+
+// ReferencedKeyColumns referenced columns matching KeyColumns ("" where the declaration
+// leaves them out and the referenced primary key is meant)
+func (fk ForeignKeyMetadata) ReferencedKeyColumns() []string {
+	refs := make([]string, len(fk.KeyColumns()))
+	if len(fk.Columns) > 0 {
+		copy(refs, fk.ReferencedColumns)
+	} else {
+		refs[0] = fk.ReferencedColumn
+	}
+	return refs
+}
+
+// IsComposite reports whether the key spans several columns
+func (fk ForeignKeyMetadata) IsComposite() bool {
+	return len(fk.Columns) > 1
+}
+
+// HasColumn reports whether a local column is part of the key
+func (fk ForeignKeyMetadata) HasColumn(colName string) bool {
+	for _, col := range fk.KeyColumns() {
+		if strings.EqualFold(col, colName) {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalRef the local side for display: "col" or "(col1, col2)"
+func (fk ForeignKeyMetadata) LocalRef() string {
+	if fk.IsComposite() {
+		return "(" + strings.Join(fk.Columns, ", ") + ")"
+	}
+	return fk.ColumnName
+}
+
+// TargetRef the referenced side for display: "table.col" or "table(col1, col2)"
+func (fk ForeignKeyMetadata) TargetRef() string {
+	if fk.IsComposite() {
+		return fmt.Sprintf("%s(%s)", fk.ReferencedTable, strings.Join(fk.ReferencedKeyColumns(), ", "))
+	}
+	return fk.ReferencedTable + "." + fk.ReferencedColumn
+}
+
+// inferredNote display suffix of an inferred key: " (inferred, 97% match)"; "" for declared keys
+func (fk ForeignKeyMetadata) inferredNote() string {
+	if !fk.Inferred {
+		return ""
+	}
+	return fmt.Sprintf(" (inferred, %.0f%% match)", fk.Confidence*100)
+}
+
+// foreignKeysFromInfo converts adapter introspection FKs to ForeignKeyMetadata
+// Adapters return one row per column; rows of the same constraint become one composite key.
+func foreignKeysFromInfo(fks []adapter.ForeignKeyInfo) []ForeignKeyMetadata {
+	result := make([]ForeignKeyMetadata, 0, len(fks))
+	for i := 0; i < len(fks); {
+		columns := []string{fks[i].ColumnName}
+		refColumns := []string{fks[i].ReferencedColumn}
+		j := i + 1
+		for ; j < len(fks); j++ {
+			if fks[i].Name == "" || fks[j].Name != fks[i].Name || fks[j].ReferencedTable != fks[i].ReferencedTable {
+				break
+			}
+			columns = append(columns, fks[j].ColumnName)
+			refColumns = append(refColumns, fks[j].ReferencedColumn)
+		}
+		result = append(result, newForeignKey(columns, fks[i].ReferencedTable, refColumns))
+		i = j
+	}
+	return result
+}
+
+// sameKey reports whether two keys link the same local columns to the same table
+// (names compared case-insensitively; a side leaving the referenced columns implicit
+// still matches)
+func (fk ForeignKeyMetadata) sameKey(other ForeignKeyMetadata) bool {
+	if !strings.EqualFold(fk.ReferencedTable, other.ReferencedTable) ||
+		!strings.EqualFold(strings.Join(fk.KeyColumns(), ","), strings.Join(other.KeyColumns(), ",")) {
+		return false
+	}
+	refs, otherRefs := fk.ReferencedKeyColumns(), other.ReferencedKeyColumns()
+	for i := range refs {
+		if refs[i] != "" && otherRefs[i] != "" && !strings.EqualFold(refs[i], otherRefs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeForeignKeys the introspected keys followed by the loaded keys (schema.sql,
+// tables.json) they do not already cover
+// Where both describe a key, the introspected one is kept, taking the referenced columns
+// from the loaded one when the declaration leaves them implicit.
+func mergeForeignKeys(loaded, introspected []ForeignKeyMetadata) []ForeignKeyMetadata {
+	merged := append([]ForeignKeyMetadata(nil), introspected...)
+	for _, fk := range loaded {
+		covered := false
+		for i := range merged {
+			if !merged[i].sameKey(fk) {
+				continue
+			}
+			covered = true
+			if merged[i].ReferencedKeyColumns()[0] == "" && fk.ReferencedKeyColumns()[0] != "" {
+				merged[i] = newForeignKey(merged[i].KeyColumns(), merged[i].ReferencedTable, fk.ReferencedKeyColumns())
+			}
+			break
+		}
+		if !covered {
+			merged = append(merged, fk)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,160 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// maxPromptGlossaryTerms terms FormatGlossaryPrompt writes (the rest are left out)
+const maxPromptGlossaryTerms = 40
+
+// GlossaryTerm business term of the database's domain ("ARR", "churn")
+type GlossaryTerm struct {
+	Term       string   `json:"term"`
+	Definition string   `json:"definition"`
+	Columns    []string `json:"columns,omitempty"` // "table.column" the term maps to
+}
+
+// SetGlossaryTerm adds or replaces a term (matched case-insensitively); columns are
+// "table.column" names, checked by ResolveGlossaryColumns once the tables are known
+func (c *SharedContext) SetGlossaryTerm(term, definition string, columns []string) {
+	term = strings.Join(strings.Fields(term), " ")
+	definition = strings.TrimSpace(definition)
+	if term == "" || definition == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := GlossaryTerm{Term: term, Definition: definition, Columns: columns}
+	for i, existing := range c.Glossary {
+		if strings.EqualFold(existing.Term, term) {
+			c.Glossary[i] = entry
+			return
+		}
+	}
+	c.Glossary = append(c.Glossary, entry)
+	sort.Slice(c.Glossary, func(i, j int) bool {
+		return strings.ToLower(c.Glossary[i].Term) < strings.ToLower(c.Glossary[j].Term)
+	})
+}
+
+// LoadGlossaryFile merges a glossary file: a JSON object of term → definition, or an
+// array of {"term", "definition", "columns"} entries. Returns the terms read.
+func (c *SharedContext) LoadGlossaryFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read glossary file: %w", err)
+	}
+
+	var entries []GlossaryTerm
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return 0, fmt.Errorf("failed to parse glossary file: %w", err)
+		}
+	} else {
+		var definitions map[string]string
+		if err := json.Unmarshal(data, &definitions); err != nil {
+			return 0, fmt.Errorf("failed to parse glossary file: %w", err)
+		}
+		for term, definition := range definitions {
+			entries = append(entries, GlossaryTerm{Term: term, Definition: definition})
+		}
+	}
+
+	for _, entry := range entries {
+		c.SetGlossaryTerm(entry.Term, entry.Definition, entry.Columns)
+	}
+	return len(entries), nil
+}
+
+// ResolveGlossaryColumns maps each term to the columns of the context: listed columns
+// that do not exist are dropped, and "table.column" names in the definition are added.
+// Call it after the tables are collected. Returns the number of term → column links.
+func (c *SharedContext) ResolveGlossaryColumns() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	known := make(map[string]string) // lower-cased "table.column" → as named in the context
+	for _, table := range c.Tables {
+		for _, col := range table.Columns {
+			name := table.Name + "." + col.Name
+			known[strings.ToLower(name)] = name
+		}
+	}
+
+	links := 0
+	for i, entry := range c.Glossary {
+		seen := make(map[string]bool)
+		var columns []string
+		add := func(name string) {
+			if resolved, ok := known[strings.ToLower(name)]; ok && !seen[resolved] {
+				seen[resolved] = true
+				columns = append(columns, resolved)
+			}
+		}
+		for _, name := range entry.Columns {
+			if _, ok := known[strings.ToLower(strings.TrimSpace(name))]; !ok && !c.Quiet {
+				fmt.Printf("[Context] ⚠️  Glossary term %q: no column %s\n", entry.Term, name)
+			}
+			add(strings.TrimSpace(name))
+		}
+		for _, word := range strings.FieldsFunc(entry.Definition, func(r rune) bool {
+			return !(r == '_' || r == '.' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'))
+		}) {
+			add(strings.Trim(word, "."))
+		}
+		sort.Strings(columns)
+		c.Glossary[i].Columns = columns
+		links += len(columns)
+	}
+	return links
+}
+
+// MatchGlossary terms found as whole words in the question (plural "s" allowed)
+func (c *SharedContext) MatchGlossary(question string) []GlossaryTerm {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	text := " " + synonymWords(question) + " "
+	var matches []GlossaryTerm
+	for _, entry := range c.Glossary {
+		words := synonymWords(entry.Term)
+		if words != "" && (strings.Contains(text, " "+words+" ") || strings.Contains(text, " "+words+"s ")) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// GlossaryTerms every term of the context (a copy)
+func (c *SharedContext) GlossaryTerms() []GlossaryTerm {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]GlossaryTerm(nil), c.Glossary...)
+}
+
+// FormatGlossaryPrompt the terms as a prompt section ("" without terms)
+func FormatGlossaryPrompt(terms []GlossaryTerm) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("Business Glossary (domain terms and the columns they map to):\n")
+	for i, entry := range terms {
+		if i == maxPromptGlossaryTerms {
+			sb.WriteString(fmt.Sprintf("- ... %d more terms\n", len(terms)-i))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("- %s: %s", entry.Term, entry.Definition))
+		if len(entry.Columns) > 0 {
+			sb.WriteString(fmt.Sprintf(" [columns: %s]", strings.Join(entry.Columns, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
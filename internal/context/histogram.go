@@ -0,0 +1,168 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// histogramBuckets buckets of a numeric histogram
+const histogramBuckets = 10
+
+// maxDateBuckets buckets of a date histogram (years are merged beyond this)
+const maxDateBuckets = 12
+
+// dateLayouts formats recognized as dates, most specific first
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"2006-01",
+}
+
+// collectNumericHistogram counts values in equal-width buckets between the range min and max
+func (qc *QualityChecker) collectNumericHistogram(ctx context.Context, colName string, r *NumericRange) []HistogramBucket {
+	if r == nil || r.Max <= r.Min {
+		return nil
+	}
+	width := (r.Max - r.Min) / histogramBuckets
+	col := quoteIdent(colName)
+
+	labels := make([]string, histogramBuckets)
+	sums := make([]string, histogramBuckets)
+	for i := 0; i < histogramBuckets; i++ {
+		low, high := r.Min+float64(i)*width, r.Min+float64(i+1)*width
+		if i == histogramBuckets-1 {
+			high = r.Max
+			sums[i] = fmt.Sprintf("SUM(CASE WHEN %s >= %s THEN 1 ELSE 0 END) AS b%d", col, formatBound(low), i)
+		} else {
+			sums[i] = fmt.Sprintf("SUM(CASE WHEN %s >= %s AND %s < %s THEN 1 ELSE 0 END) AS b%d", col, formatBound(low), col, formatBound(high), i)
+		}
+		labels[i] = formatBound(low) + ".." + formatBound(high)
+	}
+	return qc.countBuckets(ctx, colName, labels, sums)
+}
+
+// collectDateStats detects a temporal column from its MIN / MAX and buckets it by year
+// (by month within a single year). Text columns count only when both ends parse as dates.
+func (qc *QualityChecker) collectDateStats(ctx context.Context, colName, colType string) (*DateRange, []HistogramBucket) {
+	col := quoteIdent(colName)
+	filter := col + " IS NOT NULL"
+	if isTextType(colType) {
+		filter += " AND " + col + " != ''"
+	}
+	rangeSQL := fmt.Sprintf(`SELECT MIN(%s) as min_val, MAX(%s) as max_val FROM %s WHERE %s`, col, col, quoteIdent(qc.tableName), filter)
+	result, err := qc.query(ctx, rangeSQL)
+	if err != nil || result.RowCount == 0 {
+		return nil, nil
+	}
+	minDate, okMin := parseDateValue(result.Rows[0]["min_val"])
+	maxDate, okMax := parseDateValue(result.Rows[0]["max_val"])
+	if !okMin || !okMax || maxDate.Before(minDate) {
+		return nil, nil
+	}
+	dates := &DateRange{Min: minDate.Format("2006-01-02"), Max: maxDate.Format("2006-01-02")}
+
+	// Text dates compare as strings ('2019' sorts after any '2018-...'); date types need full literals
+	bound := func(t time.Time, month bool) string {
+		switch {
+		case isTextType(colType) && month:
+			return "'" + t.Format("2006-01") + "'"
+		case isTextType(colType):
+			return "'" + t.Format("2006") + "'"
+		}
+		return "'" + t.Format("2006-01-02") + "'"
+	}
+
+	var starts []time.Time
+	month := minDate.Year() == maxDate.Year()
+	if month {
+		for m := minDate.Month(); m <= maxDate.Month(); m++ {
+			starts = append(starts, time.Date(minDate.Year(), m, 1, 0, 0, 0, 0, time.UTC))
+		}
+	} else {
+		years := maxDate.Year() - minDate.Year() + 1
+		step := (years + maxDateBuckets - 1) / maxDateBuckets
+		for y := minDate.Year(); y <= maxDate.Year(); y += step {
+			starts = append(starts, time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC))
+		}
+	}
+
+	labels := make([]string, len(starts))
+	sums := make([]string, len(starts))
+	for i, start := range starts {
+		if i == len(starts)-1 {
+			sums[i] = fmt.Sprintf("SUM(CASE WHEN %s >= %s THEN 1 ELSE 0 END) AS b%d", col, bound(start, month), i)
+		} else {
+			sums[i] = fmt.Sprintf("SUM(CASE WHEN %s >= %s AND %s < %s THEN 1 ELSE 0 END) AS b%d", col, bound(start, month), col, bound(starts[i+1], month), i)
+		}
+		switch {
+		case month:
+			labels[i] = start.Format("2006-01")
+		case i < len(starts)-1 && starts[i+1].Year()-start.Year() > 1:
+			labels[i] = fmt.Sprintf("%d-%d", start.Year(), starts[i+1].Year()-1)
+		case i == len(starts)-1 && maxDate.Year() > start.Year():
+			labels[i] = fmt.Sprintf("%d-%d", start.Year(), maxDate.Year())
+		default:
+			labels[i] = strconv.Itoa(start.Year())
+		}
+	}
+	return dates, qc.countBuckets(ctx, colName, labels, sums)
+}
+
+// countBuckets runs one query of per-bucket SUM(CASE ...) expressions
+func (qc *QualityChecker) countBuckets(ctx context.Context, colName string, labels, sums []string) []HistogramBucket {
+	bucketSQL := fmt.Sprintf(`SELECT %s FROM %s WHERE %s IS NOT NULL`, strings.Join(sums, ", "), quoteIdent(qc.tableName), quoteIdent(colName))
+	result, err := qc.query(ctx, bucketSQL)
+	if err != nil || result.RowCount == 0 {
+		return nil
+	}
+	buckets := make([]HistogramBucket, len(labels))
+	for i, label := range labels {
+		buckets[i] = HistogramBucket{Label: label, Count: toInt(result.Rows[0][fmt.Sprintf("b%d", i)])}
+	}
+	return buckets
+}
+
+// parseDateValue a driver value as a date (time.Time, or text in a dateLayouts format)
+func parseDateValue(val interface{}) (time.Time, bool) {
+	var text string
+	switch v := val.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return time.Time{}, false
+	}
+	text = strings.TrimSpace(text)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, text); err == nil && t.Year() >= 1000 {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// isDateType reports whether a declared column type is temporal (DATE, DATETIME, TIMESTAMP, TIME)
+func isDateType(colType string) bool {
+	t := strings.ToUpper(colType)
+	return strings.Contains(t, "DATE") || strings.Contains(t, "TIME")
+}
+
+// formatBound a bucket bound as a short SQL-safe number
+func formatBound(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
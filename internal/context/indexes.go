@@ -0,0 +1,104 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+)
+
+// indexHintMinRows tables below this size scan cheaply; they get no index hints or notes
+const indexHintMinRows = 1000
+
+// indexedColumn a column a filter or join can look up through an index
+type indexedColumn struct {
+	name string
+	rest []string // Further columns of a composite index starting with it
+}
+
+// indexedColumns columns with an index lookup, in column order: the leading column of every
+// index and of the primary key (indexed by every engine, SQLite rowid included)
+func indexedColumns(table *TableMetadata) []indexedColumn {
+	leading := make(map[string][]string)
+	for _, idx := range table.Indexes {
+		if len(idx.Columns) == 0 {
+			continue
+		}
+		key := strings.ToLower(idx.Columns[0])
+		if rest, seen := leading[key]; !seen || len(idx.Columns)-1 > len(rest) {
+			leading[key] = idx.Columns[1:]
+		}
+	}
+	pk := table.PrimaryKey
+	if len(pk) == 0 {
+		pk = primaryKeyNames(table)
+	}
+	if len(pk) > 0 {
+		if _, seen := leading[strings.ToLower(pk[0])]; !seen {
+			leading[strings.ToLower(pk[0])] = pk[1:]
+		}
+	}
+
+	var result []indexedColumn
+	for _, col := range table.Columns {
+		if rest, ok := leading[strings.ToLower(col.Name)]; ok {
+			result = append(result, indexedColumn{name: col.Name, rest: rest})
+		}
+	}
+	return result
+}
+
+// isIndexedColumn reports whether lookups on a column can use an index
+func isIndexedColumn(table *TableMetadata, colName string) bool {
+	for _, col := range indexedColumns(table) {
+		if strings.EqualFold(col.name, colName) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexHint the prompt line naming the cheap filter and join columns of a large table
+// ("" for small tables, views and tables without any index)
+func indexHint(table *TableMetadata) string {
+	if table.IsView || table.RowCount < indexHintMinRows {
+		return ""
+	}
+	cols := indexedColumns(table)
+	if len(cols) == 0 {
+		return fmt.Sprintf("  Indexes: none, every filter or join on %s requires a full scan\n", table.Name)
+	}
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col.name
+		if len(col.rest) > 0 {
+			names[i] += " (+" + strings.Join(col.rest, ", ") + ")"
+		}
+	}
+	return fmt.Sprintf("  Indexes: filtering or joining on %s is cheap; other columns require a full scan\n",
+		strings.Join(names, ", "))
+}
+
+// checkJoinIndexes notes foreign key columns of a large table that have no index:
+// every join or lookup through them scans the table
+func (qc *QualityChecker) checkJoinIndexes(table *TableMetadata) []QualityIssue {
+	if table.IsView || table.RowCount < indexHintMinRows {
+		return nil
+	}
+
+	var issues []QualityIssue
+	for _, fk := range table.ForeignKeys {
+		col := fk.KeyColumns()[0]
+		if isIndexedColumn(table, col) {
+			continue
+		}
+		issues = append(issues, QualityIssue{
+			Table:       qc.tableName,
+			Column:      col,
+			Type:        "missing_index",
+			Severity:    "info",
+			Description: fmt.Sprintf("Join column %s → %s has no index; each join or lookup on it scans %d rows", fk.LocalRef(), fk.TargetRef(), table.RowCount),
+			SQLFix:      fmt.Sprintf("Narrow %s with indexed columns or filter %s first, then join", qc.tableName, fk.ReferencedTable),
+			AffectedOps: []string{"JOIN", "WHERE"},
+		})
+	}
+	return issues
+}
@@ -2,47 +2,127 @@ package context
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
-// AnalyzeJoinPaths analyzes JOIN paths between tables
+// maxJoinHops longest join path stored (tables further apart are rarely joined)
+const maxJoinHops = 4
+
+// AnalyzeJoinPaths computes the shortest FK join path between every pair of tables
+// Paths run through bridge tables when there is no direct foreign key and are stored with
+// ready-to-paste JOIN chains. Field semantics are no longer generated here: the verbose
+// per-column notes bloated Rich Context, FK arrows in the schema already carry them.
 func (c *SharedContext) AnalyzeJoinPaths() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.analyzeJoinPaths()
+}
 
-	// No longer generates verbose JOIN path and field semantic info
-	// This info bloats Rich Context and distracts attention
-	// Foreign key relationships are sufficient for JOIN info
-	// LLM can infer JOIN paths from foreign key relationships
-
+// analyzeJoinPaths AnalyzeJoinPaths without locking (caller holds the lock)
+func (c *SharedContext) analyzeJoinPaths() {
 	// Keep data structure init to avoid nil pointers
-	if c.JoinPaths == nil {
-		c.JoinPaths = make(map[string]*JoinPath)
-	}
+	c.JoinPaths = make(map[string]*JoinPath)
 	if c.FieldSemantics == nil {
 		c.FieldSemantics = make(map[string]*FieldSemantic)
 	}
+
+	graph := c.buildForeignKeyGraph()
+	names := make([]string, 0, len(c.Tables))
+	for name := range c.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, from := range names {
+		for _, to := range names[i+1:] {
+			path := c.findShortestPath(graph, from, to)
+			if len(path) < 2 || len(path)-1 > maxJoinHops {
+				continue
+			}
+			if joinPath := c.buildJoinPath(path); joinPath != nil {
+				c.JoinPaths[joinPathKey(from, to)] = joinPath
+			}
+		}
+	}
+}
+
+// joinPathKey JoinPaths key of a table pair (stored once, in name order)
+func joinPathKey(a, b string) string {
+	if b < a {
+		a, b = b, a
+	}
+	return a + " -> " + b
+}
+
+// JoinPathBetween the stored join path from one table to another (reversed if stored the
+// other way round), nil if the tables are not connected
+func (c *SharedContext) JoinPathBetween(from, to string) *JoinPath {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.joinPathBetween(from, to)
+}
+
+// joinPathBetween JoinPathBetween without locking (caller holds the lock)
+func (c *SharedContext) joinPathBetween(from, to string) *JoinPath {
+	from, to = c.tableKey(from), c.tableKey(to)
+	joinPath, ok := c.JoinPaths[joinPathKey(from, to)]
+	if !ok || joinPath.FromTable == from {
+		return joinPath
+	}
+	return c.buildJoinPath(c.reversePath(joinPath.Path))
+}
+
+// tableKey the Tables key matching a table name case-insensitively (the name itself if none)
+func (c *SharedContext) tableKey(name string) string {
+	if _, ok := c.Tables[name]; ok {
+		return name
+	}
+	for key := range c.Tables {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
 }
 
 // buildForeignKeyGraph builds FK relationship graph
 func (c *SharedContext) buildForeignKeyGraph() map[string][]string {
 	graph := make(map[string][]string)
 
+	edges := make(map[string]map[string]bool)
+	addEdge := func(a, b string) {
+		if edges[a] == nil {
+			edges[a] = make(map[string]bool)
+		}
+		edges[a][b] = true
+	}
+
 	for tableName, table := range c.Tables {
-		if _, exists := graph[tableName]; !exists {
-			graph[tableName] = []string{}
+		if _, exists := edges[tableName]; !exists {
+			edges[tableName] = make(map[string]bool)
 		}
 
 		for _, fk := range table.ForeignKeys {
 			// Add bidirectional edges (JOIN works both ways)
-			graph[tableName] = append(graph[tableName], fk.ReferencedTable)
-			if _, exists := graph[fk.ReferencedTable]; !exists {
-				graph[fk.ReferencedTable] = []string{}
+			referenced := c.tableKey(fk.ReferencedTable)
+			if referenced == tableName {
+				continue // Self-reference: no path to another table
 			}
-			graph[fk.ReferencedTable] = append(graph[fk.ReferencedTable], tableName)
+			addEdge(tableName, referenced)
+			addEdge(referenced, tableName)
 		}
 	}
 
+	// Sorted neighbors keep the chosen path stable among equally short ones
+	for tableName, neighbors := range edges {
+		graph[tableName] = make([]string, 0, len(neighbors))
+		for neighbor := range neighbors {
+			graph[tableName] = append(graph[tableName], neighbor)
+		}
+		sort.Strings(graph[tableName])
+	}
+
 	return graph
 }
 
@@ -88,6 +168,7 @@ func (c *SharedContext) buildJoinPath(path []string) *JoinPath {
 
 	joinClauses := []string{}
 	description := ""
+	var inferred []string
 
 	for i := 0; i < len(path)-1; i++ {
 		fromTable := path[i]
@@ -98,6 +179,9 @@ func (c *SharedContext) buildJoinPath(path []string) *JoinPath {
 		if joinClause != "" {
 			joinClauses = append(joinClauses, joinClause)
 		}
+		if fkTable, fk, _ := c.joinForeignKey(fromTable, toTable); fk != nil && fk.Inferred {
+			inferred = append(inferred, fmt.Sprintf("%s.%s → %s%s", fkTable, fk.ColumnName, fk.TargetRef(), fk.inferredNote()))
+		}
 	}
 
 	// Generate description
@@ -107,41 +191,126 @@ func (c *SharedContext) buildJoinPath(path []string) *JoinPath {
 		intermediates := path[1 : len(path)-1]
 		description = fmt.Sprintf("Join through intermediate table(s): %s", strings.Join(intermediates, ", "))
 	}
+	if len(inferred) > 0 {
+		description += "; undeclared key " + strings.Join(inferred, ", ")
+	}
+
+	// Ready-to-paste FROM ... JOIN ... ON ... chain
+	joinSQL := "FROM " + sqlIdent(path[0])
+	for i := 1; i < len(path); i++ {
+		on := c.joinCondition(path[i-1], path[i])
+		if on == "" {
+			return nil
+		}
+		joinSQL += fmt.Sprintf(" JOIN %s ON %s", sqlIdent(path[i]), on)
+	}
 
 	return &JoinPath{
 		FromTable:   path[0],
 		ToTable:     path[len(path)-1],
 		Path:        path,
 		JoinClauses: joinClauses,
+		JoinSQL:     joinSQL,
 		Description: description,
 	}
 }
 
 // findJoinClause finds JOIN condition between two tables
 func (c *SharedContext) findJoinClause(table1, table2 string) string {
+	fkTable, fk, refTable := c.joinForeignKey(table1, table2)
+	if fk == nil {
+		return ""
+	}
+	refColumns := c.referencedColumns(refTable, fk)
+	conditions := make([]string, len(refColumns))
+	for i, col := range fk.KeyColumns() {
+		conditions[i] = fmt.Sprintf("%s.%s = %s.%s", fkTable, col, refTable, refColumns[i])
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// joinCondition the ON condition between two tables with quoted identifiers
+func (c *SharedContext) joinCondition(table1, table2 string) string {
+	fkTable, fk, refTable := c.joinForeignKey(table1, table2)
+	if fk == nil {
+		return ""
+	}
+	refColumns := c.referencedColumns(refTable, fk)
+	conditions := make([]string, len(refColumns))
+	for i, col := range fk.KeyColumns() {
+		conditions[i] = fmt.Sprintf("%s.%s = %s.%s",
+			sqlIdent(fkTable), sqlIdent(col), sqlIdent(refTable), sqlIdent(refColumns[i]))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// joinForeignKey the foreign key linking two tables, whichever side declares it
+func (c *SharedContext) joinForeignKey(table1, table2 string) (string, *ForeignKeyMetadata, string) {
 	// Check if table1 has FK to table2
 	if t1, exists := c.Tables[table1]; exists {
-		for _, fk := range t1.ForeignKeys {
-			if fk.ReferencedTable == table2 {
-				return fmt.Sprintf("%s.%s = %s.%s",
-					table1, fk.ColumnName,
-					table2, fk.ReferencedColumn)
+		for i, fk := range t1.ForeignKeys {
+			if strings.EqualFold(fk.ReferencedTable, table2) {
+				return table1, &t1.ForeignKeys[i], table2
 			}
 		}
 	}
 
 	// Check if table2 has FK to table1
 	if t2, exists := c.Tables[table2]; exists {
-		for _, fk := range t2.ForeignKeys {
-			if fk.ReferencedTable == table1 {
-				return fmt.Sprintf("%s.%s = %s.%s",
-					table2, fk.ColumnName,
-					table1, fk.ReferencedColumn)
+		for i, fk := range t2.ForeignKeys {
+			if strings.EqualFold(fk.ReferencedTable, table1) {
+				return table2, &t2.ForeignKeys[i], table1
 			}
 		}
 	}
 
-	return ""
+	return "", nil, ""
+}
+
+// referencedColumns the referenced columns of a foreign key in key order, the referenced
+// table's primary key columns where the declaration leaves them out (SQLite "REFERENCES t")
+func (c *SharedContext) referencedColumns(refTable string, fk *ForeignKeyMetadata) []string {
+	refColumns := fk.ReferencedKeyColumns()
+	var pk []string
+	if table, exists := c.Tables[refTable]; exists {
+		pk = table.PrimaryKey // Declared key order
+		if len(pk) == 0 {
+			pk = primaryKeyNames(table)
+		}
+	}
+	for i, col := range refColumns {
+		switch {
+		case col != "":
+		case i < len(pk):
+			refColumns[i] = pk[i]
+		default:
+			refColumns[i] = "rowid"
+		}
+	}
+	return refColumns
+}
+
+// reservedIdents keywords that occur as table or column names and must be quoted
+var reservedIdents = map[string]bool{
+	"order": true, "group": true, "select": true, "from": true, "where": true, "table": true,
+	"user": true, "index": true, "key": true, "limit": true, "join": true, "to": true, "by": true,
+}
+
+// sqlIdent an identifier for pasting into SQL, quoted only when it needs to be
+// Schema-qualified names of merged contexts ("alias.table") are quoted part by part.
+func sqlIdent(name string) string {
+	if schema, table, ok := strings.Cut(name, "."); ok && schemaPrefixPattern.MatchString(schema) {
+		return schema + "." + sqlIdent(table)
+	}
+	if name == "" || reservedIdents[strings.ToLower(name)] {
+		return quoteIdent(name)
+	}
+	for i, r := range name {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (i == 0 || r < '0' || r > '9') {
+			return quoteIdent(name)
+		}
+	}
+	return name
 }
 
 // reversePath reverses path
@@ -158,13 +327,20 @@ func (c *SharedContext) analyzeFieldSemantics() {
 	for tableName, table := range c.Tables {
 		// Analyze FK fields
 		for _, fk := range table.ForeignKeys {
-			key := fmt.Sprintf("%s.%s", tableName, fk.ColumnName)
-			c.FieldSemantics[key] = &FieldSemantic{
-				TableName:   tableName,
-				ColumnName:  fk.ColumnName,
-				StorageType: "foreign_key",
-				References:  fmt.Sprintf("%s.%s", fk.ReferencedTable, fk.ReferencedColumn),
-				Note:        fmt.Sprintf("Stores %s ID, not %s name. Use JOIN to get related data.", fk.ReferencedTable, fk.ReferencedTable),
+			refColumns := fk.ReferencedKeyColumns()
+			for i, col := range fk.KeyColumns() {
+				key := fmt.Sprintf("%s.%s", tableName, col)
+				note := fmt.Sprintf("Stores %s ID, not %s name. Use JOIN to get related data.", fk.ReferencedTable, fk.ReferencedTable)
+				if fk.IsComposite() {
+					note = fmt.Sprintf("Part of composite key %s → %s. JOIN on all of its columns, not just this one.", fk.LocalRef(), fk.TargetRef())
+				}
+				c.FieldSemantics[key] = &FieldSemantic{
+					TableName:   tableName,
+					ColumnName:  col,
+					StorageType: "foreign_key",
+					References:  fmt.Sprintf("%s.%s", fk.ReferencedTable, refColumns[i]),
+					Note:        note,
+				}
 			}
 		}
 
@@ -197,22 +373,54 @@ func (c *SharedContext) analyzeFieldSemantics() {
 	}
 }
 
-// FormatJoinPathsForPrompt formats JOIN path info for Prompt
+// FormatJoinPathsForPrompt formats JOIN path info for Prompt (multi-hop paths of all tables)
 func (c *SharedContext) FormatJoinPathsForPrompt() string {
+	return c.FormatJoinPathsForTables(nil)
+}
+
+// FormatJoinPathsForTables formats the multi-hop join paths between the given tables (nil = all)
+// Direct joins are left out: the FK arrows of the schema already show them.
+func (c *SharedContext) FormatJoinPathsForTables(tables []string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if len(c.JoinPaths) == 0 {
 		return ""
 	}
 
-	var sb strings.Builder
-	sb.WriteString("\n## Join Path Guidelines\n")
-	sb.WriteString("When joining tables, refer to these pre-analyzed join paths:\n\n")
+	selected := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		selected[c.tableKey(table)] = true
+	}
 
 	// Sort output by table name
+	keys := make([]string, 0, len(c.JoinPaths))
 	for key, joinPath := range c.JoinPaths {
+		if len(joinPath.Path) < 3 {
+			continue
+		}
+		if tables != nil && (!selected[joinPath.FromTable] || !selected[joinPath.ToTable]) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("\n## Join Path Guidelines\n")
+	sb.WriteString("These tables have no direct foreign key; join them through the bridge tables:\n\n")
+
+	for _, key := range keys {
+		joinPath := c.JoinPaths[key]
 		sb.WriteString(fmt.Sprintf("**%s**:\n", key))
 		sb.WriteString(fmt.Sprintf("  - Path: %s\n", strings.Join(joinPath.Path, " → ")))
 		sb.WriteString(fmt.Sprintf("  - Description: %s\n", joinPath.Description))
-		if len(joinPath.JoinClauses) > 0 {
+		if joinPath.JoinSQL != "" {
+			sb.WriteString(fmt.Sprintf("  - SQL: %s\n", joinPath.JoinSQL))
+		} else if len(joinPath.JoinClauses) > 0 {
 			sb.WriteString("  - Join clauses:\n")
 			for _, clause := range joinPath.JoinClauses {
 				sb.WriteString(fmt.Sprintf("    * %s\n", clause))
@@ -0,0 +1,270 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// joinQualityMaxRows referencing rows measured per key; larger tables are measured over
+// their first rows and leave the referenced side's childless share out
+const joinQualityMaxRows = 1000000
+
+// JoinQuality measured behavior of an inner join along a foreign key
+type JoinQuality struct {
+	Rows              int64   `json:"rows"`                        // Referencing rows measured
+	NullKeyRows       int64   `json:"null_key_rows"`               // Rows with a NULL key column (an inner join drops them)
+	OrphanRows        int64   `json:"orphan_rows"`                 // Rows whose key matches no referenced row (dropped too)
+	FanOut            float64 `json:"fan_out"`                     // Referenced rows per matched row; above 1 the join duplicates rows
+	ChildrenPerParent float64 `json:"children_per_parent"`         // Referencing rows per referenced row that has any
+	MaxChildren       int64   `json:"max_children"`                // Most referencing rows of one referenced key
+	ChildlessPercent  float64 `json:"childless_percent,omitempty"` // Share of referenced rows no row references
+	Sampled           bool    `json:"sampled,omitempty"`           // Measured over the first joinQualityMaxRows rows only
+}
+
+// DroppedPercent share of the referencing rows an inner join drops (NULL keys and orphans)
+func (q *JoinQuality) DroppedPercent() float64 {
+	if q.Rows == 0 {
+		return 0
+	}
+	return float64(q.NullKeyRows+q.OrphanRows) / float64(q.Rows) * 100
+}
+
+// joinQualityTarget one foreign key to measure
+type joinQualityTarget struct {
+	table      string
+	index      int // Position in the table's ForeignKeys
+	fk         ForeignKeyMetadata
+	refColumns []string // Referenced columns, implicit primary keys resolved
+	rowCount   int64
+}
+
+// AnalyzeJoinQuality measures every foreign key (declared and inferred) of the context:
+// the rows an inner join drops for NULL or orphaned keys, how many referenced rows each
+// row matches (fan-out), referencing rows per referenced row and the referenced rows
+// without any. The result is stored on the key and feeds BuildCrossTableQualitySummary.
+// Keys whose queries fail are left unmeasured. Returns the number of keys measured.
+func (c *SharedContext) AnalyzeJoinQuality(ctx context.Context, dbAdapter adapter.DBAdapter) (int, error) {
+	targets := c.joinQualityTargets()
+
+	measured := make([]*JoinQuality, len(targets))
+	for i, target := range targets {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if quality, ok := measureJoinQuality(ctx, dbAdapter, target); ok {
+			measured[i] = quality
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := 0
+	for i, target := range targets {
+		quality := measured[i]
+		if quality == nil {
+			continue
+		}
+		table, exists := c.Tables[target.table]
+		if !exists || target.index >= len(table.ForeignKeys) || table.ForeignKeys[target.index].TargetRef() != target.fk.TargetRef() {
+			continue // Keys changed while measuring
+		}
+		table.ForeignKeys[target.index].JoinQuality = quality
+		count++
+		if !c.Quiet && (quality.DroppedPercent() > 0 || quality.FanOut > 1) {
+			fmt.Printf("[Context] JOIN %s.%s → %s: drops %.1f%% of rows, fan-out %.2f\n",
+				table.Name, target.fk.LocalRef(), target.fk.TargetRef(), quality.DroppedPercent(), quality.FanOut)
+		}
+	}
+	return count, nil
+}
+
+// joinQualityTargets the foreign keys of the context in table order
+// Keys referencing an implicit primary key are resolved against the referenced table;
+// keys that cannot be resolved are skipped.
+func (c *SharedContext) joinQualityTargets() []joinQualityTarget {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.Tables))
+	for name := range c.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var targets []joinQualityTarget
+	for _, name := range names {
+		table := c.Tables[name]
+		for i, fk := range table.ForeignKeys {
+			refColumns := fk.ReferencedKeyColumns()
+			if refColumns[0] == "" {
+				parent, ok := c.Tables[fk.ReferencedTable]
+				if !ok {
+					continue
+				}
+				if pk := primaryKeyNames(parent); len(pk) == len(refColumns) {
+					refColumns = pk
+				}
+			}
+			resolved := true
+			for _, col := range refColumns {
+				resolved = resolved && col != ""
+			}
+			if !resolved {
+				continue
+			}
+			targets = append(targets, joinQualityTarget{
+				table: name, index: i, fk: fk,
+				refColumns: refColumns, rowCount: table.RowCount,
+			})
+		}
+	}
+	return targets
+}
+
+// measureJoinQuality runs the join queries of one key (false when any fails)
+func measureJoinQuality(ctx context.Context, dbAdapter adapter.DBAdapter, target joinQualityTarget) (*JoinQuality, bool) {
+	quality := &JoinQuality{}
+	child := quoteIdent(target.table)
+	if target.rowCount > joinQualityMaxRows {
+		child = fmt.Sprintf("(SELECT * FROM %s LIMIT %d)", child, joinQualityMaxRows)
+		quality.Sampled = true
+	}
+	parent := quoteIdent(target.fk.ReferencedTable)
+
+	var on, notNull, groupBy []string
+	for i, col := range target.fk.KeyColumns() {
+		on = append(on, fmt.Sprintf("c.%s = p.%s", quoteIdent(col), quoteIdent(target.refColumns[i])))
+		notNull = append(notNull, fmt.Sprintf("c.%s IS NOT NULL", quoteIdent(col)))
+		groupBy = append(groupBy, "c."+quoteIdent(col))
+	}
+	joinOn := strings.Join(on, " AND ")
+
+	query := func(sql string) (map[string]interface{}, bool) {
+		result, err := dbAdapter.ExecuteQuery(ctx, sql)
+		if err != nil || result.Error != "" || result.RowCount == 0 {
+			return nil, false
+		}
+		return result.Rows[0], true
+	}
+
+	// Referencing side: rows, NULL keys, rows with a match
+	row, ok := query(fmt.Sprintf(
+		`SELECT COUNT(*) as total, COUNT(CASE WHEN %s THEN 1 END) as keyed, COUNT(CASE WHEN EXISTS (SELECT 1 FROM %s p WHERE %s) THEN 1 END) as matched FROM %s c`,
+		strings.Join(notNull, " AND "), parent, joinOn, child,
+	))
+	if !ok {
+		return nil, false
+	}
+	keyed, matched := int64(toInt(row["keyed"])), int64(toInt(row["matched"]))
+	quality.Rows = int64(toInt(row["total"]))
+	quality.NullKeyRows = quality.Rows - keyed
+	quality.OrphanRows = keyed - matched
+	if matched == 0 {
+		return quality, true
+	}
+
+	// Joined rows and the largest group of one key
+	row, ok = query(fmt.Sprintf(
+		`SELECT COUNT(*) as joined FROM %s c JOIN %s p ON %s`, child, parent, joinOn,
+	))
+	if !ok {
+		return nil, false
+	}
+	joined := int64(toInt(row["joined"]))
+	quality.FanOut = float64(joined) / float64(matched)
+
+	row, ok = query(fmt.Sprintf(
+		`SELECT MAX(cnt) as max_children FROM (SELECT COUNT(*) as cnt FROM %s c WHERE EXISTS (SELECT 1 FROM %s p WHERE %s) GROUP BY %s) g`,
+		child, parent, joinOn, strings.Join(groupBy, ", "),
+	))
+	if !ok {
+		return nil, false
+	}
+	quality.MaxChildren = int64(toInt(row["max_children"]))
+
+	// Referenced side: rows referenced at least once
+	row, ok = query(fmt.Sprintf(
+		`SELECT COUNT(*) as total, COUNT(CASE WHEN EXISTS (SELECT 1 FROM %s c WHERE %s) THEN 1 END) as referenced FROM %s p`,
+		child, joinOn, parent,
+	))
+	if !ok {
+		return nil, false
+	}
+	parents, referenced := toInt(row["total"]), toInt(row["referenced"])
+	if referenced > 0 {
+		quality.ChildrenPerParent = float64(matched) / float64(referenced)
+	}
+	if parents > 0 && !quality.Sampled {
+		quality.ChildlessPercent = float64(parents-referenced) / float64(parents) * 100
+	}
+	return quality, true
+}
+
+// joinQualityGuidance the notable parts of a measured join as one summary line
+// ("" when the join neither drops, duplicates nor multiplies rows noticeably)
+func joinQualityGuidance(table string, fk ForeignKeyMetadata) string {
+	q := fk.JoinQuality
+	if q == nil || q.Rows == 0 {
+		return ""
+	}
+	var parts []string
+	if dropped := q.DroppedPercent(); dropped > 0 {
+		var causes []string
+		if q.NullKeyRows > 0 {
+			causes = append(causes, countOf(q.NullKeyRows, "NULL key"))
+		}
+		if q.OrphanRows > 0 {
+			causes = append(causes, countOf(q.OrphanRows, "orphan"))
+		}
+		parts = append(parts, fmt.Sprintf("inner join drops %s of %s rows (%s) → LEFT JOIN keeps them",
+			formatPercent(dropped), table, strings.Join(causes, ", ")))
+	}
+	if q.FanOut > 1.005 {
+		parts = append(parts, fmt.Sprintf("%s is not unique: each %s row matches %.2f rows on average → deduplicate %s or COUNT(DISTINCT ...)",
+			fk.TargetRef(), table, q.FanOut, fk.ReferencedTable))
+	}
+	if q.ChildlessPercent > 0 {
+		parts = append(parts, fmt.Sprintf("%s of %s rows have no %s rows → LEFT JOIN from %s to count them as 0",
+			formatPercent(q.ChildlessPercent), fk.ReferencedTable, table, fk.ReferencedTable))
+	}
+	if q.ChildrenPerParent >= 2 {
+		parts = append(parts, fmt.Sprintf("%.1f %s rows per %s row (max %d) → aggregate %s before joining another table to %s",
+			q.ChildrenPerParent, table, fk.ReferencedTable, q.MaxChildren, table, fk.ReferencedTable))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	sampled := ""
+	if q.Sampled {
+		sampled = fmt.Sprintf(" (first %d rows)", q.Rows)
+	}
+	local := table + "." + fk.ColumnName
+	if fk.IsComposite() {
+		local = table + fk.LocalRef()
+	}
+	return fmt.Sprintf("- JOIN %s → %s%s: %s\n", local, fk.TargetRef(), sampled, strings.Join(parts, "; "))
+}
+
+// countOf "1 orphan", "3 orphans"
+func countOf(n int64, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// formatPercent a share for prompts: "25%", "3.0%", "<0.1%"
+func formatPercent(p float64) string {
+	switch {
+	case p >= 10:
+		return fmt.Sprintf("%.0f%%", p)
+	case p >= 0.1:
+		return fmt.Sprintf("%.1f%%", p)
+	default:
+		return "<0.1%"
+	}
+}
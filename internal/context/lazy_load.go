@@ -0,0 +1,314 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LazyContext context file whose table sections are decoded on demand
+// The top-level sections (join paths, field semantics, value index, embeddings) are decoded
+// at open. Each table section is parsed once for its TableIndexEntry fields only and stays
+// raw JSON until Hydrate builds its TableMetadata, so a question touching 3 of 60 tables
+// never builds the notes, column statistics and samples of the other 57.
+// A context store is read the same way: its header and stored index entries at open, each
+// table's row only when it is hydrated.
+type LazyContext struct {
+	mu        sync.Mutex
+	ctx       *SharedContext // Hydrated tables only
+	raw       map[string]json.RawMessage
+	storePath string // Context store the tables are read from (raw unused)
+	index     map[string]*TableIndexEntry
+
+	overrides *ContextOverrides // <db>.overrides.json, applied to each table as it is decoded
+}
+
+// TableIndexEntry table fields needed before schema linking
+// Stored with each table of a context store, hence the JSON tags.
+type TableIndexEntry struct {
+	Name          string               `json:"name"`
+	Columns       []string             `json:"columns"`
+	PrimaryKey    []string             `json:"primary_key,omitempty"` // Declared key order, else the columns flagged as primary key
+	ForeignKeys   []ForeignKeyMetadata `json:"foreign_keys,omitempty"`
+	Description   string               `json:"description,omitempty"`    // Description, else DDL comment, else the first Rich Context note
+	QualityIssues []QualityIssue       `json:"quality_issues,omitempty"` // Structured issues (small; linking summarizes the critical ones)
+	NoteExpiry    map[string]string    `json:"note_expiry,omitempty"`    // Rich Context note key → expires_at, notes that expire only
+}
+
+// tableIndexSection the decoded subset of a table section
+type tableIndexSection struct {
+	Comment     string `json:"comment"`
+	Description string `json:"description"`
+	Columns     []struct {
+		Name         string `json:"name"`
+		IsPrimaryKey bool   `json:"is_primary_key"`
+	} `json:"columns"`
+	PrimaryKey    []string                   `json:"primary_key"`
+	ForeignKeys   []ForeignKeyMetadata       `json:"foreign_keys"`
+	RichContext   map[string]json.RawMessage `json:"rich_context"`
+	QualityIssues []QualityIssue             `json:"quality_issues"`
+}
+
+// OpenLazyContext reads a context file or store, decoding its table index but not its tables
+// The overrides file next to it is merged into the index and each decoded table.
+func OpenLazyContext(path string) (*LazyContext, error) {
+	overrides, err := LoadOverrides(OverridesPath(path))
+	if err != nil {
+		return nil, err
+	}
+	if IsContextStore(path) {
+		return openLazyStore(path, overrides)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// The outer Tables field shadows the embedded one, leaving table sections raw
+	file := struct {
+		*SharedContext
+		Tables map[string]json.RawMessage `json:"tables"`
+	}{SharedContext: &SharedContext{}}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	l := &LazyContext{
+		ctx:   file.SharedContext,
+		raw:   file.Tables,
+		index: make(map[string]*TableIndexEntry, len(file.Tables)),
+
+		overrides: overrides,
+	}
+	l.ctx.Tables = make(map[string]*TableMetadata)
+	for name, raw := range file.Tables {
+		var section tableIndexSection
+		if err := json.Unmarshal(raw, &section); err != nil {
+			return nil, fmt.Errorf("table %s: %w", name, err)
+		}
+		l.index[name] = newTableIndexEntry(name, &section)
+	}
+	l.applyOverrides()
+	return l, nil
+}
+
+// openLazyStore reads the header and stored index entries of a context store
+func openLazyStore(path string, overrides *ContextOverrides) (*LazyContext, error) {
+	store, err := OpenContextStore(path)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	header, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	index, err := store.tableIndex()
+	if err != nil {
+		return nil, err
+	}
+	l := &LazyContext{
+		ctx:       header,
+		storePath: path,
+		index:     index,
+
+		overrides: overrides,
+	}
+	l.applyOverrides()
+	return l, nil
+}
+
+// applyOverrides merges the overrides into the summary and the index entries
+func (l *LazyContext) applyOverrides() {
+	if l.overrides == nil {
+		return
+	}
+	if l.overrides.Summary != "" {
+		l.ctx.Summary = l.overrides.Summary
+	}
+	for name, entry := range l.index {
+		override := l.overrides.forTable(name)
+		if override == nil {
+			continue
+		}
+		if override.Description != "" {
+			entry.Description = override.Description
+		}
+		kept := entry.QualityIssues[:0]
+		for _, issue := range entry.QualityIssues {
+			if !override.deletesIssue(issue) {
+				kept = append(kept, issue)
+			}
+		}
+		entry.QualityIssues = kept
+		// Override notes replace the generated ones and never expire
+		for _, key := range override.DeleteNotes {
+			delete(entry.NoteExpiry, key)
+		}
+		for key := range override.RichContext {
+			delete(entry.NoteExpiry, key)
+		}
+	}
+}
+
+// newTableIndexEntry builds the index entry of a decoded table section
+func newTableIndexEntry(name string, section *tableIndexSection) *TableIndexEntry {
+	entry := &TableIndexEntry{
+		Name:          name,
+		Columns:       make([]string, len(section.Columns)),
+		PrimaryKey:    section.PrimaryKey,
+		ForeignKeys:   section.ForeignKeys,
+		Description:   section.Description,
+		QualityIssues: section.QualityIssues,
+	}
+	for i, col := range section.Columns {
+		entry.Columns[i] = col.Name
+		if col.IsPrimaryKey && len(section.PrimaryKey) == 0 {
+			entry.PrimaryKey = append(entry.PrimaryKey, col.Name)
+		}
+	}
+	for key, raw := range section.RichContext {
+		var note struct {
+			ExpiresAt string `json:"expires_at"`
+		}
+		if err := json.Unmarshal(raw, &note); err == nil && note.ExpiresAt != "" {
+			if entry.NoteExpiry == nil {
+				entry.NoteExpiry = make(map[string]string)
+			}
+			entry.NoteExpiry[key] = note.ExpiresAt
+		}
+	}
+	if entry.Description == "" {
+		entry.Description = section.Comment
+	}
+	if entry.Description == "" {
+		// Last resort: the first note, skipping metadata keys (sorted for a stable choice)
+		keys := make([]string, 0, len(section.RichContext))
+		for key := range section.RichContext {
+			if !strings.HasSuffix(key, "_columns") && !strings.HasSuffix(key, "_indexes") &&
+				!strings.HasSuffix(key, "_rowcount") && !strings.HasSuffix(key, "_foreignkeys") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		if len(keys) > 0 {
+			var note RichContextValue
+			if err := json.Unmarshal(section.RichContext[keys[0]], &note); err == nil {
+				entry.Description = note.Content
+			}
+		}
+	}
+	return entry
+}
+
+// Index the index entries of every table in the file
+func (l *LazyContext) Index() map[string]*TableIndexEntry {
+	return l.index
+}
+
+// Context the shared context holding the tables hydrated so far
+// The same instance is returned on every call; Hydrate adds tables to it.
+func (l *LazyContext) Context() *SharedContext {
+	return l.ctx
+}
+
+// ExpiredNotes expired Rich Context notes of every table as sorted "table.key" names
+// Read from the index, so tables need not be hydrated (overrides already merged).
+func (l *LazyContext) ExpiredNotes(now time.Time) []string {
+	var names []string
+	for tableName, entry := range l.index {
+		for key, expiresAt := range entry.NoteExpiry {
+			if (BusinessNote{ExpiresAt: expiresAt}).Expired(now) {
+				names = append(names, fmt.Sprintf("%s.%s", tableName, key))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AnalyzeJoinPaths computes the context's join paths from the index
+// (files generated before join paths were stored), leaving every table undecoded
+func (l *LazyContext) AnalyzeJoinPaths() {
+	keys := NewSharedContext(l.ctx.DatabaseName, l.ctx.DatabaseType)
+	for name, entry := range l.index {
+		table := &TableMetadata{Name: name, PrimaryKey: entry.PrimaryKey, ForeignKeys: entry.ForeignKeys}
+		for _, col := range entry.Columns {
+			table.Columns = append(table.Columns, ColumnMetadata{Name: col})
+		}
+		keys.Tables[name] = table
+	}
+	keys.AnalyzeJoinPaths()
+
+	l.ctx.mu.Lock()
+	defer l.ctx.mu.Unlock()
+	l.ctx.JoinPaths = keys.JoinPaths
+	if l.ctx.FieldSemantics == nil {
+		l.ctx.FieldSemantics = keys.FieldSemantics
+	}
+}
+
+// Hydrate decodes the named tables into Context (nil = all); unknown names are ignored
+// Tables already hydrated are kept as they are, including corrections made to them.
+func (l *LazyContext) Hydrate(tables []string) (*SharedContext, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if tables == nil {
+		tables = make([]string, 0, len(l.index))
+		for name := range l.index {
+			tables = append(tables, name)
+		}
+	}
+
+	var store *ContextStore
+	if l.storePath != "" {
+		// Opened per call: a pipeline has no close hook, and hydration runs once per question
+		var err error
+		if store, err = OpenContextStore(l.storePath); err != nil {
+			return nil, err
+		}
+		defer store.Close()
+	}
+
+	l.ctx.mu.Lock()
+	defer l.ctx.mu.Unlock()
+	for _, name := range tables {
+		if _, ok := l.index[name]; !ok {
+			continue
+		}
+		if _, done := l.ctx.Tables[name]; done {
+			continue
+		}
+		raw := l.raw[name]
+		if store != nil {
+			data, err := store.tableData(name)
+			if err != nil {
+				return nil, err
+			}
+			raw = data
+		}
+		var table TableMetadata
+		if err := json.Unmarshal(raw, &table); err != nil {
+			return nil, fmt.Errorf("table %s: %w", name, err)
+		}
+		if override := l.overrides.forTable(name); override != nil {
+			override.apply(&table)
+		}
+		l.ctx.Tables[name] = &table
+	}
+	return l.ctx, nil
+}
+
+// Hydrated number of tables decoded so far
+func (l *LazyContext) Hydrated() int {
+	l.ctx.mu.RLock()
+	defer l.ctx.mu.RUnlock()
+	return len(l.ctx.Tables)
+}
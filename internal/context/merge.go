@@ -0,0 +1,171 @@
+package context
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// schemaPrefixPattern table prefixes Merge accepts (same rule as SQLite ATTACH aliases)
+var schemaPrefixPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Merge adds the tables of another database's context under a prefix, for questions that
+// span several databases. Use the alias the database is attached under (DBConfig.Attach for
+// SQLite) so the merged names "prefix.table" are also valid SQL against that connection.
+// Foreign keys stay inside their own database: references are prefixed like the tables.
+// Field semantics, glossary terms, value index entries and embeddings (when produced by
+// the same model) are carried over under the new names and join paths are recomputed.
+// An empty prefix keeps the names as they are; any table name clash is an error and
+// nothing is merged.
+func (c *SharedContext) Merge(other *SharedContext, prefix string) error {
+	if other == c {
+		return fmt.Errorf("cannot merge a context into itself")
+	}
+	if prefix != "" && !schemaPrefixPattern.MatchString(prefix) {
+		return fmt.Errorf("invalid table prefix: %q", prefix)
+	}
+
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rename := func(name string) string {
+		if prefix == "" || name == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+
+	for name := range other.Tables {
+		if existing := c.findTable(rename(name)); existing != nil {
+			return fmt.Errorf("table %s already in context", existing.Name)
+		}
+	}
+
+	if c.Tables == nil {
+		c.Tables = make(map[string]*TableMetadata)
+	}
+	hadTables := len(c.Tables) > 0
+	for name, table := range other.Tables {
+		merged := *table
+		merged.Name = rename(table.Name)
+		merged.Columns = append([]ColumnMetadata(nil), table.Columns...)
+		merged.Indexes = append([]IndexMetadata(nil), table.Indexes...)
+
+		merged.ForeignKeys = make([]ForeignKeyMetadata, len(table.ForeignKeys))
+		for i, fk := range table.ForeignKeys {
+			fk.ReferencedTable = rename(fk.ReferencedTable)
+			merged.ForeignKeys[i] = fk
+		}
+
+		merged.QualityIssues = make([]QualityIssue, len(table.QualityIssues))
+		for i, issue := range table.QualityIssues {
+			issue.Table = rename(issue.Table)
+			merged.QualityIssues[i] = issue
+		}
+
+		merged.RichContext = make(map[string]RichContextValue, len(table.RichContext))
+		for key, value := range table.RichContext {
+			merged.RichContext[key] = value
+		}
+
+		c.Tables[rename(name)] = &merged
+		c.TotalRows += table.RowCount
+	}
+	c.TotalTables = len(c.Tables)
+
+	// Semantics are keyed "table.column..." and name tables in their fields
+	if len(other.FieldSemantics) > 0 && c.FieldSemantics == nil {
+		c.FieldSemantics = make(map[string]*FieldSemantic)
+	}
+	for key, fs := range other.FieldSemantics {
+		merged := *fs
+		merged.TableName = rename(fs.TableName)
+		merged.References = rename(fs.References)
+		merged.Synonyms = append([]string(nil), fs.Synonyms...)
+		c.FieldSemantics[rename(key)] = &merged
+	}
+
+	// Glossary terms the context does not define yet, mapped to the renamed columns
+	for _, entry := range other.Glossary {
+		defined := false
+		for _, existing := range c.Glossary {
+			defined = defined || strings.EqualFold(existing.Term, entry.Term)
+		}
+		if defined {
+			continue
+		}
+		merged := entry
+		merged.Columns = make([]string, len(entry.Columns))
+		for i, column := range entry.Columns {
+			merged.Columns[i] = rename(column)
+		}
+		c.Glossary = append(c.Glossary, merged)
+	}
+	sort.Slice(c.Glossary, func(i, j int) bool {
+		return strings.ToLower(c.Glossary[i].Term) < strings.ToLower(c.Glossary[j].Term)
+	})
+
+	// A fresh index: the merged one is rebuilt lazily on first search
+	if other.ValueIndex != nil && len(other.ValueIndex.Columns) > 0 {
+		index := NewValueIndex()
+		if c.ValueIndex != nil {
+			for key, values := range c.ValueIndex.Columns {
+				index.Columns[key] = values
+			}
+		}
+		for key, values := range other.ValueIndex.Columns {
+			index.Columns[rename(key)] = values
+		}
+		c.ValueIndex = index
+	}
+
+	c.mergeEmbeddings(other.Embeddings, rename, hadTables)
+
+	if len(c.JoinPaths) > 0 || len(other.JoinPaths) > 0 {
+		c.analyzeJoinPaths()
+	}
+
+	if !c.Quiet {
+		fmt.Printf("[Context] Merged %s: %d tables (prefix %q)\n", other.DatabaseName, len(other.Tables), prefix)
+	}
+	return nil
+}
+
+// mergeEmbeddings carries over table and column vectors of a merged context (caller holds
+// the lock). Vectors of another model are not comparable, and vectors for only part of an
+// existing schema would bias linking; both are skipped (run EmbedSchema on the result).
+func (c *SharedContext) mergeEmbeddings(other *SchemaEmbeddings, rename func(string) string, hadTables bool) {
+	if other == nil {
+		return
+	}
+	if c.Embeddings == nil {
+		if hadTables {
+			if !c.Quiet {
+				fmt.Printf("[Context] ⚠️  Embeddings of %q not merged: the context has none for its own tables\n", other.Model)
+			}
+			return
+		}
+		c.Embeddings = &SchemaEmbeddings{Model: other.Model}
+	}
+	if c.Embeddings.Model != other.Model {
+		if !c.Quiet {
+			fmt.Printf("[Context] ⚠️  Embeddings of model %q not merged (context uses %q)\n", other.Model, c.Embeddings.Model)
+		}
+		return
+	}
+	if c.Embeddings.Tables == nil {
+		c.Embeddings.Tables = make(map[string][]float32)
+	}
+	if c.Embeddings.Columns == nil {
+		c.Embeddings.Columns = make(map[string][]float32)
+	}
+	for key, vector := range other.Tables {
+		c.Embeddings.Tables[strings.ToLower(rename(key))] = vector
+	}
+	for key, vector := range other.Columns {
+		c.Embeddings.Columns[strings.ToLower(rename(key))] = vector
+	}
+}
@@ -2,6 +2,7 @@ package context
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -9,59 +10,53 @@ import (
 // Note: caller must already hold the lock
 func (c *SharedContext) GenerateMermaidER() *SchemaDiagram {
 	// No lock needed, caller already holds it
+	return &SchemaDiagram{
+		Format:      "mermaid-er",
+		Description: "Entity-Relationship diagram showing database schema and relationships",
+		Content:     c.mermaidER(c.relationships()),
+	}
+}
+
+// RenderMermaidER renders the schema and the given relationships as a Mermaid ER diagram
+func (c *SharedContext) RenderMermaidER(rels []Relationship) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.mermaidER(rels)
+}
 
+// mermaidER writes the Mermaid ER diagram (caller holds the lock)
+func (c *SharedContext) mermaidER(rels []Relationship) string {
 	var sb strings.Builder
 
 	// ER diagram header
 	sb.WriteString("erDiagram\n")
 
-	// Collect all relationships
-	relationships := make(map[string]bool) // for dedup
-
-	for _, table := range c.Tables {
-		for _, fk := range table.ForeignKeys {
-			// Format: TABLE1 ||--o{ TABLE2 : "relationship"
-			// ||--o{ represents one-to-many
-			refTable := strings.ToUpper(fk.ReferencedTable)
-			currentTable := strings.ToUpper(table.Name)
-
-			// Relationship: use column name as label
-			relationKey := fmt.Sprintf("%s_%s_%s", refTable, currentTable, fk.ColumnName)
-
-			if !relationships[relationKey] {
-				sb.WriteString(fmt.Sprintf("    %s ||--o{ %s : \"has\"\n",
-					refTable, currentTable))
-				relationships[relationKey] = true
-			}
+	// Relationships: PARENT ||--o{ CHILD, markers from the estimated cardinality
+	for _, rel := range rels {
+		parent := "||"
+		if rel.Optional {
+			parent = "|o" // Child rows may have no parent
 		}
+		child := "o{"
+		if rel.Cardinality == CardinalityOneToOne {
+			child = "o|"
+		}
+		sb.WriteString(fmt.Sprintf("    %s %s--%s %s : \"%s\"\n",
+			diagramName(strings.ToUpper(rel.ToTable)), parent, child, diagramName(strings.ToUpper(rel.FromTable)), rel.Label()))
 	}
 
 	sb.WriteString("\n")
 
 	// Add table structure definitions
-	for _, table := range c.Tables {
-		tableName := strings.ToUpper(table.Name)
+	for _, table := range c.sortedTables() {
+		tableName := diagramName(strings.ToUpper(table.Name))
 		sb.WriteString(fmt.Sprintf("    %s {\n", tableName))
 
 		// Add column definitions
 		for _, col := range table.Columns {
 			// Format: type column_name PK/FK
-			var tags []string
-
-			if col.IsPrimaryKey {
-				tags = append(tags, "PK")
-			}
-
-			// Check if foreign key
-			for _, fk := range table.ForeignKeys {
-				if fk.ColumnName == col.Name {
-					tags = append(tags, "FK")
-					break
-				}
-			}
-
 			tagStr := ""
-			if len(tags) > 0 {
+			if tags := columnKeyTags(table, col); len(tags) > 0 {
 				tagStr = " " + strings.Join(tags, ",")
 			}
 
@@ -69,17 +64,52 @@ func (c *SharedContext) GenerateMermaidER() *SchemaDiagram {
 			colType := simplifyType(col.Type)
 
 			sb.WriteString(fmt.Sprintf("        %s %s%s\n",
-				colType, col.Name, tagStr))
+				colType, diagramName(col.Name), tagStr))
 		}
 
 		sb.WriteString("    }\n")
 	}
 
-	return &SchemaDiagram{
-		Format:      "mermaid-er",
-		Description: "Entity-Relationship diagram showing database schema and relationships",
-		Content:     sb.String(),
+	return sb.String()
+}
+
+// sortedTables tables ordered by name (caller holds the lock)
+func (c *SharedContext) sortedTables() []*TableMetadata {
+	tables := make([]*TableMetadata, 0, len(c.Tables))
+	for _, table := range c.Tables {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Name < tables[j].Name
+	})
+	return tables
+}
+
+// columnKeyTags PK / FK markers of a column
+func columnKeyTags(table *TableMetadata, col ColumnMetadata) []string {
+	var tags []string
+	if col.IsPrimaryKey {
+		tags = append(tags, "PK")
+	}
+
+	// Check if foreign key
+	for _, fk := range table.ForeignKeys {
+		if fk.HasColumn(col.Name) {
+			tags = append(tags, "FK")
+			break
+		}
 	}
+	return tags
+}
+
+// diagramName identifier safe for Mermaid (spaces and symbols become "_")
+func diagramName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, name)
 }
 
 // simplifyType simplifies type names for Mermaid display
@@ -0,0 +1,153 @@
+package context
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// qualityIssueSuffix key suffix of a worker note about one column ("horsepower_quality_issue")
+const qualityIssueSuffix = "_quality_issue"
+
+// RejectedNote a worker note dropped by CheckWorkerNotes
+type RejectedNote struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+var (
+	// quotedSnippetPattern `...` spans of a note
+	quotedSnippetPattern = regexp.MustCompile("`([^`]+)`")
+	// inlineQueryPattern an unquoted SELECT ... FROM ... up to the end of its sentence
+	inlineQueryPattern = regexp.MustCompile(`(?is)\bSELECT\s[^;\n]+?\bFROM\b[^;\n]*?(?:;|\n|\.\s|\.?$)`)
+	// funcCallPattern a function call, which marks a quoted snippet as an SQL expression
+	funcCallPattern = regexp.MustCompile(`\b[A-Za-z_]\w*\s*\(`)
+)
+
+// clausePrefixes clause keywords a quoted fix may start with ("WHERE TRIM(code) = 'X'")
+var clausePrefixes = []string{"WHERE ", "JOIN ", "LEFT JOIN ", "INNER JOIN ", "GROUP BY ", "ORDER BY ", "HAVING "}
+
+// CheckWorkerNotes drops generated notes of a table that would mislead SQL generation: a
+// <column>_quality_issue key or an affected column naming no column of the table, or SQL
+// in the note (a `quoted` fix or an inline SELECT) that parse rejects. Only notes written
+// by a worker or rewritten by the reviewer are checked; hand-written and proofread notes
+// are left alone, and the column check is skipped while the table has no columns.
+// Returns the dropped notes sorted by key.
+func (c *SharedContext) CheckWorkerNotes(tableName string, parse func(sql string) error) []RejectedNote {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	table, exists := c.Tables[tableName]
+	if !exists {
+		return nil
+	}
+	columns := make(map[string]bool, len(table.Columns))
+	for _, col := range table.Columns {
+		columns[squashName(col.Name)] = true
+	}
+
+	var rejected []RejectedNote
+	for key, note := range table.RichContext {
+		if note.Provenance == nil || (note.Provenance.Source != "worker" && note.Provenance.Source != "review") {
+			continue
+		}
+		reason := ""
+		if column := unknownColumn(key, note.AffectedColumns, columns); column != "" {
+			reason = fmt.Sprintf("no column %q in %s", column, tableName)
+		} else if sql, err := firstBadSQL(note.Content, parse); err != nil {
+			reason = fmt.Sprintf("SQL %q does not parse: %v", sql, err)
+		}
+		if reason == "" {
+			continue
+		}
+		delete(table.RichContext, key)
+		rejected = append(rejected, RejectedNote{Key: key, Reason: reason})
+	}
+
+	sort.Slice(rejected, func(i, j int) bool { return rejected[i].Key < rejected[j].Key })
+	if !c.Quiet {
+		for _, note := range rejected {
+			fmt.Printf("[Context] Dropped note %s.%s: %s\n", tableName, note.Key, note.Reason)
+		}
+	}
+	return rejected
+}
+
+// qualityIssueColumn the column a <column>_quality_issue key is about
+func qualityIssueColumn(key string) (string, bool) {
+	lower := strings.ToLower(key)
+	if !strings.HasSuffix(lower, qualityIssueSuffix) || len(lower) == len(qualityIssueSuffix) {
+		return "", false
+	}
+	return key[:len(key)-len(qualityIssueSuffix)], true
+}
+
+// unknownColumn the first column a note names (by its key or affected columns) that is
+// not in columns ("" when all exist, or when columns is empty)
+func unknownColumn(key string, affected []string, columns map[string]bool) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	if column, ok := qualityIssueColumn(key); ok && !columns[squashName(column)] {
+		return column
+	}
+	for _, column := range affected {
+		if !columns[squashName(column)] {
+			return column
+		}
+	}
+	return ""
+}
+
+// firstBadSQL the first SQL snippet of a note that parse rejects, with its error
+func firstBadSQL(content string, parse func(sql string) error) (string, error) {
+	for _, snippet := range sqlSnippets(content) {
+		if err := parse(snippet.query); err != nil {
+			return snippet.text, err
+		}
+	}
+	return "", nil
+}
+
+// sqlSnippet SQL found in a note: the text as written and the query that is parsed
+type sqlSnippet struct {
+	text  string
+	query string
+}
+
+// sqlSnippets SQL in a note: quoted queries, clauses and expressions (wrapped into a query
+// so they parse on their own), then unquoted SELECT statements. Quoted values and names
+// without a function call ("`YYYY-MM-DD`", "`status`") are not SQL fixes and are skipped.
+func sqlSnippets(content string) []sqlSnippet {
+	var snippets []sqlSnippet
+	for _, match := range quotedSnippetPattern.FindAllStringSubmatch(content, -1) {
+		text := strings.TrimSpace(match[1])
+		upper := strings.ToUpper(text)
+		switch {
+		case strings.HasPrefix(upper, "SELECT ") || strings.HasPrefix(upper, "WITH "):
+			snippets = append(snippets, sqlSnippet{text, text})
+		case hasClausePrefix(upper):
+			snippets = append(snippets, sqlSnippet{text, "SELECT * FROM t " + text})
+		case funcCallPattern.MatchString(text):
+			snippets = append(snippets, sqlSnippet{text, "SELECT " + text})
+		}
+	}
+
+	unquoted := quotedSnippetPattern.ReplaceAllString(content, " ")
+	for _, match := range inlineQueryPattern.FindAllString(unquoted, -1) {
+		text := strings.TrimRight(strings.TrimSpace(match), ";.")
+		snippets = append(snippets, sqlSnippet{text, text})
+	}
+	return snippets
+}
+
+// hasClausePrefix reports whether an upper-cased snippet starts with a clause keyword
+func hasClausePrefix(upper string) bool {
+	for _, prefix := range clausePrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package context
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// GeneratedNoteTTL lifetime of notes written by Phase 1 workers (unverified LLM inferences)
+	GeneratedNoteTTL = 7 * 24 * time.Hour
+	// CorrectedNoteTTL lifetime of notes corrected by proofread (checked against query results)
+	CorrectedNoteTTL = 30 * 24 * time.Hour
+)
+
+// NoteExpiry the ExpiresAt value for a note written now with the given lifetime
+func NoteExpiry(ttl time.Duration) string {
+	return time.Now().Add(ttl).Format(time.RFC3339)
+}
+
+// Expired reports whether the note expired before now
+// Notes without ExpiresAt (hand-written, BIRD descriptions) or with an unparseable one never expire.
+func (n BusinessNote) Expired(now time.Time) bool {
+	if n.ExpiresAt == "" {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, n.ExpiresAt)
+	return err == nil && now.After(expiresAt)
+}
+
+// ExpiredNotes expired Rich Context notes as sorted "table.key" names
+func (c *SharedContext) ExpiredNotes(now time.Time) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var names []string
+	for tableName, table := range c.Tables {
+		for key, note := range table.RichContext {
+			if note.Expired(now) {
+				names = append(names, fmt.Sprintf("%s.%s", tableName, key))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PruneExpiredNotes removes Rich Context notes expired before now, returning how many were removed
+func (c *SharedContext) PruneExpiredNotes(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for _, table := range c.Tables {
+		for key, note := range table.RichContext {
+			if note.Expired(now) {
+				delete(table.RichContext, key)
+				removed++
+			}
+		}
+	}
+	return removed
+}
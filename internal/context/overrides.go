@@ -0,0 +1,172 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// OverrideSource NoteProvenance source of notes written by an overrides file
+const OverrideSource = "override"
+
+// ContextOverrides human corrections merged over a generated context at load time
+// Stored next to the context as <db>.overrides.json, so regenerating the context never
+// loses them:
+//
+//	{"tables": {"orders": {
+//	    "description": "One row per customer order",
+//	    "columns": {"status": "0 = cancelled, 1 = paid"},
+//	    "rich_context": {"status_values": "0=cancelled, 1=paid, 2=refunded"},
+//	    "delete_notes": ["business_rules"],
+//	    "delete_issues": ["amount:type_mismatch", "notes"]}}}
+type ContextOverrides struct {
+	Summary string                    `json:"summary,omitempty"` // Replaces the database overview
+	Tables  map[string]*TableOverride `json:"tables"`
+}
+
+// TableOverride corrections of one table
+type TableOverride struct {
+	Description  string            `json:"description,omitempty"`   // Replaces the table description
+	Columns      map[string]string `json:"columns,omitempty"`       // Column → description (replaces the comment)
+	RichContext  map[string]string `json:"rich_context,omitempty"`  // Note key → content (added or replaced, never expires)
+	DeleteNotes  []string          `json:"delete_notes,omitempty"`  // Note keys to drop
+	DeleteIssues []string          `json:"delete_issues,omitempty"` // Quality issues to drop: "column" (all) or "column:type"
+}
+
+// overridesSuffix file name ending of overrides files
+const overridesSuffix = ".overrides.json"
+
+// OverridesPath overrides file of a context file or store
+// (orders.json, orders.ctxdb → orders.overrides.json)
+func OverridesPath(contextPath string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(contextPath, ".json"), ContextStoreExt) + overridesSuffix
+}
+
+// LoadOverrides reads an overrides file; a missing file is no overrides (nil, nil)
+func LoadOverrides(path string) (*ContextOverrides, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides: %w", err)
+	}
+	var overrides ContextOverrides
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse overrides %s: %w", path, err)
+	}
+	return &overrides, nil
+}
+
+// LoadContextWithOverrides loads a context file (or every table of a context store) and
+// merges its overrides file over it
+func LoadContextWithOverrides(path string) (*SharedContext, error) {
+	load := LoadContextFromFile
+	if IsContextStore(path) {
+		load = loadContextStore
+	}
+	ctx, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := LoadOverrides(OverridesPath(path))
+	if err != nil {
+		return nil, err
+	}
+	ctx.ApplyOverrides(overrides)
+	return ctx, nil
+}
+
+// ApplyOverrides merges overrides over the context (nil = none)
+// Tables are matched case-insensitively; overrides of tables not in the context are skipped.
+// Returns the number of tables changed.
+func (c *SharedContext) ApplyOverrides(overrides *ContextOverrides) int {
+	if overrides == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if overrides.Summary != "" {
+		c.Summary = overrides.Summary
+	}
+	changed := 0
+	for name, override := range overrides.Tables {
+		table := c.findTable(name)
+		if table == nil {
+			if !c.Quiet {
+				fmt.Printf("[Context] ⚠️  Skipping overrides of unknown table %s\n", name)
+			}
+			continue
+		}
+		override.apply(table)
+		changed++
+	}
+	return changed
+}
+
+// forTable the override of a table (case-insensitive, nil if none)
+func (o *ContextOverrides) forTable(name string) *TableOverride {
+	if o == nil {
+		return nil
+	}
+	for key, override := range o.Tables {
+		if normalizeTableName(key) == normalizeTableName(name) {
+			return override
+		}
+	}
+	return nil
+}
+
+// apply merges the override into one table
+func (o *TableOverride) apply(table *TableMetadata) {
+	if o.Description != "" {
+		table.Description = o.Description
+	}
+	for name, description := range o.Columns {
+		for i, col := range table.Columns {
+			if strings.EqualFold(col.Name, name) {
+				table.Columns[i].Comment = description
+			}
+		}
+	}
+
+	for _, key := range o.DeleteNotes {
+		delete(table.RichContext, key)
+	}
+	if len(o.RichContext) > 0 && table.RichContext == nil {
+		table.RichContext = make(map[string]RichContextValue)
+	}
+	now := time.Now().Format(time.RFC3339)
+	for key, content := range o.RichContext {
+		provenance := &NoteProvenance{Source: OverrideSource, UpdatedAt: now}
+		if previous, ok := table.RichContext[key]; ok {
+			provenance.Previous = previous.Content
+			provenance.Origin = previous.Provenance
+		}
+		table.RichContext[key] = RichContextValue{BusinessNote: BusinessNote{Content: content, Provenance: provenance}}
+	}
+
+	if len(o.DeleteIssues) > 0 {
+		kept := table.QualityIssues[:0]
+		for _, issue := range table.QualityIssues {
+			if !o.deletesIssue(issue) {
+				kept = append(kept, issue)
+			}
+		}
+		table.QualityIssues = kept
+	}
+}
+
+// deletesIssue reports whether a delete_issues entry matches the issue
+func (o *TableOverride) deletesIssue(issue QualityIssue) bool {
+	for _, entry := range o.DeleteIssues {
+		column, issueType, typed := strings.Cut(entry, ":")
+		if strings.EqualFold(column, issue.Column) && (!typed || issueType == issue.Type) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"reactsql/internal/adapter"
+)
+
+// QualityBatchOptions options of RunQualityChecks
+type QualityBatchOptions struct {
+	// Tables to check (empty = every table in the context)
+	Tables []string
+	// Tables checked at once (0 = default 4)
+	Concurrency int
+	// Limit per check query; a slow check is skipped instead of stalling its table (0 = none)
+	CheckTimeout time.Duration
+	// Called after each table finishes, from the finishing goroutine (nil = none)
+	Progress func(QualityProgress)
+}
+
+// QualityProgress progress of a batch after one table finished
+type QualityProgress struct {
+	Done   int
+	Total  int
+	Result QualityTableResult
+}
+
+// QualityTableResult outcome of the checks of one table
+type QualityTableResult struct {
+	Table    string        `json:"table"`
+	Issues   int           `json:"issues"`
+	TimedOut int           `json:"timed_out,omitempty"` // Check queries that hit CheckTimeout
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// QualityReport summary of a batch of quality checks
+type QualityReport struct {
+	Tables     []QualityTableResult `json:"tables"` // In table name order
+	Issues     int                  `json:"issues"`
+	BySeverity map[string]int       `json:"by_severity"`
+	TimedOut   int                  `json:"timed_out"`
+	Failed     int                  `json:"failed"`
+	Duration   time.Duration        `json:"duration"`
+}
+
+// defaultQualityConcurrency tables checked at once when QualityBatchOptions.Concurrency is 0
+const defaultQualityConcurrency = 4
+
+// RunQualityChecks runs the QualityChecker of several tables concurrently
+// Tables are independent: each checker only writes its own table and the locked shared indexes.
+func RunQualityChecks(ctx context.Context, dbAdapter adapter.DBAdapter, sharedCtx *SharedContext, opts QualityBatchOptions) *QualityReport {
+	tables := opts.Tables
+	if len(tables) == 0 {
+		for tableName := range sharedCtx.Tables {
+			tables = append(tables, tableName)
+		}
+	} else {
+		tables = append([]string(nil), tables...)
+	}
+	sort.Strings(tables)
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultQualityConcurrency
+	}
+
+	start := time.Now()
+	results := make([]QualityTableResult, len(tables))
+	var mu sync.Mutex
+	done := 0
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, tableName := range tables {
+		wg.Add(1)
+		sem <- struct{}{} // acquire slot
+
+		go func(i int, tableName string) {
+			defer wg.Done()
+			defer func() { <-sem }() // release slot
+
+			result := runTableQualityCheck(ctx, dbAdapter, sharedCtx, tableName, opts.CheckTimeout)
+			results[i] = result
+
+			mu.Lock()
+			done++
+			if opts.Progress != nil {
+				opts.Progress(QualityProgress{Done: done, Total: len(tables), Result: result})
+			}
+			mu.Unlock()
+		}(i, tableName)
+	}
+	wg.Wait()
+
+	report := &QualityReport{
+		Tables:     results,
+		BySeverity: make(map[string]int),
+		Duration:   time.Since(start),
+	}
+	for _, result := range results {
+		report.Issues += result.Issues
+		report.TimedOut += result.TimedOut
+		if result.Error != "" {
+			report.Failed++
+		}
+		if table, exists := sharedCtx.Tables[result.Table]; exists {
+			for _, issue := range table.QualityIssues {
+				report.BySeverity[issue.Severity]++
+			}
+		}
+	}
+	return report
+}
+
+// runTableQualityCheck runs the checks of one table and records the outcome
+func runTableQualityCheck(ctx context.Context, dbAdapter adapter.DBAdapter, sharedCtx *SharedContext, tableName string, checkTimeout time.Duration) QualityTableResult {
+	start := time.Now()
+	result := QualityTableResult{Table: tableName}
+
+	qc := NewQualityChecker(dbAdapter, sharedCtx, tableName)
+	qc.quiet = true
+	qc.checkTimeout = checkTimeout
+	if err := qc.RunAll(ctx); err != nil {
+		result.Error = err.Error()
+	}
+
+	result.TimedOut = qc.timedOut
+	result.Duration = time.Since(start)
+	if table, exists := sharedCtx.Tables[tableName]; exists {
+		result.Issues = len(table.QualityIssues)
+	}
+	return result
+}
+
+// Headline one-line totals of the report
+func (r *QualityReport) Headline() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Checked %d tables in %s: %d issues", len(r.Tables), r.Duration.Round(time.Millisecond), r.Issues))
+
+	if len(r.BySeverity) > 0 {
+		severities := make([]string, 0, len(r.BySeverity))
+		for severity := range r.BySeverity {
+			severities = append(severities, severity)
+		}
+		sort.Strings(severities)
+		parts := make([]string, len(severities))
+		for i, severity := range severities {
+			parts[i] = fmt.Sprintf("%d %s", r.BySeverity[severity], severity)
+		}
+		sb.WriteString(" (" + strings.Join(parts, ", ") + ")")
+	}
+	if r.TimedOut > 0 {
+		sb.WriteString(fmt.Sprintf(", %d checks timed out", r.TimedOut))
+	}
+	if r.Failed > 0 {
+		sb.WriteString(fmt.Sprintf(", %d tables failed", r.Failed))
+	}
+	return sb.String()
+}
+
+// Summary readable report: the headline, then tables with issues, timeouts or errors
+func (r *QualityReport) Summary() string {
+	var sb strings.Builder
+	sb.WriteString(r.Headline() + "\n")
+
+	for _, result := range r.Tables {
+		if result.Issues == 0 && result.TimedOut == 0 && result.Error == "" {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  - %s: %d issues (%s)", result.Table, result.Issues, result.Duration.Round(time.Millisecond)))
+		if result.TimedOut > 0 {
+			sb.WriteString(fmt.Sprintf(", %d checks timed out", result.TimedOut))
+		}
+		if result.Error != "" {
+			sb.WriteString(", error: " + result.Error)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
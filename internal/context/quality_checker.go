@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"reactsql/internal/adapter"
 )
@@ -15,16 +16,34 @@ type QualityChecker struct {
 	sharedCtx *SharedContext
 	tableName string
 	quiet     bool
+
+	thresholds QualityThresholds // SharedContext.QualityThresholds with defaults filled
+
+	checkTimeout time.Duration // Limit per check query (0 = none); a timed-out check reports no issue
+	timedOut     int           // Check queries that hit checkTimeout in the last RunAll
+
+	queries []string // Queries of the running check (issue provenance)
+
+	sample     string  // Sample query of a huge table ("" = whole table), see chooseSample
+	sampleRows int64   // Rows in the sample
+	sampleRate float64 // sampleRows / table rows
+	usedSample bool    // The running check read the sample
 }
 
 // NewQualityChecker creates a new quality checker for a table
 func NewQualityChecker(dbAdapter adapter.DBAdapter, sharedCtx *SharedContext, tableName string) *QualityChecker {
-	return &QualityChecker{
+	qc := &QualityChecker{
 		adapter:   dbAdapter,
 		sharedCtx: sharedCtx,
 		tableName: tableName,
 		quiet:     sharedCtx.Quiet,
 	}
+	if sharedCtx.QualityThresholds != nil {
+		qc.thresholds = sharedCtx.QualityThresholds.withDefaults()
+	} else {
+		qc.thresholds = DefaultQualityThresholds()
+	}
+	return qc
 }
 
 // RunAll executes all quality checks and value stats collection for the table.
@@ -38,34 +57,60 @@ func (qc *QualityChecker) RunAll(ctx context.Context) error {
 	if table.RowCount == 0 {
 		return nil // skip empty tables
 	}
+	qc.timedOut = 0
+
+	// Fill structure from adapter introspection if Phase 1 left it incomplete
+	if len(table.Columns) == 0 {
+		columns, err := qc.adapter.GetColumns(ctx, qc.tableName)
+		if err != nil {
+			return err
+		}
+		table.Columns = columnsFromInfo(columns)
+	}
+	if len(table.ForeignKeys) == 0 {
+		if fks, err := qc.adapter.GetForeignKeys(ctx, qc.tableName); err == nil {
+			table.ForeignKeys = foreignKeysFromInfo(fks)
+		}
+	}
+	if len(table.Indexes) == 0 {
+		if idxs, err := qc.adapter.GetIndexes(ctx, qc.tableName); err == nil {
+			table.Indexes = indexesFromInfo(idxs)
+		}
+	}
+
+	qc.chooseSample(ctx, table.RowCount)
+	checkedRows := qc.checkedRows(table.RowCount)
 
 	var allIssues []QualityIssue
+	checked := qc.checkedColumns(table)
 
 	// 1. Check quality issues for each column
 	for i, col := range table.Columns {
 		colType := strings.ToUpper(col.Type)
 
 		// 1a. Whitespace check (TEXT columns only)
-		if isTextType(colType) {
-			if issue := qc.checkWhitespace(ctx, col.Name); issue != nil {
+		if isTextType(colType) && checked[col.Name] {
+			if issue := qc.traced(func() *QualityIssue { return qc.checkWhitespace(ctx, col.Name) }); issue != nil {
 				allIssues = append(allIssues, *issue)
 			}
 		}
 
 		// 1b. Type mismatch: TEXT storing purely numeric values
-		if isTextType(colType) {
-			if issue := qc.checkTypeMismatch(ctx, col.Name, table.RowCount); issue != nil {
+		if isTextType(colType) && checked[col.Name] {
+			if issue := qc.traced(func() *QualityIssue { return qc.checkTypeMismatch(ctx, col.Name, table.RowCount) }); issue != nil {
 				allIssues = append(allIssues, *issue)
 			}
 		}
 
 		// 1c. Collect value stats for every column
+		qc.queries = nil
 		stats := qc.collectValueStats(ctx, col.Name, colType, table.RowCount)
+		statsProvenance := qc.provenance()
 		if stats != nil {
 			table.Columns[i].ValueStats = stats
 
 			// Derive quality issue from stats
-			if stats.NullPercent > 50 {
+			if stats.NullPercent > qc.thresholds.NullHeavyPercent {
 				allIssues = append(allIssues, QualityIssue{
 					Table:       qc.tableName,
 					Column:      col.Name,
@@ -74,6 +119,7 @@ func (qc *QualityChecker) RunAll(ctx context.Context) error {
 					Description: fmt.Sprintf("%.0f%% NULL values (%d/%d)", stats.NullPercent, stats.NullCount, table.RowCount),
 					SQLFix:      fmt.Sprintf("WHERE %s IS NOT NULL", quoteIdent(col.Name)),
 					AffectedOps: []string{"WHERE", "JOIN", "GROUP BY"},
+					Provenance:  statsProvenance,
 				})
 			}
 
@@ -86,38 +132,146 @@ func (qc *QualityChecker) RunAll(ctx context.Context) error {
 					Description: fmt.Sprintf("Contains %d empty string values in addition to NULLs", stats.EmptyCount),
 					SQLFix:      fmt.Sprintf("WHERE %s IS NOT NULL AND %s != ''", quoteIdent(col.Name), quoteIdent(col.Name)),
 					AffectedOps: []string{"WHERE", "GROUP BY"},
+					Provenance:  statsProvenance,
 				})
 			}
 		}
+
+		// 1d. Distinct literals for the value index (entity linking)
+		if isTextType(colType) {
+			if values := qc.collectColumnValues(ctx, col.Name); len(values) > 0 {
+				qc.sharedCtx.SetColumnValues(qc.tableName, col.Name, values)
+			}
+		}
+
+		// 1e. Same value in differing case ('USA' vs 'usa'); NOCASE columns already match
+		if isTextType(colType) && !col.CaseInsensitive && checked[col.Name] {
+			if issue := qc.traced(func() *QualityIssue { return qc.checkCaseVariants(ctx, col.Name, isForeignKeyColumn(table, col.Name)) }); issue != nil {
+				allIssues = append(allIssues, *issue)
+			}
+		}
+
+		// 1f. Date/time format of TEXT columns (recipe to filter and extract parts)
+		if isTextType(colType) && checked[col.Name] {
+			if issue := qc.traced(func() *QualityIssue { return qc.checkDateFormat(ctx, col.Name) }); issue != nil {
+				allIssues = append(allIssues, *issue)
+			}
+		}
 	}
 
 	// 2. Check orphan records for each foreign key
-	for _, fk := range table.ForeignKeys {
-		if issue := qc.checkOrphanRecords(ctx, fk); issue != nil {
-			allIssues = append(allIssues, *issue)
+	if !qc.thresholds.SkipOrphanChecks {
+		for _, fk := range table.ForeignKeys {
+			if issue := qc.traced(func() *QualityIssue { return qc.checkOrphanRecords(ctx, fk, checkedRows) }); issue != nil {
+				allIssues = append(allIssues, *issue)
+			}
 		}
 	}
 
+	// 2b. Declared primary key actually unique, and fully duplicated rows
+	if issue := qc.traced(func() *QualityIssue { return qc.checkPrimaryKeyUnique(ctx, table) }); issue != nil {
+		allIssues = append(allIssues, *issue)
+	}
+	if issue := qc.traced(func() *QualityIssue { return qc.checkDuplicateRows(ctx, checkedRows) }); issue != nil {
+		allIssues = append(allIssues, *issue)
+	}
+
+	// 2c. Foreign key columns of large tables without an index (from metadata, no query)
+	qc.queries = nil
+	for _, issue := range qc.checkJoinIndexes(table) {
+		issue.Provenance = qc.provenance()
+		allIssues = append(allIssues, issue)
+	}
+
+	// 3. Functional dependencies between columns (recorded as FieldSemantics)
+	dependencies := qc.checkDependencies(ctx, table)
+
+	// 4. Units and scales of numeric columns (recorded as FieldSemantics)
+	units := qc.checkUnits(table)
+
 	// Save to SharedContext
 	table.QualityIssues = allIssues
 
 	if !qc.quiet {
-		fmt.Printf("[QualityChecker] %s: found %d issues, checked %d columns\n",
-			qc.tableName, len(allIssues), len(table.Columns))
+		fmt.Printf("[QualityChecker] %s: found %d issues, %d column dependencies, %d units, checked %d columns\n",
+			qc.tableName, len(allIssues), dependencies, units, len(checked))
 	}
 
 	return nil
 }
 
+// checkedColumns the columns that get the per-column checks: all of them, or with
+// MaxCheckedColumns set, primary and foreign key columns first, then the rest in table order
+func (qc *QualityChecker) checkedColumns(table *TableMetadata) map[string]bool {
+	limit := qc.thresholds.MaxCheckedColumns
+	if limit <= 0 || limit > len(table.Columns) {
+		limit = len(table.Columns)
+	}
+	checked := make(map[string]bool, limit)
+	for _, keysOnly := range []bool{true, false} {
+		for _, col := range table.Columns {
+			if len(checked) == limit {
+				return checked
+			}
+			if keysOnly && !col.IsPrimaryKey && !isForeignKeyColumn(table, col.Name) {
+				continue
+			}
+			checked[col.Name] = true
+		}
+	}
+	return checked
+}
+
+// traced runs one check, recording the queries it ran in the issue's provenance
+// and, when it read the sample of a huge table, the sample rate
+func (qc *QualityChecker) traced(check func() *QualityIssue) *QualityIssue {
+	qc.queries = nil
+	qc.usedSample = false
+	issue := check()
+	if issue != nil {
+		issue.Provenance = qc.provenance()
+		if qc.usedSample {
+			issue.SampleRate = qc.sampleRate
+			issue.Description += fmt.Sprintf(" [in a %.2f%% sample of %d rows]", qc.sampleRate*100, qc.sampleRows)
+		}
+	}
+	return issue
+}
+
+// provenance of an issue found by the queries run since qc.queries was reset
+func (qc *QualityChecker) provenance() *NoteProvenance {
+	return &NoteProvenance{
+		Source:    "quality_checker",
+		Queries:   qc.queries,
+		UpdatedAt: time.Now().Format(time.RFC3339),
+	}
+}
+
+// query runs one check query, bounded by checkTimeout
+func (qc *QualityChecker) query(ctx context.Context, sql string) (*adapter.QueryResult, error) {
+	qc.queries = append(qc.queries, sql)
+	if qc.checkTimeout <= 0 {
+		return qc.adapter.ExecuteQuery(ctx, sql)
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, qc.checkTimeout)
+	defer cancel()
+
+	result, err := qc.adapter.ExecuteQuery(checkCtx, sql)
+	if err != nil && checkCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		qc.timedOut++
+	}
+	return result, err
+}
+
 // checkWhitespace checks if a TEXT column contains leading/trailing whitespace
 func (qc *QualityChecker) checkWhitespace(ctx context.Context, colName string) *QualityIssue {
 	sql := fmt.Sprintf(
 		`SELECT %s FROM %s WHERE %s IS NOT NULL AND %s != TRIM(%s) LIMIT 5`,
-		quoteIdent(colName), quoteIdent(qc.tableName),
+		quoteIdent(colName), qc.from(""),
 		quoteIdent(colName), quoteIdent(colName), quoteIdent(colName),
 	)
 
-	result, err := qc.adapter.ExecuteQuery(ctx, sql)
+	result, err := qc.query(ctx, sql)
 	if err != nil || result.RowCount == 0 {
 		return nil
 	}
@@ -152,14 +306,14 @@ func (qc *QualityChecker) checkTypeMismatch(ctx context.Context, colName string,
 	// Count non-null, non-empty values
 	countSQL := fmt.Sprintf(
 		`SELECT COUNT(*) as cnt FROM %s WHERE %s IS NOT NULL AND %s != ''`,
-		quoteIdent(qc.tableName), quoteIdent(colName), quoteIdent(colName),
+		qc.from(""), quoteIdent(colName), quoteIdent(colName),
 	)
-	countResult, err := qc.adapter.ExecuteQuery(ctx, countSQL)
+	countResult, err := qc.query(ctx, countSQL)
 	if err != nil {
 		return nil
 	}
 	nonEmptyCount := extractCount(countResult)
-	if nonEmptyCount < 5 {
+	if nonEmptyCount < qc.thresholds.MinTypeCheckValues {
 		return nil // too few values to judge
 	}
 
@@ -174,21 +328,22 @@ func (qc *QualityChecker) checkTypeMismatch(ctx context.Context, colName string,
 	)
 
 	// Simpler approach: try GLOB pattern for digits (SQLite-specific but we're on SQLite)
+	// Dates and times ("2018-01-10", "10:30") are not numbers: see collectDateStats
 	numericSQL = fmt.Sprintf(
-		`SELECT COUNT(*) as cnt FROM %s WHERE %s IS NOT NULL AND %s != '' AND %s GLOB '[0-9]*' AND %s NOT GLOB '*[a-zA-Z]*'`,
-		quoteIdent(qc.tableName),
-		quoteIdent(colName), quoteIdent(colName),
+		`SELECT COUNT(*) as cnt FROM %s WHERE %s IS NOT NULL AND %s != '' AND %s GLOB '[0-9]*' AND %s NOT GLOB '*[a-zA-Z]*' AND %s NOT GLOB '*[0-9][/:-][0-9]*'`,
+		qc.from(""),
 		quoteIdent(colName), quoteIdent(colName),
+		quoteIdent(colName), quoteIdent(colName), quoteIdent(colName),
 	)
 
-	numResult, err := qc.adapter.ExecuteQuery(ctx, numericSQL)
+	numResult, err := qc.query(ctx, numericSQL)
 	if err != nil {
 		return nil
 	}
 	numericCount := extractCount(numResult)
 
 	ratio := float64(numericCount) / float64(nonEmptyCount)
-	if ratio < 0.8 {
+	if ratio < qc.thresholds.NumericTextRatio {
 		return nil // not predominantly numeric
 	}
 
@@ -203,36 +358,186 @@ func (qc *QualityChecker) checkTypeMismatch(ctx context.Context, colName string,
 	}
 }
 
+// checkCaseVariants checks if a TEXT column holds the same value in differing case
+// Join keys are critical: '=' on such a column silently drops matches.
+func (qc *QualityChecker) checkCaseVariants(ctx context.Context, colName string, joinKey bool) *QualityIssue {
+	col := quoteIdent(colName)
+	sql := fmt.Sprintf(
+		`SELECT LOWER(%s) as k, GROUP_CONCAT(DISTINCT %s) as variants FROM %s WHERE %s IS NOT NULL GROUP BY LOWER(%s) HAVING COUNT(DISTINCT %s) > 1 LIMIT 50`,
+		col, col, qc.from(""), col, col, col,
+	)
+	result, err := qc.query(ctx, sql)
+	if err != nil || result.RowCount == 0 {
+		return nil
+	}
+
+	examples := make([]string, 0, 3)
+	for _, row := range result.Rows {
+		variants := strings.Split(fmt.Sprintf("%v", row["variants"]), ",")
+		for i, v := range variants {
+			variants[i] = "'" + v + "'"
+		}
+		examples = append(examples, strings.Join(variants, " vs "))
+		if len(examples) >= 3 {
+			break
+		}
+	}
+
+	groups := fmt.Sprintf("%d", result.RowCount)
+	if result.RowCount >= 50 {
+		groups = "50+"
+	}
+	severity := "warning"
+	affected := []string{"WHERE", "GROUP BY"}
+	if joinKey {
+		severity = "critical"
+		affected = []string{"JOIN", "WHERE", "GROUP BY"}
+	}
+	return &QualityIssue{
+		Table:       qc.tableName,
+		Column:      colName,
+		Type:        "case_variants",
+		Severity:    severity,
+		Description: fmt.Sprintf("Same value stored in differing case (%s values, e.g. %s)", groups, examples[0]),
+		SQLFix:      fmt.Sprintf("%s = 'x' COLLATE NOCASE, or LOWER(%s) = LOWER('x') (also GROUP BY LOWER(%s))", col, col, col),
+		AffectedOps: affected,
+		Examples:    examples,
+	}
+}
+
+// isForeignKeyColumn reports whether a column is a foreign key of the table
+func isForeignKeyColumn(table *TableMetadata, colName string) bool {
+	for _, fk := range table.ForeignKeys {
+		if fk.HasColumn(colName) {
+			return true
+		}
+	}
+	return false
+}
+
 // checkOrphanRecords checks for orphan records in a foreign key relationship
-func (qc *QualityChecker) checkOrphanRecords(ctx context.Context, fk ForeignKeyMetadata) *QualityIssue {
+// Composite keys match on all columns; rows with any NULL key column are not orphans.
+func (qc *QualityChecker) checkOrphanRecords(ctx context.Context, fk ForeignKeyMetadata, totalRows int64) *QualityIssue {
+	refColumns := fk.ReferencedKeyColumns()
+	var on, fix, notNull []string
+	for i, col := range fk.KeyColumns() {
+		if refColumns[i] == "" {
+			return nil // Implicit primary key reference, nothing to compare against
+		}
+		on = append(on, fmt.Sprintf("child.%s = parent.%s", quoteIdent(col), quoteIdent(refColumns[i])))
+		fix = append(fix, fmt.Sprintf("%s.%s = %s.%s", quoteIdent(qc.tableName), quoteIdent(col), quoteIdent(fk.ReferencedTable), quoteIdent(refColumns[i])))
+		notNull = append(notNull, fmt.Sprintf("child.%s IS NOT NULL", quoteIdent(col)))
+	}
 	sql := fmt.Sprintf(
-		`SELECT COUNT(*) as cnt FROM %s child LEFT JOIN %s parent ON child.%s = parent.%s WHERE parent.%s IS NULL AND child.%s IS NOT NULL`,
-		quoteIdent(qc.tableName), quoteIdent(fk.ReferencedTable),
-		quoteIdent(fk.ColumnName), quoteIdent(fk.ReferencedColumn),
-		quoteIdent(fk.ReferencedColumn), quoteIdent(fk.ColumnName),
+		`SELECT COUNT(*) as cnt FROM %s LEFT JOIN %s parent ON %s WHERE parent.%s IS NULL AND %s`,
+		qc.from("child"), quoteIdent(fk.ReferencedTable),
+		strings.Join(on, " AND "), quoteIdent(refColumns[0]), strings.Join(notNull, " AND "),
 	)
 
-	result, err := qc.adapter.ExecuteQuery(ctx, sql)
+	result, err := qc.query(ctx, sql)
 	if err != nil {
 		return nil
 	}
 
 	orphanCount := extractCount(result)
-	if orphanCount == 0 {
+	if orphanCount == 0 || orphanCount < qc.thresholds.OrphanMinCount {
 		return nil
 	}
 
+	severity := "warning"
+	if critical := qc.thresholds.OrphanCriticalPercent; critical > 0 && totalRows > 0 &&
+		float64(orphanCount)/float64(totalRows)*100 >= critical {
+		severity = "critical"
+	}
+
 	return &QualityIssue{
 		Table:       qc.tableName,
 		Column:      fk.ColumnName,
 		Type:        "orphan",
-		Severity:    "warning",
-		Description: fmt.Sprintf("%d orphan records (%s not in %s.%s)", orphanCount, fk.ColumnName, fk.ReferencedTable, fk.ReferencedColumn),
-		SQLFix:      fmt.Sprintf("LEFT JOIN %s ON %s.%s = %s.%s", quoteIdent(fk.ReferencedTable), quoteIdent(qc.tableName), quoteIdent(fk.ColumnName), quoteIdent(fk.ReferencedTable), quoteIdent(fk.ReferencedColumn)),
+		Severity:    severity,
+		Description: fmt.Sprintf("%d orphan records (%s not in %s)", orphanCount, fk.LocalRef(), fk.TargetRef()),
+		SQLFix:      fmt.Sprintf("LEFT JOIN %s ON %s", quoteIdent(fk.ReferencedTable), strings.Join(fix, " AND ")),
 		AffectedOps: []string{"JOIN"},
 	}
 }
 
+// checkPrimaryKeyUnique checks that the declared primary key has no repeated values
+func (qc *QualityChecker) checkPrimaryKeyUnique(ctx context.Context, table *TableMetadata) *QualityIssue {
+	names := primaryKeyNames(table)
+	if len(names) == 0 {
+		return nil
+	}
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = quoteIdent(name)
+	}
+	keyList := strings.Join(keys, ", ")
+
+	sql := fmt.Sprintf(
+		`SELECT COUNT(*) as cnt, COALESCE(SUM(n), 0) as dup_rows FROM (SELECT COUNT(*) as n FROM %s GROUP BY %s HAVING COUNT(*) > 1) AS v`,
+		quoteIdent(qc.tableName), keyList,
+	)
+	result, err := qc.query(ctx, sql)
+	if err != nil || result.RowCount == 0 {
+		return nil
+	}
+	dupKeys, dupRows := toInt(result.Rows[0]["cnt"]), toInt(result.Rows[0]["dup_rows"])
+	if dupKeys == 0 {
+		return nil
+	}
+
+	fix := fmt.Sprintf("COUNT(DISTINCT %s) to count entities", keyList)
+	if len(keys) > 1 {
+		fix = fmt.Sprintf("SELECT COUNT(*) FROM (SELECT DISTINCT %s FROM %s) to count entities", keyList, quoteIdent(qc.tableName))
+	}
+	return &QualityIssue{
+		Table:       qc.tableName,
+		Column:      strings.Join(names, ", "),
+		Type:        "pk_not_unique",
+		Severity:    "critical",
+		Description: fmt.Sprintf("Declared primary key is not unique: %d key values repeat across %d rows, so COUNT(*) and joins on it over-count", dupKeys, dupRows),
+		SQLFix:      fix,
+		AffectedOps: []string{"JOIN", "GROUP BY", "HAVING"},
+	}
+}
+
+// checkDuplicateRows checks for rows that are identical in every column
+func (qc *QualityChecker) checkDuplicateRows(ctx context.Context, totalRows int64) *QualityIssue {
+	sql := fmt.Sprintf(
+		`SELECT COUNT(*) as cnt FROM (SELECT DISTINCT * FROM %s) AS v`,
+		qc.from(""),
+	)
+	result, err := qc.query(ctx, sql)
+	if err != nil {
+		return nil
+	}
+	distinctRows := extractCount(result)
+	duplicates := totalRows - int64(distinctRows)
+	if distinctRows == 0 || duplicates <= 0 {
+		return nil
+	}
+
+	return &QualityIssue{
+		Table:       qc.tableName,
+		Type:        "duplicate_rows",
+		Severity:    "warning",
+		Description: fmt.Sprintf("%d fully duplicated rows (%d distinct of %d); COUNT(*) and SUM() count each copy", duplicates, distinctRows, totalRows),
+		SQLFix:      fmt.Sprintf("COUNT(DISTINCT <entity column>) instead of COUNT(*), or FROM (SELECT DISTINCT * FROM %s)", quoteIdent(qc.tableName)),
+		AffectedOps: []string{"GROUP BY", "HAVING", "JOIN"},
+	}
+}
+
+// primaryKeyNames names of the primary key columns in definition order
+func primaryKeyNames(table *TableMetadata) []string {
+	var names []string
+	for _, col := range table.Columns {
+		if col.IsPrimaryKey {
+			names = append(names, col.Name)
+		}
+	}
+	return names
+}
+
 // collectValueStats collects value statistics for a column
 func (qc *QualityChecker) collectValueStats(ctx context.Context, colName, colType string, totalRows int64) *ValueStats {
 	if totalRows == 0 {
@@ -246,7 +551,7 @@ func (qc *QualityChecker) collectValueStats(ctx context.Context, colName, colTyp
 		`SELECT COUNT(*) - COUNT(%s) as null_cnt, COUNT(DISTINCT %s) as distinct_cnt FROM %s`,
 		quoteIdent(colName), quoteIdent(colName), quoteIdent(qc.tableName),
 	)
-	basicResult, err := qc.adapter.ExecuteQuery(ctx, basicSQL)
+	basicResult, err := qc.query(ctx, basicSQL)
 	if err != nil {
 		return nil
 	}
@@ -264,20 +569,20 @@ func (qc *QualityChecker) collectValueStats(ctx context.Context, colName, colTyp
 			`SELECT COUNT(*) as cnt FROM %s WHERE %s = ''`,
 			quoteIdent(qc.tableName), quoteIdent(colName),
 		)
-		emptyResult, err := qc.adapter.ExecuteQuery(ctx, emptySQL)
+		emptyResult, err := qc.query(ctx, emptySQL)
 		if err == nil {
 			stats.EmptyCount = extractCount(emptyResult)
 		}
 	}
 
-	// 3. If enumeration type (distinct < 30), collect top values
-	if stats.DistinctCount > 0 && stats.DistinctCount <= 30 {
+	// 3. If enumeration type (few distinct values), collect top values
+	if stats.DistinctCount > 0 && stats.DistinctCount <= qc.thresholds.EnumMaxDistinct {
 		topSQL := fmt.Sprintf(
 			`SELECT %s as val, COUNT(*) as cnt FROM %s WHERE %s IS NOT NULL GROUP BY %s ORDER BY cnt DESC LIMIT 15`,
 			quoteIdent(colName), quoteIdent(qc.tableName),
 			quoteIdent(colName), quoteIdent(colName),
 		)
-		topResult, err := qc.adapter.ExecuteQuery(ctx, topSQL)
+		topResult, err := qc.query(ctx, topSQL)
 		if err == nil {
 			for _, row := range topResult.Rows {
 				val := fmt.Sprintf("%v", row["val"])
@@ -289,6 +594,9 @@ func (qc *QualityChecker) collectValueStats(ctx context.Context, colName, colTyp
 				})
 			}
 		}
+		if isTextType(strings.ToUpper(colType)) {
+			stats.Canonical = qc.collectCanonicalValues(ctx, colName)
+		}
 	}
 
 	// 4. If numeric type, collect range
@@ -301,7 +609,7 @@ func (qc *QualityChecker) collectValueStats(ctx context.Context, colName, colTyp
 			quoteIdent(colName), quoteIdent(colName), quoteIdent(colName),
 			quoteIdent(qc.tableName), quoteIdent(colName),
 		)
-		rangeResult, err := qc.adapter.ExecuteQuery(ctx, rangeSQL)
+		rangeResult, err := qc.query(ctx, rangeSQL)
 		if err == nil && rangeResult.RowCount > 0 {
 			row := rangeResult.Rows[0]
 			stats.Range = &NumericRange{
@@ -309,12 +617,62 @@ func (qc *QualityChecker) collectValueStats(ctx context.Context, colName, colTyp
 				Max: toFloat64(row["max_val"]),
 				Avg: toFloat64(row["avg_val"]),
 			}
+			stats.Histogram = qc.collectNumericHistogram(ctx, colName, stats.Range)
+		}
+	}
+
+	// 5. If temporal (DATE types, or TEXT holding dates), collect date range and yearly histogram
+	if isDateType(upperType) || isTextType(upperType) {
+		if dates, histogram := qc.collectDateStats(ctx, colName, upperType); dates != nil {
+			stats.Dates = dates
+			stats.Histogram = histogram
 		}
 	}
 
 	return stats
 }
 
+// collectColumnValues collects distinct short literals of a TEXT column, most frequent first
+func (qc *QualityChecker) collectColumnValues(ctx context.Context, colName string) []string {
+	valuesSQL := fmt.Sprintf(
+		`SELECT %s as val, COUNT(*) as cnt FROM %s WHERE %s IS NOT NULL AND %s != '' GROUP BY %s ORDER BY cnt DESC LIMIT %d`,
+		quoteIdent(colName), quoteIdent(qc.tableName),
+		quoteIdent(colName), quoteIdent(colName), quoteIdent(colName), maxIndexedValues,
+	)
+	result, err := qc.query(ctx, valuesSQL)
+	if err != nil {
+		return nil
+	}
+
+	var values []string
+	for _, row := range result.Rows {
+		val := fmt.Sprintf("%v", row["val"])
+		if strings.TrimSpace(val) == "" || len(val) > maxIndexedValueLen {
+			continue
+		}
+		values = append(values, val)
+	}
+	return values
+}
+
+// collectCanonicalValues canonicalization map of an enumerated TEXT column (all distinct values)
+func (qc *QualityChecker) collectCanonicalValues(ctx context.Context, colName string) map[string]string {
+	valuesSQL := fmt.Sprintf(
+		`SELECT DISTINCT %s as val FROM %s WHERE %s IS NOT NULL LIMIT %d`,
+		quoteIdent(colName), quoteIdent(qc.tableName), quoteIdent(colName), qc.thresholds.EnumMaxDistinct,
+	)
+	result, err := qc.query(ctx, valuesSQL)
+	if err != nil {
+		return nil
+	}
+
+	values := make([]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		values = append(values, fmt.Sprintf("%v", row["val"]))
+	}
+	return buildCanonicalMap(values)
+}
+
 // --- helper functions ---
 
 func isTextType(colType string) bool {
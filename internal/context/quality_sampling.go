@@ -0,0 +1,99 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+)
+
+// sampleRanges rowid ranges a SQLite sample is spread over
+const sampleRanges = 10
+
+// chooseSample prepares the sample the scan-heavy checks of a huge table run on
+// PostgreSQL uses TABLESAMPLE, SQLite random rowid ranges (seeded by the table name, so reruns
+// check the same rows), anything else the first rows. Tables at or below SampleAboveRows
+// are checked in full.
+func (qc *QualityChecker) chooseSample(ctx context.Context, rowCount int64) {
+	qc.sample, qc.sampleRows, qc.sampleRate = "", 0, 0
+	size := int64(qc.thresholds.SampleRows)
+	if qc.thresholds.SampleAboveRows <= 0 || rowCount <= qc.thresholds.SampleAboveRows || rowCount <= size {
+		return
+	}
+
+	table := quoteIdent(qc.tableName)
+	var sample string
+	switch strings.ToLower(qc.adapter.GetDatabaseType()) {
+	case "postgresql":
+		sample = fmt.Sprintf("SELECT * FROM %s TABLESAMPLE SYSTEM (%.4f) REPEATABLE (42)", table, float64(size)*100/float64(rowCount))
+	case "sqlite":
+		if ranges := qc.rowidRanges(ctx, size); ranges != "" {
+			sample = fmt.Sprintf("SELECT * FROM %s WHERE %s", table, ranges)
+		}
+	}
+	if sample == "" {
+		sample = fmt.Sprintf("SELECT * FROM %s LIMIT %d", table, size)
+	}
+
+	result, err := qc.adapter.ExecuteQuery(ctx, fmt.Sprintf("SELECT COUNT(*) as cnt FROM (%s) AS sampled", sample))
+	if err != nil || result.Error != "" || extractCount(result) == 0 {
+		return // Unusable sample: check the whole table
+	}
+	qc.sample = sample
+	qc.sampleRows = int64(extractCount(result))
+	qc.sampleRate = float64(qc.sampleRows) / float64(rowCount)
+
+	if !qc.quiet {
+		fmt.Printf("[QualityChecker] %s: %d rows, checking a %.2f%% sample (%d rows)\n",
+			qc.tableName, rowCount, qc.sampleRate*100, qc.sampleRows)
+	}
+}
+
+// rowidRanges WHERE condition selecting about size rows in sampleRanges rowid ranges,
+// one at a random offset in each tenth of the rowid span ("" without rowids, e.g. a view)
+func (qc *QualityChecker) rowidRanges(ctx context.Context, size int64) string {
+	result, err := qc.adapter.ExecuteQuery(ctx, fmt.Sprintf("SELECT MIN(rowid) as lo, MAX(rowid) as hi FROM %s", quoteIdent(qc.tableName)))
+	if err != nil || result.Error != "" || result.RowCount == 0 {
+		return ""
+	}
+	lo, hi := int64(toInt(result.Rows[0]["lo"])), int64(toInt(result.Rows[0]["hi"]))
+	step := (hi - lo + 1) / sampleRanges
+	width := size / sampleRanges
+	if step <= width {
+		return ""
+	}
+
+	seed := fnv.New64a()
+	seed.Write([]byte(qc.tableName))
+	rng := rand.New(rand.NewSource(int64(seed.Sum64())))
+	ranges := make([]string, sampleRanges)
+	for i := range ranges {
+		start := lo + int64(i)*step + rng.Int63n(step-width)
+		ranges[i] = fmt.Sprintf("rowid BETWEEN %d AND %d", start, start+width-1)
+	}
+	return strings.Join(ranges, " OR ")
+}
+
+// from FROM item of a sampled check: the table, or its sample on huge tables
+func (qc *QualityChecker) from(alias string) string {
+	if qc.sample == "" {
+		if alias == "" {
+			return quoteIdent(qc.tableName)
+		}
+		return quoteIdent(qc.tableName) + " " + alias
+	}
+	qc.usedSample = true
+	if alias == "" {
+		alias = "sampled"
+	}
+	return fmt.Sprintf("(%s) AS %s", qc.sample, alias)
+}
+
+// checkedRows rows the sampled checks see: the sample size, or the whole table
+func (qc *QualityChecker) checkedRows(rowCount int64) int64 {
+	if qc.sample != "" {
+		return qc.sampleRows
+	}
+	return rowCount
+}
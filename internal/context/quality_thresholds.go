@@ -0,0 +1,89 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// QualityThresholds sensitivity of the QualityChecker; zero fields keep the defaults
+type QualityThresholds struct {
+	// NULL share (percent) above which a column is reported null_heavy (default 50)
+	NullHeavyPercent float64 `json:"null_heavy_percent,omitempty"`
+	// Distinct values up to which a column counts as an enumeration and its top values are kept (default 30)
+	EnumMaxDistinct int `json:"enum_max_distinct,omitempty"`
+	// Share (0-1) of numeric-looking values that makes a TEXT column a type_mismatch (default 0.8)
+	NumericTextRatio float64 `json:"numeric_text_ratio,omitempty"`
+	// Non-empty values a TEXT column needs before its type is judged (default 5)
+	MinTypeCheckValues int `json:"min_type_check_values,omitempty"`
+	// Orphan rows below this are ignored (default 1: every orphan is reported)
+	OrphanMinCount int `json:"orphan_min_count,omitempty"`
+	// Orphan share (percent of the table's rows) from which an orphan issue is critical (default 0: always a warning)
+	OrphanCriticalPercent float64 `json:"orphan_critical_percent,omitempty"`
+	// Tables with more rows run the scan-heavy checks (whitespace, type, case, date format,
+	// orphans, duplicate rows) on a sample (default 0: never sample)
+	SampleAboveRows int64 `json:"sample_above_rows,omitempty"`
+	// Rows in such a sample (default 100000)
+	SampleRows int `json:"sample_rows,omitempty"`
+	// Columns that get the per-column checks (whitespace, type, case, date format); key
+	// columns are checked first (default 0: every column). Value stats cover every column.
+	MaxCheckedColumns int `json:"max_checked_columns,omitempty"`
+	// Skip the orphan record check of foreign keys (default false)
+	SkipOrphanChecks bool `json:"skip_orphan_checks,omitempty"`
+}
+
+// DefaultQualityThresholds thresholds used when none are configured
+func DefaultQualityThresholds() QualityThresholds {
+	return QualityThresholds{
+		NullHeavyPercent:   50,
+		EnumMaxDistinct:    30,
+		NumericTextRatio:   0.8,
+		MinTypeCheckValues: 5,
+		OrphanMinCount:     1,
+		SampleRows:         100000,
+	}
+}
+
+// withDefaults fills unset fields from DefaultQualityThresholds
+func (t QualityThresholds) withDefaults() QualityThresholds {
+	defaults := DefaultQualityThresholds()
+	if t.NullHeavyPercent <= 0 {
+		t.NullHeavyPercent = defaults.NullHeavyPercent
+	}
+	if t.EnumMaxDistinct <= 0 {
+		t.EnumMaxDistinct = defaults.EnumMaxDistinct
+	}
+	if t.NumericTextRatio <= 0 {
+		t.NumericTextRatio = defaults.NumericTextRatio
+	}
+	if t.MinTypeCheckValues <= 0 {
+		t.MinTypeCheckValues = defaults.MinTypeCheckValues
+	}
+	if t.OrphanMinCount <= 0 {
+		t.OrphanMinCount = defaults.OrphanMinCount
+	}
+	if t.SampleRows <= 0 {
+		t.SampleRows = defaults.SampleRows
+	}
+	return t
+}
+
+// LoadQualityThresholds reads thresholds from a JSON file; fields left out keep the defaults
+func LoadQualityThresholds(path string) (*QualityThresholds, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quality thresholds: %w", err)
+	}
+
+	var thresholds QualityThresholds
+	if err := json.Unmarshal(data, &thresholds); err != nil {
+		return nil, fmt.Errorf("failed to parse quality thresholds: %w", err)
+	}
+	if thresholds.MaxCheckedColumns < 0 {
+		return nil, fmt.Errorf("invalid quality thresholds in %s: max_checked_columns must not be negative", path)
+	}
+	if thresholds.NumericTextRatio > 1 || thresholds.NullHeavyPercent > 100 || thresholds.OrphanCriticalPercent > 100 {
+		return nil, fmt.Errorf("invalid quality thresholds in %s: ratios are 0-1, percents 0-100", path)
+	}
+	return &thresholds, nil
+}
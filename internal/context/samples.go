@@ -0,0 +1,89 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// sampleCandidateFactor rows fetched per stored sample, to pick the most complete ones
+const sampleCandidateFactor = 5
+
+// maxSampleValueLength sample values are truncated to this many characters
+const maxSampleValueLength = 40
+
+// TableSamples representative rows of a table, values rendered as text
+type TableSamples struct {
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+// CollectSamples fetches masked rows and stores the k most complete ones (fewest NULL or
+// empty values, original order kept among equals) as the table's Samples
+func (c *SharedContext) CollectSamples(ctx context.Context, db adapter.DBAdapter, tableName string, k int) error {
+	if k <= 0 {
+		return nil
+	}
+
+	rules := adapter.DefaultMaskingRules()
+	rules.MaxTextLength = maxSampleValueLength
+	result, err := db.SampleRows(ctx, tableName, k*sampleCandidateFactor, rules)
+	if err != nil {
+		return fmt.Errorf("failed to sample rows: %w", err)
+	}
+	if result == nil || len(result.Values) == 0 {
+		return nil
+	}
+
+	type candidate struct {
+		values []string
+		empty  int
+	}
+	candidates := make([]candidate, 0, len(result.Values))
+	for _, row := range result.Values {
+		cand := candidate{values: make([]string, len(row))}
+		for i, val := range row {
+			cand.values[i] = sampleValue(val)
+			if val.IsNull() || (val.Kind == adapter.KindText && strings.TrimSpace(val.Text) == "") {
+				cand.empty++
+			}
+		}
+		candidates = append(candidates, cand)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].empty < candidates[j].empty
+	})
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+
+	samples := &TableSamples{Columns: append([]string(nil), result.Columns...)}
+	for _, cand := range candidates {
+		samples.Rows = append(samples.Rows, cand.values)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	table, exists := c.Tables[tableName]
+	if !exists {
+		return fmt.Errorf("table not found: %s", tableName)
+	}
+	table.Samples = samples
+	return nil
+}
+
+// sampleValue renders a sampled value (dates without a time of day as plain dates)
+func sampleValue(val adapter.Value) string {
+	if val.Kind == adapter.KindTime {
+		t := val.Time
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+			return t.Format("2006-01-02")
+		}
+		return t.Format("2006-01-02 15:04:05")
+	}
+	return strings.ReplaceAll(val.String(), "\n", " ")
+}
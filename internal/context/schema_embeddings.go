@@ -0,0 +1,129 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/embeddings"
+)
+
+// SchemaEmbeddings table and column vectors computed at RC generation
+// Lets schema linking and column retrieval compare a question against the schema
+// without embedding the schema again for every query.
+type SchemaEmbeddings struct {
+	Model   string               `json:"model"`   // Embedder model that produced the vectors (questions must use the same)
+	Tables  map[string][]float32 `json:"tables"`  // Lower-cased table name -> vector of its name and descriptions
+	Columns map[string][]float32 `json:"columns"` // Lower-cased "table.column" -> vector of its names and comment
+}
+
+// EmbedderModel model name reported by an embedder's Model method ("" if it has none)
+func EmbedderModel(embedder embeddings.Embedder) string {
+	if named, ok := embedder.(interface{ Model() string }); ok {
+		return named.Model()
+	}
+	return ""
+}
+
+// EmbedSchema computes and stores the vectors of every table and column (replacing old ones)
+func (c *SharedContext) EmbedSchema(ctx context.Context, embedder embeddings.Embedder) error {
+	c.mu.RLock()
+	var keys, texts []string
+	var columnKeys, columnTexts []string
+	for _, table := range c.Tables {
+		keys = append(keys, strings.ToLower(table.Name))
+		texts = append(texts, TableEmbeddingText(table))
+		for _, col := range table.Columns {
+			columnKeys = append(columnKeys, strings.ToLower(table.Name+"."+col.Name))
+			columnTexts = append(columnTexts, ColumnEmbeddingText(table.Name, col))
+		}
+	}
+	c.mu.RUnlock()
+
+	tableVectors, err := embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed tables: %w", err)
+	}
+	columnVectors, err := embedder.EmbedDocuments(ctx, columnTexts)
+	if err != nil {
+		return fmt.Errorf("failed to embed columns: %w", err)
+	}
+	if len(tableVectors) != len(keys) || len(columnVectors) != len(columnKeys) {
+		return fmt.Errorf("embedder returned %d vectors for %d texts", len(tableVectors)+len(columnVectors), len(keys)+len(columnKeys))
+	}
+
+	stored := &SchemaEmbeddings{
+		Model:   EmbedderModel(embedder),
+		Tables:  make(map[string][]float32, len(keys)),
+		Columns: make(map[string][]float32, len(columnKeys)),
+	}
+	for i, key := range keys {
+		stored.Tables[key] = tableVectors[i]
+	}
+	for i, key := range columnKeys {
+		stored.Columns[key] = columnVectors[i]
+	}
+
+	c.mu.Lock()
+	c.Embeddings = stored
+	c.mu.Unlock()
+
+	if !c.Quiet {
+		fmt.Printf("[Context] Embedded schema: %d tables, %d columns\n", len(keys), len(columnKeys))
+	}
+	return nil
+}
+
+// TableVector stored vector of a table (nil if missing)
+func (e *SchemaEmbeddings) TableVector(table string) []float32 {
+	if e == nil {
+		return nil
+	}
+	return e.Tables[strings.ToLower(table)]
+}
+
+// ColumnVector stored vector of a column (nil if missing)
+func (e *SchemaEmbeddings) ColumnVector(table, column string) []float32 {
+	if e == nil {
+		return nil
+	}
+	return e.Columns[strings.ToLower(table+"."+column)]
+}
+
+// TableEmbeddingText text embedded for a table: its name as words plus comment and description
+func TableEmbeddingText(table *TableMetadata) string {
+	parts := []string{identifierWords(table.Name)}
+	if table.Comment != "" {
+		parts = append(parts, table.Comment)
+	}
+	if table.Description != "" {
+		parts = append(parts, table.Description)
+	}
+	return strings.Join(parts, ": ")
+}
+
+// ColumnEmbeddingText text embedded for a column: table and column names as words plus its comment
+func ColumnEmbeddingText(table string, col ColumnMetadata) string {
+	text := identifierWords(table) + " " + identifierWords(col.Name)
+	if col.Comment != "" {
+		text += ": " + col.Comment
+	}
+	return text
+}
+
+// identifierWords splits snake_case and camelCase identifiers into words ("orderDate" → "order date")
+func identifierWords(name string) string {
+	var sb strings.Builder
+	var prev rune
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			r = ' '
+		case i > 0 && r >= 'A' && r <= 'Z' && prev >= 'a' && prev <= 'z':
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(r)
+		prev = r
+	}
+	return strings.Join(strings.Fields(strings.ToLower(sb.String())), " ")
+}
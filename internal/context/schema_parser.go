@@ -23,13 +23,27 @@ type ParsedTable struct {
 	Columns     map[string]string // column_name -> type
 	PrimaryKeys []string
 	ForeignKeys []ParsedForeignKey
+	Generated   map[string]ParsedGenerated // column_name -> generation clause of computed columns
+
+	IsView         bool   // CREATE VIEW (no column list in schema.sql)
+	ViewDefinition string // SELECT the view is defined by
+}
+
+// ParsedGenerated generated (computed) column clause
+type ParsedGenerated struct {
+	Kind       string // "virtual" or "stored"
+	Expression string
 }
 
 // ParsedForeignKey parsed foreign key
+// Columns / ReferencedColumns list every column pair, in key order (several for composite keys);
+// ColumnName and ReferencedColumn are the first pair.
 type ParsedForeignKey struct {
-	ColumnName       string
-	ReferencedTable  string
-	ReferencedColumn string
+	ColumnName        string
+	ReferencedTable   string
+	ReferencedColumn  string
+	Columns           []string
+	ReferencedColumns []string
 }
 
 // Parse parses schema.sql file
@@ -66,6 +80,7 @@ func (p *SchemaParser) Parse() (map[string]*ParsedTable, error) {
 			Columns:     make(map[string]string),
 			PrimaryKeys: []string{},
 			ForeignKeys: []ParsedForeignKey{},
+			Generated:   make(map[string]ParsedGenerated),
 		}
 
 		// Parse table body
@@ -74,6 +89,20 @@ func (p *SchemaParser) Parse() (map[string]*ParsedTable, error) {
 		tables[tableName] = table
 	}
 
+	// Views: name and defining SELECT only, their columns come from the database
+	createViewRegex := regexp.MustCompile(`(?is)CREATE\s+(?:TEMP(?:ORARY)?\s+)?VIEW\s+(?:IF\s+NOT\s+EXISTS\s+)?["'\x60]?(\w+)["'\x60]?(?:\s*\([^)]*\))?\s+AS\s+(.*?)\s*(?:;|$)`)
+	for _, match := range createViewRegex.FindAllStringSubmatch(sql, -1) {
+		tables[match[1]] = &ParsedTable{
+			Name:           strings.ToLower(match[1]),
+			Columns:        make(map[string]string),
+			PrimaryKeys:    []string{},
+			ForeignKeys:    []ParsedForeignKey{},
+			Generated:      make(map[string]ParsedGenerated),
+			IsView:         true,
+			ViewDefinition: strings.Join(strings.Fields(match[2]), " "),
+		}
+	}
+
 	return tables, nil
 }
 
@@ -110,7 +139,8 @@ func parseTableBody(table *ParsedTable, body string) {
 		}
 
 		// Check if foreign key constraint
-		if strings.HasPrefix(itemLower, "foreign key") {
+		if strings.HasPrefix(itemLower, "foreign key") ||
+			(strings.HasPrefix(itemLower, "constraint") && strings.Contains(itemLower, "foreign key")) {
 			parseForeignKey(table, item)
 			continue
 		}
@@ -179,9 +209,21 @@ func parseColumnDefinition(table *ParsedTable, def string) {
 		parseForeignKeyInline(table, columnName, def)
 	}
 
+	// Check for generated column: [GENERATED ALWAYS] AS (expr) [VIRTUAL | STORED]
+	if m := generatedColumnRe.FindStringSubmatch(def); m != nil {
+		kind := "virtual"
+		if strings.EqualFold(m[2], "stored") {
+			kind = "stored"
+		}
+		table.Generated[columnName] = ParsedGenerated{Kind: kind, Expression: strings.TrimSpace(m[1])}
+	}
+
 	table.Columns[columnName] = columnType
 }
 
+// generatedColumnRe generated column clause (expression with at most one level of parens)
+var generatedColumnRe = regexp.MustCompile(`(?i)\b(?:generated\s+always\s+)?as\s*\(((?:[^()]|\([^)]*\))*)\)\s*(virtual|stored)?`)
+
 // parsePrimaryKey parses primary key constraint
 func parsePrimaryKey(table *ParsedTable, constraint string) {
 	// Extract column names from parens
@@ -207,30 +249,55 @@ func parsePrimaryKey(table *ParsedTable, constraint string) {
 
 // parseForeignKey parses FK constraint (table-level)
 func parseForeignKey(table *ParsedTable, constraint string) {
-	// Format: foreign key("col") references `table`("ref_col")
+	// Format: foreign key("col1", "col2") references `table`("ref_col1", "ref_col2")
 
-	// Extract local column (preserve case)
-	colRe := regexp.MustCompile(`(?i)foreign\s+key\s*\(\s*["'\x60]?(\w+)["'\x60]?\s*\)`)
+	// Extract local columns (preserve case)
+	colRe := regexp.MustCompile(`(?i)foreign\s+key\s*\(([^)]*)\)`)
 	colMatches := colRe.FindStringSubmatch(constraint)
 	if len(colMatches) < 2 {
 		return
 	}
-	columnName := colMatches[1]
+	columns := parseColumnList(colMatches[1])
+	if len(columns) == 0 {
+		return
+	}
 
-	// Extract referenced table and column (preserve case)
-	refRe := regexp.MustCompile(`(?i)references\s+["'\x60]?(\w+)["'\x60]?\s*\(\s*["'\x60]?(\w+)["'\x60]?\s*\)`)
+	// Extract referenced table and columns (preserve case); the column list may be left out
+	// to reference the primary key
+	refRe := regexp.MustCompile(`(?i)references\s+["'\x60]?(\w+)["'\x60]?\s*(?:\(([^)]*)\))?`)
 	refMatches := refRe.FindStringSubmatch(constraint)
 	if len(refMatches) < 3 {
 		return
 	}
+	refColumns := parseColumnList(refMatches[2])
+	if len(refColumns) != 0 && len(refColumns) != len(columns) {
+		return
+	}
+	if len(refColumns) == 0 {
+		refColumns = make([]string, len(columns))
+	}
 
 	table.ForeignKeys = append(table.ForeignKeys, ParsedForeignKey{
-		ColumnName:       columnName,
-		ReferencedTable:  strings.ToLower(refMatches[1]), // Table name lowercase
-		ReferencedColumn: refMatches[2],                  // Column name preserves original case
+		ColumnName:        columns[0],
+		ReferencedTable:   strings.ToLower(refMatches[1]), // Table name lowercase
+		ReferencedColumn:  refColumns[0],                  // Column name preserves original case
+		Columns:           columns,
+		ReferencedColumns: refColumns,
 	})
 }
 
+// parseColumnList splits a parenthesized column list, stripping quotes
+func parseColumnList(list string) []string {
+	var columns []string
+	for _, col := range strings.Split(list, ",") {
+		col = strings.Trim(strings.TrimSpace(col), "\"'`[]")
+		if col != "" {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
 // parseForeignKeyInline parses inline FK (column-level)
 func parseForeignKeyInline(table *ParsedTable, columnName, def string) {
 	// Format: REFERENCES table(column)
@@ -241,8 +308,10 @@ func parseForeignKeyInline(table *ParsedTable, columnName, def string) {
 	}
 
 	table.ForeignKeys = append(table.ForeignKeys, ParsedForeignKey{
-		ColumnName:       columnName,
-		ReferencedTable:  strings.ToLower(refMatches[1]), // Table name lowercase
-		ReferencedColumn: refMatches[2],                  // Column name preserves original case
+		ColumnName:        columnName,
+		ReferencedTable:   strings.ToLower(refMatches[1]), // Table name lowercase
+		ReferencedColumn:  refMatches[2],                  // Column name preserves original case
+		Columns:           []string{columnName},
+		ReferencedColumns: []string{refMatches[2]},
 	})
 }
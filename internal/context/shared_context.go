@@ -7,6 +7,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"reactsql/internal/adapter"
 )
 
 // TaskStatus task status enum
@@ -44,6 +46,14 @@ type TaskInfo struct {
 	EndTime     time.Time              `json:"end_time,omitempty"`
 	Result      map[string]interface{} `json:"result,omitempty"`
 	Error       string                 `json:"error,omitempty"`
+	Retries     []TaskRetry            `json:"retries,omitempty"`
+}
+
+// TaskRetry one phase rerun after its first attempt failed
+type TaskRetry struct {
+	Phase     string `json:"phase"`
+	Error     string `json:"error"`     // Why the first attempt failed
+	Recovered bool   `json:"recovered"` // The retry succeeded
 }
 
 // SchemaDiagram database relationship diagram
@@ -61,6 +71,12 @@ type SharedContext struct {
 	Version      string    `json:"version,omitempty"`
 	CollectedAt  time.Time `json:"collected_at"`
 
+	// Database overview: domain, key entities, main relationships (3-5 sentences, LLM-generated)
+	Summary string `json:"summary,omitempty"`
+
+	// Business glossary: domain terms ("ARR", "churn"), their definitions and the columns they map to
+	Glossary []GlossaryTerm `json:"glossary,omitempty"`
+
 	// Schema diagram
 	SchemaDiagram *SchemaDiagram `json:"schema_diagram,omitempty"`
 
@@ -75,6 +91,12 @@ type SharedContext struct {
 	// Field semantic info
 	FieldSemantics map[string]*FieldSemantic `json:"field_semantics,omitempty"`
 
+	// Cell-value index for entity linking
+	ValueIndex *ValueIndex `json:"value_index,omitempty"`
+
+	// Table / column embeddings for schema linking
+	Embeddings *SchemaEmbeddings `json:"embeddings,omitempty"`
+
 	// Task registry (not saved to JSON)
 	tasks map[string]*TaskInfo `json:"-"`
 
@@ -86,12 +108,39 @@ type SharedContext struct {
 
 	// Quiet suppresses verbose log output (used by gen_all_dev multi-progress mode)
 	Quiet bool `json:"-"`
+
+	// SampleRowCount rows per table workers store as Samples (0 = off)
+	SampleRowCount int `json:"-"`
+
+	// QualityThresholds sensitivity of the QualityChecker (nil = defaults)
+	QualityThresholds *QualityThresholds `json:"-"`
+
+	// ModelName model recorded in the provenance of generated notes
+	ModelName string `json:"-"`
 }
 
 // BusinessNote Rich Context entry (content + expiry)
 type BusinessNote struct {
-	Content   string `json:"content"`
-	ExpiresAt string `json:"expires_at"`
+	Content         string          `json:"content"`
+	ExpiresAt       string          `json:"expires_at"`
+	Severity        string          `json:"severity,omitempty"`         // critical/warning/info (worker notes written as JSON)
+	AffectedColumns []string        `json:"affected_columns,omitempty"` // Columns the note is about
+	Provenance      *NoteProvenance `json:"provenance,omitempty"`       // Who produced the note: the generating agent, or the correction
+}
+
+// NoteProvenance origin of a Rich Context note or quality issue
+// Traces a wrong note found during proofread analysis back to the agent, model and queries
+// that produced it.
+type NoteProvenance struct {
+	Source    string   `json:"source"`            // "worker" | "review" | "quality_checker" | "proofread"
+	Agent     string   `json:"agent,omitempty"`   // Agent ID, e.g. "worker_orders"
+	Model     string   `json:"model,omitempty"`   // LLM that wrote the note (empty for deterministic checks)
+	Queries   []string `json:"queries,omitempty"` // SQL run since the agent's previous note (its evidence)
+	Reason    string   `json:"reason,omitempty"`
+	Previous  string   `json:"previous,omitempty"` // Content before the correction
+	UpdatedAt string   `json:"updated_at"`
+
+	Origin *NoteProvenance `json:"origin,omitempty"` // Provenance of the note a correction replaced
 }
 
 // RichContextValue supports two Rich Context value formats
@@ -122,22 +171,28 @@ func (r *RichContextValue) UnmarshalJSON(data []byte) error {
 type QualityIssue struct {
 	Table       string   `json:"table"`
 	Column      string   `json:"column"`
-	Type        string   `json:"type"`         // whitespace/type_mismatch/orphan/null_heavy/empty_string
+	Type        string   `json:"type"`         // whitespace/type_mismatch/orphan/null_heavy/empty_string/missing_index
 	Severity    string   `json:"severity"`     // critical/warning/info
 	Description string   `json:"description"`
 	SQLFix      string   `json:"sql_fix"`      // Recommended SQL fix snippet
 	AffectedOps []string `json:"affected_ops"` // ["JOIN", "WHERE", "GROUP BY", "ORDER BY"]
 	Examples    []string `json:"examples,omitempty"`
+	SampleRate  float64  `json:"sample_rate,omitempty"` // Share of rows the check ran on (omitted = whole table)
+
+	Provenance *NoteProvenance `json:"provenance,omitempty"` // Check queries that found the issue
 }
 
 // ValueStats column value statistics
 type ValueStats struct {
-	DistinctCount int              `json:"distinct_count"`
-	NullCount     int              `json:"null_count"`
-	NullPercent   float64          `json:"null_percent"`
-	EmptyCount    int              `json:"empty_count,omitempty"`    // For TEXT columns: count of ''
-	TopValues     []ValueFrequency `json:"top_values,omitempty"`    // Enumeration values (distinct < 30)
-	Range         *NumericRange    `json:"range,omitempty"`         // For numeric columns
+	DistinctCount int               `json:"distinct_count"`
+	NullCount     int               `json:"null_count"`
+	NullPercent   float64           `json:"null_percent"`
+	EmptyCount    int               `json:"empty_count,omitempty"` // For TEXT columns: count of ''
+	TopValues     []ValueFrequency  `json:"top_values,omitempty"`  // Enumeration values (distinct <= QualityThresholds.EnumMaxDistinct)
+	Range         *NumericRange     `json:"range,omitempty"`       // For numeric columns
+	Dates         *DateRange        `json:"dates,omitempty"`       // For temporal columns (DATE types or date-formatted TEXT)
+	Histogram     []HistogramBucket `json:"histogram,omitempty"`   // Numeric: equal-width buckets; temporal: per year (per month within one year)
+	Canonical     map[string]string `json:"canonical,omitempty"`   // Enumerated TEXT: CanonicalKey form → exact stored literal
 }
 
 // ValueFrequency value with frequency
@@ -154,6 +209,18 @@ type NumericRange struct {
 	Avg float64 `json:"avg"`
 }
 
+// DateRange earliest and latest date of a temporal column (YYYY-MM-DD)
+type DateRange struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+// HistogramBucket row count of one value bucket ("10..20", "2019", "2019-03")
+type HistogramBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
 // TableMetadata table metadata
 type TableMetadata struct {
 	Name        string                      `json:"name"`
@@ -168,6 +235,16 @@ type TableMetadata struct {
 
 	// Structured quality issues (deterministic, not LLM-generated)
 	QualityIssues []QualityIssue `json:"quality_issues,omitempty"`
+
+	// Representative rows (PII-masked, truncated) shown to the generator
+	Samples *TableSamples `json:"samples,omitempty"`
+
+	// Views are read-only: queried like tables, never written to
+	IsView         bool   `json:"is_view,omitempty"`
+	ViewDefinition string `json:"view_definition,omitempty"` // SELECT the view is defined by
+
+	// How the table's worker ran: steps, queries, tokens, duration (nil for hand-built contexts)
+	Generation *WorkerTelemetry `json:"generation,omitempty"`
 }
 
 // ColumnMetadata column metadata
@@ -179,6 +256,12 @@ type ColumnMetadata struct {
 	DefaultValue string      `json:"default,omitempty"`
 	IsPrimaryKey bool        `json:"is_primary_key,omitempty"`
 	ValueStats   *ValueStats `json:"value_stats,omitempty"` // Deterministic value statistics
+
+	Collation       string `json:"collation,omitempty"`        // Explicit collation (e.g. NOCASE, utf8mb4_general_ci)
+	CaseInsensitive bool   `json:"case_insensitive,omitempty"` // '=' ignores case ('usa' matches 'USA')
+
+	Generated  string `json:"generated,omitempty"`  // Computed column: "virtual" or "stored" (read-only)
+	Expression string `json:"expression,omitempty"` // Generation expression of a computed column
 }
 
 // IndexMetadata index metadata
@@ -190,28 +273,38 @@ type IndexMetadata struct {
 }
 
 // ForeignKeyMetadata foreign key metadata
+// Composite keys list all their columns in Columns / ReferencedColumns; ColumnName and
+// ReferencedColumn hold the first pair.
 type ForeignKeyMetadata struct {
-	ColumnName       string `json:"column_name"`       // Local column name
-	ReferencedTable  string `json:"referenced_table"`  // Referenced table name
-	ReferencedColumn string `json:"referenced_column"` // Referenced column name
+	ColumnName        string       `json:"column_name"`                  // Local column name
+	ReferencedTable   string       `json:"referenced_table"`             // Referenced table name
+	ReferencedColumn  string       `json:"referenced_column"`            // Referenced column name
+	Columns           []string     `json:"columns,omitempty"`            // All local columns of a composite key, in key order
+	ReferencedColumns []string     `json:"referenced_columns,omitempty"` // Referenced columns matching Columns
+	Inferred          bool         `json:"inferred,omitempty"`           // Not declared; found by value overlap (InferForeignKeys)
+	Confidence        float64      `json:"confidence,omitempty"`         // Inferred keys: share of distinct values found in the referenced column
+	JoinQuality       *JoinQuality `json:"join_quality,omitempty"`       // Measured join behavior (AnalyzeJoinQuality)
 }
 
 // JoinPath JOIN path info
 type JoinPath struct {
-	FromTable   string   `json:"from_table"`   // Source table
-	ToTable     string   `json:"to_table"`     // Target table
-	Path        []string `json:"path"`         // Full path (including intermediate tables)
-	JoinClauses []string `json:"join_clauses"` // JOIN clause list
-	Description string   `json:"description"`  // Path description
+	FromTable   string   `json:"from_table"`         // Source table
+	ToTable     string   `json:"to_table"`           // Target table
+	Path        []string `json:"path"`               // Full path (including intermediate tables)
+	JoinClauses []string `json:"join_clauses"`       // JOIN clause list
+	JoinSQL     string   `json:"join_sql,omitempty"` // Ready-to-paste FROM ... JOIN ... ON ... chain
+	Description string   `json:"description"`        // Path description
 }
 
 // FieldSemantic field semantic info
 type FieldSemantic struct {
-	TableName   string `json:"table_name"`           // Table name
-	ColumnName  string `json:"column_name"`          // Column name
-	StorageType string `json:"storage_type"`         // Storage type: foreign_key, name, id, etc.
-	References  string `json:"references,omitempty"` // Referenced table.column
-	Note        string `json:"note"`                 // Semantic note
+	TableName   string   `json:"table_name"`           // Table name
+	ColumnName  string   `json:"column_name"`          // Column name
+	StorageType string   `json:"storage_type"`         // Storage type: foreign_key, name, id, synonyms, etc.
+	References  string   `json:"references,omitempty"` // Referenced table.column
+	Note        string   `json:"note"`                 // Semantic note
+	Synonyms    []string `json:"synonyms,omitempty"`   // Natural-language names of the column (StorageType "synonyms")
+	Unit        string   `json:"unit,omitempty"`       // Detected unit and scale, e.g. "percent 0-100" (StorageType "unit")
 }
 
 // NewSharedContext creates shared context
@@ -246,6 +339,9 @@ func (c *SharedContext) LoadSchemaFromFile(schemaPath string) error {
 			Indexes:     []IndexMetadata{},
 			ForeignKeys: []ForeignKeyMetadata{},
 			RichContext: make(map[string]RichContextValue),
+
+			IsView:         parsedTable.IsView,
+			ViewDefinition: parsedTable.ViewDefinition,
 		}
 
 		// Convert column info
@@ -255,6 +351,10 @@ func (c *SharedContext) LoadSchemaFromFile(schemaPath string) error {
 				Type:     colType,
 				Nullable: true, // Default nullable, SQLite characteristic
 			}
+			if gen, ok := parsedTable.Generated[colName]; ok {
+				col.Generated = gen.Kind
+				col.Expression = gen.Expression
+			}
 
 			// Check if primary key
 			for _, pk := range parsedTable.PrimaryKeys {
@@ -269,16 +369,15 @@ func (c *SharedContext) LoadSchemaFromFile(schemaPath string) error {
 
 		// Convert foreign key info
 		for _, fk := range parsedTable.ForeignKeys {
-			table.ForeignKeys = append(table.ForeignKeys, ForeignKeyMetadata{
-				ColumnName:       fk.ColumnName,
-				ReferencedTable:  fk.ReferencedTable,
-				ReferencedColumn: fk.ReferencedColumn,
-			})
+			table.ForeignKeys = append(table.ForeignKeys, newForeignKey(fk.Columns, fk.ReferencedTable, fk.ReferencedColumns))
 		}
 
 		// If table exists (from DB query), merge info
 		if existingTable, exists := c.Tables[tableName]; exists {
-			// Keep RowCount and RichContext
+			// Keep RowCount and RichContext; views keep their introspected columns
+			if table.IsView {
+				table.Columns = existingTable.Columns
+			}
 			table.RowCount = existingTable.RowCount
 			table.Description = existingTable.Description
 			table.RichContext = existingTable.RichContext
@@ -379,6 +478,19 @@ func (c *SharedContext) FailTask(taskID string, err error) error {
 	return nil
 }
 
+// RecordTaskRetry appends a phase retry to the task info
+func (c *SharedContext) RecordTaskRetry(taskID string, retry TaskRetry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	task, exists := c.tasks[taskID]
+	if !exists {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	task.Retries = append(task.Retries, retry)
+	return nil
+}
+
 // SetData sets temp data
 func (c *SharedContext) SetData(key string, value interface{}) {
 	c.mu.Lock()
@@ -388,7 +500,14 @@ func (c *SharedContext) SetData(key string, value interface{}) {
 
 // SetTableRichContext sets table Rich Context
 // key determined by LLM, e.g.:"status_enum_meaning", "business_rules" etc.
-func (c *SharedContext) SetTableRichContext(tableName, key, content, expiresAt string) error {
+// provenance records the producing agent (nil = unknown).
+func (c *SharedContext) SetTableRichContext(tableName, key, content, expiresAt string, provenance *NoteProvenance) error {
+	return c.SetTableNote(tableName, key, BusinessNote{Content: content, ExpiresAt: expiresAt, Provenance: provenance})
+}
+
+// SetTableNote sets a table Rich Context note with all its fields
+// Like SetTableRichContext, a table that is not registered yet is created.
+func (c *SharedContext) SetTableNote(tableName, key string, note BusinessNote) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -409,15 +528,68 @@ func (c *SharedContext) SetTableRichContext(tableName, key, content, expiresAt s
 		table.RichContext = make(map[string]RichContextValue)
 	}
 
-	table.RichContext[key] = RichContextValue{
-		BusinessNote: BusinessNote{
-			Content:   content,
-			ExpiresAt: expiresAt,
-		},
-	}
+	table.RichContext[key] = RichContextValue{BusinessNote: note}
 	return nil
 }
 
+// CorrectTableRichContext replaces an existing table note, returning the previous content
+// Unlike SetTableRichContext, unknown tables and keys are an error (corrections never add notes).
+func (c *SharedContext) CorrectTableRichContext(tableName, key string, note BusinessNote) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	table, exists := c.Tables[tableName]
+	if !exists {
+		return "", fmt.Errorf("table '%s' not found in context", tableName)
+	}
+	previous, exists := table.RichContext[key]
+	if !exists {
+		return "", fmt.Errorf("note key '%s' not found in table '%s'", key, tableName)
+	}
+
+	if note.Provenance != nil && note.Provenance.Origin == nil {
+		note.Provenance.Origin = previous.Provenance
+	}
+	table.RichContext[key] = RichContextValue{BusinessNote: note}
+	return previous.Content, nil
+}
+
+// RemoveTableRichContext deletes a table note, reporting whether it existed
+func (c *SharedContext) RemoveTableRichContext(tableName, key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	table, exists := c.Tables[tableName]
+	if !exists {
+		return false
+	}
+	if _, exists := table.RichContext[key]; !exists {
+		return false
+	}
+	delete(table.RichContext, key)
+	return true
+}
+
+// TableSnapshot a copy of a table's metadata that agents can read while other workers
+// write to the context (columns, quality issues and notes are copied; other fields shared)
+func (c *SharedContext) TableSnapshot(tableName string) (*TableMetadata, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	table, exists := c.Tables[tableName]
+	if !exists {
+		return nil, false
+	}
+	snapshot := *table
+	snapshot.Columns = append([]ColumnMetadata(nil), table.Columns...)
+	snapshot.QualityIssues = append([]QualityIssue(nil), table.QualityIssues...)
+	snapshot.RichContext = make(map[string]RichContextValue, len(table.RichContext))
+	for key, note := range table.RichContext {
+		snapshot.RichContext[key] = note
+	}
+	return &snapshot, true
+}
+
 // SetTableDescription sets table business description
 func (c *SharedContext) SetTableDescription(tableName, description string) error {
 	c.mu.Lock()
@@ -440,6 +612,13 @@ func (c *SharedContext) SetTableDescription(tableName, description string) error
 	return nil
 }
 
+// SetSummary sets the whole-database overview paragraph
+func (c *SharedContext) SetSummary(summary string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Summary = summary
+}
+
 // SetTableQualityIssues sets structured quality issues for a table
 func (c *SharedContext) SetTableQualityIssues(tableName string, issues []QualityIssue) error {
 	c.mu.Lock()
@@ -474,6 +653,28 @@ func (c *SharedContext) SetColumnValueStats(tableName, columnName string, stats
 	return fmt.Errorf("column not found: %s.%s", tableName, columnName)
 }
 
+// SetColumnComment sets a column comment unless it already has one (column matched case-insensitively)
+func (c *SharedContext) SetColumnComment(tableName, columnName, comment string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	table, exists := c.Tables[tableName]
+	if !exists {
+		return fmt.Errorf("table not found: %s", tableName)
+	}
+
+	for i, col := range table.Columns {
+		if strings.EqualFold(col.Name, columnName) {
+			if table.Columns[i].Comment == "" {
+				table.Columns[i].Comment = comment
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("column not found: %s.%s", tableName, columnName)
+}
+
 // GetData gets data
 func (c *SharedContext) GetData(key string) (interface{}, bool) {
 	c.mu.RLock()
@@ -655,6 +856,8 @@ func (c *SharedContext) BuildTableMetadata(tableName string) {
 	// Parse column info
 	if columnsData, ok := c.tempData[tableName+"_columns"]; ok {
 		switch cols := columnsData.(type) {
+		case []adapter.ColumnInfo:
+			table.Columns = append(table.Columns, columnsFromInfo(cols)...)
 		case []interface{}:
 			for _, colData := range cols {
 				if colMap, ok := colData.(map[string]interface{}); ok {
@@ -674,6 +877,8 @@ func (c *SharedContext) BuildTableMetadata(tableName string) {
 	if indexesData, ok := c.tempData[tableName+"_indexes"]; ok {
 		indexMap := make(map[string]*IndexMetadata)
 		switch idxs := indexesData.(type) {
+		case []adapter.IndexInfo:
+			table.Indexes = append(table.Indexes, indexesFromInfo(idxs)...)
 		case []interface{}:
 			for _, idxData := range idxs {
 				if idxMap, ok := idxData.(map[string]interface{}); ok {
@@ -731,6 +936,10 @@ func (c *SharedContext) BuildTableMetadata(tableName string) {
 	// Parse FK info
 	if foreignKeysData, ok := c.tempData[tableName+"_foreignkeys"]; ok {
 		switch fks := foreignKeysData.(type) {
+		case []adapter.ForeignKeyInfo:
+			// Introspected FKs come first; keys loaded from schema.sql or tables.json
+			// (hand-annotated, often more complete) are kept unless they duplicate one
+			table.ForeignKeys = mergeForeignKeys(table.ForeignKeys, foreignKeysFromInfo(fks))
 		case []interface{}:
 			for _, fkData := range fks {
 				if fkMap, ok := fkData.(map[string]interface{}); ok {
@@ -772,6 +981,12 @@ func (c *SharedContext) BuildTableMetadata(tableName string) {
 		}
 	}
 
+	// View discovered by the coordinator
+	if view, ok := c.tempData[tableName+"_view"].(adapter.ViewInfo); ok {
+		table.IsView = true
+		table.ViewDefinition = view.Definition
+	}
+
 	c.Tables[tableName] = table
 	if !c.Quiet {
 		fmt.Printf("[Context] Built metadata for table: %s (%d columns, %d indexes, %d rows)\n",
@@ -820,8 +1035,10 @@ func (c *SharedContext) buildTablesFromTempData() {
 
 		// Parse column info
 		if columnsData, ok := c.tempData[tableName+"_columns"]; ok {
-			// Try two types: []interface{} and []map[string]interface{}
+			// Try typed introspection results, then []interface{} and []map[string]interface{}
 			switch cols := columnsData.(type) {
+			case []adapter.ColumnInfo:
+				table.Columns = append(table.Columns, columnsFromInfo(cols)...)
 			case []interface{}:
 				for _, colData := range cols {
 					if colMap, ok := colData.(map[string]interface{}); ok {
@@ -861,6 +1078,8 @@ func (c *SharedContext) buildTablesFromTempData() {
 		if indexesData, ok := c.tempData[tableName+"_indexes"]; ok {
 			indexMap := make(map[string]*IndexMetadata)
 			switch idxs := indexesData.(type) {
+			case []adapter.IndexInfo:
+				table.Indexes = append(table.Indexes, indexesFromInfo(idxs)...)
 			case []interface{}:
 				for _, idxData := range idxs {
 					if idxMap, ok := idxData.(map[string]interface{}); ok {
@@ -950,6 +1169,42 @@ func (c *SharedContext) buildTablesFromTempData() {
 	c.TotalTables = len(c.Tables)
 }
 
+// columnsFromInfo converts adapter introspection columns to ColumnMetadata
+func columnsFromInfo(cols []adapter.ColumnInfo) []ColumnMetadata {
+	result := make([]ColumnMetadata, 0, len(cols))
+	for _, col := range cols {
+		result = append(result, ColumnMetadata{
+			Name:         col.Name,
+			Type:         col.Type,
+			Comment:      col.Comment,
+			Nullable:     col.Nullable,
+			DefaultValue: col.DefaultValue,
+			IsPrimaryKey: col.IsPrimaryKey,
+
+			Collation:       col.Collation,
+			CaseInsensitive: col.CaseInsensitive,
+
+			Generated:  col.Generated,
+			Expression: col.Expression,
+		})
+	}
+	return result
+}
+
+// indexesFromInfo converts adapter introspection indexes to IndexMetadata
+func indexesFromInfo(idxs []adapter.IndexInfo) []IndexMetadata {
+	result := make([]IndexMetadata, 0, len(idxs))
+	for _, idx := range idxs {
+		result = append(result, IndexMetadata{
+			Name:      idx.Name,
+			Columns:   idx.Columns,
+			IsUnique:  idx.IsUnique,
+			IsPrimary: idx.IsPrimary,
+		})
+	}
+	return result
+}
+
 // parseColumnMetadata parses column metadata (supports different DB formats)
 func parseColumnMetadata(colMap map[string]interface{}, dbType string) ColumnMetadata {
 	col := ColumnMetadata{}
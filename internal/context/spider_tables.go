@@ -0,0 +1,206 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SpiderDatabase one database entry of Spider's tables.json
+// Its foreign keys are annotated by hand, so they are more complete than the keys declared
+// in the SQLite files or their schema.sql.
+type SpiderDatabase struct {
+	DBID        string            `json:"db_id"`
+	TableNames  []string          `json:"table_names_original"`
+	ColumnNames []spiderColumn    `json:"column_names_original"` // Index 0 is the "*" pseudo-column
+	ColumnTypes []string          `json:"column_types"`          // "text", "number", "time", "boolean", "others"
+	PrimaryKeys []json.RawMessage `json:"primary_keys"`          // Column index, or a list of them for a composite key
+	ForeignKeys [][2]int          `json:"foreign_keys"`          // [column, referenced column] indexes
+}
+
+// spiderColumn [table index, column name] pair (table -1 = "*")
+type spiderColumn struct {
+	Table int
+	Name  string
+}
+
+// UnmarshalJSON decodes the [table index, name] pair
+func (c *spiderColumn) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("column entry %s is not a [table, name] pair", data)
+	}
+	if err := json.Unmarshal(pair[0], &c.Table); err != nil {
+		return err
+	}
+	return json.Unmarshal(pair[1], &c.Name)
+}
+
+// LoadSpiderTables reads Spider's tables.json, keyed by db_id
+func LoadSpiderTables(path string) (map[string]*SpiderDatabase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var entries []*SpiderDatabase
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	databases := make(map[string]*SpiderDatabase, len(entries))
+	for _, entry := range entries {
+		databases[entry.DBID] = entry
+	}
+	return databases, nil
+}
+
+// primaryKeyColumns column indexes of the primary keys (composite keys flattened)
+func (d *SpiderDatabase) primaryKeyColumns() map[int]bool {
+	columns := make(map[int]bool)
+	for _, raw := range d.PrimaryKeys {
+		var single int
+		if err := json.Unmarshal(raw, &single); err == nil {
+			columns[single] = true
+			continue
+		}
+		var composite []int
+		if err := json.Unmarshal(raw, &composite); err == nil {
+			for _, column := range composite {
+				columns[column] = true
+			}
+		}
+	}
+	return columns
+}
+
+// column the column at index i (ok false when out of range or "*")
+func (d *SpiderDatabase) column(i int) (table string, column spiderColumn, ok bool) {
+	if i <= 0 || i >= len(d.ColumnNames) {
+		return "", spiderColumn{}, false
+	}
+	column = d.ColumnNames[i]
+	if column.Table < 0 || column.Table >= len(d.TableNames) {
+		return "", spiderColumn{}, false
+	}
+	return d.TableNames[column.Table], column, true
+}
+
+// foreignKeys the keys of each table, in tables.json order
+// tables.json lists one column pair per entry. Pairs of a table referencing the same table
+// become one composite key when their referenced columns are that table's composite primary
+// key; other pairs (e.g. home_team_id and away_team_id → team.id) stay separate keys.
+func (d *SpiderDatabase) foreignKeys(tables map[string]*TableMetadata) map[string][]ForeignKeyMetadata {
+	type link struct{ table, refTable string }
+	type pair struct{ column, refColumn string }
+	var order []link
+	pairs := make(map[link][]pair)
+	for _, fk := range d.ForeignKeys {
+		tableName, column, ok := d.column(fk[0])
+		refTable, refColumn, refOK := d.column(fk[1])
+		if !ok || !refOK {
+			continue
+		}
+		key := link{tableName, refTable}
+		if _, seen := pairs[key]; !seen {
+			order = append(order, key)
+		}
+		pairs[key] = append(pairs[key], pair{column.Name, refColumn.Name})
+	}
+
+	result := make(map[string][]ForeignKeyMetadata)
+	for _, key := range order {
+		group := pairs[key]
+		var columns, refColumns []string
+		for _, p := range group {
+			columns = append(columns, p.column)
+			refColumns = append(refColumns, p.refColumn)
+		}
+		if len(group) > 1 && sameColumnSet(refColumns, tables[key.refTable].PrimaryKey) {
+			result[key.table] = append(result[key.table], newForeignKey(columns, key.refTable, refColumns))
+			continue
+		}
+		for _, p := range group {
+			result[key.table] = append(result[key.table], newForeignKey([]string{p.column}, key.refTable, []string{p.refColumn}))
+		}
+	}
+	return result
+}
+
+// sameColumnSet reports whether two column lists hold the same names, in any order
+func sameColumnSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := make(map[string]int, len(a))
+	for _, name := range a {
+		count[strings.ToLower(name)]++
+	}
+	for _, name := range b {
+		if count[strings.ToLower(name)] == 0 {
+			return false
+		}
+		count[strings.ToLower(name)]--
+	}
+	return true
+}
+
+// LoadSpiderSchema loads table structure from a tables.json entry
+// Like LoadSchemaFromFile: tables already in the context keep their row count, description
+// and Rich Context. Returns the number of tables loaded.
+func (c *SharedContext) LoadSpiderSchema(db *SpiderDatabase) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	primaryKeys := db.primaryKeyColumns()
+	tables := make(map[string]*TableMetadata, len(db.TableNames))
+	for _, name := range db.TableNames {
+		tables[name] = &TableMetadata{
+			Name:        name,
+			Columns:     []ColumnMetadata{},
+			Indexes:     []IndexMetadata{},
+			ForeignKeys: []ForeignKeyMetadata{},
+			RichContext: make(map[string]RichContextValue),
+		}
+	}
+
+	for i := range db.ColumnNames {
+		tableName, column, ok := db.column(i)
+		if !ok {
+			continue
+		}
+		table := tables[tableName]
+		col := ColumnMetadata{
+			Name:         column.Name,
+			Nullable:     !primaryKeys[i],
+			IsPrimaryKey: primaryKeys[i],
+		}
+		if i < len(db.ColumnTypes) {
+			col.Type = db.ColumnTypes[i]
+		}
+		table.Columns = append(table.Columns, col)
+		if col.IsPrimaryKey {
+			table.PrimaryKey = append(table.PrimaryKey, col.Name)
+		}
+	}
+
+	for tableName, fks := range db.foreignKeys(tables) {
+		tables[tableName].ForeignKeys = fks
+	}
+
+	for name, table := range tables {
+		if existing, exists := c.Tables[name]; exists {
+			table.RowCount = existing.RowCount
+			table.Description = existing.Description
+			table.RichContext = existing.RichContext
+		}
+		c.Tables[name] = table
+	}
+
+	if !c.Quiet {
+		fmt.Printf("[Context] Loaded schema from tables.json: %d tables\n", len(tables))
+	}
+	return len(tables)
+}
@@ -0,0 +1,333 @@
+package context
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// headerKey meta row holding everything of the context except its tables
+const headerKey = "context"
+
+// ContextStoreExt file extension of context stores written by gen_all_dev -context-store
+const ContextStoreExt = ".ctxdb"
+
+// sqliteMagic first bytes of every SQLite database file
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// IsContextStore reports whether path is a context store rather than a JSON context file
+// (sniffed from the file header, so any file name works)
+func IsContextStore(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, len(sqliteMagic))
+	n, _ := f.Read(header)
+	return bytes.Equal(header[:n], sqliteMagic)
+}
+
+// ContextPath the context of a database in a context directory: <db>.ctxdb when present,
+// else <db>.json
+func ContextPath(dir, dbName string) string {
+	store := filepath.Join(dir, dbName+ContextStoreExt)
+	if _, err := os.Stat(store); err == nil {
+		return store
+	}
+	return filepath.Join(dir, dbName+".json")
+}
+
+// ContextStore SQLite-backed SharedContext storage
+// The context header (field semantics, join paths, value index, ...) and each table are
+// stored as separate JSON rows, so large databases load only the tables they need and a
+// worker finishing one table rewrites one row instead of the whole file. Each row also
+// holds the table's TableIndexEntry, which OpenLazyContext reads without the table data.
+// SaveToFile and LoadContextFromFile remain the portable format; ImportFile / ExportFile convert.
+type ContextStore struct {
+	db   *sql.DB
+	path string
+}
+
+// OpenContextStore opens (or creates) a context store file
+func OpenContextStore(path string) (*ContextStore, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open context store: %w", err)
+	}
+	// A single connection serializes writers; SQLite allows one at a time anyway
+	db.SetMaxOpenConns(1)
+
+	schema := `
+CREATE TABLE IF NOT EXISTS meta (key TEXT PRIMARY KEY, value TEXT NOT NULL);
+CREATE TABLE IF NOT EXISTS tables (
+	name       TEXT PRIMARY KEY,
+	data       TEXT NOT NULL,
+	idx        TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create context store schema: %w", err)
+	}
+	return &ContextStore{db: db, path: path}, nil
+}
+
+// Close closes the store
+func (s *ContextStore) Close() error {
+	return s.db.Close()
+}
+
+// Save writes the whole context in one transaction, replacing what the store held
+func (s *ContextStore) Save(c *SharedContext) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.Tables) > 0 {
+		c.SchemaDiagram = c.GenerateMermaidER()
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM tables`); err != nil {
+		return fmt.Errorf("failed to clear tables: %w", err)
+	}
+	for name, table := range c.Tables {
+		if err := putTable(tx, name, table); err != nil {
+			return err
+		}
+	}
+	if err := c.putHeader(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveTable atomically rewrites one table and the context header (row totals, semantics)
+func (s *ContextStore) SaveTable(c *SharedContext, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	table, exists := c.Tables[name]
+	if !exists {
+		return fmt.Errorf("table not found: %s", name)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := putTable(tx, name, table); err != nil {
+		return err
+	}
+	if err := c.putHeader(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteTable removes one table from the store
+func (s *ContextStore) DeleteTable(name string) error {
+	if _, err := s.db.Exec(`DELETE FROM tables WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("failed to delete table %s: %w", name, err)
+	}
+	return nil
+}
+
+// Load reads the context header only; tables are fetched on demand with LoadTables
+func (s *ContextStore) Load() (*SharedContext, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key = ?`, headerKey).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("context store %s is empty", s.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context header: %w", err)
+	}
+
+	c := NewSharedContext("", "")
+	if err := json.Unmarshal([]byte(data), c); err != nil {
+		return nil, fmt.Errorf("failed to parse context header: %w", err)
+	}
+	c.Tables = make(map[string]*TableMetadata)
+	return c, nil
+}
+
+// LoadAll reads the header and every table
+func (s *ContextStore) LoadAll() (*SharedContext, error) {
+	c, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.TableNames()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.LoadTables(c, names...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// TableNames names of the stored tables (sorted)
+func (s *ContextStore) TableNames() ([]string, error) {
+	rows, err := s.db.Query(`SELECT name FROM tables ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// LoadTables fetches the named tables into the context, skipping ones already loaded
+func (s *ContextStore) LoadTables(c *SharedContext, names ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range names {
+		if _, loaded := c.Tables[name]; loaded {
+			continue
+		}
+		data, err := s.tableData(name)
+		if err != nil {
+			return err
+		}
+		var table TableMetadata
+		if err := json.Unmarshal(data, &table); err != nil {
+			return fmt.Errorf("failed to parse table %s: %w", name, err)
+		}
+		c.Tables[name] = &table
+	}
+	return nil
+}
+
+// tableData the stored JSON of one table
+func (s *ContextStore) tableData(name string) (json.RawMessage, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM tables WHERE name = ?`, name).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("table not found: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table %s: %w", name, err)
+	}
+	return json.RawMessage(data), nil
+}
+
+// tableIndex the index entries of every stored table, without reading their data
+func (s *ContextStore) tableIndex() (map[string]*TableIndexEntry, error) {
+	rows, err := s.db.Query(`SELECT name, idx FROM tables`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table index: %w", err)
+	}
+	defer rows.Close()
+
+	index := make(map[string]*TableIndexEntry)
+	for rows.Next() {
+		var name, data string
+		if err := rows.Scan(&name, &data); err != nil {
+			return nil, fmt.Errorf("failed to read table index: %w", err)
+		}
+		var entry TableIndexEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse index of table %s: %w", name, err)
+		}
+		index[name] = &entry
+	}
+	return index, rows.Err()
+}
+
+// loadContextStore reads the whole context of a store file
+func loadContextStore(path string) (*SharedContext, error) {
+	store, err := OpenContextStore(path)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	return store.LoadAll()
+}
+
+// ImportFile copies a JSON context file into the store
+func (s *ContextStore) ImportFile(path string) (*SharedContext, error) {
+	c, err := LoadContextFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	if err := s.Save(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ExportFile writes the full stored context as a portable JSON file
+func (s *ContextStore) ExportFile(path string) (*SharedContext, error) {
+	c, err := s.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SaveToFile(path); err != nil {
+		return nil, fmt.Errorf("failed to save %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// putHeader writes the context without its tables (caller holds the lock)
+// Tables are detached for the marshal; TotalTables and TotalRows still describe them.
+func (c *SharedContext) putHeader(tx *sql.Tx) error {
+	tables := c.Tables
+	c.Tables = nil
+	data, err := json.Marshal(c)
+	c.Tables = tables
+	if err != nil {
+		return fmt.Errorf("failed to encode context header: %w", err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
+		headerKey, string(data)); err != nil {
+		return fmt.Errorf("failed to write context header: %w", err)
+	}
+	return nil
+}
+
+// putTable writes one table row with its index entry
+func putTable(tx *sql.Tx, name string, table *TableMetadata) error {
+	data, err := json.Marshal(table)
+	if err != nil {
+		return fmt.Errorf("failed to encode table %s: %w", name, err)
+	}
+	// Built from the encoded table, so the entry matches what a JSON context file yields
+	var section tableIndexSection
+	if err := json.Unmarshal(data, &section); err != nil {
+		return fmt.Errorf("failed to index table %s: %w", name, err)
+	}
+	idx, err := json.Marshal(newTableIndexEntry(name, &section))
+	if err != nil {
+		return fmt.Errorf("failed to index table %s: %w", name, err)
+	}
+	if _, err := tx.Exec(`INSERT INTO tables (name, data, idx, updated_at) VALUES (?, ?, ?, ?) ON CONFLICT(name) DO UPDATE SET data = excluded.data, idx = excluded.idx, updated_at = excluded.updated_at`,
+		name, string(data), string(idx), time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("failed to write table %s: %w", name, err)
+	}
+	return nil
+}
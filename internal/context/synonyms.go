@@ -0,0 +1,162 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SynonymStorageType FieldSemantic storage type of a column synonym entry
+const SynonymStorageType = "synonyms"
+
+// SynonymMatch a question term naming a column through one of its synonyms
+type SynonymMatch struct {
+	Term   string
+	Table  string
+	Column string
+}
+
+// synonymKey FieldSemantics key of a column's synonyms (kept apart from the "table.column"
+// entries AnalyzeJoinPaths rewrites)
+func synonymKey(tableName, columnName string) string {
+	return tableName + "." + columnName + "#synonyms"
+}
+
+// AddColumnSynonyms merges natural-language synonyms of a column ("revenue" for
+// total_amount); terms are lower-cased and deduplicated, the column name itself is skipped.
+// Returns the number of new synonyms.
+func (c *SharedContext) AddColumnSynonyms(tableName, columnName string, synonyms []string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	table := c.findTable(tableName)
+	if table == nil {
+		return 0, fmt.Errorf("table not found: %s", tableName)
+	}
+	column := ""
+	for _, col := range table.Columns {
+		if strings.EqualFold(col.Name, columnName) {
+			column = col.Name
+		}
+	}
+	if column == "" {
+		return 0, fmt.Errorf("column not found: %s.%s", tableName, columnName)
+	}
+
+	if c.FieldSemantics == nil {
+		c.FieldSemantics = make(map[string]*FieldSemantic)
+	}
+	key := synonymKey(table.Name, column)
+	fs, exists := c.FieldSemantics[key]
+	if !exists {
+		fs = &FieldSemantic{TableName: table.Name, ColumnName: column, StorageType: SynonymStorageType}
+	}
+
+	known := make(map[string]bool, len(fs.Synonyms)+1)
+	known[strings.ToLower(column)] = true
+	for _, s := range fs.Synonyms {
+		known[s] = true
+	}
+	added := 0
+	for _, s := range synonyms {
+		s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+		if s == "" || known[s] {
+			continue
+		}
+		known[s] = true
+		fs.Synonyms = append(fs.Synonyms, s)
+		added++
+	}
+	if len(fs.Synonyms) == 0 {
+		return 0, nil
+	}
+
+	fs.Note = "Also called: " + strings.Join(fs.Synonyms, ", ")
+	c.FieldSemantics[key] = fs
+	return added, nil
+}
+
+// ColumnSynonyms synonyms per column of one table (column name → synonyms)
+func (c *SharedContext) ColumnSynonyms(tableName string) map[string][]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result map[string][]string
+	for _, fs := range c.FieldSemantics {
+		if fs.StorageType != SynonymStorageType || !strings.EqualFold(fs.TableName, tableName) {
+			continue
+		}
+		if result == nil {
+			result = make(map[string][]string)
+		}
+		result[fs.ColumnName] = fs.Synonyms
+	}
+	return result
+}
+
+// LoadSynonymsFile merges user-provided synonyms: a JSON object of "table.column" → terms
+// Entries naming unknown tables or columns are skipped. Returns the synonyms added.
+func (c *SharedContext) LoadSynonymsFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read synonyms file: %w", err)
+	}
+	var entries map[string][]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return 0, fmt.Errorf("failed to parse synonyms file: %w", err)
+	}
+
+	total := 0
+	for key, synonyms := range entries {
+		tableName, columnName, ok := strings.Cut(key, ".")
+		if !ok {
+			continue
+		}
+		added, err := c.AddColumnSynonyms(tableName, columnName, synonyms)
+		if err != nil {
+			if !c.Quiet {
+				fmt.Printf("[Context] ⚠️  Skipping synonyms of %s: %v\n", key, err)
+			}
+			continue
+		}
+		total += added
+	}
+	return total, nil
+}
+
+// MatchSynonyms synonyms found as whole words in the question
+// synonyms maps table → column → terms; matches are sorted by table, column and term.
+func MatchSynonyms(question string, synonyms map[string]map[string][]string) []SynonymMatch {
+	text := " " + synonymWords(question) + " "
+
+	var matches []SynonymMatch
+	for table, columns := range synonyms {
+		for column, terms := range columns {
+			for _, term := range terms {
+				if words := synonymWords(term); words != "" && strings.Contains(text, " "+words+" ") {
+					matches = append(matches, SynonymMatch{Term: term, Table: table, Column: column})
+				}
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Term < b.Term
+	})
+	return matches
+}
+
+// synonymWords lower-cased words of a text joined by single spaces (punctuation dropped)
+func synonymWords(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r == '_' || r == '%' || r == '$' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || r > 127)
+	}), " ")
+}
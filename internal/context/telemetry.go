@@ -0,0 +1,43 @@
+package context
+
+import "time"
+
+// Worker outcomes recorded in WorkerTelemetry
+const (
+	OutcomeCompleted      = "completed"
+	OutcomeFailed         = "failed"
+	OutcomeTimedOut       = "timed_out"       // Partial metadata, cut off by the table deadline
+	OutcomeBudgetExceeded = "budget_exceeded" // Deterministic stats only, the LLM cap was hit
+)
+
+// WorkerTelemetry cost and outcome of the worker that analyzed a table
+// Saved with the table so expensive or flaky tables can be found across a corpus of contexts.
+type WorkerTelemetry struct {
+	Agent       string  `json:"agent"`
+	Model       string  `json:"model,omitempty"`
+	Outcome     string  `json:"outcome"`
+	ReActSteps  int     `json:"react_steps"` // Tool calls of the ReAct phases
+	SQLQueries  int     `json:"sql_queries"` // execute_sql calls, failed ones included
+	LLMCalls    int     `json:"llm_calls"`
+	Tokens      int     `json:"tokens"`            // Total tokens reported by the provider (0 when unknown)
+	Retries     int     `json:"retries,omitempty"` // Phases rerun with the strict prompt
+	DurationSec float64 `json:"duration_sec"`
+	GeneratedAt string  `json:"generated_at"`
+}
+
+// SetTableTelemetry records how a table's worker ran
+// Tables without metadata (a worker that failed before Phase 1) are skipped, so a failure
+// never adds an empty table to the context.
+func (c *SharedContext) SetTableTelemetry(tableName string, telemetry *WorkerTelemetry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	table, exists := c.Tables[tableName]
+	if !exists {
+		return
+	}
+	if telemetry.GeneratedAt == "" {
+		telemetry.GeneratedAt = time.Now().Format(time.RFC3339)
+	}
+	table.Generation = telemetry
+}
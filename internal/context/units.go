@@ -0,0 +1,221 @@
+package context
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// UnitStorageType FieldSemantic storage type of a detected unit
+const UnitStorageType = "unit"
+
+// Word lists of column names (split on "_" and case) that suggest a unit
+var (
+	percentWords  = []string{"percent", "percentage", "pct", "perc"}
+	fractionWords = []string{"rate", "ratio", "share", "proportion", "fraction"}
+	currencyWords = []string{"price", "cost", "amount", "salary", "revenue", "fee", "fees", "income", "budget", "payment", "balance", "wage", "tax", "profit", "spend", "usd", "eur", "dollars"}
+	durationWords = []string{"duration", "elapsed", "latency", "runtime", "laptime", "time"}
+	epochWords    = []string{"epoch", "timestamp", "ts", "unix"}
+)
+
+// currencyMarkers symbols (anywhere in the comment) and words (in the name or comment) naming a currency
+var currencyMarkers = []struct{ marker, code string }{
+	{"$", "USD"}, {"usd", "USD"}, {"dollar", "USD"}, {"dollars", "USD"},
+	{"€", "EUR"}, {"eur", "EUR"}, {"euro", "EUR"}, {"euros", "EUR"},
+	{"£", "GBP"}, {"gbp", "GBP"}, {"pound", "GBP"},
+	{"yuan", "CNY"}, {"rmb", "CNY"}, {"cny", "CNY"}, {"yen", "JPY"}, {"jpy", "JPY"},
+}
+
+// columnUnit a detected unit: short label for the column line and the note with its recipe
+type columnUnit struct {
+	label string
+	note  string
+}
+
+// checkUnits detects units and scales of numeric columns and records them as FieldSemantics
+// Returns the number of columns with a unit.
+func (qc *QualityChecker) checkUnits(table *TableMetadata) int {
+	found := 0
+	for _, col := range table.Columns {
+		unit := detectUnit(col)
+		if unit == nil {
+			continue
+		}
+		qc.sharedCtx.SetFieldSemantic(unitKey(qc.tableName, col.Name), unit.semantic(qc.tableName, col.Name))
+		found++
+	}
+	return found
+}
+
+// DetectUnits re-runs unit detection over all tables, e.g. after column comments were
+// merged from dataset descriptions. Returns the number of columns with a unit.
+func (c *SharedContext) DetectUnits() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.FieldSemantics == nil {
+		c.FieldSemantics = make(map[string]*FieldSemantic)
+	}
+	found := 0
+	for tableName, table := range c.Tables {
+		for _, col := range table.Columns {
+			if unit := detectUnit(col); unit != nil {
+				c.FieldSemantics[unitKey(tableName, col.Name)] = unit.semantic(tableName, col.Name)
+				found++
+			}
+		}
+	}
+	return found
+}
+
+// semantic the unit as a FieldSemantic of the column
+func (u *columnUnit) semantic(tableName, columnName string) *FieldSemantic {
+	return &FieldSemantic{
+		TableName:   tableName,
+		ColumnName:  columnName,
+		StorageType: UnitStorageType,
+		Unit:        u.label,
+		Note:        u.note,
+	}
+}
+
+// unitKey FieldSemantics key of a column's unit
+func unitKey(tableName, columnName string) string {
+	return tableName + "." + columnName + "#unit"
+}
+
+// columnUnits unit labels of a table's columns, keyed by lower-cased column name
+func (c *SharedContext) columnUnits(tableName string) map[string]string {
+	var units map[string]string
+	for _, fs := range c.FieldSemantics {
+		if fs.StorageType != UnitStorageType || fs.Unit == "" || !strings.EqualFold(fs.TableName, tableName) {
+			continue
+		}
+		if units == nil {
+			units = make(map[string]string)
+		}
+		units[strings.ToLower(fs.ColumnName)] = fs.Unit
+	}
+	return units
+}
+
+// detectUnit unit of a numeric column from its name, comment and observed range (nil if unknown)
+func detectUnit(col ColumnMetadata) *columnUnit {
+	colType := strings.ToUpper(col.Type)
+	var r *NumericRange
+	if col.ValueStats != nil {
+		r = col.ValueStats.Range
+	}
+	numeric := r != nil || strings.Contains(colType, "INT") || strings.Contains(colType, "REAL") ||
+		strings.Contains(colType, "FLOA") || strings.Contains(colType, "DOUB") ||
+		strings.Contains(colType, "NUM") || strings.Contains(colType, "DEC")
+	if !numeric {
+		return nil
+	}
+
+	words := strings.Fields(synonymWords(strings.ReplaceAll(splitCamel(col.Name), "_", " ")))
+	has := func(list []string) bool {
+		for _, w := range words {
+			for _, l := range list {
+				if w == l {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	comment := strings.ToLower(col.Comment)
+	commentWords := " " + synonymWords(comment) + " "
+	observed := ""
+	if r != nil {
+		observed = fmt.Sprintf(" (observed %s..%s)", rangeBound(r.Min), rangeBound(r.Max))
+	}
+
+	// Percent vs fraction: the observed range settles which scale the column uses
+	percentName := has(percentWords) || strings.Contains(comment, "%") || strings.Contains(comment, "percent")
+	if percentName || has(fractionWords) {
+		switch {
+		case r != nil && r.Min >= 0 && r.Max <= 1:
+			return &columnUnit{
+				label: "fraction 0-1",
+				note:  fmt.Sprintf("Fraction on a 0-1 scale%s: 25%% is 0.25; multiply by 100 to report a percentage", observed),
+			}
+		case percentName && (r == nil || (r.Min >= 0 && r.Max <= 100)):
+			return &columnUnit{
+				label: "percent 0-100",
+				note:  fmt.Sprintf("Percentage on a 0-100 scale%s: 25%% is 25, not 0.25; divide by 100 for a ratio", observed),
+			}
+		}
+	}
+
+	// Unix time stored as a number
+	if (has(epochWords) || isTemporalName(col.Name)) && r != nil && r.Min >= 1e9 {
+		if r.Min >= 1e12 {
+			return &columnUnit{
+				label: "epoch milliseconds",
+				note:  "Unix time in milliseconds: datetime(col / 1000, 'unixepoch') gives the date; compare against epoch values, not date strings",
+			}
+		}
+		return &columnUnit{
+			label: "epoch seconds",
+			note:  "Unix time in seconds: datetime(col, 'unixepoch') gives the date; compare against epoch values, not date strings",
+		}
+	}
+
+	// Durations: explicit unit words first, then magnitude of duration-like columns
+	switch {
+	case has([]string{"ms", "millis", "millisecond", "milliseconds", "msec"}):
+		return &columnUnit{label: "milliseconds", note: "Duration in milliseconds: divide by 1000 for seconds, by 60000 for minutes"}
+	case has([]string{"sec", "secs", "seconds"}):
+		return &columnUnit{label: "seconds", note: "Duration in seconds: divide by 60 for minutes, by 3600 for hours"}
+	case has([]string{"min", "mins", "minute", "minutes"}) && has(durationWords):
+		return &columnUnit{label: "minutes", note: "Duration in minutes: divide by 60 for hours, multiply by 60 for seconds"}
+	case has(durationWords) && r != nil && r.Avg >= 10000 && !has(epochWords):
+		return &columnUnit{
+			label: "milliseconds?",
+			note:  fmt.Sprintf("Duration probably in milliseconds%s: values are too large for seconds; divide by 1000 for seconds", observed),
+		}
+	}
+
+	// Money: cents need scaling, other amounts only a currency
+	if has([]string{"cents", "cent"}) {
+		return &columnUnit{label: "currency cents", note: "Money stored in cents: divide by 100 for the currency amount"}
+	}
+	if has(currencyWords) || strings.Contains(comment, "price") || strings.Contains(comment, "currency") {
+		currency := ""
+		for _, m := range currencyMarkers {
+			symbol := m.marker[0] >= 0x80 || m.marker == "$"
+			if (symbol && strings.Contains(comment, m.marker)) || strings.Contains(commentWords, " "+m.marker+" ") || has([]string{m.marker}) {
+				currency = m.code
+				break
+			}
+		}
+		if currency == "" {
+			return &columnUnit{label: "currency", note: fmt.Sprintf("Money amount%s; sums and averages are in the same currency unit", observed)}
+		}
+		return &columnUnit{label: "currency " + currency, note: fmt.Sprintf("Money amount in %s%s", currency, observed)}
+	}
+	return nil
+}
+
+// splitCamel inserts "_" at lower-to-upper case changes ("LapTimeMs" → "Lap_Time_Ms")
+func splitCamel(name string) string {
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && r >= 'A' && r <= 'Z' && runes[i-1] >= 'a' && runes[i-1] <= 'z' {
+			sb.WriteRune('_')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// rangeBound a range bound for display: whole numbers as integers, others to two decimals
+func rangeBound(v float64) string {
+	if v == math.Trunc(v) {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,400 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// Validation issue kinds
+const (
+	IssueMissingTable     = "missing_table"      // In the context, not in the database
+	IssueMissingColumn    = "missing_column"     // In the context, not in the database
+	IssueUntrackedTable   = "untracked_table"    // In the database, not in the context
+	IssueUntrackedColumn  = "untracked_column"   // In the database, not in the context
+	IssueChangedType      = "changed_type"       // Column type differs from the database
+	IssueStaleRowCount    = "stale_row_count"    // Row count off by more than the tolerance
+	IssueStaleRichContext = "stale_rich_context" // Rich Context key about a column that does not exist
+	IssueDanglingFK       = "dangling_foreign_key"
+)
+
+// richContextColumnSuffixes suffixes of Rich Context keys written about one column ("status_values")
+var richContextColumnSuffixes = []string{"_values", "_meaning", "_format", "_encoding", "_description", "_note", "_range", "_unit", qualityIssueSuffix}
+
+// ValidationIssue one mismatch between a context file and its live database
+type ValidationIssue struct {
+	Kind   string `json:"kind"`
+	Table  string `json:"table"`
+	Column string `json:"column,omitempty"`
+	Key    string `json:"key,omitempty"` // Rich Context key (stale_rich_context)
+	Detail string `json:"detail"`
+}
+
+// liveTable a table as introspected from the database
+type liveTable struct {
+	name        string
+	rowCount    int64
+	columns     []adapter.ColumnInfo
+	foreignKeys []adapter.ForeignKeyInfo
+	indexes     []adapter.IndexInfo
+	columnSet   map[string]bool   // Normalized column names
+	view        *adapter.ViewInfo // Set for views
+}
+
+// Validate checks the context against the live database
+// Row counts differing by more than rowTolerance (a fraction, e.g. 0.1) are stale. With fix,
+// the context is repaired in place: vanished tables, columns, foreign keys and Rich Context
+// keys are pruned, new tables and columns are added from introspection (without statistics
+// or notes), and column types and row counts are refreshed. Issues are sorted by table, kind and column.
+func (c *SharedContext) Validate(ctx context.Context, db adapter.DBAdapter, rowTolerance float64, fix bool) ([]ValidationIssue, error) {
+	tables, err := introspectAll(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]*liveTable, len(tables))
+	for _, table := range tables {
+		live[normalizeTableName(table.name)] = table
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var issues []ValidationIssue
+	add := func(issue ValidationIssue) {
+		issues = append(issues, issue)
+	}
+
+	// Tables and columns of the context
+	for key, table := range c.Tables {
+		current, ok := live[normalizeTableName(table.Name)]
+		if !ok {
+			add(ValidationIssue{Kind: IssueMissingTable, Table: table.Name, Detail: "table no longer exists"})
+			if fix {
+				delete(c.Tables, key)
+				c.pruneReferences(table.Name, "")
+			}
+			continue
+		}
+
+		liveTypes := make(map[string]string, len(current.columns))
+		for _, col := range current.columns {
+			liveTypes[normalizeColumnName(col.Name)] = col.Type
+		}
+
+		var kept []ColumnMetadata
+		known := make(map[string]bool, len(table.Columns))
+		for _, col := range table.Columns {
+			known[normalizeColumnName(col.Name)] = true
+			if current.columnSet[normalizeColumnName(col.Name)] {
+				if liveType := liveTypes[normalizeColumnName(col.Name)]; !strings.EqualFold(strings.TrimSpace(col.Type), strings.TrimSpace(liveType)) {
+					add(ValidationIssue{Kind: IssueChangedType, Table: table.Name, Column: col.Name, Detail: fmt.Sprintf("type %s in context, %s in database", col.Type, liveType)})
+					if fix {
+						col.Type = liveType
+					}
+				}
+				kept = append(kept, col)
+				continue
+			}
+			add(ValidationIssue{Kind: IssueMissingColumn, Table: table.Name, Column: col.Name, Detail: "column no longer exists"})
+			if fix {
+				c.pruneReferences(table.Name, col.Name)
+			}
+		}
+		for _, col := range current.columns {
+			if known[normalizeColumnName(col.Name)] {
+				continue
+			}
+			add(ValidationIssue{Kind: IssueUntrackedColumn, Table: table.Name, Column: col.Name, Detail: fmt.Sprintf("column %s (%s) is not in the context", col.Name, col.Type)})
+			if fix {
+				kept = append(kept, columnsFromInfo([]adapter.ColumnInfo{col})...)
+			}
+		}
+		if fix {
+			table.Columns = kept
+		}
+
+		if staleRowCount(table.RowCount, current.rowCount, rowTolerance) {
+			add(ValidationIssue{Kind: IssueStaleRowCount, Table: table.Name, Detail: fmt.Sprintf("row count %d in context, %d in database", table.RowCount, current.rowCount)})
+			if fix {
+				table.RowCount = current.rowCount
+			}
+		}
+
+		for _, key := range staleRichContextKeys(table, current.columnSet) {
+			add(ValidationIssue{Kind: IssueStaleRichContext, Table: table.Name, Key: key, Detail: fmt.Sprintf("Rich Context key %q refers to an unknown column", key)})
+			if fix {
+				delete(table.RichContext, key)
+			}
+		}
+	}
+
+	// Tables of the database missing from the context
+	for _, current := range live {
+		if c.findTable(current.name) != nil {
+			continue
+		}
+		add(ValidationIssue{Kind: IssueUntrackedTable, Table: current.name, Detail: fmt.Sprintf("table with %d columns, %d rows is not in the context", len(current.columns), current.rowCount)})
+		if fix {
+			c.Tables[current.name] = current.metadata()
+		}
+	}
+
+	// Foreign keys pointing at tables or columns that no longer exist (checked after pruning)
+	for _, table := range c.Tables {
+		if _, ok := live[normalizeTableName(table.Name)]; !ok {
+			continue // Reported as missing
+		}
+		var kept []ForeignKeyMetadata
+		for _, fk := range table.ForeignKeys {
+			target, ok := live[normalizeTableName(fk.ReferencedTable)]
+			for _, col := range fk.ReferencedKeyColumns() {
+				if ok && col != "" && !target.columnSet[normalizeColumnName(col)] {
+					ok = false
+				}
+			}
+			if ok {
+				kept = append(kept, fk)
+				continue
+			}
+			add(ValidationIssue{Kind: IssueDanglingFK, Table: table.Name, Column: fk.ColumnName, Detail: fmt.Sprintf("references %s, which no longer exists", fk.TargetRef())})
+		}
+		if fix {
+			table.ForeignKeys = kept
+		}
+	}
+
+	if fix {
+		c.TotalTables = len(c.Tables)
+		c.TotalRows = 0
+		for _, table := range c.Tables {
+			c.TotalRows += table.RowCount
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Column+a.Key < b.Column+b.Key
+	})
+	return issues, nil
+}
+
+// introspectAll reads every table and view of the database
+func introspectAll(ctx context.Context, db adapter.DBAdapter) ([]*liveTable, error) {
+	names, err := db.GetTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	views, err := db.GetViews(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+
+	tables := make([]*liveTable, 0, len(names)+len(views))
+	for _, name := range names {
+		table, err := introspectTable(ctx, db, name)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	for i := range views {
+		table, err := introspectTable(ctx, db, views[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		table.view = &views[i]
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// introspectTable reads one table's structure and row count
+func introspectTable(ctx context.Context, db adapter.DBAdapter, name string) (*liveTable, error) {
+	columns, err := db.GetColumns(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", name, err)
+	}
+	table := &liveTable{name: name, columns: columns, columnSet: make(map[string]bool, len(columns))}
+	for _, col := range columns {
+		table.columnSet[normalizeColumnName(col.Name)] = true
+	}
+	// Keys and indexes only matter for tables added by a fix; failures leave them empty
+	table.foreignKeys, _ = db.GetForeignKeys(ctx, name)
+	table.indexes, _ = db.GetIndexes(ctx, name)
+
+	result, err := db.ExecuteQuery(ctx, fmt.Sprintf(`SELECT COUNT(*) as cnt FROM %s`, quoteIdent(name)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rows of %s: %w", name, err)
+	}
+	table.rowCount = int64(extractCount(result))
+	return table, nil
+}
+
+// metadata the table as context metadata (structure and row count, no statistics or notes)
+func (t *liveTable) metadata() *TableMetadata {
+	table := &TableMetadata{
+		Name:        t.name,
+		RowCount:    t.rowCount,
+		Columns:     columnsFromInfo(t.columns),
+		Indexes:     indexesFromInfo(t.indexes),
+		ForeignKeys: foreignKeysFromInfo(t.foreignKeys),
+		RichContext: make(map[string]RichContextValue),
+	}
+	if t.view != nil {
+		table.IsView = true
+		table.ViewDefinition = t.view.Definition
+	}
+	return table
+}
+
+// LoadSchemaFromDB adds every table and view of the database to the context by introspection
+// Only structure and row counts are loaded; existing tables are replaced.
+func (c *SharedContext) LoadSchemaFromDB(ctx context.Context, db adapter.DBAdapter) error {
+	live, err := introspectAll(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	tables := make([]*TableMetadata, 0, len(live))
+	for _, table := range live {
+		tables = append(tables, table.metadata())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, table := range tables {
+		c.Tables[table.Name] = table
+	}
+	c.TotalTables = len(c.Tables)
+	c.TotalRows = 0
+	for _, table := range c.Tables {
+		c.TotalRows += table.RowCount
+	}
+	return nil
+}
+
+// findTable context table by name, case-insensitive (caller holds the lock)
+func (c *SharedContext) findTable(name string) *TableMetadata {
+	for _, table := range c.Tables {
+		if normalizeTableName(table.Name) == normalizeTableName(name) {
+			return table
+		}
+	}
+	return nil
+}
+
+// pruneReferences removes everything recorded about a table (column "") or one of its
+// columns outside the column list: keys, indexes, quality issues, semantics, value index
+// entries and embeddings (caller holds the lock)
+func (c *SharedContext) pruneReferences(tableName, column string) {
+	matches := func(table, col string) bool {
+		if normalizeTableName(table) != normalizeTableName(tableName) {
+			return false
+		}
+		return column == "" || normalizeColumnName(col) == normalizeColumnName(column)
+	}
+
+	if table := c.findTable(tableName); table != nil && column != "" {
+		var fks []ForeignKeyMetadata
+		for _, fk := range table.ForeignKeys {
+			if !fk.HasColumn(column) {
+				fks = append(fks, fk)
+			}
+		}
+		table.ForeignKeys = fks
+
+		var indexes []IndexMetadata
+		for _, idx := range table.Indexes {
+			uses := false
+			for _, col := range idx.Columns {
+				uses = uses || matches(tableName, col)
+			}
+			if !uses {
+				indexes = append(indexes, idx)
+			}
+		}
+		table.Indexes = indexes
+
+		var qualityIssues []QualityIssue
+		for _, issue := range table.QualityIssues {
+			if !matches(tableName, issue.Column) {
+				qualityIssues = append(qualityIssues, issue)
+			}
+		}
+		table.QualityIssues = qualityIssues
+	}
+
+	for key, fs := range c.FieldSemantics {
+		if matches(fs.TableName, fs.ColumnName) {
+			delete(c.FieldSemantics, key)
+		}
+	}
+	if c.ValueIndex != nil {
+		for key := range c.ValueIndex.Columns {
+			table, col, _ := strings.Cut(key, ".")
+			if matches(table, col) {
+				delete(c.ValueIndex.Columns, key)
+			}
+		}
+	}
+	if c.Embeddings != nil {
+		if column == "" {
+			delete(c.Embeddings.Tables, strings.ToLower(tableName))
+		}
+		for key := range c.Embeddings.Columns {
+			table, col, _ := strings.Cut(key, ".")
+			if matches(table, col) {
+				delete(c.Embeddings.Columns, key)
+			}
+		}
+	}
+}
+
+// staleRowCount reports whether two row counts differ by more than the tolerance fraction
+func staleRowCount(recorded, actual int64, tolerance float64) bool {
+	if recorded == actual {
+		return false
+	}
+	diff := float64(actual - recorded)
+	if diff < 0 {
+		diff = -diff
+	}
+	base := float64(actual)
+	if base == 0 {
+		return true
+	}
+	return diff/base > tolerance
+}
+
+// staleRichContextKeys Rich Context keys about a column ("status_values") whose column is gone
+func staleRichContextKeys(table *TableMetadata, columns map[string]bool) []string {
+	var stale []string
+	for key := range table.RichContext {
+		lower := strings.ToLower(key)
+		if columns[lower] {
+			continue // A column named like a note ("order_note")
+		}
+		for _, suffix := range richContextColumnSuffixes {
+			column := strings.TrimSuffix(lower, suffix)
+			if column == lower || column == "" {
+				continue
+			}
+			if !columns[column] {
+				stale = append(stale, key)
+			}
+			break
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
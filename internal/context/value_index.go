@@ -0,0 +1,428 @@
+package context
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// maxIndexedValues distinct literals kept per column (most frequent first)
+	maxIndexedValues = 1000
+	// maxIndexedValueLen longer values are free text, not entity names
+	maxIndexedValueLen = 80
+)
+
+// ValueIndex per-database cell-value index for entity linking
+// Maps "table.column" to distinct text literals; the search structures are built lazily.
+type ValueIndex struct {
+	Columns map[string][]string `json:"columns"`
+
+	once     sync.Once
+	docs     []valueDoc
+	postings map[string][]int // token -> doc ids
+	exact    map[string][]int // lower-cased value -> doc ids
+	avgLen   float64
+}
+
+// ValueMatch value found for a question phrase
+type ValueMatch struct {
+	Table  string  `json:"table"`
+	Column string  `json:"column"`
+	Value  string  `json:"value"`
+	Phrase string  `json:"phrase"` // question text that matched
+	Exact  bool    `json:"exact"`  // case-insensitive equality (otherwise BM25/LIKE match)
+	Score  float64 `json:"score"`
+}
+
+type valueDoc struct {
+	table  string
+	column string
+	value  string
+	lower  string
+	tokens []string
+}
+
+// NewValueIndex creates empty value index
+func NewValueIndex() *ValueIndex {
+	return &ValueIndex{Columns: make(map[string][]string)}
+}
+
+// Size returns the number of indexed values
+func (vi *ValueIndex) Size() int {
+	n := 0
+	for _, values := range vi.Columns {
+		n += len(values)
+	}
+	return n
+}
+
+// SetColumnValues sets indexed values for a column
+func (c *SharedContext) SetColumnValues(tableName, columnName string, values []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ValueIndex == nil {
+		c.ValueIndex = NewValueIndex()
+	}
+	c.ValueIndex.Columns[tableName+"."+columnName] = values
+}
+
+// build creates inverted and exact-match indexes
+func (vi *ValueIndex) build() {
+	vi.postings = make(map[string][]int)
+	vi.exact = make(map[string][]int)
+
+	keys := make([]string, 0, len(vi.Columns))
+	for key := range vi.Columns {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	totalLen := 0
+	for _, key := range keys {
+		table, column := key, ""
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			table, column = key[:i], key[i+1:]
+		}
+		for _, value := range vi.Columns[key] {
+			doc := valueDoc{
+				table:  table,
+				column: column,
+				value:  value,
+				lower:  strings.ToLower(strings.TrimSpace(value)),
+				tokens: valueTokens(value),
+			}
+			id := len(vi.docs)
+			vi.docs = append(vi.docs, doc)
+			vi.exact[doc.lower] = append(vi.exact[doc.lower], id)
+
+			seen := make(map[string]bool)
+			for _, tok := range doc.tokens {
+				if !seen[tok] {
+					seen[tok] = true
+					vi.postings[tok] = append(vi.postings[tok], id)
+				}
+			}
+			totalLen += len(doc.tokens)
+		}
+	}
+	if len(vi.docs) > 0 {
+		vi.avgLen = float64(totalLen) / float64(len(vi.docs))
+	}
+}
+
+// Lookup returns values equal to the phrase (case-insensitive)
+func (vi *ValueIndex) Lookup(phrase string) []ValueMatch {
+	vi.once.Do(vi.build)
+
+	var matches []ValueMatch
+	for _, id := range vi.exact[strings.ToLower(strings.TrimSpace(phrase))] {
+		matches = append(matches, vi.match(id, phrase, true, 0))
+	}
+	return matches
+}
+
+// SearchLike returns values containing the substring (case-insensitive, like LIKE '%s%')
+func (vi *ValueIndex) SearchLike(substr string, limit int) []ValueMatch {
+	vi.once.Do(vi.build)
+
+	needle := strings.ToLower(strings.TrimSpace(substr))
+	if needle == "" {
+		return nil
+	}
+	var matches []ValueMatch
+	for id, doc := range vi.docs {
+		if strings.Contains(doc.lower, needle) {
+			matches = append(matches, vi.match(id, substr, doc.lower == needle, 0))
+			if limit > 0 && len(matches) >= limit {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// Search ranks values against the query with BM25 over value tokens
+func (vi *ValueIndex) Search(query string, limit int) []ValueMatch {
+	vi.once.Do(vi.build)
+
+	lowerQuery := strings.ToLower(strings.TrimSpace(query))
+	var matches []ValueMatch
+	for _, hit := range vi.rank(query, limit) {
+		matches = append(matches, vi.match(hit.id, query, vi.docs[hit.id].lower == lowerQuery, hit.score))
+	}
+	return matches
+}
+
+type scoredValue struct {
+	id    int
+	score float64
+}
+
+// rank scores docs sharing a token with the query (BM25, k1=1.2, b=0.75)
+func (vi *ValueIndex) rank(query string, limit int) []scoredValue {
+	const k1, b = 1.2, 0.75
+	n := float64(len(vi.docs))
+	scores := make(map[int]float64)
+	for _, tok := range uniqueTokens(valueTokens(query)) {
+		ids := vi.postings[tok]
+		if len(ids) == 0 {
+			continue
+		}
+		df := float64(len(ids))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, id := range ids {
+			tf := 0.0
+			for _, t := range vi.docs[id].tokens {
+				if t == tok {
+					tf++
+				}
+			}
+			docLen := float64(len(vi.docs[id].tokens))
+			scores[id] += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*docLen/vi.avgLen))
+		}
+	}
+
+	hits := make([]scoredValue, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, scoredValue{id, score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].id < hits[j].id
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// MatchQuestion finds question phrases that name stored values
+// Word n-grams are matched exactly; quoted and capitalized phrases without an exact hit fall back to BM25.
+func (vi *ValueIndex) MatchQuestion(question string, limit int) []ValueMatch {
+	vi.once.Do(vi.build)
+	if len(vi.docs) == 0 {
+		return nil
+	}
+
+	var matches []ValueMatch
+	seen := make(map[int]bool)
+	covered := make(map[string]bool) // lower-cased phrases already explained
+	add := func(m ValueMatch, id int) {
+		if !seen[id] {
+			seen[id] = true
+			matches = append(matches, m)
+		}
+	}
+
+	// 1. Exact matches of word n-grams (longest first)
+	words := questionWords(question)
+	for size := 4; size >= 1; size-- {
+		for i := 0; i+size <= len(words); i++ {
+			phrase := strings.Join(words[i:i+size], " ")
+			lower := strings.ToLower(phrase)
+			if !isCandidatePhrase(words[i : i+size]) {
+				continue
+			}
+			for _, id := range vi.exact[lower] {
+				add(vi.match(id, phrase, true, 0), id)
+				covered[lower] = true
+			}
+		}
+	}
+
+	// 2. Fuzzy matches for explicit entity mentions
+	for _, phrase := range entityPhrases(question) {
+		lower := strings.ToLower(phrase)
+		if covered[lower] {
+			continue
+		}
+		queryTokens := uniqueTokens(valueTokens(phrase))
+		for _, hit := range vi.rank(phrase, 3) {
+			// Require every phrase token in the value, otherwise BM25 surfaces partial noise
+			if !containsAllTokens(vi.docs[hit.id].tokens, queryTokens) {
+				continue
+			}
+			add(vi.match(hit.id, phrase, vi.docs[hit.id].lower == lower, hit.score), hit.id)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Exact != matches[j].Exact {
+			return matches[i].Exact
+		}
+		return len(matches[i].Phrase) > len(matches[j].Phrase)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func (vi *ValueIndex) match(id int, phrase string, exact bool, score float64) ValueMatch {
+	doc := vi.docs[id]
+	return ValueMatch{
+		Table:  doc.table,
+		Column: doc.column,
+		Value:  doc.value,
+		Phrase: strings.TrimSpace(phrase),
+		Exact:  exact,
+		Score:  score,
+	}
+}
+
+// ─── Tokenization ────────────────────────────────────────────────
+
+// valueStopwords never identify a value on their own
+var valueStopwords = map[string]bool{
+	"the": true, "a": true, "an": true, "of": true, "is": true, "are": true, "was": true, "were": true,
+	"to": true, "in": true, "for": true, "and": true, "or": true, "that": true, "with": true, "on": true,
+	"by": true, "be": true, "as": true, "it": true, "its": true, "this": true, "what": true, "which": true,
+	"who": true, "how": true, "many": true, "much": true, "list": true, "show": true, "give": true,
+	"find": true, "name": true, "names": true, "all": true, "from": true, "at": true, "has": true,
+	"have": true, "did": true, "does": true, "do": true, "there": true, "their": true, "than": true,
+	"more": true, "less": true, "most": true, "least": true, "number": true, "each": true, "me": true,
+}
+
+func valueTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueTokens(tokens []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, t := range tokens {
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func containsAllTokens(haystack, needles []string) bool {
+	set := make(map[string]bool, len(haystack))
+	for _, t := range haystack {
+		set[t] = true
+	}
+	for _, t := range needles {
+		if !set[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// questionWords splits a question into words, keeping inner punctuation of values like "O'Brien" or "F-16"
+func questionWords(question string) []string {
+	fields := strings.Fields(question)
+	words := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.TrimFunc(f, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if f != "" {
+			words = append(words, f)
+		}
+	}
+	return words
+}
+
+// isCandidatePhrase rejects n-grams that are stopwords only or too short to be meaningful
+func isCandidatePhrase(words []string) bool {
+	content := 0
+	for _, w := range words {
+		lw := strings.ToLower(w)
+		if !valueStopwords[lw] {
+			content++
+		}
+	}
+	if content == 0 {
+		return false
+	}
+	if len(words) == 1 {
+		w := words[0]
+		// Bare numbers and 1-2 letter words match far too many columns
+		if len([]rune(w)) < 3 || isAllDigits(w) {
+			return false
+		}
+		// Edge words must carry content
+	} else if valueStopwords[strings.ToLower(words[0])] || valueStopwords[strings.ToLower(words[len(words)-1])] {
+		return false
+	}
+	return true
+}
+
+// quotedPhrasePattern single quotes must stand apart from words so apostrophes (O'Brien) don't open a quote
+var quotedPhrasePattern = regexp.MustCompile(`"([^"]+)"|“([^”]+)”|(?:^|\s)'([^']+)'(?:$|[\s,.?!;:])`)
+
+// entityPhrases extracts quoted strings and runs of capitalized words
+func entityPhrases(question string) []string {
+	var phrases []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		p = strings.TrimSpace(p)
+		if p != "" && !seen[strings.ToLower(p)] {
+			seen[strings.ToLower(p)] = true
+			phrases = append(phrases, p)
+		}
+	}
+
+	// Quoted phrases
+	for _, m := range quotedPhrasePattern.FindAllStringSubmatch(question, -1) {
+		for _, g := range m[1:] {
+			add(g)
+		}
+	}
+
+	// Capitalized runs, skipping the sentence-initial word
+	words := strings.Fields(question)
+	var run []string
+	flush := func() {
+		if len(run) > 0 {
+			add(strings.Join(run, " "))
+			run = nil
+		}
+	}
+	for i, w := range words {
+		clean := strings.TrimFunc(w, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		first, _ := firstRune(clean)
+		if i > 0 && clean != "" && unicode.IsUpper(first) {
+			run = append(run, clean)
+		} else {
+			flush()
+		}
+		if strings.ContainsAny(w, ",;:?!.") {
+			flush()
+		}
+	}
+	flush()
+
+	return phrases
+}
+
+func firstRune(s string) (rune, bool) {
+	for _, r := range s {
+		return r, true
+	}
+	return 0, false
+}
+
+func isAllDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,65 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// withBudget bounds ctx by Config.MaxDuration (no bound when unset)
+func (p *Pipeline) withBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.config.MaxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.config.MaxDuration)
+}
+
+// budgetExhausted reports whether the pipeline's budget, not the caller, ended ctx
+// The first detection is logged and recorded in result.
+func (p *Pipeline) budgetExhausted(callerCtx, ctx context.Context, result *Result) bool {
+	if ctx.Err() == nil || callerCtx.Err() != nil {
+		return false
+	}
+	if !result.BudgetExhausted {
+		result.BudgetExhausted = true
+		p.Logger.Printf("⏱️  Time budget exhausted (%s), returning the best SQL so far\n", p.config.MaxDuration)
+	}
+	return true
+}
+
+// partialReActSQL best SQL of an interrupted ReAct loop: the last query that executed,
+// else the last SQL the agent passed to a tool
+func partialReActSQL(sqlTool *SQLTool, steps []CollectedStep) string {
+	if sqlTool.lastValid != "" {
+		return sqlTool.lastValid
+	}
+	for i := len(steps) - 1; i >= 0; i-- {
+		input := actionInputText(steps[i].ActionInput)
+		switch steps[i].Action {
+		case "execute_sql", "verify_sql", "verify_result_fields":
+			if sql := CleanSQL(input, ""); sql != "" {
+				return sql
+			}
+		case "compare_sql":
+			if _, sqlB, ok := parseCompareInput(input); ok {
+				return sqlB
+			}
+		}
+	}
+	return ""
+}
+
+// actionInputText tool input as the agent wrote it
+func actionInputText(input interface{}) string {
+	switch v := input.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,234 @@
+package inference
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"reactsql/internal/adapter"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// candidateTemperature sampling temperature for candidates after the first (one-shot mode)
+const candidateTemperature = 0.8
+
+// Candidate one generated SQL in multi-candidate mode
+type Candidate struct {
+	SQL         string `json:"sql"`
+	Path        string `json:"path,omitempty"` // Generation path (Path* constants)
+	Success     bool   `json:"success"`
+	Error       string `json:"error,omitempty"`
+	RowCount    int    `json:"row_count"`
+	Cluster     int    `json:"cluster"` // Result-set cluster id (-1 = execution failed)
+	ClusterSize int    `json:"cluster_size"`
+	Fingerprint string `json:"fingerprint,omitempty"` // Hash of the sorted result rows
+	Complexity  int    `json:"complexity,omitempty"`  // Tie-break score, lower = simpler
+	Selected    bool   `json:"selected"`
+}
+
+// generateSQL runs one SQL generation (ReAct or one-shot)
+func (p *Pipeline) generateSQL(ctx context.Context, query, contextPrompt, crossTableSummary string, result *Result, opts ...llms.CallOption) (string, error) {
+	if p.config.UseReact {
+		return p.generateReAct(ctx, query, contextPrompt, crossTableSummary, result, opts...)
+	}
+	sql, err := p.oneShotGeneration(ctx, query, contextPrompt, crossTableSummary, opts...)
+	result.LLMCalls++
+	result.GenerationPath = PathOneShot
+	return sql, err
+}
+
+// generateCandidates generates Config.Candidates SQLs and picks one by execution consensus
+// Candidates are executed and clustered by result-set fingerprint; the pick is the simplest SQL
+// of the largest cluster (ties: non-empty results, then simpler SQL). If nothing executes, the
+// first candidate is used.
+func (p *Pipeline) generateCandidates(ctx context.Context, query, contextPrompt, crossTableSummary string, result *Result) (string, error) {
+	n := p.config.Candidates
+	var candidates []Candidate
+	var lastErr error
+
+	for i := 0; i < n; i++ {
+		p.Logger.Printf("🎲 Generating candidate %d/%d\n", i+1, n)
+		var opts []llms.CallOption
+		if i > 0 {
+			opts = append(opts, llms.WithTemperature(candidateTemperature))
+		}
+		sql, err := p.generateSQL(ctx, query, contextPrompt, crossTableSummary, result, opts...)
+		if err != nil {
+			p.Logger.Printf("⚠️  Candidate %d failed: %v\n", i+1, err)
+			lastErr = err
+			continue
+		}
+		sql = p.repairSyntax(ctx, query, contextPrompt, sql, result)
+		candidates = append(candidates, Candidate{SQL: sql, Path: result.GenerationPath, Cluster: -1})
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("all %d candidates failed: %w", n, lastErr)
+	}
+
+	// Execute and cluster by result-set fingerprint
+	dialect := NormalizeDialect(p.queryAdapter.GetDatabaseType())
+	var clusters []candidateCluster
+	clusterOf := make(map[string]int)
+	for i := range candidates {
+		c := &candidates[i]
+		execResult, err := p.queryAdapter.ExecuteQuery(ctx, c.SQL)
+		result.SQLExecutions++
+		if err != nil {
+			c.Error = err.Error()
+			continue
+		}
+		c.Success = true
+		c.RowCount = len(execResult.Rows)
+		c.Fingerprint = resultFingerprint(execResult)
+		c.Complexity = sqlComplexity(c.SQL, dialect)
+
+		id, ok := clusterOf[c.Fingerprint]
+		if !ok {
+			id = len(clusters)
+			clusterOf[c.Fingerprint] = id
+			clusters = append(clusters, candidateCluster{empty: c.RowCount == 0, simplest: i})
+		}
+		c.Cluster = id
+		cl := &clusters[id]
+		cl.size++
+		if c.Complexity < candidates[cl.simplest].Complexity {
+			cl.simplest = i
+		}
+	}
+
+	// Largest cluster; ties: non-empty result, then the simpler SQL, then the earliest
+	best := -1
+	largest := 0
+	for id, cl := range clusters {
+		largest = max(largest, cl.size)
+		if best < 0 || clusterBetter(cl, clusters[best], candidates) {
+			best = id
+		}
+	}
+
+	selected := 0
+	for i := range candidates {
+		c := &candidates[i]
+		if c.Cluster >= 0 {
+			c.ClusterSize = clusters[c.Cluster].size
+		}
+	}
+	if best >= 0 {
+		selected = clusters[best].simplest
+	}
+	candidates[selected].Selected = true
+	result.Candidates = candidates
+	result.GenerationPath = candidates[selected].Path
+	result.Agreement = candidateAgreement(candidates, clusters, best, largest)
+
+	p.Logger.Printf("🗳️  Selected candidate %d/%d (cluster size %d, %d clusters, %d failed, agreement %.0f%%)\n",
+		selected+1, len(candidates), candidates[selected].ClusterSize, len(clusters), countFailed(candidates),
+		result.Agreement.Ratio*100)
+	return candidates[selected].SQL, nil
+}
+
+// candidateCluster candidates with the same result set
+type candidateCluster struct {
+	size     int
+	empty    bool // Zero rows
+	simplest int  // Candidate index with the lowest complexity (earliest on ties)
+}
+
+// clusterBetter reports whether a should be picked over b
+func clusterBetter(a, b candidateCluster, candidates []Candidate) bool {
+	if a.size != b.size {
+		return a.size > b.size
+	}
+	if a.empty != b.empty {
+		return !a.empty
+	}
+	return candidates[a.simplest].Complexity < candidates[b.simplest].Complexity
+}
+
+// Agreement self-consistency statistics of a multi-candidate run
+type Agreement struct {
+	Samples  int     `json:"samples"`  // Candidates generated
+	Executed int     `json:"executed"` // Candidates that executed
+	Clusters int     `json:"clusters"` // Distinct result sets
+	Selected int     `json:"selected"` // Size of the selected cluster
+	Ratio    float64 `json:"ratio"`    // Selected / Executed (0 if nothing executed)
+	Tied     bool    `json:"tied"`     // Several clusters had the largest size
+}
+
+// candidateAgreement summarizes how far the candidates agree
+func candidateAgreement(candidates []Candidate, clusters []candidateCluster, best, largest int) *Agreement {
+	a := &Agreement{Samples: len(candidates), Executed: len(candidates) - countFailed(candidates), Clusters: len(clusters)}
+	if best >= 0 {
+		a.Selected = clusters[best].size
+	}
+	if a.Executed > 0 {
+		a.Ratio = float64(a.Selected) / float64(a.Executed)
+	}
+	tops := 0
+	for _, cl := range clusters {
+		if cl.size == largest {
+			tops++
+		}
+	}
+	a.Tied = tops > 1
+	return a
+}
+
+// resultFingerprint hash of the sorted rows (see resultSetKey)
+func resultFingerprint(result *adapter.QueryResult) string {
+	sum := sha256.Sum256([]byte(resultSetKey(result)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// sqlComplexity rough size of a query for tie-breaking: tokens, plus a penalty per join and subquery
+func sqlComplexity(sql, dialect string) int {
+	toks, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return len(sql)
+	}
+	cost := len(toks)
+	selects := 0
+	for _, tok := range toks {
+		if tok.kind != tokWord {
+			continue
+		}
+		switch tok.upper {
+		case "JOIN":
+			cost += 5
+		case "SELECT":
+			selects++
+		}
+	}
+	if selects > 1 {
+		cost += 10 * (selects - 1)
+	}
+	return cost
+}
+
+// resultSetKey identifies a result set independent of row order and column names
+func resultSetKey(result *adapter.QueryResult) string {
+	rows := make([]string, len(result.Values))
+	for i, row := range result.Values {
+		keys := make([]string, len(row))
+		for j, v := range row {
+			keys[j] = v.Key()
+		}
+		rows[i] = strings.Join(keys, "\x1f")
+	}
+	sort.Strings(rows)
+	return fmt.Sprintf("%d|%s", len(result.Columns), strings.Join(rows, "\x1e"))
+}
+
+func countFailed(candidates []Candidate) int {
+	n := 0
+	for _, c := range candidates {
+		if !c.Success {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,162 @@
+package inference
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ClarifyRequest clarification question the agent asks the user
+type ClarifyRequest struct {
+	Query    string `json:"query"`    // Original natural-language question
+	Question string `json:"question"` // Agent's clarification question (clarify_fields input)
+}
+
+// ClarifyBackend answers the agent's clarification questions
+// Set one with Pipeline.SetClarifyBackend; the clarify_fields tool is offered when Config.ClarifyMode is "on".
+type ClarifyBackend interface {
+	Clarify(ctx context.Context, req ClarifyRequest) (string, error)
+}
+
+// ClarifyFunc adapts a function to ClarifyBackend
+type ClarifyFunc func(ctx context.Context, req ClarifyRequest) (string, error)
+
+// Clarify calls f
+func (f ClarifyFunc) Clarify(ctx context.Context, req ClarifyRequest) (string, error) {
+	return f(ctx, req)
+}
+
+// ─── Static (benchmark) ──────────────────────────────────────────
+
+// StaticClarifyBackend answers every question with pre-computed result fields (benchmark mode)
+type StaticClarifyBackend struct {
+	Fields      []string
+	Description string
+}
+
+// Clarify returns the field list and descriptions
+func (b *StaticClarifyBackend) Clarify(ctx context.Context, req ClarifyRequest) (string, error) {
+	return fmt.Sprintf("Required fields in EXACT ORDER: %s\n\nField descriptions: %s\n\nIMPORTANT: Use these field names WITHOUT table prefixes (e.g., 'Name' not 'singer.Name')",
+		strings.Join(b.Fields, ", "),
+		b.Description), nil
+}
+
+// ─── Terminal ────────────────────────────────────────────────────
+
+// StdinClarifyBackend asks the question on a terminal and reads one line as the answer
+type StdinClarifyBackend struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewStdinClarifyBackend creates terminal backend (nil in/out = stdin/stdout)
+func NewStdinClarifyBackend(in io.Reader, out io.Writer) *StdinClarifyBackend {
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+	return &StdinClarifyBackend{in: bufio.NewReader(in), out: out}
+}
+
+// Clarify prompts and waits for a line (or context cancellation)
+func (b *StdinClarifyBackend) Clarify(ctx context.Context, req ClarifyRequest) (string, error) {
+	fmt.Fprintf(b.out, "\n❓ The agent asks: %s\n> ", req.Question)
+
+	type line struct {
+		text string
+		err  error
+	}
+	ch := make(chan line, 1)
+	go func() {
+		text, err := b.in.ReadString('\n')
+		ch <- line{text, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case l := <-ch:
+		answer := strings.TrimSpace(l.text)
+		if l.err != nil && answer == "" {
+			return "", fmt.Errorf("failed to read answer: %w", l.err)
+		}
+		return answer, nil
+	}
+}
+
+// ─── Webhook ─────────────────────────────────────────────────────
+
+// defaultClarifyTimeout time a webhook has to answer (a human may be on the other side)
+const defaultClarifyTimeout = 5 * time.Minute
+
+// WebhookClarifyBackend POSTs the request as JSON and uses the response as the answer
+// The response may be JSON {"answer": "..."} or plain text.
+type WebhookClarifyBackend struct {
+	URL     string
+	Headers map[string]string // e.g. Authorization
+	Timeout time.Duration     // 0 = default 5 minutes
+	client  *http.Client
+}
+
+// NewWebhookClarifyBackend creates webhook backend
+func NewWebhookClarifyBackend(url string) *WebhookClarifyBackend {
+	return &WebhookClarifyBackend{URL: url, client: &http.Client{}}
+}
+
+// Clarify sends the request and reads the answer
+func (b *WebhookClarifyBackend) Clarify(ctx context.Context, req ClarifyRequest) (string, error) {
+	timeout := b.Timeout
+	if timeout <= 0 {
+		timeout = defaultClarifyTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode clarify request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, b.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create clarify request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range b.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	client := b.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("clarify webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read clarify response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("clarify webhook returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var parsed struct {
+		Answer string `json:"answer"`
+	}
+	if err := json.Unmarshal(data, &parsed); err == nil && parsed.Answer != "" {
+		return parsed.Answer, nil
+	}
+	return strings.TrimSpace(string(data)), nil
+}
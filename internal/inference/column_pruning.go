@@ -0,0 +1,328 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	contextpkg "reactsql/internal/context"
+
+	"github.com/tmc/langchaingo/embeddings"
+)
+
+// columnRelevanceFloor columns scoring at least this are never pruned
+const columnRelevanceFloor = 0.3
+
+// ColumnRelevance relevance score of a schema column to the question
+type ColumnRelevance struct {
+	Table  string  `json:"table"`
+	Column string  `json:"column"`
+	Score  float64 `json:"score"` // max(lexical overlap, embedding cosine), 0..1
+}
+
+// promptColumn a column line of a compact schema prompt
+type promptColumn struct {
+	line   int
+	table  string
+	column string
+	key    bool // Marked PK / FK ("[PK]", "→ t.c")
+}
+
+// pruneColumns drops low-relevance columns from a schema prompt over Config.PruneSchemaTokens
+// Columns are ranked by similarity to the question and dropped lowest first until the prompt
+// fits; keys and columns at or above columnRelevanceFloor are kept. Works on the compact
+// ("Table X (N rows):" / "  - col: type ...") and basic schema layouts.
+func (p *Pipeline) pruneColumns(ctx context.Context, query, contextPrompt string, tables map[string]*TableInfo) (string, []ColumnRelevance) {
+	limit := p.config.PruneSchemaTokens
+	if limit <= 0 {
+		return contextPrompt, nil
+	}
+	tokens := p.estimateTokens(contextPrompt)
+	if tokens <= limit {
+		return contextPrompt, nil
+	}
+
+	lines := strings.Split(contextPrompt, "\n")
+	columns := parsePromptColumns(lines)
+	keys := p.keyColumns(ctx, columns, tables)
+	var candidates []promptColumn
+	for _, col := range columns {
+		if !col.key && !keys[strings.ToLower(col.table+"."+col.column)] {
+			candidates = append(candidates, col)
+		}
+	}
+	if len(candidates) == 0 {
+		p.Logger.Printf("✂️  Column pruning: schema %d tokens > %d, but only key columns to prune\n", tokens, limit)
+		return contextPrompt, nil
+	}
+
+	scores := p.scoreColumns(ctx, query, candidates)
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	// Lowest score first; on ties the later column (schemas list the important ones first)
+	sort.SliceStable(order, func(a, b int) bool {
+		if scores[order[a]] != scores[order[b]] {
+			return scores[order[a]] < scores[order[b]]
+		}
+		return order[a] > order[b]
+	})
+
+	dropped := make(map[int]bool) // Line index
+	droppedCols := make(map[string]bool)
+	var pruned []ColumnRelevance
+	remaining := tokens
+	for _, i := range order {
+		if remaining <= limit || scores[i] >= columnRelevanceFloor {
+			break
+		}
+		col := candidates[i]
+		dropped[col.line] = true
+		droppedCols[strings.ToLower(col.table+"."+col.column)] = true
+		remaining -= p.estimateTokens(lines[col.line] + "\n")
+		pruned = append(pruned, ColumnRelevance{Table: col.table, Column: col.column, Score: scores[i]})
+	}
+	if len(pruned) == 0 {
+		p.Logger.Printf("✂️  Column pruning: schema %d tokens > %d, all %d non-key columns relevant (score ≥ %.2f), kept\n",
+			tokens, limit, len(candidates), columnRelevanceFloor)
+		return contextPrompt, nil
+	}
+
+	kept := make([]string, 0, len(lines)-len(dropped))
+	for i, line := range lines {
+		if dropped[i] || mentionsDroppedColumn(line, droppedCols) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	result := strings.Join(kept, "\n")
+
+	names := make([]string, len(pruned))
+	for i, c := range pruned {
+		names[i] = fmt.Sprintf("%s.%s (%.2f)", c.Table, c.Column, c.Score)
+	}
+	p.Logger.Printf("✂️  Column pruning: schema %d tokens > %d, dropped %d of %d non-key columns (→ %d tokens)\n",
+		tokens, limit, len(pruned), len(candidates), p.estimateTokens(result))
+	p.Logger.FileOnly("   Pruned: %s\n", strings.Join(names, ", "))
+	return result, pruned
+}
+
+// parsePromptColumns column lines per "Table ..." block
+func parsePromptColumns(lines []string) []promptColumn {
+	var columns []promptColumn
+	table := ""
+	for i, line := range lines {
+		if strings.HasPrefix(line, "Table ") {
+			table = promptTableName(line)
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			table = ""
+			continue
+		}
+		rest, ok := strings.CutPrefix(line, "  - ")
+		if !ok || table == "" {
+			continue
+		}
+		name, _, ok := strings.Cut(rest, ": ")
+		if !ok || name == "" {
+			continue
+		}
+		columns = append(columns, promptColumn{
+			line:   i,
+			table:  table,
+			column: name,
+			key:    strings.Contains(rest, "[PK]") || strings.Contains(rest, " → "),
+		})
+	}
+	return columns
+}
+
+// promptTableName table name of a "Table <name> (N rows):" / "Table <name>:" line
+func promptTableName(line string) string {
+	name := strings.TrimSuffix(strings.TrimPrefix(line, "Table "), ":")
+	if i := strings.LastIndex(name, " ("); i > 0 && strings.HasSuffix(name, ")") {
+		name = name[:i]
+	}
+	return strings.TrimSpace(name)
+}
+
+// keyColumns both ends of every foreign key, plus the primary keys of the prompt's tables
+// when the prompt does not mark them (basic schema), as lower-cased "table.column"
+func (p *Pipeline) keyColumns(ctx context.Context, columns []promptColumn, tables map[string]*TableInfo) map[string]bool {
+	keys := make(map[string]bool)
+	for _, info := range tables {
+		for _, fk := range info.ForeignKeys {
+			refColumns := fk.ReferencedKeyColumns()
+			for i, col := range fk.KeyColumns() {
+				keys[strings.ToLower(info.Name+"."+col)] = true
+				keys[strings.ToLower(fk.ReferencedTable+"."+refColumns[i])] = true
+			}
+		}
+	}
+	if p.context != nil {
+		return keys
+	}
+
+	seen := make(map[string]bool)
+	for _, col := range columns {
+		if seen[col.table] {
+			continue
+		}
+		seen[col.table] = true
+		tableColumns, err := p.adapter.GetColumns(ctx, col.table)
+		if err != nil {
+			continue
+		}
+		for _, c := range tableColumns {
+			if c.IsPrimaryKey {
+				keys[strings.ToLower(col.table+"."+c.Name)] = true
+			}
+		}
+	}
+	return keys
+}
+
+// mentionsDroppedColumn reports whether an annotation line (quality issue) is about a dropped column
+func mentionsDroppedColumn(line string, dropped map[string]bool) bool {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "* [") {
+		return false
+	}
+	_, rest, ok := strings.Cut(trimmed, "] ")
+	if !ok {
+		return false
+	}
+	ref, _, ok := strings.Cut(rest, ":")
+	return ok && dropped[strings.ToLower(strings.TrimSpace(ref))]
+}
+
+// scoreColumns relevance of each column to the question: the better of lexical overlap
+// of the column name and embedding cosine (Config.ColumnEmbedder, local HashEmbedder if nil;
+// column vectors stored in the context are reused when the embedder model matches)
+func (p *Pipeline) scoreColumns(ctx context.Context, query string, columns []promptColumn) []float64 {
+	queryWords := make(map[string]bool)
+	for _, w := range relevanceWords(query) {
+		queryWords[w] = true
+	}
+
+	scores := make([]float64, len(columns))
+	texts := make([]string, len(columns))
+	for i, col := range columns {
+		words := relevanceWords(col.column)
+		texts[i] = strings.Join(words, " ")
+		if len(words) == 0 {
+			continue
+		}
+		hits := 0
+		for _, w := range words {
+			if queryWords[w] {
+				hits++
+			}
+		}
+		scores[i] = float64(hits) / float64(len(words))
+	}
+
+	embedder := p.config.ColumnEmbedder
+	if embedder == nil {
+		embedder = NewHashEmbedder(defaultHashDim)
+	}
+	queryVec, err := embedder.EmbedQuery(ctx, query)
+	if err == nil {
+		var vectors [][]float32
+		vectors, err = p.columnVectors(ctx, embedder, columns, texts)
+		if err == nil && len(vectors) == len(columns) {
+			normalizeVector(queryVec)
+			for i, v := range vectors {
+				if len(v) != len(queryVec) {
+					continue
+				}
+				normalizeVector(v)
+				var dot float64
+				for j := range v {
+					dot += float64(v[j]) * float64(queryVec[j])
+				}
+				if dot > scores[i] {
+					scores[i] = dot
+				}
+			}
+		}
+	}
+	if err != nil {
+		p.Logger.Printf("⚠️  Column embedding failed, using lexical relevance only: %v\n", err)
+	}
+	return scores
+}
+
+// columnVectors embeddings of the columns, reusing the context's stored vectors when they
+// come from the same embedder model; only columns without a stored vector are embedded
+func (p *Pipeline) columnVectors(ctx context.Context, embedder embeddings.Embedder, columns []promptColumn, texts []string) ([][]float32, error) {
+	var stored *contextpkg.SchemaEmbeddings
+	if p.context != nil && p.context.Embeddings != nil && p.context.Embeddings.Model == contextpkg.EmbedderModel(embedder) {
+		stored = p.context.Embeddings
+	}
+
+	vectors := make([][]float32, len(columns))
+	var missing []int
+	var missingTexts []string
+	for i, col := range columns {
+		if v := stored.ColumnVector(col.table, col.column); v != nil {
+			vectors[i] = append([]float32(nil), v...) // Copied: normalized in place by the caller
+			continue
+		}
+		missing = append(missing, i)
+		missingTexts = append(missingTexts, texts[i])
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := embedder.EmbedDocuments(ctx, missingTexts)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missing) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d columns", len(embedded), len(missing))
+	}
+	for j, i := range missing {
+		vectors[i] = embedded[j]
+	}
+	return vectors, nil
+}
+
+// relevanceWords lower-cased words of an identifier or question, singularized, stopwords removed
+// Identifiers split on "_", spaces and camelCase ("orderDate" → order, date).
+func relevanceWords(text string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) == 0 {
+			return
+		}
+		w := strings.ToLower(string(cur))
+		cur = cur[:0]
+		if fewShotStopwords[w] {
+			return
+		}
+		if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+			w = strings.TrimSuffix(w, "s")
+		}
+		words = append(words, w)
+	}
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,192 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// compareExampleRows differing rows shown per side
+const compareExampleRows = 3
+
+// CompareSQLTool result-equivalence tool
+// Runs two queries and reports whether their results differ, so the model can tell whether
+// a revision changed the semantics of a draft.
+type CompareSQLTool struct {
+	adapter adapter.DBAdapter
+	logger  *InferenceLogger
+}
+
+// NewCompareSQLTool creates result-equivalence tool
+func NewCompareSQLTool(adapter adapter.DBAdapter) *CompareSQLTool {
+	return &CompareSQLTool{adapter: adapter}
+}
+
+// Name returns tool name
+func (t *CompareSQLTool) Name() string {
+	return "compare_sql"
+}
+
+// Description returns tool description
+func (t *CompareSQLTool) Description() string {
+	return `Compare the RESULTS of two SQL queries (e.g. your draft and a revision).
+Executes both and reports whether they return the same rows (ignoring row order), the same rows in a different order, or different rows (with examples).
+
+Input should be a JSON object with:
+- sql_a: string (first query, e.g. the draft)
+- sql_b: string (second query, e.g. the revision)
+
+Example:
+{"sql_a": "SELECT name FROM singer WHERE age > 30", "sql_b": "SELECT name FROM singer WHERE age >= 31"}
+
+Use this tool when you rewrite a query and need to know whether the "fix" actually changed the answer.`
+}
+
+// compareInput compare_sql parameters
+type compareInput struct {
+	SQLA string `json:"sql_a"`
+	SQLB string `json:"sql_b"`
+}
+
+// Call executes both queries and compares their results
+func (t *CompareSQLTool) Call(ctx context.Context, input string) (string, error) {
+	logf := func(format string, a ...interface{}) {
+		if t.logger != nil {
+			t.logger.Printf(format, a...)
+		} else {
+			fmt.Printf(format, a...)
+		}
+	}
+
+	sqlA, sqlB, ok := parseCompareInput(input)
+	if !ok {
+		return `⚠️  Failed to parse input. Provide JSON: {"sql_a": "<first SQL>", "sql_b": "<second SQL>"} without markdown code blocks.`, nil
+	}
+
+	logf("\n⚖️  Tool Call [compare_sql]:\n")
+	logf("SQL A: %s\n", sqlA)
+	logf("SQL B: %s\n", sqlB)
+
+	resultA, errA := t.adapter.ExecuteQuery(ctx, sqlA)
+	resultB, errB := t.adapter.ExecuteQuery(ctx, sqlB)
+	if errA != nil || errB != nil {
+		var sb strings.Builder
+		sb.WriteString("❌ Cannot compare, a query failed:\n")
+		if errA != nil {
+			sb.WriteString(fmt.Sprintf("SQL A: %v\n", errA))
+		}
+		if errB != nil {
+			sb.WriteString(fmt.Sprintf("SQL B: %v\n", errB))
+		}
+		logf("Output: %s\n", sb.String())
+		return sb.String(), nil
+	}
+
+	output := compareResults(resultA, resultB)
+	logf("Output: %s\n", output)
+	return output, nil
+}
+
+// parseCompareInput reads {"sql_a", "sql_b"}, or two queries separated by a "---" line
+func parseCompareInput(input string) (string, string, bool) {
+	input = strings.TrimSpace(input)
+	input = strings.TrimPrefix(input, "```json")
+	input = strings.TrimPrefix(input, "```")
+	input = strings.TrimSuffix(input, "```")
+	input = strings.TrimSpace(input)
+
+	var in compareInput
+	if err := json.Unmarshal([]byte(input), &in); err != nil {
+		parts := strings.SplitN(input, "\n---\n", 2)
+		if len(parts) != 2 {
+			return "", "", false
+		}
+		in.SQLA, in.SQLB = parts[0], parts[1]
+	}
+	sqlA := strings.TrimSuffix(strings.TrimSpace(in.SQLA), ";")
+	sqlB := strings.TrimSuffix(strings.TrimSpace(in.SQLB), ";")
+	return sqlA, sqlB, sqlA != "" && sqlB != ""
+}
+
+// compareResults reports whether two result sets are equal, equal up to row order, or different
+func compareResults(a, b *adapter.QueryResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("SQL A: %d row(s), columns: %s\n", len(a.Values), strings.Join(a.Columns, ", ")))
+	sb.WriteString(fmt.Sprintf("SQL B: %d row(s), columns: %s\n", len(b.Values), strings.Join(b.Columns, ", ")))
+
+	if len(a.Columns) != len(b.Columns) {
+		sb.WriteString(fmt.Sprintf("❌ DIFFERENT: the queries return %d vs %d columns.", len(a.Columns), len(b.Columns)))
+		return sb.String()
+	}
+
+	keysA := rowKeys(a.Values)
+	keysB := rowKeys(b.Values)
+
+	// Multiset difference in both directions
+	counts := make(map[string]int, len(keysA))
+	for _, k := range keysA {
+		counts[k]++
+	}
+	var onlyB []int
+	for i, k := range keysB {
+		if counts[k] > 0 {
+			counts[k]--
+		} else {
+			onlyB = append(onlyB, i)
+		}
+	}
+	var onlyA []int
+	for i := len(keysA) - 1; i >= 0; i-- {
+		if counts[keysA[i]] > 0 {
+			counts[keysA[i]]--
+			onlyA = append([]int{i}, onlyA...)
+		}
+	}
+
+	if len(onlyA) == 0 && len(onlyB) == 0 {
+		for i := range keysA {
+			if keysA[i] != keysB[i] {
+				sb.WriteString("⚠️  SAME rows, DIFFERENT order. This only matters if the question asks for an ordering (ORDER BY / top-N).")
+				return sb.String()
+			}
+		}
+		sb.WriteString("✓ IDENTICAL results: the two queries are equivalent on this data.")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("❌ DIFFERENT: %d row(s) only in A, %d row(s) only in B.\n", len(onlyA), len(onlyB)))
+	writeRowExamples(&sb, "Only in A", a, onlyA)
+	writeRowExamples(&sb, "Only in B", b, onlyB)
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// rowKeys comparable key per row (typed values, column names ignored)
+func rowKeys(values [][]adapter.Value) []string {
+	keys := make([]string, len(values))
+	for i, row := range values {
+		parts := make([]string, len(row))
+		for j, v := range row {
+			parts[j] = v.Key()
+		}
+		keys[i] = strings.Join(parts, "\x1f")
+	}
+	return keys
+}
+
+// writeRowExamples lists the first differing rows of one side
+func writeRowExamples(sb *strings.Builder, label string, result *adapter.QueryResult, indexes []int) {
+	if len(indexes) == 0 {
+		return
+	}
+	sb.WriteString(label + ":\n")
+	for i, idx := range indexes {
+		if i >= compareExampleRows {
+			sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(indexes)-compareExampleRows))
+			break
+		}
+		sb.WriteString("  " + adapter.FormatRows(result.Columns, result.Values[idx:idx+1], 1) + "\n")
+	}
+}
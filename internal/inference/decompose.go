@@ -0,0 +1,249 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+const (
+	// maxSubQuestions sub-questions per decomposition plan
+	maxSubQuestions = 4
+	// subResultPreviewRows rows of each sub-result shown to later steps
+	subResultPreviewRows = 5
+)
+
+// SubQuestion one step of a decomposed question, solved as a CTE
+type SubQuestion struct {
+	Name     string `json:"name"` // CTE name
+	Question string `json:"question"`
+	SQL      string `json:"sql,omitempty"`
+	Preview  string `json:"preview,omitempty"` // First rows of the intermediate result
+	Error    string `json:"error,omitempty"`   // Intermediate execution error
+}
+
+// cteNamePattern valid CTE identifiers (the plan's names become SQL)
+var cteNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// generateDecomposed breaks a multi-hop question into sub-questions, solves each with an
+// intermediate execution, and composes the final SQL from them as CTEs.
+// Returns "" when the question is simple or decomposition fails (caller falls back to normal generation).
+func (p *Pipeline) generateDecomposed(ctx context.Context, query, contextPrompt, crossTableSummary string, result *Result) string {
+	p.Logger.Println("🧩 Question decomposition...")
+
+	// 1. Plan sub-questions
+	planPrompt := p.buildDecomposePlanPrompt(query, contextPrompt)
+	response, err := p.llm.Call(ctx, planPrompt)
+	result.LLMCalls++
+	if err != nil {
+		p.Logger.Printf("⚠️  Decomposition planning failed: %v\n", err)
+		return ""
+	}
+	p.promptTexts = append(p.promptTexts, planPrompt)
+	p.responseTexts = append(p.responseTexts, response)
+
+	subs := parseDecomposePlan(response)
+	if len(subs) < 2 {
+		p.Logger.Println("🧩 Question is single-hop, skipping decomposition")
+		return ""
+	}
+	p.Logger.Printf("🧩 Decomposed into %d sub-questions\n", len(subs))
+
+	// 2. Solve sub-questions in order; each may reference the earlier ones by name
+	for i := range subs {
+		sub := &subs[i]
+		prompt := p.buildSubQuestionPrompt(query, contextPrompt, crossTableSummary, subs[:i], *sub)
+		response, err := p.llm.Call(ctx, prompt)
+		result.LLMCalls++
+		if err != nil {
+			p.Logger.Printf("⚠️  Sub-question %d failed: %v\n", i+1, err)
+			return ""
+		}
+		p.promptTexts = append(p.promptTexts, prompt)
+		p.responseTexts = append(p.responseTexts, response)
+
+		sub.SQL = p.extractSQL(response)
+		execResult, execErr := p.queryAdapter.ExecuteQuery(ctx, withCTEs(subs[:i], sub.SQL))
+		result.SQLExecutions++
+		if execErr != nil {
+			sub.Error = execErr.Error()
+		} else {
+			sub.Preview = fmt.Sprintf("%d rows: %s", execResult.RowCount,
+				adapter.FormatRows(execResult.Columns, execResult.Values, subResultPreviewRows))
+		}
+
+		observation := sub.Preview
+		if sub.Error != "" {
+			observation = "Execution error: " + sub.Error
+		}
+		step := ReActStep{
+			Thought:     fmt.Sprintf("Sub-question %d (%s): %s", i+1, sub.Name, sub.Question),
+			Action:      "solve_subquestion",
+			ActionInput: sub.SQL,
+			Observation: truncate(observation, 500),
+			Phase:       "decomposition",
+		}
+		result.ReActSteps = append(result.ReActSteps, step)
+		p.notifyStep(step, "observation")
+		p.Logger.Printf("🧩 [%d/%d] %s → %s\n", i+1, len(subs), sub.Name, truncate(observation, 120))
+	}
+	result.SubQuestions = subs
+
+	// 3. Compose the final SQL from the solved steps
+	composePrompt := p.buildComposePrompt(query, contextPrompt, subs)
+	response, err = p.llm.Call(ctx, composePrompt)
+	result.LLMCalls++
+	if err != nil {
+		p.Logger.Printf("⚠️  Composition failed: %v\n", err)
+		return ""
+	}
+	p.promptTexts = append(p.promptTexts, composePrompt)
+	p.responseTexts = append(p.responseTexts, response)
+
+	sql := p.extractSQL(response)
+	step := ReActStep{
+		Thought:     "Compose the final query from the solved sub-questions",
+		Action:      "compose",
+		Observation: sql,
+		Phase:       "decomposition",
+	}
+	result.ReActSteps = append(result.ReActSteps, step)
+	p.notifyStep(step, "observation")
+	p.Logger.Printf("🧩 Composed SQL: %s\n", sql)
+	return sql
+}
+
+// parseDecomposePlan parses the JSON sub-question list; anything else means "simple"
+func parseDecomposePlan(response string) []SubQuestion {
+	start := strings.Index(response, "[")
+	end := strings.LastIndex(response, "]")
+	if start < 0 || end <= start {
+		return nil
+	}
+
+	var plan []SubQuestion
+	if err := json.Unmarshal([]byte(response[start:end+1]), &plan); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var subs []SubQuestion
+	for i, sub := range plan {
+		sub.Question = strings.TrimSpace(sub.Question)
+		if sub.Question == "" {
+			continue
+		}
+		if !cteNamePattern.MatchString(sub.Name) || seen[strings.ToLower(sub.Name)] {
+			sub.Name = fmt.Sprintf("step%d", i+1)
+		}
+		seen[strings.ToLower(sub.Name)] = true
+		sub.SQL, sub.Preview, sub.Error = "", "", ""
+		subs = append(subs, sub)
+		if len(subs) == maxSubQuestions {
+			break
+		}
+	}
+	return subs
+}
+
+// withCTEs prefixes a query with the earlier sub-questions as CTEs
+// Later CTEs may reference earlier ones; a query with its own WITH clause is merged into the same list.
+func withCTEs(prior []SubQuestion, sql string) string {
+	if len(prior) == 0 {
+		return sql
+	}
+	defs := make([]string, len(prior))
+	for i, sub := range prior {
+		defs[i] = fmt.Sprintf("%s AS (%s)", sub.Name, sub.SQL)
+	}
+	body := strings.TrimSpace(sql)
+	if len(body) > 5 && strings.EqualFold(body[:5], "WITH ") {
+		return "WITH " + strings.Join(defs, ", ") + ", " + strings.TrimSpace(body[5:])
+	}
+	return "WITH " + strings.Join(defs, ", ") + " " + body
+}
+
+// buildDecomposePlanPrompt asks whether the question needs multiple hops and, if so, for the plan
+func (p *Pipeline) buildDecomposePlanPrompt(query, contextPrompt string) string {
+	var sb strings.Builder
+	sb.WriteString("You are a database expert planning how to answer a question with SQL.\n\n")
+	sb.WriteString("## Database Schema\n")
+	sb.WriteString(contextPrompt)
+	sb.WriteString(fmt.Sprintf("\n\nQuestion: %s\n\n", query))
+	sb.WriteString("If the question can be answered by one straightforward query, output exactly: SIMPLE\n\n")
+	sb.WriteString(fmt.Sprintf("Otherwise break it into 2-%d sub-questions that build on each other ", maxSubQuestions))
+	sb.WriteString("(e.g. first find an aggregate or an entity, then use it in a comparison or a filter). ")
+	sb.WriteString("Each sub-question becomes a named intermediate result that later sub-questions can query like a table.\n")
+	sb.WriteString("Output a JSON array only:\n")
+	sb.WriteString(`[{"name": "snake_case_name", "question": "sub-question in plain English"}, ...]`)
+	sb.WriteString("\nThe last sub-question should be the original question rephrased in terms of the earlier results.\n")
+	return sb.String()
+}
+
+// buildSubQuestionPrompt asks for the SQL of one sub-question, given the earlier solved steps
+func (p *Pipeline) buildSubQuestionPrompt(query, contextPrompt, crossTableSummary string, prior []SubQuestion, sub SubQuestion) string {
+	dialect := dialectDisplayName(p.generationDialect())
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("You are writing one step of a multi-step %s query.\n\n", dialect))
+	sb.WriteString("## Database Schema\n")
+	sb.WriteString(contextPrompt)
+	sb.WriteString("\n")
+	if crossTableSummary != "" {
+		sb.WriteString(crossTableSummary)
+		sb.WriteString("\n")
+	}
+	if p.glossaryPrompt != "" {
+		sb.WriteString(p.glossaryPrompt)
+	}
+	if p.valueHintPrompt != "" {
+		sb.WriteString(p.valueHintPrompt)
+	}
+	sb.WriteString(fmt.Sprintf("\nOriginal question: %s\n\n", query))
+
+	if len(prior) > 0 {
+		sb.WriteString("## Earlier Steps (query them by name like tables)\n")
+		writeSubQuestions(&sb, prior)
+	}
+
+	sb.WriteString(fmt.Sprintf("## Current Step: %s\n%s\n\n", sub.Name, sub.Question))
+	sb.WriteString("Write a single SELECT statement for the current step only. Refer to earlier steps by name; do not redefine them.\n")
+	sb.WriteString(fmt.Sprintf("Output ONLY the %s SQL after \"Final Answer:\".\n", dialect))
+	return sb.String()
+}
+
+// buildComposePrompt asks for the final query built from the solved steps
+func (p *Pipeline) buildComposePrompt(query, contextPrompt string, subs []SubQuestion) string {
+	dialect := dialectDisplayName(p.generationDialect())
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Compose the final %s query for a question that was solved in steps.\n\n", dialect))
+	sb.WriteString("## Database Schema\n")
+	sb.WriteString(contextPrompt)
+	sb.WriteString(fmt.Sprintf("\n\nQuestion: %s\n\n", query))
+	sb.WriteString("## Solved Steps\n")
+	writeSubQuestions(&sb, subs)
+	sb.WriteString("Write ONE query that answers the original question:\n")
+	sb.WriteString("- Define the steps you need as CTEs: WITH step_a AS (...), step_b AS (...) SELECT ...\n")
+	sb.WriteString("- Fix any step that failed or returned an implausible result\n")
+	sb.WriteString("- The final SELECT must return exactly what the question asks for\n")
+	sb.WriteString(fmt.Sprintf("Output ONLY the %s SQL after \"Final Answer:\".\n", dialect))
+	return sb.String()
+}
+
+func writeSubQuestions(sb *strings.Builder, subs []SubQuestion) {
+	for i, sub := range subs {
+		sb.WriteString(fmt.Sprintf("%d. %s: %s\n", i+1, sub.Name, sub.Question))
+		sb.WriteString(fmt.Sprintf("   SQL: %s\n", sub.SQL))
+		if sub.Error != "" {
+			sb.WriteString(fmt.Sprintf("   Execution error: %s\n", sub.Error))
+		} else {
+			sb.WriteString(fmt.Sprintf("   Result: %s\n", truncate(sub.Preview, 600)))
+		}
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,352 @@
+package inference
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EvidenceHint one clause of BIRD evidence, parsed (Config.Evidence)
+type EvidenceHint struct {
+	Kind       string `json:"kind"`                 // "mapping" | "value" | "formula" | "note"
+	Phrase     string `json:"phrase,omitempty"`     // Question wording the clause defines
+	Table      string `json:"table,omitempty"`      // Resolved against the schema ("" if unknown or ambiguous)
+	Column     string `json:"column,omitempty"`     // mapping / value
+	Operator   string `json:"operator,omitempty"`   // value: =, >, LIKE, IN, BETWEEN, ...
+	Value      string `json:"value,omitempty"`      // value: literal as written
+	Expression string `json:"expression,omitempty"` // formula: SQL expression as written
+	Text       string `json:"text"`                 // Clause as written
+}
+
+// Hint kinds
+const (
+	EvidenceMapping = "mapping" // Phrase names a column
+	EvidenceValue   = "value"   // Phrase is a column compared with a literal
+	EvidenceFormula = "formula" // Phrase is computed by an expression
+	EvidenceNote    = "note"    // Not understood, kept as text
+)
+
+const evidenceIdent = "(?:`[^`]+`|\"[^\"]+\"|\\[[^\\]]+\\]|[A-Za-z_][A-Za-z0-9_]*)"
+
+var (
+	// evidenceConnector "<phrase> refers to <definition>" and its variants
+	evidenceConnector = regexp.MustCompile(`(?is)^(.+?)\s+(?:refers?\s+to|means?|stands\s+for|represents?|indicates?|is\s+defined\s+as|is\s+calculated\s+as|can\s+be\s+computed\s+(?:as|by))\s+(.+)$`)
+
+	// evidenceConstraint "[table.]column <op> <value>"
+	evidenceConstraint = regexp.MustCompile(`(?is)^((?:` + evidenceIdent + `\s*\.\s*)?` + evidenceIdent + `)\s*(=|!=|<>|>=|<=|>|<|\bNOT\s+LIKE\b|\bLIKE\b|\bNOT\s+IN\b|\bIN\b|\bBETWEEN\b)\s*(.+)$`)
+
+	// evidenceColumnRef "[table.]column"
+	evidenceColumnRef = regexp.MustCompile(`^(?:(` + evidenceIdent + `)\s*\.\s*)?(` + evidenceIdent + `)$`)
+
+	// evidenceRangeAnd separator of BETWEEN bounds
+	evidenceRangeAnd = regexp.MustCompile(`(?i)\s+AND\s+`)
+
+	// evidenceLiteral a string, number, percentage, NULL or boolean
+	evidenceLiteral = regexp.MustCompile(`(?is)^(?:'(?:[^']|'')*'|"[^"]*"|-?\d+(?:\.\d+)?%?|NULL|TRUE|FALSE)$`)
+)
+
+// ParseEvidence splits evidence into clauses (";" and line breaks) and classifies each
+// Table is left empty; resolveEvidence fills it from the schema.
+func ParseEvidence(evidence string) []EvidenceHint {
+	var hints []EvidenceHint
+	for _, clause := range splitEvidence(evidence) {
+		hints = append(hints, parseEvidenceClause(clause))
+	}
+	return hints
+}
+
+// splitEvidence splits on ";" and newlines outside quotes and parentheses
+func splitEvidence(evidence string) []string {
+	var clauses []string
+	var cur strings.Builder
+	var quote rune
+	depth := 0
+	flush := func() {
+		clause := strings.TrimSpace(cur.String())
+		clause = strings.TrimSpace(strings.TrimSuffix(clause, "."))
+		if clause != "" {
+			clauses = append(clauses, clause)
+		}
+		cur.Reset()
+	}
+	for _, r := range evidence {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth > 0 {
+				depth--
+			}
+		case (r == ';' || r == '\n') && depth == 0:
+			flush()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	flush()
+	return clauses
+}
+
+// parseEvidenceClause classifies one clause
+func parseEvidenceClause(clause string) EvidenceHint {
+	hint := EvidenceHint{Kind: EvidenceNote, Text: clause}
+
+	definition := clause
+	if m := evidenceConnector.FindStringSubmatch(clause); m != nil {
+		hint.Phrase = strings.Trim(strings.TrimSpace(m[1]), "'\"")
+		definition = strings.TrimSpace(m[2])
+	}
+
+	if m := evidenceConstraint.FindStringSubmatch(definition); m != nil {
+		op := strings.ToUpper(strings.Join(strings.Fields(m[2]), " "))
+		value := strings.TrimSpace(m[3])
+		if isEvidenceLiteral(op, value) {
+			hint.Kind = EvidenceValue
+			hint.Table, hint.Column = splitColumnRef(m[1])
+			hint.Operator = op
+			hint.Value = value
+			return hint
+		}
+		if hint.Phrase == "" && op == "=" {
+			// "percentage = DIVIDE(...)": the left side is the phrase being defined
+			hint.Kind = EvidenceFormula
+			hint.Phrase = strings.TrimSpace(m[1])
+			hint.Expression = value
+			return hint
+		}
+	}
+
+	if hint.Phrase != "" && evidenceColumnRef.MatchString(definition) {
+		hint.Kind = EvidenceMapping
+		hint.Table, hint.Column = splitColumnRef(definition)
+		return hint
+	}
+
+	if hint.Phrase != "" && strings.ContainsAny(definition, "()*/+-=<>") {
+		hint.Kind = EvidenceFormula
+		hint.Expression = definition
+	}
+	return hint
+}
+
+// isEvidenceLiteral reports whether the right side of a comparison is only literals
+func isEvidenceLiteral(op, value string) bool {
+	switch op {
+	case "IN", "NOT IN":
+		inner := strings.TrimSpace(value)
+		if !strings.HasPrefix(inner, "(") || !strings.HasSuffix(inner, ")") {
+			return false
+		}
+		for _, item := range splitTopLevel(inner[1:len(inner)-1], ',') {
+			if !evidenceLiteral.MatchString(strings.TrimSpace(item)) {
+				return false
+			}
+		}
+		return true
+	case "BETWEEN":
+		parts := evidenceRangeAnd.Split(value, 2)
+		return len(parts) == 2 && evidenceLiteral.MatchString(strings.TrimSpace(parts[0])) &&
+			evidenceLiteral.MatchString(strings.TrimSpace(parts[1]))
+	}
+	return evidenceLiteral.MatchString(value)
+}
+
+// splitTopLevel splits on sep outside quotes and parentheses
+func splitTopLevel(s string, sep rune) []string {
+	var parts []string
+	var quote rune
+	depth, start := 0, 0
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// splitColumnRef "[table.]column" with identifier quotes removed
+func splitColumnRef(ref string) (string, string) {
+	m := evidenceColumnRef.FindStringSubmatch(strings.TrimSpace(ref))
+	if m == nil {
+		return "", strings.TrimSpace(ref)
+	}
+	return evidenceName(m[1]), evidenceName(m[2])
+}
+
+// evidenceName identifier with quotes ("x", `x`, [x]) removed
+func evidenceName(ident string) string {
+	if len(ident) >= 2 && strings.ContainsRune("\"`[", rune(ident[0])) {
+		return unquoteIdent(ident)
+	}
+	return ident
+}
+
+// resolveEvidence sets canonical table and column names from the schema
+// A qualifier must name a table; an unqualified column is attributed to the one selected
+// table that has it, else to the one table in the schema that has it.
+func resolveEvidence(hints []EvidenceHint, tables map[string]*TableInfo, selected []string) {
+	byLower := make(map[string]*TableInfo, len(tables))
+	for _, info := range tables {
+		byLower[strings.ToLower(info.Name)] = info
+	}
+	selectedSet := lowerSet(selected)
+
+	columnOf := func(info *TableInfo, column string) (string, bool) {
+		for _, c := range info.Columns {
+			if strings.EqualFold(c, column) {
+				return c, true
+			}
+		}
+		return "", false
+	}
+
+	for i := range hints {
+		h := &hints[i]
+		if h.Column == "" {
+			continue
+		}
+		if h.Table != "" {
+			info, ok := byLower[strings.ToLower(h.Table)]
+			if !ok {
+				h.Table = "" // Alias (T1) or unknown: resolve by column
+			} else {
+				h.Table = info.Name
+				if col, ok := columnOf(info, h.Column); ok {
+					h.Column = col
+				}
+				continue
+			}
+		}
+
+		var inSelected, inAll []*TableInfo
+		for _, info := range tables {
+			if _, ok := columnOf(info, h.Column); ok {
+				inAll = append(inAll, info)
+				if selectedSet[strings.ToLower(info.Name)] {
+					inSelected = append(inSelected, info)
+				}
+			}
+		}
+		match := inSelected
+		if len(match) == 0 {
+			match = inAll
+		}
+		if len(match) == 1 {
+			h.Table = match[0].Name
+			h.Column, _ = columnOf(match[0], h.Column)
+		}
+	}
+}
+
+// formatEvidenceHint one hint as a prompt line
+func formatEvidenceHint(h EvidenceHint) string {
+	column := h.Column
+	if h.Table != "" {
+		column = h.Table + "." + h.Column
+	}
+	phrase := ""
+	if h.Phrase != "" {
+		phrase = fmt.Sprintf("%q → ", h.Phrase)
+	}
+	switch h.Kind {
+	case EvidenceMapping:
+		return phrase + column
+	case EvidenceValue:
+		return fmt.Sprintf("%s%s %s %s", phrase, column, h.Operator, h.Value)
+	case EvidenceFormula:
+		return fmt.Sprintf("%q = %s", h.Phrase, h.Expression)
+	}
+	return h.Text
+}
+
+// injectEvidenceHints adds each column hint under its table in a compact schema prompt
+// ("Table name ..." blocks), and the rest as an evidence section after the schema.
+func injectEvidenceHints(contextPrompt string, hints []EvidenceHint) string {
+	byTable := make(map[string][]string)
+	var general []string
+	for _, h := range hints {
+		line := formatEvidenceHint(h)
+		if h.Table != "" && (h.Kind == EvidenceMapping || h.Kind == EvidenceValue) {
+			byTable[strings.ToLower(h.Table)] = append(byTable[strings.ToLower(h.Table)], line)
+			continue
+		}
+		general = append(general, line)
+	}
+
+	lines := strings.Split(contextPrompt, "\n")
+	out := make([]string, 0, len(lines)+len(hints)+4)
+	var pending []string // Hints of the current table block
+	flush := func() {
+		if len(pending) > 0 {
+			out = append(out, "  📌 Evidence (MUST follow):")
+			for _, line := range pending {
+				out = append(out, "    * "+line)
+			}
+			pending = nil
+		}
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Table ") {
+			flush()
+			if key := schemaTableKey(line, byTable); key != "" {
+				pending = byTable[key]
+				delete(byTable, key)
+			}
+		} else if !strings.HasPrefix(line, " ") {
+			flush()
+		}
+		out = append(out, line)
+	}
+	flush()
+
+	// Tables the schema prompt does not show
+	for _, h := range hints {
+		key := strings.ToLower(h.Table)
+		if rest, ok := byTable[key]; ok {
+			general = append(general, rest...)
+			delete(byTable, key)
+		}
+	}
+
+	result := strings.Join(out, "\n")
+	if len(general) > 0 {
+		var sb strings.Builder
+		sb.WriteString(strings.TrimRight(result, "\n"))
+		sb.WriteString("\n\nEvidence (MUST follow these definitions):\n")
+		for _, line := range general {
+			sb.WriteString("- " + line + "\n")
+		}
+		result = sb.String()
+	}
+	return result
+}
+
+// schemaTableKey byTable key of the table a "Table <name> (N rows):" / "Table <name>:" line starts
+// Table names may contain spaces, so the known names are matched rather than the line split.
+func schemaTableKey(line string, byTable map[string][]string) string {
+	lower := strings.ToLower(strings.TrimPrefix(line, "Table "))
+	for key := range byTable {
+		if rest, ok := strings.CutPrefix(lower, key); ok && (rest == "" || rest[0] == ' ' || rest[0] == ':') {
+			return key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// execRepairAttempts resolves the configured attempt count K (opt-in; 0 = off)
+func (p *Pipeline) execRepairAttempts() int {
+	if n := p.config.ExecRepairAttempts; n > 0 {
+		return n
+	}
+	return 0
+}
+
+// repairExecution executes the final SQL and feeds failures back to the LLM
+// A failure is an execution error, or zero rows when the question implies an answer exists.
+// Returns the first SQL that succeeds; when attempts run out, the last SQL that executed
+// without error is preferred over one that errors.
+func (p *Pipeline) repairExecution(ctx context.Context, query, contextPrompt, sql string, result *Result) string {
+	attempts := p.execRepairAttempts()
+
+	var bestSQL string
+	var bestResult *adapter.QueryResult
+
+	for attempt := 0; ; attempt++ {
+		execResult, execErr := p.queryAdapter.ExecuteQuery(ctx, sql)
+		result.SQLExecutions++
+
+		var problem string
+		switch {
+		case execErr != nil:
+			problem = fmt.Sprintf("Execution error: %v", execErr)
+		case len(execResult.Rows) == 0 && questionExpectsRows(query):
+			problem = "The query executed successfully but returned 0 rows, while the question implies a non-empty answer."
+			if bestResult == nil {
+				bestSQL, bestResult = sql, execResult
+			}
+		default:
+			result.ExecutionResult = execResult
+			return sql
+		}
+
+		if attempt >= attempts || ctx.Err() != nil {
+			if attempts > 0 {
+				p.Logger.Printf("⚠️  SQL still failing after %d repair attempts: %s\n", attempts, problem)
+			}
+			if execErr != nil && bestResult != nil {
+				result.ExecutionResult = bestResult
+				return bestSQL
+			}
+			if execErr == nil {
+				result.ExecutionResult = execResult
+			}
+			return sql
+		}
+
+		p.Logger.Printf("🔧 Execution repair %d/%d: %s\n", attempt+1, attempts, problem)
+
+		prompt := p.buildExecRepairPrompt(query, contextPrompt, sql, problem)
+		response, err := p.llm.Call(ctx, prompt)
+		result.LLMCalls++
+		if err != nil {
+			p.Logger.Printf("⚠️  Execution repair LLM call failed: %v\n", err)
+			if execErr == nil {
+				result.ExecutionResult = execResult
+			}
+			return sql
+		}
+		p.promptTexts = append(p.promptTexts, prompt)
+		p.responseTexts = append(p.responseTexts, response)
+
+		repaired := p.extractSQL(response)
+		step := ReActStep{
+			Thought:     problem,
+			Action:      "exec_repair",
+			ActionInput: sql,
+			Observation: repaired,
+			Phase:       "exec_repair",
+		}
+		result.ReActSteps = append(result.ReActSteps, step)
+		p.notifyStep(step, "observation")
+
+		if strings.TrimSpace(repaired) == strings.TrimSpace(sql) {
+			p.Logger.Println("⚠️  Execution repair returned the same SQL, stopping")
+			if execErr == nil {
+				result.ExecutionResult = execResult
+			}
+			return sql
+		}
+		p.Logger.Printf("🔧 Repaired SQL: %s\n", repaired)
+		sql = repaired
+	}
+}
+
+// emptyAnswerHints phrases where an empty result is a legitimate answer
+var emptyAnswerHints = []string{
+	"whether", "is there", "are there", "if any", "if there", "does ", "did ", "do any", "has any", "have any",
+}
+
+// questionExpectsRows reports whether an empty result would contradict the question
+func questionExpectsRows(question string) bool {
+	q := strings.ToLower(question)
+	for _, hint := range emptyAnswerHints {
+		if strings.Contains(q, hint) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildExecRepairPrompt builds the prompt for fixing a failing or empty query
+func (p *Pipeline) buildExecRepairPrompt(query, contextPrompt, sql, problem string) string {
+	dialect := dialectDisplayName(p.generationDialect())
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("The following %s query was generated for a question but did not work.\n\n", dialect))
+	sb.WriteString(fmt.Sprintf("Question: %s\n\n", query))
+	sb.WriteString("## Database Schema\n")
+	sb.WriteString(contextPrompt)
+	sb.WriteString("\n\n## SQL\n")
+	sb.WriteString(sql)
+	sb.WriteString(fmt.Sprintf("\n\n## Problem\n%s\n\n", problem))
+	sb.WriteString("Fix the query:\n")
+	sb.WriteString("- For errors: check table/column names against the schema, quoting, and function names for this dialect\n")
+	sb.WriteString("- For empty results: check literal values (case, spelling, date/number formats), join conditions, and filters that are stricter than the question\n")
+	sb.WriteString(fmt.Sprintf("Output ONLY the corrected %s SQL after \"Final Answer:\".\n", dialect))
+	return sb.String()
+}
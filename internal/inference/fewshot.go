@@ -0,0 +1,250 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/tmc/langchaingo/embeddings"
+)
+
+// defaultFewShotK examples injected when Config.FewShotK is 0
+const defaultFewShotK = 3
+
+// defaultHashDim vector size of the local hashing embedder
+const defaultHashDim = 1024
+
+// FewShotExample (question, SQL) pair from a training set
+type FewShotExample struct {
+	DbID     string `json:"db_id"`
+	Question string `json:"question"`
+	Evidence string `json:"evidence,omitempty"`
+	SQL      string `json:"sql"`
+}
+
+// LoadFewShotExamples loads Spider (train_spider.json: query) or BIRD (train.json: SQL, evidence) training pairs
+func LoadFewShotExamples(path string) ([]FewShotExample, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read examples: %w", err)
+	}
+
+	var raw []struct {
+		DbID     string `json:"db_id"`
+		Question string `json:"question"`
+		Evidence string `json:"evidence"`
+		Query    string `json:"query"` // Spider
+		SQL      string `json:"SQL"`   // BIRD
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse examples: %w", err)
+	}
+
+	examples := make([]FewShotExample, 0, len(raw))
+	for _, r := range raw {
+		sql := r.SQL
+		if sql == "" {
+			sql = r.Query
+		}
+		if r.Question == "" || sql == "" {
+			continue
+		}
+		examples = append(examples, FewShotExample{
+			DbID:     r.DbID,
+			Question: strings.TrimSpace(r.Question),
+			Evidence: strings.TrimSpace(r.Evidence),
+			SQL:      strings.TrimSpace(sql),
+		})
+	}
+	if len(examples) == 0 {
+		return nil, fmt.Errorf("no (question, SQL) pairs in %s", path)
+	}
+	return examples, nil
+}
+
+// FewShotRetriever embedding index over training examples
+// Build once and share across pipelines (set Config.FewShot); embedding the pool is the expensive part.
+type FewShotRetriever struct {
+	examples []FewShotExample
+	vectors  [][]float32
+	embedder embeddings.Embedder
+}
+
+// NewFewShotRetriever embeds every example question (embedder nil = local HashEmbedder)
+func NewFewShotRetriever(ctx context.Context, examples []FewShotExample, embedder embeddings.Embedder) (*FewShotRetriever, error) {
+	if embedder == nil {
+		embedder = NewHashEmbedder(defaultHashDim)
+	}
+
+	texts := make([]string, len(examples))
+	for i, ex := range examples {
+		texts[i] = ex.Question
+	}
+	vectors, err := embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed examples: %w", err)
+	}
+	if len(vectors) != len(examples) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d examples", len(vectors), len(examples))
+	}
+	for _, v := range vectors {
+		normalizeVector(v)
+	}
+
+	return &FewShotRetriever{examples: examples, vectors: vectors, embedder: embedder}, nil
+}
+
+// Size returns the number of indexed examples
+func (r *FewShotRetriever) Size() int {
+	return len(r.examples)
+}
+
+// Retrieve returns the k examples most similar to the question (cosine similarity)
+// An example with the identical question is skipped so a dev question never retrieves itself.
+func (r *FewShotRetriever) Retrieve(ctx context.Context, question string, k int) ([]FewShotExample, error) {
+	query, err := r.embedder.EmbedQuery(ctx, question)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed question: %w", err)
+	}
+	normalizeVector(query)
+
+	type scored struct {
+		idx   int
+		score float32
+	}
+	target := strings.ToLower(strings.TrimSpace(question))
+	scores := make([]scored, 0, len(r.vectors))
+	for i, v := range r.vectors {
+		if len(v) != len(query) || strings.ToLower(r.examples[i].Question) == target {
+			continue
+		}
+		var dot float32
+		for j := range v {
+			dot += v[j] * query[j]
+		}
+		scores = append(scores, scored{i, dot})
+	}
+	sort.SliceStable(scores, func(a, b int) bool { return scores[a].score > scores[b].score })
+
+	if k > len(scores) {
+		k = len(scores)
+	}
+	result := make([]FewShotExample, k)
+	for i := 0; i < k; i++ {
+		result[i] = r.examples[scores[i].idx]
+	}
+	return result, nil
+}
+
+func normalizeVector(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+// formatFewShotPrompt renders retrieved examples for the generation prompt
+func formatFewShotPrompt(examples []FewShotExample) string {
+	if len(examples) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Similar Examples\n")
+	sb.WriteString("Solved questions from other databases. Reuse their SQL patterns, not their table or column names.\n\n")
+	for i, ex := range examples {
+		sb.WriteString(fmt.Sprintf("Example %d:\nQuestion: %s\n", i+1, ex.Question))
+		if ex.Evidence != "" {
+			sb.WriteString(fmt.Sprintf("Evidence: %s\n", ex.Evidence))
+		}
+		sb.WriteString(fmt.Sprintf("SQL: %s\n\n", ex.SQL))
+	}
+	return sb.String()
+}
+
+// ─── Local embedder ──────────────────────────────────────────────
+
+// HashEmbedder local embedder: feature-hashed word unigrams and bigrams
+// Needs no embedding API; used when no embedding model is configured.
+type HashEmbedder struct {
+	Dim int
+}
+
+// NewHashEmbedder creates hashing embedder (dim <= 0 = default 1024)
+func NewHashEmbedder(dim int) *HashEmbedder {
+	if dim <= 0 {
+		dim = defaultHashDim
+	}
+	return &HashEmbedder{Dim: dim}
+}
+
+// Model identifies the embedder (vectors of different dimensions are not comparable)
+func (e *HashEmbedder) Model() string {
+	return fmt.Sprintf("hash-%d", e.Dim)
+}
+
+// EmbedDocuments embeds each text
+func (e *HashEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+// EmbedQuery embeds a single text
+func (e *HashEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return e.embed(text), nil
+}
+
+// fewShotStopwords carry no signal about the SQL shape
+var fewShotStopwords = map[string]bool{
+	"the": true, "a": true, "an": true, "of": true, "is": true, "are": true, "was": true, "were": true,
+	"to": true, "in": true, "for": true, "and": true, "that": true, "with": true, "on": true, "by": true,
+	"be": true, "as": true, "it": true, "its": true, "this": true, "please": true, "all": true,
+}
+
+func (e *HashEmbedder) embed(text string) []float32 {
+	v := make([]float32, e.Dim)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var kept []string
+	for _, w := range words {
+		if !fewShotStopwords[w] {
+			kept = append(kept, w)
+		}
+	}
+	for i, w := range kept {
+		e.add(v, w, 1)
+		if i > 0 {
+			e.add(v, kept[i-1]+" "+w, 0.5)
+		}
+	}
+	normalizeVector(v)
+	return v
+}
+
+// add hashes a feature into the vector with a hash-derived sign (reduces collision bias)
+func (e *HashEmbedder) add(v []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	v[sum%uint64(e.Dim)] += weight
+}
@@ -0,0 +1,252 @@
+package inference
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FinalAnswer structured final answer (Config.JSONAnswer)
+type FinalAnswer struct {
+	SQL        string   `json:"sql"`
+	Tables     []string `json:"tables,omitempty"`
+	Confidence float64  `json:"confidence"` // Model's self-reported confidence, 0-1
+}
+
+// jsonAnswerFormat answer object shown in the prompt
+const jsonAnswerFormat = `{"sql": "<the SQL query>", "tables": ["<table>", ...], "confidence": <0.0-1.0>}`
+
+// extractAnswer parses the JSON answer when Config.JSONAnswer is set, falling back to text extraction
+func (p *Pipeline) extractAnswer(response string) string {
+	if thinking := extractThinking(response); thinking != "" {
+		p.thinking = thinking
+	}
+	if p.config.JSONAnswer {
+		if answer, ok := parseFinalAnswer(response); ok {
+			sql := CleanSQL(answer.SQL, p.generationDialect())
+			if !p.isGiveUpSQL(sql) {
+				answer.SQL = sql
+				p.finalAnswer = answer
+				return sql
+			}
+		}
+		p.Logger.Printf("⚠️  No usable JSON final answer, falling back to text extraction\n")
+	}
+	return p.extractSQL(response)
+}
+
+// extractThinking reasoning in a response: its Thought, or the text before the answer
+func extractThinking(response string) string {
+	if thought := extractThought(response); thought != "" {
+		return thought
+	}
+	cut := -1
+	for _, marker := range []string{"Final Answer:", "```", "{"} {
+		if idx := strings.Index(response, marker); idx >= 0 && (cut < 0 || idx < cut) {
+			cut = idx
+		}
+	}
+	if cut < 0 {
+		return ""
+	}
+	return strings.TrimSpace(response[:cut])
+}
+
+// sqlFieldPattern "sql" string member, for objects that fail to parse as a whole
+var sqlFieldPattern = regexp.MustCompile(`"(?i:sql|query)"\s*:\s*"((?:\\.|[^"\\])*)"`)
+
+// parseFinalAnswer tolerant decoder: finds the first JSON object with a "sql" member
+// Accepts surrounding prose and code fences, raw newlines inside strings, trailing commas,
+// and tables/confidence given as strings.
+func parseFinalAnswer(text string) (*FinalAnswer, bool) {
+	for _, raw := range LooseJSONObjects(text) {
+		if answer := decodeFinalAnswer(raw); answer.SQL != "" {
+			return answer, true
+		}
+	}
+
+	// Last resort: the "sql" member alone (e.g. a truncated object)
+	if m := sqlFieldPattern.FindStringSubmatch(text); m != nil {
+		var sql string
+		if err := json.Unmarshal([]byte(`"`+repairJSON(m[1])+`"`), &sql); err == nil && strings.TrimSpace(sql) != "" {
+			return &FinalAnswer{SQL: sql}, true
+		}
+	}
+	return nil, false
+}
+
+// LooseJSONObjects the JSON objects in model output, in order of appearance
+// Tolerates surrounding prose and code fences, raw newlines inside strings and trailing
+// commas; spans that still fail to parse are skipped.
+func LooseJSONObjects(text string) []map[string]interface{} {
+	var objects []map[string]interface{}
+	for _, candidate := range jsonObjectCandidates(text) {
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(repairJSON(candidate)), &raw); err == nil {
+			objects = append(objects, raw)
+		}
+	}
+	return objects
+}
+
+// jsonObjectCandidates balanced {...} spans in order of appearance (outermost only)
+func jsonObjectCandidates(text string) []string {
+	var spans []string
+	for start := 0; start < len(text); {
+		open := strings.IndexByte(text[start:], '{')
+		if open < 0 {
+			break
+		}
+		open += start
+		end := matchingBrace(text, open)
+		if end < 0 {
+			break
+		}
+		spans = append(spans, text[open:end+1])
+		start = end + 1
+	}
+	return spans
+}
+
+// matchingBrace returns the index of the brace closing text[open], skipping string contents
+func matchingBrace(text string, open int) int {
+	depth := 0
+	inString := false
+	for i := open; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// repairJSON escapes raw control characters inside strings and drops trailing commas
+func repairJSON(s string) string {
+	var sb strings.Builder
+	inString := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch c {
+			case '\\':
+				sb.WriteByte(c)
+				if i+1 < len(s) {
+					i++
+					sb.WriteByte(s[i])
+				}
+				continue
+			case '"':
+				inString = false
+			case '\n':
+				sb.WriteString(`\n`)
+				continue
+			case '\r':
+				sb.WriteString(`\r`)
+				continue
+			case '\t':
+				sb.WriteString(`\t`)
+				continue
+			}
+			sb.WriteByte(c)
+			continue
+		}
+
+		if c == '"' {
+			inString = true
+		}
+		if c == ',' {
+			// Trailing comma before } or ]
+			j := i + 1
+			for j < len(s) && strings.IndexByte(" \t\r\n", s[j]) >= 0 {
+				j++
+			}
+			if j < len(s) && (s[j] == '}' || s[j] == ']') {
+				continue
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// decodeFinalAnswer reads the members by case-insensitive name, coercing loose types
+func decodeFinalAnswer(raw map[string]interface{}) *FinalAnswer {
+	answer := &FinalAnswer{}
+	for key, value := range raw {
+		switch strings.ToLower(key) {
+		case "sql", "query":
+			if s, ok := value.(string); ok {
+				answer.SQL = strings.TrimSpace(s)
+			}
+		case "tables":
+			switch v := value.(type) {
+			case []interface{}:
+				for _, t := range v {
+					if s, ok := t.(string); ok && strings.TrimSpace(s) != "" {
+						answer.Tables = append(answer.Tables, strings.TrimSpace(s))
+					}
+				}
+			case string:
+				for _, t := range strings.Split(v, ",") {
+					if t = strings.TrimSpace(t); t != "" {
+						answer.Tables = append(answer.Tables, t)
+					}
+				}
+			}
+		case "confidence":
+			answer.Confidence = parseConfidence(value)
+		}
+	}
+	return answer
+}
+
+// parseConfidence accepts 0-1 numbers, percentages and numeric strings
+func parseConfidence(value interface{}) float64 {
+	var c float64
+	switch v := value.(type) {
+	case float64:
+		c = v
+	case string:
+		s := strings.TrimSpace(v)
+		percent := strings.HasSuffix(s, "%")
+		f, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		if err != nil {
+			return 0
+		}
+		c = f
+		if percent {
+			c /= 100
+		}
+	default:
+		return 0
+	}
+	if c > 1 && c <= 100 {
+		c /= 100
+	}
+	if c < 0 {
+		return 0
+	}
+	if c > 1 {
+		return 1
+	}
+	return c
+}
@@ -0,0 +1,112 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LinkCache schema-linking results keyed by (database, normalized question)
+// Share one cache across pipelines (set Config.LinkCache) so repeated questions, retries and
+// re-runs in the same process skip the linking call. Safe for concurrent use.
+type LinkCache struct {
+	mu      sync.Mutex
+	entries map[string]*SchemaLinkResult
+	hits    int
+	misses  int
+}
+
+// NewLinkCache creates an empty cache
+func NewLinkCache() *LinkCache {
+	return &LinkCache{entries: make(map[string]*SchemaLinkResult)}
+}
+
+// Stats returns lookups answered from the cache and lookups that ran the linker
+func (c *LinkCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Len returns the number of cached results
+func (c *LinkCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *LinkCache) get(key string) (*SchemaLinkResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res, ok := c.entries[key]
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return res, ok
+}
+
+func (c *LinkCache) put(key string, res *SchemaLinkResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = res
+}
+
+// linkCacheKey database, linker settings, schema fingerprint and normalized question
+// The schema fingerprint keeps a result from outliving a context edit (persisted proofread).
+func (p *Pipeline) linkCacheKey(query string, allTables map[string]*TableInfo, fullRCPrompt string) string {
+	h := fnv.New64a()
+	h.Write([]byte(fullRCPrompt))
+	names := make([]string, 0, len(allTables))
+	for _, info := range allTables {
+		names = append(names, info.Name+"("+strings.Join(info.Columns, ",")+")")
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Write([]byte(name))
+	}
+
+	question := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	question = strings.TrimRight(question, "?.! ")
+	return fmt.Sprintf("%s|react=%t|columns=%t|%x|%s",
+		p.config.DBName, p.config.ReactLinking, p.config.ColumnLinking, h.Sum64(), question)
+}
+
+// linkSchema runs the schema linker, or reuses its result from Config.LinkCache
+func (p *Pipeline) linkSchema(ctx context.Context, query string, allTables map[string]*TableInfo, fullRCPrompt string) (*SchemaLinkResult, bool, error) {
+	cache := p.config.LinkCache
+	if cache == nil {
+		res, err := p.schemaLinker.Link(ctx, query, allTables, fullRCPrompt)
+		return res, false, err
+	}
+
+	key := p.linkCacheKey(query, allTables, fullRCPrompt)
+	if cached, ok := cache.get(key); ok {
+		p.Logger.Printf("♻️  Schema linking reused from cache: %v\n", cached.Tables)
+		return cloneLinkResult(cached), true, nil
+	}
+	res, err := p.schemaLinker.Link(ctx, query, allTables, fullRCPrompt)
+	if err != nil {
+		return nil, false, err
+	}
+	cache.put(key, cloneLinkResult(res))
+	return res, false, nil
+}
+
+// cloneLinkResult copies the slices and map so callers cannot change a cached result
+func cloneLinkResult(res *SchemaLinkResult) *SchemaLinkResult {
+	out := *res
+	out.Tables = append([]string(nil), res.Tables...)
+	out.Steps = append([]ReActStep(nil), res.Steps...)
+	if res.Columns != nil {
+		out.Columns = make(map[string][]string, len(res.Columns))
+		for table, cols := range res.Columns {
+			out.Columns[table] = append([]string(nil), cols...)
+		}
+	}
+	return &out
+}
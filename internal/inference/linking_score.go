@@ -0,0 +1,374 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// LinkingScore schema linking quality against the tables and columns a gold SQL references
+type LinkingScore struct {
+	GoldTables     []string
+	GoldColumns    map[string][]string // Gold table -> referenced columns
+	TablePrecision float64             // Selected tables that the gold SQL uses
+	TableRecall    float64             // Gold tables that were selected
+
+	// Column metrics, set when the linker selected columns (Config.ColumnLinking)
+	ColumnScored    bool
+	ColumnPrecision float64
+	ColumnRecall    float64
+}
+
+// ScoreLinking compares the linker's selection with the gold SQL's references
+// Gold references are resolved against the database (CTE names, aliases and unknown names are ignored).
+// columns is Result.SelectedColumns; nil skips the column metrics.
+func ScoreLinking(ctx context.Context, db adapter.DBAdapter, goldSQL string, tables []string, columns map[string][]string) (*LinkingScore, error) {
+	goldTables, goldColumns, err := GoldReferences(ctx, db, goldSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	score := &LinkingScore{GoldTables: goldTables, GoldColumns: goldColumns}
+	score.TablePrecision, score.TableRecall = precisionRecall(lowerSet(tables), lowerSet(goldTables))
+
+	if columns != nil {
+		selected := make(map[string]bool)
+		for table, cols := range columns {
+			for _, col := range cols {
+				selected[strings.ToLower(table+"."+col)] = true
+			}
+		}
+		gold := make(map[string]bool)
+		for table, cols := range goldColumns {
+			for _, col := range cols {
+				gold[strings.ToLower(table+"."+col)] = true
+			}
+		}
+		score.ColumnScored = true
+		score.ColumnPrecision, score.ColumnRecall = precisionRecall(selected, gold)
+	}
+	return score, nil
+}
+
+// GoldReferences tables and columns referenced by a SQL query, in the database's names
+func GoldReferences(ctx context.Context, db adapter.DBAdapter, sql string) ([]string, map[string][]string, error) {
+	usage, err := AnalyzeGoldSQL(ctx, db, sql)
+	if err != nil {
+		return nil, nil, err
+	}
+	return usage.Tables, usage.Columns, nil
+}
+
+// GoldUsage what a SQL query needs from the schema, in the database's names
+type GoldUsage struct {
+	Tables  []string
+	Columns map[string][]string // Table -> referenced columns (sorted)
+	Values  []GoldValue         // String literals compared with a column
+	Joins   []GoldJoin          // Equalities between columns of two tables
+}
+
+// GoldValue string literal a query compares a column with (LIKE wildcards at the ends stripped)
+type GoldValue struct {
+	Table  string
+	Column string
+	Value  string
+}
+
+// GoldJoin equality between columns of two tables (ON / WHERE a.x = b.y)
+type GoldJoin struct {
+	Table     string
+	Column    string
+	RefTable  string
+	RefColumn string
+}
+
+// goldColumn column resolved against the database
+type goldColumn struct {
+	table  string
+	column string
+}
+
+// columnScope resolves column references of a tokenized query against its FROM tables
+type columnScope struct {
+	toks         []sqlToken
+	refs         tableRefs
+	tableNames   map[string]string            // lower -> table name
+	tableColumns map[string]map[string]string // table -> lower column -> name
+}
+
+// columnAt resolves the column reference starting at token i (width 0 = none)
+func (s *columnScope) columnAt(i int) ([]goldColumn, int) {
+	toks := s.toks
+	if s.refs.skip[i] {
+		return nil, 0
+	}
+	name, ok := identText(toks[i])
+	if !ok || (i > 0 && toks[i-1].kind == tokOp && toks[i-1].text == ".") {
+		return nil, 0
+	}
+	next := toks[i+1]
+	switch {
+	case next.kind == tokOp && next.text == ".":
+		// qualifier.column
+		col, ok := identText(toks[i+2])
+		if !ok {
+			return nil, 0
+		}
+		qualifier := strings.ToLower(name)
+		table, ok := s.refs.aliases[qualifier]
+		if !ok {
+			table = s.tableNames[qualifier]
+		}
+		if resolved, ok := s.tableColumns[table][strings.ToLower(col)]; ok {
+			return []goldColumn{{table, resolved}}, 3
+		}
+		return nil, 3
+	case next.kind == tokOp && next.text == "(":
+		// Function call
+		return nil, 0
+	}
+	// Bare column: valid SQL only leaves a name unqualified when it is unambiguous in its
+	// scope, so a name several referenced tables have comes from different subqueries
+	var cols []goldColumn
+	for _, table := range s.refs.tables {
+		if resolved, ok := s.tableColumns[table][strings.ToLower(name)]; ok {
+			cols = append(cols, goldColumn{table, resolved})
+		}
+	}
+	return cols, 1
+}
+
+// AnalyzeGoldSQL resolves a SQL query's tables, columns, compared values and joins against the database
+// CTE names, aliases and unknown names are ignored; values and joins are only taken from
+// column references that resolve to exactly one table.
+func AnalyzeGoldSQL(ctx context.Context, db adapter.DBAdapter, sql string) (*GoldUsage, error) {
+	dialect := NormalizeDialect(db.GetDatabaseType())
+	toks, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize gold SQL: %w", err)
+	}
+	dbTables, err := db.GetTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	tableNames := make(map[string]string, len(dbTables)) // lower -> database name
+	for _, t := range dbTables {
+		tableNames[strings.ToLower(t)] = t
+	}
+
+	refs := scanTableRefs(toks, tableNames)
+
+	// Columns of the referenced tables, for resolving qualified and bare names
+	tableColumns := make(map[string]map[string]string, len(refs.tables)) // table -> lower column -> name
+	for _, table := range refs.tables {
+		cols, err := db.GetColumns(ctx, table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get columns of %s: %w", table, err)
+		}
+		byName := make(map[string]string, len(cols))
+		for _, c := range cols {
+			byName[strings.ToLower(c.Name)] = c.Name
+		}
+		tableColumns[table] = byName
+	}
+
+	scope := &columnScope{toks: toks, refs: refs, tableNames: tableNames, tableColumns: tableColumns}
+	usage := &GoldUsage{Tables: refs.tables, Columns: make(map[string][]string)}
+	seen := make(map[string]bool)
+	for i := 0; i < len(toks); i++ {
+		cols, width := scope.columnAt(i)
+		if width == 0 {
+			continue
+		}
+		for _, col := range cols {
+			if key := col.table + "." + col.column; !seen[key] {
+				seen[key] = true
+				usage.Columns[col.table] = append(usage.Columns[col.table], col.column)
+			}
+		}
+
+		if len(cols) == 1 {
+			j := i + width
+			for _, value := range comparedLiterals(toks, j, dialect) {
+				if key := "value:" + cols[0].table + "." + cols[0].column + "=" + value; !seen[key] {
+					seen[key] = true
+					usage.Values = append(usage.Values, GoldValue{Table: cols[0].table, Column: cols[0].column, Value: value})
+				}
+			}
+			if toks[j].kind == tokOp && (toks[j].text == "=" || toks[j].text == "==") {
+				if right, _ := scope.columnAt(j + 1); len(right) == 1 && right[0].table != cols[0].table {
+					if key := "join:" + cols[0].table + "." + cols[0].column + "=" + right[0].table + "." + right[0].column; !seen[key] {
+						seen[key] = true
+						usage.Joins = append(usage.Joins, GoldJoin{
+							Table: cols[0].table, Column: cols[0].column,
+							RefTable: right[0].table, RefColumn: right[0].column,
+						})
+					}
+				}
+			}
+		}
+		i += width - 1
+	}
+
+	for _, cols := range usage.Columns {
+		sort.Strings(cols)
+	}
+	return usage, nil
+}
+
+// comparedLiterals string literals compared with the expression ending before token i
+// LIKE / GLOB patterns have their wildcards at the ends stripped.
+func comparedLiterals(toks []sqlToken, i int, dialect string) []string {
+	idx, pattern := comparedLiteralTokens(toks, i)
+	var values []string
+	for _, j := range idx {
+		value := unquoteString(toks[j].text, dialect)
+		if pattern {
+			if value = strings.Trim(value, "%*"); value == "" {
+				continue
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// comparedLiteralTokens indexes of the string literals compared with the expression ending
+// before token i; pattern is set for LIKE / GLOB
+// Handles =, !=, <>, [NOT] LIKE / GLOB and [NOT] IN (...). Double-quoted names count as
+// strings here, since SQLite falls back to that when no column has the name.
+func comparedLiteralTokens(toks []sqlToken, i int) (idx []int, pattern bool) {
+	literal := func(tok sqlToken) bool {
+		return tok.kind == tokString || (tok.kind == tokQuoted && tok.text[0] == '"')
+	}
+
+	if toks[i].kind == tokWord && toks[i].upper == "NOT" {
+		i++
+	}
+	switch {
+	case toks[i].kind == tokOp && (toks[i].text == "=" || toks[i].text == "==" || toks[i].text == "!=" || toks[i].text == "<>"):
+		if literal(toks[i+1]) {
+			return []int{i + 1}, false
+		}
+	case toks[i].kind == tokWord && (toks[i].upper == "LIKE" || toks[i].upper == "GLOB"):
+		if literal(toks[i+1]) {
+			return []int{i + 1}, true
+		}
+	case toks[i].kind == tokWord && toks[i].upper == "IN" && toks[i+1].kind == tokOp && toks[i+1].text == "(":
+		for j := i + 2; j < len(toks); j += 2 {
+			if !literal(toks[j]) {
+				break
+			}
+			idx = append(idx, j)
+			if toks[j+1].kind != tokOp || toks[j+1].text != "," {
+				break
+			}
+		}
+	}
+	return idx, false
+}
+
+// tableRefs tables named in FROM / JOIN clauses
+type tableRefs struct {
+	tables  []string          // Database names, in order of appearance
+	aliases map[string]string // lower alias -> table
+	skip    map[int]bool      // Token indexes of table names and aliases
+}
+
+// scanTableRefs collects the database tables after FROM, JOIN and commas in a FROM list
+func scanTableRefs(toks []sqlToken, tableNames map[string]string) tableRefs {
+	refs := tableRefs{aliases: make(map[string]string), skip: make(map[int]bool)}
+	added := make(map[string]bool)
+
+	for i := 0; i < len(toks); i++ {
+		if toks[i].kind != tokWord || (toks[i].upper != "FROM" && toks[i].upper != "JOIN") {
+			continue
+		}
+		for j := i + 1; j < len(toks); {
+			name, ok := identText(toks[j])
+			if !ok {
+				break // Subquery or end of clause
+			}
+			// schema.table: keep the last part
+			for toks[j+1].kind == tokOp && toks[j+1].text == "." {
+				if part, ok := identText(toks[j+2]); ok {
+					refs.skip[j] = true
+					name = part
+					j += 2
+					continue
+				}
+				break
+			}
+			refs.skip[j] = true
+			j++
+
+			table, isTable := tableNames[strings.ToLower(name)]
+			if isTable && !added[table] {
+				added[table] = true
+				refs.tables = append(refs.tables, table)
+			}
+
+			// [AS] alias
+			if toks[j].kind == tokWord && toks[j].upper == "AS" {
+				j++
+			}
+			if alias, ok := identText(toks[j]); ok {
+				if isTable {
+					refs.aliases[strings.ToLower(alias)] = table
+				}
+				refs.skip[j] = true
+				j++
+			}
+
+			if toks[j].kind != tokOp || toks[j].text != "," {
+				break
+			}
+			j++
+		}
+	}
+	return refs
+}
+
+// identText name of an identifier token (bare non-reserved word or quoted identifier)
+func identText(tok sqlToken) (string, bool) {
+	switch tok.kind {
+	case tokWord:
+		if reservedWords[tok.upper] {
+			return "", false
+		}
+		return tok.text, true
+	case tokQuoted:
+		return unquoteIdent(tok.text), true
+	}
+	return "", false
+}
+
+// lowerSet case-insensitive set of names
+func lowerSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[strings.ToLower(n)] = true
+	}
+	return set
+}
+
+// precisionRecall of a selection against a gold set (empty gold = full recall)
+func precisionRecall(selected, gold map[string]bool) (float64, float64) {
+	hits := 0
+	for name := range selected {
+		if gold[name] {
+			hits++
+		}
+	}
+	precision, recall := 0.0, 1.0
+	if len(selected) > 0 {
+		precision = float64(hits) / float64(len(selected))
+	}
+	if len(gold) > 0 {
+		recall = float64(hits) / float64(len(gold))
+	}
+	return precision, recall
+}
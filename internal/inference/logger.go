@@ -12,13 +12,20 @@ import (
 type InferenceLogger struct {
 	mu      sync.Mutex
 	file    *os.File
+	out     io.Writer // Console destination (stdout by default)
 	writers []io.Writer
 }
 
 // NewInferenceLogger creates a logger that writes to stdout only.
 func NewInferenceLogger() *InferenceLogger {
+	return NewInferenceLoggerTo(os.Stdout)
+}
+
+// NewInferenceLoggerTo creates a logger that writes to w instead of stdout (io.Discard = silent).
+func NewInferenceLoggerTo(w io.Writer) *InferenceLogger {
 	return &InferenceLogger{
-		writers: []io.Writer{os.Stdout},
+		out:     w,
+		writers: []io.Writer{w},
 	}
 }
 
@@ -29,9 +36,9 @@ func (l *InferenceLogger) SetFile(f *os.File) {
 	defer l.mu.Unlock()
 	l.file = f
 	if f != nil {
-		l.writers = []io.Writer{os.Stdout, f}
+		l.writers = []io.Writer{l.out, f}
 	} else {
-		l.writers = []io.Writer{os.Stdout}
+		l.writers = []io.Writer{l.out}
 	}
 }
 
@@ -44,7 +51,7 @@ func (l *InferenceLogger) CloseFile() {
 		l.file.Close()
 		l.file = nil
 	}
-	l.writers = []io.Writer{os.Stdout}
+	l.writers = []io.Writer{l.out}
 }
 
 // Printf writes formatted output to all destinations (stdout + file).
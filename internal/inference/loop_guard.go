@@ -0,0 +1,133 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// defaultLoopInterventions interventions before a stuck ReAct loop is cut short
+const defaultLoopInterventions = 2
+
+// maxRepeatedObservation observation length quoted back in an intervention
+const maxRepeatedObservation = 300
+
+// errReActStuck the agent kept repeating tool calls after the interventions ran out
+var errReActStuck = errors.New("react loop stuck repeating tool calls")
+
+// loopGuard detects repeated or oscillating tool calls within one ReAct loop
+// A call with the same tool and (normalized) input as an earlier one is not run again;
+// the agent gets an intervention observation instead. Past the limit the loop is ended
+// with errReActStuck so the best answer can be forced rather than burning iterations.
+type loopGuard struct {
+	dialect string
+	limit   int // Interventions allowed (<0 = detection off)
+	logger  *InferenceLogger
+
+	history       []string          // Call keys in order
+	observations  map[string]string // Observation of each call key's first run
+	Interventions int
+}
+
+// reset clears the call history for a new query
+func (g *loopGuard) reset(dialect string, limit int, logger *InferenceLogger) {
+	g.dialect, g.limit, g.logger = dialect, limit, logger
+	g.history = nil
+	g.observations = map[string]string{}
+	g.Interventions = 0
+}
+
+// guard wraps the tools so their calls go through the guard
+func (g *loopGuard) guard(toolsList []tools.Tool) []tools.Tool {
+	guarded := make([]tools.Tool, len(toolsList))
+	for i, tool := range toolsList {
+		guarded[i] = &guardedTool{Tool: tool, guard: g}
+	}
+	return guarded
+}
+
+// callKey identifies a tool call: tool name + input with case and spacing normalized
+func (g *loopGuard) callKey(name, input string) string {
+	input = strings.TrimSpace(input)
+	if sql := CleanSQL(input, g.dialect); sql != "" {
+		if toks, err := tokenizeSQL(sql, NormalizeDialect(g.dialect)); err == nil {
+			parts := make([]string, 0, len(toks))
+			for _, tok := range toks {
+				if tok.kind == tokEOF || isSQLOp(tok, ";") {
+					continue
+				}
+				if tok.kind == tokWord {
+					parts = append(parts, tok.upper)
+				} else {
+					parts = append(parts, tok.text)
+				}
+			}
+			return name + "\x00" + strings.Join(parts, " ")
+		}
+	}
+	return name + "\x00" + strings.Join(strings.Fields(input), " ")
+}
+
+// intervention the observation replacing a repeated call ("" = not a repeat)
+func (g *loopGuard) intervention(name, key string) string {
+	last := len(g.history) - 1
+	previous, seen := g.observations[key]
+	if !seen {
+		return ""
+	}
+	if len(previous) > maxRepeatedObservation {
+		previous = previous[:maxRepeatedObservation] + "..."
+	}
+
+	var sb strings.Builder
+	switch {
+	case last >= 0 && g.history[last] == key:
+		sb.WriteString(fmt.Sprintf("Repeated action: you just called %s with this same input.", name))
+	case last >= 1 && g.history[last-1] == key:
+		sb.WriteString(fmt.Sprintf("Oscillation: you are alternating between the same two actions and are back at %s with an input you already tried.", name))
+	default:
+		sb.WriteString(fmt.Sprintf("Repeated action: you already called %s with this same input earlier.", name))
+	}
+	sb.WriteString(" It was not run again; the result will not change.\n")
+	sb.WriteString("Previous observation: " + previous + "\n")
+	sb.WriteString("Change your approach (different tables, joins, filters or tool) or give your Final Answer now with the best SQL you have verified.")
+	return sb.String()
+}
+
+// guardedTool a ReAct tool whose repeated calls are intercepted by a loopGuard
+type guardedTool struct {
+	tools.Tool
+	guard *loopGuard
+}
+
+// Call runs the wrapped tool unless the call repeats an earlier one
+func (t *guardedTool) Call(ctx context.Context, input string) (string, error) {
+	g := t.guard
+	if g.limit < 0 {
+		return t.Tool.Call(ctx, input)
+	}
+
+	name := t.Tool.Name()
+	key := g.callKey(name, input)
+	message := g.intervention(name, key)
+	g.history = append(g.history, key)
+
+	if message == "" {
+		observation, err := t.Tool.Call(ctx, input)
+		if err == nil {
+			g.observations[key] = observation
+		}
+		return observation, err
+	}
+
+	g.Interventions++
+	if g.Interventions > g.limit {
+		g.logger.Printf("\n🔁 Tool call [%s] repeated after %d interventions, ending the loop\n", name, g.limit)
+		return "", errReActStuck
+	}
+	g.logger.Printf("\n🔁 Tool call [%s] repeats an earlier call, intervening (%d/%d)\n", name, g.Interventions, g.limit)
+	return message, nil
+}
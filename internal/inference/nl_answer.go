@@ -0,0 +1,77 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// Result rows shown to the answer prompt
+const (
+	maxAnswerRows  = 50
+	maxAnswerChars = 4000
+)
+
+// answerQuestion turns the executed SQL's rows into a short natural-language answer (Config.NLAnswer)
+// Returns "" when the SQL did not execute; the SQL itself stays in Result.GeneratedSQL.
+func (p *Pipeline) answerQuestion(ctx context.Context, query, sql string, result *Result) string {
+	rows, ok := result.ExecutionResult.(*adapter.QueryResult)
+	if !ok || rows == nil {
+		p.Logger.Println("⚠️  No execution result, skipping the natural-language answer")
+		return ""
+	}
+
+	prompt := buildAnswerPrompt(query, sql, rows)
+	response, err := p.llm.Call(ctx, prompt)
+	result.LLMCalls++
+	if err != nil {
+		p.Logger.Printf("⚠️  Natural-language answer failed: %v\n", err)
+		return ""
+	}
+	p.promptTexts = append(p.promptTexts, prompt)
+	p.responseTexts = append(p.responseTexts, response)
+
+	answer := strings.TrimSpace(response)
+	if idx := strings.Index(answer, "Answer:"); idx >= 0 {
+		answer = strings.TrimSpace(answer[idx+len("Answer:"):])
+	}
+	p.Logger.Printf("💬 Answer: %s\n", answer)
+	return answer
+}
+
+// buildAnswerPrompt question, SQL and result rows, asking for an answer grounded in the rows
+func buildAnswerPrompt(query, sql string, rows *adapter.QueryResult) string {
+	var sb strings.Builder
+	sb.WriteString("Answer the user's question using only the result of the SQL query below.\n\n")
+	sb.WriteString(fmt.Sprintf("Question: %s\n\n", query))
+	sb.WriteString(fmt.Sprintf("SQL:\n%s\n\n", sql))
+
+	total := len(rows.Values)
+	sb.WriteString(fmt.Sprintf("Result (%d rows", total))
+	if total > maxAnswerRows {
+		sb.WriteString(fmt.Sprintf(", first %d shown", maxAnswerRows))
+	}
+	sb.WriteString("):\n")
+	if total == 0 {
+		sb.WriteString("(no rows)\n")
+	} else {
+		formatted := adapter.FormatRows(rows.Columns, rows.Values, maxAnswerRows)
+		if len(formatted) > maxAnswerChars {
+			formatted = formatted[:maxAnswerChars] + "\n... (truncated)"
+		}
+		sb.WriteString(formatted + "\n")
+	}
+
+	sb.WriteString(`
+Rules:
+1. Answer in 1-3 plain sentences, in the language of the question
+2. Use only values from the result; never invent or estimate numbers
+3. If there are no rows, say that no matching data was found
+4. For long lists, give the count and a few examples
+5. Do not mention SQL, tables or columns
+
+Answer:`)
+	return sb.String()
+}
@@ -0,0 +1,110 @@
+package inference
+
+import (
+	"context"
+	"time"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// Pipeline phases reported in Result.Phases
+// Clarify and proofread are tool time spent inside generation (already included in its duration).
+const (
+	PhaseFollowUp      = "follow_up"
+	PhaseSchemaLinking = "schema_linking"
+	PhaseDecomposition = "decomposition"
+	PhaseGeneration    = "generation"
+	PhaseSyntaxRepair  = "syntax_repair"
+	PhaseExecRepair    = "exec_repair"
+	PhaseAnswer        = "answer"
+	PhaseClarify       = "clarify"
+	PhaseProofread     = "proofread"
+)
+
+// PhaseStats time and cost of one pipeline phase
+type PhaseStats struct {
+	Duration time.Duration
+	Calls    int // Tool calls (clarify / proofread only)
+	LLMCalls int
+	Tokens   int // Prompt + response tokens (~4 chars per token without a tokenizer)
+}
+
+// phaseMark counters at the start of a phase
+type phaseMark struct {
+	start       time.Time
+	llmCalls    int
+	exchanges   int
+	reactTokens int
+}
+
+// beginPhase marks the start of a phase
+func (p *Pipeline) beginPhase(result *Result) phaseMark {
+	return phaseMark{
+		start:       time.Now(),
+		llmCalls:    result.LLMCalls,
+		exchanges:   len(p.promptTexts),
+		reactTokens: p.reactTokens,
+	}
+}
+
+// endPhase adds the time, LLM calls and tokens since mark to result.Phases[name]
+func (p *Pipeline) endPhase(result *Result, name string, mark phaseMark) {
+	tokens := p.reactTokens - mark.reactTokens
+	for i := mark.exchanges; i < len(p.promptTexts); i++ {
+		tokens += p.estimateTokens(p.promptTexts[i])
+		if i < len(p.responseTexts) {
+			tokens += p.estimateTokens(p.responseTexts[i])
+		}
+	}
+	result.addPhase(name, PhaseStats{
+		Duration: time.Since(mark.start),
+		LLMCalls: result.LLMCalls - mark.llmCalls,
+		Tokens:   tokens,
+	})
+}
+
+// addPhase accumulates stats for a phase (phases may run more than once)
+func (r *Result) addPhase(name string, stats PhaseStats) {
+	if r.Phases == nil {
+		r.Phases = make(map[string]*PhaseStats)
+	}
+	ps, ok := r.Phases[name]
+	if !ok {
+		ps = &PhaseStats{}
+		r.Phases[name] = ps
+	}
+	ps.Duration += stats.Duration
+	ps.Calls += stats.Calls
+	ps.LLMCalls += stats.LLMCalls
+	ps.Tokens += stats.Tokens
+}
+
+// recordReActCost adds the ReAct iterations' tokens and the clarify / proofread tool time
+func (p *Pipeline) recordReActCost(handler *PrettyReActHandler, result *Result, clarify, proofread *timedTool) {
+	for _, text := range handler.LLMTexts() {
+		p.reactTokens += p.estimateTokens(text)
+	}
+	if clarify.calls > 0 {
+		result.addPhase(PhaseClarify, PhaseStats{Duration: clarify.elapsed, Calls: clarify.calls})
+	}
+	if proofread.calls > 0 {
+		result.addPhase(PhaseProofread, PhaseStats{Duration: proofread.elapsed, Calls: proofread.calls})
+	}
+}
+
+// timedTool measures the calls of a wrapped tool (clarify / proofread time inside the ReAct loop)
+type timedTool struct {
+	tools.Tool
+	calls   int
+	elapsed time.Duration
+}
+
+// Call runs the wrapped tool
+func (t *timedTool) Call(ctx context.Context, input string) (string, error) {
+	start := time.Now()
+	defer func() {
+		t.calls++
+		t.elapsed += time.Since(start)
+	}()
+	return t.Tool.Call(ctx, input)
+}
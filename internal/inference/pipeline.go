@@ -2,14 +2,14 @@ package inference
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/pkoukk/tiktoken-go"
+	"github.com/tmc/langchaingo/embeddings"
 	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/tools"
 
 	"reactsql/internal/adapter"
 	contextpkg "reactsql/internal/context"
@@ -17,23 +17,91 @@ import (
 
 // Config inference pipeline configuration
 type Config struct {
-	UseRichContext bool
-	UseReact       bool
-	ReactLinking   bool // Whether Schema Linking uses ReAct mode
-	UseDryRun      bool
-	MaxIterations  int
-	ContextFile    string
+	UseRichContext   bool
+	UseReact         bool
+	ReactLinking     bool // Whether Schema Linking uses ReAct mode
+	ColumnLinking    bool // Schema Linking also selects columns; the schema prompt keeps only those (plus keys)
+	UseDryRun        bool
+	MaxIterations    int // ReAct loop model calls before a final answer is forced (0 = default 10)
+	ContextFile      string
+	LazyContext      bool // Parse only each table's index fields up front; full table metadata is decoded after schema linking picks the tables
+	SkipExpiredNotes bool // Leave expired Rich Context notes out of prompts (default: keep them, tagged [EXPIRED])
+	RefreshQuality   bool // Re-run the deterministic quality checks on the live database after loading the context
 
 	// Clarify feature config
-	ClarifyMode             string   // Clarify mode: "off" (off) | "on" (agent asks) | "force" (forced)
+	ClarifyMode             string   // Clarify mode: "off" (off) | "on" (agent asks, see SetClarifyBackend) | "force" (forced)
 	LogMode                 string   // Log mode: "simple" (simple) | "full" (full)
 	ResultFields            []string // Expected result field list
 	ResultFieldsDescription string   // Result field descriptions
 
 	// Proofread config
-	EnableProofread bool   // Enable proofread (allow LLM to fix Rich Context)
-	DBName          string // Database name
-	DBType          string // Database type
+	EnableProofread  bool   // Enable proofread (allow LLM to fix Rich Context)
+	PersistProofread bool   // Write proofread corrections back to the context file (with provenance)
+	DBName           string // Database name
+	DBType           string // Database type
+
+	// Dialect config
+	GenerationDialect string   // Dialect the LLM writes SQL in ("" = the database's); transpiled before execution
+	EmitDialects      []string // Extra dialects to transpile the final SQL to (Result.DialectSQL)
+
+	// SQL style config
+	Style SQLStyle // Style guide preferences, stated in the prompt and enforced on the final SQL (zero = none)
+
+	// Few-shot config
+	FewShot  *FewShotRetriever // Similar (question, SQL) examples injected into the prompt (nil = off)
+	FewShotK int               // Examples to inject (0 = default 3)
+
+	// Schema-linking cache config
+	LinkCache *LinkCache // Linking results shared across pipelines, keyed by database and question (nil = link every query)
+
+	// Value hint config
+	ValueHints bool // Match question phrases against the context's cell-value index and hint the columns
+
+	// Value canonicalization config
+	CanonicalValues bool // Rewrite literals compared with enumerated TEXT columns into the exact stored literal ("usa" → " USA")
+
+	// Decomposition config
+	Decompose bool // Break multi-hop questions into sub-questions, solve them, and compose the final SQL with CTEs
+
+	// Multi-candidate config
+	Candidates int // Generate N candidate SQLs and pick by execution consensus (<=1 = single)
+
+	// Prompt budget config
+	MaxPromptTokens int // Generation prompt limit; low-priority sections are dropped to fit (0 = unlimited)
+	MaxTableTokens  int // Per-table limit of the compact schema prompt; annotations, then non-key columns are trimmed (0 = unlimited)
+	MaxEnumValues   int // Enumerated values shown per column in the schema prompt (0 = default 8)
+
+	// Column pruning config
+	PruneSchemaTokens int                 // Drop columns least similar to the question (keys kept) when the schema prompt exceeds this (0 = off)
+	ColumnEmbedder    embeddings.Embedder // Column relevance embedder (nil = local HashEmbedder)
+
+	// Execution repair config
+	ExecRepairAttempts int // Repair attempts when the final SQL fails or returns no rows (0 = off)
+
+	// Answer format config
+	JSONAnswer bool // Ask for the final answer as {"sql", "tables", "confidence"} and parse it (text extraction as fallback)
+
+	// Natural-language answer config
+	NLAnswer bool // After execution, answer the question in prose from the result rows (Result.Answer)
+
+	// Time budget config
+	MaxDuration time.Duration // Wall-clock limit per question; then the best SQL so far is returned (0 = unlimited)
+
+	// Retry config
+	RetryTemperatures []float64 // Temperature ladder for regenerating after repeated identical failures (nil = off), e.g. 0.4, 0.7, 1.0
+
+	// Write-statement config
+	WriteMode    bool // Answer data-changing questions with INSERT/UPDATE/DELETE, dry-run in a rolled-back transaction
+	CommitWrites bool // Commit the final write statement (WriteMode; tools still only dry-run)
+
+	// Tool config
+	DisabledTools []string // ReAct tools to leave out, built-in or registered (e.g. "update_rich_context")
+
+	// Loop detection config
+	MaxLoopInterventions int // Interventions on repeated ReAct tool calls before forcing a final answer (0 = off)
+
+	// Evidence config
+	Evidence string // BIRD evidence for the question; parsed into hints placed under the tables they refer to
 
 	// Benchmark-specific config
 	Benchmark string // "spider" | "bird" — controls prompt strategy
@@ -47,18 +115,57 @@ type StepCallback func(step ReActStep, eventType string)
 type Pipeline struct {
 	llm          llms.Model
 	adapter      adapter.DBAdapter
+	queryAdapter adapter.DBAdapter // Adapter for LLM tools, accepts SQL in the generation dialect
 	config       *Config
 	context      *contextpkg.SharedContext
+	lazyContext  *contextpkg.LazyContext // Config.LazyContext: source of context's tables, hydrated per query
 	schemaLinker SchemaLinker
 	tokenizer    *tiktoken.Tiktoken
 
 	// Token statistics accumulator
 	promptTexts   []string
 	responseTexts []string
+	reactTokens   int // ReAct iteration tokens (the agent's calls are not in promptTexts)
+
+	// Retrieved few-shot examples for the current query
+	fewShotPrompt string
+
+	// Value index matches for the current query
+	valueHintPrompt string
+
+	// Multi-hop join paths between the current query's selected tables
+	joinPathPrompt string
+
+	// Glossary terms used in the current query
+	glossaryPrompt string
+
+	// Parsed JSON final answer for the current query (Config.JSONAnswer)
+	finalAnswer *FinalAnswer
+
+	// Model reasoning behind the current query's answer
+	thinking string
+
+	// Failed attempts shown to a temperature-ladder retry
+	retryPreamble string
+
+	// Best SQL of an interrupted ReAct loop (Config.MaxDuration)
+	partialSQL string
+
+	// Previous turns of the Session asking the current question
+	history []Turn
 
 	// Streaming callback
 	stepCallback StepCallback
 
+	// Answers clarify_fields questions (nil = pre-computed ResultFields)
+	clarifyBackend ClarifyBackend
+
+	// Domain tools added with RegisterTool
+	customTools []tools.Tool
+
+	// ReAct executor and tools, reused across queries
+	reactAgent *reactAgent
+
 	// Logger for structured output (stdout + file)
 	Logger *InferenceLogger
 }
@@ -66,6 +173,7 @@ type Pipeline struct {
 // Result inference result
 type Result struct {
 	Query           string
+	StandaloneQuery string // Follow-up rewritten to stand alone (Session; "" otherwise)
 	GeneratedSQL    string
 	ExecutionResult interface{}
 
@@ -76,19 +184,80 @@ type Result struct {
 	TotalTokens   int
 	ClarifyCount  int // Clarify count
 
+	// Decomposition steps (Config.Decompose, multi-hop questions only)
+	SubQuestions []SubQuestion
+
+	// Multi-candidate details (Config.Candidates > 1)
+	Candidates []Candidate
+
+	// Final SQL per Config.EmitDialects
+	DialectSQL map[string]string
+
+	// Structured final answer (Config.JSONAnswer; nil if the model's answer was not valid JSON)
+	FinalAnswer *FinalAnswer
+
+	// Natural-language answer from the result rows (Config.NLAnswer; "" if the SQL did not execute)
+	Answer string
+
+	// Model reasoning behind the final SQL (last ReAct Thought, or the prose around a one-shot answer)
+	Thinking string
+
+	// Config.Evidence parsed into column mappings, value constraints and formulas
+	EvidenceHints []EvidenceHint
+
+	// Columns dropped from the schema prompt as irrelevant (Config.PruneSchemaTokens)
+	PrunedColumns []ColumnRelevance
+
+	// Self-consistency statistics of the candidates (Config.Candidates > 1)
+	Agreement *Agreement
+
+	// The ReAct loop hit its iteration limit and the SQL came from a forced final answer
+	ForcedFinalAnswer bool
+
+	// Path that produced the SQL (Path* constants; "one_shot_fallback" = ReAct failed)
+	GenerationPath string
+
+	// Config.Style rewrites applied to the final SQL
+	StyleRewrites []string
+
+	// Config.CanonicalValues literal rewrites ("table.column: \"usa\" → \" USA\"")
+	ValueRewrites []string
+
+	// Config.MaxDuration ran out; the SQL is the best seen so far (later phases skipped)
+	BudgetExhausted bool
+
+	// Repeated ReAct tool calls answered with an intervention instead of being run
+	LoopInterventions int
+
+	// Schema linking came from Config.LinkCache (no linking call)
+	LinkingCached bool
+
+	// Change made to the SELECT list to match ResultFields (force clarify; "" = none)
+	ProjectionRewrite string
+
+	// Outcome of a data-changing final SQL (Config.WriteMode; nil for queries)
+	Write *WriteOutcome
+
 	// Intermediate results
-	SelectedTables []string
-	ReActSteps     []ReActStep
+	SelectedTables  []string
+	SelectedColumns map[string][]string // Column linking output (nil = all columns)
+	ReActSteps      []ReActStep
+
+	// Time, LLM calls and tokens per phase (Phase* constants)
+	Phases map[string]*PhaseStats
+
+	// Versioned steps + LLM exchanges for export and replay
+	Trajectory *Trajectory
 }
 
 // ReActStep represents a ReAct step
 type ReActStep struct {
-	Step        int         `json:"step,omitempty"`              // Step number for streaming
+	Step        int         `json:"step,omitempty"` // Step number for streaming
 	Thought     string      `json:"thought"`
 	Action      string      `json:"action"`
 	ActionInput interface{} `json:"action_input,omitempty"` // Supports string and map[string]interface{}
 	Observation string      `json:"observation,omitempty"`
-	Phase       string      `json:"phase,omitempty"` // "schema_linking" or "sql_generation"
+	Phase       string      `json:"phase,omitempty"` // "schema_linking" | "decomposition" | "sql_generation" | "syntax_repair" | "exec_repair"
 }
 
 // Reset cleans accumulated stats to prevent memory leaks
@@ -107,11 +276,38 @@ func (p *Pipeline) SetLogger(logger *InferenceLogger) {
 	}
 }
 
+// SetContext sets the Rich Context directly (instead of Config.ContextFile)
+func (p *Pipeline) SetContext(ctx *contextpkg.SharedContext) {
+	p.context = ctx
+	p.setLinkerSummary()
+}
+
+// SetLazyContext sets a lazily decoded Rich Context (instead of Config.ContextFile with LazyContext)
+func (p *Pipeline) SetLazyContext(lazy *contextpkg.LazyContext) {
+	p.lazyContext = lazy
+	p.context = lazy.Context()
+	p.setLinkerSummary()
+}
+
+// setLinkerSummary hands the context's database overview to the schema linker
+// Set once with the context, never per Execute: concurrent questions share the linker.
+func (p *Pipeline) setLinkerSummary() {
+	if linker, ok := p.schemaLinker.(*LLMSchemaLinker); ok && p.context != nil {
+		linker.summary = p.context.Summary
+	}
+}
+
 // SetStepCallback sets the callback function for streaming ReAct steps
 func (p *Pipeline) SetStepCallback(callback StepCallback) {
 	p.stepCallback = callback
 }
 
+// SetClarifyBackend routes the agent's clarification questions to a real user
+// Takes effect when Config.ClarifyMode is "on".
+func (p *Pipeline) SetClarifyBackend(backend ClarifyBackend) {
+	p.clarifyBackend = backend
+}
+
 // notifyStep notifies the callback of a ReAct step update
 func (p *Pipeline) notifyStep(step ReActStep, eventType string) {
 	if p.stepCallback != nil {
@@ -119,6 +315,9 @@ func (p *Pipeline) notifyStep(step ReActStep, eventType string) {
 	}
 }
 
+// refreshQualityCheckTimeout limit per check query of Config.RefreshQuality
+const refreshQualityCheckTimeout = 5 * time.Second
+
 // NewPipeline creates inference pipeline
 func NewPipeline(llm llms.Model, adapter adapter.DBAdapter, config *Config) *Pipeline {
 	// Initialize tokenizer (using cl100k_base for GPT-3.5/GPT-4/DeepSeek)
@@ -128,12 +327,21 @@ func NewPipeline(llm llms.Model, adapter adapter.DBAdapter, config *Config) *Pip
 		tokenizer = nil
 	}
 
+	// LLM tools run SQL in the generation dialect; writes only ever dry-run
+	toolAdapter := adapter
+	if config.WriteMode {
+		toolAdapter = &dryRunAdapter{DBAdapter: adapter}
+	}
+	queryAdapter := newQueryAdapter(toolAdapter, config)
+
 	// Schema Linking uses ReAct mode (controlled by ReactLinking config)
-	linker := NewLLMSchemaLinker(llm, adapter, config.ReactLinking)
+	linker := NewLLMSchemaLinker(llm, queryAdapter, config.ReactLinking)
+	linker.linkColumns = config.ColumnLinking
 
 	p := &Pipeline{
 		llm:          llm,
 		adapter:      adapter,
+		queryAdapter: queryAdapter,
 		config:       config,
 		schemaLinker: linker,
 		tokenizer:    tokenizer,
@@ -152,11 +360,25 @@ func NewPipeline(llm llms.Model, adapter adapter.DBAdapter, config *Config) *Pip
 	// Load Context file (if provided)
 	// Note: context always loaded for Schema Linking
 	// UseRichContext only controls using rich_context in SQL Generation
-	if config.ContextFile != "" {
+	if config.ContextFile != "" && config.LazyContext {
+		if lazy, err := p.openLazyContext(config.ContextFile); err == nil {
+			p.SetLazyContext(lazy)
+		}
+	} else if config.ContextFile != "" {
 		if ctx, err := p.loadContext(config.ContextFile); err == nil {
-			p.context = ctx
+			p.SetContext(ctx)
 		}
 	}
+	if config.RefreshQuality && p.lazyContext != nil {
+		// Quality checks rewrite every table's issues
+		p.lazyContext.Hydrate(nil)
+	}
+	if config.RefreshQuality && p.context != nil {
+		report := contextpkg.RunQualityChecks(context.Background(), adapter, p.context, contextpkg.QualityBatchOptions{
+			CheckTimeout: refreshQualityCheckTimeout,
+		})
+		p.Logger.Printf("🔍 Refreshed quality checks: %s\n", report.Headline())
+	}
 
 	return p
 }
@@ -177,16 +399,37 @@ func (p *Pipeline) Execute(ctx context.Context, query string) (*Result, error) {
 	// Reset token stat accumulator
 	p.promptTexts = []string{}
 	p.responseTexts = []string{}
+	p.reactTokens = 0
+	p.finalAnswer = nil
+	p.thinking = ""
+	p.retryPreamble = ""
+	p.partialSQL = ""
+
+	// Wall-clock budget: once spent, the best SQL so far is returned (Result.BudgetExhausted)
+	callerCtx := ctx
+	ctx, cancel := p.withBudget(ctx)
+	defer cancel()
 
 	result := &Result{
 		Query:      query,
 		ReActSteps: []ReActStep{},
 	}
 
+	// Follow-up in a Session: rewrite it to stand alone before linking
+	if len(p.history) > 0 {
+		mark := p.beginPhase(result)
+		query = p.resolveFollowUp(ctx, query, result)
+		result.StandaloneQuery = query
+		p.endPhase(result, PhaseFollowUp, mark)
+	}
+
 	// 1. Schema Linking (always runs, identifies relevant tables)
 	var allTableInfo map[string]*TableInfo
 	var err error
-	if p.context != nil {
+	if p.lazyContext != nil {
+		// Table index only; the selected tables are decoded after linking
+		allTableInfo = tableInfoFromIndex(p.lazyContext)
+	} else if p.context != nil {
 		// Extract table info from Rich Context
 		allTableInfo = ExtractTableInfo(p.context)
 	} else {
@@ -198,25 +441,51 @@ func (p *Pipeline) Execute(ctx context.Context, query string) (*Result, error) {
 	}
 
 	// Build full RC prompt for Schema Linker (so it can read everything and output focused context)
+	// Skipped for a lazy context, which would have to decode every table for it
 	var fullRCPrompt string
-	if p.config.UseRichContext && p.context != nil {
+	if p.config.UseRichContext && p.context != nil && p.lazyContext == nil {
 		fullRCOpts := &contextpkg.ExportOptions{
 			Tables:             nil, // all tables
 			IncludeColumns:     true,
 			IncludeIndexes:     true,
 			IncludeRichContext: true,
 			IncludeStats:       true,
+			MaxTableTokens:     p.config.MaxTableTokens,
+			MaxEnumValues:      p.config.MaxEnumValues,
+			SkipExpiredNotes:   p.config.SkipExpiredNotes,
 		}
 		fullRCPrompt = p.context.ExportToCompactPrompt(fullRCOpts)
 	}
 
-	linkResult, err := p.schemaLinker.Link(ctx, query, allTableInfo, fullRCPrompt)
+	// The linker reads the evidence as text; generation gets it structured
+	linkQuery := query
+	if p.config.Evidence != "" {
+		linkQuery = fmt.Sprintf("%s\n\nEvidence (MUST follow these constraints):\n%s", query, p.config.Evidence)
+	}
+
+	// Business jargon in the question ("ARR", "churn"), with the columns it maps to
+	p.glossaryPrompt = ""
+	if p.config.UseRichContext && p.context != nil {
+		if terms := p.context.MatchGlossary(query); len(terms) > 0 {
+			p.glossaryPrompt = contextpkg.FormatGlossaryPrompt(terms)
+			linkQuery += "\n\n" + strings.TrimSpace(p.glossaryPrompt)
+			p.Logger.Printf("📖 Matched %d glossary terms\n", len(terms))
+		}
+	}
+
+	linkMark := p.beginPhase(result)
+	linkResult, cached, err := p.linkSchema(ctx, linkQuery, allTableInfo, fullRCPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("schema linking failed: %w", err)
 	}
 	tables := linkResult.Tables
 	result.SelectedTables = tables
-	result.LLMCalls++
+	result.SelectedColumns = linkResult.Columns
+	result.LinkingCached = cached
+	if !cached {
+		result.LLMCalls++
+	}
+	p.endPhase(result, PhaseSchemaLinking, linkMark)
 
 	// Add Schema Linking ReAct steps to result
 	for _, step := range linkResult.Steps {
@@ -231,6 +500,13 @@ func (p *Pipeline) Execute(ctx context.Context, query string) (*Result, error) {
 
 	p.Logger.Printf("📋 Selected Tables: %v\n\n", tables)
 
+	if p.lazyContext != nil {
+		if _, err := p.lazyContext.Hydrate(tables); err != nil {
+			return nil, fmt.Errorf("failed to load context tables: %w", err)
+		}
+		p.Logger.FileOnly("📚 Context tables decoded: %d of %d\n", p.lazyContext.Hydrated(), len(allTableInfo))
+	}
+
 	// 2. Build Schema Context for SQL generation
 	var contextPrompt string
 	var crossTableSummary string
@@ -248,6 +524,10 @@ func (p *Pipeline) Execute(ctx context.Context, query string) (*Result, error) {
 				IncludeIndexes:     true,
 				IncludeRichContext: true,
 				IncludeStats:       true,
+				Columns:            linkResult.Columns,
+				MaxTableTokens:     p.config.MaxTableTokens,
+				MaxEnumValues:      p.config.MaxEnumValues,
+				SkipExpiredNotes:   p.config.SkipExpiredNotes,
 			}
 			contextPrompt = p.context.ExportToCompactPrompt(opts)
 			p.Logger.Printf("📚 Using full Rich Context for %d tables (linker had no focused context)\n", len(tables))
@@ -265,85 +545,240 @@ func (p *Pipeline) Execute(ctx context.Context, query string) (*Result, error) {
 		p.Logger.FileOnly("└──────────────────────────────────────────────────────────\n\n")
 	} else {
 		// Use basic Schema (table+column names only)
-		contextPrompt = p.buildBasicSchema(ctx, tables)
+		contextPrompt = p.buildBasicSchema(ctx, tables, linkResult.Columns)
 		p.Logger.Printf("📋 Using Basic Schema for %d tables\n", len(tables))
 	}
 
-	// 3. Generate SQL
+	// Low-relevance columns out of an oversized schema (scored against question and evidence)
+	contextPrompt, result.PrunedColumns = p.pruneColumns(ctx, linkQuery, contextPrompt, allTableInfo)
+
+	// Evidence hints under the tables they refer to, the rest after the schema
+	if p.config.Evidence != "" {
+		hints := ParseEvidence(p.config.Evidence)
+		resolveEvidence(hints, allTableInfo, tables)
+		result.EvidenceHints = hints
+		contextPrompt = injectEvidenceHints(contextPrompt, hints)
+		p.Logger.Printf("📌 Parsed %d evidence clauses\n", len(hints))
+	}
+
+	// Few-shot examples (embedding retrieval over training pairs)
+	p.fewShotPrompt = ""
+	if p.config.FewShot != nil {
+		k := p.config.FewShotK
+		if k <= 0 {
+			k = defaultFewShotK
+		}
+		examples, err := p.config.FewShot.Retrieve(ctx, query, k)
+		if err != nil {
+			p.Logger.Printf("⚠️  Few-shot retrieval failed: %v\n", err)
+		} else {
+			p.fewShotPrompt = formatFewShotPrompt(examples)
+			p.Logger.Printf("📎 Retrieved %d few-shot examples\n", len(examples))
+		}
+	}
+
+	// Join paths through bridge tables between the selected tables
+	p.joinPathPrompt = ""
+	if p.config.UseRichContext && p.context != nil {
+		p.joinPathPrompt = p.context.FormatJoinPathsForTables(tables)
+	}
+
+	// Value hints (question phrases found in the cell-value index)
+	p.valueHintPrompt = ""
+	if p.config.ValueHints {
+		if matches := p.matchValueHints(query, tables); len(matches) > 0 {
+			p.valueHintPrompt = formatValueHintsPrompt(matches, p.generationDialect())
+			p.Logger.Printf("🔎 Matched %d question values in the value index\n", len(matches))
+		}
+	}
+
+	// 3. Generate SQL: decomposed for multi-hop questions, else N candidates reranked by execution when configured
 	var sql string
-	if p.config.UseReact {
-		sql, err = p.reactLoop(ctx, query, contextPrompt, crossTableSummary, result)
-	} else {
-		sql, err = p.oneShotGeneration(ctx, query, contextPrompt, crossTableSummary)
-		result.LLMCalls++
+	if p.config.Decompose {
+		mark := p.beginPhase(result)
+		sql = p.generateDecomposed(ctx, query, contextPrompt, crossTableSummary, result)
+		p.endPhase(result, PhaseDecomposition, mark)
+		if sql != "" {
+			result.GenerationPath = PathDecomposition
+		}
 	}
+	if sql == "" {
+		mark := p.beginPhase(result)
+		if p.config.Candidates > 1 {
+			sql, err = p.generateCandidates(ctx, query, contextPrompt, crossTableSummary, result)
+		} else {
+			sql, err = p.generateWithRetries(ctx, query, contextPrompt, crossTableSummary, result)
+		}
+		p.endPhase(result, PhaseGeneration, mark)
 
-	if err != nil {
-		return nil, fmt.Errorf("SQL generation failed: %w", err)
+		if err != nil && p.budgetExhausted(callerCtx, ctx, result) && p.partialSQL != "" {
+			p.Logger.Printf("⏱️  Using the last SQL of the interrupted loop: %s\n", p.partialSQL)
+			sql, err = p.partialSQL, nil
+			result.GenerationPath = PathReAct
+		}
+		if err != nil && result.BudgetExhausted {
+			return nil, fmt.Errorf("time budget of %s exhausted before any SQL: %w", p.config.MaxDuration, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("SQL generation failed: %w", err)
+		}
+	}
+
+	// Validate syntax before accepting the answer; parse failures get a repair round
+	if !p.budgetExhausted(callerCtx, ctx, result) {
+		repairMark := p.beginPhase(result)
+		sql = p.repairSyntax(ctx, query, contextPrompt, sql, result)
+		p.endPhase(result, PhaseSyntaxRepair, repairMark)
+	}
+
+	// Exact stored literals for user-provided values, before execution checks the result
+	if sql != "" {
+		sql = p.applyValueCanonicalization(sql, result)
+	}
+
+	// 4. Execute SQL, repairing execution errors and unexpected empty results
+	if sql != "" && !p.budgetExhausted(callerCtx, ctx, result) {
+		mark := p.beginPhase(result)
+		sql = p.repairExecution(ctx, query, contextPrompt, sql, result)
+		p.endPhase(result, PhaseExecRepair, mark)
+	}
+
+	// Team style guide (Config.Style): CTEs vs subqueries, explicit joins, alias conventions
+	if sql != "" {
+		sql = p.applySQLStyle(sql, result)
+	}
+
+	// Required output fields (force clarify): SELECT list put in their order
+	if p.config.ClarifyMode == "force" && len(p.config.ResultFields) > 0 && sql != "" {
+		sql = p.alignResultFields(sql, result)
+	}
+
+	// Natural-language answer from the executed rows
+	if p.config.NLAnswer && sql != "" && !p.budgetExhausted(callerCtx, ctx, result) {
+		mark := p.beginPhase(result)
+		result.Answer = p.answerQuestion(ctx, query, sql, result)
+		p.endPhase(result, PhaseAnswer, mark)
+	}
+
+	// Emit the answer in the database's dialect (and any extra requested dialects)
+	sql = p.transpileResult(sql, result)
+
+	// Data-changing answer: dry run, or commit when explicitly configured
+	if p.config.WriteMode && sql != "" && !p.budgetExhausted(callerCtx, ctx, result) {
+		result.Write = p.applyWrite(ctx, sql, result)
 	}
 
 	result.GeneratedSQL = sql
 	result.TotalTime = time.Since(startTime)
+	result.FinalAnswer = p.finalAnswer
+	result.Thinking = p.thinking
+	result.Trajectory = p.buildTrajectory(result)
+
+	// 5. Count tokens (per phase; clarify/proofread are tool time without LLM calls)
+	result.TotalTokens = 0
+	for _, phase := range result.Phases {
+		result.TotalTokens += phase.Tokens
+	}
 
-	// 4. Count tokens (from all accumulated prompts and responses)
-	// Token counting temporarily disabled to avoid potential issues
-	p.Logger.Printf("[DEBUG] Token counting disabled (would count %d prompts, %d responses)\n", len(p.promptTexts), len(p.responseTexts))
-	result.TotalTokens = 0 // temporarily set to 0
-
-	// if p.tokenizer != nil {
-	// 	for i, prompt := range p.promptTexts {
-	// 		fmt.Printf("[DEBUG] Counting prompt %d/%d (length: %d)\n", i+1, len(p.promptTexts), len(prompt))
-	// 		result.TotalTokens += p.countTokens(prompt)
-	// 	}
-	// 	for i, response := range p.responseTexts {
-	// 		fmt.Printf("[DEBUG] Counting response %d/%d (length: %d)\n", i+1, len(p.responseTexts), len(response))
-	// 		result.TotalTokens += p.countTokens(response)
-	// 	}
-	// }
-
-	// 5. Execute SQL (optional)
-	if sql != "" {
-		execResult, err := p.adapter.ExecuteQuery(ctx, sql)
-		if err == nil {
-			result.ExecutionResult = execResult
-			result.SQLExecutions++
+	return result, nil
+}
+
+// targetDialect dialect of the connected database
+func (p *Pipeline) targetDialect() string {
+	return NormalizeDialect(p.adapter.GetDatabaseType())
+}
+
+// generationDialect dialect the LLM is asked to write in
+func (p *Pipeline) generationDialect() string {
+	if d := NormalizeDialect(p.config.GenerationDialect); d != "" {
+		return d
+	}
+	return p.targetDialect()
+}
+
+// transpileResult converts the generated SQL to the target dialect and fills Result.DialectSQL
+// On a translation failure the SQL is kept as generated.
+func (p *Pipeline) transpileResult(sql string, result *Result) string {
+	from, to := p.generationDialect(), p.targetDialect()
+
+	for _, name := range p.config.EmitDialects {
+		dialect := NormalizeDialect(name)
+		if dialect == "" {
+			p.Logger.Printf("⚠️  Unknown dialect %q in EmitDialects\n", name)
+			continue
+		}
+		translated, err := TranspileSQL(sql, from, dialect)
+		if err != nil {
+			p.Logger.Printf("⚠️  Cannot emit %s SQL: %v\n", dialectDisplayName(dialect), err)
+			continue
+		}
+		if result.DialectSQL == nil {
+			result.DialectSQL = make(map[string]string)
 		}
+		result.DialectSQL[dialect] = translated
 	}
 
-	return result, nil
+	if from == to || to == "" {
+		return sql
+	}
+	translated, err := TranspileSQL(sql, from, to)
+	if err != nil {
+		p.Logger.Printf("⚠️  Transpile %s → %s failed, keeping generated SQL: %v\n", dialectDisplayName(from), dialectDisplayName(to), err)
+		return sql
+	}
+	p.Logger.Printf("🔁 Transpiled %s → %s: %s\n", dialectDisplayName(from), dialectDisplayName(to), translated)
+	return translated
 }
 
-// loadContext loads Rich Context
+// loadContext loads Rich Context with its overrides file merged over it
 func (p *Pipeline) loadContext(path string) (*contextpkg.SharedContext, error) {
-	data, err := os.ReadFile(path)
+	ctx, err := contextpkg.LoadContextWithOverrides(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var ctx contextpkg.SharedContext
-	if err := json.Unmarshal(data, &ctx); err != nil {
+	// Files generated before join paths were stored
+	if len(ctx.JoinPaths) == 0 {
+		ctx.AnalyzeJoinPaths()
+	}
+	p.logExpiredNotes(ctx.ExpiredNotes(time.Now()))
+
+	return ctx, nil
+}
+
+// openLazyContext opens Rich Context for lazy decoding, with loadContext's post-load steps
+// run on the table index so no table is decoded for them
+func (p *Pipeline) openLazyContext(path string) (*contextpkg.LazyContext, error) {
+	lazy, err := contextpkg.OpenLazyContext(path)
+	if err != nil {
 		return nil, err
 	}
 
-	return &ctx, nil
+	// Files generated before join paths were stored
+	if len(lazy.Context().JoinPaths) == 0 {
+		lazy.AnalyzeJoinPaths()
+	}
+	p.logExpiredNotes(lazy.ExpiredNotes(time.Now()))
+
+	return lazy, nil
+}
+
+// logExpiredNotes reports the expired notes of a freshly loaded context
+func (p *Pipeline) logExpiredNotes(expired []string) {
+	if len(expired) == 0 {
+		return
+	}
+	action := "tagged [EXPIRED] in prompts"
+	if p.config.SkipExpiredNotes {
+		action = "left out of prompts"
+	}
+	p.Logger.Printf("⏳ %d Rich Context notes expired, %s (prune with context_gc): %v\n", len(expired), action, expired)
 }
 
 // extractTableInfoFromDB extracts table info from DB
 func (p *Pipeline) extractTableInfoFromDB(ctx context.Context) (map[string]*TableInfo, error) {
 	// Get all table names
-	var query string
-	switch p.adapter.GetDatabaseType() {
-	case "MySQL":
-		query = "SHOW TABLES"
-	case "PostgreSQL":
-		query = "SELECT tablename FROM pg_tables WHERE schemaname='public'"
-	case "SQLite":
-		query = "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'"
-	default:
-		return nil, fmt.Errorf("unsupported database type")
-	}
-
-	result, err := p.adapter.ExecuteQuery(ctx, query)
+	tables, err := p.adapter.GetTables(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -351,56 +786,15 @@ func (p *Pipeline) extractTableInfoFromDB(ctx context.Context) (map[string]*Tabl
 	tableInfo := make(map[string]*TableInfo)
 
 	// Query column info for each table
-	for _, row := range result.Rows {
-		var tableName string
-		for _, val := range row {
-			if name, ok := val.(string); ok {
-				tableName = name
-				break
-			}
-		}
-
-		if tableName == "" {
-			continue
-		}
-
-		// Query column info
-		var colQuery string
-		switch p.adapter.GetDatabaseType() {
-		case "MySQL":
-			colQuery = fmt.Sprintf("DESCRIBE %s", tableName)
-		case "SQLite":
-			colQuery = fmt.Sprintf("PRAGMA table_info(%s)", tableName)
-		case "PostgreSQL":
-			colQuery = fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name='%s'", tableName)
-		}
-
-		colResult, err := p.adapter.ExecuteQuery(ctx, colQuery)
+	for _, tableName := range tables {
+		cols, err := p.adapter.GetColumns(ctx, tableName)
 		if err != nil {
 			continue
 		}
 
-		columns := make([]string, 0, len(colResult.Rows))
-		for _, colRow := range colResult.Rows {
-			var colName string
-			switch p.adapter.GetDatabaseType() {
-			case "MySQL":
-				if field, ok := colRow["Field"].(string); ok {
-					colName = field
-				}
-			case "SQLite":
-				if name, ok := colRow["name"].(string); ok {
-					colName = name
-				}
-			case "PostgreSQL":
-				if name, ok := colRow["column_name"].(string); ok {
-					colName = name
-				}
-			}
-
-			if colName != "" {
-				columns = append(columns, colName)
-			}
+		columns := make([]string, 0, len(cols))
+		for _, col := range cols {
+			columns = append(columns, col.Name)
 		}
 
 		tableInfo[tableName] = &TableInfo{
@@ -413,26 +807,14 @@ func (p *Pipeline) extractTableInfoFromDB(ctx context.Context) (map[string]*Tabl
 }
 
 // buildBasicSchema builds basic schema from DB table structure
-func (p *Pipeline) buildBasicSchema(ctx context.Context, tables []string) string {
+func (p *Pipeline) buildBasicSchema(ctx context.Context, tables []string, linkedColumns map[string][]string) string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("Database: %s\n\n", p.adapter.GetDatabaseType()))
 
 	for _, tableName := range tables {
 		// Query table structure
-		var query string
-		switch p.adapter.GetDatabaseType() {
-		case "MySQL":
-			query = fmt.Sprintf("DESCRIBE %s", tableName)
-		case "SQLite":
-			query = fmt.Sprintf("PRAGMA table_info(%s)", tableName)
-		case "PostgreSQL":
-			query = fmt.Sprintf("SELECT column_name, data_type FROM information_schema.columns WHERE table_name='%s'", tableName)
-		default:
-			continue
-		}
-
-		result, err := p.adapter.ExecuteQuery(ctx, query)
+		columns, err := p.adapter.GetColumns(ctx, tableName)
 		if err != nil {
 			continue
 		}
@@ -440,37 +822,12 @@ func (p *Pipeline) buildBasicSchema(ctx context.Context, tables []string) string
 		// Format table structure
 		sb.WriteString(fmt.Sprintf("Table %s:\n", tableName))
 
-		for _, row := range result.Rows {
-			var colName, colType string
-
-			// Extract column name and type based on DB type
-			switch p.adapter.GetDatabaseType() {
-			case "MySQL":
-				if field, ok := row["Field"].(string); ok {
-					colName = field
-				}
-				if typ, ok := row["Type"].(string); ok {
-					colType = typ
-				}
-			case "SQLite":
-				if name, ok := row["name"].(string); ok {
-					colName = name
-				}
-				if typ, ok := row["type"].(string); ok {
-					colType = typ
-				}
-			case "PostgreSQL":
-				if name, ok := row["column_name"].(string); ok {
-					colName = name
-				}
-				if typ, ok := row["data_type"].(string); ok {
-					colType = typ
-				}
-			}
-
-			if colName != "" {
-				sb.WriteString(fmt.Sprintf("  - %s: %s\n", colName, colType))
+		keep := p.basicColumnFilter(ctx, tableName, columns, linkedColumns[tableName])
+		for _, col := range columns {
+			if keep != nil && !keep[strings.ToLower(col.Name)] {
+				continue
 			}
+			sb.WriteString(fmt.Sprintf("  - %s: %s\n", col.Name, col.Type))
 		}
 
 		sb.WriteString("\n")
@@ -478,3 +835,35 @@ func (p *Pipeline) buildBasicSchema(ctx context.Context, tables []string) string
 
 	return sb.String()
 }
+
+// basicColumnFilter returns the lower-cased columns to show (nil = all); keys are always kept
+func (p *Pipeline) basicColumnFilter(ctx context.Context, tableName string, columns []adapter.ColumnInfo, selected []string) map[string]bool {
+	if len(selected) == 0 {
+		return nil
+	}
+	existing := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		existing[strings.ToLower(col.Name)] = true
+	}
+	keep := make(map[string]bool)
+	for _, name := range selected {
+		if existing[strings.ToLower(name)] {
+			keep[strings.ToLower(name)] = true
+		}
+	}
+	if len(keep) == 0 {
+		return nil
+	}
+
+	for _, col := range columns {
+		if col.IsPrimaryKey {
+			keep[strings.ToLower(col.Name)] = true
+		}
+	}
+	if fks, err := p.adapter.GetForeignKeys(ctx, tableName); err == nil {
+		for _, fk := range fks {
+			keep[strings.ToLower(fk.ColumnName)] = true
+		}
+	}
+	return keep
+}
@@ -0,0 +1,354 @@
+package inference
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// selectListEnd keywords that end the SELECT list at depth 0
+var selectListEnd = map[string]bool{
+	"FROM": true, "INTO": true, "WHERE": true, "GROUP": true, "HAVING": true, "ORDER": true,
+	"LIMIT": true, "OFFSET": true, "FETCH": true, "WINDOW": true,
+}
+
+// compoundKeywords set operators; their branches must keep the same column order
+var compoundKeywords = map[string]bool{"UNION": true, "INTERSECT": true, "EXCEPT": true}
+
+// plainAlias identifiers usable as an alias without quotes
+var plainAlias = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// projectionItem one SELECT list entry
+type projectionItem struct {
+	start, end int    // Byte range in the SQL
+	expr       string // Text without the alias
+	name       string // Output name: alias, column name, else the expression (normalized)
+	alias      string // Alias as written ("" = none)
+}
+
+// alignProjection reorders (and, for a single leftover pair, renames) the outer SELECT list
+// to the expected fields, so the output matches ResultFields without relying on the prompt.
+// Items are matched to fields by output name, then by expression text; the SQL is returned
+// unchanged with the reason unless every field maps to exactly one item, else rewritten
+// with a summary of the change. Positional ORDER BY / GROUP BY references follow the reordering.
+func alignProjection(sql string, fields []string, dialect string) (string, string) {
+	if len(fields) == 0 {
+		return sql, ""
+	}
+	toks, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return sql, "not tokenizable"
+	}
+
+	// Outer SELECT list
+	depth, selectAt, listEnd := 0, -1, -1
+	for i, tok := range toks {
+		switch {
+		case tok.kind == tokOp && tok.text == "(":
+			depth++
+		case tok.kind == tokOp && tok.text == ")":
+			depth--
+		case depth != 0 || tok.kind != tokWord:
+		case compoundKeywords[tok.upper]:
+			return sql, "compound query"
+		case tok.upper == "SELECT" && selectAt < 0:
+			selectAt = i
+		case selectAt >= 0 && listEnd < 0 && selectListEnd[tok.upper]:
+			listEnd = i
+		}
+		if selectAt >= 0 && listEnd < 0 && depth == 0 && (tok.kind == tokEOF || (tok.kind == tokOp && tok.text == ";")) {
+			listEnd = i
+		}
+	}
+	if selectAt < 0 || listEnd < 0 {
+		return sql, "no outer SELECT"
+	}
+	listStart := selectAt + 1
+	for listStart < listEnd && toks[listStart].kind == tokWord && (toks[listStart].upper == "DISTINCT" || toks[listStart].upper == "ALL") {
+		listStart++
+	}
+	if listStart < listEnd && toks[listStart].kind == tokWord && toks[listStart].upper == "ON" {
+		return sql, "DISTINCT ON"
+	}
+
+	items := splitProjection(sql, toks[listStart:listEnd])
+	if len(items) == 0 {
+		return sql, "empty SELECT list"
+	}
+	for _, item := range items {
+		if strings.HasSuffix(item.expr, "*") && item.alias == "" {
+			return sql, "SELECT *"
+		}
+	}
+	if len(items) != len(fields) {
+		return sql, fmt.Sprintf("%d columns for %d fields", len(items), len(fields))
+	}
+
+	// Match by output name, then expression, then pair a single leftover
+	assigned := make([]int, len(fields)) // Field → item
+	used := make([]bool, len(items))
+	for i := range assigned {
+		assigned[i] = -1
+	}
+	matchBy := func(key func(projectionItem) string) {
+		for fi, field := range fields {
+			if assigned[fi] >= 0 {
+				continue
+			}
+			want := normalizeFieldName(field)
+			for ii, item := range items {
+				if !used[ii] && key(item) == want {
+					assigned[fi], used[ii] = ii, true
+					break
+				}
+			}
+		}
+	}
+	matchBy(func(it projectionItem) string { return it.name })
+	matchBy(func(it projectionItem) string { return normalizeFieldName(it.expr) })
+
+	rename := -1 // Field whose item gets its alias
+	var leftFields, leftItems []int
+	for fi := range fields {
+		if assigned[fi] < 0 {
+			leftFields = append(leftFields, fi)
+		}
+	}
+	for ii := range items {
+		if !used[ii] {
+			leftItems = append(leftItems, ii)
+		}
+	}
+	switch {
+	case len(leftFields) == 1 && len(leftItems) == 1:
+		assigned[leftFields[0]] = leftItems[0]
+		rename = leftFields[0]
+	case len(leftFields) > 0:
+		return sql, fmt.Sprintf("%d fields not matched to a column", len(leftFields))
+	}
+
+	reordered := false
+	for fi, ii := range assigned {
+		if fi != ii {
+			reordered = true
+		}
+	}
+	if !reordered && rename < 0 {
+		return sql, ""
+	}
+
+	// Rebuild the list in field order
+	parts := make([]string, len(fields))
+	for fi, ii := range assigned {
+		item := items[ii]
+		text := sql[item.start:item.end]
+		if fi == rename {
+			if item.alias != "" && aliasReferenced(toks, item) {
+				rename = -1 // ORDER BY / HAVING uses the alias; keep it
+			} else {
+				alias := fields[fi]
+				if !plainAlias.MatchString(alias) || reservedWords[strings.ToUpper(alias)] {
+					alias = quoteIdent(alias, dialect)
+				}
+				text = item.expr + " AS " + alias
+			}
+		}
+		parts[fi] = text
+	}
+
+	var sb strings.Builder
+	sb.WriteString(sql[:items[0].start])
+	sb.WriteString(strings.Join(parts, ", "))
+	tail := sql[items[len(items)-1].end:]
+	if reordered {
+		tail = remapPositions(tail, toks[listEnd:], items[len(items)-1].end, assigned)
+	}
+	sb.WriteString(tail)
+
+	var changes []string
+	if reordered {
+		changes = append(changes, "reordered")
+	}
+	if rename >= 0 {
+		changes = append(changes, fmt.Sprintf("renamed column %d to %s", rename+1, fields[rename]))
+	}
+	return sb.String(), strings.Join(changes, ", ")
+}
+
+// splitProjection splits the SELECT list tokens on depth-0 commas
+func splitProjection(sql string, toks []sqlToken) []projectionItem {
+	var items []projectionItem
+	depth, from := 0, 0
+	flush := func(to int) {
+		if to > from {
+			items = append(items, newProjectionItem(sql, toks[from:to]))
+		}
+		from = to + 1
+	}
+	for i, tok := range toks {
+		switch {
+		case tok.kind == tokOp && tok.text == "(":
+			depth++
+		case tok.kind == tokOp && tok.text == ")":
+			depth--
+		case tok.kind == tokOp && tok.text == "," && depth == 0:
+			flush(i)
+		}
+	}
+	flush(len(toks))
+	return items
+}
+
+// newProjectionItem reads the alias and output name of one SELECT list entry
+func newProjectionItem(sql string, toks []sqlToken) projectionItem {
+	last := toks[len(toks)-1]
+	item := projectionItem{start: toks[0].pos, end: last.pos + len(last.text)}
+	exprToks := toks
+
+	n := len(toks)
+	isAliasTok := func(t sqlToken) bool {
+		return t.kind == tokQuoted || (t.kind == tokWord && !reservedWords[t.upper])
+	}
+	switch {
+	case n >= 3 && toks[n-2].kind == tokWord && toks[n-2].upper == "AS" && (isAliasTok(last) || last.kind == tokString):
+		item.alias = last.text
+		exprToks = toks[:n-2]
+	case n >= 2 && isAliasTok(last) && !(toks[n-2].kind == tokOp && toks[n-2].text == "."):
+		prev := toks[n-2]
+		if (prev.kind != tokOp || prev.text == ")") && !(prev.kind == tokWord && reservedWords[prev.upper]) {
+			item.alias = last.text
+			exprToks = toks[:n-1]
+		}
+	}
+	lastExpr := exprToks[len(exprToks)-1]
+	item.expr = sql[exprToks[0].pos : lastExpr.pos+len(lastExpr.text)]
+
+	switch {
+	case item.alias != "":
+		item.name = normalizeFieldName(aliasText(item.alias))
+	case isColumnChain(exprToks):
+		item.name = normalizeFieldName(aliasText(lastExpr.text))
+	default:
+		item.name = normalizeFieldName(item.expr)
+	}
+	return item
+}
+
+// isColumnChain reports whether toks is name{.name}
+func isColumnChain(toks []sqlToken) bool {
+	for i, t := range toks {
+		if i%2 == 1 {
+			if t.kind != tokOp || t.text != "." {
+				return false
+			}
+		} else if t.kind != tokWord && t.kind != tokQuoted {
+			return false
+		}
+	}
+	return len(toks)%2 == 1
+}
+
+// aliasText identifier or string-literal alias without quotes
+func aliasText(text string) string {
+	if len(text) >= 2 && strings.ContainsRune("\"`['", rune(text[0])) {
+		if text[0] == '\'' {
+			return strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+		}
+		return unquoteIdent(text)
+	}
+	return text
+}
+
+// aliasReferenced reports whether the item's alias is used outside the item
+func aliasReferenced(toks []sqlToken, item projectionItem) bool {
+	alias := strings.ToLower(aliasText(item.alias))
+	for _, t := range toks {
+		if t.pos >= item.start && t.pos < item.end {
+			continue
+		}
+		if (t.kind == tokWord || t.kind == tokQuoted) && strings.ToLower(aliasText(t.text)) == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// remapPositions rewrites "ORDER BY 2" / "GROUP BY 1" references after the SELECT list
+// tail is sql from offset; assigned maps new position → old position.
+func remapPositions(tail string, toks []sqlToken, offset int, assigned []int) string {
+	newPos := make(map[int]int, len(assigned)) // Old 1-based → new 1-based
+	for fi, ii := range assigned {
+		newPos[ii+1] = fi + 1
+	}
+
+	type edit struct {
+		at, length int
+		text       string
+	}
+	var edits []edit
+	depth := 0
+	inBy := false
+	for i, tok := range toks {
+		switch {
+		case tok.kind == tokOp && tok.text == "(":
+			depth++
+		case tok.kind == tokOp && tok.text == ")":
+			depth--
+		}
+		if depth != 0 {
+			continue
+		}
+		if tok.kind == tokWord {
+			switch tok.upper {
+			case "BY":
+				inBy = i > 0 && toks[i-1].kind == tokWord && (toks[i-1].upper == "ORDER" || toks[i-1].upper == "GROUP")
+			case "HAVING", "LIMIT", "OFFSET", "FETCH", "WINDOW":
+				inBy = false
+			}
+		}
+		if !inBy || tok.kind != tokNumber || i == 0 {
+			continue
+		}
+		prev, next := toks[i-1], toks[i+1]
+		startsItem := (prev.kind == tokWord && prev.upper == "BY") || (prev.kind == tokOp && prev.text == ",")
+		endsItem := next.kind == tokEOF || (next.kind == tokOp && (next.text == "," || next.text == ";" || next.text == ")")) ||
+			(next.kind == tokWord && (next.upper == "ASC" || next.upper == "DESC" || next.upper == "NULLS" ||
+				selectListEnd[next.upper] || compoundKeywords[next.upper]))
+		old, err := strconv.Atoi(tok.text)
+		if !startsItem || !endsItem || err != nil {
+			continue
+		}
+		if pos, ok := newPos[old]; ok && pos != old {
+			edits = append(edits, edit{at: tok.pos - offset, length: len(tok.text), text: strconv.Itoa(pos)})
+		}
+	}
+
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		tail = tail[:e.at] + e.text + tail[e.at+e.length:]
+	}
+	return tail
+}
+
+// alignResultFields applies alignProjection to the final query
+func (p *Pipeline) alignResultFields(sql string, result *Result) string {
+	dialect := p.generationDialect()
+	if writeStatementKind(sql, dialect) != "" {
+		return sql
+	}
+	aligned, note := alignProjection(sql, p.config.ResultFields, dialect)
+	if aligned == sql {
+		if note != "" {
+			p.Logger.Printf("🔀 Projection left as is (%s)\n", note)
+		}
+		return sql
+	}
+	if err := ParseSQL(aligned, dialect); err != nil {
+		p.Logger.Printf("⚠️  Projection rewrite discarded, it does not parse: %v\n", err)
+		return sql
+	}
+	p.Logger.Printf("🔀 Projection aligned to the result fields (%s): %s\n", note, aligned)
+	result.ProjectionRewrite = note
+	return aligned
+}
@@ -0,0 +1,110 @@
+package inference
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Prompt sections the budgeter may drop
+const (
+	sectionCrossTable    = "cross-table quality summary"
+	sectionJoinHints     = "join paths and field semantics"
+	sectionSamples       = "sample rows"
+	sectionBusinessNotes = "business notes"
+	sectionStats         = "value statistics"
+	sectionFewShot       = "few-shot examples"
+)
+
+// promptDropOrder lowest priority first: details about other tables go before
+// annotations of the selected tables; the schema itself is never dropped.
+var promptDropOrder = []string{
+	sectionCrossTable,
+	sectionJoinHints,
+	sectionSamples,
+	sectionBusinessNotes,
+	sectionStats,
+	sectionFewShot,
+}
+
+// promptSections set of dropped sections
+type promptSections map[string]bool
+
+// fitPromptBudget assembles the prompt and drops sections in promptDropOrder until it fits Config.MaxPromptTokens
+func (p *Pipeline) fitPromptBudget(assemble func(dropped promptSections) string) string {
+	dropped := promptSections{}
+	prompt := assemble(dropped)
+
+	budget := p.config.MaxPromptTokens
+	if budget <= 0 {
+		return prompt
+	}
+
+	tokens := p.estimateTokens(prompt)
+	for _, section := range promptDropOrder {
+		if tokens <= budget {
+			break
+		}
+		dropped[section] = true
+		trimmed := assemble(dropped)
+		trimmedTokens := p.estimateTokens(trimmed)
+		if trimmedTokens < tokens {
+			p.Logger.Printf("✂️  Prompt budget: dropped %s (%d → %d tokens, limit %d)\n", section, tokens, trimmedTokens, budget)
+		}
+		prompt, tokens = trimmed, trimmedTokens
+	}
+
+	if tokens > budget {
+		p.Logger.Printf("⚠️  Prompt still over budget with all optional sections dropped: %d tokens (limit %d)\n", tokens, budget)
+	}
+	return prompt
+}
+
+// estimateTokens counts tokens with the tokenizer, or estimates ~4 chars per token without one
+func (p *Pipeline) estimateTokens(text string) int {
+	if p.tokenizer != nil {
+		return p.countTokens(text)
+	}
+	return (len(text) + 3) / 4
+}
+
+// inlineStatsPattern value annotations on compact column lines: values=[...] / range=[...] / spans a..b
+var inlineStatsPattern = regexp.MustCompile(`( (values|range)=\[[^\n]*?\]| spans \S+\.\.\S+( \(no rows in [^)\n]*\))?)(\s*$)`)
+
+// trimContextPrompt removes dropped annotations from a compact schema prompt
+// Works on ExportToCompactPrompt output and the linker's focused context, which follows the same layout.
+func trimContextPrompt(contextPrompt string, dropped promptSections) string {
+	if !dropped[sectionSamples] && !dropped[sectionBusinessNotes] && !dropped[sectionStats] {
+		return contextPrompt
+	}
+
+	// Block headers whose indented entries go with them
+	blocks := map[string]bool{
+		"Sample rows:":    dropped[sectionSamples],
+		"Business Notes:": dropped[sectionBusinessNotes],
+	}
+
+	lines := strings.Split(contextPrompt, "\n")
+	kept := make([]string, 0, len(lines))
+	inBlock := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if blocks[trimmed] {
+			inBlock = true
+			continue
+		}
+		if inBlock {
+			// Block entries are indented deeper than the header
+			if strings.HasPrefix(line, "    ") && trimmed != "" {
+				continue
+			}
+			inBlock = false
+		}
+
+		if dropped[sectionStats] {
+			line = inlineStatsPattern.ReplaceAllString(line, "$4")
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
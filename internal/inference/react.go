@@ -2,18 +2,18 @@ package inference
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"reactsql/internal/adapter"
 
-	"github.com/tmc/langchaingo/agents"
-	"github.com/tmc/langchaingo/tools"
+	"github.com/tmc/langchaingo/llms"
 )
 
 // oneShotGeneration one-shot SQL generation
-func (p *Pipeline) oneShotGeneration(ctx context.Context, query string, contextPrompt string, crossTableSummary string) (string, error) {
+func (p *Pipeline) oneShotGeneration(ctx context.Context, query string, contextPrompt string, crossTableSummary string, opts ...llms.CallOption) (string, error) {
 	prompt := p.buildPrompt(query, contextPrompt, crossTableSummary, false)
 
 	p.Logger.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
@@ -29,7 +29,7 @@ func (p *Pipeline) oneShotGeneration(ctx context.Context, query string, contextP
 	backoffDelays := []time.Duration{1 * time.Second, 3 * time.Second}
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		response, err = p.llm.Call(ctx, prompt)
+		response, err = p.llm.Call(ctx, prompt, opts...)
 		if err == nil {
 			break
 		}
@@ -58,7 +58,7 @@ func (p *Pipeline) oneShotGeneration(ctx context.Context, query string, contextP
 	p.Logger.Println()
 
 	// Extract SQL
-	sql := p.extractSQL(response)
+	sql := p.extractAnswer(response)
 
 	p.Logger.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	p.Logger.Println(" Extracted SQL:")
@@ -70,81 +70,44 @@ func (p *Pipeline) oneShotGeneration(ctx context.Context, query string, contextP
 }
 
 // reactLoop ReAct loop
-func (p *Pipeline) reactLoop(ctx context.Context, query string, contextPrompt string, crossTableSummary string, result *Result) (string, error) {
-	// Create tools
-	sqlTool := &SQLTool{
-		adapter:   p.adapter,
-		useDryRun: p.config.UseDryRun,
-		logger:    p.Logger,
-	}
-
-	clarifyTool := &ClarifyTool{
-		resultFields:            p.config.ResultFields,
-		resultFieldsDescription: p.config.ResultFieldsDescription,
-		logger:                  p.Logger,
-	}
-
-	// Create verify_sql tool
-	verifySQLTool := NewVerifySQLTool(p.adapter, p.config.DBType)
-	verifySQLTool.logger = p.Logger
-
-	// Create ReAct Agent
-	var toolsList []tools.Tool
-	toolsList = []tools.Tool{sqlTool, verifySQLTool}
-
-	if p.config.ClarifyMode == "on" {
-		toolsList = append(toolsList, clarifyTool)
-	}
-
-	if p.config.EnableProofread {
-		updateTool := NewUpdateRichContextTool(p.config.DBName, p.config.DBType, p.config.Benchmark)
-		updateTool.logger = p.Logger
-		toolsList = append(toolsList, updateTool)
-	}
+func (p *Pipeline) reactLoop(ctx context.Context, query string, contextPrompt string, crossTableSummary string, result *Result, opts ...llms.CallOption) (string, error) {
+	// Tools are built once per Pipeline, then reset for this query
+	agent := p.reactAgentFor(query)
+	sqlTool, clarifyTool, reactHandler := agent.sqlTool, agent.clarifyTool, agent.handler
+	maxIterations := agent.controller.maxIterations
 
-	// Create handler to collect ReAct steps
-	reactHandler := &PrettyReActHandler{logMode: p.config.LogMode, logger: p.Logger}
-
-	// Set up streaming callback if available (for real-time step notifications)
-	if p.stepCallback != nil {
-		reactHandler.SetStepNotifier(func(step CollectedStep, eventType string) {
-			p.stepCallback(ReActStep{
-				Step:        step.Step,
-				Thought:     step.Thought,
-				Action:      step.Action,
-				ActionInput: step.ActionInput,
-				Observation: step.Observation,
-				Phase:       "sql_generation",
-			}, eventType)
-		})
-	}
-
-	// Tell the model a realistic iteration count so it doesn't rush
-	// Allow slightly more actual iterations as safety margin
-	claimedMaxIterations := 10
-	actualMaxIterations := 15
-
-	executor, err := agents.Initialize(
-		p.llm,
-		toolsList,
-		agents.ZeroShotReactDescription,
-		agents.WithMaxIterations(actualMaxIterations),
-		agents.WithCallbacksHandler(reactHandler),
-	)
-	if err != nil {
-		return "", err
-	}
-
-	// Build Prompt - pass claimed iterations to prompt
 	prompt := p.buildPrompt(query, contextPrompt, crossTableSummary, true)
 
 	// Print key info only, skip full prompt（avoid duplicate Best Practices etc.）
 	p.Logger.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	p.Logger.Printf("🔄 Starting ReAct Loop (Claimed %d, Actual Max %d iterations)\n", claimedMaxIterations, actualMaxIterations)
+	p.Logger.Printf("🔄 Starting ReAct Loop (max %d iterations)\n", maxIterations)
 	p.Logger.Printf("Question: %s\n", query)
 	p.Logger.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
-	agentResult, err := executor.Call(ctx, map[string]any{"input": prompt})
+	output, err := agent.controller.run(ctx, prompt, opts...)
+	p.recordReActCost(reactHandler, result, agent.timedClarify, agent.timedProofread)
+	result.LoopInterventions = agent.loopGuard.Interventions
+	if errors.Is(err, errReActStuck) {
+		// Still repeating after the interventions: stop wasting iterations
+		p.Logger.Printf("\n🔁 ReAct Loop kept repeating itself, forcing a final answer\n")
+		collectedSteps := p.collectReActSteps(reactHandler, sqlTool, clarifyTool, result)
+		p.thinking = lastThought(collectedSteps)
+		return p.forceFinalAnswer(ctx, prompt, collectedSteps, result, "Repeated tool calls after interventions")
+	}
+	if errors.Is(err, errReActIterations) {
+		// Out of iterations: keep the work done so far and ask for the best answer
+		p.Logger.Printf("\n⏱️  ReAct Loop hit the iteration limit (%d), forcing a final answer\n", maxIterations)
+		collectedSteps := p.collectReActSteps(reactHandler, sqlTool, clarifyTool, result)
+		p.thinking = lastThought(collectedSteps)
+		return p.forceFinalAnswer(ctx, prompt, collectedSteps, result, "Iteration limit reached")
+	}
+	if err != nil && ctx.Err() != nil {
+		// Interrupted (Config.MaxDuration): keep the steps and the best SQL so far
+		collectedSteps := p.collectReActSteps(reactHandler, sqlTool, clarifyTool, result)
+		p.partialSQL = partialReActSQL(sqlTool, collectedSteps)
+		p.Logger.Printf("\n⏱️  ReAct Loop interrupted: %v\n\n", err)
+		return "", err
+	}
 	if err != nil {
 		p.Logger.Printf("\n❌ ReAct Loop failed: %v\n\n", err)
 		return "", err
@@ -154,8 +117,19 @@ func (p *Pipeline) reactLoop(ctx context.Context, query string, contextPrompt st
 	p.Logger.Println("✅ ReAct Loop completed successfully")
 	p.Logger.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
-	// Collect ReAct steps from handler
-	collectedSteps := reactHandler.GetCollectedSteps()
+	collectedSteps := p.collectReActSteps(reactHandler, sqlTool, clarifyTool, result)
+	p.thinking = lastThought(collectedSteps)
+
+	// Already counted in the iteration tokens
+	p.reactTokens -= p.estimateTokens(prompt) + p.estimateTokens(output)
+	p.promptTexts = append(p.promptTexts, prompt)
+	p.responseTexts = append(p.responseTexts, output)
+	return p.extractAnswer(output), nil
+}
+
+// collectReActSteps copies the handler's steps and tool counters into result
+func (p *Pipeline) collectReActSteps(handler *PrettyReActHandler, sqlTool *SQLTool, clarifyTool *ClarifyTool, result *Result) []CollectedStep {
+	collectedSteps := handler.GetCollectedSteps()
 	for _, step := range collectedSteps {
 		result.ReActSteps = append(result.ReActSteps, ReActStep{
 			Thought:     step.Thought,
@@ -167,48 +141,64 @@ func (p *Pipeline) reactLoop(ctx context.Context, query string, contextPrompt st
 	}
 
 	// Update statistics
-	result.LLMCalls += len(collectedSteps) // Use actual iteration count
+	result.LLMCalls += handler.Iterations()
 	result.SQLExecutions += sqlTool.ExecutionCount
 	result.ClarifyCount = clarifyTool.ClarifyCount
+	return collectedSteps
+}
 
-	// Extract final SQL
-	if output, ok := agentResult["output"].(string); ok {
-		sql := p.extractSQL(output)
-		return sql, nil
+// reactIterations the ReAct loop's iteration limit (Config.MaxIterations, 0 = default)
+func (p *Pipeline) reactIterations() int {
+	if p.config.MaxIterations > 0 {
+		return p.config.MaxIterations
 	}
+	return defaultReActIterations
+}
 
-	return "", fmt.Errorf("no SQL generated")
+// lastThought the most recent non-empty Thought of the ReAct loop
+func lastThought(steps []CollectedStep) string {
+	for i := len(steps) - 1; i >= 0; i-- {
+		if thought := strings.TrimSpace(steps[i].Thought); thought != "" {
+			return thought
+		}
+	}
+	return ""
 }
 
-// buildPrompt builds prompt
+// buildPrompt builds prompt, dropping low-priority sections to fit Config.MaxPromptTokens
 func (p *Pipeline) buildPrompt(query string, contextPrompt string, crossTableSummary string, isReact bool) string {
+	return p.fitPromptBudget(func(dropped promptSections) string {
+		return p.assemblePrompt(query, contextPrompt, crossTableSummary, isReact, dropped)
+	})
+}
+
+// assemblePrompt assembles the generation prompt without the dropped sections
+func (p *Pipeline) assemblePrompt(query string, contextPrompt string, crossTableSummary string, isReact bool, dropped promptSections) string {
 	var sb strings.Builder
 
 	sb.WriteString("You are a SQL expert. Generate SQL to answer the question.\n\n")
 
-	// Database type info
-	if p.config.DBType != "" {
-		sb.WriteString(fmt.Sprintf("**Database Type: %s**\n", p.config.DBType))
-		sb.WriteString(fmt.Sprintf("CRITICAL: Write SQL that strictly follows %s syntax rules.\n", p.config.DBType))
-		sb.WriteString("Common syntax differences to watch:\n")
-		switch p.config.DBType {
-		case "SQLite":
-			sb.WriteString("- Use double quotes for identifiers if needed, single quotes for strings\n")
-			sb.WriteString("- No LIMIT offset without LIMIT clause\n")
-			sb.WriteString("- Use || for string concatenation\n")
-		case "MySQL":
-			sb.WriteString("- Use backticks for identifiers, single quotes for strings\n")
-			sb.WriteString("- LIMIT syntax: LIMIT offset, count\n")
-			sb.WriteString("- Use CONCAT() for string concatenation\n")
-		case "PostgreSQL":
-			sb.WriteString("- Use double quotes for identifiers, single quotes for strings\n")
-			sb.WriteString("- LIMIT syntax: LIMIT count OFFSET offset\n")
-			sb.WriteString("- Use || for string concatenation\n")
+	// SQL dialect: the model writes one dialect, the transpiler handles the database's syntax
+	if dialect := p.generationDialect(); dialect != "" {
+		name := dialectDisplayName(dialect)
+		sb.WriteString(fmt.Sprintf("**SQL Dialect: %s**\n", name))
+		sb.WriteString(fmt.Sprintf("CRITICAL: Write SQL that strictly follows %s syntax rules.\n", name))
+		if target := p.targetDialect(); target != dialect {
+			sb.WriteString(fmt.Sprintf("The query is translated to %s automatically before it runs; do not use %s-specific syntax.\n", dialectDisplayName(target), dialectDisplayName(target)))
 		}
 		sb.WriteString("\n")
 	}
 
+	// Team style guide (Config.Style)
+	sb.WriteString(p.config.Style.prompt())
+
+	// Data-changing questions (Config.WriteMode)
+	if p.config.WriteMode {
+		sb.WriteString(writeModePrompt)
+	}
+
 	// Rich Context
+	contextPrompt = trimContextPrompt(contextPrompt, dropped)
 	if contextPrompt != "" {
 		sb.WriteString("Database Schema:\n")
 		sb.WriteString(contextPrompt)
@@ -216,7 +206,7 @@ func (p *Pipeline) buildPrompt(query string, contextPrompt string, crossTableSum
 	}
 
 	// Cross-table quality summary (smart injection from full-table analysis)
-	if crossTableSummary != "" {
+	if crossTableSummary != "" && !dropped[sectionCrossTable] {
 		sb.WriteString(crossTableSummary)
 		sb.WriteString("\n")
 	}
@@ -225,9 +215,9 @@ func (p *Pipeline) buildPrompt(query string, contextPrompt string, crossTableSum
 	// These are enhanced hints from onboarding, should not be used in baseline
 	if p.config.UseRichContext {
 		// JOIN paths and field semantics (only in Rich Context mode)
-		if p.context != nil {
-			if joinPathsPrompt := p.context.FormatJoinPathsForPrompt(); joinPathsPrompt != "" {
-				sb.WriteString(joinPathsPrompt)
+		if p.context != nil && !dropped[sectionJoinHints] {
+			if p.joinPathPrompt != "" {
+				sb.WriteString(p.joinPathPrompt)
 			}
 			if fieldSemanticsPrompt := p.context.FormatFieldSemanticsForPrompt(); fieldSemanticsPrompt != "" {
 				sb.WriteString(fieldSemanticsPrompt)
@@ -243,6 +233,32 @@ func (p *Pipeline) buildPrompt(query string, contextPrompt string, crossTableSum
 		}
 	}
 
+	// Glossary terms of the question
+	if p.glossaryPrompt != "" {
+		sb.WriteString(p.glossaryPrompt)
+	}
+
+	// Value hints from the cell-value index
+	if p.valueHintPrompt != "" {
+		sb.WriteString(p.valueHintPrompt)
+	}
+
+	// Few-shot examples (works with or without Rich Context)
+	if p.fewShotPrompt != "" && !dropped[sectionFewShot] {
+		sb.WriteString(p.fewShotPrompt)
+	}
+
+	// Previous turns: the question may build on their SQL
+	if len(p.history) > 0 {
+		sb.WriteString(formatConversation(p.history))
+		sb.WriteString("The question below continues this conversation; reuse and adapt the previous SQL where it applies.\n\n")
+	}
+
+	// Failed attempts at this question (temperature-ladder retries)
+	if p.retryPreamble != "" {
+		sb.WriteString(p.retryPreamble)
+	}
+
 	sb.WriteString(fmt.Sprintf("Question: %s\n\n", query))
 
 	// force mode: mandatory field info in prompt
@@ -259,17 +275,32 @@ func (p *Pipeline) buildPrompt(query string, contextPrompt string, crossTableSum
 
 	if isReact {
 		// Tools available
-		sb.WriteString(`Available Tools:
-- execute_sql: Execute SQL and see results
+		sb.WriteString(`Available Tools:`)
+		if p.toolEnabled("execute_sql") {
+			sb.WriteString(`
+- execute_sql: Execute SQL and see results`)
+		}
+		if p.toolEnabled("verify_sql") {
+			sb.WriteString(`
 - verify_sql: Verify SQL correctness — checks syntax, executes, and reports row count + sample results + warnings`)
-		if p.config.ClarifyMode == "on" {
+		}
+		if p.toolEnabled("verify_result_fields") {
+			sb.WriteString(`
+- verify_result_fields: Check the output columns (count, order, names) against the fields the question asks for`)
+		}
+		if p.toolEnabled("compare_sql") {
+			sb.WriteString(`
+- compare_sql: Compare the results of two SQLs (e.g. draft vs revision) to see whether a change altered the answer`)
+		}
+		if p.config.ClarifyMode == "on" && p.toolEnabled("clarify_fields") {
 			sb.WriteString(`
 - clarify_fields: Ask which fields to return (when question doesn't specify)`)
 		}
-		if p.config.EnableProofread {
+		if p.config.EnableProofread && p.toolEnabled("update_rich_context") {
 			sb.WriteString(`
 - update_rich_context: Update expired/incorrect Rich Context`)
 		}
+		sb.WriteString(p.customToolPrompt())
 
 		// Workflow
 		sb.WriteString(`
@@ -289,10 +320,22 @@ Workflow:
 3. If Rich Context conflicts with actual data → use update_rich_context`)
 		}
 		sb.WriteString(`
-4. Write SQL following best practices
-5. MANDATORY: Use verify_sql to check your SQL before giving Final Answer
-6. If verify_sql reports issues → fix and re-verify
-7. Provide Final Answer
+4. Write SQL following best practices`)
+		if p.toolEnabled("verify_sql") {
+			sb.WriteString(`
+5. MANDATORY: Use verify_sql to check your SQL before giving Final Answer`)
+		}
+		if p.toolEnabled("verify_result_fields") {
+			sb.WriteString(`
+6. Use verify_result_fields to confirm the SELECT list returns exactly the requested fields`)
+		}
+		sb.WriteString(`
+7. If a tool reports issues → fix and re-verify`)
+		if p.toolEnabled("compare_sql") {
+			sb.WriteString(" (use compare_sql to check whether the fix changed the results)")
+		}
+		sb.WriteString(`
+8. Provide Final Answer
 
 `)
 
@@ -305,8 +348,13 @@ A) Use tool:
 
 B) Give answer:
    Thought: [reasoning]
-   Final Answer: [SQL only, no markdown]
-
+`)
+		if p.config.JSONAnswer {
+			sb.WriteString("   Final Answer: " + jsonAnswerFormat + "\n")
+		} else {
+			sb.WriteString("   Final Answer: [SQL only, no markdown]\n")
+		}
+		sb.WriteString(`
 ⚠️ NEVER write "Action: None"! If no tool needed, use option B.
 
 `)
@@ -315,11 +363,19 @@ B) Give answer:
 		sb.WriteString(`Critical Rules:
 1. ONE action per iteration — never output multiple Action/Action Input pairs in a single response
 2. Field Order: SELECT fields MUST match expected order exactly
-3. Iterations: 10 max (update_rich_context doesn't count). Track: "Iteration X/10"
-4. MUST verify: Always call verify_sql before Final Answer
-5. No repetition: If stuck, try different approach
-6. Final Answer: SQL only, no explanations
-7. NEVER give up: Always output a valid SQL query. NEVER output comments, empty strings, or SELECT 0/1.
+`)
+		sb.WriteString(fmt.Sprintf("3. Iterations: %d max, every response counts. Track: \"Iteration X/%d\"\n", p.reactIterations(), p.reactIterations()))
+		if p.toolEnabled("verify_sql") {
+			sb.WriteString(`4. MUST verify: Always call verify_sql before Final Answer
+`)
+		}
+		sb.WriteString("5. No repetition: If stuck, try different approach\n")
+		if p.config.JSONAnswer {
+			sb.WriteString("6. Final Answer: the JSON object only (SQL in \"sql\", one line), no explanations\n")
+		} else {
+			sb.WriteString("6. Final Answer: SQL only, no explanations\n")
+		}
+		sb.WriteString(`7. NEVER give up: Always output a valid SQL query. NEVER output comments, empty strings, or SELECT 0/1.
    If you cannot find the right table or column, write your best-guess query.
 
 `)
@@ -344,6 +400,12 @@ Before Final Answer, verify your SQL returns these EXACT fields in EXACT order:
 6. Clarify: Follow field names/descriptions from clarify_fields precisely
 `)
 		}
+	} else if p.config.JSONAnswer {
+		sb.WriteString(`Task: Generate SQL directly.
+Output ONLY a JSON object (no explanations, no markdown) with the SQL, the tables it uses, and your confidence:
+
+Format:
+` + jsonAnswerFormat)
 	} else {
 		sb.WriteString(`Task: Generate SQL directly.
 Output ONLY the SQL query (no explanations, no markdown).
@@ -362,53 +424,8 @@ func (p *Pipeline) extractSQL(response string) string {
 		response = response[idx+13:]
 	}
 
-	// Clean up
-	response = strings.TrimSpace(response)
-
-	// Remove markdown code blocks
-	response = strings.TrimPrefix(response, "```sql")
-	response = strings.TrimPrefix(response, "```")
-	response = strings.TrimSuffix(response, "```")
-	response = strings.TrimSpace(response)
-
-	// Extract backtick-wrapped SQL
-	if strings.Contains(response, "`SELECT") || strings.Contains(response, "`select") {
-		start := strings.Index(response, "`")
-		if start >= 0 {
-			end := strings.Index(response[start+1:], "`")
-			if end >= 0 {
-				response = response[start+1 : start+1+end]
-			}
-		}
-	}
-
-	// If multi-line response，and first line is SELECT, take first line only
-	lines := strings.Split(response, "\n")
-	if len(lines) > 1 {
-		firstLine := strings.TrimSpace(lines[0])
-		if strings.HasPrefix(strings.ToUpper(firstLine), "SELECT") ||
-			strings.HasPrefix(strings.ToUpper(firstLine), "WITH") ||
-			strings.HasPrefix(strings.ToUpper(firstLine), "INSERT") ||
-			strings.HasPrefix(strings.ToUpper(firstLine), "UPDATE") ||
-			strings.HasPrefix(strings.ToUpper(firstLine), "DELETE") {
-			// Find SQL statement end (non-SQL content)
-			var sqlLines []string
-			for _, line := range lines {
-				trimmed := strings.TrimSpace(line)
-				// If explanatory text encountered (e.g. "This query"), stop
-				if strings.HasPrefix(trimmed, "This ") ||
-					strings.HasPrefix(trimmed, "The ") ||
-					strings.HasPrefix(trimmed, "Since ") ||
-					strings.HasPrefix(trimmed, "Note:") {
-					break
-				}
-				sqlLines = append(sqlLines, line)
-			}
-			response = strings.Join(sqlLines, "\n")
-		}
-	}
-
-	result := strings.TrimSpace(response)
+	// Markdown, surrounding prose, unbalanced quotes, comments, whitespace
+	result := CleanSQL(response, p.generationDialect())
 
 	// Sanitize give-up patterns: if LLM output a comment, placeholder, or empty string,
 	// try to extract any SELECT statement from the full response as fallback
@@ -474,9 +491,7 @@ func (p *Pipeline) extractFallbackSQL(response string) string {
 		sqlLines = append(sqlLines, line)
 	}
 
-	sql := strings.TrimSpace(strings.Join(sqlLines, "\n"))
-	sql = strings.TrimSuffix(sql, ";")
-	sql = strings.TrimSpace(sql)
+	sql := CleanSQL(strings.Join(sqlLines, "\n"), p.generationDialect())
 
 	if sql != "" && !p.isGiveUpSQL(sql) {
 		return sql
@@ -498,6 +513,8 @@ type SQLTool struct {
 	useDryRun      bool
 	ExecutionCount int
 	logger         *InferenceLogger
+
+	lastValid string // Last query that executed (best SQL so far when the loop is interrupted)
 }
 
 func (t *SQLTool) Name() string {
@@ -544,13 +561,15 @@ func (t *SQLTool) Call(ctx context.Context, input string) (string, error) {
 		return fmt.Sprintf("SQL execution failed: %v", err), nil
 	}
 
+	t.lastValid = sql
+
 	// Format results
 	output := fmt.Sprintf("Query executed successfully!\nRows: %d\n", result.RowCount)
 
 	// Decide display based on char length not row count
 	// Serialize result and check length
 	if result.RowCount > 0 {
-		sampleStr := fmt.Sprintf("%v", result.Rows)
+		sampleStr := adapter.FormatRows(result.Columns, result.Values, 0)
 		const maxSampleLength = 1000 // max display 1000 chars
 
 		if len(sampleStr) <= maxSampleLength {
@@ -571,10 +590,10 @@ func (t *SQLTool) Call(ctx context.Context, input string) (string, error) {
 
 // ClarifyTool tool for asking which fields to return
 type ClarifyTool struct {
-	resultFields            []string
-	resultFieldsDescription string
-	ClarifyCount            int
-	logger                  *InferenceLogger
+	backend      ClarifyBackend
+	query        string
+	ClarifyCount int
+	logger       *InferenceLogger
 }
 
 func (t *ClarifyTool) Name() string {
@@ -601,11 +620,14 @@ func (t *ClarifyTool) Call(ctx context.Context, input string) (string, error) {
 
 	logf("\n🔔 Clarification requested: %s\n", input)
 
-	// Return field list + descriptions
-	fieldsStr := strings.Join(t.resultFields, ", ")
-	response := fmt.Sprintf("Required fields in EXACT ORDER: %s\n\nField descriptions: %s\n\nIMPORTANT: Use these field names WITHOUT table prefixes (e.g., 'Name' not 'singer.Name')",
-		fieldsStr,
-		t.resultFieldsDescription)
+	response, err := t.backend.Clarify(ctx, ClarifyRequest{Query: t.query, Question: strings.TrimSpace(input)})
+	if err != nil {
+		logf("⚠️  Clarification failed: %v\n\n", err)
+		return fmt.Sprintf("No clarification available (%v). Proceed with your best interpretation of the question.", err), nil
+	}
+	if response == "" {
+		response = "No answer given. Proceed with your best interpretation of the question."
+	}
 
 	logf("📋 Clarification response: %s\n\n", response)
 
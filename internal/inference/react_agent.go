@@ -0,0 +1,167 @@
+package inference
+
+import (
+	"strings"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// reactAgent ReAct controller and tools, built once per Pipeline and reused across queries
+// Per-query state (question, counters, collected steps) is reset by prepare.
+type reactAgent struct {
+	controller *reactController
+	toolsKey   reactToolsKey // Config the controller's tool list was built for
+	handler    *PrettyReActHandler
+
+	sqlTool          *SQLTool
+	clarifyTool      *ClarifyTool
+	verifyFieldsTool *VerifyResultFieldsTool
+	updateTool       *UpdateRichContextTool
+	timedClarify     *timedTool
+	timedProofread   *timedTool
+	loopGuard        *loopGuard
+}
+
+// reactToolsKey the inputs of the controller and its tool list; the adapter and model are
+// fixed for a Pipeline, so only Config edits, SetLogger and RegisterTool change it
+type reactToolsKey struct {
+	logger      *InferenceLogger
+	dbType      string
+	clarify     bool
+	proofread   bool
+	disabled    string
+	customTools int
+	iterations  int
+}
+
+// reactToolsKey key of the current Config
+func (p *Pipeline) reactToolsKey() reactToolsKey {
+	return reactToolsKey{
+		logger:      p.Logger,
+		dbType:      p.config.DBType,
+		clarify:     p.config.ClarifyMode == "on",
+		proofread:   p.config.EnableProofread,
+		disabled:    strings.Join(p.config.DisabledTools, ","),
+		customTools: len(p.customTools),
+		iterations:  p.reactIterations(),
+	}
+}
+
+// reactAgentFor returns the cached ReAct agent prepared for query
+// The controller and tool list are rebuilt only when their reactToolsKey changes.
+func (p *Pipeline) reactAgentFor(query string) *reactAgent {
+	a := p.reactAgent
+	if a == nil {
+		a = p.newReactAgentTools()
+		p.reactAgent = a
+	}
+	if key := p.reactToolsKey(); a.controller == nil || a.toolsKey != key {
+		a.controller = newReactController(p.llm, p.reactTools(a), a.handler, p.reactIterations())
+		a.toolsKey = key
+	}
+	a.prepare(p, query)
+	return a
+}
+
+// newReactAgentTools creates the built-in tools
+func (p *Pipeline) newReactAgentTools() *reactAgent {
+	a := &reactAgent{
+		sqlTool:          &SQLTool{},
+		clarifyTool:      &ClarifyTool{},
+		verifyFieldsTool: NewVerifyResultFieldsTool(p.queryAdapter, nil),
+		updateTool:       NewUpdateRichContextTool(p.config.DBName, p.config.DBType, p.config.Benchmark),
+		handler:          &PrettyReActHandler{},
+	}
+	// Clarify and proofread tool time is reported as its own phase
+	a.timedClarify = &timedTool{Tool: a.clarifyTool}
+	a.timedProofread = &timedTool{Tool: a.updateTool}
+	a.loopGuard = &loopGuard{}
+	return a
+}
+
+// reactTools the tools enabled by the current Config
+func (p *Pipeline) reactTools(a *reactAgent) []tools.Tool {
+	verifySQLTool := NewVerifySQLTool(p.queryAdapter, p.config.DBType)
+	verifySQLTool.logger = p.Logger
+
+	compareTool := NewCompareSQLTool(p.queryAdapter)
+	compareTool.logger = p.Logger
+
+	toolsList := []tools.Tool{a.sqlTool, verifySQLTool, a.verifyFieldsTool, compareTool}
+	if p.config.ClarifyMode == "on" {
+		toolsList = append(toolsList, a.timedClarify)
+	}
+	if p.config.EnableProofread {
+		toolsList = append(toolsList, a.timedProofread)
+	}
+
+	// Registered domain tools; Config.DisabledTools applies to all
+	// Every call goes through the loop guard (repeated calls get an intervention)
+	return a.loopGuard.guard(p.enabledTools(toolsList))
+}
+
+// prepare resets per-query state and applies the current Config to the tools
+func (a *reactAgent) prepare(p *Pipeline, query string) {
+	a.sqlTool.adapter = p.queryAdapter
+	a.sqlTool.useDryRun = p.config.UseDryRun
+	a.sqlTool.logger = p.Logger
+	a.sqlTool.ExecutionCount = 0
+	a.sqlTool.lastValid = ""
+
+	a.clarifyTool.backend = p.clarifyBackend
+	if a.clarifyTool.backend == nil {
+		// Benchmark mode: answer with the pre-computed result fields
+		a.clarifyTool.backend = &StaticClarifyBackend{
+			Fields:      p.config.ResultFields,
+			Description: p.config.ResultFieldsDescription,
+		}
+	}
+	a.clarifyTool.query = query
+	a.clarifyTool.logger = p.Logger
+	a.clarifyTool.ClarifyCount = 0
+
+	// Expected fields only when clarify mode may reveal them
+	// (ResultFields are derived from the gold SQL in benchmarks)
+	a.verifyFieldsTool.adapter = p.queryAdapter
+	a.verifyFieldsTool.resultFields = nil
+	if p.config.ClarifyMode == "on" || p.config.ClarifyMode == "force" {
+		a.verifyFieldsTool.resultFields = p.config.ResultFields
+	}
+	a.verifyFieldsTool.logger = p.Logger
+
+	a.updateTool.logger = p.Logger
+	a.updateTool.shared = p.context
+	a.updateTool.persist = p.config.PersistProofread
+	if p.config.ContextFile != "" {
+		a.updateTool.contextPath = p.config.ContextFile
+	}
+
+	a.timedClarify.calls, a.timedClarify.elapsed = 0, 0
+	a.timedProofread.calls, a.timedProofread.elapsed = 0, 0
+
+	limit := p.config.MaxLoopInterventions
+	if limit == 0 {
+		limit = defaultLoopInterventions
+	}
+	a.loopGuard.reset(p.generationDialect(), limit, p.Logger)
+
+	a.handler.reset()
+	a.handler.logMode = p.config.LogMode
+	a.handler.logger = p.Logger
+
+	// Streaming callback (for real-time step notifications)
+	var notifier StepNotifier
+	if p.stepCallback != nil {
+		notifier = func(step CollectedStep, eventType string) {
+			p.stepCallback(ReActStep{
+				Step:        step.Step,
+				Thought:     step.Thought,
+				Action:      step.Action,
+				ActionInput: step.ActionInput,
+				Observation: step.Observation,
+				Phase:       "sql_generation",
+			}, eventType)
+		}
+	}
+	a.handler.SetStepNotifier(notifier)
+}
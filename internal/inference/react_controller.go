@@ -0,0 +1,299 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// defaultReActIterations model calls a ReAct loop may make when Config.MaxIterations is unset
+const defaultReActIterations = 10
+
+// maxReActFormatErrors consecutive unparseable responses before the loop gives up
+const maxReActFormatErrors = 2
+
+var (
+	// errReActIterations the loop used all its iterations without a final answer
+	errReActIterations = errors.New("react loop reached its iteration limit")
+	// errReActFormat the model's responses could not be parsed as ReAct steps
+	errReActFormat = errors.New("unable to parse ReAct output")
+)
+
+// reactStopWords end a response where the model would start inventing the observation
+var reactStopWords = []string{"\nObservation:", "\n\tObservation:"}
+
+// reactPromptTemplate tool list and step format around the task prompt
+const reactPromptTemplate = `Answer the following questions as best you can. You have access to the following tools:
+
+%s
+Use the following format:
+
+Question: the input question you must answer
+Thought: you should always think about what to do
+Action: the action to take, should be one of [ %s ]
+Action Input: the input to the action
+Observation: the result of the action
+... (this Thought/Action/Action Input/Observation can repeat N times)
+Thought: I now know the final answer
+Final Answer: the final answer to the original input question
+
+Begin!
+
+Question: %s
+`
+
+// reactAction one tool call parsed from a model response
+type reactAction struct {
+	Tool  string
+	Input string
+}
+
+// reactTurn one parsed model response: tool calls, or the final answer
+type reactTurn struct {
+	Thought  string
+	Actions  []reactAction
+	Final    string
+	Finished bool
+	Log      string // Response text kept in the scratchpad (invented observations cut)
+}
+
+// reactController runs the Thought / Action / Observation loop over a set of tools
+// Each iteration is one model call; the tool calls it asks for run in order and their
+// observations are appended to the scratchpad for the next call.
+type reactController struct {
+	llm           llms.Model
+	tools         []tools.Tool
+	handler       *PrettyReActHandler
+	maxIterations int
+}
+
+// newReactController creates a controller allowing maxIterations model calls (<=0 = default)
+func newReactController(llm llms.Model, toolsList []tools.Tool, handler *PrettyReActHandler, maxIterations int) *reactController {
+	if maxIterations <= 0 {
+		maxIterations = defaultReActIterations
+	}
+	return &reactController{llm: llm, tools: toolsList, handler: handler, maxIterations: maxIterations}
+}
+
+// RunReAct runs one ReAct loop over toolsList and returns the final answer
+// For callers outside the pipeline (the context generation agents): no step log is kept,
+// and maxIterations <= 0 uses the default.
+func RunReAct(ctx context.Context, llm llms.Model, toolsList []tools.Tool, maxIterations int, input string) (string, error) {
+	return newReactController(llm, toolsList, nil, maxIterations).run(ctx, input)
+}
+
+// prompt the first model input: tool list, format and the task
+func (c *reactController) prompt(input string) string {
+	var descriptions strings.Builder
+	names := make([]string, len(c.tools))
+	for i, tool := range c.tools {
+		names[i] = tool.Name()
+		descriptions.WriteString(fmt.Sprintf("- %s: %s\n", tool.Name(), tool.Description()))
+	}
+	return fmt.Sprintf(reactPromptTemplate, descriptions.String(), strings.Join(names, ", "), input)
+}
+
+// run drives the loop and returns the final answer
+// Errors: errReActIterations when the limit is reached (the steps stay in the handler),
+// errReActFormat after repeated unparseable responses, and any tool or model error.
+func (c *reactController) run(ctx context.Context, input string, opts ...llms.CallOption) (string, error) {
+	prompt := c.prompt(input)
+	callOpts := append([]llms.CallOption{llms.WithStopWords(reactStopWords)}, opts...)
+
+	var scratchpad strings.Builder
+	formatErrors := 0
+	for iteration := 1; iteration <= c.maxIterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		text := prompt + scratchpad.String()
+		c.handler.startIteration(iteration, c.maxIterations, text)
+		response, err := llms.GenerateFromSinglePrompt(ctx, c.llm, text, callOpts...)
+		if err != nil {
+			c.handler.recordError(err)
+			return "", fmt.Errorf("failed to call LLM: %w", err)
+		}
+
+		turn, err := parseReActTurn(response)
+		c.handler.recordResponse(response, turn.Thought)
+		if err != nil {
+			formatErrors++
+			if formatErrors >= maxReActFormatErrors {
+				c.handler.recordError(err)
+				return "", err
+			}
+			observation := "Invalid format: reply with Thought, Action and Action Input lines to use a tool, or Thought and Final Answer to finish."
+			c.handler.recordObservation(observation)
+			scratchpad.WriteString("\n" + turn.Log + "\nObservation: " + observation + "\n")
+			continue
+		}
+		formatErrors = 0
+
+		if turn.Finished {
+			c.handler.recordFinish(turn.Final)
+			return turn.Final, nil
+		}
+
+		scratchpad.WriteString("\n" + turn.Log)
+		for i, action := range turn.Actions {
+			c.handler.recordAction(action.Tool, action.Input)
+			observation, err := c.call(ctx, action)
+			if err != nil {
+				c.handler.recordError(err)
+				return "", err
+			}
+			if i == len(turn.Actions)-1 && iteration == c.maxIterations-1 {
+				observation += "\n(Only one iteration left: your next response must be the Final Answer.)"
+			}
+			c.handler.recordObservation(observation)
+			scratchpad.WriteString("\nObservation: " + observation + "\n")
+		}
+	}
+
+	c.handler.recordLimit()
+	return "", errReActIterations
+}
+
+// call runs the named tool; an unknown tool is reported back to the model
+func (c *reactController) call(ctx context.Context, action reactAction) (string, error) {
+	names := make([]string, len(c.tools))
+	for i, tool := range c.tools {
+		if strings.EqualFold(tool.Name(), action.Tool) {
+			return tool.Call(ctx, action.Input)
+		}
+		names[i] = tool.Name()
+	}
+	return fmt.Sprintf("%s is not a valid tool, use one of [ %s ]", action.Tool, strings.Join(names, ", ")), nil
+}
+
+// reactSection text following one marker of a response ("" marker = text before any)
+type reactSection struct {
+	marker string
+	text   string
+}
+
+// reactMarkers line prefixes of a ReAct response, longest first
+var reactMarkers = []string{"action input", "final answer", "observation", "thought", "action"}
+
+// parseReActTurn parses a model response
+// Tolerates markdown emphasis around markers, a missing "Thought:" (leading prose is the
+// thought), several Action / Action Input pairs, code-fenced inputs, prose after an input
+// (cut at the first blank line) and invented observations (cut).
+func parseReActTurn(response string) (reactTurn, error) {
+	sections := splitReActSections(strings.TrimSpace(response))
+	turn := reactTurn{Log: cutObservation(response)}
+
+	for i := 0; i < len(sections) && sections[i].marker != "observation"; i++ {
+		section := sections[i]
+		switch section.marker {
+		case "", "thought":
+			if turn.Thought == "" {
+				turn.Thought = strings.TrimSpace(section.text)
+			}
+		case "final answer":
+			if len(turn.Actions) == 0 {
+				turn.Final = strings.TrimSpace(section.text)
+				turn.Finished = true
+				return turn, nil
+			}
+		case "action":
+			action := reactAction{Tool: cleanToolName(firstLine(section.text))}
+			if i+1 < len(sections) && sections[i+1].marker == "action input" {
+				action.Input = cleanActionInput(sections[i+1].text)
+				i++
+			} else if open := strings.IndexByte(action.Tool, '('); open > 0 && strings.HasSuffix(action.Tool, ")") {
+				// Action: execute_sql(SELECT ...)
+				action.Tool, action.Input = action.Tool[:open], cleanActionInput(action.Tool[open+1:len(action.Tool)-1])
+			}
+			if action.Tool == "" || strings.EqualFold(action.Tool, "none") {
+				continue
+			}
+			turn.Actions = append(turn.Actions, action)
+		}
+	}
+
+	if len(turn.Actions) == 0 {
+		return turn, fmt.Errorf("%w: %s", errReActFormat, truncate(response, 200))
+	}
+	return turn, nil
+}
+
+// splitReActSections splits a response at its marker lines
+func splitReActSections(text string) []reactSection {
+	sections := []reactSection{{}}
+	for _, line := range strings.Split(text, "\n") {
+		if marker, rest, ok := reactMarkerLine(line); ok {
+			sections = append(sections, reactSection{marker: marker, text: rest})
+			continue
+		}
+		last := &sections[len(sections)-1]
+		if last.text != "" || last.marker != "" {
+			last.text += "\n"
+		}
+		last.text += line
+	}
+	if strings.TrimSpace(sections[0].text) == "" {
+		sections = sections[1:]
+	}
+	return sections
+}
+
+// reactMarkerLine reports whether line starts with a marker ("**Action:** x", "Thought: y")
+func reactMarkerLine(line string) (marker, rest string, ok bool) {
+	trimmed := strings.TrimLeft(line, " \t*#>")
+	lower := strings.ToLower(trimmed)
+	for _, marker := range reactMarkers {
+		if !strings.HasPrefix(lower, marker) {
+			continue
+		}
+		after := strings.TrimLeft(trimmed[len(marker):], " *")
+		if !strings.HasPrefix(after, ":") {
+			continue
+		}
+		return marker, strings.TrimSpace(strings.TrimLeft(after[1:], "*")), true
+	}
+	return "", "", false
+}
+
+// cutObservation the response up to an observation the model made up
+func cutObservation(response string) string {
+	lines := strings.Split(strings.TrimSpace(response), "\n")
+	for i, line := range lines {
+		if marker, _, ok := reactMarkerLine(line); ok && marker == "observation" {
+			return strings.TrimSpace(strings.Join(lines[:i], "\n"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cleanToolName strips quoting and brackets around a tool name
+func cleanToolName(name string) string {
+	return strings.Trim(strings.TrimSpace(name), "`'\"[]* ")
+}
+
+// cleanActionInput the tool input without code fences, quotes or trailing prose
+func cleanActionInput(input string) string {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "```") {
+		body := input[3:]
+		if nl := strings.IndexByte(body, '\n'); nl >= 0 {
+			body = body[nl+1:]
+		}
+		if end := strings.Index(body, "```"); end >= 0 {
+			body = body[:end]
+		}
+		return strings.TrimSpace(body)
+	}
+	if blank := strings.Index(input, "\n\n"); blank >= 0 {
+		input = strings.TrimSpace(input[:blank])
+	}
+	if len(input) >= 2 && strings.IndexByte("`\"", input[0]) >= 0 && input[len(input)-1] == input[0] && !strings.ContainsRune(input, '\n') {
+		input = strings.TrimSpace(input[1 : len(input)-1])
+	}
+	return input
+}
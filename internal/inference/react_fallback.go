@@ -0,0 +1,54 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Generation paths reported in Result.GenerationPath
+const (
+	PathReAct         = "react"
+	PathOneShot       = "one_shot"
+	PathFallback      = "one_shot_fallback" // ReAct failed, one-shot produced the SQL
+	PathDecomposition = "decomposition"
+)
+
+// generateReAct runs the ReAct loop; when the loop fails (unparseable output, provider
+// error) or ends without SQL, one-shot generation gets the same context before the
+// generation counts as failed
+func (p *Pipeline) generateReAct(ctx context.Context, query, contextPrompt, crossTableSummary string, result *Result, opts ...llms.CallOption) (string, error) {
+	sql, err := p.reactLoop(ctx, query, contextPrompt, crossTableSummary, result, opts...)
+	if err == nil && sql != "" {
+		result.GenerationPath = PathReAct
+		return sql, nil
+	}
+	if ctx.Err() != nil {
+		return sql, err
+	}
+
+	reason := "ReAct loop returned no SQL"
+	if err != nil {
+		reason = err.Error()
+	}
+	p.Logger.Printf("↩️  ReAct failed (%s), falling back to one-shot generation\n", truncate(reason, 200))
+	step := ReActStep{
+		Thought: reason,
+		Action:  "fallback_one_shot",
+		Phase:   "sql_generation",
+	}
+	result.ReActSteps = append(result.ReActSteps, step)
+	p.notifyStep(step, "action")
+
+	fallbackSQL, fallbackErr := p.oneShotGeneration(ctx, query, contextPrompt, crossTableSummary, opts...)
+	result.LLMCalls++
+	if fallbackErr != nil {
+		if err == nil {
+			return sql, fmt.Errorf("one-shot fallback failed: %w", fallbackErr)
+		}
+		return "", fmt.Errorf("ReAct failed (%v), one-shot fallback failed: %w", err, fallbackErr)
+	}
+	result.GenerationPath = PathFallback
+	return fallbackSQL, nil
+}
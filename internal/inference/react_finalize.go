@@ -0,0 +1,80 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxFinalizeObservation observation length kept per step in the finalization prompt
+const maxFinalizeObservation = 1500
+
+// forceFinalAnswer asks for the best SQL after the ReAct loop ran out of iterations (or kept
+// repeating itself; reason says which). The prompt replays the loop's steps so the model can
+// reuse the SQL it already verified.
+func (p *Pipeline) forceFinalAnswer(ctx context.Context, reactPrompt string, steps []CollectedStep, result *Result, reason string) (string, error) {
+	prompt := buildFinalizePrompt(reactPrompt, steps, p.config.JSONAnswer)
+
+	response, err := p.llm.Call(ctx, prompt)
+	result.LLMCalls++
+	if err != nil {
+		p.Logger.Printf("❌ Forced final answer failed: %v\n\n", err)
+		return "", fmt.Errorf("failed to force final answer: %w", err)
+	}
+	p.promptTexts = append(p.promptTexts, prompt)
+	p.responseTexts = append(p.responseTexts, response)
+
+	sql := p.extractAnswer(response)
+	result.ForcedFinalAnswer = true
+	step := ReActStep{
+		Thought:     reason,
+		Action:      "force_final_answer",
+		Observation: sql,
+		Phase:       "sql_generation",
+	}
+	result.ReActSteps = append(result.ReActSteps, step)
+	p.notifyStep(step, "observation")
+
+	if p.isGiveUpSQL(sql) {
+		p.Logger.Println("❌ No SQL in the forced final answer")
+		return "", fmt.Errorf("no SQL in the forced final answer (%s)", strings.ToLower(reason))
+	}
+	p.Logger.Printf("✅ Forced final answer: %s\n\n", sql)
+	return sql, nil
+}
+
+// buildFinalizePrompt original task + the steps taken + the instruction to answer now
+func buildFinalizePrompt(reactPrompt string, steps []CollectedStep, jsonAnswer bool) string {
+	var sb strings.Builder
+	sb.WriteString(reactPrompt)
+	sb.WriteString("\n\n## Steps Taken So Far\n")
+	for i, step := range steps {
+		sb.WriteString(fmt.Sprintf("\nStep %d\n", i+1))
+		if step.Thought != "" {
+			sb.WriteString("Thought: " + step.Thought + "\n")
+		}
+		if step.Action != "" {
+			sb.WriteString("Action: " + step.Action + "\n")
+		}
+		if input := formatActionInput(step.ActionInput); input != "" {
+			sb.WriteString("Action Input: " + input + "\n")
+		}
+		if step.Observation != "" {
+			observation := step.Observation
+			if len(observation) > maxFinalizeObservation {
+				observation = observation[:maxFinalizeObservation] + "\n... (truncated)"
+			}
+			sb.WriteString("Observation: " + observation + "\n")
+		}
+	}
+
+	sb.WriteString("\n## You Are Out of Iterations\n")
+	sb.WriteString("No more tool calls are allowed. Output your best SQL now, using what the steps above verified.\n")
+	sb.WriteString("Even if you are unsure, give the most likely query; never answer with an empty query or a comment.\n\n")
+	if jsonAnswer {
+		sb.WriteString("Final Answer: " + jsonAnswerFormat + "\n")
+	} else {
+		sb.WriteString("Final Answer: [SQL only, no markdown]\n")
+	}
+	return sb.String()
+}
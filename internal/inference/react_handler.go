@@ -1,25 +1,20 @@
 package inference
 
 import (
-	"context"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/schema"
 )
 
 // StepNotifier is called when a ReAct step is updated (can be partial)
 type StepNotifier func(step CollectedStep, eventType string)
 
-// PrettyReActHandler enhanced ReAct log handler with step collection
+// PrettyReActHandler ReAct loop log and step collection, fed by reactController
+// A nil handler records nothing (RunReAct).
 type PrettyReActHandler struct {
-	iterationCount          int // total iterations (including all tool calls)
-	effectiveIterationCount int // effective iterations (excluding update_rich_context)
-	lastAction              string
-	logMode                 string // log mode: "simple" or "full"
+	iterationCount int    // model calls so far
+	logMode        string // log mode: "simple" or "full"
 
 	// Step collection for frontend visualization
 	mu            sync.Mutex
@@ -29,6 +24,9 @@ type PrettyReActHandler struct {
 	// Streaming callback for real-time step notifications
 	stepNotifier StepNotifier
 
+	// Prompt inputs and responses of every iteration (token accounting)
+	llmTexts []string
+
 	// Logger for dual output (stdout + file)
 	logger *InferenceLogger
 }
@@ -40,6 +38,16 @@ func (h *PrettyReActHandler) SetStepNotifier(notifier StepNotifier) {
 	h.stepNotifier = notifier
 }
 
+// reset clears the steps and counters of the previous run (the handler is reused across queries)
+func (h *PrettyReActHandler) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.iterationCount = 0
+	h.collectedSteps = nil
+	h.currentStep = nil
+	h.llmTexts = nil
+}
+
 // CollectedStep represents a collected ReAct step
 type CollectedStep struct {
 	Step        int         `json:"step"`
@@ -65,6 +73,13 @@ func (h *PrettyReActHandler) GetCollectedSteps() []CollectedStep {
 	return h.collectedSteps
 }
 
+// LLMTexts returns the prompt inputs and responses seen so far
+func (h *PrettyReActHandler) LLMTexts() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.llmTexts
+}
+
 // finalizeCurrentStep finalizes the current step and notifies
 func (h *PrettyReActHandler) finalizeCurrentStep() {
 	if h.currentStep != nil && (h.currentStep.Action != "" || h.currentStep.Thought != "") {
@@ -80,24 +95,12 @@ func (h *PrettyReActHandler) notifyStepUpdate(eventType string) {
 	}
 }
 
-var _ interface {
-	HandleText(ctx context.Context, text string)
-	HandleLLMStart(ctx context.Context, prompts []string)
-	HandleLLMGenerateContentStart(ctx context.Context, ms []llms.MessageContent)
-	HandleLLMGenerateContentEnd(ctx context.Context, res *llms.ContentResponse)
-	HandleLLMError(ctx context.Context, err error)
-	HandleChainStart(ctx context.Context, inputs map[string]any)
-	HandleChainEnd(ctx context.Context, outputs map[string]any)
-	HandleChainError(ctx context.Context, err error)
-	HandleToolStart(ctx context.Context, input string)
-	HandleToolEnd(ctx context.Context, output string)
-	HandleToolError(ctx context.Context, err error)
-	HandleAgentAction(ctx context.Context, action schema.AgentAction)
-	HandleAgentFinish(ctx context.Context, finish schema.AgentFinish)
-	HandleRetrieverStart(ctx context.Context, query string)
-	HandleRetrieverEnd(ctx context.Context, query string, documents []schema.Document)
-	HandleStreamingFunc(ctx context.Context, chunk []byte)
-} = &PrettyReActHandler{}
+// Iterations returns the model calls of the current run
+func (h *PrettyReActHandler) Iterations() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.iterationCount
+}
 
 func (h *PrettyReActHandler) logPrintf(format string, a ...interface{}) {
 	if h.logger != nil {
@@ -115,189 +118,149 @@ func (h *PrettyReActHandler) logPrintln(a ...interface{}) {
 	}
 }
 
-func (h *PrettyReActHandler) HandleText(_ context.Context, text string) {}
-
-func (h *PrettyReActHandler) HandleLLMStart(_ context.Context, prompts []string) {
-	if h.logMode == "full" {
-		h.logPrintln("\n" + strings.Repeat("=", 80))
-		h.logPrintln("📤 LLM Prompt (Full)")
-		h.logPrintln(strings.Repeat("=", 80))
-		for i, prompt := range prompts {
-			h.logPrintf("Prompt %d:\n%s\n", i+1, prompt)
-		}
-		h.logPrintln(strings.Repeat("=", 80))
+// logBlock prints text line by line inside the iteration box
+func (h *PrettyReActHandler) logBlock(title, text string) {
+	h.logPrintf("│ %s\n", title)
+	for _, line := range strings.Split(text, "\n") {
+		h.logPrintf("│   %s\n", line)
 	}
 }
 
-func (h *PrettyReActHandler) HandleLLMGenerateContentStart(_ context.Context, ms []llms.MessageContent) {
-}
+// startIteration begins step collection for model call n of max
+func (h *PrettyReActHandler) startIteration(n, max int, prompt string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	h.iterationCount = n
+	h.finalizeCurrentStep()
+	h.currentStep = &CollectedStep{
+		Step:      n,
+		Timestamp: time.Now(),
+	}
+	h.llmTexts = append(h.llmTexts, prompt)
+	h.mu.Unlock()
 
-func (h *PrettyReActHandler) HandleLLMGenerateContentEnd(_ context.Context, res *llms.ContentResponse) {
+	h.logPrintf("\n┌─ Iteration %d/%d ─────────────────────────────────────────────────────\n", n, max)
 	if h.logMode == "full" {
 		h.logPrintln("\n" + strings.Repeat("=", 80))
-		h.logPrintln("📥 LLM Response (Full)")
+		h.logPrintln("📤 LLM Prompt (Full)")
 		h.logPrintln(strings.Repeat("=", 80))
-		for i, choice := range res.Choices {
-			h.logPrintf("Choice %d:\n%s\n", i+1, choice.Content)
-		}
+		h.logPrintln(prompt)
 		h.logPrintln(strings.Repeat("=", 80))
 	}
 }
 
-func (h *PrettyReActHandler) HandleLLMError(_ context.Context, err error) {
-	h.logPrintf("❌ LLM Error: %v\n", err)
-}
-
-func (h *PrettyReActHandler) HandleChainStart(_ context.Context, inputs map[string]any) {
-	// Each chain start represents a new iteration
-	h.iterationCount++
-
-	// If last action was not update_rich_context, increment effective count
-	if h.lastAction != "" && h.lastAction != "update_rich_context" {
-		h.effectiveIterationCount++
+// recordResponse records the model's response and its Thought
+func (h *PrettyReActHandler) recordResponse(response, thought string) {
+	if h == nil {
+		return
 	}
-
-	// Display iteration info
-	if h.effectiveIterationCount > 0 {
-		h.logPrintf("\n┌─ Iteration %d (Effective: %d/5) ─────────────────────────────────────────────\n", h.iterationCount, h.effectiveIterationCount)
-	} else {
-		h.logPrintf("\n┌─ Iteration %d ─────────────────────────────────────────────────────\n", h.iterationCount)
-	}
-	// Start collecting a new step
 	h.mu.Lock()
-	// Save previous step if exists and has content
-	h.finalizeCurrentStep()
-	h.currentStep = &CollectedStep{
-		Step:      h.iterationCount,
-		Timestamp: time.Now(),
+	h.llmTexts = append(h.llmTexts, response)
+	if h.currentStep != nil {
+		h.currentStep.Thought = thought
 	}
+	// Notify about thought immediately (real-time streaming)
+	h.notifyStepUpdate("thought")
 	h.mu.Unlock()
-}
 
-func (h *PrettyReActHandler) HandleChainEnd(_ context.Context, outputs map[string]any) {
-	// Extract LLM response
-	if text, ok := outputs["text"].(string); ok {
-		// Extract thought from response
-		thought := extractThought(text)
-		
-		h.mu.Lock()
-		if h.currentStep != nil {
-			h.currentStep.Thought = thought
-		}
-		// Notify about thought immediately (real-time streaming)
-		h.notifyStepUpdate("thought")
-		h.mu.Unlock()
-
-		// full mode: output full response
-		if h.logMode == "full" {
-			h.logPrintf("│ 📝 Full Response:\n")
-			for _, line := range strings.Split(text, "\n") {
-				h.logPrintf("│   %s\n", line)
-			}
-		} else {
-			// simple mode: show Thought summary only
-			if thought != "" {
-				h.logPrintf("│ 💭 Thought: %s\n", truncate(thought, 120))
-			}
-		}
+	if h.logMode == "full" {
+		h.logBlock("📝 Full Response:", response)
+	} else if thought != "" {
+		// simple mode: show Thought summary only
+		h.logPrintf("│ 💭 Thought: %s\n", truncate(thought, 120))
 	}
 }
 
-func (h *PrettyReActHandler) HandleChainError(_ context.Context, err error) {
-	h.logPrintf("│ ❌ Chain Error: %v\n", err)
-}
-
-func (h *PrettyReActHandler) HandleToolStart(_ context.Context, input string) {}
-
-func (h *PrettyReActHandler) HandleToolEnd(_ context.Context, output string) {
-	// Collect observation
+// recordAction records a tool call; further calls of the same response become their own steps
+func (h *PrettyReActHandler) recordAction(tool, input string) {
+	if h == nil {
+		return
+	}
 	h.mu.Lock()
+	if h.currentStep != nil && h.currentStep.Action != "" {
+		h.finalizeCurrentStep()
+		h.currentStep = &CollectedStep{
+			Step:      h.currentStep.Step,
+			Thought:   h.currentStep.Thought,
+			Timestamp: time.Now(),
+		}
+	}
 	if h.currentStep != nil {
-		h.currentStep.Observation = output
+		h.currentStep.Action = tool
+		h.currentStep.ActionInput = input
 	}
-	// Notify about observation immediately (real-time streaming)
-	h.notifyStepUpdate("observation")
+	// Notify about action immediately (real-time streaming)
+	h.notifyStepUpdate("action")
 	h.mu.Unlock()
 
-	// full mode: show tool output
+	h.logPrintf("│ 🎯 Action: %s\n", tool)
 	if h.logMode == "full" {
-		h.logPrintf("│ 📤 Tool Output (Full):\n")
-		for _, line := range strings.Split(output, "\n") {
-			h.logPrintf("│   %s\n", line)
-		}
+		h.logBlock("📥 Input (Full):", input)
+	} else {
+		h.logPrintf("│ 📥 Input: %s\n", truncate(input, 100))
 	}
 }
 
-func (h *PrettyReActHandler) HandleToolError(_ context.Context, err error) {
-	h.logPrintf("│ ❌ Tool Error: %v\n", err)
-	
+// recordObservation records the result of the current tool call
+func (h *PrettyReActHandler) recordObservation(observation string) {
+	if h == nil {
+		return
+	}
 	h.mu.Lock()
 	if h.currentStep != nil {
-		h.currentStep.Observation = fmt.Sprintf("Error: %v", err)
+		h.currentStep.Observation = observation
 	}
+	// Notify about observation immediately (real-time streaming)
+	h.notifyStepUpdate("observation")
 	h.mu.Unlock()
-}
 
-func (h *PrettyReActHandler) HandleAgentAction(_ context.Context, action schema.AgentAction) {
-	// Record current action
-	h.lastAction = action.Tool
+	if h.logMode == "full" {
+		h.logBlock("📤 Tool Output (Full):", observation)
+	}
+}
 
-	// Collect action info
+// recordFinish records the final answer
+func (h *PrettyReActHandler) recordFinish(output string) {
+	if h == nil {
+		return
+	}
 	h.mu.Lock()
 	if h.currentStep != nil {
-		h.currentStep.Action = action.Tool
-		h.currentStep.ActionInput = action.ToolInput
+		h.currentStep.Action = "Final Answer"
+		h.currentStep.ActionInput = output
 	}
-	// Notify about action immediately (real-time streaming)
-	h.notifyStepUpdate("action")
+	// Notify about final answer immediately (real-time streaming)
+	h.notifyStepUpdate("finish")
 	h.mu.Unlock()
 
-	h.logPrintf("│ 🎯 Action: %s\n", action.Tool)
-
-	// full mode: show full input
-	if h.logMode == "full" {
-		h.logPrintf("│ 📥 Input (Full):\n")
-		for _, line := range strings.Split(action.ToolInput, "\n") {
-			h.logPrintf("│   %s\n", line)
-		}
-	} else {
-		// simple mode: truncated display
-		h.logPrintf("│ 📥 Input: %s\n", truncate(action.ToolInput, 100))
-	}
-
-	// Mark update_rich_context as not counting towards limit
-	if action.Tool == "update_rich_context" {
-		h.logPrintf("│ ℹ️  Note: This action does NOT count towards the 5-iteration limit\n")
-	}
+	h.logPrintf("└─ ✅ Final Answer ──────────────────────────────────\n")
+	h.logPrintf("   %s\n", truncate(output, 150))
 }
 
-func (h *PrettyReActHandler) HandleAgentFinish(_ context.Context, finish schema.AgentFinish) {
-	if output, ok := finish.ReturnValues["output"].(string); ok {
-		// Set the final answer as action
-		h.mu.Lock()
-		if h.currentStep != nil {
-			h.currentStep.Action = "Final Answer"
-			h.currentStep.ActionInput = output
-		}
-		// Notify about final answer immediately (real-time streaming)
-		h.notifyStepUpdate("finish")
-		h.mu.Unlock()
-
-		if strings.Contains(output, "agent not finished") {
-			h.logPrintf("└─ ⚠️  Max iterations reached ────────────────────────────\n")
-		} else {
-			h.logPrintf("└─ ✅ Final Answer ──────────────────────────────────\n")
-			h.logPrintf("   %s\n", truncate(output, 150))
-		}	}
+// recordLimit logs that the iterations ran out
+func (h *PrettyReActHandler) recordLimit() {
+	if h == nil {
+		return
+	}
+	h.logPrintf("└─ ⚠️  Max iterations reached ────────────────────────────\n")
 }
 
-func (h *PrettyReActHandler) HandleRetrieverStart(_ context.Context, query string) {}
+// recordError logs a model, parse or tool error
+func (h *PrettyReActHandler) recordError(err error) {
+	if h == nil {
+		return
+	}
+	h.logPrintf("│ ❌ Error: %v\n", err)
 
-func (h *PrettyReActHandler) HandleRetrieverEnd(_ context.Context, query string, documents []schema.Document) {
+	h.mu.Lock()
+	if h.currentStep != nil && h.currentStep.Action != "" && h.currentStep.Observation == "" {
+		h.currentStep.Observation = fmt.Sprintf("Error: %v", err)
+	}
+	h.mu.Unlock()
 }
 
-func (h *PrettyReActHandler) HandleStreamingFunc(_ context.Context, chunk []byte) {}
-
 // extractThought extracts thought from LLM response text
 func extractThought(text string) string {
 	if idx := strings.Index(text, "Thought:"); idx >= 0 {
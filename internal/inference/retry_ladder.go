@@ -0,0 +1,156 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// failedAttempt a generation attempt that failed the same way as before
+type failedAttempt struct {
+	SQL    string
+	Reason string
+}
+
+// generateWithRetries runs generateSQL, regenerating up Config.RetryTemperatures while the
+// attempts fail the same way (the same SQL error twice, or the agent repeating an action)
+// Each retry gets a preamble listing the failed attempts so the model changes approach.
+func (p *Pipeline) generateWithRetries(ctx context.Context, query, contextPrompt, crossTableSummary string, result *Result) (string, error) {
+	from := len(result.ReActSteps)
+	sql, err := p.generateSQL(ctx, query, contextPrompt, crossTableSummary, result)
+	if len(p.config.RetryTemperatures) == 0 {
+		return sql, err
+	}
+
+	seen := make(map[string]int) // Error message -> occurrences across attempts
+	var failures []failedAttempt
+	defer func() { p.retryPreamble = "" }()
+
+	for i, temperature := range p.config.RetryTemperatures {
+		if ctx.Err() != nil {
+			break
+		}
+		reason := p.repeatedFailure(ctx, sql, err, result.ReActSteps[from:], seen, result)
+		if reason == "" {
+			break
+		}
+		failures = append(failures, failedAttempt{SQL: sql, Reason: reason})
+		p.Logger.Printf("🌡️  Repeated failure (%s), regenerating at temperature %.2f (%d/%d)\n",
+			truncate(reason, 120), temperature, i+1, len(p.config.RetryTemperatures))
+
+		step := ReActStep{
+			Thought:     reason,
+			Action:      "retry_generation",
+			ActionInput: fmt.Sprintf("temperature=%.2f", temperature),
+			Phase:       "sql_generation",
+		}
+		result.ReActSteps = append(result.ReActSteps, step)
+		p.notifyStep(step, "action")
+
+		p.retryPreamble = formatRetryPreamble(failures)
+		from = len(result.ReActSteps)
+		retrySQL, retryErr := p.generateAtTemperature(ctx, query, contextPrompt, crossTableSummary, result, temperature)
+		if retryErr != nil && sql != "" {
+			// Keep the previous SQL; the failed call still counts as a repeat of its error
+			p.Logger.Printf("⚠️  Retry at temperature %.2f failed: %v\n", temperature, retryErr)
+			seen[retryErr.Error()]++
+			continue
+		}
+		sql, err = retrySQL, retryErr
+	}
+	return sql, err
+}
+
+// generateAtTemperature runs one generation with a sampling temperature
+func (p *Pipeline) generateAtTemperature(ctx context.Context, query, contextPrompt, crossTableSummary string, result *Result, temperature float64) (string, error) {
+	if p.config.UseReact {
+		return p.generateReAct(ctx, query, contextPrompt, crossTableSummary, result, llms.WithTemperature(temperature))
+	}
+	return p.generateSQL(ctx, query, contextPrompt, crossTableSummary, result, llms.WithTemperature(temperature))
+}
+
+// repeatedFailure describes how an attempt repeated a failure ("" = no repeat)
+// Errors seen in the agent's observations, the generation error and the final SQL's
+// execution error are counted in seen, so a repeat may span attempts.
+func (p *Pipeline) repeatedFailure(ctx context.Context, sql string, genErr error, steps []ReActStep, seen map[string]int, result *Result) string {
+	// Agent loop: the same tool call with the same input twice
+	calls := make(map[string]bool)
+	for _, step := range steps {
+		if step.Action == "" || step.Action == "Final Answer" {
+			continue
+		}
+		key := step.Action + "\x00" + strings.TrimSpace(formatActionInput(step.ActionInput))
+		if calls[key] {
+			return fmt.Sprintf("agent loop: called %s twice with the same input", step.Action)
+		}
+		calls[key] = true
+	}
+
+	var errs []string
+	for _, step := range steps {
+		if msg := observationError(step.Observation); msg != "" {
+			errs = append(errs, msg)
+		}
+	}
+	if genErr != nil {
+		errs = append(errs, genErr.Error())
+	}
+	if sql != "" && genErr == nil {
+		_, execErr := p.queryAdapter.ExecuteQuery(ctx, sql)
+		result.SQLExecutions++
+		if execErr != nil {
+			errs = append(errs, execErr.Error())
+		}
+	}
+
+	for _, msg := range errs {
+		seen[msg]++
+		if seen[msg] >= 2 {
+			return "same error twice: " + msg
+		}
+	}
+	return ""
+}
+
+// observationError error message in a tool observation ("" if the call succeeded)
+func observationError(observation string) string {
+	for _, header := range []string{"SQL execution failed:", "SQL validation failed:"} {
+		if idx := strings.Index(observation, header); idx >= 0 {
+			return firstLine(observation[idx+len(header):])
+		}
+	}
+	// verify_sql: "❌ SQL validation failed (static check):\n<error>\n\n..."
+	if idx := strings.Index(observation, "SQL validation failed ("); idx >= 0 {
+		if colon := strings.Index(observation[idx:], ":"); colon >= 0 {
+			return firstLine(observation[idx+colon+1:])
+		}
+	}
+	return ""
+}
+
+// firstLine first non-empty line, trimmed
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// formatRetryPreamble prompt section listing the failed attempts
+func formatRetryPreamble(failures []failedAttempt) string {
+	var sb strings.Builder
+	sb.WriteString("## Previous Attempts Failed\n")
+	sb.WriteString("Earlier attempts at this question failed. Do not repeat them; take a different approach (other tables, joins, filters or functions).\n")
+	for i, f := range failures {
+		sb.WriteString(fmt.Sprintf("%d. Failed because: %s\n", i+1, f.Reason))
+		if f.SQL != "" {
+			sb.WriteString(fmt.Sprintf("   SQL: %s\n", strings.Join(strings.Fields(f.SQL), " ")))
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -6,7 +6,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/tmc/langchaingo/agents"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
 
@@ -19,6 +18,7 @@ type SchemaLinkResult struct {
 	Tables       []string   // Selected table names
 	Steps        []ReActStep // ReAct steps (if using ReAct mode)
 	ContextPrompt string    // LLM-generated focused context for SQL generation (empty if not available)
+	Columns      map[string][]string // Selected columns per table (column linking; missing table = all columns)
 }
 
 // SchemaLinker module interface
@@ -36,6 +36,7 @@ type TableInfo struct {
 	ForeignKeys []contextpkg.ForeignKeyMetadata // Foreign key relationships
 	Description string                          // Table description (optional, from rich_context or table comment)
 	QualitySummary string                       // One-line quality issues summary
+	Synonyms    map[string][]string             // Natural-language synonyms per column
 }
 
 // LLMSchemaLinker LLM-based Schema Linking
@@ -43,8 +44,15 @@ type LLMSchemaLinker struct {
 	llm           llms.Model
 	adapter       adapter.DBAdapter
 	useReact      bool
+	linkColumns   bool // Also select columns per table (TABLES line uses table(col, ...) syntax)
+	summary       string // Database overview shown above the table list (the full RC prompt has its own)
 	tokenRecorder func(prompt, response string)
 	logger        *InferenceLogger
+
+	// ReAct mode controller, built on first use and reused across queries
+	reactController *reactController
+	reactHandler    *PrettyReActHandler
+	reactSQLTool    *SQLTool
 }
 
 // NewLLMSchemaLinker creates LLM Schema Linker
@@ -74,7 +82,7 @@ func (l *LLMSchemaLinker) linkOneShot(ctx context.Context, query string, allTabl
 		if len(table.ForeignKeys) > 0 {
 			schemaDesc.WriteString("  Foreign Keys:\n")
 			for _, fk := range table.ForeignKeys {
-				schemaDesc.WriteString(fmt.Sprintf("    %s → %s.%s\n", fk.ColumnName, fk.ReferencedTable, fk.ReferencedColumn))
+				schemaDesc.WriteString(fmt.Sprintf("    %s → %s\n", fk.LocalRef(), fk.TargetRef()))
 			}
 		}
 		if table.Description != "" {
@@ -86,22 +94,28 @@ func (l *LLMSchemaLinker) linkOneShot(ctx context.Context, query string, allTabl
 		schemaDesc.WriteString("\n")
 	}
 
+	outputFormat := "Output format: table1, table2, table3 (comma-separated, no extra text)"
+	if l.linkColumns {
+		outputFormat = `Also select the columns each table needs (SELECT, WHERE, GROUP BY, ORDER BY); key columns are added automatically.
+Output format: table1(col_a, col_b), table2(col_c) (comma-separated, no extra text)`
+	}
+
 	// Build Prompt
 	prompt := fmt.Sprintf(`You are a database expert. Identify which tables are relevant to answer the question.
 
-Available Tables:
+%sAvailable Tables:
+%s
 %s
-
 Question: %s
 
 Task: Select ALL tables needed to answer this question, including intermediate/bridge tables for JOINs.
 IMPORTANT: If table A references table B via foreign key, and you need data from A, you likely need B too.
 When in doubt, INCLUDE the table — it's better to select extra tables than to miss one.
-Output format: table1, table2, table3 (comma-separated, no extra text)
+%s
 If all tables are needed, output: all
 If no tables are needed, output: none
 
-Output:`, schemaDesc.String(), query)
+Output:`, l.overview(), schemaDesc.String(), synonymHints(query, allTables), query, outputFormat)
 
 	// Print summary to stdout + dump full prompt to log file
 	if l.logger != nil {
@@ -197,15 +211,9 @@ Output:`, schemaDesc.String(), query)
 	lines := strings.Split(response, "\n")
 	firstLine := strings.TrimSpace(lines[0])
 
-	// Parse table name list
-	tables := strings.Split(firstLine, ",")
-	result := make([]string, 0, len(tables))
-	for _, table := range tables {
-		table = strings.TrimSpace(table)
-		if table != "" {
-			result = append(result, table)
-		}
-	}
+	// Parse table name list (with optional per-table columns)
+	result, columns := parseTableSelection(firstLine)
+	columns = resolveLinkedColumns(columns, allTables)
 
 	// Auto-complete: add FK-referenced tables that were missed
 	result = l.autoCompleteFKTables(result, allTables)
@@ -235,9 +243,12 @@ Output:`, schemaDesc.String(), query)
 		},
 	}
 
-	return &SchemaLinkResult{Tables: result, Steps: steps}, nil
+	return &SchemaLinkResult{Tables: result, Steps: steps, Columns: columns}, nil
 }
 
+// linkingMaxIterations model calls allowed to ReAct schema linking (the prompt states the same limit)
+const linkingMaxIterations = 8
+
 // linkWithReact ReAct mode Schema Linking
 func (l *LLMSchemaLinker) linkWithReact(ctx context.Context, query string, allTables map[string]*TableInfo, fullRCPrompt string) (*SchemaLinkResult, error) {
 	if l.logger != nil {
@@ -246,31 +257,18 @@ func (l *LLMSchemaLinker) linkWithReact(ctx context.Context, query string, allTa
 		fmt.Println("🔍 Schema Linking (ReAct mode)...")
 	}
 
-	// Create SQL tool
-	sqlTool := &SQLTool{
-		adapter:   l.adapter,
-		useDryRun: false,
-		logger:    l.logger,
-	}
-
-	// Create handler to collect ReAct steps
-	reactHandler := &PrettyReActHandler{logMode: "simple", logger: l.logger}
-
-	// Create ReAct Agent
-	// Strategy: tell model max 5 iterations (urgency), actual 15 (enough room)
-	actualMaxIterations := 15
-	claimedMaxIterations := 5
-
-	executor, err := agents.Initialize(
-		l.llm,
-		[]tools.Tool{sqlTool},
-		agents.ZeroShotReactDescription,
-		agents.WithMaxIterations(actualMaxIterations),
-		agents.WithCallbacksHandler(reactHandler),
-	)
-	if err != nil {
-		return nil, err
+	// Create the ReAct controller once, reset its SQL tool and step handler per query
+	if l.reactController == nil {
+		l.reactSQLTool = &SQLTool{adapter: l.adapter, useDryRun: false}
+		l.reactHandler = &PrettyReActHandler{logMode: "simple"}
+		l.reactController = newReactController(l.llm, []tools.Tool{l.reactSQLTool}, l.reactHandler, linkingMaxIterations)
 	}
+	l.reactSQLTool.logger = l.logger
+	l.reactSQLTool.ExecutionCount = 0
+	l.reactSQLTool.lastValid = ""
+	l.reactHandler.reset()
+	l.reactHandler.logger = l.logger
+	controller, reactHandler := l.reactController, l.reactHandler
 
 	// Build schema description: use full RC if available, otherwise use basic table info
 	var schemaSection string
@@ -278,13 +276,14 @@ func (l *LLMSchemaLinker) linkWithReact(ctx context.Context, query string, allTa
 		schemaSection = fullRCPrompt
 	} else {
 		var schemaDesc strings.Builder
+		schemaDesc.WriteString(l.overview())
 		for _, table := range allTables {
 			schemaDesc.WriteString(fmt.Sprintf("- %s\n", table.Name))
 			schemaDesc.WriteString(fmt.Sprintf("  Columns: %s\n", strings.Join(table.Columns, ", ")))
 			if len(table.ForeignKeys) > 0 {
 				schemaDesc.WriteString("  Foreign Keys:\n")
 				for _, fk := range table.ForeignKeys {
-					schemaDesc.WriteString(fmt.Sprintf("    %s → %s.%s\n", fk.ColumnName, fk.ReferencedTable, fk.ReferencedColumn))
+					schemaDesc.WriteString(fmt.Sprintf("    %s → %s\n", fk.LocalRef(), fk.TargetRef()))
 				}
 			}
 			if table.Description != "" {
@@ -298,6 +297,13 @@ func (l *LLMSchemaLinker) linkWithReact(ctx context.Context, query string, allTa
 		schemaSection = schemaDesc.String()
 	}
 
+	tablesRule := `- TABLES line: comma-separated table names (use "all" or "none" if appropriate)`
+	tablesExample := "TABLES: orders, customers"
+	if l.linkColumns {
+		tablesRule = `- TABLES line: comma-separated tables, each followed by the columns it needs: orders(order_date, total_amount) (use "all" or "none" if appropriate)`
+		tablesExample = "TABLES: orders(order_date, total_amount), customers(name, country)"
+	}
+
 	// Build Prompt — with full RC, linker outputs BOTH tables AND focused context
 	prompt := fmt.Sprintf(`You are a database expert. Your task has TWO parts:
 1. Identify which tables (and columns) are relevant to the question.
@@ -307,7 +313,7 @@ func (l *LLMSchemaLinker) linkWithReact(ctx context.Context, query string, allTa
 
 Full Database Schema:
 %s
-
+%s
 Question: %s
 
 You can use execute_sql to:
@@ -340,7 +346,7 @@ To give final answer (MUST follow this exact format):
 
 Example Final Answer:
    Final Answer:
-   TABLES: orders, customers
+   %s
    CONTEXT:
    Table orders (50000 rows):
      - order_id: INTEGER [PK]
@@ -354,13 +360,13 @@ Example Final Answer:
 
 CRITICAL RULES:
 - ONE action per iteration — never output multiple Action/Action Input pairs
-- TABLES line: comma-separated table names (use "all" or "none" if appropriate)
+%s
 - CONTEXT section: Only include columns/info relevant to answering the question
 - For columns used in WHERE filters, include value statistics (values=[...] or range=[...])
 - For FK/JOIN columns, include the FK arrow notation (→ table.column)
 - Keep it compact — the SQL generator will use this context directly
 
-Output:`, claimedMaxIterations, schemaSection, query)
+Output:`, linkingMaxIterations, schemaSection, synonymHints(query, allTables), query, tablesExample, tablesRule)
 
 	// Execute ReAct — dump prompt to file for post-analysis
 	if l.logger != nil {
@@ -368,7 +374,7 @@ Output:`, claimedMaxIterations, schemaSection, query)
 		l.logger.FileOnly("%s", prompt)
 		l.logger.FileOnly("└──────────────────────────────────────────────────────────────────\n\n")
 	}
-	agentResult, err := executor.Call(ctx, map[string]any{"input": prompt})
+	output, err := controller.run(ctx, prompt)
 	if err != nil {
 		return nil, err
 	}
@@ -387,38 +393,35 @@ Output:`, claimedMaxIterations, schemaSection, query)
 	}
 
 	// Extract final result — parse TABLES and CONTEXT sections
-	if output, ok := agentResult["output"].(string); ok {
-		tables, contextPrompt := parseSchemaLinkOutput(output)
+	tables, columns, contextPrompt := parseSchemaLinkOutput(output)
+	columns = resolveLinkedColumns(columns, allTables)
 
-		if len(tables) == 1 && tables[0] == "all" {
-			allTableNames := make([]string, 0, len(allTables))
-			for name := range allTables {
-				allTableNames = append(allTableNames, name)
-			}
-			return &SchemaLinkResult{Tables: allTableNames, Steps: schemaLinkingSteps, ContextPrompt: contextPrompt}, nil
-		}
-
-		if len(tables) == 1 && tables[0] == "none" {
-			return &SchemaLinkResult{Tables: []string{}, Steps: schemaLinkingSteps, ContextPrompt: contextPrompt}, nil
+	if len(tables) == 1 && tables[0] == "all" {
+		allTableNames := make([]string, 0, len(allTables))
+		for name := range allTables {
+			allTableNames = append(allTableNames, name)
 		}
+		return &SchemaLinkResult{Tables: allTableNames, Steps: schemaLinkingSteps, ContextPrompt: contextPrompt}, nil
+	}
 
-		// Auto-complete FK-referenced tables
-		tables = l.autoCompleteFKTables(tables, allTables)
-		return &SchemaLinkResult{Tables: tables, Steps: schemaLinkingSteps, ContextPrompt: contextPrompt}, nil
+	if len(tables) == 1 && tables[0] == "none" {
+		return &SchemaLinkResult{Tables: []string{}, Steps: schemaLinkingSteps, ContextPrompt: contextPrompt}, nil
 	}
 
-	return nil, fmt.Errorf("schema linking failed to produce a valid table list")
+	// Auto-complete FK-referenced tables
+	tables = l.autoCompleteFKTables(tables, allTables)
+	return &SchemaLinkResult{Tables: tables, Steps: schemaLinkingSteps, ContextPrompt: contextPrompt, Columns: columns}, nil
 }
 
 // parseSchemaLinkOutput parses the structured output from schema linking.
 // Expected format:
 //
-//	TABLES: table1, table2
+//	TABLES: table1, table2(col_a, col_b)
 //	CONTEXT:
 //	...focused schema text...
 //
 // Falls back to treating the entire output as comma-separated table names (legacy format).
-func parseSchemaLinkOutput(output string) (tables []string, contextPrompt string) {
+func parseSchemaLinkOutput(output string) (tables []string, columns map[string][]string, contextPrompt string) {
 	output = strings.TrimSpace(output)
 
 	// Try to find TABLES: line
@@ -437,12 +440,7 @@ func parseSchemaLinkOutput(output string) (tables []string, contextPrompt string
 		if nlIdx := strings.IndexByte(tablesLine, '\n'); nlIdx >= 0 {
 			tablesLine = strings.TrimSpace(tablesLine[:nlIdx])
 		}
-		for _, t := range strings.Split(tablesLine, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" {
-				tables = append(tables, t)
-			}
-		}
+		tables, columns = parseTableSelection(tablesLine)
 
 		// Extract CONTEXT section
 		if contextIdx >= 0 {
@@ -453,16 +451,79 @@ func parseSchemaLinkOutput(output string) (tables []string, contextPrompt string
 
 	// Legacy fallback: first line is comma-separated table names
 	lines := strings.Split(output, "\n")
-	firstLine := strings.TrimSpace(lines[0])
-	for _, t := range strings.Split(firstLine, ",") {
-		t = strings.TrimSpace(t)
-		if t != "" {
-			tables = append(tables, t)
+	tables, columns = parseTableSelection(strings.TrimSpace(lines[0]))
+	return
+}
+
+// parseTableSelection parses "t1, t2(c1, c2)" into table names and per-table columns
+// Tables listed without parentheses get no column entry (= all columns).
+func parseTableSelection(line string) (tables []string, columns map[string][]string) {
+	var parts []string
+	depth, last := 0, 0
+	for i, r := range line {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, line[last:i])
+			last = i + 1
+		}
+	}
+	parts = append(parts, line[last:])
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		name := part
+		var cols []string
+		if open := strings.IndexByte(part, '('); open >= 0 {
+			name = strings.TrimSpace(part[:open])
+			inner := strings.TrimSuffix(strings.TrimSpace(part[open+1:]), ")")
+			for _, c := range strings.Split(inner, ",") {
+				c = strings.Trim(strings.TrimSpace(c), "`\"[]")
+				// Accept qualified table.column
+				if dot := strings.LastIndexByte(c, '.'); dot >= 0 {
+					c = c[dot+1:]
+				}
+				if c != "" && c != "*" {
+					cols = append(cols, c)
+				}
+			}
+		}
+		name = strings.Trim(name, "`\"[]")
+		if name == "" {
+			continue
+		}
+		tables = append(tables, name)
+		if len(cols) > 0 {
+			if columns == nil {
+				columns = make(map[string][]string)
+			}
+			columns[name] = append(columns[name], cols...)
 		}
 	}
 	return
 }
 
+// resolveLinkedColumns maps LLM-written table names onto the schema's spelling and drops unknown tables
+func resolveLinkedColumns(columns map[string][]string, allTables map[string]*TableInfo) map[string][]string {
+	if len(columns) == 0 {
+		return nil
+	}
+	byLower := make(map[string]string, len(allTables))
+	for name := range allTables {
+		byLower[strings.ToLower(name)] = name
+	}
+	resolved := make(map[string][]string, len(columns))
+	for table, cols := range columns {
+		if name, ok := byLower[strings.ToLower(table)]; ok {
+			resolved[name] = append(resolved[name], cols...)
+		}
+	}
+	return resolved
+}
+
 // autoCompleteFKTables adds FK-referenced tables that were selected tables depend on.
 // For each selected table, if it has FK references to another table not in the set, add it.
 // Also adds tables that reference selected tables (reverse FK — bridge tables).
@@ -546,28 +607,75 @@ func ExtractTableInfo(ctx *contextpkg.SharedContext) map[string]*TableInfo {
 			}
 		}
 
-		// Build quality summary from structured issues
-		qualitySummary := ""
-		if len(table.QualityIssues) > 0 {
-			var criticals []string
-			for _, issue := range table.QualityIssues {
-				if issue.Severity == "critical" {
-					criticals = append(criticals, fmt.Sprintf("%s(%s)", issue.Column, issue.Type))
-				}
-			}
-			if len(criticals) > 0 {
-				qualitySummary = fmt.Sprintf("⚠️ Quality: %s", strings.Join(criticals, ", "))
-			}
-		}
-
 		result[name] = &TableInfo{
 			Name:           name,
 			Columns:        columns,
 			ForeignKeys:    table.ForeignKeys,
 			Description:    description,
-			QualitySummary: qualitySummary,
+			QualitySummary: criticalQualitySummary(table.QualityIssues),
+			Synonyms:       ctx.ColumnSynonyms(table.Name),
 		}
 	}
 
 	return result
 }
+
+// overview the database overview paragraph of a linking prompt ("" without a summary)
+func (l *LLMSchemaLinker) overview() string {
+	if l.summary == "" {
+		return ""
+	}
+	return fmt.Sprintf("Database overview: %s\n\n", l.summary)
+}
+
+// tableInfoFromIndex builds linking info from a lazy context's table index (no table decoded)
+func tableInfoFromIndex(lazy *contextpkg.LazyContext) map[string]*TableInfo {
+	ctx := lazy.Context()
+	result := make(map[string]*TableInfo)
+	for name, entry := range lazy.Index() {
+		result[name] = &TableInfo{
+			Name:           name,
+			Columns:        entry.Columns,
+			ForeignKeys:    entry.ForeignKeys,
+			Description:    entry.Description,
+			QualitySummary: criticalQualitySummary(entry.QualityIssues),
+			Synonyms:       ctx.ColumnSynonyms(name),
+		}
+	}
+	return result
+}
+
+// criticalQualitySummary one-line summary of the critical structured issues ("" if none)
+func criticalQualitySummary(issues []contextpkg.QualityIssue) string {
+	var criticals []string
+	for _, issue := range issues {
+		if issue.Severity == "critical" {
+			criticals = append(criticals, fmt.Sprintf("%s(%s)", issue.Column, issue.Type))
+		}
+	}
+	if len(criticals) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("⚠️ Quality: %s", strings.Join(criticals, ", "))
+}
+
+// synonymHints question terms that name a column through its synonyms (empty if none match)
+func synonymHints(query string, allTables map[string]*TableInfo) string {
+	synonyms := make(map[string]map[string][]string)
+	for _, table := range allTables {
+		if len(table.Synonyms) > 0 {
+			synonyms[table.Name] = table.Synonyms
+		}
+	}
+	matches := contextpkg.MatchSynonyms(query, synonyms)
+	if len(matches) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Question terms that name columns:\n")
+	for _, m := range matches {
+		sb.WriteString(fmt.Sprintf("- \"%s\" → %s.%s\n", m.Term, m.Table, m.Column))
+	}
+	return sb.String()
+}
@@ -0,0 +1,161 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// defaultSessionTurns prior turns shown to a follow-up question when Session.MaxTurns is 0
+const defaultSessionTurns = 5
+
+// turnPreviewRows result rows kept per turn for follow-ups ("which of those ...")
+const turnPreviewRows = 3
+
+// Turn one answered question in a session
+type Turn struct {
+	Question   string   // As asked
+	Standalone string   // Rewritten to stand alone ("" for the first turn)
+	SQL        string   // Final SQL
+	Columns    []string // Result columns ("" if the SQL did not execute)
+	RowCount   int
+	Preview    string // First rows of the result
+	Answer     string // Natural-language answer (Config.NLAnswer)
+}
+
+// Session multi-turn conversation: follow-up questions ("now only for 2023", "sort by revenue")
+// are resolved against the previous questions, SQL and results
+// A Session drives one Pipeline and, like it, is not safe for concurrent use.
+type Session struct {
+	MaxTurns int // Prior turns shown to a follow-up (0 = default 5)
+
+	pipeline *Pipeline
+	turns    []Turn
+}
+
+// NewSession starts a conversation on a pipeline
+func NewSession(p *Pipeline) *Session {
+	return &Session{pipeline: p}
+}
+
+// Ask answers a question in the context of the previous turns
+// A failed question is not added to the history.
+func (s *Session) Ask(ctx context.Context, question string) (*Result, error) {
+	s.pipeline.history = s.recentTurns()
+	defer func() { s.pipeline.history = nil }()
+
+	result, err := s.pipeline.Execute(ctx, question)
+	if err != nil {
+		return nil, err
+	}
+	s.turns = append(s.turns, turnOf(result))
+	return result, nil
+}
+
+// Turns returns the answered turns, oldest first
+func (s *Session) Turns() []Turn {
+	return s.turns
+}
+
+// Reset forgets the conversation
+func (s *Session) Reset() {
+	s.turns = nil
+}
+
+// recentTurns the last MaxTurns turns
+func (s *Session) recentTurns() []Turn {
+	n := s.MaxTurns
+	if n <= 0 {
+		n = defaultSessionTurns
+	}
+	if len(s.turns) <= n {
+		return s.turns
+	}
+	return s.turns[len(s.turns)-n:]
+}
+
+// turnOf records a result as a turn
+func turnOf(result *Result) Turn {
+	turn := Turn{
+		Question:   result.Query,
+		Standalone: result.StandaloneQuery,
+		SQL:        result.GeneratedSQL,
+		Answer:     result.Answer,
+	}
+	if rows, ok := result.ExecutionResult.(*adapter.QueryResult); ok && rows != nil {
+		turn.Columns = rows.Columns
+		turn.RowCount = len(rows.Values)
+		turn.Preview = adapter.FormatRows(rows.Columns, rows.Values, turnPreviewRows)
+	}
+	return turn
+}
+
+// resolveFollowUp rewrites a follow-up question so it stands alone (schema linking sees one question)
+// On failure the question is used as asked.
+func (p *Pipeline) resolveFollowUp(ctx context.Context, query string, result *Result) string {
+	prompt := buildFollowUpPrompt(p.history, query)
+	response, err := p.llm.Call(ctx, prompt)
+	result.LLMCalls++
+	if err != nil {
+		p.Logger.Printf("⚠️  Follow-up rewrite failed, using the question as asked: %v\n", err)
+		return query
+	}
+	p.promptTexts = append(p.promptTexts, prompt)
+	p.responseTexts = append(p.responseTexts, response)
+
+	standalone := strings.TrimSpace(response)
+	if idx := strings.Index(standalone, "Standalone Question:"); idx >= 0 {
+		standalone = strings.TrimSpace(standalone[idx+len("Standalone Question:"):])
+	}
+	standalone = firstLine(strings.Trim(standalone, "\"'`"))
+	if standalone == "" {
+		return query
+	}
+	p.Logger.Printf("💬 Follow-up resolved: %s\n", standalone)
+	return standalone
+}
+
+// buildFollowUpPrompt asks for the follow-up rewritten as a self-contained question
+func buildFollowUpPrompt(history []Turn, query string) string {
+	var sb strings.Builder
+	sb.WriteString("Rewrite the user's latest question so it can be understood without the conversation.\n\n")
+	sb.WriteString(formatConversation(history))
+	sb.WriteString(fmt.Sprintf("Latest question: %s\n\n", query))
+	sb.WriteString(`Rules:
+1. Carry over everything the latest question leaves implicit (entities, filters, time ranges, grouping, ordering, limits)
+2. Apply the latest question's changes ("now only for 2023" replaces or adds a filter, "sort by revenue" changes the ordering)
+3. If the latest question already stands alone, return it unchanged
+4. Output one question on one line, no SQL
+
+Standalone Question:`)
+	return sb.String()
+}
+
+// formatConversation prompt section with the previous turns
+func formatConversation(history []Turn) string {
+	var sb strings.Builder
+	sb.WriteString("## Conversation So Far\n")
+	for i, turn := range history {
+		question := turn.Question
+		if turn.Standalone != "" {
+			question = turn.Standalone
+		}
+		sb.WriteString(fmt.Sprintf("%d. Question: %s\n", i+1, question))
+		if turn.SQL != "" {
+			sb.WriteString(fmt.Sprintf("   SQL: %s\n", strings.Join(strings.Fields(turn.SQL), " ")))
+		}
+		if len(turn.Columns) > 0 {
+			sb.WriteString(fmt.Sprintf("   Result: %d rows (%s)\n", turn.RowCount, strings.Join(turn.Columns, ", ")))
+			if turn.Preview != "" {
+				sb.WriteString("   " + strings.ReplaceAll(turn.Preview, "\n", "\n   ") + "\n")
+			}
+		}
+		if turn.Answer != "" {
+			sb.WriteString(fmt.Sprintf("   Answer: %s\n", turn.Answer))
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,274 @@
+package inference
+
+import (
+	"strings"
+)
+
+// formatClauses keywords that start a new line at their query's indentation
+var formatClauses = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "HAVING": true, "ORDER": true,
+	"LIMIT": true, "OFFSET": true, "WINDOW": true, "UNION": true, "INTERSECT": true, "EXCEPT": true,
+	"VALUES": true, "SET": true, "RETURNING": true,
+}
+
+// joinModifiers words that can precede JOIN
+var joinModifiers = map[string]bool{
+	"LEFT": true, "RIGHT": true, "FULL": true, "INNER": true, "CROSS": true, "NATURAL": true, "OUTER": true,
+}
+
+// formatKeywords non-reserved keywords upper-cased by FormatSQL
+var formatKeywords = map[string]bool{
+	"INSERT": true, "INTO": true, "UPDATE": true, "SET": true, "DELETE": true, "RETURNING": true,
+	"RECURSIVE": true, "OVER": true, "PARTITION": true, "FILTER": true, "WITHIN": true, "NULLS": true,
+	"TRUE": true, "FALSE": true, "INTERVAL": true, "STRAIGHT_JOIN": true,
+}
+
+// formatFunctions function names upper-cased by FormatSQL when called
+var formatFunctions = map[string]bool{
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true, "COALESCE": true, "IFNULL": true,
+	"NULLIF": true, "IIF": true, "IF": true, "CAST": true, "ROUND": true, "ABS": true, "LENGTH": true,
+	"UPPER": true, "LOWER": true, "SUBSTR": true, "SUBSTRING": true, "TRIM": true, "REPLACE": true,
+	"INSTR": true, "STRFTIME": true, "DATE": true, "TIME": true, "DATETIME": true, "JULIANDAY": true,
+	"GROUP_CONCAT": true, "STRING_AGG": true, "EXTRACT": true, "CONCAT": true, "ROW_NUMBER": true,
+	"RANK": true, "DENSE_RANK": true,
+}
+
+// spacedBeforeParen non-reserved words written with a space before "(" (not function calls)
+var spacedBeforeParen = map[string]bool{"OVER": true, "FILTER": true, "INTO": true, "RECURSIVE": true}
+
+// formatFrame one parenthesis level of the query being formatted
+type formatFrame struct {
+	query     bool   // Statement or subquery: clauses start new lines
+	indent    int    // Indentation of its clause keywords
+	open      int    // Indentation of the line holding its "("
+	clause    string // Current clause keyword
+	between   bool   // Inside BETWEEN ... AND
+	listBreak bool   // SELECT list written one item per line
+}
+
+// sqlFormatter state of one FormatSQL call
+type sqlFormatter struct {
+	toks   []sqlToken
+	frames []formatFrame
+	lines  []string
+	line   strings.Builder
+	indent int       // Indentation of the current line
+	prev   *sqlToken // Last token of the current line (nil at line start)
+	unary  bool      // Last token was a sign: no space after it
+}
+
+// FormatSQL pretty-prints a query for logs, reports and diffs
+// Keywords and common functions are upper-cased, each clause starts a line, multi-column SELECT
+// lists and top-level WHERE / HAVING conditions get one line per entry, and subqueries are
+// indented. Identifiers and literals are kept as written, comments dropped. A query that does
+// not tokenize is returned trimmed.
+func FormatSQL(sql, dialect string) string {
+	sql = strings.TrimSpace(sql)
+	toks, err := tokenizeSQL(sql, NormalizeDialect(dialect))
+	if err != nil {
+		return sql
+	}
+	f := &sqlFormatter{toks: toks, frames: []formatFrame{{query: true}}}
+	return f.format()
+}
+
+func (f *sqlFormatter) format() string {
+	for i, tok := range f.toks {
+		if tok.kind == tokEOF {
+			break
+		}
+		if isSQLOp(tok, ";") {
+			continue
+		}
+		top := &f.frames[len(f.frames)-1]
+
+		if isSQLOp(tok, ")") && len(f.frames) > 1 {
+			f.frames = f.frames[:len(f.frames)-1]
+			if top.query {
+				f.newLine(top.open)
+			}
+			f.emit(tok, ")")
+			continue
+		}
+		if top.query && tok.kind == tokWord {
+			f.breakBefore(i, top)
+		}
+		f.emit(tok, f.casing(i))
+
+		switch {
+		case isSQLOp(tok, "("):
+			frame := formatFrame{indent: f.indent, open: f.indent}
+			if isSQLWord(f.toks[i+1], "SELECT", "WITH") {
+				frame.query, frame.indent = true, f.indent+1
+			}
+			f.frames = append(f.frames, frame)
+			if frame.query {
+				f.newLine(frame.indent)
+			}
+		case !top.query || !top.listBreak || top.clause != "SELECT":
+		case isSQLOp(tok, ","):
+			f.newLine(top.indent + 1)
+		case isSQLWord(tok, "SELECT", "DISTINCT", "ALL") && !isSQLWord(f.toks[i+1], "DISTINCT", "ALL", "ON"):
+			f.newLine(top.indent + 1)
+		}
+	}
+	f.newLine(0)
+	return strings.Join(f.lines, "\n")
+}
+
+// breakBefore starts a new line before a clause, join or top-level condition
+func (f *sqlFormatter) breakBefore(i int, top *formatFrame) {
+	tok := f.toks[i]
+	var prev sqlToken
+	if i > 0 {
+		prev = f.toks[i-1]
+	}
+	switch {
+	case formatClauses[tok.upper] && !(tok.upper == "GROUP" && isSQLWord(prev, "WITHIN")):
+		f.newLine(top.indent)
+		top.clause, top.between = tok.upper, false
+		if tok.upper == "SELECT" {
+			top.listBreak = f.selectListBreaks(i)
+		}
+	case (top.clause == "FROM" || top.clause == "JOIN") && f.startsJoin(i):
+		f.newLine(top.indent)
+		top.clause = "JOIN"
+	case tok.upper == "BETWEEN":
+		top.between = true
+	case tok.upper == "AND" && top.between:
+		top.between = false
+	case (tok.upper == "AND" || tok.upper == "OR") && (top.clause == "WHERE" || top.clause == "HAVING"):
+		f.newLine(top.indent + 1)
+	}
+}
+
+// startsJoin reports whether the word at i begins a join ("LEFT OUTER JOIN", "JOIN", ...)
+func (f *sqlFormatter) startsJoin(i int) bool {
+	tok := f.toks[i]
+	if i > 0 && isSQLWord(f.toks[i-1], "LEFT", "RIGHT", "FULL", "INNER", "CROSS", "NATURAL", "OUTER") {
+		return false
+	}
+	switch {
+	case tok.upper == "JOIN" || tok.upper == "STRAIGHT_JOIN":
+		return true
+	case joinModifiers[tok.upper]:
+		return !isSQLOp(f.toks[i+1], "(") // LEFT(name, 3)
+	}
+	return false
+}
+
+// selectListBreaks reports whether the SELECT list at i has several items
+func (f *sqlFormatter) selectListBreaks(i int) bool {
+	depth := 0
+	for j := i + 1; j < len(f.toks); j++ {
+		tok := f.toks[j]
+		switch {
+		case tok.kind == tokEOF:
+			return false
+		case isSQLOp(tok, "("):
+			depth++
+		case isSQLOp(tok, ")"):
+			depth--
+			if depth < 0 {
+				return false
+			}
+		case depth > 0:
+		case isSQLOp(tok, ","):
+			return true
+		case tok.kind == tokWord && formatClauses[tok.upper] && !isSQLWord(f.toks[j-1], "WITHIN"):
+			return false
+		}
+	}
+	return false
+}
+
+// casing keyword and function names upper-cased, everything else as written
+func (f *sqlFormatter) casing(i int) string {
+	tok := f.toks[i]
+	if tok.kind != tokWord {
+		return tok.text
+	}
+	if reservedWords[tok.upper] || formatKeywords[tok.upper] || (formatFunctions[tok.upper] && isSQLOp(f.toks[i+1], "(")) {
+		return tok.upper
+	}
+	return tok.text
+}
+
+// newLine ends the current line; the next token starts one at indent
+func (f *sqlFormatter) newLine(indent int) {
+	if f.line.Len() > 0 {
+		f.lines = append(f.lines, strings.TrimRight(f.line.String(), " "))
+		f.line.Reset()
+	}
+	f.indent = indent
+	f.prev = nil
+	f.unary = false
+}
+
+func (f *sqlFormatter) emit(tok sqlToken, text string) {
+	if f.line.Len() == 0 {
+		f.line.WriteString(strings.Repeat("  ", f.indent))
+	} else if f.spaceBefore(tok) {
+		f.line.WriteByte(' ')
+	}
+	f.line.WriteString(text)
+
+	prev := f.prev
+	f.unary = (isSQLOp(tok, "-") || isSQLOp(tok, "+")) &&
+		(prev == nil || (prev.kind == tokOp && prev.text != ")") || (prev.kind == tokWord && reservedWords[prev.upper]))
+	f.prev = &tok
+}
+
+// spaceBefore reports whether a space separates tok from the previous token on the line
+func (f *sqlFormatter) spaceBefore(tok sqlToken) bool {
+	prev := f.prev
+	switch {
+	case prev == nil || f.unary:
+		return false
+	case tok.kind == tokOp && (tok.text == ")" || tok.text == "," || tok.text == "." || tok.text == "::" || tok.text == "]"):
+		return false
+	case prev.kind == tokOp && (prev.text == "(" || prev.text == "." || prev.text == "::" || prev.text == "["):
+		return false
+	case isSQLOp(tok, "(") && prev.kind == tokWord:
+		return (reservedWords[prev.upper] && prev.upper != "LEFT" && prev.upper != "RIGHT") || spacedBeforeParen[prev.upper]
+	}
+	return true
+}
+
+// DiffSQL line diff of two formatted queries: "  " both, "- " only in a, "+ " only in b
+func DiffSQL(a, b, dialect string) string {
+	x := strings.Split(FormatSQL(a, dialect), "\n")
+	y := strings.Split(FormatSQL(b, dialect), "\n")
+
+	// lcs[i][j] = longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			sb.WriteString("  " + x[i] + "\n")
+			i, j = i+1, j+1
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + x[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + y[j] + "\n")
+			j++
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
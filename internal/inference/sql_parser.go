@@ -0,0 +1,1081 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SQLSyntaxError parse failure with position info for the repair prompt
+type SQLSyntaxError struct {
+	Offset int    // Byte offset in the SQL
+	Near   string // Token at the error position ("" = end of input)
+	Msg    string
+}
+
+func (e *SQLSyntaxError) Error() string {
+	if e.Near == "" {
+		return fmt.Sprintf("syntax error at end of input: %s", e.Msg)
+	}
+	return fmt.Sprintf("syntax error near %q (offset %d): %s", e.Near, e.Offset, e.Msg)
+}
+
+// ParseSQL checks that sql is a single syntactically valid query in the given dialect
+// dialect: "sqlite", "mysql" or "postgresql" (anything else = permissive common subset).
+// Recursive-descent over the query grammar used by text-to-SQL answers (SELECT / WITH /
+// set operations / joins / subqueries / window functions); it validates structure only,
+// never names, so it runs without a database.
+func ParseSQL(sql, dialect string) (err error) {
+	toks, err := tokenizeSQL(sql, strings.ToLower(dialect))
+	if err != nil {
+		return err
+	}
+
+	p := &sqlParser{toks: toks, dialect: strings.ToLower(dialect)}
+	defer func() {
+		if r := recover(); r != nil {
+			se, ok := r.(*SQLSyntaxError)
+			if !ok {
+				panic(r)
+			}
+			err = se
+		}
+	}()
+
+	if p.peek().kind == tokEOF {
+		p.fail("empty statement")
+	}
+	if !p.isWord("SELECT", "WITH", "VALUES") && !p.isOp("(") {
+		p.fail("expected SELECT or WITH (only queries are allowed)")
+	}
+	p.parseQuery()
+	for p.acceptOp(";") {
+	}
+	if p.peek().kind != tokEOF {
+		if p.isWord("SELECT", "WITH", "INSERT", "UPDATE", "DELETE", "CREATE", "DROP") {
+			p.fail("multiple statements are not allowed")
+		}
+		p.fail("unexpected token after end of query")
+	}
+	return nil
+}
+
+// ─── Tokenizer ───────────────────────────────────────────────────
+
+type tokKind int
+
+const (
+	tokEOF    tokKind = iota
+	tokWord           // bare identifier or keyword
+	tokQuoted         // quoted identifier
+	tokString         // string literal
+	tokNumber
+	tokParam // ?, $1, :name, @name
+	tokOp    // punctuation / operator
+)
+
+type sqlToken struct {
+	kind  tokKind
+	text  string
+	upper string // upper-cased text for words
+	pos   int
+}
+
+// multi-char operators, longest first
+var sqlOperators = []string{
+	"<=>", "->>", "!~*", "||", "::", "<=", ">=", "<>", "!=", "==", "<<", ">>", "->", "~*", "!~",
+	"(", ")", ",", ".", ";", "*", "+", "-", "/", "%", "=", "<", ">", "&", "|", "~", "[", "]",
+}
+
+func tokenizeSQL(sql, dialect string) ([]sqlToken, error) {
+	var toks []sqlToken
+	i := 0
+	for i < len(sql) {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case c == '-' && strings.HasPrefix(sql[i:], "--"), c == '#' && dialect == "mysql":
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, &SQLSyntaxError{Offset: i, Near: "/*", Msg: "unterminated comment"}
+			}
+			i += end + 4
+		case c == '\'':
+			end, ok := scanQuoted(sql, i, '\'', dialect == "mysql")
+			if !ok {
+				return nil, &SQLSyntaxError{Offset: i, Near: snippet(sql, i), Msg: "unterminated string literal"}
+			}
+			toks = append(toks, sqlToken{kind: tokString, text: sql[i:end], pos: i})
+			i = end
+		case c == '"':
+			// MySQL (without ANSI_QUOTES) treats "..." as a string; both are fine for parsing
+			end, ok := scanQuoted(sql, i, '"', false)
+			if !ok {
+				return nil, &SQLSyntaxError{Offset: i, Near: snippet(sql, i), Msg: "unterminated quoted identifier"}
+			}
+			toks = append(toks, sqlToken{kind: tokQuoted, text: sql[i:end], pos: i})
+			i = end
+		case c == '`':
+			if dialect == "postgresql" {
+				return nil, &SQLSyntaxError{Offset: i, Near: snippet(sql, i), Msg: "backtick-quoted identifiers are not valid in PostgreSQL, use double quotes"}
+			}
+			end, ok := scanQuoted(sql, i, '`', false)
+			if !ok {
+				return nil, &SQLSyntaxError{Offset: i, Near: snippet(sql, i), Msg: "unterminated quoted identifier"}
+			}
+			toks = append(toks, sqlToken{kind: tokQuoted, text: sql[i:end], pos: i})
+			i = end
+		case c == '[' && dialect == "sqlite":
+			end := strings.IndexByte(sql[i:], ']')
+			if end < 0 {
+				return nil, &SQLSyntaxError{Offset: i, Near: snippet(sql, i), Msg: "unterminated [identifier]"}
+			}
+			toks = append(toks, sqlToken{kind: tokQuoted, text: sql[i : i+end+1], pos: i})
+			i += end + 1
+		case isDigit(c) || (c == '.' && i+1 < len(sql) && isDigit(sql[i+1])):
+			start := i
+			for i < len(sql) && (isDigit(sql[i]) || sql[i] == '.') {
+				i++
+			}
+			if i < len(sql) && (sql[i] == 'e' || sql[i] == 'E') {
+				i++
+				if i < len(sql) && (sql[i] == '+' || sql[i] == '-') {
+					i++
+				}
+				for i < len(sql) && isDigit(sql[i]) {
+					i++
+				}
+			}
+			if i < len(sql) && isWordChar(sql[i]) {
+				return nil, &SQLSyntaxError{Offset: start, Near: snippet(sql, start), Msg: "malformed number or identifier starting with a digit"}
+			}
+			toks = append(toks, sqlToken{kind: tokNumber, text: sql[start:i], pos: start})
+		case c == '?' || ((c == '$' || c == ':' || c == '@') && i+1 < len(sql) && isWordChar(sql[i+1]) && !strings.HasPrefix(sql[i:], "::")):
+			start := i
+			i++
+			for i < len(sql) && isWordChar(sql[i]) {
+				i++
+			}
+			toks = append(toks, sqlToken{kind: tokParam, text: sql[start:i], pos: start})
+		case isWordChar(c) || c >= 0x80:
+			start := i
+			for i < len(sql) && (isWordChar(sql[i]) || sql[i] == '$' || sql[i] >= 0x80) {
+				i++
+			}
+			word := sql[start:i]
+			toks = append(toks, sqlToken{kind: tokWord, text: word, upper: strings.ToUpper(word), pos: start})
+		default:
+			matched := false
+			for _, op := range sqlOperators {
+				if strings.HasPrefix(sql[i:], op) {
+					toks = append(toks, sqlToken{kind: tokOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &SQLSyntaxError{Offset: i, Near: string(c), Msg: "unexpected character"}
+			}
+		}
+	}
+	return append(toks, sqlToken{kind: tokEOF, pos: len(sql)}), nil
+}
+
+// scanQuoted returns the offset just past a quoted token starting at i (doubled quote = escape)
+func scanQuoted(sql string, i int, quote byte, backslashEscapes bool) (int, bool) {
+	for j := i + 1; j < len(sql); j++ {
+		if backslashEscapes && sql[j] == '\\' {
+			j++
+			continue
+		}
+		if sql[j] == quote {
+			if j+1 < len(sql) && sql[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1, true
+		}
+	}
+	return 0, false
+}
+
+func snippet(sql string, i int) string {
+	end := i + 20
+	if end > len(sql) {
+		end = len(sql)
+	}
+	return sql[i:end]
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isWordChar(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// ─── Parser ──────────────────────────────────────────────────────
+
+// reservedWords cannot be used as bare column names or aliases
+var reservedWords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "HAVING": true, "ORDER": true,
+	"LIMIT": true, "OFFSET": true, "UNION": true, "INTERSECT": true, "EXCEPT": true, "JOIN": true,
+	"ON": true, "USING": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true,
+	"CROSS": true, "NATURAL": true, "OUTER": true, "AND": true, "OR": true, "NOT": true,
+	"AS": true, "BY": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true, "CASE": true,
+	"IN": true, "IS": true, "LIKE": true, "ILIKE": true, "GLOB": true, "REGEXP": true,
+	"BETWEEN": true, "DISTINCT": true, "ALL": true, "WITH": true, "VALUES": true, "NULL": true,
+	"EXISTS": true, "ASC": true, "DESC": true, "WINDOW": true, "FETCH": true, "ESCAPE": true,
+	"COLLATE": true,
+}
+
+type sqlParser struct {
+	toks    []sqlToken
+	i       int
+	dialect string
+}
+
+func (p *sqlParser) peek() sqlToken { return p.toks[p.i] }
+
+func (p *sqlParser) peekAt(n int) sqlToken {
+	if p.i+n < len(p.toks) {
+		return p.toks[p.i+n]
+	}
+	return p.toks[len(p.toks)-1]
+}
+
+func (p *sqlParser) next() sqlToken {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *sqlParser) fail(msg string) {
+	t := p.peek()
+	panic(&SQLSyntaxError{Offset: t.pos, Near: t.text, Msg: msg})
+}
+
+func (p *sqlParser) isWord(kws ...string) bool {
+	t := p.peek()
+	if t.kind != tokWord {
+		return false
+	}
+	for _, kw := range kws {
+		if t.upper == kw {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *sqlParser) wordAt(n int, kws ...string) bool {
+	t := p.peekAt(n)
+	if t.kind != tokWord {
+		return false
+	}
+	for _, kw := range kws {
+		if t.upper == kw {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *sqlParser) acceptWord(kws ...string) bool {
+	if p.isWord(kws...) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) expectWord(kw string) {
+	if !p.acceptWord(kw) {
+		p.fail("expected " + kw)
+	}
+}
+
+func (p *sqlParser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == op
+}
+
+func (p *sqlParser) acceptOp(op string) bool {
+	if p.isOp(op) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) expectOp(op string) {
+	if !p.acceptOp(op) {
+		p.fail(fmt.Sprintf("expected %q", op))
+	}
+}
+
+// isName reports whether the current token can be an identifier (bare non-reserved word or quoted)
+func (p *sqlParser) isName() bool {
+	t := p.peek()
+	return t.kind == tokQuoted || (t.kind == tokWord && !reservedWords[t.upper])
+}
+
+func (p *sqlParser) expectName(what string) {
+	if !p.isName() {
+		p.fail("expected " + what)
+	}
+	p.i++
+}
+
+// skipBalanced consumes a parenthesized token group (window specs, WITHIN GROUP)
+func (p *sqlParser) skipBalanced() {
+	p.expectOp("(")
+	depth := 1
+	for depth > 0 {
+		t := p.next()
+		switch {
+		case t.kind == tokEOF:
+			p.fail("unbalanced parentheses")
+		case t.kind == tokOp && t.text == "(":
+			depth++
+		case t.kind == tokOp && t.text == ")":
+			depth--
+		}
+	}
+}
+
+// parseQuery: [WITH [RECURSIVE] cte, ...] compound
+func (p *sqlParser) parseQuery() {
+	if p.acceptWord("WITH") {
+		p.acceptWord("RECURSIVE")
+		for {
+			p.expectName("CTE name")
+			if p.isOp("(") {
+				p.parseNameList()
+			}
+			p.expectWord("AS")
+			p.acceptWord("NOT")
+			p.acceptWord("MATERIALIZED")
+			p.expectOp("(")
+			p.parseQuery()
+			p.expectOp(")")
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+	p.parseCompound()
+}
+
+// parseCompound: core {set-op core} [ORDER BY] [LIMIT/OFFSET/FETCH]
+func (p *sqlParser) parseCompound() {
+	p.parseSelectCore()
+	for p.isWord("UNION", "INTERSECT", "EXCEPT") || (p.dialect == "postgresql" && p.isWord("MINUS")) {
+		p.next()
+		p.acceptWord("ALL", "DISTINCT")
+		p.parseSelectCore()
+	}
+
+	if p.acceptWord("ORDER") {
+		p.expectWord("BY")
+		p.parseOrderList()
+	}
+	if p.acceptWord("LIMIT") {
+		if !(p.dialect == "postgresql" && p.acceptWord("ALL")) {
+			p.parseExpr()
+		}
+		if p.isOp(",") {
+			if p.dialect == "postgresql" {
+				p.fail("LIMIT offset, count is not valid in PostgreSQL, use LIMIT count OFFSET offset")
+			}
+			p.next()
+			p.parseExpr()
+		}
+	}
+	if p.acceptWord("OFFSET") {
+		p.parseExpr()
+		p.acceptWord("ROW", "ROWS")
+	}
+	if p.isWord("FETCH") {
+		if p.dialect != "postgresql" && p.dialect != "" {
+			p.fail("FETCH FIRST is not supported in this dialect, use LIMIT")
+		}
+		p.next()
+		p.expectWordOneOf("FIRST", "NEXT")
+		if !p.isWord("ROW", "ROWS") {
+			p.parseExpr()
+		}
+		p.expectWordOneOf("ROW", "ROWS")
+		p.expectWordOneOf("ONLY", "WITH")
+	}
+}
+
+func (p *sqlParser) expectWordOneOf(kws ...string) {
+	if !p.acceptWord(kws...) {
+		p.fail("expected " + strings.Join(kws, " or "))
+	}
+}
+
+// parseSelectCore: SELECT ... | VALUES (...) | ( query )
+func (p *sqlParser) parseSelectCore() {
+	if p.acceptOp("(") {
+		p.parseQuery()
+		p.expectOp(")")
+		return
+	}
+	if p.acceptWord("VALUES") {
+		for {
+			p.expectOp("(")
+			p.parseExprList()
+			p.expectOp(")")
+			if !p.acceptOp(",") {
+				return
+			}
+		}
+	}
+
+	p.expectWord("SELECT")
+	if p.acceptWord("DISTINCT") {
+		if p.dialect == "postgresql" && p.acceptWord("ON") {
+			p.expectOp("(")
+			p.parseExprList()
+			p.expectOp(")")
+		}
+	} else {
+		p.acceptWord("ALL")
+	}
+	if p.isWord("TOP") && p.peekAt(1).kind == tokNumber {
+		p.fail("TOP n is not valid here, use LIMIT n")
+	}
+
+	p.parseResultColumns()
+
+	if p.acceptWord("FROM") {
+		p.parseFromClause()
+	}
+	if p.acceptWord("WHERE") {
+		p.parseExpr()
+	}
+	if p.acceptWord("GROUP") {
+		p.expectWord("BY")
+		p.parseExprList()
+		if p.dialect == "mysql" && p.isWord("WITH") && p.wordAt(1, "ROLLUP") {
+			p.i += 2
+		}
+	}
+	if p.acceptWord("HAVING") {
+		p.parseExpr()
+	}
+	if p.acceptWord("WINDOW") {
+		for {
+			p.expectName("window name")
+			p.expectWord("AS")
+			p.skipBalanced()
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+}
+
+func (p *sqlParser) parseResultColumns() {
+	for {
+		if p.isOp(",") || p.isWord("FROM") {
+			p.fail("expected a column expression (empty item or trailing comma in SELECT list)")
+		}
+		if !p.acceptOp("*") {
+			p.parseExpr()
+			p.parseOptionalAlias()
+		}
+		if !p.acceptOp(",") {
+			return
+		}
+	}
+}
+
+// parseOptionalAlias: [AS] alias, reports whether an alias was present
+func (p *sqlParser) parseOptionalAlias() bool {
+	if p.acceptWord("AS") {
+		t := p.peek()
+		if p.isName() || (t.kind == tokString && p.dialect != "postgresql") ||
+			(t.kind == tokWord && !clauseKeywords[t.upper]) {
+			p.next()
+			return true
+		}
+		p.fail("expected alias after AS")
+	}
+	if p.isName() || (p.peek().kind == tokString && p.dialect != "postgresql") {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) parseNameList() {
+	p.expectOp("(")
+	for {
+		p.expectName("column name")
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+	p.expectOp(")")
+}
+
+func (p *sqlParser) parseOrderList() {
+	for {
+		p.parseExpr()
+		p.acceptWord("ASC", "DESC")
+		if p.acceptWord("NULLS") {
+			p.expectWordOneOf("FIRST", "LAST")
+		}
+		if !p.acceptOp(",") {
+			return
+		}
+	}
+}
+
+func (p *sqlParser) parseExprList() {
+	for {
+		p.parseExpr()
+		if !p.acceptOp(",") {
+			return
+		}
+	}
+}
+
+// parseFromClause: table_ref {, table_ref | join}
+func (p *sqlParser) parseFromClause() {
+	p.parseTableRef()
+	for {
+		if p.acceptOp(",") {
+			p.parseTableRef()
+			continue
+		}
+		if !p.parseJoin() {
+			return
+		}
+	}
+}
+
+// parseJoin parses one join if present
+func (p *sqlParser) parseJoin() bool {
+	start := p.i
+	natural := p.acceptWord("NATURAL")
+	switch {
+	case p.isWord("FULL"):
+		if p.dialect == "mysql" {
+			p.fail("MySQL does not support FULL OUTER JOIN, combine LEFT and RIGHT joins with UNION")
+		}
+		p.next()
+		p.acceptWord("OUTER")
+	case p.acceptWord("LEFT", "RIGHT"):
+		p.acceptWord("OUTER")
+	case p.acceptWord("INNER", "CROSS"):
+	case p.acceptWord("STRAIGHT_JOIN"):
+		p.parseTableRef()
+		p.parseJoinConstraint()
+		return true
+	}
+	if !p.acceptWord("JOIN") {
+		if p.i != start || natural {
+			p.fail("expected JOIN")
+		}
+		return false
+	}
+	p.parseTableRef()
+	p.parseJoinConstraint()
+	return true
+}
+
+func (p *sqlParser) parseJoinConstraint() {
+	if p.acceptWord("ON") {
+		p.parseExpr()
+	} else if p.acceptWord("USING") {
+		p.parseNameList()
+	}
+}
+
+// parseTableRef: name [alias] | (query) alias | (from) | func(args) [alias]
+func (p *sqlParser) parseTableRef() {
+	p.acceptWord("LATERAL")
+	if p.acceptOp("(") {
+		if p.isWord("SELECT", "WITH", "VALUES") || p.isOp("(") {
+			p.parseQuery()
+		} else {
+			p.parseFromClause()
+		}
+		p.expectOp(")")
+	} else {
+		p.expectName("table name")
+		for p.acceptOp(".") {
+			p.expectName("table name")
+		}
+		if p.isOp("(") {
+			p.parseCallArgs()
+		}
+	}
+	if p.parseOptionalAlias() && p.isOp("(") {
+		p.parseNameList() // alias(col1, col2)
+	}
+	if p.dialect == "sqlite" && p.isWord("INDEXED") {
+		p.next()
+		p.expectWord("BY")
+		p.expectName("index name")
+	} else if p.dialect == "sqlite" && p.isWord("NOT") && p.wordAt(1, "INDEXED") {
+		p.i += 2
+	}
+}
+
+// ─── Expressions (precedence climbing) ───────────────────────────
+
+func (p *sqlParser) parseExpr() {
+	p.parseAnd()
+	for p.acceptWord("OR") || (p.dialect == "mysql" && p.acceptWord("XOR")) {
+		p.parseAnd()
+	}
+}
+
+func (p *sqlParser) parseAnd() {
+	p.parseNot()
+	for p.acceptWord("AND") {
+		p.parseNot()
+	}
+}
+
+func (p *sqlParser) parseNot() {
+	if p.acceptWord("NOT") {
+		p.parseNot()
+		return
+	}
+	p.parseComparison()
+}
+
+var comparisonOps = map[string]bool{
+	"=": true, "==": true, "!=": true, "<>": true, "<": true, "<=": true, ">": true, ">=": true,
+}
+
+func (p *sqlParser) parseComparison() {
+	p.parseAdditive()
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokOp && comparisonOps[t.text],
+			t.kind == tokOp && t.text == "<=>" && p.dialect == "mysql",
+			t.kind == tokOp && (t.text == "~" || t.text == "~*" || t.text == "!~" || t.text == "!~*") && p.dialect == "postgresql":
+			p.next()
+			if p.acceptWord("ANY", "ALL", "SOME") {
+				p.expectOp("(")
+				p.parseSubqueryOrExprList()
+				p.expectOp(")")
+			} else {
+				p.parseAdditive()
+			}
+		case p.isWord("IS"):
+			p.next()
+			p.acceptWord("NOT")
+			if p.acceptWord("DISTINCT") {
+				p.expectWord("FROM")
+			}
+			p.parseAdditive()
+		case p.isWord("ISNULL", "NOTNULL"):
+			p.next()
+		case p.isWord("NOT") && p.wordAt(1, "IN", "LIKE", "ILIKE", "GLOB", "REGEXP", "RLIKE", "MATCH", "BETWEEN", "SIMILAR"):
+			p.next()
+			p.parsePredicate()
+		case p.isWord("IN", "LIKE", "ILIKE", "GLOB", "REGEXP", "RLIKE", "MATCH", "BETWEEN", "SIMILAR"):
+			p.parsePredicate()
+		default:
+			return
+		}
+	}
+}
+
+// parsePredicate: IN (...) | LIKE x [ESCAPE y] | BETWEEN a AND b
+func (p *sqlParser) parsePredicate() {
+	switch {
+	case p.acceptWord("IN"):
+		if !p.acceptOp("(") {
+			if p.dialect == "sqlite" && p.isName() {
+				p.next() // SQLite: x IN table
+				return
+			}
+			p.fail("expected ( after IN")
+		}
+		if !p.isOp(")") {
+			p.parseSubqueryOrExprList()
+		}
+		p.expectOp(")")
+	case p.isWord("BETWEEN"):
+		p.next()
+		p.parseAdditive()
+		p.expectWord("AND")
+		p.parseAdditive()
+	default:
+		kw := p.next().upper
+		if kw == "ILIKE" && p.dialect != "postgresql" && p.dialect != "" {
+			p.i--
+			p.fail("ILIKE is PostgreSQL-only, use LIKE (or LOWER(x) LIKE LOWER(y))")
+		}
+		if kw == "SIMILAR" {
+			p.expectWord("TO")
+		}
+		p.parseAdditive()
+		if p.acceptWord("ESCAPE") {
+			p.parseAdditive()
+		}
+	}
+}
+
+func (p *sqlParser) parseSubqueryOrExprList() {
+	if p.isWord("SELECT", "WITH", "VALUES") {
+		p.parseQuery()
+		return
+	}
+	p.parseExprList()
+}
+
+var additiveOps = map[string]bool{"+": true, "-": true, "||": true, "&": true, "|": true, "<<": true, ">>": true}
+
+func (p *sqlParser) parseAdditive() {
+	p.parseMultiplicative()
+	for {
+		t := p.peek()
+		if t.kind == tokOp && additiveOps[t.text] {
+			p.next()
+			p.parseMultiplicative()
+			continue
+		}
+		if p.dialect == "mysql" && p.isWord("DIV", "MOD") {
+			p.next()
+			p.parseMultiplicative()
+			continue
+		}
+		return
+	}
+}
+
+func (p *sqlParser) parseMultiplicative() {
+	p.parseUnary()
+	for p.isOp("*") || p.isOp("/") || p.isOp("%") {
+		p.next()
+		p.parseUnary()
+	}
+}
+
+func (p *sqlParser) parseUnary() {
+	if p.acceptOp("-") || p.acceptOp("+") || p.acceptOp("~") {
+		p.parseUnary()
+		return
+	}
+	p.parsePostfix()
+}
+
+func (p *sqlParser) parsePostfix() {
+	p.parsePrimary()
+	for {
+		switch {
+		case p.isOp("::"):
+			if p.dialect != "postgresql" && p.dialect != "" {
+				p.fail(":: casts are PostgreSQL-only, use CAST(x AS type)")
+			}
+			p.next()
+			p.parseTypeName(false)
+		case p.acceptWord("COLLATE"):
+			p.expectName("collation name")
+		case p.isOp("->") || p.isOp("->>"):
+			p.next()
+			p.parsePrimary()
+		case p.isOp("[") && p.dialect == "postgresql":
+			p.next()
+			p.parseExpr()
+			p.expectOp("]")
+		default:
+			return
+		}
+	}
+}
+
+// parseTypeName: word {word} [(n [, m])] — multi-word only inside CAST(...)
+func (p *sqlParser) parseTypeName(multiWord bool) {
+	if p.peek().kind != tokWord && p.peek().kind != tokQuoted {
+		p.fail("expected type name")
+	}
+	p.next()
+	for multiWord && p.peek().kind == tokWord && !p.isWord("AS") {
+		p.next()
+	}
+	if p.acceptOp("(") {
+		p.parseExprList()
+		p.expectOp(")")
+	}
+	for p.isOp("[") && p.peekAt(1).kind == tokOp && p.peekAt(1).text == "]" {
+		p.i += 2
+	}
+	for multiWord && p.peek().kind == tokWord {
+		p.next() // e.g. UNSIGNED, WITH TIME ZONE
+	}
+}
+
+func (p *sqlParser) parsePrimary() {
+	t := p.peek()
+	switch t.kind {
+	case tokNumber, tokString, tokParam:
+		p.next()
+		return
+	case tokEOF:
+		p.fail("unexpected end of query")
+	case tokQuoted:
+		p.parseNameChain()
+		return
+	case tokOp:
+		if p.acceptOp("(") {
+			if p.isWord("SELECT", "WITH", "VALUES") {
+				p.parseQuery()
+			} else {
+				p.parseExprList()
+			}
+			p.expectOp(")")
+			return
+		}
+		p.fail("unexpected " + t.text)
+	}
+
+	// tokWord
+	switch t.upper {
+	case "NULL", "TRUE", "FALSE", "CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP":
+		p.next()
+		return
+	case "CASE":
+		p.parseCase()
+		return
+	case "CAST":
+		if p.peekAt(1).kind == tokOp && p.peekAt(1).text == "(" {
+			p.next()
+			p.expectOp("(")
+			p.parseExpr()
+			p.expectWord("AS")
+			p.parseTypeName(true)
+			p.expectOp(")")
+			return
+		}
+	case "EXISTS":
+		p.next()
+		p.expectOp("(")
+		p.parseQuery()
+		p.expectOp(")")
+		return
+	case "INTERVAL":
+		p.next()
+		if p.peek().kind == tokString {
+			p.next()
+		} else {
+			p.parseAdditive()
+		}
+		if p.peek().kind == tokWord && !reservedWords[p.peek().upper] {
+			p.next() // unit
+		}
+		return
+	case "DATE", "TIME", "TIMESTAMP":
+		if p.peekAt(1).kind == tokString {
+			p.i += 2
+			return
+		}
+	case "EXTRACT":
+		if p.peekAt(1).kind == tokOp && p.peekAt(1).text == "(" {
+			p.i += 2
+			if p.peek().kind != tokWord {
+				p.fail("expected date part")
+			}
+			p.next()
+			p.expectWord("FROM")
+			p.parseExpr()
+			p.expectOp(")")
+			return
+		}
+	}
+
+	// Function call: any word followed by '(' except clause keywords
+	if p.peekAt(1).kind == tokOp && p.peekAt(1).text == "(" && !clauseKeywords[t.upper] {
+		p.next()
+		p.parseCallArgs()
+		p.parseCallSuffix()
+		return
+	}
+
+	if reservedWords[t.upper] {
+		p.fail("unexpected keyword " + t.upper)
+	}
+	p.parseNameChain()
+}
+
+// clauseKeywords can never be a function name
+var clauseKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "HAVING": true, "ORDER": true,
+	"LIMIT": true, "ON": true, "JOIN": true, "AND": true, "OR": true, "NOT": true, "AS": true,
+	"IN": true, "IS": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true, "USING": true,
+}
+
+// parseNameChain: name {. name | . *}
+func (p *sqlParser) parseNameChain() {
+	p.next()
+	for p.acceptOp(".") {
+		if p.acceptOp("*") {
+			return
+		}
+		t := p.peek()
+		if t.kind != tokWord && t.kind != tokQuoted {
+			p.fail("expected name after '.'")
+		}
+		p.next()
+	}
+}
+
+// parseCallArgs: ( [*] | [DISTINCT|ALL] expr {, expr} [ORDER BY ...] [SEPARATOR str] )
+func (p *sqlParser) parseCallArgs() {
+	p.expectOp("(")
+	if p.acceptOp(")") {
+		return
+	}
+	if p.acceptOp("*") {
+		p.expectOp(")")
+		return
+	}
+	p.acceptWord("DISTINCT", "ALL")
+	p.parseExprList()
+	if p.acceptWord("ORDER") {
+		p.expectWord("BY")
+		p.parseOrderList()
+	}
+	if p.dialect == "mysql" && p.acceptWord("SEPARATOR") {
+		if p.peek().kind != tokString {
+			p.fail("expected string after SEPARATOR")
+		}
+		p.next()
+	}
+	if p.isWord("FROM", "FOR") {
+		// SUBSTRING(x FROM a FOR b), TRIM(... FROM x), POSITION(a IN b) style
+		for p.acceptWord("FROM", "FOR") {
+			p.parseExpr()
+		}
+	}
+	p.expectOp(")")
+}
+
+// parseCallSuffix: [WITHIN GROUP (...)] [FILTER (WHERE ...)] [OVER (...) | OVER name]
+func (p *sqlParser) parseCallSuffix() {
+	if p.isWord("WITHIN") && p.wordAt(1, "GROUP") {
+		p.i += 2
+		p.skipBalanced()
+	}
+	if p.isWord("FILTER") && p.peekAt(1).kind == tokOp && p.peekAt(1).text == "(" {
+		p.next()
+		p.expectOp("(")
+		p.expectWord("WHERE")
+		p.parseExpr()
+		p.expectOp(")")
+	}
+	if p.acceptWord("OVER") {
+		if p.isOp("(") {
+			p.skipBalanced()
+		} else {
+			p.expectName("window name")
+		}
+	}
+}
+
+// parseCase: CASE [expr] WHEN expr THEN expr {...} [ELSE expr] END
+func (p *sqlParser) parseCase() {
+	p.expectWord("CASE")
+	if !p.isWord("WHEN") {
+		p.parseExpr()
+	}
+	if !p.isWord("WHEN") {
+		p.fail("expected WHEN in CASE")
+	}
+	for p.acceptWord("WHEN") {
+		p.parseExpr()
+		p.expectWord("THEN")
+		p.parseExpr()
+	}
+	if p.acceptWord("ELSE") {
+		p.parseExpr()
+	}
+	p.expectWord("END")
+}
+
+// ─── Syntax repair ───────────────────────────────────────────────
+
+// maxSyntaxRepairRounds LLM repair attempts for a candidate SQL that fails to parse
+const maxSyntaxRepairRounds = 2
+
+// repairSyntax parses the candidate SQL and asks the LLM to fix parse failures
+// Returns the first candidate that parses; if every round fails, the last attempt is kept.
+func (p *Pipeline) repairSyntax(ctx context.Context, query, contextPrompt, sql string, result *Result) string {
+	dialect := p.generationDialect()
+
+	// The parser only knows queries; the database checks writes when they are dry-run
+	if p.config.WriteMode && writeStatementKind(sql, dialect) != "" {
+		return sql
+	}
+
+	for round := 1; ; round++ {
+		parseErr := ParseSQL(sql, dialect)
+		if parseErr == nil {
+			return sql
+		}
+		if round > maxSyntaxRepairRounds {
+			p.Logger.Printf("⚠️  SQL still fails to parse after %d repair rounds: %v\n", maxSyntaxRepairRounds, parseErr)
+			return sql
+		}
+
+		p.Logger.Printf("🔧 Syntax repair round %d/%d: %v\n", round, maxSyntaxRepairRounds, parseErr)
+
+		prompt := p.buildSyntaxRepairPrompt(query, contextPrompt, sql, parseErr)
+		response, err := p.llm.Call(ctx, prompt)
+		result.LLMCalls++
+		if err != nil {
+			p.Logger.Printf("⚠️  Syntax repair LLM call failed: %v\n", err)
+			return sql
+		}
+		p.promptTexts = append(p.promptTexts, prompt)
+		p.responseTexts = append(p.responseTexts, response)
+
+		repaired := p.extractSQL(response)
+		step := ReActStep{
+			Thought:     parseErr.Error(),
+			Action:      "syntax_repair",
+			ActionInput: sql,
+			Observation: repaired,
+			Phase:       "syntax_repair",
+		}
+		result.ReActSteps = append(result.ReActSteps, step)
+		p.notifyStep(step, "observation")
+
+		p.Logger.Printf("🔧 Repaired SQL: %s\n", repaired)
+		sql = repaired
+	}
+}
+
+// buildSyntaxRepairPrompt builds the prompt for fixing a SQL parse error
+func (p *Pipeline) buildSyntaxRepairPrompt(query, contextPrompt, sql string, parseErr error) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("The following %s query does not parse.\n\n", dialectDisplayName(p.generationDialect())))
+	sb.WriteString(fmt.Sprintf("Question: %s\n\n", query))
+	sb.WriteString("## Database Schema\n")
+	sb.WriteString(contextPrompt)
+	sb.WriteString("\n\n## Broken SQL\n")
+	sb.WriteString(sql)
+	sb.WriteString(fmt.Sprintf("\n\n## Parser Error\n%s\n\n", parseErr.Error()))
+	sb.WriteString(fmt.Sprintf("Fix the syntax so the query is a single valid %s SELECT statement that still answers the question. ", dialectDisplayName(p.generationDialect())))
+	sb.WriteString("Keep the same tables, columns and logic unless they caused the error.\n")
+	sb.WriteString("Output ONLY the corrected SQL after \"Final Answer:\".\n")
+	return sb.String()
+}
@@ -0,0 +1,185 @@
+package inference
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// CleanSQL deterministic cleanup of SQL extracted from an LLM response
+// Strips markdown and surrounding prose, closes an unterminated quote, drops comments and
+// anything after the first top-level semicolon, quotes reserved words used as identifiers
+// (T1.order, AS group) for the dialect ("" = double quotes), and collapses whitespace
+// outside literals so the result is a single line.
+func CleanSQL(sql, dialect string) string {
+	sql = stripMarkdown(sql)
+	sql = stripSurroundingProse(sql)
+	return normalizeSQLText(sql, NormalizeDialect(dialect))
+}
+
+// inlineSQLPattern SQL wrapped in single backticks inside a sentence: `SELECT ...`
+var inlineSQLPattern = regexp.MustCompile("(?is)`((?:SELECT|WITH)\\b[^`]*)`")
+
+// stripMarkdown removes code fences and inline-code wrapping
+func stripMarkdown(text string) string {
+	text = strings.TrimSpace(text)
+
+	if strings.Contains(text, "```") {
+		// Keep the first fenced block when there is one, otherwise just drop fence lines
+		if start := strings.Index(text, "```"); start >= 0 {
+			rest := text[start+3:]
+			if nl := strings.IndexByte(rest, '\n'); nl >= 0 {
+				body := rest[nl+1:]
+				if end := strings.Index(body, "```"); end >= 0 {
+					return strings.TrimSpace(body[:end])
+				}
+				return strings.TrimSpace(body)
+			}
+		}
+		text = strings.ReplaceAll(text, "```sql", "")
+		text = strings.ReplaceAll(text, "```", "")
+	}
+
+	if m := inlineSQLPattern.FindStringSubmatch(text); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return strings.TrimSpace(text)
+}
+
+// proseMarkers line prefixes that end the SQL (explanations after the query)
+var proseMarkers = []string{
+	"This ", "The ", "Since ", "Note:", "Note ", "Explanation", "Thought:", "Here ", "In this ", "I ", "**",
+}
+
+// stripSurroundingProse drops lines before the statement and explanatory lines after it
+func stripSurroundingProse(text string) string {
+	lines := strings.Split(text, "\n")
+
+	start := -1
+	for i, line := range lines {
+		if startsStatement(line) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return text
+	}
+
+	var kept []string
+	for _, line := range lines[start:] {
+		trimmed := strings.TrimSpace(line)
+		if len(kept) > 0 && hasProseMarker(trimmed) {
+			break
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+func startsStatement(line string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(line))
+	for _, kw := range []string{"SELECT", "WITH", "(", "INSERT", "UPDATE", "DELETE"} {
+		if strings.HasPrefix(upper, kw) {
+			rest := upper[len(kw):]
+			return kw == "(" || rest == "" || !isIdentRune(rune(rest[0]))
+		}
+	}
+	return false
+}
+
+func hasProseMarker(line string) bool {
+	for _, marker := range proseMarkers {
+		if strings.HasPrefix(line, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSQLText rewrites the statement piece by piece; literals are copied verbatim
+func normalizeSQLText(sql, dialect string) string {
+	var sb strings.Builder
+	pendingSpace := false
+	prevWord := ""     // last significant word (upper-cased)
+	prevSig := byte(0) // last significant non-space byte
+
+	emit := func(s string) {
+		if pendingSpace && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		pendingSpace = false
+		sb.WriteString(s)
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pendingSpace = true
+			i++
+
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			pendingSpace = true
+
+		case c == '#' && dialect == DialectMySQL:
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			pendingSpace = true
+
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 4
+			}
+			pendingSpace = true
+
+		case c == ';':
+			// Anything after the first statement is dropped
+			return strings.TrimSpace(sb.String())
+
+		case c == '\'' || c == '"' || c == '`':
+			end, ok := scanQuoted(sql, i, c, c == '\'' && dialect == DialectMySQL)
+			if !ok {
+				end = len(sql)
+			}
+			text := sql[i:end]
+			if !ok {
+				text += string(c) // balance an unterminated quote
+			}
+			emit(text)
+			prevWord, prevSig = "", c
+			i = end
+
+		case isIdentRune(rune(c)) || c >= 0x80:
+			start := i
+			for i < len(sql) && (isIdentRune(rune(sql[i])) || sql[i] >= 0x80) {
+				i++
+			}
+			word := sql[start:i]
+			upper := strings.ToUpper(word)
+			// NOT after AS: WITH t AS NOT MATERIALIZED (...)
+			if reservedWords[upper] && (prevSig == '.' || (prevWord == "AS" && upper != "NOT")) {
+				word = quoteIdent(word, dialect)
+			}
+			emit(word)
+			prevWord, prevSig = upper, word[len(word)-1]
+
+		default:
+			emit(string(c))
+			prevWord, prevSig = "", c
+			i++
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func isIdentRune(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
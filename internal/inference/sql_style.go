@@ -0,0 +1,807 @@
+package inference
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SQL style preferences (Config.Style)
+const (
+	StyleCTE           = "cte"      // Subqueries: FROM-clause subqueries become WITH common table expressions
+	StyleNested        = "nested"   // Subqueries: single-use CTEs are inlined as FROM-clause subqueries
+	StyleExplicitJoins = "explicit" // Joins: JOIN ... ON instead of comma-separated tables
+	StyleAliasAS       = "as"       // Aliases: always written with AS
+	StyleAliasShort    = "short"    // Aliases: tables aliased T1, T2, ... in FROM order
+)
+
+// SQLStyle generation preferences of a team's SQL style guide ("" = no preference)
+// Each preference is stated in the generation prompt and enforced on the final query by
+// rewrites that keep its meaning; a query a rewrite cannot handle safely is left as written.
+type SQLStyle struct {
+	Subqueries string // StyleCTE | StyleNested
+	Joins      string // StyleExplicitJoins
+	Aliases    string // StyleAliasAS | StyleAliasShort
+}
+
+// ParseSQLStyle parses a comma-separated preference list, e.g. "cte,explicit,as"
+func ParseSQLStyle(text string) (SQLStyle, error) {
+	var style SQLStyle
+	for _, part := range strings.Split(text, ",") {
+		switch pref := strings.ToLower(strings.TrimSpace(part)); pref {
+		case "":
+		case StyleCTE, StyleNested:
+			style.Subqueries = pref
+		case StyleExplicitJoins:
+			style.Joins = pref
+		case StyleAliasAS, StyleAliasShort:
+			style.Aliases = pref
+		default:
+			return SQLStyle{}, fmt.Errorf("unknown SQL style preference %q (want cte, nested, explicit, as, short)", pref)
+		}
+	}
+	return style, nil
+}
+
+// IsZero reports whether no preference is set
+func (s SQLStyle) IsZero() bool {
+	return s == SQLStyle{}
+}
+
+// prompt style guide section of the generation prompt ("" = no preferences)
+func (s SQLStyle) prompt() string {
+	var rules []string
+	switch s.Subqueries {
+	case StyleCTE:
+		rules = append(rules, "Write subqueries used as tables as WITH common table expressions, not nested in FROM.")
+	case StyleNested:
+		rules = append(rules, "Nest subqueries in FROM instead of defining WITH common table expressions.")
+	}
+	if s.Joins == StyleExplicitJoins {
+		rules = append(rules, "Write every join as JOIN ... ON; never list tables separated by commas in FROM.")
+	}
+	switch s.Aliases {
+	case StyleAliasAS:
+		rules = append(rules, "Always write aliases with AS (singer AS s, COUNT(*) AS total).")
+	case StyleAliasShort:
+		rules = append(rules, "Alias tables T1, T2, ... in the order they appear in FROM and qualify columns with these aliases.")
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SQL Style Guide:\n")
+	for _, rule := range rules {
+		sb.WriteString("- " + rule + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// restyleSQL applies the style rewrites to a query, returning one note per rewrite that changed it
+// A rewrite whose output does not parse is discarded.
+func restyleSQL(sql string, style SQLStyle, dialect string) (string, []string) {
+	if ParseSQL(sql, dialect) != nil {
+		return sql, nil
+	}
+
+	var rewrites []func(string, string) (string, string)
+	switch style.Subqueries {
+	case StyleCTE:
+		rewrites = append(rewrites, hoistSubqueries)
+	case StyleNested:
+		rewrites = append(rewrites, inlineCTEs)
+	}
+	if style.Joins == StyleExplicitJoins {
+		rewrites = append(rewrites, explicitJoins)
+	}
+	switch style.Aliases {
+	case StyleAliasAS:
+		rewrites = append(rewrites, explicitAliases)
+	case StyleAliasShort:
+		rewrites = append(rewrites, shortAliases)
+	}
+
+	var changes []string
+	for _, rewrite := range rewrites {
+		out, note := rewrite(sql, dialect)
+		if out == sql || ParseSQL(out, dialect) != nil {
+			continue
+		}
+		sql = out
+		changes = append(changes, note)
+	}
+	return sql, changes
+}
+
+// applySQLStyle enforces Config.Style on the final query
+func (p *Pipeline) applySQLStyle(sql string, result *Result) string {
+	dialect := p.generationDialect()
+	if p.config.Style.IsZero() || writeStatementKind(sql, dialect) != "" {
+		return sql
+	}
+	styled, changes := restyleSQL(sql, p.config.Style, dialect)
+	if len(changes) == 0 {
+		return sql
+	}
+	p.Logger.Printf("🎨 SQL style applied (%s): %s\n", strings.Join(changes, ", "), styled)
+	result.StyleRewrites = changes
+	return styled
+}
+
+// ─── Statement structure ─────────────────────────────────────────
+
+// selectBlock token ranges of one SELECT ... [FROM ...] [WHERE ...] block
+type selectBlock struct {
+	depth           int // Parenthesis depth of the SELECT keyword
+	selectAt        int // SELECT token
+	listEnd         int // First token after the SELECT list
+	from, fromEnd   int // FROM list (from = -1: none)
+	where, whereEnd int // WHERE condition (where = -1: none)
+}
+
+// tableRef one table reference of a FROM list
+type tableRef struct {
+	start, end  int  // Token range
+	name        int  // Table name token, last part of the chain (-1: parenthesized)
+	chain       int  // Parts in the name chain (schema.table = 2)
+	open, close int  // Parentheses of a subquery or parenthesized join (-1: none)
+	subquery    bool // (SELECT ...) derived table
+	call        bool // Table-valued function
+	lateral     bool
+	as          int  // AS token (-1: none)
+	alias       int  // Alias token (-1: none)
+	columns     bool // Alias with a column list: AS t(a, b)
+}
+
+// fromKeywords non-reserved words that can follow a table reference without being its alias
+var fromKeywords = map[string]bool{
+	"LATERAL": true, "STRAIGHT_JOIN": true, "INDEXED": true, "USE": true, "FORCE": true,
+	"IGNORE": true, "TABLESAMPLE": true,
+}
+
+// tokenDepths parenthesis depth of each token; "(" and ")" get the depth outside them
+func tokenDepths(toks []sqlToken) []int {
+	depths := make([]int, len(toks))
+	depth := 0
+	for i, tok := range toks {
+		if isSQLOp(tok, ")") {
+			depth--
+		}
+		depths[i] = depth
+		if isSQLOp(tok, "(") {
+			depth++
+		}
+	}
+	return depths
+}
+
+// matchParen index of the ")" closing the "(" at open
+func matchParen(toks []sqlToken, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch {
+		case isSQLOp(toks[i], "("):
+			depth++
+		case isSQLOp(toks[i], ")"):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(toks) - 1
+}
+
+func isSQLOp(tok sqlToken, op string) bool {
+	return tok.kind == tokOp && tok.text == op
+}
+
+func isSQLWord(tok sqlToken, words ...string) bool {
+	if tok.kind != tokWord {
+		return false
+	}
+	for _, w := range words {
+		if tok.upper == w {
+			return true
+		}
+	}
+	return false
+}
+
+// tokEnd byte offset after the token
+func tokEnd(tok sqlToken) int {
+	return tok.pos + len(tok.text)
+}
+
+// refName lower-cased identifier of a name or alias token
+func refName(tok sqlToken) string {
+	if tok.kind == tokQuoted {
+		return strings.ToLower(unquoteIdent(tok.text))
+	}
+	return strings.ToLower(tok.text)
+}
+
+// selectBlocks every SELECT block of the statement, in text order
+func selectBlocks(toks []sqlToken, depths []int) []selectBlock {
+	var blocks []selectBlock
+	for i, tok := range toks {
+		if !isSQLWord(tok, "SELECT") {
+			continue
+		}
+		b := selectBlock{depth: depths[i], selectAt: i, listEnd: -1, from: -1, fromEnd: -1, where: -1, whereEnd: -1}
+		closeClause := func(j int) {
+			if b.listEnd < 0 {
+				b.listEnd = j
+			}
+			if b.from >= 0 && b.fromEnd < 0 {
+				b.fromEnd = j
+			}
+			if b.where >= 0 && b.whereEnd < 0 {
+				b.whereEnd = j
+			}
+		}
+	scan:
+		for j := i + 1; j < len(toks); j++ {
+			t := toks[j]
+			switch {
+			case depths[j] < b.depth || t.kind == tokEOF || (depths[j] == b.depth && isSQLOp(t, ";")):
+				closeClause(j)
+				break scan
+			case depths[j] != b.depth || t.kind != tokWord:
+			case t.upper == "FROM" && b.listEnd < 0:
+				closeClause(j)
+				b.from = j + 1
+			case t.upper == "WHERE" && b.where < 0:
+				closeClause(j)
+				b.where = j + 1
+			case compoundKeywords[t.upper]:
+				closeClause(j)
+				break scan
+			case selectListEnd[t.upper] && !isSQLWord(toks[j-1], "WITHIN"): // WITHIN GROUP (ORDER BY ...)
+				closeClause(j)
+			}
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+// fromRefs table references of a block's FROM list, joined by commas or JOIN
+func fromRefs(toks []sqlToken, depths []int, b selectBlock) []tableRef {
+	if b.from < 0 {
+		return nil
+	}
+	var refs []tableRef
+	expect := true
+	for i := b.from; i < b.fromEnd; i++ {
+		t := toks[i]
+		if depths[i] != b.depth {
+			continue
+		}
+		if !expect {
+			expect = isSQLOp(t, ",") || isSQLWord(t, "JOIN", "STRAIGHT_JOIN")
+			continue
+		}
+
+		ref := tableRef{start: i, name: -1, open: -1, close: -1, as: -1, alias: -1}
+		j := i
+		if isSQLWord(toks[j], "LATERAL") {
+			ref.lateral = true
+			j++
+		}
+		switch {
+		case isSQLOp(toks[j], "("):
+			ref.open, ref.close = j, matchParen(toks, j)
+			ref.subquery = isSQLWord(toks[j+1], "SELECT", "WITH")
+			j = ref.close + 1
+		case toks[j].kind == tokWord || toks[j].kind == tokQuoted:
+			for {
+				ref.name = j
+				ref.chain++
+				j++
+				if isSQLOp(toks[j], ".") && (toks[j+1].kind == tokWord || toks[j+1].kind == tokQuoted) {
+					j++
+					continue
+				}
+				break
+			}
+			if isSQLOp(toks[j], "(") {
+				ref.call = true
+				j = matchParen(toks, j) + 1
+			}
+		default:
+			return refs
+		}
+		if j < b.fromEnd && isSQLWord(toks[j], "AS") {
+			ref.as = j
+			j++
+		}
+		if j < b.fromEnd && (toks[j].kind == tokQuoted || (toks[j].kind == tokWord && !reservedWords[toks[j].upper] && !fromKeywords[toks[j].upper])) {
+			ref.alias = j
+			j++
+			if isSQLOp(toks[j], "(") {
+				ref.columns = true
+				j = matchParen(toks, j) + 1
+			}
+		}
+		ref.end = j
+		refs = append(refs, ref)
+		expect = false
+		i = j - 1
+	}
+	return refs
+}
+
+// refLabel lower-cased name the rest of the query uses for a table reference ("" = none)
+func refLabel(toks []sqlToken, ref tableRef) string {
+	switch {
+	case ref.alias >= 0:
+		return refName(toks[ref.alias])
+	case ref.name >= 0 && !ref.call:
+		return refName(toks[ref.name])
+	}
+	return ""
+}
+
+// textEdit replacement of sql[start:end]
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits applies non-overlapping edits
+func applyEdits(sql string, edits []textEdit) string {
+	sort.Slice(edits, func(a, b int) bool { return edits[a].start > edits[b].start })
+	for _, e := range edits {
+		sql = sql[:e.start] + e.text + sql[e.end:]
+	}
+	return sql
+}
+
+// ─── Joins ───────────────────────────────────────────────────────
+
+// explicitJoins rewrites comma-separated FROM lists as JOIN ... ON, moving the qualified
+// equalities of the WHERE clause that link the tables into the ON conditions
+func explicitJoins(sql, dialect string) (string, string) {
+	joins := 0
+	for {
+		toks, err := tokenizeSQL(sql, dialect)
+		if err != nil {
+			break
+		}
+		depths := tokenDepths(toks)
+		changed := false
+		for _, b := range selectBlocks(toks, depths) {
+			if out, n := joinCommaList(sql, toks, depths, b); n > 0 {
+				sql, joins, changed = out, joins+n, true
+				break
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return sql, fmt.Sprintf("comma joins made explicit: %d", joins)
+}
+
+// joinCommaList rewrites one block's comma list, returning the number of joins written
+func joinCommaList(sql string, toks []sqlToken, depths []int, b selectBlock) (string, int) {
+	refs := fromRefs(toks, depths, b)
+	if len(refs) < 2 {
+		return sql, 0
+	}
+	// Only pure comma lists: mixing commas with JOIN changes precedence in MySQL
+	var segments [][2]int
+	segStart := b.from
+	for i := b.from; i < b.fromEnd; i++ {
+		if depths[i] != b.depth {
+			continue
+		}
+		if isSQLWord(toks[i], "JOIN", "STRAIGHT_JOIN", "ON", "USING", "NATURAL") {
+			return sql, 0
+		}
+		if isSQLOp(toks[i], ",") {
+			segments = append(segments, [2]int{segStart, i})
+			segStart = i + 1
+		}
+	}
+	segments = append(segments, [2]int{segStart, b.fromEnd})
+	if len(segments) != len(refs) {
+		return sql, 0
+	}
+
+	owner := make(map[string]int) // Reference label → index (-1 = ambiguous)
+	for k, ref := range refs {
+		if ref.lateral {
+			return sql, 0
+		}
+		label := refLabel(toks, ref)
+		if label == "" {
+			continue
+		}
+		if _, dup := owner[label]; dup {
+			owner[label] = -1
+			continue
+		}
+		owner[label] = k
+	}
+
+	conjuncts := whereConjuncts(toks, depths, b)
+	onConds := make([][]string, len(refs))
+	var remaining []string
+	for _, c := range conjuncts {
+		text := sql[toks[c[0]].pos:tokEnd(toks[c[1]-1])]
+		if a, z, ok := joinEquality(toks[c[0]:c[1]]); ok {
+			ka, okA := owner[a]
+			kz, okZ := owner[z]
+			if okA && okZ && ka >= 0 && kz >= 0 && ka != kz {
+				onConds[max(ka, kz)] = append(onConds[max(ka, kz)], text)
+				continue
+			}
+		}
+		remaining = append(remaining, text)
+	}
+
+	segText := func(k int) string {
+		return sql[toks[segments[k][0]].pos:tokEnd(toks[segments[k][1]-1])]
+	}
+	var sb strings.Builder
+	sb.WriteString(segText(0))
+	for k := 1; k < len(refs); k++ {
+		if len(onConds[k]) == 0 {
+			sb.WriteString(" CROSS JOIN " + segText(k))
+			continue
+		}
+		sb.WriteString(" JOIN " + segText(k) + " ON " + strings.Join(onConds[k], " AND "))
+	}
+
+	end := tokEnd(toks[b.fromEnd-1])
+	if b.where >= 0 {
+		end = tokEnd(toks[b.whereEnd-1])
+		if len(remaining) > 0 {
+			sb.WriteString(" " + toks[b.where-1].text + " " + strings.Join(remaining, " AND "))
+		}
+	}
+	edit := textEdit{start: toks[b.from].pos, end: end, text: sb.String()}
+	return applyEdits(sql, []textEdit{edit}), len(refs) - 1
+}
+
+// whereConjuncts token ranges of the top-level AND terms of a block's WHERE clause
+// A clause with a top-level OR is returned whole.
+func whereConjuncts(toks []sqlToken, depths []int, b selectBlock) [][2]int {
+	if b.where < 0 || b.whereEnd <= b.where {
+		return nil
+	}
+	var terms [][2]int
+	start, between := b.where, false
+	for i := b.where; i < b.whereEnd; i++ {
+		if depths[i] != b.depth {
+			continue
+		}
+		switch {
+		case isSQLWord(toks[i], "OR"):
+			return [][2]int{{b.where, b.whereEnd}}
+		case isSQLWord(toks[i], "BETWEEN"):
+			between = true
+		case isSQLWord(toks[i], "AND") && between:
+			between = false
+		case isSQLWord(toks[i], "AND"):
+			terms = append(terms, [2]int{start, i})
+			start = i + 1
+		}
+	}
+	return append(terms, [2]int{start, b.whereEnd})
+}
+
+// joinEquality qualifiers of a "a.x = b.y" term, lower-cased
+func joinEquality(toks []sqlToken) (string, string, bool) {
+	isName := func(t sqlToken) bool { return t.kind == tokQuoted || (t.kind == tokWord && !reservedWords[t.upper]) }
+	if len(toks) != 7 || !isName(toks[0]) || !isSQLOp(toks[1], ".") || !isName(toks[2]) || !isSQLOp(toks[3], "=") ||
+		!isName(toks[4]) || !isSQLOp(toks[5], ".") || !isName(toks[6]) {
+		return "", "", false
+	}
+	return refName(toks[0]), refName(toks[4]), true
+}
+
+// ─── Aliases ─────────────────────────────────────────────────────
+
+// explicitAliases writes AS before every table and column alias that omits it
+func explicitAliases(sql, dialect string) (string, string) {
+	toks, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return sql, ""
+	}
+	depths := tokenDepths(toks)
+	at := make(map[int]int, len(toks)) // Byte offset → token
+	for i, t := range toks {
+		at[t.pos] = i
+	}
+
+	var edits []textEdit
+	for _, b := range selectBlocks(toks, depths) {
+		for _, ref := range fromRefs(toks, depths, b) {
+			if ref.alias >= 0 && ref.as < 0 {
+				edits = append(edits, textEdit{start: toks[ref.alias].pos, end: toks[ref.alias].pos, text: "AS "})
+			}
+		}
+
+		listStart := b.selectAt + 1
+		for listStart < b.listEnd && isSQLWord(toks[listStart], "DISTINCT", "ALL") {
+			listStart++
+		}
+		if listStart >= b.listEnd {
+			continue
+		}
+		for _, item := range splitProjection(sql, toks[listStart:b.listEnd]) {
+			if item.alias == "" {
+				continue
+			}
+			i, ok := at[item.end-len(item.alias)]
+			if ok && i > 0 && !isSQLWord(toks[i-1], "AS") {
+				edits = append(edits, textEdit{start: toks[i].pos, end: toks[i].pos, text: "AS "})
+			}
+		}
+	}
+	if len(edits) == 0 {
+		return sql, ""
+	}
+	return applyEdits(sql, edits), fmt.Sprintf("AS added to aliases: %d", len(edits))
+}
+
+// shortAliases aliases the tables of a single-block query T1, T2, ... in FROM order and
+// requalifies the columns; queries with subqueries or CTEs are left as written
+func shortAliases(sql, dialect string) (string, string) {
+	toks, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return sql, ""
+	}
+	depths := tokenDepths(toks)
+	blocks := selectBlocks(toks, depths)
+	if len(blocks) != 1 || blocks[0].depth != 0 || !isSQLWord(toks[0], "SELECT") {
+		return sql, ""
+	}
+	refs := fromRefs(toks, depths, blocks[0])
+	if len(refs) == 0 {
+		return sql, ""
+	}
+
+	rename := make(map[string]string) // Old label → new alias
+	inChain := make(map[int]bool)     // Tokens of the FROM name chains
+	done := true
+	for k, ref := range refs {
+		if ref.name < 0 || ref.call || ref.columns {
+			return sql, ""
+		}
+		label := refLabel(toks, ref)
+		if _, dup := rename[label]; dup {
+			return sql, ""
+		}
+		alias := fmt.Sprintf("T%d", k+1)
+		rename[label] = alias
+		if ref.as < 0 || toks[ref.alias].text != alias {
+			done = false
+		}
+		for i := ref.start; i <= ref.name; i++ {
+			inChain[i] = true
+		}
+	}
+	if done {
+		return sql, ""
+	}
+
+	var edits []textEdit
+	for k, ref := range refs {
+		alias := fmt.Sprintf("T%d", k+1)
+		switch {
+		case ref.alias >= 0 && ref.as >= 0:
+			edits = append(edits, textEdit{start: toks[ref.alias].pos, end: tokEnd(toks[ref.alias]), text: alias})
+		case ref.alias >= 0:
+			edits = append(edits, textEdit{start: toks[ref.alias].pos, end: tokEnd(toks[ref.alias]), text: "AS " + alias})
+		default:
+			edits = append(edits, textEdit{start: tokEnd(toks[ref.name]), end: tokEnd(toks[ref.name]), text: " AS " + alias})
+		}
+	}
+	for i, t := range toks {
+		if inChain[i] || (t.kind != tokWord && t.kind != tokQuoted) {
+			continue
+		}
+		name := refName(t)
+		if _, label := rename[name]; !label {
+			for k := range refs {
+				if name == fmt.Sprintf("t%d", k+1) {
+					return sql, "" // T<n> already names something else
+				}
+			}
+		}
+		if !isSQLOp(toks[i+1], ".") || (i > 0 && isSQLOp(toks[i-1], ".")) {
+			continue
+		}
+		if i+3 < len(toks) && isSQLOp(toks[i+3], ".") {
+			return sql, "" // schema.table.column
+		}
+		if alias, ok := rename[name]; ok {
+			edits = append(edits, textEdit{start: t.pos, end: tokEnd(t), text: alias})
+		}
+	}
+	return applyEdits(sql, edits), fmt.Sprintf("tables aliased T1..T%d", len(refs))
+}
+
+// ─── Subqueries ──────────────────────────────────────────────────
+
+// hoistSubqueries moves the aliased FROM-clause subqueries of the outer query into WITH
+func hoistSubqueries(sql, dialect string) (string, string) {
+	hoisted := 0
+	for {
+		toks, err := tokenizeSQL(sql, dialect)
+		if err != nil {
+			break
+		}
+		out := hoistSubquery(sql, toks)
+		if out == sql {
+			break
+		}
+		sql = out
+		hoisted++
+	}
+	return sql, fmt.Sprintf("subqueries moved to CTEs: %d", hoisted)
+}
+
+// hoistSubquery moves the first hoistable subquery, returning sql unchanged when there is none
+func hoistSubquery(sql string, toks []sqlToken) string {
+	depths := tokenDepths(toks)
+	for _, b := range selectBlocks(toks, depths) {
+		if b.depth != 0 {
+			continue
+		}
+		for _, ref := range fromRefs(toks, depths, b) {
+			if !ref.subquery || ref.lateral || ref.alias < 0 {
+				continue
+			}
+			// The CTE name must not be used for anything else (tables, CTEs, columns)
+			name := refName(toks[ref.alias])
+			taken := false
+			for i, t := range toks {
+				if i != ref.alias && (t.kind == tokWord || t.kind == tokQuoted) && refName(t) == name && !isSQLOp(toks[i+1], ".") {
+					taken = true
+					break
+				}
+			}
+			if taken {
+				continue
+			}
+
+			body := strings.TrimSpace(sql[tokEnd(toks[ref.open]):toks[ref.close].pos])
+			head := sql[toks[ref.alias].pos:tokEnd(toks[ref.end-1])] // Name and column list
+			def := head + " AS (" + body + ")"
+			edits := []textEdit{{start: toks[ref.start].pos, end: tokEnd(toks[ref.end-1]), text: toks[ref.alias].text}}
+			switch {
+			case isSQLWord(toks[0], "WITH") && b.selectAt > 0 && isSQLOp(toks[b.selectAt-1], ")"):
+				at := tokEnd(toks[b.selectAt-1])
+				edits = append(edits, textEdit{start: at, end: at, text: ", " + def})
+			case isSQLWord(toks[0], "SELECT"):
+				edits = append(edits, textEdit{start: toks[0].pos, end: toks[0].pos, text: "WITH " + def + " "})
+			default:
+				return sql
+			}
+			return applyEdits(sql, edits)
+		}
+	}
+	return sql
+}
+
+// cteDef one WITH list entry
+type cteDef struct {
+	start, end  int  // Token range, without the separating comma
+	name        int  // Name token
+	open, close int  // Body parentheses
+	plain       bool // No column list or MATERIALIZED hint
+}
+
+// inlineCTEs inlines CTEs referenced once, as a table, into a FROM-clause subquery
+func inlineCTEs(sql, dialect string) (string, string) {
+	inlined := 0
+	for {
+		toks, err := tokenizeSQL(sql, dialect)
+		if err != nil {
+			break
+		}
+		out := inlineCTE(sql, toks)
+		if out == sql {
+			break
+		}
+		sql = out
+		inlined++
+	}
+	return sql, fmt.Sprintf("CTEs inlined as subqueries: %d", inlined)
+}
+
+// inlineCTE inlines the first inlinable CTE, returning sql unchanged when there is none
+func inlineCTE(sql string, toks []sqlToken) string {
+	if !isSQLWord(toks[0], "WITH") || isSQLWord(toks[1], "RECURSIVE") {
+		return sql
+	}
+
+	// WITH list
+	var defs []cteDef
+	i := 1
+	for {
+		if toks[i].kind != tokWord && toks[i].kind != tokQuoted {
+			return sql
+		}
+		def := cteDef{start: i, name: i, plain: true}
+		i++
+		if isSQLOp(toks[i], "(") {
+			def.plain = false
+			i = matchParen(toks, i) + 1
+		}
+		if !isSQLWord(toks[i], "AS") {
+			return sql
+		}
+		i++
+		for !isSQLOp(toks[i], "(") {
+			if toks[i].kind == tokEOF {
+				return sql
+			}
+			def.plain = false // [NOT] MATERIALIZED
+			i++
+		}
+		def.open, def.close = i, matchParen(toks, i)
+		def.end = def.close + 1
+		defs = append(defs, def)
+		i = def.end
+		if !isSQLOp(toks[i], ",") {
+			break
+		}
+		i++
+	}
+	mainStart := i
+
+	depths := tokenDepths(toks)
+	refAt := make(map[int]tableRef) // Name token → reference
+	for _, b := range selectBlocks(toks, depths) {
+		for _, ref := range fromRefs(toks, depths, b) {
+			if ref.name >= 0 {
+				refAt[ref.name] = ref
+			}
+		}
+	}
+
+	for d, def := range defs {
+		if !def.plain {
+			continue
+		}
+		name := refName(toks[def.name])
+		uses := -1
+		count := 0
+		for j := def.end; j < len(toks); j++ {
+			t := toks[j]
+			if (t.kind == tokWord || t.kind == tokQuoted) && refName(t) == name &&
+				!isSQLOp(toks[j+1], ".") && !isSQLOp(toks[j-1], ".") {
+				uses, count = j, count+1
+			}
+		}
+		ref, ok := refAt[uses]
+		if count != 1 || !ok || ref.chain != 1 || ref.call || ref.lateral || ref.columns {
+			continue
+		}
+
+		alias := toks[def.name].text
+		if ref.alias >= 0 {
+			alias = toks[ref.alias].text
+		}
+		body := strings.TrimSpace(sql[tokEnd(toks[def.open]):toks[def.close].pos])
+		edits := []textEdit{{start: toks[ref.start].pos, end: tokEnd(toks[ref.end-1]), text: "(" + body + ") AS " + alias}}
+		switch {
+		case len(defs) == 1:
+			edits = append(edits, textEdit{start: toks[0].pos, end: toks[mainStart].pos})
+		case d < len(defs)-1:
+			edits = append(edits, textEdit{start: toks[def.start].pos, end: toks[defs[d+1].start].pos})
+		default:
+			edits = append(edits, textEdit{start: tokEnd(toks[defs[d-1].end-1]), end: tokEnd(toks[def.end-1])})
+		}
+		return applyEdits(sql, edits)
+	}
+	return sql
+}
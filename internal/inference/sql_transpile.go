@@ -0,0 +1,1041 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// SQL dialects understood by the parser and transpiler
+const (
+	DialectSQLite     = "sqlite"
+	DialectMySQL      = "mysql"
+	DialectPostgreSQL = "postgresql"
+)
+
+// NormalizeDialect maps a database type name ("SQLite", "postgres", ...) to a dialect constant ("" = unknown)
+func NormalizeDialect(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "sqlite", "sqlite3", "csv":
+		return DialectSQLite
+	case "mysql", "mariadb":
+		return DialectMySQL
+	case "postgresql", "postgres", "pg":
+		return DialectPostgreSQL
+	}
+	return ""
+}
+
+// dialectDisplayName returns the prompt-facing name of a dialect
+func dialectDisplayName(dialect string) string {
+	switch dialect {
+	case DialectSQLite:
+		return "SQLite"
+	case DialectMySQL:
+		return "MySQL"
+	case DialectPostgreSQL:
+		return "PostgreSQL"
+	}
+	return dialect
+}
+
+// TranspileSQL rewrites a query from one dialect to another
+// Covers what differs between the engines in text-to-SQL answers: identifier quoting, string
+// escapes, || concatenation, :: casts, CAST target types, LIMIT offset/count, ILIKE/LIKE case
+// semantics, and the common functions (IIF/IF, IFNULL, INSTR/STRPOS, GROUP_CONCAT/STRING_AGG,
+// strftime/DATE_FORMAT/TO_CHAR, YEAR/EXTRACT, RANDOM/RAND, NOW). Constructs with no equivalent
+// return an error rather than a silently different query; the output is re-parsed in the target dialect.
+func TranspileSQL(sql, from, to string) (string, error) {
+	from, to = NormalizeDialect(from), NormalizeDialect(to)
+	if from == "" || to == "" {
+		return "", fmt.Errorf("unsupported dialect pair: %q -> %q", from, to)
+	}
+	if from == to {
+		return sql, nil
+	}
+
+	toks, err := lexForRewrite(sql, from)
+	if err != nil {
+		return "", err
+	}
+
+	t := &transpiler{toks: toks, from: from, to: to}
+	passes := []func() error{t.rewriteLexical, t.rewriteCasts, t.rewriteFunctions, t.rewriteCastTypes, t.rewriteConcat, t.rewriteLimit}
+	for _, pass := range passes {
+		if err := pass(); err != nil {
+			return "", err
+		}
+	}
+
+	out := strings.TrimSpace(renderTokens(t.toks))
+	if err := ParseSQL(out, to); err != nil {
+		return "", fmt.Errorf("transpiled SQL is not valid %s: %w", dialectDisplayName(to), err)
+	}
+	return out, nil
+}
+
+// ─── Token stream ────────────────────────────────────────────────
+
+// xtok token with its source spacing, so untouched parts of the query keep their layout
+type xtok struct {
+	sqlToken
+	lead  string // whitespace / comments before the token in the source
+	synth bool   // synthesized: spacing is derived from the neighbours instead of lead
+}
+
+func lexForRewrite(sql, dialect string) ([]xtok, error) {
+	toks, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]xtok, len(toks))
+	prevEnd := 0
+	for i, t := range toks {
+		out[i] = xtok{sqlToken: t, lead: sql[prevEnd:t.pos]}
+		prevEnd = t.pos + len(t.text)
+	}
+	return out, nil
+}
+
+func renderTokens(toks []xtok) string {
+	var sb strings.Builder
+	for i, t := range toks {
+		if !t.synth {
+			sb.WriteString(t.lead)
+		} else if i > 0 && needsSpace(toks[i-1], t) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(t.text)
+	}
+	return sb.String()
+}
+
+func needsSpace(prev, t xtok) bool {
+	if t.kind == tokEOF {
+		return false
+	}
+	if t.kind == tokOp && (t.text == ")" || t.text == "," || t.text == ".") {
+		return false
+	}
+	if t.kind == tokOp && t.text == "(" {
+		return prev.kind != tokWord && !(prev.kind == tokOp && prev.text == "(")
+	}
+	if prev.kind == tokOp && (prev.text == "(" || prev.text == ".") {
+		return false
+	}
+	return true
+}
+
+func wordTok(text string) xtok {
+	return xtok{sqlToken: sqlToken{kind: tokWord, text: text, upper: strings.ToUpper(text)}, synth: true}
+}
+
+func opTok(text string) xtok {
+	return xtok{sqlToken: sqlToken{kind: tokOp, text: text}, synth: true}
+}
+
+// asArg copies a token span for use inside a synthesized construct (spacing re-derived at its start)
+func asArg(span []xtok) []xtok {
+	out := append([]xtok(nil), span...)
+	if len(out) > 0 {
+		out[0].synth = true
+	}
+	return out
+}
+
+// joinArgs builds "(a, b, ...)"
+func joinArgs(args ...[]xtok) []xtok {
+	out := []xtok{opTok("(")}
+	for i, arg := range args {
+		if i > 0 {
+			out = append(out, opTok(","))
+		}
+		out = append(out, asArg(arg)...)
+	}
+	return append(out, opTok(")"))
+}
+
+func isOpTok(t xtok, op string) bool { return t.kind == tokOp && t.text == op }
+
+// isFuncName reports whether a word before "(" is a function name rather than a keyword
+func isFuncName(t xtok) bool {
+	return t.kind == tokWord && (!reservedWords[t.upper] || t.upper == "LEFT" || t.upper == "RIGHT")
+}
+
+type transpiler struct {
+	toks     []xtok
+	from, to string
+}
+
+// splice replaces toks[start:end] with repl, giving repl the lead of the replaced span
+func (t *transpiler) splice(start, end int, repl []xtok) {
+	repl = append([]xtok(nil), repl...)
+	if len(repl) > 0 {
+		repl[0].lead = t.toks[start].lead
+		repl[0].synth = t.toks[start].synth
+	}
+	rest := append(repl, t.toks[end:]...)
+	t.toks = append(t.toks[:start], rest...)
+}
+
+func (t *transpiler) matchParen(i int) int {
+	depth := 0
+	for j := i; j < len(t.toks); j++ {
+		switch {
+		case isOpTok(t.toks[j], "("):
+			depth++
+		case isOpTok(t.toks[j], ")"):
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+func (t *transpiler) matchParenBack(i int) int {
+	depth := 0
+	for j := i; j >= 0; j-- {
+		switch {
+		case isOpTok(t.toks[j], ")"):
+			depth++
+		case isOpTok(t.toks[j], "("):
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// matchCase returns the END closing the CASE at i (or the CASE opening the END at i when back)
+func (t *transpiler) matchCase(i int, back bool) int {
+	depth := 0
+	step := 1
+	if back {
+		step = -1
+	}
+	for j := i; j >= 0 && j < len(t.toks); j += step {
+		tok := t.toks[j]
+		if tok.kind != tokWord {
+			continue
+		}
+		open, close := tok.upper == "CASE", tok.upper == "END"
+		if back {
+			open, close = close, open
+		}
+		if open {
+			depth++
+		} else if close {
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// operandStart returns where the operand ending at index end begins (-1 if not an operand)
+func (t *transpiler) operandStart(end int) int {
+	if end < 0 {
+		return -1
+	}
+	tok := t.toks[end]
+	switch {
+	case isOpTok(tok, ")"):
+		j := t.matchParenBack(end)
+		if j > 0 && isFuncName(t.toks[j-1]) {
+			j--
+		}
+		return j
+	case tok.kind == tokWord && tok.upper == "END":
+		return t.matchCase(end, true)
+	case tok.kind == tokWord || tok.kind == tokQuoted:
+		j := end
+		for j >= 2 && isOpTok(t.toks[j-1], ".") && (t.toks[j-2].kind == tokWord || t.toks[j-2].kind == tokQuoted) {
+			j -= 2
+		}
+		return j
+	case tok.kind == tokString || tok.kind == tokNumber || tok.kind == tokParam:
+		return end
+	}
+	return -1
+}
+
+// operandEnd returns the index just past the operand starting at start (-1 if not an operand)
+func (t *transpiler) operandEnd(start int) int {
+	if start >= len(t.toks) {
+		return -1
+	}
+	tok := t.toks[start]
+	switch {
+	case isOpTok(tok, "("):
+		if j := t.matchParen(start); j >= 0 {
+			return j + 1
+		}
+	case isOpTok(tok, "-") || isOpTok(tok, "+"):
+		return t.operandEnd(start + 1)
+	case tok.kind == tokWord && tok.upper == "CASE":
+		if j := t.matchCase(start, false); j >= 0 {
+			return j + 1
+		}
+	case isFuncName(tok) && start+1 < len(t.toks) && isOpTok(t.toks[start+1], "("):
+		if j := t.matchParen(start + 1); j >= 0 {
+			return j + 1
+		}
+	case tok.kind == tokWord || tok.kind == tokQuoted:
+		j := start + 1
+		for j+1 < len(t.toks) && isOpTok(t.toks[j], ".") && (t.toks[j+1].kind == tokWord || t.toks[j+1].kind == tokQuoted) {
+			j += 2
+		}
+		return j
+	case tok.kind == tokString || tok.kind == tokNumber || tok.kind == tokParam:
+		return start + 1
+	}
+	return -1
+}
+
+// splitArgs splits the tokens between "(" at open and its ")" on top-level commas
+func (t *transpiler) splitArgs(open, close int) [][]xtok {
+	var args [][]xtok
+	depth, last := 0, open+1
+	for j := open + 1; j < close; j++ {
+		switch {
+		case isOpTok(t.toks[j], "("):
+			depth++
+		case isOpTok(t.toks[j], ")"):
+			depth--
+		case isOpTok(t.toks[j], ",") && depth == 0:
+			args = append(args, t.toks[last:j])
+			last = j + 1
+		}
+	}
+	if last < close || len(args) > 0 {
+		args = append(args, t.toks[last:close])
+	}
+	return args
+}
+
+// ─── Literals ────────────────────────────────────────────────────
+
+// unquoteIdent strips identifier quotes ("x", `x`, [x])
+func unquoteIdent(text string) string {
+	inner := text[1 : len(text)-1]
+	switch text[0] {
+	case '"':
+		return strings.ReplaceAll(inner, `""`, `"`)
+	case '`':
+		return strings.ReplaceAll(inner, "``", "`")
+	}
+	return inner
+}
+
+func quoteIdent(name, dialect string) string {
+	if dialect == DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// unquoteString decodes a string literal (doubled-quote escapes; MySQL also backslash escapes)
+func unquoteString(text, dialect string) string {
+	quote := text[0]
+	inner := text[1 : len(text)-1]
+	if dialect != DialectMySQL {
+		return strings.ReplaceAll(inner, string(quote)+string(quote), string(quote))
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		switch {
+		case c == '\\' && i+1 < len(inner):
+			i++
+			switch inner[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '0':
+				sb.WriteByte(0)
+			case '%', '_':
+				sb.WriteByte('\\') // kept for LIKE patterns
+				sb.WriteByte(inner[i])
+			default:
+				sb.WriteByte(inner[i])
+			}
+		case c == quote && i+1 < len(inner) && inner[i+1] == quote:
+			sb.WriteByte(quote)
+			i++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func quoteString(value, dialect string) string {
+	if dialect == DialectMySQL {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func stringTok(value, dialect string) xtok {
+	return xtok{sqlToken: sqlToken{kind: tokString, text: quoteString(value, dialect)}, synth: true}
+}
+
+// ─── Passes ──────────────────────────────────────────────────────
+
+// rewriteLexical handles token-local differences: quoting, escapes, operators and keywords
+func (t *transpiler) rewriteLexical() error {
+	for i := range t.toks {
+		tok := &t.toks[i]
+		switch tok.kind {
+		case tokQuoted:
+			if t.from == DialectMySQL && tok.text[0] == '"' {
+				// MySQL (default sql_mode) reads "..." as a string
+				tok.kind = tokString
+				tok.text = quoteString(unquoteString(tok.text, t.from), t.to)
+				continue
+			}
+			tok.text = quoteIdent(unquoteIdent(tok.text), t.to)
+		case tokString:
+			tok.text = quoteString(unquoteString(tok.text, t.from), t.to)
+		case tokOp:
+			switch {
+			case tok.text == "||" && t.from == DialectMySQL:
+				tok.kind, tok.text, tok.upper = tokWord, "OR", "OR"
+			case tok.text == "==" && t.to != DialectSQLite:
+				tok.text = "="
+			case (tok.text == "~" || tok.text == "~*" || tok.text == "!~" || tok.text == "!~*") && t.from == DialectPostgreSQL:
+				return fmt.Errorf("PostgreSQL regex operator %s has no %s equivalent", tok.text, dialectDisplayName(t.to))
+			}
+		case tokWord:
+			switch tok.upper {
+			case "ILIKE":
+				if t.to != DialectPostgreSQL {
+					tok.text, tok.upper = "LIKE", "LIKE" // SQLite LIKE / MySQL _ci collations ignore case
+				}
+			case "LIKE":
+				if t.to == DialectPostgreSQL {
+					tok.text, tok.upper = "ILIKE", "ILIKE" // keep the case-insensitive source semantics
+				}
+			case "GLOB":
+				return fmt.Errorf("GLOB has no %s equivalent", dialectDisplayName(t.to))
+			case "FULL":
+				if t.to == DialectMySQL && i+1 < len(t.toks) && (t.toks[i+1].upper == "JOIN" || t.toks[i+1].upper == "OUTER") {
+					return fmt.Errorf("MySQL does not support FULL OUTER JOIN")
+				}
+			case "ON":
+				if i > 0 && t.toks[i-1].upper == "DISTINCT" && t.to != DialectPostgreSQL {
+					return fmt.Errorf("DISTINCT ON is PostgreSQL-only")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rewriteCasts turns PostgreSQL x::type into CAST(x AS type)
+func (t *transpiler) rewriteCasts() error {
+	if t.from != DialectPostgreSQL {
+		return nil
+	}
+	for i := 0; i < len(t.toks); i++ {
+		if !isOpTok(t.toks[i], "::") {
+			continue
+		}
+		start := t.operandStart(i - 1)
+		if start < 0 || i+1 >= len(t.toks) || t.toks[i+1].kind != tokWord {
+			return fmt.Errorf("cannot translate :: cast at offset %d", t.toks[i].pos)
+		}
+		end := i + 2
+		if end < len(t.toks) && isOpTok(t.toks[end], "(") {
+			end = t.matchParen(end) + 1
+		}
+		if end < len(t.toks) && isOpTok(t.toks[end], "[") {
+			return fmt.Errorf("array casts have no %s equivalent", dialectDisplayName(t.to))
+		}
+
+		repl := []xtok{wordTok("CAST"), opTok("(")}
+		repl = append(repl, asArg(t.toks[start:i])...)
+		repl = append(repl, wordTok("AS"))
+		repl = append(repl, asArg(t.toks[i+1:end])...)
+		repl = append(repl, opTok(")"))
+		t.splice(start, end, repl)
+		i = start
+	}
+	return nil
+}
+
+// rewriteFunctions maps function calls to the target's equivalents
+func (t *transpiler) rewriteFunctions() error {
+	for i := 0; i+1 < len(t.toks); i++ {
+		if !isFuncName(t.toks[i]) || !isOpTok(t.toks[i+1], "(") {
+			continue
+		}
+		close := t.matchParen(i + 1)
+		if close < 0 {
+			return fmt.Errorf("unbalanced parentheses")
+		}
+		repl, err := t.mapFunction(t.toks[i].upper, i+1, close)
+		if err != nil {
+			return err
+		}
+		if repl != nil {
+			t.splice(i, close+1, repl)
+		}
+	}
+	return nil
+}
+
+func (t *transpiler) mapFunction(name string, open, close int) ([]xtok, error) {
+	args := t.splitArgs(open, close)
+	to := t.to
+
+	switch name {
+	case "IFNULL", "NVL":
+		if to == DialectPostgreSQL {
+			return append([]xtok{wordTok("COALESCE")}, joinArgs(args...)...), nil
+		}
+	case "INSTR":
+		if to == DialectPostgreSQL {
+			return append([]xtok{wordTok("STRPOS")}, joinArgs(args...)...), nil
+		}
+	case "STRPOS":
+		if to != DialectPostgreSQL {
+			return append([]xtok{wordTok("INSTR")}, joinArgs(args...)...), nil
+		}
+	case "RAND", "RANDOM":
+		target := "RANDOM"
+		if to == DialectMySQL {
+			target = "RAND"
+		}
+		if name != target {
+			return append([]xtok{wordTok(target)}, joinArgs(args...)...), nil
+		}
+	case "NOW":
+		if to == DialectSQLite && len(args) == 0 {
+			return []xtok{wordTok("CURRENT_TIMESTAMP")}, nil
+		}
+	case "IIF", "IF":
+		if len(args) != 3 {
+			return nil, nil
+		}
+		switch to {
+		case DialectSQLite:
+			return append([]xtok{wordTok("IIF")}, joinArgs(args...)...), nil
+		case DialectMySQL:
+			return append([]xtok{wordTok("IF")}, joinArgs(args...)...), nil
+		default:
+			repl := []xtok{wordTok("CASE"), wordTok("WHEN")}
+			repl = append(repl, asArg(args[0])...)
+			repl = append(repl, wordTok("THEN"))
+			repl = append(repl, asArg(args[1])...)
+			repl = append(repl, wordTok("ELSE"))
+			repl = append(repl, asArg(args[2])...)
+			return append(repl, wordTok("END")), nil
+		}
+	case "GROUP_CONCAT", "STRING_AGG":
+		return t.mapStringAgg(name, open, close)
+	case "STRFTIME", "DATE_FORMAT", "TO_CHAR":
+		return t.mapDateFormat(name, args)
+	case "YEAR", "MONTH", "DAY":
+		if len(args) != 1 || to == DialectMySQL {
+			return nil, nil
+		}
+		return datePartExpr(name, args[0], to)
+	case "EXTRACT":
+		if to != DialectSQLite {
+			return nil, nil
+		}
+		inner := t.toks[open+1 : close]
+		if len(inner) < 3 || inner[0].kind != tokWord || inner[1].upper != "FROM" {
+			return nil, fmt.Errorf("cannot translate EXTRACT")
+		}
+		return datePartExpr(inner[0].upper, inner[2:], to)
+	case "DATE", "DATETIME":
+		// SQLite date('now') / datetime('now')
+		if to != DialectSQLite && len(args) == 1 && len(args[0]) == 1 && args[0][0].kind == tokString &&
+			strings.EqualFold(unquoteString(args[0][0].text, to), "now") {
+			if name == "DATE" {
+				return []xtok{wordTok("CURRENT_DATE")}, nil
+			}
+			return []xtok{wordTok("CURRENT_TIMESTAMP")}, nil
+		}
+	case "JULIANDAY":
+		if to != DialectSQLite {
+			return nil, fmt.Errorf("JULIANDAY has no %s equivalent", dialectDisplayName(to))
+		}
+	}
+	return nil, nil
+}
+
+// datePartFormats strftime format for each extractable date part
+var datePartFormats = map[string]string{
+	"YEAR": "%Y", "MONTH": "%m", "DAY": "%d", "HOUR": "%H", "MINUTE": "%M", "SECOND": "%S",
+}
+
+// datePartExpr builds the target's integer date-part extraction
+func datePartExpr(part string, arg []xtok, to string) ([]xtok, error) {
+	format, ok := datePartFormats[part]
+	if !ok {
+		return nil, fmt.Errorf("cannot translate date part %s to %s", part, dialectDisplayName(to))
+	}
+	if to == DialectSQLite {
+		repl := []xtok{wordTok("CAST"), opTok("("), wordTok("strftime")}
+		repl = append(repl, joinArgs([]xtok{stringTok(format, to)}, arg)...)
+		return append(repl, wordTok("AS"), wordTok("INTEGER"), opTok(")")), nil
+	}
+	repl := []xtok{wordTok("EXTRACT"), opTok("("), wordTok(part), wordTok("FROM")}
+	repl = append(repl, asArg(arg)...)
+	return append(repl, opTok(")")), nil
+}
+
+// mapStringAgg translates GROUP_CONCAT / STRING_AGG between their dialect-specific argument forms
+func (t *transpiler) mapStringAgg(name string, open, close int) ([]xtok, error) {
+	inner := t.toks[open+1 : close]
+	distinct := len(inner) > 0 && inner[0].upper == "DISTINCT"
+	if distinct {
+		inner = inner[1:]
+	}
+
+	// Split off ORDER BY / SEPARATOR at depth 0
+	orderIdx, sepIdx, depth := -1, -1, 0
+	for j, tok := range inner {
+		switch {
+		case isOpTok(tok, "("):
+			depth++
+		case isOpTok(tok, ")"):
+			depth--
+		case depth == 0 && tok.upper == "ORDER" && orderIdx < 0:
+			orderIdx = j
+		case depth == 0 && tok.upper == "SEPARATOR":
+			sepIdx = j
+		}
+	}
+	cut := len(inner)
+	var orderBy, sep []xtok
+	if orderIdx >= 0 {
+		cut = orderIdx
+		end := len(inner)
+		if sepIdx > orderIdx {
+			end = sepIdx
+		}
+		orderBy = inner[orderIdx:end]
+	}
+	if sepIdx >= 0 {
+		cut = min(cut, sepIdx)
+		end := len(inner)
+		if orderIdx > sepIdx {
+			end = orderIdx
+		}
+		sep = inner[sepIdx+1 : end]
+	}
+	exprs := splitTopLevelTokens(inner[:cut])
+	if len(exprs) == 0 {
+		return nil, fmt.Errorf("empty %s", name)
+	}
+
+	var value []xtok
+	switch {
+	case name == "GROUP_CONCAT" && t.from == DialectMySQL:
+		// MySQL concatenates all listed expressions per row
+		value = exprs[0]
+		if len(exprs) > 1 {
+			value = append([]xtok{wordTok("CONCAT")}, joinArgs(exprs...)...)
+		}
+	default:
+		value = exprs[0]
+		if len(exprs) > 1 {
+			sep = exprs[1]
+		}
+	}
+	defaultSep := sep == nil
+	if defaultSep {
+		sep = []xtok{stringTok(",", t.to)}
+	}
+
+	var repl []xtok
+	switch t.to {
+	case DialectMySQL:
+		repl = []xtok{wordTok("GROUP_CONCAT"), opTok("(")}
+		if distinct {
+			repl = append(repl, wordTok("DISTINCT"))
+		}
+		repl = append(repl, asArg(value)...)
+		if orderBy != nil {
+			repl = append(repl, asArg(orderBy)...)
+		}
+		repl = append(repl, wordTok("SEPARATOR"))
+		repl = append(repl, asArg(sep)...)
+	case DialectSQLite:
+		if distinct && !defaultSep {
+			return nil, fmt.Errorf("SQLite GROUP_CONCAT(DISTINCT ...) cannot take a separator")
+		}
+		repl = []xtok{wordTok("GROUP_CONCAT"), opTok("(")}
+		if distinct {
+			repl = append(repl, wordTok("DISTINCT"))
+		}
+		repl = append(repl, asArg(value)...)
+		if !distinct {
+			repl = append(repl, opTok(","))
+			repl = append(repl, asArg(sep)...)
+		}
+		if orderBy != nil {
+			repl = append(repl, asArg(orderBy)...)
+		}
+	default:
+		repl = []xtok{wordTok("STRING_AGG"), opTok("(")}
+		if distinct {
+			repl = append(repl, wordTok("DISTINCT"))
+		}
+		repl = append(repl, wordTok("CAST"), opTok("("))
+		repl = append(repl, asArg(value)...)
+		repl = append(repl, wordTok("AS"), wordTok("TEXT"), opTok(")"), opTok(","))
+		repl = append(repl, asArg(sep)...)
+		if orderBy != nil {
+			repl = append(repl, asArg(orderBy)...)
+		}
+	}
+	return append(repl, opTok(")")), nil
+}
+
+// splitTopLevelTokens splits a token span on depth-0 commas
+func splitTopLevelTokens(toks []xtok) [][]xtok {
+	if len(toks) == 0 {
+		return nil
+	}
+	var parts [][]xtok
+	depth, last := 0, 0
+	for j, tok := range toks {
+		switch {
+		case isOpTok(tok, "("):
+			depth++
+		case isOpTok(tok, ")"):
+			depth--
+		case isOpTok(tok, ",") && depth == 0:
+			parts = append(parts, toks[last:j])
+			last = j + 1
+		}
+	}
+	return append(parts, toks[last:])
+}
+
+// dateFormatSpecs equivalent date format fields: strftime, DATE_FORMAT, TO_CHAR
+var dateFormatSpecs = []struct{ sqlite, mysql, pg string }{
+	{"%Y", "%Y", "YYYY"},
+	{"%m", "%m", "MM"},
+	{"%j", "%j", "DDD"}, // before DD
+	{"%d", "%d", "DD"},
+	{"%H", "%H", "HH24"},
+	{"%M", "%i", "MI"},
+	{"%S", "%s", "SS"},
+}
+
+// mapDateFormat translates strftime / DATE_FORMAT / TO_CHAR date formatting
+func (t *transpiler) mapDateFormat(name string, args [][]xtok) ([]xtok, error) {
+	native := map[string]string{DialectSQLite: "STRFTIME", DialectMySQL: "DATE_FORMAT", DialectPostgreSQL: "TO_CHAR"}
+	if native[t.to] == name {
+		return nil, nil
+	}
+
+	var formatArg, dateArg []xtok
+	switch name {
+	case "STRFTIME":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("strftime with modifiers has no %s equivalent", dialectDisplayName(t.to))
+		}
+		formatArg, dateArg = args[0], args[1]
+	default:
+		if len(args) != 2 {
+			return nil, nil
+		}
+		dateArg, formatArg = args[0], args[1]
+	}
+	if len(formatArg) != 1 || formatArg[0].kind != tokString {
+		return nil, fmt.Errorf("%s needs a literal format to translate", strings.ToLower(name))
+	}
+	format, err := convertDateFormat(unquoteString(formatArg[0].text, t.to), t.from, t.to)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite 'now' as the date argument
+	if len(dateArg) == 1 && dateArg[0].kind == tokString && strings.EqualFold(unquoteString(dateArg[0].text, t.to), "now") && t.to != DialectSQLite {
+		dateArg = []xtok{wordTok("CURRENT_TIMESTAMP")}
+	}
+
+	fmtTok := []xtok{stringTok(format, t.to)}
+	switch t.to {
+	case DialectSQLite:
+		return append([]xtok{wordTok("strftime")}, joinArgs(fmtTok, dateArg)...), nil
+	case DialectMySQL:
+		return append([]xtok{wordTok("DATE_FORMAT")}, joinArgs(dateArg, fmtTok)...), nil
+	default:
+		cast := []xtok{wordTok("CAST"), opTok("(")}
+		cast = append(cast, asArg(dateArg)...)
+		cast = append(cast, wordTok("AS"), wordTok("TIMESTAMP"), opTok(")"))
+		return append([]xtok{wordTok("TO_CHAR")}, joinArgs(cast, fmtTok)...), nil
+	}
+}
+
+// convertDateFormat rewrites a date format string between dialects
+func convertDateFormat(format, from, to string) (string, error) {
+	spec := func(i int, dialect string) string {
+		switch dialect {
+		case DialectSQLite:
+			return dateFormatSpecs[i].sqlite
+		case DialectMySQL:
+			return dateFormatSpecs[i].mysql
+		}
+		return dateFormatSpecs[i].pg
+	}
+
+	var sb strings.Builder
+	fields := 0
+	for i := 0; i < len(format); {
+		matched := false
+		for k := range dateFormatSpecs {
+			s := spec(k, from)
+			if from == DialectPostgreSQL {
+				if !strings.HasPrefix(strings.ToUpper(format[i:]), s) {
+					continue
+				}
+			} else if !strings.HasPrefix(format[i:], s) {
+				continue
+			}
+			sb.WriteString(spec(k, to))
+			i += len(s)
+			fields++
+			matched = true
+			break
+		}
+		if matched {
+			continue
+		}
+
+		c := format[i]
+		switch {
+		case from != DialectPostgreSQL && c == '%' && strings.HasPrefix(format[i:], "%%"):
+			c = '%'
+			i++
+		case from != DialectPostgreSQL && c == '%':
+			return "", fmt.Errorf("unsupported date format field %q", format[i:min(i+2, len(format))])
+		case from == DialectPostgreSQL && isWordChar(c):
+			return "", fmt.Errorf("unsupported date format field near %q", format[i:])
+		}
+		i++
+
+		switch {
+		case to == DialectPostgreSQL && isWordChar(c):
+			sb.WriteString(`"` + string(c) + `"`)
+		case to != DialectPostgreSQL && c == '%':
+			sb.WriteString("%%")
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if fields == 0 {
+		return "", fmt.Errorf("format %q has no date fields", format)
+	}
+	return sb.String(), nil
+}
+
+// rewriteCastTypes maps CAST(... AS type) target types to the target's names
+func (t *transpiler) rewriteCastTypes() error {
+	for i := 0; i+1 < len(t.toks); i++ {
+		if t.toks[i].upper != "CAST" || !isOpTok(t.toks[i+1], "(") {
+			continue
+		}
+		close := t.matchParen(i + 1)
+		if close < 0 {
+			return fmt.Errorf("unbalanced parentheses")
+		}
+		// Last depth-1 AS
+		as, depth := -1, 0
+		for j := i + 1; j < close; j++ {
+			switch {
+			case isOpTok(t.toks[j], "("):
+				depth++
+			case isOpTok(t.toks[j], ")"):
+				depth--
+			case depth == 1 && t.toks[j].upper == "AS":
+				as = j
+			}
+		}
+		if as < 0 || as+1 >= close {
+			continue
+		}
+		if repl := mapCastType(t.toks[as+1:close], t.to); repl != nil {
+			t.splice(as+1, close, repl)
+		}
+	}
+	return nil
+}
+
+// mapCastType returns the target's spelling of a CAST type (nil = keep)
+func mapCastType(typ []xtok, to string) []xtok {
+	name := typ[0].upper
+	if len(typ) > 1 && name == "DOUBLE" && typ[1].upper == "PRECISION" {
+		name = "DOUBLE PRECISION"
+	}
+	hasArgs := len(typ) > 1 && isOpTok(typ[len(typ)-1], ")")
+
+	var kind string
+	switch name {
+	case "INT", "INTEGER", "BIGINT", "SMALLINT", "SIGNED", "UNSIGNED", "INT4", "INT8":
+		kind = "int"
+	case "REAL", "FLOAT", "DOUBLE", "DOUBLE PRECISION", "FLOAT8":
+		kind = "float"
+	case "TEXT", "VARCHAR", "CHAR", "CHARACTER", "NVARCHAR", "STRING":
+		kind = "text"
+	case "DATETIME", "TIMESTAMP":
+		kind = "timestamp"
+	default:
+		return nil // DECIMAL/NUMERIC(p,s), DATE, ... are spelled the same everywhere
+	}
+
+	var out string
+	switch to {
+	case DialectSQLite:
+		out = map[string]string{"int": "INTEGER", "float": "REAL", "text": "TEXT", "timestamp": "TEXT"}[kind]
+	case DialectMySQL:
+		out = map[string]string{"int": "SIGNED", "float": "DOUBLE", "text": "CHAR", "timestamp": "DATETIME"}[kind]
+	default:
+		out = map[string]string{"int": "BIGINT", "float": "DOUBLE PRECISION", "text": "TEXT", "timestamp": "TIMESTAMP"}[kind]
+		if kind == "int" && (name == "INTEGER" || name == "INT" || name == "INT4" || name == "SMALLINT") {
+			out = "INTEGER"
+		}
+	}
+	if kind == "text" && hasArgs && to != DialectSQLite {
+		return nil // VARCHAR(n) / CHAR(n) are valid in MySQL and PostgreSQL
+	}
+
+	var repl []xtok
+	for _, w := range strings.Fields(out) {
+		repl = append(repl, wordTok(w))
+	}
+	return repl
+}
+
+// rewriteConcat turns a || b || c into CONCAT(a, b, c) for MySQL
+func (t *transpiler) rewriteConcat() error {
+	if t.to != DialectMySQL || t.from == DialectMySQL {
+		return nil
+	}
+	for i := 0; i < len(t.toks); i++ {
+		if !isOpTok(t.toks[i], "||") {
+			continue
+		}
+		start := t.operandStart(i - 1)
+		if start < 0 {
+			return fmt.Errorf("cannot translate || at offset %d", t.toks[i].pos)
+		}
+		operands := [][]xtok{t.toks[start:i]}
+		j := i
+		for j < len(t.toks) && isOpTok(t.toks[j], "||") {
+			end := t.operandEnd(j + 1)
+			if end < 0 {
+				return fmt.Errorf("cannot translate || at offset %d", t.toks[j].pos)
+			}
+			operands = append(operands, t.toks[j+1:end])
+			j = end
+		}
+		t.splice(start, j, append([]xtok{wordTok("CONCAT")}, joinArgs(operands...)...))
+		i = start
+	}
+	return nil
+}
+
+// rewriteLimit turns LIMIT offset, count into LIMIT count OFFSET offset for PostgreSQL
+func (t *transpiler) rewriteLimit() error {
+	if t.to != DialectPostgreSQL {
+		return nil
+	}
+	for i := 0; i < len(t.toks); i++ {
+		if t.toks[i].upper != "LIMIT" {
+			continue
+		}
+		comma, end, depth := -1, len(t.toks), 0
+	scan:
+		for j := i + 1; j < len(t.toks); j++ {
+			tok := t.toks[j]
+			switch {
+			case isOpTok(tok, "("):
+				depth++
+			case isOpTok(tok, ")") && depth == 0, isOpTok(tok, ";"), tok.kind == tokEOF:
+				end = j
+				break scan
+			case isOpTok(tok, ")"):
+				depth--
+			case isOpTok(tok, ",") && depth == 0:
+				comma = j
+			case tok.kind == tokWord && depth == 0 && reservedWords[tok.upper]:
+				end = j
+				break scan
+			}
+		}
+		if comma < 0 {
+			continue
+		}
+		offset := append([]xtok(nil), t.toks[i+1:comma]...)
+		count := append([]xtok(nil), t.toks[comma+1:end]...)
+		repl := []xtok{t.toks[i]}
+		repl = append(repl, asArg(count)...)
+		repl = append(repl, wordTok("OFFSET"))
+		repl = append(repl, asArg(offset)...)
+		t.splice(i, end, repl)
+	}
+	return nil
+}
+
+// ─── Adapter ─────────────────────────────────────────────────────
+
+// transpilingAdapter accepts SQL in the generation dialect and runs it on the target database
+// Used for the LLM-facing tools so exploration queries work while the model writes its own dialect.
+type transpilingAdapter struct {
+	adapter.DBAdapter
+	from, to string
+}
+
+// newQueryAdapter wraps db when the configured generation dialect differs from the database's
+func newQueryAdapter(db adapter.DBAdapter, config *Config) adapter.DBAdapter {
+	from := NormalizeDialect(config.GenerationDialect)
+	to := NormalizeDialect(db.GetDatabaseType())
+	if from == "" || to == "" || from == to {
+		return db
+	}
+	return &transpilingAdapter{DBAdapter: db, from: from, to: to}
+}
+
+// GetDatabaseType reports the dialect queries are accepted in
+func (a *transpilingAdapter) GetDatabaseType() string {
+	return dialectDisplayName(a.from)
+}
+
+// ExecuteQuery transpiles then executes
+func (a *transpilingAdapter) ExecuteQuery(ctx context.Context, query string) (*adapter.QueryResult, error) {
+	translated, err := TranspileSQL(query, a.from, a.to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate %s SQL to %s: %w", dialectDisplayName(a.from), dialectDisplayName(a.to), err)
+	}
+	return a.DBAdapter.ExecuteQuery(ctx, translated)
+}
+
+// DryRunSQL transpiles then validates
+func (a *transpilingAdapter) DryRunSQL(ctx context.Context, sql string) error {
+	translated, err := TranspileSQL(sql, a.from, a.to)
+	if err != nil {
+		return fmt.Errorf("failed to translate %s SQL to %s: %w", dialectDisplayName(a.from), dialectDisplayName(a.to), err)
+	}
+	return a.DBAdapter.DryRunSQL(ctx, translated)
+}
@@ -0,0 +1,70 @@
+package inference
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// builtinToolNames ReAct tools created by reactLoop
+var builtinToolNames = []string{"execute_sql", "verify_sql", "verify_result_fields", "compare_sql", "clarify_fields", "update_rich_context"}
+
+// RegisterTool adds a domain tool (metric dictionary lookup, date resolver, ...) to the ReAct loop
+// The tool is listed in the prompt with the first line of its Description.
+func (p *Pipeline) RegisterTool(tool tools.Tool) error {
+	name := strings.TrimSpace(tool.Name())
+	if name == "" {
+		return fmt.Errorf("tool name is empty")
+	}
+	for _, builtin := range builtinToolNames {
+		if strings.EqualFold(name, builtin) {
+			return fmt.Errorf("tool %q conflicts with a built-in tool", name)
+		}
+	}
+	for _, registered := range p.customTools {
+		if strings.EqualFold(name, registered.Name()) {
+			return fmt.Errorf("tool %q is already registered", name)
+		}
+	}
+	p.customTools = append(p.customTools, tool)
+	return nil
+}
+
+// toolEnabled reports whether Config.DisabledTools leaves the tool on
+func (p *Pipeline) toolEnabled(name string) bool {
+	for _, disabled := range p.config.DisabledTools {
+		if strings.EqualFold(strings.TrimSpace(disabled), name) {
+			return false
+		}
+	}
+	return true
+}
+
+// enabledTools appends the registered tools and drops disabled ones
+func (p *Pipeline) enabledTools(builtins []tools.Tool) []tools.Tool {
+	all := append(builtins, p.customTools...)
+	enabled := make([]tools.Tool, 0, len(all))
+	for _, tool := range all {
+		if p.toolEnabled(tool.Name()) {
+			enabled = append(enabled, tool)
+		}
+	}
+	return enabled
+}
+
+// customToolPrompt tool-list lines for the enabled registered tools
+func (p *Pipeline) customToolPrompt() string {
+	var sb strings.Builder
+	for _, tool := range p.customTools {
+		if !p.toolEnabled(tool.Name()) {
+			continue
+		}
+		summary := strings.TrimSpace(tool.Description())
+		if idx := strings.IndexByte(summary, '\n'); idx >= 0 {
+			summary = strings.TrimSpace(summary[:idx])
+		}
+		sb.WriteString(fmt.Sprintf("\n- %s: %s", tool.Name(), summary))
+	}
+	return sb.String()
+}
@@ -0,0 +1,122 @@
+package inference
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TrajectoryVersion current trajectory format version (bump on incompatible changes)
+const TrajectoryVersion = 1
+
+// Trajectory versioned record of one inference run: every ReAct step plus the LLM exchanges behind them
+type Trajectory struct {
+	Version      int           `json:"version"`
+	Query        string        `json:"query"`
+	GeneratedSQL string        `json:"generated_sql"`
+	Steps        []ReActStep   `json:"steps"`
+	Exchanges    []LLMExchange `json:"exchanges,omitempty"` // In call order
+}
+
+// LLMExchange one prompt sent to the LLM and its response
+type LLMExchange struct {
+	Prompt   string `json:"prompt"`
+	Response string `json:"response,omitempty"`
+}
+
+// buildTrajectory snapshots the run's steps and recorded LLM exchanges
+func (p *Pipeline) buildTrajectory(result *Result) *Trajectory {
+	t := &Trajectory{
+		Version:      TrajectoryVersion,
+		Query:        result.Query,
+		GeneratedSQL: result.GeneratedSQL,
+		Steps:        make([]ReActStep, len(result.ReActSteps)),
+	}
+	for i, step := range result.ReActSteps {
+		step.Step = i + 1
+		t.Steps[i] = step
+	}
+	for i, prompt := range p.promptTexts {
+		exchange := LLMExchange{Prompt: prompt}
+		if i < len(p.responseTexts) {
+			exchange.Response = p.responseTexts[i]
+		}
+		t.Exchanges = append(t.Exchanges, exchange)
+	}
+	return t
+}
+
+// RenderTrajectory pretty-prints a trajectory grouped by phase; withExchanges appends the full prompts and responses
+func RenderTrajectory(w io.Writer, t *Trajectory, withExchanges bool) {
+	fmt.Fprintf(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Fprintf(w, "🧭 Trajectory (v%d, %d steps, %d LLM exchanges)\n", t.Version, len(t.Steps), len(t.Exchanges))
+	fmt.Fprintf(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Fprintf(w, "Question: %s\n", t.Query)
+
+	phase := ""
+	for i, step := range t.Steps {
+		if step.Phase != phase || i == 0 {
+			phase = step.Phase
+			name := phase
+			if name == "" {
+				name = "unknown phase"
+			}
+			fmt.Fprintf(w, "\n── %s ──\n", name)
+		}
+
+		n := step.Step
+		if n == 0 {
+			n = i + 1
+		}
+		fmt.Fprintf(w, "[%d] ", n)
+		if step.Thought != "" {
+			writeIndented(w, "💭 Thought: ", step.Thought)
+			fmt.Fprintf(w, "    ")
+		}
+		fmt.Fprintf(w, "🔧 Action: %s\n", step.Action)
+		if input := formatActionInput(step.ActionInput); input != "" {
+			writeIndented(w, "    📥 Input: ", input)
+		}
+		if step.Observation != "" {
+			writeIndented(w, "    👁  Observation: ", step.Observation)
+		}
+	}
+
+	fmt.Fprintf(w, "\n✅ Final SQL: %s\n", t.GeneratedSQL)
+
+	if !withExchanges {
+		return
+	}
+	for i, ex := range t.Exchanges {
+		fmt.Fprintf(w, "\n━━━ LLM exchange %d/%d ━━━\n", i+1, len(t.Exchanges))
+		fmt.Fprintf(w, "── Prompt ──\n%s\n", strings.TrimSpace(ex.Prompt))
+		fmt.Fprintf(w, "── Response ──\n%s\n", strings.TrimSpace(ex.Response))
+	}
+}
+
+// formatActionInput renders a string input as is and structured input as JSON
+func formatActionInput(input interface{}) string {
+	switch v := input.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
+
+// writeIndented writes label+text, aligning continuation lines under the text
+func writeIndented(w io.Writer, label, text string) {
+	pad := strings.Repeat(" ", 4)
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	fmt.Fprintf(w, "%s%s\n", label, lines[0])
+	for _, line := range lines[1:] {
+		fmt.Fprintf(w, "%s    %s\n", pad, line)
+	}
+}
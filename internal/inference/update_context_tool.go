@@ -8,12 +8,17 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	contextpkg "reactsql/internal/context"
 )
 
 // UpdateRichContextTool tool for updating Rich Context
+// Corrections go to the loaded context; with persist they are also written to the context file.
 type UpdateRichContextTool struct {
 	dbName      string
 	contextPath string
+	shared      *contextpkg.SharedContext
+	persist     bool
 	logger      *InferenceLogger
 }
 
@@ -83,56 +88,36 @@ func (t *UpdateRichContextTool) Call(ctx context.Context, input string) (string,
 		return "⚠️  Error: new_content is required", nil
 	}
 
-	// Read Rich Context file
-	data, err := os.ReadFile(t.contextPath)
-	if err != nil {
-		return fmt.Sprintf("⚠️  Failed to read context file: %v\nContinue with SQL generation.", err), nil
-	}
-
-	// Parse as generic map
-	var rawData map[string]interface{}
-	if err := json.Unmarshal(data, &rawData); err != nil {
-		return fmt.Sprintf("⚠️  Failed to parse context file: %v\nContinue with SQL generation.", err), nil
-	}
-
-	// Get tables
-	tables, ok := rawData["tables"].(map[string]interface{})
-	if !ok {
-		return "⚠️  No tables field in context. Continue with SQL generation.", nil
-	}
-
-	// Get specified table
-	tableData, ok := tables[updateInput.TableName].(map[string]interface{})
-	if !ok {
-		return fmt.Sprintf("⚠️  Table '%s' not found in context. Continue with SQL generation.", updateInput.TableName), nil
-	}
-
-	// Get rich_context
-	richContext, ok := tableData["rich_context"].(map[string]interface{})
-	if !ok {
-		return fmt.Sprintf("⚠️  No rich_context in table '%s'. Continue with SQL generation.", updateInput.TableName), nil
-	}
-
-	// Check note existence - create new if not found
-	if _, exists := richContext[updateInput.NoteKey]; !exists {
-		return fmt.Sprintf("⚠️  Note key '%s' not found in table '%s'.\nTip: This might be a new insight. You can continue with SQL generation based on your findings.", updateInput.NoteKey, updateInput.TableName), nil
+	// Corrections are checked against query results, so they outlive generated notes
+	expiresAt := contextpkg.NoteExpiry(contextpkg.CorrectedNoteTTL)
+	note := contextpkg.BusinessNote{
+		Content:   updateInput.NewContent,
+		ExpiresAt: expiresAt,
+		Provenance: &contextpkg.NoteProvenance{
+			Source:    "proofread",
+			Reason:    updateInput.Reason,
+			UpdatedAt: time.Now().Format(time.RFC3339),
+		},
 	}
 
-	// Update note
-	expiresAt := time.Now().Add(7 * 24 * time.Hour).Format(time.RFC3339)
-	richContext[updateInput.NoteKey] = map[string]string{
-		"content":    updateInput.NewContent,
-		"expires_at": expiresAt,
-	}
-
-	// Write back to file
-	output, err := json.MarshalIndent(rawData, "", "  ")
-	if err != nil {
-		return fmt.Sprintf("⚠️  Failed to marshal context: %v\nContinue with SQL generation.", err), nil
+	// Loaded context: later questions answered by this pipeline see the correction
+	if t.shared != nil {
+		previous, err := t.shared.CorrectTableRichContext(updateInput.TableName, updateInput.NoteKey, note)
+		if err != nil {
+			return fmt.Sprintf("⚠️  %v.\nTip: This might be a new insight. You can continue with SQL generation based on your findings.", err), nil
+		}
+		note.Provenance.Previous = previous
+	} else if !t.persist {
+		return "⚠️  No Rich Context loaded, nothing to update. Continue with SQL generation.", nil
 	}
 
-	if err := os.WriteFile(t.contextPath, output, 0644); err != nil {
-		return fmt.Sprintf("⚠️  Failed to write context file: %v\nContinue with SQL generation.", err), nil
+	// Context file: later examples and runs see the correction
+	saved := "no (this run only)"
+	if t.persist {
+		if msg := t.persistNote(updateInput.TableName, updateInput.NoteKey, note); msg != "" {
+			return msg, nil
+		}
+		saved = t.contextPath
 	}
 
 	// Return success info
@@ -142,12 +127,14 @@ func (t *UpdateRichContextTool) Call(ctx context.Context, input string) (string,
 			"Note: %s\n"+
 			"New Content: %s\n"+
 			"Expires At: %s\n"+
-			"Reason: %s",
+			"Reason: %s\n"+
+			"Saved: %s",
 		updateInput.TableName,
 		updateInput.NoteKey,
 		updateInput.NewContent,
 		expiresAt,
 		updateInput.Reason,
+		saved,
 	)
 
 	logf := func(format string, a ...interface{}) {
@@ -173,6 +160,80 @@ func (t *UpdateRichContextTool) Call(ctx context.Context, input string) (string,
 	return result, nil
 }
 
+// persistNote writes the corrected note to the context file; returns a message for the agent on failure
+// The file is patched as generic JSON so fields this build does not know survive.
+func (t *UpdateRichContextTool) persistNote(tableName, key string, note contextpkg.BusinessNote) string {
+	if contextpkg.IsContextStore(t.contextPath) {
+		return t.persistStoreNote(tableName, key, note)
+	}
+
+	data, err := os.ReadFile(t.contextPath)
+	if err != nil {
+		return fmt.Sprintf("⚠️  Failed to read context file: %v\nContinue with SQL generation.", err)
+	}
+
+	// Parse as generic map
+	var rawData map[string]interface{}
+	if err := json.Unmarshal(data, &rawData); err != nil {
+		return fmt.Sprintf("⚠️  Failed to parse context file: %v\nContinue with SQL generation.", err)
+	}
+
+	// Get tables
+	tables, ok := rawData["tables"].(map[string]interface{})
+	if !ok {
+		return "⚠️  No tables field in context. Continue with SQL generation."
+	}
+
+	// Get specified table
+	tableData, ok := tables[tableName].(map[string]interface{})
+	if !ok {
+		return fmt.Sprintf("⚠️  Table '%s' not found in context. Continue with SQL generation.", tableName)
+	}
+
+	// Get rich_context
+	richContext, ok := tableData["rich_context"].(map[string]interface{})
+	if !ok {
+		return fmt.Sprintf("⚠️  No rich_context in table '%s'. Continue with SQL generation.", tableName)
+	}
+
+	// Check note existence (corrections never add notes)
+	previous, exists := richContext[key]
+	if !exists {
+		return fmt.Sprintf("⚠️  Note key '%s' not found in table '%s'.\nTip: This might be a new insight. You can continue with SQL generation based on your findings.", key, tableName)
+	}
+	if note.Provenance != nil && note.Provenance.Previous == "" {
+		switch v := previous.(type) {
+		case string:
+			note.Provenance.Previous = v
+		case map[string]interface{}:
+			note.Provenance.Previous, _ = v["content"].(string)
+		}
+	}
+	if v, ok := previous.(map[string]interface{}); ok && note.Provenance != nil && note.Provenance.Origin == nil {
+		// Keep the generating agent's provenance so the wrong note stays traceable
+		if raw, err := json.Marshal(v["provenance"]); err == nil {
+			json.Unmarshal(raw, &note.Provenance.Origin)
+		}
+	}
+	richContext[key] = note
+
+	output, err := json.MarshalIndent(rawData, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("⚠️  Failed to marshal context: %v\nContinue with SQL generation.", err)
+	}
+
+	// Write to a temp file and rename so an interrupted run never leaves a truncated context
+	tmpPath := t.contextPath + ".tmp"
+	if err := os.WriteFile(tmpPath, output, 0644); err != nil {
+		return fmt.Sprintf("⚠️  Failed to write context file: %v\nContinue with SQL generation.", err)
+	}
+	if err := os.Rename(tmpPath, t.contextPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Sprintf("⚠️  Failed to write context file: %v\nContinue with SQL generation.", err)
+	}
+	return ""
+}
+
 // NewUpdateRichContextTool creates update tool
 func NewUpdateRichContextTool(dbName, dbType, benchmark string) *UpdateRichContextTool {
 	// Build context file path using actual benchmark name (spider/bird)
@@ -183,3 +244,34 @@ func NewUpdateRichContextTool(dbName, dbType, benchmark string) *UpdateRichConte
 		contextPath: contextPath,
 	}
 }
+
+// persistStoreNote writes the corrected note to a context store, rewriting only its table's row
+func (t *UpdateRichContextTool) persistStoreNote(tableName, key string, note contextpkg.BusinessNote) string {
+	store, err := contextpkg.OpenContextStore(t.contextPath)
+	if err != nil {
+		return fmt.Sprintf("⚠️  Failed to open context store: %v\nContinue with SQL generation.", err)
+	}
+	defer store.Close()
+
+	stored, err := store.Load()
+	if err != nil {
+		return fmt.Sprintf("⚠️  Failed to read context store: %v\nContinue with SQL generation.", err)
+	}
+	if err := store.LoadTables(stored, tableName); err != nil {
+		return fmt.Sprintf("⚠️  Table '%s' not found in context. Continue with SQL generation.", tableName)
+	}
+
+	// Check note existence (corrections never add notes)
+	previous, err := stored.CorrectTableRichContext(tableName, key, note)
+	if err != nil {
+		return fmt.Sprintf("⚠️  Note key '%s' not found in table '%s'.\nTip: This might be a new insight. You can continue with SQL generation based on your findings.", key, tableName)
+	}
+	if note.Provenance != nil && note.Provenance.Previous == "" {
+		note.Provenance.Previous = previous
+	}
+
+	if err := store.SaveTable(stored, tableName); err != nil {
+		return fmt.Sprintf("⚠️  Failed to write context store: %v\nContinue with SQL generation.", err)
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package inference
+
+import (
+	"fmt"
+	"strings"
+
+	contextpkg "reactsql/internal/context"
+)
+
+// canonicalizeValues rewrites string literals compared with a column (=, !=, IN) into the
+// exact literal stored in the database, from the context's canonicalization maps
+// ("usa" → " USA"). LIKE patterns and columns without a map are left alone. Returns the
+// rewritten SQL and one "table.column: 'from' → 'to'" note per rewrite.
+func canonicalizeValues(sql, dialect string, sharedCtx *contextpkg.SharedContext) (string, []string) {
+	toks, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return sql, nil
+	}
+
+	tableNames := make(map[string]string, len(sharedCtx.Tables))
+	tableColumns := make(map[string]map[string]string, len(sharedCtx.Tables))
+	for _, table := range sharedCtx.Tables {
+		tableNames[strings.ToLower(table.Name)] = table.Name
+		byName := make(map[string]string, len(table.Columns))
+		for _, col := range table.Columns {
+			byName[strings.ToLower(col.Name)] = col.Name
+		}
+		tableColumns[table.Name] = byName
+	}
+	scope := &columnScope{toks: toks, refs: scanTableRefs(toks, tableNames), tableNames: tableNames, tableColumns: tableColumns}
+
+	replacements := make(map[int]string) // token index -> quoted literal
+	var notes []string
+	for i := 0; i < len(toks); i++ {
+		cols, width := scope.columnAt(i)
+		if width == 0 {
+			continue
+		}
+		if len(cols) == 1 {
+			idx, pattern := comparedLiteralTokens(toks, i+width)
+			for _, j := range idx {
+				if pattern {
+					break
+				}
+				value := unquoteString(toks[j].text, dialect)
+				literal, ok := sharedCtx.CanonicalValue(cols[0].table, cols[0].column, value)
+				if !ok {
+					continue
+				}
+				replacements[j] = quoteString(literal, dialect)
+				notes = append(notes, fmt.Sprintf("%s.%s: %q → %q", cols[0].table, cols[0].column, value, literal))
+			}
+		}
+		i += width - 1
+	}
+	if len(replacements) == 0 {
+		return sql, nil
+	}
+
+	var sb strings.Builder
+	last := 0
+	for i, tok := range toks {
+		if literal, ok := replacements[i]; ok {
+			sb.WriteString(sql[last:tok.pos])
+			sb.WriteString(literal)
+			last = tok.pos + len(tok.text)
+		}
+	}
+	sb.WriteString(sql[last:])
+	return sb.String(), notes
+}
+
+// applyValueCanonicalization enforces Config.CanonicalValues on the generated query
+func (p *Pipeline) applyValueCanonicalization(sql string, result *Result) string {
+	if !p.config.CanonicalValues || p.context == nil {
+		return sql
+	}
+	rewritten, notes := canonicalizeValues(sql, p.generationDialect(), p.context)
+	if len(notes) == 0 {
+		return sql
+	}
+	p.Logger.Printf("🔤 Canonicalized %d values (%s): %s\n", len(notes), strings.Join(notes, ", "), rewritten)
+	result.ValueRewrites = notes
+	return rewritten
+}
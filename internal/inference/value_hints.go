@@ -0,0 +1,56 @@
+package inference
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	contextpkg "reactsql/internal/context"
+)
+
+// maxValueHints value hints injected per question
+const maxValueHints = 8
+
+// matchValueHints matches question phrases against the context's cell-value index
+// Matches in the linked tables come first; others are kept since the linker may have missed a table.
+func (p *Pipeline) matchValueHints(query string, tables []string) []contextpkg.ValueMatch {
+	if p.context == nil || p.context.ValueIndex == nil {
+		return nil
+	}
+
+	matches := p.context.ValueIndex.MatchQuestion(query, 0)
+	linked := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		linked[strings.ToLower(t)] = true
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		li, lj := linked[strings.ToLower(matches[i].Table)], linked[strings.ToLower(matches[j].Table)]
+		if li != lj {
+			return li
+		}
+		return matches[i].Exact && !matches[j].Exact
+	})
+	if len(matches) > maxValueHints {
+		matches = matches[:maxValueHints]
+	}
+	return matches
+}
+
+// formatValueHintsPrompt renders value matches for the generation prompt
+func formatValueHintsPrompt(matches []contextpkg.ValueMatch, dialect string) string {
+	if len(matches) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Value Hints\n")
+	sb.WriteString("Question phrases found in the database. Use the stored spelling in filters.\n")
+	for _, m := range matches {
+		if m.Exact {
+			sb.WriteString(fmt.Sprintf("- \"%s\": value %s appears in column %s.%s\n", m.Phrase, quoteString(m.Value, dialect), m.Table, m.Column))
+		} else {
+			sb.WriteString(fmt.Sprintf("- \"%s\": similar value %s appears in column %s.%s\n", m.Phrase, quoteString(m.Value, dialect), m.Table, m.Column))
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,155 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"reactsql/internal/adapter"
+)
+
+// VerifyResultFieldsTool result-shape verification tool
+// Runs the SQL with LIMIT 1 and compares the returned columns with the expected result fields.
+type VerifyResultFieldsTool struct {
+	adapter      adapter.DBAdapter
+	resultFields []string // Expected output fields (empty = unknown, only the shape is reported)
+	logger       *InferenceLogger
+}
+
+// NewVerifyResultFieldsTool creates result-shape verification tool
+func NewVerifyResultFieldsTool(adapter adapter.DBAdapter, resultFields []string) *VerifyResultFieldsTool {
+	return &VerifyResultFieldsTool{
+		adapter:      adapter,
+		resultFields: resultFields,
+	}
+}
+
+// Name returns tool name
+func (t *VerifyResultFieldsTool) Name() string {
+	return "verify_result_fields"
+}
+
+// Description returns tool description
+func (t *VerifyResultFieldsTool) Description() string {
+	return `Verify the OUTPUT COLUMNS of a SQL query (count, order and names) against the fields the question asks for.
+Runs the query with LIMIT 1 and reports the returned columns and any mismatch.
+
+Input: SQL query string to check
+Output: Returned columns, expected fields, and mismatches to fix in the SELECT list
+
+Use this tool before Final Answer: wrong SELECT columns (extra, missing or misordered) are the most common error.`
+}
+
+// Call executes the shape check
+func (t *VerifyResultFieldsTool) Call(ctx context.Context, input string) (string, error) {
+	sql := strings.TrimSuffix(strings.TrimSpace(input), ";")
+
+	logf := func(format string, a ...interface{}) {
+		if t.logger != nil {
+			t.logger.Printf(format, a...)
+		} else {
+			fmt.Printf(format, a...)
+		}
+	}
+
+	logf("\n📐 Tool Call [verify_result_fields]:\n")
+	logf("Input SQL: %s\n", sql)
+
+	// LIMIT 1 through a derived table; MySQL rejects derived tables with duplicate
+	// column names, so fall back to the original query
+	data, err := t.adapter.ExecuteQuery(ctx, fmt.Sprintf("SELECT * FROM (%s) AS shape_check LIMIT 1", sql))
+	if err != nil {
+		data, err = t.adapter.ExecuteQuery(ctx, sql)
+	}
+	if err != nil {
+		result := fmt.Sprintf("❌ SQL execution failed:\n%v\n\nFix the query first (use verify_sql).", err)
+		logf("Output: %s\n", result)
+		return result, nil
+	}
+
+	result := checkResultShape(data.Columns, t.resultFields)
+	logf("Output: %s\n", result)
+	return result, nil
+}
+
+// checkResultShape reports returned columns against the expected fields
+func checkResultShape(columns, expected []string) string {
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("Returned %d column(s): %s\n", len(columns), strings.Join(columns, ", ")))
+
+	if len(expected) == 0 {
+		report.WriteString("No expected field list is available. Compare the columns with the question:\n")
+		report.WriteString("- Return ONLY what the question asks for (no extra ids, counts or sort keys)\n")
+		report.WriteString("- Keep the order in which the question mentions the fields\n")
+		if len(columns) > 4 {
+			report.WriteString(fmt.Sprintf("⚠️  %d columns is unusually wide. Check for SELECT * or helper columns.\n", len(columns)))
+		}
+		return report.String()
+	}
+
+	report.WriteString(fmt.Sprintf("Expected %d field(s): %s\n", len(expected), strings.Join(expected, ", ")))
+
+	var issues []string
+	if len(columns) != len(expected) {
+		issues = append(issues, fmt.Sprintf("❌ Column count mismatch: query returns %d, expected %d.", len(columns), len(expected)))
+	}
+
+	got := make([]string, len(columns))
+	gotSet := make(map[string]bool, len(columns))
+	for i, c := range columns {
+		got[i] = normalizeFieldName(c)
+		gotSet[got[i]] = true
+	}
+	want := make([]string, len(expected))
+	wantSet := make(map[string]bool, len(expected))
+	for i, f := range expected {
+		want[i] = normalizeFieldName(f)
+		wantSet[want[i]] = true
+	}
+
+	var missing, extra []string
+	for i, w := range want {
+		if !gotSet[w] {
+			missing = append(missing, expected[i])
+		}
+	}
+	for i, g := range got {
+		if !wantSet[g] {
+			extra = append(extra, columns[i])
+		}
+	}
+	if len(missing) > 0 {
+		issues = append(issues, fmt.Sprintf("⚠️  Expected fields not found by name: %s", strings.Join(missing, ", ")))
+	}
+	if len(extra) > 0 {
+		issues = append(issues, fmt.Sprintf("⚠️  Returned columns not in the expected list: %s", strings.Join(extra, ", ")))
+	}
+	if len(missing) == 0 && len(extra) == 0 && len(got) == len(want) {
+		for i := range want {
+			if got[i] != want[i] {
+				issues = append(issues, "❌ Columns are in the wrong order. Reorder the SELECT list to match the expected fields.")
+				break
+			}
+		}
+	}
+
+	if len(issues) == 0 {
+		report.WriteString("✓ Output columns match the expected fields.")
+		return report.String()
+	}
+	report.WriteString(strings.Join(issues, "\n"))
+	report.WriteString("\nFix the SELECT list: one column per expected field, in that order. ")
+	report.WriteString("A name difference alone is fine if the column holds the expected value (e.g. an alias or an expression).")
+	return report.String()
+}
+
+var fieldQualifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\.`)
+
+// normalizeFieldName lower-cases and strips quotes, a table qualifier and whitespace
+func normalizeFieldName(name string) string {
+	n := strings.ToLower(strings.TrimSpace(name))
+	n = strings.NewReplacer("`", "", `"`, "", "[", "", "]", "").Replace(n)
+	n = fieldQualifierPattern.ReplaceAllString(n, "")
+	return strings.Join(strings.Fields(n), "")
+}
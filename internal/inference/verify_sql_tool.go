@@ -81,8 +81,8 @@ func (t *VerifySQLTool) Call(ctx context.Context, input string) (string, error)
 		if data.RowCount < 3 {
 			maxShow = data.RowCount
 		}
-		for i := 0; i < maxShow && i < len(data.Rows); i++ {
-			report.WriteString(fmt.Sprintf("  Row %d: %v\n", i+1, data.Rows[i]))
+		for i := 0; i < maxShow && i < len(data.Values); i++ {
+			report.WriteString(fmt.Sprintf("  Row %d: %s\n", i+1, adapter.FormatRows(data.Columns, data.Values[i:i+1], 1)))
 		}
 
 		// 5. Check for NULL values in results — only warn if majority are NULL
@@ -105,8 +105,7 @@ func (t *VerifySQLTool) Call(ctx context.Context, input string) (string, error)
 	}
 
 	// 6. Check duplicate rows
-	rows := convertQueryResultFormat(data.Rows)
-	if duplicateWarning := t.checkDuplicateRows(rows); duplicateWarning != "" {
+	if duplicateWarning := t.checkDuplicateRows(data.Columns, data.Values); duplicateWarning != "" {
 		warnings = append(warnings, duplicateWarning)
 	}
 
@@ -187,53 +186,27 @@ func NewVerifySQLTool(adapter adapter.DBAdapter, dbType string) *VerifySQLTool {
 	}
 }
 
-// checkDuplicateRows checks for duplicate rows
-func (t *VerifySQLTool) checkDuplicateRows(rows [][]string) string {
-	if len(rows) <= 2 { // no data rows or only one row
+// checkDuplicateRows checks for duplicate rows (typed: NULL and empty string are different values)
+func (t *VerifySQLTool) checkDuplicateRows(columns []string, values [][]adapter.Value) string {
+	if len(values) <= 1 { // no data rows or only one row
 		return ""
 	}
 
 	seen := make(map[string]bool)
-	dataRows := rows[1:] // Exclude header row
-
-	for _, row := range dataRows {
+	for i, row := range values {
 		// Create unique key for row
-		rowKey := strings.Join(row, "||<SEP>||")
+		keys := make([]string, len(row))
+		for j, val := range row {
+			keys[j] = val.Key()
+		}
+		rowKey := strings.Join(keys, "||<SEP>||")
 		if seen[rowKey] {
 			// Duplicate found
-			return fmt.Sprintf("Warning: The query returned duplicate rows (e.g., %v). Review the question to determine if duplicates should be removed using DISTINCT.", row)
+			return fmt.Sprintf("Warning: The query returned duplicate rows (e.g., %s). Review the question to determine if duplicates should be removed using DISTINCT.",
+				adapter.FormatRows(columns, values[i:i+1], 1))
 		}
 		seen[rowKey] = true
 	}
 
 	return ""
 }
-
-// convertQueryResultFormat converts query result from map to 2D string array
-func convertQueryResultFormat(data []map[string]interface{}) [][]string {
-	if len(data) == 0 {
-		return nil
-	}
-
-	var headers []string
-	for key := range data[0] {
-		headers = append(headers, key)
-	}
-
-	result := make([][]string, len(data)+1)
-	result[0] = headers
-
-	for i, row := range data {
-		rowValues := make([]string, len(headers))
-		for j, header := range headers {
-			if val, ok := row[header]; ok {
-				rowValues[j] = fmt.Sprintf("%v", val)
-			} else {
-				rowValues[j] = ""
-			}
-		}
-		result[i+1] = rowValues
-	}
-
-	return result
-}
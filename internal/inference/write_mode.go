@@ -0,0 +1,194 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+
+	"reactsql/internal/adapter"
+)
+
+// WriteOutcome result of a data-changing answer (Config.WriteMode)
+type WriteOutcome struct {
+	Kind         string // "INSERT" | "UPDATE" | "DELETE" | "REPLACE" | "MERGE"
+	RowsAffected int64
+	Committed    bool   // false = dry run, rolled back
+	Error        string // Execution error ("" on success)
+}
+
+// writeKeywords statement keywords that change data
+var writeKeywords = map[string]bool{"INSERT": true, "UPDATE": true, "DELETE": true, "REPLACE": true, "MERGE": true}
+
+// writeStatementKind statement keyword of a data-changing statement ("" for queries)
+// The first top-level INSERT / UPDATE / DELETE / REPLACE / MERGE or SELECT decides, so
+// "WITH ... UPDATE" is a write and "INSERT INTO ... SELECT" is too.
+func writeStatementKind(sql, dialect string) string {
+	toks, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return ""
+	}
+	depth := 0
+	for _, tok := range toks {
+		switch {
+		case tok.kind == tokOp && tok.text == "(":
+			depth++
+		case tok.kind == tokOp && tok.text == ")":
+			depth--
+		case depth == 0 && tok.kind == tokWord && writeKeywords[tok.upper]:
+			return tok.upper
+		case depth == 0 && tok.kind == tokWord && (tok.upper == "SELECT" || tok.upper == "VALUES"):
+			return ""
+		}
+	}
+	return ""
+}
+
+// multipleStatements reports whether sql has a statement after a top-level semicolon
+func multipleStatements(sql, dialect string) bool {
+	toks, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return false
+	}
+	for i, tok := range toks {
+		if tok.kind == tokOp && tok.text == ";" {
+			for _, rest := range toks[i+1:] {
+				if rest.kind != tokEOF && !(rest.kind == tokOp && rest.text == ";") {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// mutatingKeywords keywords that make a statement change data, schema or connection state
+// REPLACE is left out: it is also a string function inside queries.
+var mutatingKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true,
+	"CREATE": true, "DROP": true, "ALTER": true, "TRUNCATE": true, "RENAME": true,
+	"ATTACH": true, "DETACH": true, "PRAGMA": true, "VACUUM": true, "REINDEX": true, "ANALYZE": true,
+	"GRANT": true, "REVOKE": true,
+}
+
+// readOnlyQuery checks sql is a single SELECT / WITH ... SELECT / VALUES that cannot change the database
+// Anything it cannot verify is refused, including SQL the tokenizer rejects, data-modifying
+// CTEs and SELECT ... INTO.
+func readOnlyQuery(sql, dialect string) error {
+	toks, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return fmt.Errorf("cannot verify the statement is read-only: %w", err)
+	}
+	if multipleStatements(sql, dialect) {
+		return fmt.Errorf("multiple statements are not allowed")
+	}
+
+	depth := 0
+	first := ""
+	for _, tok := range toks {
+		switch {
+		case tok.kind == tokOp && tok.text == "(":
+			depth++
+		case tok.kind == tokOp && tok.text == ")":
+			depth--
+		case tok.kind != tokWord:
+		case first == "":
+			first = tok.upper
+			if first != "SELECT" && first != "WITH" && first != "VALUES" {
+				return fmt.Errorf("only SELECT queries are allowed, got %s", first)
+			}
+		case mutatingKeywords[tok.upper]:
+			return fmt.Errorf("only SELECT queries are allowed, found %s", tok.upper)
+		case depth == 0 && tok.upper == "INTO":
+			return fmt.Errorf("SELECT ... INTO is not allowed")
+		}
+	}
+	if first == "" {
+		return fmt.Errorf("empty statement")
+	}
+	return nil
+}
+
+// dryRunAdapter runs data-changing statements in a rolled-back transaction (Config.WriteMode)
+// The LLM tools see the affected row count as a one-row result. Only statements readOnlyQuery
+// accepts reach the database directly; DDL and anything unrecognised are refused.
+type dryRunAdapter struct {
+	adapter.DBAdapter
+}
+
+// ExecuteQuery dry-runs DML, executes read-only queries and refuses everything else
+func (a *dryRunAdapter) ExecuteQuery(ctx context.Context, query string) (*adapter.QueryResult, error) {
+	dialect := NormalizeDialect(a.GetDatabaseType())
+	if writeStatementKind(query, dialect) == "" {
+		if err := readOnlyQuery(query, dialect); err != nil {
+			return nil, fmt.Errorf("not executed in write mode: %w", err)
+		}
+		return a.DBAdapter.ExecuteQuery(ctx, query)
+	}
+	if multipleStatements(query, dialect) {
+		return nil, fmt.Errorf("multiple statements are not allowed")
+	}
+	writer, ok := a.DBAdapter.(adapter.WriteExecutor)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support write statements", a.GetDatabaseType())
+	}
+
+	res, err := writer.ExecuteWrite(ctx, query, false)
+	if err != nil {
+		return nil, err
+	}
+	value := adapter.NewValue(res.RowsAffected)
+	return &adapter.QueryResult{
+		Columns:       []string{"rows_affected"},
+		Rows:          []map[string]interface{}{{"rows_affected": res.RowsAffected}},
+		Values:        [][]adapter.Value{{value}},
+		RowCount:      1,
+		ExecutionTime: res.ExecutionTime,
+	}, nil
+}
+
+// applyWrite runs a data-changing final SQL in a transaction, committing only with Config.CommitWrites
+// Returns nil when the SQL is a query.
+func (p *Pipeline) applyWrite(ctx context.Context, sql string, result *Result) *WriteOutcome {
+	dialect := p.targetDialect()
+	kind := writeStatementKind(sql, dialect)
+	if kind == "" {
+		return nil
+	}
+	outcome := &WriteOutcome{Kind: kind}
+
+	if multipleStatements(sql, dialect) {
+		outcome.Error = "multiple statements are not allowed"
+		p.Logger.Printf("⚠️  %s not executed: %s\n", kind, outcome.Error)
+		return outcome
+	}
+	writer, ok := p.adapter.(adapter.WriteExecutor)
+	if !ok {
+		outcome.Error = fmt.Sprintf("%s does not support write statements", p.adapter.GetDatabaseType())
+		p.Logger.Printf("⚠️  %s not executed: %s\n", kind, outcome.Error)
+		return outcome
+	}
+
+	res, err := writer.ExecuteWrite(ctx, sql, p.config.CommitWrites)
+	result.SQLExecutions++
+	if err != nil {
+		outcome.Error = err.Error()
+		p.Logger.Printf("⚠️  %s failed: %v\n", kind, err)
+		return outcome
+	}
+	outcome.RowsAffected = res.RowsAffected
+	outcome.Committed = res.Committed
+	if res.Committed {
+		p.Logger.Printf("✍️  %s committed: %d rows affected\n", kind, res.RowsAffected)
+	} else {
+		p.Logger.Printf("✍️  %s dry run: %d rows would be affected (rolled back)\n", kind, res.RowsAffected)
+	}
+	return outcome
+}
+
+// writeModePrompt generation prompt section for Config.WriteMode
+const writeModePrompt = `## Data Changes
+If the question asks to add, change or remove data, answer with ONE INSERT, UPDATE or DELETE statement instead of a query.
+Tools run such statements in a transaction that is rolled back and report the affected row count (rows_affected); nothing is saved.
+Check the count is plausible before giving the Final Answer.
+
+`
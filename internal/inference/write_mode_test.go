@@ -0,0 +1,35 @@
+package inference
+
+import "testing"
+
+func TestReadOnlyQuery(t *testing.T) {
+	allowed := []string{
+		"SELECT name FROM singer",
+		"(SELECT 1) UNION (SELECT 2)",
+		"WITH t AS (SELECT 1 AS x) SELECT x FROM t",
+		"SELECT REPLACE(name, 'a', 'b') FROM singer;",
+		"VALUES (1), (2)",
+	}
+	for _, sql := range allowed {
+		if err := readOnlyQuery(sql, "sqlite"); err != nil {
+			t.Errorf("%q: unexpected error %v", sql, err)
+		}
+	}
+
+	denied := []string{
+		"CREATE TABLE t (x INT)",
+		"DROP TABLE singer",
+		"ALTER TABLE singer ADD COLUMN x INT",
+		"PRAGMA writable_schema = 1",
+		"SELECT 1; DROP TABLE singer",
+		"WITH d AS (DELETE FROM singer RETURNING *) SELECT * FROM d",
+		"SELECT * INTO backup FROM singer",
+		"SELECT 'unterminated",
+		"",
+	}
+	for _, sql := range denied {
+		if err := readOnlyQuery(sql, "sqlite"); err == nil {
+			t.Errorf("%q: expected to be refused", sql)
+		}
+	}
+}
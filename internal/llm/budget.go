@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ErrBudgetExceeded returned by a BudgetModel once its token or call cap is spent
+var ErrBudgetExceeded = errors.New("LLM budget exceeded")
+
+// BudgetModel caps the tokens and calls spent through it (e.g. by one table's worker)
+// The call that crosses a cap completes; every later call fails with ErrBudgetExceeded
+// without reaching the model. Wrap a shared UsageModel to keep the run totals.
+type BudgetModel struct {
+	*UsageModel // Usage of this budget only
+
+	MaxTokens int // Total tokens (0 = unlimited)
+	MaxCalls  int // Model calls (0 = unlimited)
+}
+
+// NewBudgetModel wraps model with a token and call cap
+func NewBudgetModel(model llms.Model, maxTokens, maxCalls int) *BudgetModel {
+	return &BudgetModel{UsageModel: NewUsageModel(model), MaxTokens: maxTokens, MaxCalls: maxCalls}
+}
+
+// Exceeded reports whether a cap is spent
+func (m *BudgetModel) Exceeded() bool {
+	usage := m.Usage()
+	return (m.MaxTokens > 0 && usage.TotalTokens >= m.MaxTokens) ||
+		(m.MaxCalls > 0 && usage.Calls >= m.MaxCalls)
+}
+
+// GenerateContent calls the wrapped model unless the budget is spent
+func (m *BudgetModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	if m.Exceeded() {
+		return nil, ErrBudgetExceeded
+	}
+	return m.UsageModel.GenerateContent(ctx, messages, options...)
+}
+
+// Call routes through GenerateContent so text-only calls are capped too
+func (m *BudgetModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
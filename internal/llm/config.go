@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 
+	"github.com/tmc/langchaingo/embeddings"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/openai"
 )
@@ -26,6 +27,9 @@ type ConfigFile struct {
 	AliDeepSeek    ModelConfig `json:"ali_deepseek_v3_2"`
 	DoubaoSeed2Pro  ModelConfig `json:"doubao_seed2_pro"`
 	Qwen3CoderPlus ModelConfig `json:"qwen3_coder_plus"`
+
+	// Embedding model for few-shot retrieval (optional, OpenAI-compatible endpoint)
+	Embedding ModelConfig `json:"embedding,omitempty"`
 }
 
 var (
@@ -106,6 +110,35 @@ func CreateLLM(config ModelConfig) (llms.Model, error) {
 	)
 }
 
+// CreateEmbedder creates embedder from an OpenAI-compatible embedding model config
+// The embedder reports config.ModelName from Model(), recorded with stored schema vectors.
+func CreateEmbedder(config ModelConfig) (embeddings.Embedder, error) {
+	client, err := openai.New(
+		openai.WithEmbeddingModel(config.ModelName),
+		openai.WithToken(config.Token),
+		openai.WithBaseURL(config.BaseURL),
+	)
+	if err != nil {
+		return nil, err
+	}
+	embedder, err := embeddings.NewEmbedder(client)
+	if err != nil {
+		return nil, err
+	}
+	return &namedEmbedder{Embedder: embedder, model: config.ModelName}, nil
+}
+
+// namedEmbedder embedder that reports its model name
+type namedEmbedder struct {
+	embeddings.Embedder
+	model string
+}
+
+// Model returns the embedding model name
+func (e *namedEmbedder) Model() string {
+	return e.model
+}
+
 // CreateLLMWithFlag creates LLM by flag
 func CreateLLMWithFlag(useV32 bool) (llms.Model, error) {
 	modelConfig := GetModel(useV32)
@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Usage LLM calls and tokens counted by a UsageModel
+// Tokens are those the provider reports; providers reporting none count 0.
+type Usage struct {
+	Calls            int `json:"calls"`
+	Failures         int `json:"failures"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Add accumulates other into u
+func (u *Usage) Add(other Usage) {
+	u.Calls += other.Calls
+	u.Failures += other.Failures
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+// UsageModel wraps an llms.Model and counts its calls and token usage (safe for concurrent agents)
+type UsageModel struct {
+	llms.Model
+
+	mu    sync.Mutex
+	usage Usage
+}
+
+// NewUsageModel wraps model with usage counting
+func NewUsageModel(model llms.Model) *UsageModel {
+	return &UsageModel{Model: model}
+}
+
+// GenerateContent calls the wrapped model and records the call
+func (m *UsageModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	resp, err := m.Model.GenerateContent(ctx, messages, options...)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.Calls++
+	if err != nil {
+		m.usage.Failures++
+		return resp, err
+	}
+	// Usage is per response; every choice repeats it
+	if resp != nil && len(resp.Choices) > 0 {
+		info := resp.Choices[0].GenerationInfo
+		m.usage.PromptTokens += usageInt(info, "PromptTokens")
+		m.usage.CompletionTokens += usageInt(info, "CompletionTokens")
+		m.usage.TotalTokens += usageInt(info, "TotalTokens")
+	}
+	return resp, nil
+}
+
+// Call routes through GenerateContent so text-only calls are counted too
+func (m *UsageModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// Usage counts so far
+func (m *UsageModel) Usage() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+// usageInt reads a token count from GenerationInfo (providers use int or float64)
+func usageInt(info map[string]any, key string) int {
+	switch v := info[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,266 @@
+// Package reactsql is the supported library API for embedding the NL→SQL pipeline in other Go services.
+//
+// A Pipeline answers questions against one database:
+//
+//	db, err := reactsql.NewAdapter(&reactsql.DBConfig{Type: "sqlite", FilePath: "app.db"})
+//	...
+//	p, err := reactsql.New(model, db, &reactsql.Config{UseReact: true, ClarifyMode: "off"})
+//	...
+//	res, err := p.Run(ctx, "How many users signed up last week?")
+//	fmt.Println(res.GeneratedSQL)
+//
+// The pipeline writes no output unless SetLog is called. A Pipeline keeps per-question
+// state and is not safe for concurrent use; create one per goroutine.
+package reactsql
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"reactsql/internal/adapter"
+	contextpkg "reactsql/internal/context"
+	"reactsql/internal/inference"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// Pipeline configuration and results
+type (
+	Config          = inference.Config
+	Result          = inference.Result
+	ReActStep       = inference.ReActStep
+	StepCallback    = inference.StepCallback
+	Trajectory      = inference.Trajectory
+	LLMExchange     = inference.LLMExchange
+	PhaseStats      = inference.PhaseStats
+	FinalAnswer     = inference.FinalAnswer
+	SubQuestion     = inference.SubQuestion
+	Candidate       = inference.Candidate
+	Session         = inference.Session
+	Turn            = inference.Turn
+	WriteOutcome    = inference.WriteOutcome
+	Agreement       = inference.Agreement
+	ColumnRelevance = inference.ColumnRelevance
+	LinkCache       = inference.LinkCache
+	SQLStyle        = inference.SQLStyle
+)
+
+// Clarification
+type (
+	ClarifyBackend        = inference.ClarifyBackend
+	ClarifyRequest        = inference.ClarifyRequest
+	ClarifyFunc           = inference.ClarifyFunc
+	StaticClarifyBackend  = inference.StaticClarifyBackend
+	StdinClarifyBackend   = inference.StdinClarifyBackend
+	WebhookClarifyBackend = inference.WebhookClarifyBackend
+)
+
+// Database access
+type (
+	DBAdapter      = adapter.DBAdapter
+	DBConfig       = adapter.DBConfig
+	QueryResult    = adapter.QueryResult
+	ColumnInfo     = adapter.ColumnInfo
+	ForeignKeyInfo = adapter.ForeignKeyInfo
+	IndexInfo      = adapter.IndexInfo
+	MaskingRules   = adapter.MaskingRules
+	RetryConfig    = adapter.RetryConfig
+	TextOptions    = adapter.TextOptions
+	SandboxConfig  = adapter.SandboxConfig
+)
+
+// Rich Context
+type (
+	SharedContext = contextpkg.SharedContext
+	ExportOptions = contextpkg.ExportOptions
+)
+
+// Phase names in Result.Phases
+const (
+	PhaseFollowUp      = inference.PhaseFollowUp
+	PhaseSchemaLinking = inference.PhaseSchemaLinking
+	PhaseDecomposition = inference.PhaseDecomposition
+	PhaseGeneration    = inference.PhaseGeneration
+	PhaseSyntaxRepair  = inference.PhaseSyntaxRepair
+	PhaseExecRepair    = inference.PhaseExecRepair
+	PhaseAnswer        = inference.PhaseAnswer
+	PhaseClarify       = inference.PhaseClarify
+	PhaseProofread     = inference.PhaseProofread
+)
+
+// Generation paths in Result.GenerationPath
+const (
+	PathReAct         = inference.PathReAct
+	PathOneShot       = inference.PathOneShot
+	PathFallback      = inference.PathFallback
+	PathDecomposition = inference.PathDecomposition
+)
+
+// SQL style preferences in SQLStyle
+const (
+	StyleCTE           = inference.StyleCTE
+	StyleNested        = inference.StyleNested
+	StyleExplicitJoins = inference.StyleExplicitJoins
+	StyleAliasAS       = inference.StyleAliasAS
+	StyleAliasShort    = inference.StyleAliasShort
+)
+
+// NewAdapter creates a database adapter (call Connect before use)
+func NewAdapter(config *DBConfig) (DBAdapter, error) {
+	return adapter.NewAdapter(config)
+}
+
+// SandboxMain must be the first call in main() when DBConfig.Sandbox is used
+// (the sandbox re-executes the binary as a query worker); it returns immediately otherwise.
+func SandboxMain() {
+	adapter.SandboxMain()
+}
+
+// LoadContext loads a Rich Context file generated by gen_all_dev, merging the
+// <db>.overrides.json next to it (if any) over it
+func LoadContext(path string) (*SharedContext, error) {
+	ctx, err := contextpkg.LoadContextWithOverrides(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load context: %w", err)
+	}
+	ctx.Quiet = true
+	return ctx, nil
+}
+
+// NewLinkCache creates a schema-linking cache to share across pipelines (Config.LinkCache)
+func NewLinkCache() *LinkCache {
+	return inference.NewLinkCache()
+}
+
+// ParseSQLStyle parses a comma-separated preference list, e.g. "cte,explicit,as"
+func ParseSQLStyle(text string) (SQLStyle, error) {
+	return inference.ParseSQLStyle(text)
+}
+
+// FormatSQL pretty-prints a query: upper-cased keywords, one clause per line, indented subqueries
+func FormatSQL(sql, dialect string) string {
+	return inference.FormatSQL(sql, dialect)
+}
+
+// DiffSQL line diff of two formatted queries ("- " only in a, "+ " only in b)
+func DiffSQL(a, b, dialect string) string {
+	return inference.DiffSQL(a, b, dialect)
+}
+
+// NewStdinClarifyBackend creates a terminal clarification backend (nil in/out = stdin/stdout)
+func NewStdinClarifyBackend(in io.Reader, out io.Writer) *StdinClarifyBackend {
+	return inference.NewStdinClarifyBackend(in, out)
+}
+
+// NewWebhookClarifyBackend creates a clarification backend that POSTs questions to url
+func NewWebhookClarifyBackend(url string) *WebhookClarifyBackend {
+	return inference.NewWebhookClarifyBackend(url)
+}
+
+// CleanSQL normalizes SQL extracted from model output (markdown, prose, whitespace)
+func CleanSQL(sql, dialect string) string {
+	return inference.CleanSQL(sql, dialect)
+}
+
+// TranspileSQL rewrites SQL from one dialect to another ("sqlite", "mysql", "postgresql")
+func TranspileSQL(sql, from, to string) (string, error) {
+	return inference.TranspileSQL(sql, from, to)
+}
+
+// RenderTrajectory pretty-prints a result's trajectory; withExchanges adds the full prompts and responses
+func RenderTrajectory(w io.Writer, t *Trajectory, withExchanges bool) {
+	inference.RenderTrajectory(w, t, withExchanges)
+}
+
+// Pipeline NL→SQL pipeline bound to one model and one database
+type Pipeline struct {
+	p *inference.Pipeline
+}
+
+// New creates a pipeline; the config is copied
+// Config.ContextFile is loaded here (only its table index with Config.LazyContext) and a load
+// error is returned (use SetContext for an in-memory context).
+func New(model llms.Model, db DBAdapter, config *Config) (*Pipeline, error) {
+	if model == nil {
+		return nil, fmt.Errorf("model is required")
+	}
+	if db == nil {
+		return nil, fmt.Errorf("database adapter is required")
+	}
+	cfg := Config{}
+	if config != nil {
+		cfg = *config
+	}
+
+	var rc *SharedContext
+	var lazy *contextpkg.LazyContext
+	if cfg.ContextFile != "" && cfg.LazyContext {
+		opened, err := contextpkg.OpenLazyContext(cfg.ContextFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load context: %w", err)
+		}
+		opened.Context().Quiet = true
+		lazy = opened
+		cfg.ContextFile = ""
+	} else if cfg.ContextFile != "" {
+		loaded, err := LoadContext(cfg.ContextFile)
+		if err != nil {
+			return nil, err
+		}
+		rc = loaded
+		cfg.ContextFile = ""
+	}
+
+	p := inference.NewPipeline(model, db, &cfg)
+	p.SetLogger(inference.NewInferenceLoggerTo(io.Discard))
+	if rc != nil {
+		p.SetContext(rc)
+	}
+	if lazy != nil {
+		p.SetLazyContext(lazy)
+	}
+	return &Pipeline{p: p}, nil
+}
+
+// Run answers one question
+func (p *Pipeline) Run(ctx context.Context, question string) (*Result, error) {
+	return p.p.Execute(ctx, question)
+}
+
+// SetLog writes progress output to w (nil = silent, the default)
+func (p *Pipeline) SetLog(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+	p.p.SetLogger(inference.NewInferenceLoggerTo(w))
+}
+
+// SetContext sets the Rich Context used for schema linking and generation
+func (p *Pipeline) SetContext(ctx *SharedContext) {
+	if ctx != nil {
+		ctx.Quiet = true
+	}
+	p.p.SetContext(ctx)
+}
+
+// SetClarifyBackend answers the agent's clarification questions (Config.ClarifyMode "on")
+func (p *Pipeline) SetClarifyBackend(backend ClarifyBackend) {
+	p.p.SetClarifyBackend(backend)
+}
+
+// SetStepCallback streams ReAct steps as they happen
+func (p *Pipeline) SetStepCallback(callback StepCallback) {
+	p.p.SetStepCallback(callback)
+}
+
+// NewSession starts a multi-turn conversation; follow-ups are resolved against earlier turns
+func (p *Pipeline) NewSession() *Session {
+	return inference.NewSession(p.p)
+}
+
+// RegisterTool adds a domain tool to the ReAct loop
+func (p *Pipeline) RegisterTool(tool tools.Tool) error {
+	return p.p.RegisterTool(tool)
+}